@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	analysisApp "alert_agent/internal/application/analysis"
+	"alert_agent/internal/domain/analysis"
+	aiAnalysis "alert_agent/internal/infrastructure/analysis"
+	"alert_agent/internal/infrastructure/config"
+	"alert_agent/internal/pkg/logger"
+)
+
+// listFlag 可重复指定的参数
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var variants, prices listFlag
+	dataset := flag.String("dataset", "", "JSONL 格式的标注告警集")
+	flag.Var(&variants, "variant", "参与对比的组合 name=backend:model[@template.json]，可重复，backend 为 ollama 或 openai")
+	flag.Var(&prices, "price", "组合每千 token 价格 name=price，可重复")
+	format := flag.String("format", "table", "输出格式: table, json")
+	timeout := flag.Duration("timeout", 30*time.Minute, "评测超时时间")
+	logLevel := flag.String("log-level", "warn", "日志级别: debug, info, warn, error")
+	flag.Parse()
+
+	if *dataset == "" || len(variants) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: analysis-eval -dataset cases.jsonl -variant name=backend:model[@template.json] [-variant ...] [-price name=0.002]")
+		os.Exit(2)
+	}
+	if err := logger.Init(*logLevel); err != nil {
+		fatalf("init logger: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatalf("load config: %v", err)
+	}
+
+	file, err := os.Open(*dataset)
+	if err != nil {
+		fatalf("open dataset: %v", err)
+	}
+	cases, err := analysisApp.LoadEvalCases(file)
+	file.Close()
+	if err != nil {
+		fatalf("load dataset: %v", err)
+	}
+
+	priceByName, err := parsePrices(prices)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	evalVariants := make([]*analysisApp.EvalVariant, 0, len(variants))
+	for _, spec := range variants {
+		variant, err := buildVariant(spec, &cfg.AI)
+		if err != nil {
+			fatalf("variant %q: %v", spec, err)
+		}
+		variant.PricePer1KTokens = priceByName[variant.Name]
+		evalVariants = append(evalVariants, variant)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	reports := analysisApp.RunEvaluation(ctx, cases, evalVariants)
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(reports)
+	default:
+		err = analysisApp.WriteEvalReports(os.Stdout, reports)
+	}
+	if err != nil {
+		fatalf("write report: %v", err)
+	}
+}
+
+// buildVariant 解析 name=backend:model[@template.json] 并创建不缓存、不统计用量的分析引擎
+func buildVariant(spec string, cfg *config.AIConfig) (*analysisApp.EvalVariant, error) {
+	name, rest, ok := strings.Cut(spec, "=")
+	if !ok || name == "" {
+		return nil, fmt.Errorf("expected name=backend:model[@template.json]")
+	}
+	rest, templateFile, _ := strings.Cut(rest, "@")
+	backend, model, ok := strings.Cut(rest, ":")
+	if !ok || model == "" {
+		return nil, fmt.Errorf("expected backend:model")
+	}
+
+	chatConfig := aiAnalysis.ChatConfig{
+		Model:       model,
+		Temperature: cfg.Temperature,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+	}
+	var service aiAnalysis.AIService
+	switch backend {
+	case aiAnalysis.BackendOllama:
+		chatConfig.BaseURL = cfg.OllamaEndpoint
+		service = aiAnalysis.NewOllamaAIService(chatConfig)
+	case aiAnalysis.BackendOpenAI:
+		chatConfig.BaseURL = cfg.OpenAIBaseURL
+		chatConfig.APIKey = cfg.OpenAIAPIKey
+		service = aiAnalysis.NewOpenAIAIService(chatConfig)
+	default:
+		return nil, fmt.Errorf("unsupported backend: %s", backend)
+	}
+
+	templates := make(map[analysis.AnalysisType]*aiAnalysis.AnalysisTemplate)
+	if templateFile != "" {
+		template, err := loadTemplate(templateFile)
+		if err != nil {
+			return nil, err
+		}
+		templates[template.Type] = template
+	}
+
+	engineConfig := aiAnalysis.DefaultEngineConfig()
+	engineConfig.EnableCache = false
+	if cfg.Timeout > 0 {
+		engineConfig.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	if cfg.MaxRetries >= 0 {
+		engineConfig.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.RepairAttempts >= 0 {
+		engineConfig.RepairAttempts = cfg.RepairAttempts
+	}
+	engine := aiAnalysis.NewAnalysisEngine(service, aiAnalysis.NewStaticTemplateRepository(templates), nil, nil, nil, engineConfig)
+	return &analysisApp.EvalVariant{Name: name, Engine: engine}, nil
+}
+
+// loadTemplate 读取 JSON 格式的分析模板，与模板接口导出的格式一致
+func loadTemplate(path string) (*aiAnalysis.AnalysisTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+	var template aiAnalysis.AnalysisTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", path, err)
+	}
+	if template.Prompt == "" {
+		return nil, fmt.Errorf("template %s has no prompt", path)
+	}
+	if template.Type == "" {
+		template.Type = analysis.AnalysisTypeRootCause
+	}
+	if template.ID == "" {
+		template.ID = path
+	}
+	return &template, nil
+}
+
+// parsePrices 解析 name=price 列表
+func parsePrices(specs []string) (map[string]float64, error) {
+	prices := make(map[string]float64, len(specs))
+	for _, spec := range specs {
+		name, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid price %q, expected name=price", spec)
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid price %q", spec)
+		}
+		prices[name] = price
+	}
+	return prices, nil
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "analysis-eval: "+format+"\n", args...)
+	os.Exit(1)
+}
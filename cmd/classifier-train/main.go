@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	gatewayApp "alert_agent/internal/application/gateway"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/infrastructure/config"
+	"alert_agent/internal/infrastructure/database"
+	"alert_agent/internal/infrastructure/repository"
+	"alert_agent/internal/pkg/logger"
+)
+
+func main() {
+	defaults := gatewayApp.DefaultClassifierTrainConfig()
+	since := flag.Duration("since", 90*24*time.Hour, "使用最近多长时间内处理完成的告警")
+	limit := flag.Int("limit", 20000, "最多使用的样本数")
+	holdout := flag.Float64("holdout", defaults.Holdout, "用于评估的样本比例")
+	minSamples := flag.Int("min-samples", defaults.MinSamples, "训练所需的最少样本数")
+	smoothing := flag.Float64("smoothing", defaults.Smoothing, "拉普拉斯平滑系数")
+	minAccuracy := flag.Float64("min-accuracy", 0, "留出集准确率低于该值时保存但不启用新版本")
+	activate := flag.Bool("activate", true, "训练后启用新版本")
+	dryRun := flag.Bool("dry-run", false, "只训练和评估，不保存新版本")
+	list := flag.Bool("list", false, "列出已保存的模型版本")
+	rollback := flag.String("rollback", "", "重新启用指定的已保存版本")
+	format := flag.String("format", "table", "输出格式: table, json")
+	timeout := flag.Duration("timeout", 10*time.Minute, "超时时间")
+	logLevel := flag.String("log-level", "warn", "日志级别: debug, info, warn, error")
+	flag.Parse()
+
+	if err := logger.Init(*logLevel); err != nil {
+		fatalf("init logger: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fatalf("load config: %v", err)
+	}
+	db, err := database.NewConnection(cfg.Database)
+	if err != nil {
+		fatalf("connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&gateway.ClassifierModel{}); err != nil {
+		fatalf("migrate classifier models: %v", err)
+	}
+	repo := repository.NewAlertClassifierRepository(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch {
+	case *list:
+		models, err := repo.List(ctx, 50)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		output(*format, models, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "VERSION\tACTIVE\tSAMPLES\tACCURACY\tCREATED")
+			for _, m := range models {
+				fmt.Fprintf(w, "%s\t%t\t%d\t%.3f\t%s\n", m.Version, m.Active, m.Samples, m.Accuracy, m.CreatedAt.Format(time.RFC3339))
+			}
+		})
+		return
+	case *rollback != "":
+		if err := repo.Activate(ctx, *rollback); err != nil {
+			fatalf("%v", err)
+		}
+		fmt.Printf("activated classifier model %s\n", *rollback)
+		return
+	}
+
+	samples, err := repo.ListTrainingSamples(ctx, time.Now().Add(-*since), *limit)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	version := "v" + time.Now().UTC().Format("20060102150405")
+	trained, report, err := gatewayApp.TrainClassifier(samples, version, gatewayApp.ClassifierTrainConfig{
+		Smoothing:  *smoothing,
+		Holdout:    *holdout,
+		MinSamples: *minSamples,
+	})
+	if err != nil {
+		fatalf("train: %v", err)
+	}
+
+	if !*dryRun {
+		if err := repo.Create(ctx, trained); err != nil {
+			fatalf("%v", err)
+		}
+		if *activate && report.Accuracy >= *minAccuracy {
+			if err := repo.Activate(ctx, version); err != nil {
+				fatalf("%v", err)
+			}
+			trained.Active = true
+		}
+	}
+
+	output(*format, report, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "version\t%s\n", report.Version)
+		fmt.Fprintf(w, "samples\t%d\n", report.Samples)
+		fmt.Fprintf(w, "evaluated\t%d\n", report.Evaluated)
+		fmt.Fprintf(w, "accuracy\t%.3f\n", report.Accuracy)
+		fmt.Fprintf(w, "severity accuracy\t%.3f\n", report.SeverityAccuracy)
+		fmt.Fprintf(w, "saved\t%t\n", !*dryRun)
+		fmt.Fprintf(w, "active\t%t\n", trained.Active)
+		categories := make([]string, 0, len(report.Categories))
+		for category := range report.Categories {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			fmt.Fprintf(w, "  %s\t%d\n", category, report.Categories[category])
+		}
+	})
+}
+
+// output 按格式输出结果
+func output(format string, value interface{}, table func(w *tabwriter.Writer)) {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(value); err != nil {
+			fatalf("write output: %v", err)
+		}
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	w.Flush()
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "classifier-train: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"alert_agent/internal/application/gateway"
 	"alert_agent/internal/infrastructure/config"
 	"alert_agent/internal/infrastructure/database"
 	"alert_agent/internal/infrastructure/di"
@@ -15,6 +17,7 @@ import (
 	"alert_agent/internal/pkg/types"
 	"alert_agent/pkg/logger"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
@@ -107,25 +110,150 @@ func main() {
 		logger.Error("Failed to process unanalyzed alerts", zap.Error(err))
 	}
 
-	// TODO: 实现工作器获取和启动逻辑
-	// 获取工作器
-	// worker := container.GetWorker()
-
-	// 启动工作器
 	workerCtx, workerCancel := context.WithCancel(context.Background())
+
+	// 加载告警分类模型，重新训练启用新版本后自动切换
+	classifierDone := make(chan struct{})
+	if classifier := container.GetAlertClassifier(); classifier != nil {
+		go func() {
+			defer close(classifierDone)
+			if err := classifier.Run(workerCtx); err != nil {
+				logger.Error("Alert classifier reloader failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(classifierDone)
+	}
+
+	// 启动网关处理流消费者
+	consumer := gateway.NewPipelineConsumer(
+		container.GetAlertStream(),
+		container.GetSmartGateway(),
+		container.GetGatewayMetrics(),
+		container.PipelineConfig(),
+		logger,
+	)
+	consumerDone := make(chan struct{})
 	go func() {
-		logger.Info("Starting worker...")
-		// TODO: 实现工作器启动逻辑
-		// if err := worker.Start(workerCtx); err != nil {
-		//	logger.Error("Worker failed", zap.Error(err))
-		// }
-		for {
-			select {
-			case <-workerCtx.Done():
-				return
-			case <-time.After(10 * time.Second):
-				logger.Debug("Worker heartbeat")
+		defer close(consumerDone)
+		logger.Info("Starting gateway pipeline consumer...")
+		if err := consumer.Run(workerCtx); err != nil {
+			logger.Error("Gateway pipeline consumer failed", zap.Error(err))
+		}
+	}()
+
+	// 定期重新评估抖动中的告警，抖动结束时补发暂停的通知；结束抖动的状态更新是原子的，可在多个 worker 上运行
+	flapDone := make(chan struct{})
+	if cfg.Gateway.FlapHoldNotifications {
+		go func() {
+			defer close(flapDone)
+			if err := container.GetFlapReleaser().Run(workerCtx); err != nil {
+				logger.Error("Flap releaser failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(flapDone)
+	}
+
+	// 启动规则评估，租约保证每条规则只由一个 worker 评估
+	ruleDone := make(chan struct{})
+	if cfg.RuleEngine.Enabled {
+		go func() {
+			defer close(ruleDone)
+			if err := container.GetRuleScheduler().Run(workerCtx); err != nil {
+				logger.Error("Rule evaluation scheduler failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(ruleDone)
+	}
+
+	// 启动告警分析小时聚合刷新，租约保证只由一个 worker 刷新
+	analyticsDone := make(chan struct{})
+	if cfg.Analytics.Enabled {
+		go func() {
+			defer close(analyticsDone)
+			if err := container.GetAnalyticsService().Run(workerCtx); err != nil {
+				logger.Error("Analytics rollup refresher failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(analyticsDone)
+	}
+
+	// 启动过期数据归档，租约保证只由一个 worker 归档
+	retentionDone := make(chan struct{})
+	if cfg.Retention.Enabled {
+		go func() {
+			defer close(retentionDone)
+			if err := container.GetRetentionService().Run(workerCtx); err != nil {
+				logger.Error("Retention archiver failed", zap.Error(err))
 			}
+		}()
+	} else {
+		close(retentionDone)
+	}
+
+	// 回收租约过期的分析任务，回收在 Redis 中原子完成，可在多个 worker 上运行
+	reaperDone := make(chan struct{})
+	if cfg.AnalysisQueue.ReaperEnabled {
+		go func() {
+			defer close(reaperDone)
+			if err := container.GetAnalysisQueueReaper().Run(workerCtx); err != nil {
+				logger.Error("Analysis queue reaper failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(reaperDone)
+	}
+
+	// 同步知识索引，分析任务在本进程检索参考资料，索引快照随同步保存
+	knowledgeDone := make(chan struct{})
+	if cfg.RAG.Enabled {
+		go func() {
+			defer close(knowledgeDone)
+			if err := container.GetKnowledgeIndexService().Run(workerCtx); err != nil {
+				logger.Error("Knowledge index sync failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(knowledgeDone)
+	}
+
+	// 启动分析工作器，按队列积压在上下限之间扩缩容
+	autoscalerDone := make(chan struct{})
+	if cfg.AnalysisWorker.Enabled {
+		go func() {
+			defer close(autoscalerDone)
+			if err := container.GetWorkerAutoscaler().Run(workerCtx); err != nil {
+				logger.Error("Analysis worker autoscaler failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(autoscalerDone)
+	}
+
+	// 执行已审批或无需审批的运行手册，执行通过状态条件更新领取，可在多个 worker 上运行
+	automationDone := make(chan struct{})
+	if cfg.Automation.Enabled {
+		go func() {
+			defer close(automationDone)
+			if err := container.GetAutomationRunner().Run(workerCtx); err != nil {
+				logger.Error("Runbook runner failed", zap.Error(err))
+			}
+		}()
+	} else {
+		close(automationDone)
+	}
+
+	// 暴露处理流积压等指标
+	metricsServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Gateway.MetricsPort),
+		Handler: promhttp.Handler(),
+	}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Metrics server failed", zap.Error(err))
 		}
 	}()
 
@@ -142,16 +270,19 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// 停止工作器
+	// 停止消费者，未确认的消息由其他消费者接管
 	workerCancel()
+	metricsServer.Shutdown(ctx)
 
-	// 等待工作器完全停止
-	select {
-	case <-ctx.Done():
-		logger.Warn("Worker shutdown timeout")
-	case <-time.After(5 * time.Second):
-		logger.Info("Worker shutdown completed")
+	// 等待消费者、分类模型加载、规则评估、分析聚合、归档、租约回收、知识索引同步与分析工作器完全停止
+	for _, done := range []chan struct{}{consumerDone, flapDone, classifierDone, ruleDone, analyticsDone, retentionDone, reaperDone, knowledgeDone, autoscalerDone, automationDone} {
+		select {
+		case <-ctx.Done():
+			logger.Warn("Worker shutdown timeout")
+		case <-done:
+		}
 	}
+	logger.Info("Worker shutdown completed")
 
 	logger.Info("Worker exited")
 }
\ No newline at end of file
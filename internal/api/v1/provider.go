@@ -1,11 +1,15 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"alert_agent/internal/model"
 	"alert_agent/internal/pkg/database"
+	providerclient "alert_agent/internal/pkg/provider"
 	"alert_agent/internal/pkg/redis"
 	"alert_agent/internal/service"
 
@@ -207,6 +211,7 @@ func DeleteProvider(c *gin.Context) {
 }
 
 // TestProvider 测试数据源连接
+// 请求体携带 id 时按已保存的配置检查并记录结果，忽略请求体中的其他字段；否则只探测请求体中的配置
 func TestProvider(c *gin.Context) {
 	var provider model.Provider
 	if err := c.ShouldBindJSON(&provider); err != nil {
@@ -218,15 +223,189 @@ func TestProvider(c *gin.Context) {
 		return
 	}
 
-	// TODO: 实现具体的连接测试逻辑
-	// 这里可以根据 provider.Type 调用相应的测试方法
-	
+	providerService := service.NewProviderService(database.DB, redis.Client)
+	if provider.ID == 0 {
+		info, err := providerService.TestConnection(c.Request.Context(), &provider)
+		respondConnectionTest(c, info, err)
+		return
+	}
+
+	info, err := providerService.CheckProvider(c.Request.Context(), provider.ID)
+	if errors.Is(err, service.ErrProviderNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": 404,
+			"msg":  "数据源不存在",
+			"data": err.Error(),
+		})
+		return
+	}
+	respondConnectionTest(c, info, err)
+}
+
+// CheckProvider 测试已保存数据源的连接并记录检查结果
+func CheckProvider(c *gin.Context) {
+	providerID, ok := parseProviderID(c)
+	if !ok {
+		return
+	}
+
+	providerService := service.NewProviderService(database.DB, redis.Client)
+	info, err := providerService.CheckProvider(c.Request.Context(), providerID)
+	if errors.Is(err, service.ErrProviderNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": 404,
+			"msg":  "数据源不存在",
+			"data": err.Error(),
+		})
+		return
+	}
+	respondConnectionTest(c, info, err)
+}
+
+// QueryProvider 通过数据源执行即时查询
+func QueryProvider(c *gin.Context) {
+	proxyProviderQuery(c, true, func(ctx context.Context, client *providerclient.Client, params url.Values) (*providerclient.APIResponse, error) {
+		return client.Query(ctx, params)
+	})
+}
+
+// QueryRangeProvider 通过数据源执行范围查询
+func QueryRangeProvider(c *gin.Context) {
+	proxyProviderQuery(c, true, func(ctx context.Context, client *providerclient.Client, params url.Values) (*providerclient.APIResponse, error) {
+		return client.QueryRange(ctx, params)
+	})
+}
+
+// ListProviderLabels 查询数据源标签名
+func ListProviderLabels(c *gin.Context) {
+	proxyProviderQuery(c, false, func(ctx context.Context, client *providerclient.Client, params url.Values) (*providerclient.APIResponse, error) {
+		return client.Labels(ctx, params)
+	})
+}
+
+// ListProviderLabelValues 查询数据源标签值
+func ListProviderLabelValues(c *gin.Context) {
+	name := c.Param("name")
+	proxyProviderQuery(c, false, func(ctx context.Context, client *providerclient.Client, params url.Values) (*providerclient.APIResponse, error) {
+		return client.LabelValues(ctx, name, params)
+	})
+}
+
+// ListProviderSeries 查询数据源时间序列
+func ListProviderSeries(c *gin.Context) {
+	if len(c.QueryArray("match[]")) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "缺少 match[] 参数",
+			"data": nil,
+		})
+		return
+	}
+	proxyProviderQuery(c, false, func(ctx context.Context, client *providerclient.Client, params url.Values) (*providerclient.APIResponse, error) {
+		return client.Series(ctx, params)
+	})
+}
+
+// proxyQueryParams 允许透传到数据源的查询参数
+var proxyQueryParams = []string{"query", "time", "timeout", "start", "end", "step", "match[]", "limit"}
+
+// proxyProviderQuery 获取数据源客户端并透传查询参数
+func proxyProviderQuery(c *gin.Context, requireQuery bool, query func(context.Context, *providerclient.Client, url.Values) (*providerclient.APIResponse, error)) {
+	providerID, ok := parseProviderID(c)
+	if !ok {
+		return
+	}
+	if requireQuery && c.Query("query") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "缺少 query 参数",
+			"data": nil,
+		})
+		return
+	}
+
+	params := url.Values{}
+	for _, key := range proxyQueryParams {
+		if values := c.QueryArray(key); len(values) > 0 {
+			params[key] = values
+		}
+	}
+
+	providerService := service.NewProviderService(database.DB, redis.Client)
+	client, err := providerService.Client(c.Request.Context(), providerID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProviderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "数据源不存在", "data": err.Error()})
+		case errors.Is(err, service.ErrProviderInactive), errors.Is(err, service.ErrInvalidProvider):
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "数据源不可用", "data": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "获取数据源失败", "data": err.Error()})
+		}
+		return
+	}
+
+	resp, err := query(c.Request.Context(), client, params)
+	if err != nil {
+		var apiErr *providerclient.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorType == "bad_data" {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "查询参数无效", "data": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"code": 502, "msg": "数据源查询失败", "data": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "success",
+		"data": resp,
+	})
+}
+
+// parseProviderID 解析路径中的数据源ID
+func parseProviderID(c *gin.Context) (uint, bool) {
+	providerID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "无效的数据源ID",
+			"data": nil,
+		})
+		return 0, false
+	}
+	return uint(providerID), true
+}
+
+// respondConnectionTest 输出连接测试结果
+func respondConnectionTest(c *gin.Context, info *providerclient.BuildInfo, err error) {
+	if errors.Is(err, service.ErrInvalidProvider) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  "数据源配置无效: " + err.Error(),
+			"data": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"code": 502,
+			"msg":  "连接测试失败",
+			"data": gin.H{
+				"status":  "failed",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "连接测试成功",
 		"data": gin.H{
-			"status": "success",
-			"message": "数据源连接正常",
+			"status":     "success",
+			"message":    "数据源连接正常",
+			"build_info": info,
 		},
 	})
-}
\ No newline at end of file
+}
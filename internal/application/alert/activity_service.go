@@ -0,0 +1,269 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// ActivityService 告警动态服务实现
+type ActivityService struct {
+	repo   alert.ActivityRepository
+	alerts alert.AlertRepository
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewActivityService 创建告警动态服务
+func NewActivityService(repo alert.ActivityRepository, alerts alert.AlertRepository, logger *zap.Logger) *ActivityService {
+	return &ActivityService{
+		repo:   repo,
+		alerts: alerts,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// GetDetail 获取告警详情与时间线
+func (s *ActivityService) GetDetail(ctx context.Context, alertID uint) (*alert.AlertDetail, error) {
+	a, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	timeline, err := s.timeline(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return &alert.AlertDetail{Alert: a.ToResponse(), Assignee: a.Handler, Timeline: timeline}, nil
+}
+
+// Timeline 获取告警时间线，回复嵌套在所属评论下
+func (s *ActivityService) Timeline(ctx context.Context, alertID uint) ([]*alert.Activity, error) {
+	a, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	return s.timeline(ctx, a)
+}
+
+// AddComment 添加评论或回复，回复的回复归入同一讨论串
+func (s *ActivityService) AddComment(ctx context.Context, alertID uint, req *alert.CommentRequest) (*alert.Activity, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, errors.NewValidationError("INVALID_COMMENT", "Comment body is required")
+	}
+	if _, err := s.getAlert(ctx, alertID); err != nil {
+		return nil, err
+	}
+
+	activity := &alert.Activity{
+		AlertID:   alertID,
+		Type:      alert.ActivityComment,
+		Actor:     req.Actor,
+		Message:   body,
+		Mentions:  alert.ParseMentions(body),
+		CreatedAt: s.now(),
+	}
+	if req.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to get parent comment", err)
+		}
+		if parent == nil || parent.AlertID != alertID || parent.Type != alert.ActivityComment {
+			return nil, errors.NewValidationError("INVALID_PARENT", fmt.Sprintf("Comment %d not found on alert %d", *req.ParentID, alertID))
+		}
+		rootID := parent.ID
+		if parent.ParentID != nil {
+			rootID = *parent.ParentID
+		}
+		activity.ParentID = &rootID
+	}
+
+	if err := s.repo.Create(ctx, activity); err != nil {
+		return nil, errors.NewInternalError("failed to add comment", err)
+	}
+	if len(activity.Mentions) > 0 {
+		s.logger.Info("alert comment mentions users",
+			zap.Uint("alert_id", alertID),
+			zap.String("actor", req.Actor),
+			zap.Strings("mentions", activity.Mentions))
+	}
+	return activity, nil
+}
+
+// Assign 指派或转派告警，历史处理人保留在时间线中
+func (s *ActivityService) Assign(ctx context.Context, alertID uint, req *alert.AssignRequest) (*alert.Activity, error) {
+	a, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	assignee := strings.TrimSpace(req.Assignee)
+	if assignee == a.Handler {
+		return nil, errors.NewConflictError(fmt.Sprintf("Alert %d is already assigned to %q", alertID, assignee))
+	}
+	if err := s.alerts.UpdateByID(ctx, alertID, map[string]interface{}{"handler": assignee}); err != nil {
+		return nil, errors.NewInternalError("failed to assign alert", err)
+	}
+
+	activity := NewAssignmentActivity(alertID, req.Actor, a.Handler, assignee, req.Note, s.now())
+	if err := s.repo.Create(ctx, activity); err != nil {
+		return nil, errors.NewInternalError("failed to record assignment", err)
+	}
+	return activity, nil
+}
+
+// ChangeStatus 变更告警状态，首次确认或恢复时记录处理人与处理时间
+func (s *ActivityService) ChangeStatus(ctx context.Context, alertID uint, req *alert.StatusRequest) (*alert.Activity, error) {
+	switch req.Status {
+	case model.AlertStatusNew, model.AlertStatusAcknowledged, model.AlertStatusResolved:
+	default:
+		return nil, errors.NewValidationError("INVALID_STATUS", fmt.Sprintf("Invalid alert status: %s", req.Status))
+	}
+	a, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status == req.Status {
+		return nil, errors.NewConflictError(fmt.Sprintf("Alert %d is already %s", alertID, req.Status))
+	}
+
+	now := s.now()
+	updates := map[string]interface{}{"status": req.Status}
+	if req.Status != model.AlertStatusNew {
+		if a.Handler == "" && req.Actor != "" {
+			updates["handler"] = req.Actor
+		}
+		if a.HandleTime == nil {
+			updates["handle_time"] = now
+		}
+	}
+	if err := s.alerts.UpdateByID(ctx, alertID, updates); err != nil {
+		return nil, errors.NewInternalError("failed to update alert status", err)
+	}
+
+	activity := NewStatusActivity(alertID, req.Actor, a.Status, req.Status, req.Note, now)
+	if err := s.repo.Create(ctx, activity); err != nil {
+		return nil, errors.NewInternalError("failed to record status change", err)
+	}
+	return activity, nil
+}
+
+// Record 记录其他操作产生的动态，失败只记录日志
+func (s *ActivityService) Record(ctx context.Context, activities ...*alert.Activity) {
+	if err := s.repo.Create(ctx, activities...); err != nil {
+		s.logger.Warn("failed to record alert activities", zap.Int("count", len(activities)), zap.Error(err))
+	}
+}
+
+// NewAssignmentActivity 生成指派动态
+func NewAssignmentActivity(alertID uint, actor, from, to, note string, at time.Time) *alert.Activity {
+	var message string
+	switch {
+	case to == "":
+		message = fmt.Sprintf("取消指派 %s", from)
+	case from == "":
+		message = fmt.Sprintf("指派给 %s", to)
+	default:
+		message = fmt.Sprintf("从 %s 转派给 %s", from, to)
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		message += "：" + note
+	}
+	return &alert.Activity{
+		AlertID:   alertID,
+		Type:      alert.ActivityAssignment,
+		Actor:     actor,
+		Message:   message,
+		Data:      map[string]interface{}{"from": from, "to": to},
+		CreatedAt: at,
+	}
+}
+
+// NewStatusActivity 生成状态变更动态
+func NewStatusActivity(alertID uint, actor, from, to, note string, at time.Time) *alert.Activity {
+	message := fmt.Sprintf("状态 %s → %s", from, to)
+	if note = strings.TrimSpace(note); note != "" {
+		message += "：" + note
+	}
+	return &alert.Activity{
+		AlertID:   alertID,
+		Type:      alert.ActivityStatusChange,
+		Actor:     actor,
+		Message:   message,
+		Data:      map[string]interface{}{"from": from, "to": to},
+		CreatedAt: at,
+	}
+}
+
+func (s *ActivityService) getAlert(ctx context.Context, alertID uint) (*model.Alert, error) {
+	a, err := s.repo.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get alert", err)
+	}
+	if a == nil {
+		return nil, errors.NewNotFoundError("alert")
+	}
+	return a, nil
+}
+
+// timeline 合并告警创建、持久化动态与通知记录，按时间正序，回复嵌套在评论下
+func (s *ActivityService) timeline(ctx context.Context, a *model.Alert) ([]*alert.Activity, error) {
+	activities, err := s.repo.ListByAlert(ctx, a.ID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list alert activities", err)
+	}
+	records, err := s.repo.ListNotifyRecords(ctx, a.ID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list notify records", err)
+	}
+
+	entries := []*alert.Activity{{
+		AlertID:   a.ID,
+		Type:      alert.ActivityCreated,
+		Actor:     a.Source,
+		Message:   a.Title,
+		Data:      map[string]interface{}{"level": a.Level, "rule_id": a.RuleID},
+		CreatedAt: a.CreatedAt,
+	}}
+	for _, record := range records {
+		message := fmt.Sprintf("%s 通知 %s：%s", record.Type, record.Target, record.Status)
+		if record.Error != "" {
+			message += "（" + record.Error + "）"
+		}
+		entries = append(entries, &alert.Activity{
+			AlertID:   a.ID,
+			Type:      alert.ActivityNotification,
+			Actor:     "system",
+			Message:   message,
+			Data:      map[string]interface{}{"channel": record.Type, "target": record.Target, "status": record.Status, "retry_count": record.RetryCount},
+			CreatedAt: record.CreatedAt,
+		})
+	}
+
+	comments := make(map[uint]*alert.Activity)
+	for _, activity := range activities {
+		if activity.Type == alert.ActivityComment && activity.ParentID == nil {
+			comments[activity.ID] = activity
+		}
+	}
+	for _, activity := range activities {
+		if activity.ParentID != nil {
+			if parent, ok := comments[*activity.ParentID]; ok {
+				parent.Replies = append(parent.Replies, activity)
+				continue
+			}
+		}
+		entries = append(entries, activity)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
@@ -0,0 +1,150 @@
+package alert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryActivityRepository 内存告警动态仓储
+type memoryActivityRepository struct {
+	alerts     map[uint]*model.Alert
+	activities []*alert.Activity
+	records    []*model.NotifyRecord
+}
+
+func (r *memoryActivityRepository) GetAlert(ctx context.Context, id uint) (*model.Alert, error) {
+	if a, ok := r.alerts[id]; ok {
+		copied := *a
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+// activityAlertRepository 告警仓储，更新写入内存动态仓储中的告警
+type activityAlertRepository struct {
+	alert.AlertRepository
+	repo *memoryActivityRepository
+}
+
+func (r *activityAlertRepository) UpdateByID(ctx context.Context, id uint, updates map[string]interface{}) error {
+	a := r.repo.alerts[id]
+	for key, value := range updates {
+		switch key {
+		case "status":
+			a.Status = value.(string)
+		case "handler":
+			a.Handler = value.(string)
+		case "handle_time":
+			t := value.(time.Time)
+			a.HandleTime = &t
+		}
+	}
+	return nil
+}
+
+func (r *memoryActivityRepository) Create(ctx context.Context, activities ...*alert.Activity) error {
+	for _, activity := range activities {
+		activity.ID = uint(len(r.activities) + 1)
+		r.activities = append(r.activities, activity)
+	}
+	return nil
+}
+
+func (r *memoryActivityRepository) GetByID(ctx context.Context, id uint) (*alert.Activity, error) {
+	if id == 0 || int(id) > len(r.activities) {
+		return nil, nil
+	}
+	return r.activities[id-1], nil
+}
+
+func (r *memoryActivityRepository) ListByAlert(ctx context.Context, alertID uint) ([]*alert.Activity, error) {
+	var activities []*alert.Activity
+	for _, activity := range r.activities {
+		if activity.AlertID == alertID {
+			copied := *activity
+			activities = append(activities, &copied)
+		}
+	}
+	return activities, nil
+}
+
+func (r *memoryActivityRepository) ListNotifyRecords(ctx context.Context, alertID uint) ([]*model.NotifyRecord, error) {
+	return r.records, nil
+}
+
+func TestParseMentions(t *testing.T) {
+	assert.Equal(t, []string{"bob", "carol.li"}, alert.ParseMentions("@bob please check, cc @carol.li. mail a@b.com @bob"))
+	assert.Empty(t, alert.ParseMentions("no mentions here"))
+}
+
+func TestActivityService_Timeline(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	clock := start
+	repo := &memoryActivityRepository{
+		alerts: map[uint]*model.Alert{1: {ID: 1, Title: "disk full", Source: "prometheus", Status: model.AlertStatusNew, CreatedAt: start}},
+	}
+	record := &model.NotifyRecord{AlertID: 1, Type: model.NotifyTypeWebhook, Target: "oncall", Status: model.NotifyStatusSent}
+	record.CreatedAt = start.Add(30 * time.Second)
+	repo.records = []*model.NotifyRecord{record}
+
+	service := NewActivityService(repo, &activityAlertRepository{repo: repo}, zap.NewNop())
+	service.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+
+	_, err := service.Assign(ctx, 1, &alert.AssignRequest{Assignee: "alice", Actor: "lead"})
+	require.NoError(t, err)
+	reassigned, err := service.Assign(ctx, 1, &alert.AssignRequest{Assignee: "bob", Note: "handover", Actor: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "从 alice 转派给 bob：handover", reassigned.Message)
+
+	_, err = service.Assign(ctx, 1, &alert.AssignRequest{Assignee: "bob", Actor: "alice"})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeConflict))
+
+	comment, err := service.AddComment(ctx, 1, &alert.CommentRequest{Body: "looking, @alice FYI", Actor: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, comment.Mentions)
+	reply, err := service.AddComment(ctx, 1, &alert.CommentRequest{Body: "thanks", ParentID: &comment.ID, Actor: "alice"})
+	require.NoError(t, err)
+	nested, err := service.AddComment(ctx, 1, &alert.CommentRequest{Body: "np", ParentID: &reply.ID, Actor: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, comment.ID, *nested.ParentID)
+
+	status, err := service.ChangeStatus(ctx, 1, &alert.StatusRequest{Status: model.AlertStatusAcknowledged, Actor: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "状态 new → acknowledged", status.Message)
+	assert.Equal(t, "bob", repo.alerts[1].Handler)
+	require.NotNil(t, repo.alerts[1].HandleTime)
+
+	_, err = service.ChangeStatus(ctx, 1, &alert.StatusRequest{Status: "handled"})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+
+	detail, err := service.GetDetail(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", detail.Assignee)
+
+	var types []alert.ActivityType
+	for _, entry := range detail.Timeline {
+		types = append(types, entry.Type)
+	}
+	assert.Equal(t, []alert.ActivityType{
+		alert.ActivityCreated, alert.ActivityNotification, alert.ActivityAssignment,
+		alert.ActivityAssignment, alert.ActivityComment, alert.ActivityStatusChange,
+	}, types)
+	require.Len(t, detail.Timeline[4].Replies, 2)
+	assert.Equal(t, "np", detail.Timeline[4].Replies[1].Message)
+
+	_, err = service.GetDetail(ctx, 2)
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+}
@@ -0,0 +1,357 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+const (
+	reanalyzeType  = "root_cause" // 重新分析使用的分析类型
+	anonymousActor = "anonymous"  // 未登录时的操作者
+)
+
+// BulkService 告警批量操作服务实现
+type BulkService struct {
+	alerts   alert.AlertRepository
+	repo     alert.BulkRepository
+	analyzer analysis.DifyAnalysisService
+	recorder alert.ActivityRecorder
+	config   alert.BulkConfig
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewBulkService 创建告警批量操作服务，analyzer 为空时不支持重新分析，recorder 为空时不记录告警动态
+func NewBulkService(alerts alert.AlertRepository, repo alert.BulkRepository, analyzer analysis.DifyAnalysisService, recorder alert.ActivityRecorder, config alert.BulkConfig, logger *zap.Logger) *BulkService {
+	if config.MaxItems <= 0 {
+		config.MaxItems = alert.DefaultBulkConfig().MaxItems
+	}
+	return &BulkService{
+		alerts:   alerts,
+		repo:     repo,
+		analyzer: analyzer,
+		recorder: recorder,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// bulkGroup 更新内容相同的一组告警，合并为一次批量更新
+type bulkGroup struct {
+	updates map[string]interface{}
+	items   []*alert.BulkItemResult
+	alerts  []*model.Alert // 与 items 一一对应的更新前告警
+}
+
+// Execute 执行或预演批量操作，匹配数量超过上限时拒绝执行
+func (s *BulkService) Execute(ctx context.Context, req *alert.BulkRequest, actor alert.BulkActor) (*alert.BulkResult, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+	limit := s.config.MaxItems
+	if req.MaxItems > 0 && req.MaxItems < limit {
+		limit = req.MaxItems
+	}
+
+	selection, err := s.selection(req.Selector, limit)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := s.repo.Count(ctx, selection)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to count alerts", err)
+	}
+
+	result := &alert.BulkResult{
+		Action:  req.Action,
+		DryRun:  req.DryRun,
+		Matched: matched,
+		Limit:   limit,
+		Items:   []*alert.BulkItemResult{},
+	}
+	if matched > int64(limit) {
+		if req.DryRun {
+			result.ExceedsLimit = true
+			return result, nil
+		}
+		return nil, errors.NewValidationError("BULK_LIMIT_EXCEEDED", fmt.Sprintf(
+			"Selector matches %d alerts, exceeding the limit of %d; narrow the selector or preview with dry_run", matched, limit))
+	}
+
+	alerts, err := s.repo.List(ctx, selection, limit)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list alerts", err)
+	}
+
+	now := s.now()
+	actorName := actor.Username
+	if actorName == "" {
+		actorName = anonymousActor
+	}
+
+	groups := make(map[string]*bulkGroup)
+	var keys []string
+	found := make(map[uint]bool, len(alerts))
+	for _, a := range alerts {
+		found[a.ID] = true
+		item := &alert.BulkItemResult{AlertID: a.ID, Status: alert.BulkItemPending}
+		result.Items = append(result.Items, item)
+
+		updates, reason := s.plan(req, actorName, a, now)
+		if reason != "" {
+			item.Status = alert.BulkItemSkipped
+			item.Reason = reason
+			continue
+		}
+		key := groupKey(updates)
+		group, ok := groups[key]
+		if !ok {
+			group = &bulkGroup{updates: updates}
+			groups[key] = group
+			keys = append(keys, key)
+		}
+		group.items = append(group.items, item)
+		group.alerts = append(group.alerts, a)
+	}
+	for _, id := range selection.IDs {
+		if !found[id] {
+			result.Items = append(result.Items, &alert.BulkItemResult{
+				AlertID: id, Status: alert.BulkItemNotFound, Reason: "alert not found or not matched by query",
+			})
+		}
+	}
+
+	if !req.DryRun {
+		for _, key := range keys {
+			s.apply(ctx, req, actorName, groups[key], now)
+		}
+	}
+
+	var alertIDs []uint
+	for _, item := range result.Items {
+		switch item.Status {
+		case alert.BulkItemPending, alert.BulkItemUpdated:
+			result.Succeeded++
+			alertIDs = append(alertIDs, item.AlertID)
+		case alert.BulkItemFailed:
+			result.Failed++
+			alertIDs = append(alertIDs, item.AlertID)
+		default:
+			result.Skipped++
+		}
+	}
+	if req.DryRun {
+		return result, nil
+	}
+
+	s.logger.Info("Bulk alert operation executed",
+		zap.String("action", string(req.Action)),
+		zap.String("actor", actorName),
+		zap.Int64("matched", matched),
+		zap.Int("succeeded", result.Succeeded),
+		zap.Int("skipped", result.Skipped),
+		zap.Int("failed", result.Failed))
+	if err := s.repo.RecordAudit(ctx, &alert.BulkAuditRecord{
+		Actor:     actor,
+		Action:    req.Action,
+		Selector:  req.Selector,
+		Matched:   matched,
+		Succeeded: result.Succeeded,
+		Skipped:   result.Skipped,
+		Failed:    result.Failed,
+		AlertIDs:  alertIDs,
+		CreatedAt: now,
+	}); err != nil {
+		s.logger.Error("Failed to record bulk alert audit log", zap.Error(err))
+	}
+	return result, nil
+}
+
+// validate 检查操作类型与必填参数
+func (s *BulkService) validate(req *alert.BulkRequest) error {
+	if !req.Action.IsValid() {
+		return errors.NewValidationError("INVALID_ACTION", fmt.Sprintf("Invalid bulk action: %s", req.Action))
+	}
+	if len(req.Selector.IDs) == 0 && strings.TrimSpace(req.Selector.Query) == "" {
+		return errors.NewValidationError("EMPTY_SELECTOR", "Selector requires ids or query")
+	}
+	switch req.Action {
+	case alert.BulkActionAssign:
+		if strings.TrimSpace(req.Assignee) == "" {
+			return errors.NewValidationError("ASSIGNEE_REQUIRED", "Assignee is required for assign")
+		}
+	case alert.BulkActionNote:
+		if strings.TrimSpace(req.Note) == "" {
+			return errors.NewValidationError("NOTE_REQUIRED", "Note is required for note")
+		}
+	case alert.BulkActionReanalyze:
+		if s.analyzer == nil {
+			return errors.NewValidationError("ANALYSIS_UNAVAILABLE", "Alert analysis is not configured")
+		}
+	}
+	return nil
+}
+
+// selection 解析选择器，ID去重后不能超过上限
+func (s *BulkService) selection(selector alert.BulkSelector, limit int) (*alert.BulkSelection, error) {
+	selection := &alert.BulkSelection{}
+	seen := make(map[uint]bool, len(selector.IDs))
+	for _, id := range selector.IDs {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			selection.IDs = append(selection.IDs, id)
+		}
+	}
+	if len(selection.IDs) > limit {
+		return nil, errors.NewValidationError("BULK_LIMIT_EXCEEDED",
+			fmt.Sprintf("Selector contains %d ids, exceeding the limit of %d", len(selection.IDs), limit))
+	}
+
+	expr, err := alert.ParseQueryAt(selector.Query, s.now())
+	if err != nil {
+		return nil, errors.NewValidationError("INVALID_QUERY", err.Error())
+	}
+	selection.Expr = expr
+	if len(selection.IDs) == 0 && selection.Expr == nil {
+		return nil, errors.NewValidationError("EMPTY_SELECTOR", "Selector requires ids or query")
+	}
+	return selection, nil
+}
+
+// plan 计算单条告警需要更新的字段，返回跳过原因时不处理
+func (s *BulkService) plan(req *alert.BulkRequest, actorName string, a *model.Alert, now time.Time) (map[string]interface{}, string) {
+	updates := make(map[string]interface{})
+	switch req.Action {
+	case alert.BulkActionAcknowledge:
+		switch a.Status {
+		case model.AlertStatusResolved:
+			return nil, "already resolved"
+		case model.AlertStatusAcknowledged:
+			return nil, "already acknowledged"
+		}
+		updates["status"] = model.AlertStatusAcknowledged
+	case alert.BulkActionResolve:
+		if a.Status == model.AlertStatusResolved {
+			return nil, "already resolved"
+		}
+		updates["status"] = model.AlertStatusResolved
+	case alert.BulkActionAssign:
+		if a.Handler == req.Assignee {
+			return nil, "already assigned to " + req.Assignee
+		}
+		updates["handler"] = req.Assignee
+		return updates, ""
+	default:
+		return updates, ""
+	}
+
+	// 确认与恢复保留已有的处理人和首次处理时间
+	if a.Handler == "" {
+		updates["handler"] = actorName
+	}
+	if a.HandleTime == nil {
+		updates["handle_time"] = now
+	}
+	return updates, ""
+}
+
+// apply 执行一组告警的更新、备注与重新分析，失败时记录到每条结果
+func (s *BulkService) apply(ctx context.Context, req *alert.BulkRequest, actorName string, group *bulkGroup, now time.Time) {
+	ids := make([]uint, 0, len(group.items))
+	for _, item := range group.items {
+		ids = append(ids, item.AlertID)
+	}
+	// 动态基于更新前的状态与处理人生成
+	activities := s.activities(req, actorName, group, now)
+
+	if len(group.updates) > 0 {
+		if err := s.alerts.BatchUpdate(ctx, ids, group.updates); err != nil {
+			s.logger.Error("Failed to batch update alerts", zap.Error(err), zap.Int("count", len(ids)))
+			markFailed(group.items, err)
+			return
+		}
+	}
+
+	if note := strings.TrimSpace(req.Note); note != "" {
+		line := fmt.Sprintf("[%s %s] %s", now.Format("2006-01-02 15:04:05"), actorName, note)
+		if err := s.repo.AppendNote(ctx, ids, line); err != nil {
+			s.logger.Error("Failed to append alert notes", zap.Error(err), zap.Int("count", len(ids)))
+			markFailed(group.items, err)
+			return
+		}
+	}
+
+	if s.recorder != nil && len(activities) > 0 {
+		s.recorder.Record(ctx, activities...)
+	}
+
+	for _, item := range group.items {
+		item.Status = alert.BulkItemUpdated
+		if req.Action != alert.BulkActionReanalyze {
+			continue
+		}
+		task, err := s.analyzer.AnalyzeAlert(ctx, &analysis.DifyAnalysisRequest{
+			AlertID:      item.AlertID,
+			AnalysisType: reanalyzeType,
+			UserID:       actorName,
+		})
+		if err != nil {
+			item.Status = alert.BulkItemFailed
+			item.Reason = err.Error()
+			continue
+		}
+		item.TaskID = task.ID
+	}
+}
+
+// activities 生成批量操作在每条告警时间线中的动态
+func (s *BulkService) activities(req *alert.BulkRequest, actorName string, group *bulkGroup, now time.Time) []*alert.Activity {
+	note := strings.TrimSpace(req.Note)
+	var activities []*alert.Activity
+	for _, a := range group.alerts {
+		switch req.Action {
+		case alert.BulkActionAcknowledge, alert.BulkActionResolve:
+			activities = append(activities, NewStatusActivity(a.ID, actorName, a.Status, group.updates["status"].(string), note, now))
+		case alert.BulkActionAssign:
+			activities = append(activities, NewAssignmentActivity(a.ID, actorName, a.Handler, req.Assignee, note, now))
+		case alert.BulkActionNote:
+			activities = append(activities, &alert.Activity{
+				AlertID:   a.ID,
+				Type:      alert.ActivityComment,
+				Actor:     actorName,
+				Message:   note,
+				Mentions:  alert.ParseMentions(note),
+				CreatedAt: now,
+			})
+		}
+	}
+	return activities
+}
+
+func markFailed(items []*alert.BulkItemResult, err error) {
+	for _, item := range items {
+		item.Status = alert.BulkItemFailed
+		item.Reason = err.Error()
+	}
+}
+
+// groupKey 更新字段相同的告警合并为一组；同一次操作中字段值都相同
+func groupKey(updates map[string]interface{}) string {
+	keys := make([]string, 0, len(updates))
+	for key := range updates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,224 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryBulkStore 内存告警存储，只实现批量操作用到的方法
+type memoryBulkStore struct {
+	alerts  map[uint]*model.Alert
+	batches int
+	audits  []*alert.BulkAuditRecord
+	records []*alert.Activity
+}
+
+func (s *memoryBulkStore) Record(ctx context.Context, activities ...*alert.Activity) {
+	s.records = append(s.records, activities...)
+}
+
+func (s *memoryBulkStore) selected(selection *alert.BulkSelection) []*model.Alert {
+	var result []*model.Alert
+	for _, a := range s.alerts {
+		if len(selection.IDs) > 0 {
+			found := false
+			for _, id := range selection.IDs {
+				found = found || id == a.ID
+			}
+			if !found {
+				continue
+			}
+		}
+		// 测试只使用 status=<value> 与 status!=<value> 查询
+		if cond, ok := selection.Expr.(*alert.Condition); ok && (a.Status == cond.Value) == (cond.Op == alert.OpNe) {
+			continue
+		}
+		result = append(result, a)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func (s *memoryBulkStore) Count(ctx context.Context, selection *alert.BulkSelection) (int64, error) {
+	return int64(len(s.selected(selection))), nil
+}
+
+func (s *memoryBulkStore) List(ctx context.Context, selection *alert.BulkSelection, limit int) ([]*model.Alert, error) {
+	alerts := s.selected(selection)
+	if limit > 0 && len(alerts) > limit {
+		alerts = alerts[:limit]
+	}
+	return alerts, nil
+}
+
+// memoryAlertRepository 告警仓储，批量更新写入内存存储
+type memoryAlertRepository struct {
+	alert.AlertRepository
+	store *memoryBulkStore
+}
+
+func (r *memoryAlertRepository) BatchUpdate(ctx context.Context, ids []uint, updates map[string]interface{}) error {
+	s := r.store
+	s.batches++
+	for _, id := range ids {
+		a := s.alerts[id]
+		for key, value := range updates {
+			switch key {
+			case "status":
+				a.Status = value.(string)
+			case "handler":
+				a.Handler = value.(string)
+			case "handle_time":
+				t := value.(time.Time)
+				a.HandleTime = &t
+			}
+		}
+	}
+	return nil
+}
+
+func (s *memoryBulkStore) AppendNote(ctx context.Context, ids []uint, line string) error {
+	for _, id := range ids {
+		if s.alerts[id].HandleNote != "" {
+			s.alerts[id].HandleNote += "\n"
+		}
+		s.alerts[id].HandleNote += line
+	}
+	return nil
+}
+
+func (s *memoryBulkStore) RecordAudit(ctx context.Context, record *alert.BulkAuditRecord) error {
+	s.audits = append(s.audits, record)
+	return nil
+}
+
+// stubAnalyzer 记录重新分析请求，指定告警返回失败
+type stubAnalyzer struct {
+	analysis.DifyAnalysisService
+	failFor uint
+}
+
+func (a *stubAnalyzer) AnalyzeAlert(ctx context.Context, request *analysis.DifyAnalysisRequest) (*analysis.DifyAnalysisTask, error) {
+	if request.AlertID == a.failFor {
+		return nil, fmt.Errorf("dify unavailable")
+	}
+	return &analysis.DifyAnalysisTask{ID: fmt.Sprintf("task-%d", request.AlertID), AlertID: request.AlertID}, nil
+}
+
+func newBulkFixture(t *testing.T) (*BulkService, *memoryBulkStore, time.Time) {
+	t.Helper()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	store := &memoryBulkStore{alerts: map[uint]*model.Alert{
+		1: {ID: 1, Status: model.AlertStatusNew},
+		2: {ID: 2, Status: model.AlertStatusNew, Handler: "bob"},
+		3: {ID: 3, Status: model.AlertStatusAcknowledged, Handler: "carol", HandleTime: &earlier},
+		4: {ID: 4, Status: model.AlertStatusResolved},
+	}}
+	service := NewBulkService(&memoryAlertRepository{store: store}, store, &stubAnalyzer{failFor: 3}, store, alert.BulkConfig{MaxItems: 4}, zap.NewNop())
+	service.now = func() time.Time { return now }
+	return service, store, now
+}
+
+func TestBulkService_Acknowledge(t *testing.T) {
+	service, store, now := newBulkFixture(t)
+	actor := alert.BulkActor{UserID: "7", Username: "alice", IPAddress: "10.0.0.1"}
+
+	result, err := service.Execute(context.Background(), &alert.BulkRequest{
+		Action:   alert.BulkActionAcknowledge,
+		Selector: alert.BulkSelector{IDs: []uint{1, 2, 3, 2, 99}},
+		Note:     "db failover",
+	}, actor)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), result.Matched)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 2, result.Skipped)
+	require.Len(t, result.Items, 4)
+	assert.Equal(t, alert.BulkItemUpdated, result.Items[0].Status)
+	assert.Equal(t, alert.BulkItemSkipped, result.Items[2].Status)
+	assert.Equal(t, "already acknowledged", result.Items[2].Reason)
+	assert.Equal(t, alert.BulkItemNotFound, result.Items[3].Status)
+
+	// 未指派的告警由操作者处理，已指派的保留处理人；两组分别批量更新
+	assert.Equal(t, 2, store.batches)
+	assert.Equal(t, "alice", store.alerts[1].Handler)
+	assert.Equal(t, "bob", store.alerts[2].Handler)
+	assert.Equal(t, model.AlertStatusAcknowledged, store.alerts[2].Status)
+	assert.Equal(t, now, *store.alerts[1].HandleTime)
+	assert.Equal(t, "[2024-05-01 12:00:00 alice] db failover", store.alerts[1].HandleNote)
+	assert.Empty(t, store.alerts[3].HandleNote)
+
+	require.Len(t, store.records, 2)
+	assert.Equal(t, alert.ActivityStatusChange, store.records[0].Type)
+	assert.Equal(t, "状态 new → acknowledged：db failover", store.records[0].Message)
+
+	require.Len(t, store.audits, 1)
+	assert.Equal(t, actor, store.audits[0].Actor)
+	assert.Equal(t, []uint{1, 2}, store.audits[0].AlertIDs)
+}
+
+func TestBulkService_DryRunAndLimit(t *testing.T) {
+	service, store, _ := newBulkFixture(t)
+	ctx := context.Background()
+	query := alert.BulkSelector{Query: "status!=resolved"}
+	store.alerts[5] = &model.Alert{ID: 5, Status: model.AlertStatusNew}
+	store.alerts[6] = &model.Alert{ID: 6, Status: model.AlertStatusNew}
+
+	// 5 条未恢复告警超过上限 4
+	result, err := service.Execute(ctx, &alert.BulkRequest{Action: alert.BulkActionResolve, Selector: query, DryRun: true}, alert.BulkActor{})
+	require.NoError(t, err)
+	assert.True(t, result.ExceedsLimit)
+	assert.Equal(t, int64(5), result.Matched)
+	assert.Empty(t, result.Items)
+
+	_, err = service.Execute(ctx, &alert.BulkRequest{Action: alert.BulkActionResolve, Selector: query}, alert.BulkActor{})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+
+	result, err = service.Execute(ctx, &alert.BulkRequest{
+		Action: alert.BulkActionResolve, Selector: alert.BulkSelector{Query: "status=new"}, DryRun: true,
+	}, alert.BulkActor{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Succeeded)
+	assert.Equal(t, alert.BulkItemPending, result.Items[0].Status)
+	assert.Equal(t, 0, store.batches)
+	assert.Empty(t, store.audits)
+
+	_, err = service.Execute(ctx, &alert.BulkRequest{Action: alert.BulkActionResolve}, alert.BulkActor{})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+
+	_, err = service.Execute(ctx, &alert.BulkRequest{
+		Action: alert.BulkActionAssign, Selector: alert.BulkSelector{IDs: []uint{1}},
+	}, alert.BulkActor{})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+}
+
+func TestBulkService_Reanalyze(t *testing.T) {
+	service, store, _ := newBulkFixture(t)
+
+	result, err := service.Execute(context.Background(), &alert.BulkRequest{
+		Action:   alert.BulkActionReanalyze,
+		Selector: alert.BulkSelector{IDs: []uint{1, 3}},
+	}, alert.BulkActor{Username: "alice"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "task-1", result.Items[0].TaskID)
+	assert.Equal(t, alert.BulkItemFailed, result.Items[1].Status)
+	assert.Equal(t, "dify unavailable", result.Items[1].Reason)
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 0, store.batches)
+	assert.Equal(t, []uint{1, 3}, store.audits[0].AlertIDs)
+}
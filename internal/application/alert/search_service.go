@@ -0,0 +1,196 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// SearchService 告警搜索服务实现
+type SearchService struct {
+	repo     alert.SearchRepository
+	searches alert.SavedSearchRepository
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewSearchService 创建告警搜索服务
+func NewSearchService(repo alert.SearchRepository, searches alert.SavedSearchRepository, logger *zap.Logger) *SearchService {
+	return &SearchService{
+		repo:     repo,
+		searches: searches,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Search 按查询语言搜索告警
+func (s *SearchService) Search(ctx context.Context, req *alert.SearchRequest) (*alert.SearchResult, error) {
+	expr, err := alert.ParseQueryAt(req.Query, s.now())
+	if err != nil {
+		return nil, errors.NewValidationError("INVALID_QUERY", err.Error())
+	}
+	sort, err := alert.ParseSort(req.Sort)
+	if err != nil {
+		return nil, errors.NewValidationError("INVALID_SORT", err.Error())
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = alert.DefaultSearchLimit
+	}
+	if limit > alert.MaxSearchLimit {
+		limit = alert.MaxSearchLimit
+	}
+
+	query := &alert.SearchQuery{Expr: expr, Sort: sort, Limit: limit + 1}
+	if req.Cursor != "" {
+		if query.After, err = alert.DecodeCursor(req.Cursor, sort); err != nil {
+			return nil, errors.NewValidationError("INVALID_CURSOR", err.Error())
+		}
+	}
+
+	alerts, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to search alerts", err)
+	}
+
+	result := &alert.SearchResult{
+		Alerts: make([]*model.AlertResponse, 0, len(alerts)),
+		Sort:   sort.String(),
+	}
+	if expr != nil {
+		result.Query = expr.String()
+	}
+	if len(alerts) > limit {
+		alerts = alerts[:limit]
+		result.HasMore = true
+		result.NextCursor = alert.NewCursor(sort, alerts[limit-1]).Encode()
+	}
+	for _, a := range alerts {
+		result.Alerts = append(result.Alerts, a.ToResponse())
+	}
+	return result, nil
+}
+
+// ListSavedSearches 获取用户保存的搜索
+func (s *SearchService) ListSavedSearches(ctx context.Context, owner string) ([]*alert.SavedSearch, error) {
+	searches, err := s.searches.ListByOwner(ctx, owner)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list saved searches", err)
+	}
+	return searches, nil
+}
+
+// CreateSavedSearch 保存搜索，同一用户下名称唯一
+func (s *SearchService) CreateSavedSearch(ctx context.Context, owner string, req *alert.SavedSearchRequest) (*alert.SavedSearch, error) {
+	if err := s.validateSavedSearch(req); err != nil {
+		return nil, err
+	}
+	existing, err := s.searches.GetByName(ctx, owner, req.Name)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get saved search", err)
+	}
+	if existing != nil {
+		return nil, errors.NewConflictError(fmt.Sprintf("Saved search %q already exists", req.Name))
+	}
+
+	now := s.now()
+	search := &alert.SavedSearch{
+		Owner:       owner,
+		Name:        req.Name,
+		Description: req.Description,
+		Query:       req.Query,
+		Sort:        req.Sort,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.searches.Create(ctx, search); err != nil {
+		return nil, errors.NewInternalError("failed to create saved search", err)
+	}
+	return search, nil
+}
+
+// UpdateSavedSearch 更新保存的搜索
+func (s *SearchService) UpdateSavedSearch(ctx context.Context, owner string, id uint, req *alert.SavedSearchRequest) (*alert.SavedSearch, error) {
+	search, err := s.getOwned(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateSavedSearch(req); err != nil {
+		return nil, err
+	}
+	if req.Name != search.Name {
+		existing, err := s.searches.GetByName(ctx, owner, req.Name)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to get saved search", err)
+		}
+		if existing != nil {
+			return nil, errors.NewConflictError(fmt.Sprintf("Saved search %q already exists", req.Name))
+		}
+	}
+
+	search.Name = req.Name
+	search.Description = req.Description
+	search.Query = req.Query
+	search.Sort = req.Sort
+	search.UpdatedAt = s.now()
+	if err := s.searches.Update(ctx, search); err != nil {
+		return nil, errors.NewInternalError("failed to update saved search", err)
+	}
+	return search, nil
+}
+
+// DeleteSavedSearch 删除保存的搜索
+func (s *SearchService) DeleteSavedSearch(ctx context.Context, owner string, id uint) error {
+	if _, err := s.getOwned(ctx, owner, id); err != nil {
+		return err
+	}
+	if err := s.searches.Delete(ctx, id); err != nil {
+		return errors.NewInternalError("failed to delete saved search", err)
+	}
+	return nil
+}
+
+// RunSavedSearch 执行保存的搜索
+func (s *SearchService) RunSavedSearch(ctx context.Context, owner string, id uint, cursor string, limit int) (*alert.SearchResult, error) {
+	search, err := s.getOwned(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Search(ctx, &alert.SearchRequest{Query: search.Query, Sort: search.Sort, Cursor: cursor, Limit: limit})
+}
+
+// getOwned 获取用户自己的保存搜索，其他用户的搜索视为不存在
+func (s *SearchService) getOwned(ctx context.Context, owner string, id uint) (*alert.SavedSearch, error) {
+	search, err := s.searches.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get saved search", err)
+	}
+	if search == nil || search.Owner != owner {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("saved search %d", id))
+	}
+	return search, nil
+}
+
+// validateSavedSearch 保存前校验名称、查询与排序
+func (s *SearchService) validateSavedSearch(req *alert.SavedSearchRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.NewValidationError("INVALID_NAME", "Saved search name is required")
+	}
+	if _, err := alert.ParseQueryAt(req.Query, s.now()); err != nil {
+		return errors.NewValidationError("INVALID_QUERY", err.Error())
+	}
+	if _, err := alert.ParseSort(req.Sort); err != nil {
+		return errors.NewValidationError("INVALID_SORT", err.Error())
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"alert_agent/internal/domain/analysis"
 	"alert_agent/internal/pkg/logger"
+	sharedErrors "alert_agent/internal/shared/errors"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -15,7 +16,7 @@ import (
 
 // AnalysisServiceImpl 分析服务实现
 type AnalysisServiceImpl struct {
-	taskQueue       analysis.AnalysisTaskQueue
+	taskQueue       analysis.ReliableTaskQueue
 	taskRepo        analysis.AnalysisTaskRepository
 	resultRepo      analysis.AnalysisResultRepository
 	progressTracker analysis.AnalysisProgressTracker
@@ -23,12 +24,14 @@ type AnalysisServiceImpl struct {
 	notifier        analysis.AnalysisNotifier
 	metricsCollector analysis.AnalysisMetricsCollector
 	retryPolicy     analysis.AnalysisRetryPolicy
+	eventBus        analysis.AnalysisEventBus
+	autoscalers     analysis.AutoscalerStatusStore
 	logger          *zap.Logger
 }
 
-// NewAnalysisService 创建分析服务实例
+// NewAnalysisService 创建分析服务实例，eventBus 为 nil 时不支持流式订阅，autoscalers 为 nil 时不返回 worker 进程的扩缩容状态
 func NewAnalysisService(
-	taskQueue analysis.AnalysisTaskQueue,
+	taskQueue analysis.ReliableTaskQueue,
 	taskRepo analysis.AnalysisTaskRepository,
 	resultRepo analysis.AnalysisResultRepository,
 	progressTracker analysis.AnalysisProgressTracker,
@@ -36,6 +39,8 @@ func NewAnalysisService(
 	notifier analysis.AnalysisNotifier,
 	metricsCollector analysis.AnalysisMetricsCollector,
 	retryPolicy analysis.AnalysisRetryPolicy,
+	eventBus analysis.AnalysisEventBus,
+	autoscalers analysis.AutoscalerStatusStore,
 ) analysis.AnalysisService {
 	return &AnalysisServiceImpl{
 		taskQueue:       taskQueue,
@@ -46,6 +51,8 @@ func NewAnalysisService(
 		notifier:        notifier,
 		metricsCollector: metricsCollector,
 		retryPolicy:     retryPolicy,
+		eventBus:        eventBus,
+		autoscalers:     autoscalers,
 		logger:          logger.L.Named("analysis-service"),
 	}
 }
@@ -75,9 +82,33 @@ func (s *AnalysisServiceImpl) SubmitAnalysis(ctx context.Context, request *analy
 		}
 	}
 
+	// 占用幂等键，并发的重复提交合并为同一任务
+	taskID := uuid.New().String()
+	owner, err := s.taskQueue.ClaimIdempotencyKey(ctx, analysis.IdempotencyKey(alertIDStr, request.Type), taskID)
+	if err != nil {
+		s.logger.Error("Failed to claim idempotency key", zap.Error(err))
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if owner != taskID {
+		s.logger.Info("Duplicate analysis submission collapsed",
+			zap.String("task_id", owner),
+			zap.String("alert_id", alertIDStr),
+			zap.String("type", string(request.Type)))
+		if existing, err := s.taskRepo.GetByID(ctx, owner); err == nil && existing != nil {
+			return existing, nil
+		}
+		// 任务仍在由并发请求创建
+		return &analysis.AnalysisTask{
+			ID:      owner,
+			AlertID: alertIDStr,
+			Type:    request.Type,
+			Status:  analysis.AnalysisStatusPending,
+		}, nil
+	}
+
 	// 创建新任务
 	task := &analysis.AnalysisTask{
-		ID:         uuid.New().String(),
+		ID:         taskID,
 		AlertID:    alertIDStr,
 		Type:       request.Type,
 		Status:     analysis.AnalysisStatusPending,
@@ -107,6 +138,7 @@ func (s *AnalysisServiceImpl) SubmitAnalysis(ctx context.Context, request *analy
 	// 保存任务到数据库
 	if err := s.taskRepo.Create(ctx, task); err != nil {
 		s.logger.Error("Failed to create task", zap.Error(err))
+		s.releaseTask(ctx, taskID)
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
 
@@ -116,6 +148,7 @@ func (s *AnalysisServiceImpl) SubmitAnalysis(ctx context.Context, request *analy
 		// 更新任务状态为失败
 		task.Status = analysis.AnalysisStatusFailed
 		s.taskRepo.Update(ctx, task)
+		s.releaseTask(ctx, taskID)
 		return nil, fmt.Errorf("failed to push task to queue: %w", err)
 	}
 
@@ -131,6 +164,56 @@ func (s *AnalysisServiceImpl) SubmitAnalysis(ctx context.Context, request *analy
 	return task, nil
 }
 
+// releaseTask 提交失败时移除任务并释放幂等键
+func (s *AnalysisServiceImpl) releaseTask(ctx context.Context, taskID string) {
+	if err := s.taskQueue.Remove(ctx, taskID); err != nil {
+		s.logger.Warn("Failed to release idempotency key",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+	}
+}
+
+// ListDeadLetters 获取死信任务
+func (s *AnalysisServiceImpl) ListDeadLetters(ctx context.Context, limit int64) ([]*analysis.DeadLetterTask, error) {
+	deadLetters, err := s.taskQueue.ListDeadLetters(ctx, limit)
+	if err != nil {
+		s.logger.Error("Failed to list dead letters", zap.Error(err))
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
+
+// RequeueDeadLetter 重新投递死信任务，任务状态重置为待处理
+func (s *AnalysisServiceImpl) RequeueDeadLetter(ctx context.Context, taskID string) error {
+	if err := s.taskQueue.RequeueDeadLetter(ctx, taskID); err != nil {
+		if sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeNotFound) {
+			return err
+		}
+		s.logger.Error("Failed to requeue dead letter",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+		return fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil || task == nil {
+		s.logger.Warn("Requeued dead letter without task record", zap.String("task_id", taskID))
+		return nil
+	}
+	task.Status = analysis.AnalysisStatusPending
+	task.UpdatedAt = time.Now()
+	task.StartedAt = nil
+	task.CompletedAt = nil
+	if err := s.taskRepo.Update(ctx, task); err != nil {
+		s.logger.Warn("Failed to reset requeued task status",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Dead letter requeued", zap.String("task_id", taskID))
+	return nil
+}
+
 // GetAnalysisResult 获取分析结果
 func (s *AnalysisServiceImpl) GetAnalysisResult(ctx context.Context, taskID string) (*analysis.AnalysisResult, error) {
 	result, err := s.resultRepo.GetByTaskID(ctx, taskID)
@@ -157,6 +240,74 @@ func (s *AnalysisServiceImpl) GetAnalysisProgress(ctx context.Context, taskID st
 	return progress, nil
 }
 
+// StreamAnalysis 订阅任务的进度与模型输出
+func (s *AnalysisServiceImpl) StreamAnalysis(ctx context.Context, taskID string) (<-chan *analysis.StreamEvent, error) {
+	if s.eventBus == nil {
+		return nil, sharedErrors.NewValidationError("STREAM_DISABLED", "Analysis streaming is not enabled")
+	}
+	task, err := s.taskRepo.GetByID(ctx, taskID)
+	if err != nil || task == nil {
+		return nil, sharedErrors.NewNotFoundError("analysis task")
+	}
+
+	// 先订阅再读取当前进度，两者之间的事件不会遗漏
+	streamCtx, cancel := context.WithCancel(ctx)
+	events, err := s.eventBus.Subscribe(streamCtx, taskID)
+	if err != nil {
+		cancel()
+		s.logger.Error("Failed to subscribe analysis events",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to subscribe analysis events: %w", err)
+	}
+	snapshot, err := s.progressTracker.GetProgress(streamCtx, taskID)
+	if err != nil {
+		// 进度已过期或尚未开始处理，按任务状态推断
+		snapshot = progressFromTask(task)
+	}
+
+	out := make(chan *analysis.StreamEvent, 1)
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		event := &analysis.StreamEvent{TaskID: taskID, Type: analysis.StreamEventProgress, Progress: snapshot}
+		for {
+			select {
+			case out <- event:
+			case <-streamCtx.Done():
+				return
+			}
+			if event.IsTerminal() {
+				return
+			}
+			var ok bool
+			if event, ok = <-events; !ok {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// progressFromTask 根据任务状态生成进度快照
+func progressFromTask(task *analysis.AnalysisTask) *analysis.AnalysisProgress {
+	progress := &analysis.AnalysisProgress{TaskID: task.ID, UpdatedAt: task.UpdatedAt}
+	switch task.Status {
+	case analysis.AnalysisStatusCompleted:
+		progress.Stage, progress.Progress = analysis.ProgressStageCompleted, 100
+	case analysis.AnalysisStatusFailed:
+		progress.Stage = analysis.ProgressStageFailed
+	case analysis.AnalysisStatusCancelled:
+		progress.Stage = analysis.ProgressStageCancelled
+	case analysis.AnalysisStatusProcessing:
+		progress.Stage = analysis.ProgressStageAnalyzing
+	default:
+		progress.Stage = analysis.ProgressStagePending
+	}
+	return progress
+}
+
 // CancelAnalysis 取消分析任务
 func (s *AnalysisServiceImpl) CancelAnalysis(ctx context.Context, taskID string) error {
 	// 获取任务
@@ -195,6 +346,25 @@ func (s *AnalysisServiceImpl) CancelAnalysis(ctx context.Context, taskID string)
 			zap.Error(err))
 	}
 
+	// 通知订阅方任务已取消
+	if s.eventBus != nil {
+		event := &analysis.StreamEvent{
+			TaskID: taskID,
+			Type:   analysis.StreamEventProgress,
+			Progress: &analysis.AnalysisProgress{
+				TaskID:    taskID,
+				Stage:     analysis.ProgressStageCancelled,
+				Message:   "任务已取消",
+				UpdatedAt: time.Now(),
+			},
+		}
+		if err := s.eventBus.Publish(ctx, event); err != nil {
+			s.logger.Warn("Failed to publish cancel event",
+				zap.String("task_id", taskID),
+				zap.Error(err))
+		}
+	}
+
 	s.logger.Info("Analysis task cancelled", zap.String("task_id", taskID))
 	return nil
 }
@@ -231,7 +401,7 @@ func (s *AnalysisServiceImpl) RetryAnalysis(ctx context.Context, taskID string)
 		return fmt.Errorf("failed to update task for retry: %w", err)
 	}
 
-	// 重新推送到队列
+	// 重新推送到队列，队列重置投递次数并移出死信
 	if err := s.taskQueue.Push(ctx, task); err != nil {
 		s.logger.Error("Failed to push retry task to queue", 
 			zap.String("task_id", taskID),
@@ -345,6 +515,24 @@ func (s *AnalysisServiceImpl) GetWorkerStatuses(ctx context.Context) ([]*analysi
 	return statuses, nil
 }
 
+// GetWorkerPoolStatus 获取本进程的工作器与各 worker 进程上报的扩缩容状态
+func (s *AnalysisServiceImpl) GetWorkerPoolStatus(ctx context.Context) (*analysis.WorkerPoolStatus, error) {
+	status := &analysis.WorkerPoolStatus{
+		Workers:     s.workerManager.GetWorkerStatuses(),
+		Autoscalers: []*analysis.AutoscalerStatus{},
+	}
+	if s.autoscalers == nil {
+		return status, nil
+	}
+	autoscalers, err := s.autoscalers.List(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list autoscaler statuses", zap.Error(err))
+		return nil, fmt.Errorf("failed to list autoscaler statuses: %w", err)
+	}
+	status.Autoscalers = autoscalers
+	return status, nil
+}
+
 // HealthCheck 健康检查
 func (s *AnalysisServiceImpl) HealthCheck(ctx context.Context) error {
 	// 检查队列连接
@@ -300,9 +300,15 @@ func (s *DifyAnalysisServiceImpl) BuildAlertContext(ctx context.Context, alertID
 	
 	// 构建指标上下文
 	if options.IncludeMetrics {
-		// 这里应该调用实际的指标查询
-		// metrics, _ := s.metricsRepository.GetMetrics(ctx, alertID, options.MetricsTimeRange)
-		// context.Metrics = metrics
+		// 使用告警丰富阶段采集的指标快照
+		if snapshot, err := alertInfo.GetMetricSnapshot(); err != nil {
+			s.logger.Warn("Failed to parse metric snapshot", zap.Uint("alert_id", alertID), zap.Error(err))
+		} else if snapshot != nil {
+			context.Metrics = map[string]interface{}{
+				"snapshot": snapshot,
+				"chart":    snapshot.TextChart(),
+			}
+		}
 	}
 	
 	// 构建日志上下文
@@ -744,19 +750,27 @@ func (s *DifyAnalysisServiceImpl) convertAlertToMap(alertInfo interface{}) map[s
 }
 
 func (s *DifyAnalysisServiceImpl) buildRootCausePrompt(context *analysis.AlertContext) string {
-	return "请分析这个告警的根本原因，并提供详细的分析过程和结论。"
+	return withMetricChart("请分析这个告警的根本原因，并提供详细的分析过程和结论。", context)
 }
 
 func (s *DifyAnalysisServiceImpl) buildImpactPrompt(context *analysis.AlertContext) string {
-	return "请分析这个告警的影响范围和严重程度，包括对业务和系统的潜在影响。"
+	return withMetricChart("请分析这个告警的影响范围和严重程度，包括对业务和系统的潜在影响。", context)
 }
 
 func (s *DifyAnalysisServiceImpl) buildRecommendationPrompt(context *analysis.AlertContext) string {
-	return "请基于告警信息和上下文，提供具体的解决建议和预防措施。"
+	return withMetricChart("请基于告警信息和上下文，提供具体的解决建议和预防措施。", context)
 }
 
 func (s *DifyAnalysisServiceImpl) buildClassificationPrompt(context *analysis.AlertContext) string {
-	return "请对这个告警进行分类，包括告警类型、严重级别、影响范围等。"
+	return withMetricChart("请对这个告警进行分类，包括告警类型、严重级别、影响范围等。", context)
+}
+
+// withMetricChart 在提示词后附加指标快照文本图表
+func withMetricChart(prompt string, context *analysis.AlertContext) string {
+	if chart, ok := context.Metrics["chart"].(string); ok && chart != "" {
+		return prompt + "\n\n" + chart
+	}
+	return prompt
 }
 
 func (s *DifyAnalysisServiceImpl) extractRootCause(answer string) string {
@@ -0,0 +1,215 @@
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+)
+
+// EvalCase 标注的评测样本
+type EvalCase struct {
+	ID       string                `json:"id"`
+	Type     analysis.AnalysisType `json:"type"`
+	Alert    *model.Alert          `json:"alert"`
+	Expected EvalExpectation       `json:"expected"`
+}
+
+// EvalExpectation 样本的标注结果，只比较填写了的字段
+type EvalExpectation struct {
+	Category  string   `json:"category,omitempty"`
+	Severity  string   `json:"severity,omitempty"`
+	Priority  string   `json:"priority,omitempty"`
+	RootCause []string `json:"root_cause,omitempty"` // 根因关键词，任一根因描述包含任一关键词即正确
+}
+
+// Match 比较分析结果与标注，返回是否正确与不一致的原因
+func (e EvalExpectation) Match(result *analysis.AnalysisResult) (bool, string) {
+	output := result.Structured
+	if output == nil {
+		return false, "no structured output"
+	}
+	if e.Category != "" && output.Category != e.Category {
+		return false, fmt.Sprintf("category %q, expected %q", output.Category, e.Category)
+	}
+	if e.Severity != "" && output.Severity != e.Severity {
+		return false, fmt.Sprintf("severity %q, expected %q", output.Severity, e.Severity)
+	}
+	if e.Priority != "" && output.Priority != e.Priority {
+		return false, fmt.Sprintf("priority %q, expected %q", output.Priority, e.Priority)
+	}
+	if len(e.RootCause) > 0 && !rootCauseMatches(output, e.RootCause) {
+		return false, fmt.Sprintf("root cause does not mention any of %v", e.RootCause)
+	}
+	return true, ""
+}
+
+// rootCauseMatches 根因描述或摘要是否包含任一关键词，不区分大小写
+func rootCauseMatches(output *analysis.StructuredOutput, keywords []string) bool {
+	texts := []string{output.Summary}
+	for _, cause := range output.RootCauses {
+		texts = append(texts, cause.Description)
+	}
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, keyword := range keywords {
+			if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadEvalCases 读取 JSONL 格式的评测集，跳过空行与 # 开头的注释
+func LoadEvalCases(r io.Reader) ([]*EvalCase, error) {
+	var cases []*EvalCase
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var c EvalCase
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if c.Alert == nil {
+			return nil, fmt.Errorf("line %d: alert is required", line)
+		}
+		if c.Type == "" {
+			c.Type = analysis.AnalysisTypeRootCause
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		cases = append(cases, &c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// EvalVariant 参与对比的模板与模型组合
+type EvalVariant struct {
+	Name             string
+	Engine           analysis.AnalysisEngine
+	PricePer1KTokens float64 // 每千 token 价格，用于估算成本
+}
+
+// EvalFailure 分析失败或结果不正确的样本
+type EvalFailure struct {
+	CaseID string `json:"case_id"`
+	Reason string `json:"reason"`
+}
+
+// EvalReport 单个组合的评测结果
+type EvalReport struct {
+	Variant          string        `json:"variant"`
+	Cases            int           `json:"cases"`
+	Succeeded        int           `json:"succeeded"` // 得到结构化结果的样本数
+	Correct          int           `json:"correct"`
+	Accuracy         float64       `json:"accuracy"` // 正确数占全部样本的比例
+	AvgLatency       time.Duration `json:"avg_latency"`
+	P95Latency       time.Duration `json:"p95_latency"`
+	PromptTokens     int64         `json:"prompt_tokens"`
+	CompletionTokens int64         `json:"completion_tokens"`
+	TotalTokens      int64         `json:"total_tokens"`
+	Cost             float64       `json:"cost"`
+	Failures         []EvalFailure `json:"failures,omitempty"`
+}
+
+// RunEvaluation 依次用每个组合分析全部样本，组合之间串行执行以免互相影响延迟
+func RunEvaluation(ctx context.Context, cases []*EvalCase, variants []*EvalVariant) []*EvalReport {
+	reports := make([]*EvalReport, 0, len(variants))
+	for _, variant := range variants {
+		reports = append(reports, evaluateVariant(ctx, cases, variant))
+	}
+	return reports
+}
+
+func evaluateVariant(ctx context.Context, cases []*EvalCase, variant *EvalVariant) *EvalReport {
+	report := &EvalReport{Variant: variant.Name, Cases: len(cases)}
+	latencies := make([]time.Duration, 0, len(cases))
+	for _, c := range cases {
+		if ctx.Err() != nil {
+			report.Failures = append(report.Failures, EvalFailure{CaseID: c.ID, Reason: ctx.Err().Error()})
+			continue
+		}
+		start := time.Now()
+		result, err := variant.Engine.Analyze(ctx, &analysis.AnalysisRequest{Alert: c.Alert, Type: c.Type})
+		latencies = append(latencies, time.Since(start))
+		if err != nil {
+			report.Failures = append(report.Failures, EvalFailure{CaseID: c.ID, Reason: err.Error()})
+			continue
+		}
+		report.Succeeded++
+		addTokens(report, result.Metadata["tokens"])
+		if ok, reason := c.Expected.Match(result); ok {
+			report.Correct++
+		} else {
+			report.Failures = append(report.Failures, EvalFailure{CaseID: c.ID, Reason: reason})
+		}
+	}
+
+	if report.Cases > 0 {
+		report.Accuracy = float64(report.Correct) / float64(report.Cases)
+	}
+	if len(latencies) > 0 {
+		var total time.Duration
+		for _, latency := range latencies {
+			total += latency
+		}
+		report.AvgLatency = total / time.Duration(len(latencies))
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.P95Latency = latencies[(len(latencies)*95+99)/100-1]
+	}
+	report.Cost = float64(report.TotalTokens) / 1000 * variant.PricePer1KTokens
+	return report
+}
+
+// addTokens 累加结果元数据中的 token 用量，兼容引擎返回的结构体与数据库读出的 map
+func addTokens(report *EvalReport, value interface{}) {
+	if value == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	var tokens struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+		TotalTokens      int64 `json:"total_tokens"`
+	}
+	if json.Unmarshal(data, &tokens) != nil {
+		return
+	}
+	report.PromptTokens += tokens.PromptTokens
+	report.CompletionTokens += tokens.CompletionTokens
+	report.TotalTokens += tokens.TotalTokens
+}
+
+// WriteEvalReports 以表格输出各组合的对比结果
+func WriteEvalReports(w io.Writer, reports []*EvalReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIANT\tCASES\tOK\tCORRECT\tACCURACY\tAVG LATENCY\tP95 LATENCY\tTOKENS\tCOST")
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f%%\t%s\t%s\t%d\t%.4f\n",
+			r.Variant, r.Cases, r.Succeeded, r.Correct, r.Accuracy*100,
+			r.AvgLatency.Round(time.Millisecond), r.P95Latency.Round(time.Millisecond), r.TotalTokens, r.Cost)
+	}
+	return tw.Flush()
+}
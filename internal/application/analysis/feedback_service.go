@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/feature"
+	"alert_agent/internal/pkg/logger"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// defaultFeedbackWindow 计算准确率的默认时间窗口
+const defaultFeedbackWindow = 24 * time.Hour
+
+// MaturityRecorder 接收 AI 指标，由 feature.AIMaturityEvaluator 实现
+type MaturityRecorder interface {
+	RecordMetrics(featureName feature.FeatureName, metrics feature.AIMetrics)
+}
+
+// FeedbackService 分析反馈服务
+type FeedbackService struct {
+	repo     analysis.FeedbackRepository
+	results  analysis.AnalysisResultRepository
+	recorder MaturityRecorder
+	window   time.Duration
+	logger   *zap.Logger
+}
+
+// NewFeedbackService 创建分析反馈服务，recorder 为 nil 时不更新成熟度指标
+func NewFeedbackService(repo analysis.FeedbackRepository, results analysis.AnalysisResultRepository, recorder MaturityRecorder, window time.Duration) *FeedbackService {
+	if window <= 0 {
+		window = defaultFeedbackWindow
+	}
+	return &FeedbackService{
+		repo:     repo,
+		results:  results,
+		recorder: recorder,
+		window:   window,
+		logger:   logger.L.Named("analysis-feedback"),
+	}
+}
+
+// SubmitFeedback 对分析结果提交评价
+func (s *FeedbackService) SubmitFeedback(ctx context.Context, resultID string, req *analysis.FeedbackRequest) (*analysis.AnalysisFeedback, error) {
+	if err := validateFeedback(req); err != nil {
+		return nil, err
+	}
+	result, err := s.results.GetByID(ctx, resultID)
+	if err != nil || result == nil {
+		return nil, sharedErrors.NewNotFoundError("analysis result")
+	}
+	if result.Status != analysis.AnalysisStatusCompleted {
+		return nil, sharedErrors.NewValidationError("RESULT_NOT_COMPLETED", "Only completed analysis results can be rated")
+	}
+
+	feedback := &analysis.AnalysisFeedback{
+		ResultID:           result.ID,
+		TaskID:             result.TaskID,
+		AlertID:            result.AlertID,
+		Type:               result.Type,
+		Rating:             req.Rating,
+		CorrectedRootCause: strings.TrimSpace(req.CorrectedRootCause),
+		CorrectedCategory:  req.CorrectedCategory,
+		Comment:            strings.TrimSpace(req.Comment),
+		User:               req.User,
+		ModelUsed:          metadataString(result.Metadata, "model_used"),
+		TemplateID:         metadataString(result.Metadata, "template_id"),
+		Confidence:         result.ConfidenceScore,
+		ProcessingTimeMs:   result.ProcessingTime.Milliseconds(),
+		CreatedAt:          time.Now(),
+	}
+	if err := s.repo.Create(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	s.recordMaturity(ctx)
+	return feedback, nil
+}
+
+// ListFeedback 获取分析结果的反馈
+func (s *FeedbackService) ListFeedback(ctx context.Context, resultID string) ([]*analysis.AnalysisFeedback, error) {
+	return s.repo.ListByResult(ctx, resultID)
+}
+
+// GetStats 获取反馈统计
+func (s *FeedbackService) GetStats(ctx context.Context, filter *analysis.FeedbackFilter) (*analysis.FeedbackSummary, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, sharedErrors.NewValidationError("INVALID_RANGE", "feedback range end must be after start")
+	}
+	byModel, err := s.repo.StatsByModel(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return analysis.SummarizeFeedback(byModel), nil
+}
+
+// recordMaturity 按时间窗口内的反馈更新异步分析功能的成熟度指标，失败只记录日志
+func (s *FeedbackService) recordMaturity(ctx context.Context) {
+	if s.recorder == nil {
+		return
+	}
+	byModel, err := s.repo.StatsByModel(ctx, &analysis.FeedbackFilter{From: time.Now().Add(-s.window)})
+	if err != nil {
+		s.logger.Warn("Failed to aggregate feedback for maturity metrics", zap.Error(err))
+		return
+	}
+	overall := analysis.SummarizeFeedback(byModel).Overall
+	if overall.Total == 0 {
+		return
+	}
+	// 只有成功的分析可以评价，成功率按 1 记录
+	s.recorder.RecordMetrics(feature.FeatureAsyncAnalysis, feature.AIMetrics{
+		Accuracy:    overall.Accuracy,
+		Confidence:  overall.AvgConfidence,
+		Latency:     int(overall.AvgLatencyMs),
+		SuccessRate: 1,
+		SampleCount: int(overall.Total),
+	})
+}
+
+// validateFeedback 校验反馈请求
+func validateFeedback(req *analysis.FeedbackRequest) error {
+	if req == nil || !req.Rating.Valid() {
+		return sharedErrors.NewValidationError("INVALID_RATING", "rating must be up or down")
+	}
+	if req.CorrectedCategory != "" && !containsString(analysis.Categories, req.CorrectedCategory) {
+		return sharedErrors.NewValidationError("INVALID_CATEGORY", fmt.Sprintf("unknown category: %s", req.CorrectedCategory))
+	}
+	return nil
+}
+
+// metadataString 读取结果元数据中的字符串字段
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
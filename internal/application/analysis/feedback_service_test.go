@@ -0,0 +1,220 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/feature"
+	"alert_agent/internal/pkg/logger"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryFeedbackRepository 内存反馈仓储，统计逻辑与数据库实现一致
+type memoryFeedbackRepository struct {
+	feedback []*analysis.AnalysisFeedback
+}
+
+func (r *memoryFeedbackRepository) Create(ctx context.Context, feedback *analysis.AnalysisFeedback) error {
+	feedback.ID = uint(len(r.feedback) + 1)
+	r.feedback = append(r.feedback, feedback)
+	return nil
+}
+
+func (r *memoryFeedbackRepository) ListByResult(ctx context.Context, resultID string) ([]*analysis.AnalysisFeedback, error) {
+	var feedback []*analysis.AnalysisFeedback
+	for _, f := range r.feedback {
+		if f.ResultID == resultID {
+			feedback = append(feedback, f)
+		}
+	}
+	return feedback, nil
+}
+
+func (r *memoryFeedbackRepository) StatsByModel(ctx context.Context, filter *analysis.FeedbackFilter) ([]*analysis.FeedbackStats, error) {
+	byModel := make(map[string]*analysis.FeedbackStats)
+	var stats []*analysis.FeedbackStats
+	for _, f := range r.feedback {
+		if f.CreatedAt.Before(filter.From) {
+			continue
+		}
+		s, ok := byModel[f.ModelUsed]
+		if !ok {
+			s = &analysis.FeedbackStats{ModelUsed: f.ModelUsed}
+			byModel[f.ModelUsed] = s
+			stats = append(stats, s)
+		}
+		s.AvgConfidence = (s.AvgConfidence*float64(s.Total) + f.Confidence) / float64(s.Total+1)
+		s.AvgLatencyMs = (s.AvgLatencyMs*float64(s.Total) + float64(f.ProcessingTimeMs)) / float64(s.Total+1)
+		s.Total++
+		if f.Rating == analysis.FeedbackUp {
+			s.Positive++
+		} else {
+			s.Negative++
+		}
+		s.Accuracy = float64(s.Positive) / float64(s.Total)
+	}
+	return stats, nil
+}
+
+// memoryResultRepository 只实现按 ID 查询的结果仓储
+type memoryResultRepository struct {
+	analysis.AnalysisResultRepository
+	results map[string]*analysis.AnalysisResult
+}
+
+func (r *memoryResultRepository) GetByID(ctx context.Context, resultID string) (*analysis.AnalysisResult, error) {
+	if result, ok := r.results[resultID]; ok {
+		return result, nil
+	}
+	return nil, errors.New("not found")
+}
+
+type recordedMetrics struct {
+	feature feature.FeatureName
+	metrics feature.AIMetrics
+}
+
+type fakeMaturityRecorder struct {
+	records []recordedMetrics
+}
+
+func (r *fakeMaturityRecorder) RecordMetrics(featureName feature.FeatureName, metrics feature.AIMetrics) {
+	r.records = append(r.records, recordedMetrics{feature: featureName, metrics: metrics})
+}
+
+func TestFeedbackService_SubmitFeedback(t *testing.T) {
+	logger.L = zap.NewNop()
+	repo := &memoryFeedbackRepository{}
+	recorder := &fakeMaturityRecorder{}
+	results := &memoryResultRepository{results: map[string]*analysis.AnalysisResult{
+		"result-1": {
+			ID: "result-1", TaskID: "task-1", AlertID: "alert-1", Type: analysis.AnalysisTypeRootCause,
+			Status: analysis.AnalysisStatusCompleted, ConfidenceScore: 0.8, ProcessingTime: 2 * time.Second,
+			Metadata: map[string]interface{}{"model_used": "qwen2.5:7b", "template_id": "builtin-root-cause"},
+		},
+		"result-2": {
+			ID: "result-2", Status: analysis.AnalysisStatusCompleted, ConfidenceScore: 0.4, ProcessingTime: time.Second,
+			Metadata: map[string]interface{}{"model_used": "qwen2.5:7b"},
+		},
+		"result-3": {ID: "result-3", Status: analysis.AnalysisStatusFailed},
+	}}
+	service := NewFeedbackService(repo, results, recorder, 0)
+	ctx := context.Background()
+
+	feedback, err := service.SubmitFeedback(ctx, "result-1", &analysis.FeedbackRequest{Rating: analysis.FeedbackUp, User: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "alert-1", feedback.AlertID)
+	assert.Equal(t, "qwen2.5:7b", feedback.ModelUsed)
+	assert.Equal(t, "builtin-root-cause", feedback.TemplateID)
+	assert.Equal(t, int64(2000), feedback.ProcessingTimeMs)
+
+	_, err = service.SubmitFeedback(ctx, "result-2", &analysis.FeedbackRequest{
+		Rating:             analysis.FeedbackDown,
+		CorrectedRootCause: "  磁盘写满导致服务不可用 ",
+		CorrectedCategory:  analysis.CategoryStorage,
+	})
+	require.NoError(t, err)
+
+	// 每次提交都按窗口内的全部反馈更新成熟度指标
+	require.Len(t, recorder.records, 2)
+	last := recorder.records[1]
+	assert.Equal(t, feature.FeatureAsyncAnalysis, last.feature)
+	assert.Equal(t, 0.5, last.metrics.Accuracy)
+	assert.InDelta(t, 0.6, last.metrics.Confidence, 1e-9)
+	assert.Equal(t, 1500, last.metrics.Latency)
+	assert.Equal(t, 2, last.metrics.SampleCount)
+
+	list, err := service.ListFeedback(ctx, "result-2")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "磁盘写满导致服务不可用", list[0].CorrectedRootCause)
+
+	_, err = service.SubmitFeedback(ctx, "result-1", &analysis.FeedbackRequest{Rating: "maybe"})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+	_, err = service.SubmitFeedback(ctx, "result-1", &analysis.FeedbackRequest{Rating: analysis.FeedbackDown, CorrectedCategory: "weather"})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+	_, err = service.SubmitFeedback(ctx, "result-3", &analysis.FeedbackRequest{Rating: analysis.FeedbackUp})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+	_, err = service.SubmitFeedback(ctx, "missing", &analysis.FeedbackRequest{Rating: analysis.FeedbackUp})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeNotFound))
+	assert.Len(t, recorder.records, 2)
+
+	summary, err := service.GetStats(ctx, &analysis.FeedbackFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), summary.Overall.Total)
+	_, err = service.GetStats(ctx, &analysis.FeedbackFilter{From: time.Now(), To: time.Now().Add(-time.Hour)})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+}
+
+// scriptedEngine 按告警名返回预设结果的分析引擎
+type scriptedEngine struct {
+	analysis.AnalysisEngine
+	outputs map[string]*analysis.StructuredOutput
+}
+
+func (e *scriptedEngine) Analyze(ctx context.Context, request *analysis.AnalysisRequest) (*analysis.AnalysisResult, error) {
+	output, ok := e.outputs[request.Alert.Name]
+	if !ok {
+		return nil, errors.New("model unavailable")
+	}
+	return &analysis.AnalysisResult{
+		Structured: output,
+		Metadata: map[string]interface{}{"tokens": map[string]int{
+			"prompt_tokens": 800, "completion_tokens": 200, "total_tokens": 1000,
+		}},
+	}, nil
+}
+
+func TestRunEvaluation(t *testing.T) {
+	dataset := `# 标注样本
+{"id":"disk","alert":{"name":"DiskFull"},"expected":{"category":"storage","root_cause":["磁盘"]}}
+{"alert":{"name":"HighLatency"},"expected":{"category":"performance"}}
+`
+	cases, err := LoadEvalCases(strings.NewReader(dataset))
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, "line-3", cases[1].ID)
+	assert.Equal(t, analysis.AnalysisTypeRootCause, cases[1].Type)
+
+	_, err = LoadEvalCases(strings.NewReader(`{"id":"no-alert"}`))
+	assert.Error(t, err)
+
+	good := &scriptedEngine{outputs: map[string]*analysis.StructuredOutput{
+		"DiskFull":    {Category: analysis.CategoryStorage, RootCauses: []analysis.RootCause{{Description: "磁盘空间不足"}}},
+		"HighLatency": {Category: analysis.CategoryPerformance},
+	}}
+	flaky := &scriptedEngine{outputs: map[string]*analysis.StructuredOutput{
+		"DiskFull": {Category: analysis.CategoryNetwork},
+	}}
+	reports := RunEvaluation(context.Background(), cases, []*EvalVariant{
+		{Name: "good", Engine: good, PricePer1KTokens: 0.002},
+		{Name: "flaky", Engine: flaky},
+	})
+	require.Len(t, reports, 2)
+
+	assert.Equal(t, 2, reports[0].Correct)
+	assert.Equal(t, 1.0, reports[0].Accuracy)
+	assert.Equal(t, int64(2000), reports[0].TotalTokens)
+	assert.Equal(t, int64(1600), reports[0].PromptTokens)
+	assert.InDelta(t, 0.004, reports[0].Cost, 1e-9)
+	assert.Empty(t, reports[0].Failures)
+
+	assert.Equal(t, 1, reports[1].Succeeded)
+	assert.Equal(t, 0, reports[1].Correct)
+	assert.Equal(t, 0.0, reports[1].Accuracy)
+	assert.Len(t, reports[1].Failures, 2)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteEvalReports(&out, reports))
+	assert.Contains(t, out.String(), "good")
+	assert.Contains(t, out.String(), "100.0%")
+}
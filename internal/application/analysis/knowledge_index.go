@@ -0,0 +1,212 @@
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// maxEmbeddingChars 生成向量时使用的最大正文长度
+const maxEmbeddingChars = 4000
+
+// KnowledgeIndexService 维护知识库与已解决告警的向量索引，并为分析检索参考资料
+// 每个进程维护自己的索引，启动时从快照恢复，之后按间隔增量同步
+type KnowledgeIndexService struct {
+	source   analysis.KnowledgeSource
+	index    analysis.VectorIndex
+	embedder gateway.EmbeddingProvider
+	config   analysis.RetrievalConfig
+	logger   *zap.Logger
+	syncMu   sync.Mutex
+	cursor   analysis.KnowledgeCursor // 已同步到的位置
+}
+
+// NewKnowledgeIndexService 创建知识索引服务
+func NewKnowledgeIndexService(
+	source analysis.KnowledgeSource,
+	index analysis.VectorIndex,
+	embedder gateway.EmbeddingProvider,
+	config analysis.RetrievalConfig,
+	logger *zap.Logger,
+) *KnowledgeIndexService {
+	defaults := analysis.DefaultRetrievalConfig()
+	if config.TopK <= 0 {
+		config.TopK = defaults.TopK
+	}
+	if config.SnippetLength <= 0 {
+		config.SnippetLength = defaults.SnippetLength
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = defaults.SyncInterval
+	}
+	if config.SyncBatchSize <= 0 {
+		config.SyncBatchSize = defaults.SyncBatchSize
+	}
+	return &KnowledgeIndexService{
+		source:   source,
+		index:    index,
+		embedder: embedder,
+		config:   config,
+		logger:   logger.Named("knowledge-index"),
+	}
+}
+
+// Run 按间隔同步索引并保存快照，直到上下文取消
+func (s *KnowledgeIndexService) Run(ctx context.Context) error {
+	s.logger.Info("Knowledge index sync started",
+		zap.Int("documents", s.index.Len()),
+		zap.Duration("interval", s.config.SyncInterval))
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		indexed, removed, err := s.Sync(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to sync knowledge index", zap.Error(err))
+		}
+		if indexed > 0 || removed > 0 {
+			s.logger.Info("Knowledge index synced",
+				zap.Int("indexed", indexed),
+				zap.Int("removed", removed),
+				zap.Int("documents", s.index.Len()))
+		}
+		if err := s.index.Persist(); err != nil {
+			s.logger.Error("Failed to persist knowledge index", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Knowledge index sync stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync 清理已删除的文档并为新增或变更的文档生成向量
+// 正文未变化的文档不重新生成向量，因此重启后的首次全量扫描开销很小
+func (s *KnowledgeIndexService) Sync(ctx context.Context) (indexed int, removed int, err error) {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	ids, err := s.source.ListDocumentIDs(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	current := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		current[id] = struct{}{}
+	}
+	for _, id := range s.index.IDs() {
+		if _, ok := current[id]; !ok && s.index.Delete(id) {
+			removed++
+		}
+	}
+
+	for {
+		documents, err := s.source.ListDocuments(ctx, s.cursor, s.config.SyncBatchSize)
+		if err != nil {
+			return indexed, removed, err
+		}
+		for _, document := range documents {
+			changed, err := s.upsert(ctx, document)
+			if err != nil {
+				return indexed, removed, fmt.Errorf("failed to index %s: %w", document.ID, err)
+			}
+			if changed {
+				indexed++
+			}
+			s.cursor = document.Cursor()
+		}
+		if len(documents) < s.config.SyncBatchSize {
+			return indexed, removed, nil
+		}
+	}
+}
+
+// upsert 为文档生成向量并写入索引，正文未变化时跳过
+func (s *KnowledgeIndexService) upsert(ctx context.Context, document *analysis.KnowledgeDocument) (bool, error) {
+	text := truncateRunes(document.Title+"\n"+document.Content, maxEmbeddingChars)
+	sum := sha256.Sum256([]byte(text))
+	hash := hex.EncodeToString(sum[:])
+	if meta, ok := s.index.Get(document.ID); ok && meta.ContentHash == hash {
+		return false, nil
+	}
+
+	vector, err := s.embedder.Embed(ctx, text)
+	if err != nil {
+		return false, err
+	}
+	return true, s.index.Upsert(document.ID, vector, analysis.VectorMeta{
+		SourceType:  document.SourceType,
+		SourceID:    document.SourceID,
+		Title:       document.Title,
+		Snippet:     truncateRunes(strings.TrimSpace(document.Content), s.config.SnippetLength),
+		ContentHash: hash,
+		UpdatedAt:   document.UpdatedAt,
+	})
+}
+
+// Retrieve 检索与告警最相关的知识，过滤低于最低相似度的资料与告警自身
+func (s *KnowledgeIndexService) Retrieve(ctx context.Context, alert *model.Alert, limit int) ([]*analysis.Citation, error) {
+	if limit <= 0 {
+		limit = s.config.TopK
+	}
+	if s.index.Len() == 0 {
+		return nil, nil
+	}
+
+	vector, err := s.embedder.Embed(ctx, alertQueryText(alert))
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed alert: %w", err)
+	}
+
+	self := analysis.KnowledgeDocumentID(analysis.KnowledgeSourceAlert, alert.ID)
+	citations := make([]*analysis.Citation, 0, limit)
+	for _, hit := range s.index.Search(vector, limit+1) {
+		if hit.ID == self || hit.Score < s.config.MinScore {
+			continue
+		}
+		citations = append(citations, &analysis.Citation{
+			Ref:        fmt.Sprintf("K%d", len(citations)+1),
+			DocumentID: hit.ID,
+			SourceType: hit.Meta.SourceType,
+			SourceID:   hit.Meta.SourceID,
+			Title:      hit.Meta.Title,
+			Snippet:    hit.Meta.Snippet,
+			Score:      hit.Score,
+		})
+		if len(citations) == limit {
+			break
+		}
+	}
+	return citations, nil
+}
+
+// alertQueryText 告警的检索文本
+func alertQueryText(alert *model.Alert) string {
+	title := alert.Title
+	if title == "" {
+		title = alert.Name
+	}
+	return truncateRunes(fmt.Sprintf("%s\n%s\n%s", title, alert.Labels, alert.Content), maxEmbeddingChars)
+}
+
+// truncateRunes 按字符截断
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/infrastructure/vectorindex"
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryKnowledgeSource 内存知识文档来源
+type memoryKnowledgeSource struct {
+	documents map[string]*analysis.KnowledgeDocument
+}
+
+func (s *memoryKnowledgeSource) ListDocuments(ctx context.Context, after analysis.KnowledgeCursor, limit int) ([]*analysis.KnowledgeDocument, error) {
+	var documents []*analysis.KnowledgeDocument
+	for _, document := range s.documents {
+		if after.Before(document.Cursor()) {
+			documents = append(documents, document)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].Cursor().Before(documents[j].Cursor())
+	})
+	if len(documents) > limit {
+		documents = documents[:limit]
+	}
+	return documents, nil
+}
+
+func (s *memoryKnowledgeSource) ListDocumentIDs(ctx context.Context) ([]string, error) {
+	ids := make([]string, 0, len(s.documents))
+	for id := range s.documents {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// keywordEmbedder 按关键词出现次数生成向量
+type keywordEmbedder struct {
+	calls int
+}
+
+var embedderKeywords = []string{"disk", "cpu", "memory", "network", "mysql"}
+
+func (e *keywordEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	text = strings.ToLower(text)
+	vector := make([]float32, len(embedderKeywords)+1)
+	for i, keyword := range embedderKeywords {
+		vector[i] = float32(strings.Count(text, keyword))
+	}
+	vector[len(embedderKeywords)] = 0.1
+	return vector, nil
+}
+
+func knowledgeDocument(sourceType string, id uint, title, content string, updatedAt time.Time) *analysis.KnowledgeDocument {
+	return &analysis.KnowledgeDocument{
+		ID:         analysis.KnowledgeDocumentID(sourceType, id),
+		SourceType: sourceType,
+		SourceID:   id,
+		Title:      title,
+		Content:    content,
+		UpdatedAt:  updatedAt,
+	}
+}
+
+func TestKnowledgeIndexService_SyncAndRetrieve(t *testing.T) {
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	source := &memoryKnowledgeSource{documents: map[string]*analysis.KnowledgeDocument{}}
+	for _, document := range []*analysis.KnowledgeDocument{
+		knowledgeDocument(analysis.KnowledgeSourceKnowledge, 1, "Disk full runbook", "clean disk logs, expand disk volume", base.Add(time.Minute)),
+		knowledgeDocument(analysis.KnowledgeSourceKnowledge, 2, "CPU saturation", "cpu throttling, scale out", base.Add(2*time.Minute)),
+		knowledgeDocument(analysis.KnowledgeSourceAlert, 7, "DiskFull on node-1", "处理说明：disk cleaned by logrotate", base.Add(3*time.Minute)),
+		knowledgeDocument(analysis.KnowledgeSourceAlert, 9, "Network loss", "network packet loss", base.Add(4*time.Minute)),
+	} {
+		source.documents[document.ID] = document
+	}
+
+	embedder := &keywordEmbedder{}
+	config := analysis.DefaultRetrievalConfig()
+	config.SyncBatchSize = 3
+	config.SnippetLength = 10
+	service := NewKnowledgeIndexService(source, vectorindex.NewHNSW(vectorindex.DefaultHNSWConfig()), embedder, config, zap.NewNop())
+
+	indexed, removed, err := service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, indexed, "documents are synced across batches")
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, 4, embedder.calls)
+
+	// 告警自身已在索引中时不作为参考资料
+	alert := &model.Alert{ID: 7, Name: "DiskFull", Title: "DiskFull on node-2", Content: "disk usage 95%"}
+	citations, err := service.Retrieve(context.Background(), alert, 0)
+	require.NoError(t, err)
+	require.Len(t, citations, 1, "unrelated documents fall below the minimum score")
+	assert.Equal(t, "K1", citations[0].Ref)
+	assert.Equal(t, "knowledge:1", citations[0].DocumentID)
+	assert.Equal(t, "clean disk", citations[0].Snippet)
+	assert.GreaterOrEqual(t, citations[0].Score, config.MinScore)
+
+	// 未变化的文档不重新生成向量，删除的文档从索引移除
+	delete(source.documents, "knowledge:2")
+	updated := knowledgeDocument(analysis.KnowledgeSourceKnowledge, 1, "Disk full runbook", "expand disk volume", base.Add(5*time.Minute))
+	source.documents[updated.ID] = updated
+	touched := *source.documents["alert:9"]
+	touched.UpdatedAt = base.Add(6 * time.Minute)
+	source.documents[touched.ID] = &touched
+	embedder.calls = 0
+
+	indexed, removed, err = service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 1, embedder.calls)
+}
+
+func TestKnowledgeIndexService_SyncTiedUpdateTimes(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	source := &memoryKnowledgeSource{documents: map[string]*analysis.KnowledgeDocument{}}
+	for id := uint(1); id <= 4; id++ {
+		for _, sourceType := range []string{analysis.KnowledgeSourceKnowledge, analysis.KnowledgeSourceAlert} {
+			document := knowledgeDocument(sourceType, id, "batch import", "imported runbook", updatedAt)
+			source.documents[document.ID] = document
+		}
+	}
+
+	config := analysis.DefaultRetrievalConfig()
+	config.SyncBatchSize = 3
+	service := NewKnowledgeIndexService(source, vectorindex.NewHNSW(vectorindex.DefaultHNSWConfig()), &keywordEmbedder{}, config, zap.NewNop())
+
+	// 更新时间相同的文档多于一批时分批读取，不会跳过
+	indexed, _, err := service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 8, indexed)
+
+	// 之后写入的同一时间文档在下次同步时读取
+	late := knowledgeDocument(analysis.KnowledgeSourceKnowledge, 5, "batch import", "late runbook", updatedAt)
+	source.documents[late.ID] = late
+	indexed, _, err = service.Sync(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+}
@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"go.uber.org/zap"
+)
+
+// QueueReaper 定期重新投递租约过期的分析任务
+// 回收在 Redis 脚本中原子完成，多个 worker 同时运行不会重复投递
+type QueueReaper struct {
+	queue    analysis.ReliableTaskQueue
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewQueueReaper 创建过期租约回收器
+func NewQueueReaper(queue analysis.ReliableTaskQueue, interval time.Duration, logger *zap.Logger) *QueueReaper {
+	if interval <= 0 {
+		interval = analysis.DefaultQueueConfig().ReapInterval
+	}
+	return &QueueReaper{
+		queue:    queue,
+		interval: interval,
+		logger:   logger.Named("analysis-queue-reaper"),
+	}
+}
+
+// Run 按间隔回收过期租约，直到上下文取消
+func (r *QueueReaper) Run(ctx context.Context) error {
+	r.logger.Info("Analysis queue reaper started", zap.Duration("interval", r.interval))
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.reap(ctx)
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Analysis queue reaper stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// reap 回收一批过期租约
+func (r *QueueReaper) reap(ctx context.Context) {
+	requeued, deadLettered, err := r.queue.RequeueExpired(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("Failed to requeue expired analysis tasks", zap.Error(err))
+		}
+		return
+	}
+	if deadLettered > 0 {
+		r.logger.Warn("Analysis tasks moved to dead letter queue", zap.Int("count", deadLettered))
+	}
+	if requeued > 0 {
+		r.logger.Info("Expired analysis tasks requeued", zap.Int("count", requeued))
+	}
+}
@@ -0,0 +1,64 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	sharedErrors "alert_agent/internal/shared/errors"
+)
+
+// maxUsageDays 单次查询的最大天数
+const maxUsageDays = 93
+
+// UsageService 模型用量与预算查询
+type UsageService struct {
+	store  analysis.UsageStore
+	budget analysis.BudgetConfig
+}
+
+// NewUsageService 创建模型用量查询服务
+func NewUsageService(store analysis.UsageStore, budget analysis.BudgetConfig) *UsageService {
+	return &UsageService{store: store, budget: budget}
+}
+
+// ListUsage 获取单日所有租户的用量与预算状态
+func (s *UsageService) ListUsage(ctx context.Context, day time.Time) ([]*analysis.TenantUsage, error) {
+	usages, err := s.store.List(ctx, day)
+	if err != nil {
+		return nil, err
+	}
+	for _, usage := range usages {
+		s.budget.Apply(usage)
+	}
+	return usages, nil
+}
+
+// TenantUsage 获取租户在日期范围内每天的用量，按日期正序
+func (s *UsageService) TenantUsage(ctx context.Context, tenant string, from, to time.Time) ([]*analysis.TenantUsage, error) {
+	from = truncateDay(from)
+	to = truncateDay(to)
+	if to.Before(from) {
+		return nil, sharedErrors.NewValidationError("INVALID_RANGE", "usage range end is before start")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxUsageDays {
+		return nil, sharedErrors.NewValidationError("INVALID_RANGE", fmt.Sprintf("usage range exceeds %d days", maxUsageDays))
+	}
+
+	var usages []*analysis.TenantUsage
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		usage, err := s.store.Get(ctx, tenant, day)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, s.budget.Apply(usage))
+	}
+	return usages, nil
+}
+
+// truncateDay 截断到当天零点，保留时区
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,317 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"go.uber.org/zap"
+)
+
+// maxScalingDecisions 状态中保留的最近决策数
+const maxScalingDecisions = 20
+
+// WorkerAutoscaler 按分析队列积压与最老任务等待时间调整本进程的工作器数
+// 多个 worker 进程按上报状态的进程数分摊积压，工作器数不超过模型并发上限之和
+type WorkerAutoscaler struct {
+	queue    analysis.AnalysisTaskQueue
+	manager  analysis.AnalysisWorkerManager
+	limiter  analysis.ModelLimiter
+	store    analysis.AutoscalerStatusStore
+	metrics  analysis.AutoscalerMetrics
+	config   analysis.AutoscalerConfig
+	instance string
+	logger   *zap.Logger
+	now      func() time.Time
+
+	lastChange time.Time // 最近一次调整的时间，只在 Run 的循环中访问
+
+	mu     sync.Mutex
+	status *analysis.AutoscalerStatus
+}
+
+// NewWorkerAutoscaler 创建工作器自动扩缩容，limiter、store、metrics 为 nil 时不限制模型并发、不上报状态、不记录指标
+func NewWorkerAutoscaler(
+	queue analysis.AnalysisTaskQueue,
+	manager analysis.AnalysisWorkerManager,
+	limiter analysis.ModelLimiter,
+	store analysis.AutoscalerStatusStore,
+	metrics analysis.AutoscalerMetrics,
+	config analysis.AutoscalerConfig,
+	logger *zap.Logger,
+) *WorkerAutoscaler {
+	defaults := analysis.DefaultAutoscalerConfig()
+	if config.MinWorkers < 0 {
+		config.MinWorkers = 0
+	}
+	if config.MaxWorkers <= 0 {
+		config.MaxWorkers = defaults.MaxWorkers
+	}
+	if config.MaxWorkers < config.MinWorkers {
+		config.MaxWorkers = config.MinWorkers
+	}
+	if config.TasksPerWorker <= 0 {
+		config.TasksPerWorker = defaults.TasksPerWorker
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	hostname, _ := os.Hostname()
+	return &WorkerAutoscaler{
+		queue:    queue,
+		manager:  manager,
+		limiter:  limiter,
+		store:    store,
+		metrics:  metrics,
+		config:   config,
+		instance: fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		logger:   logger.Named("analysis-autoscaler"),
+		now:      time.Now,
+	}
+}
+
+// Run 启动最少数量的工作器并按间隔调整，上下文取消后停止所有工作器
+func (a *WorkerAutoscaler) Run(ctx context.Context) error {
+	a.logger.Info("Analysis worker autoscaler started",
+		zap.Int("min_workers", a.config.MinWorkers),
+		zap.Int("max_workers", a.config.MaxWorkers),
+		zap.Duration("interval", a.config.Interval))
+	a.lastChange = a.now()
+	if a.config.MinWorkers > 0 {
+		if err := a.manager.StartWorkers(ctx, a.config.MinWorkers); err != nil {
+			return fmt.Errorf("failed to start analysis workers: %w", err)
+		}
+	}
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+	for {
+		a.evaluate(ctx)
+		select {
+		case <-ctx.Done():
+			stopCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := a.manager.StopWorkers(stopCtx); err != nil {
+				a.logger.Error("Failed to stop analysis workers", zap.Error(err))
+			}
+			a.logger.Info("Analysis worker autoscaler stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status 获取本进程的扩缩容状态
+func (a *WorkerAutoscaler) Status() *analysis.AutoscalerStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.status
+}
+
+// evaluate 读取队列状态并调整工作器数
+func (a *WorkerAutoscaler) evaluate(ctx context.Context) {
+	queueStatus, err := a.queue.GetStatus(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			a.logger.Error("Failed to get analysis queue status", zap.Error(err))
+		}
+		return
+	}
+
+	now := a.now()
+	current := len(a.manager.GetWorkerStatuses())
+	capacity, models := a.capacity()
+	desired, reason := desiredWorkers(a.config, queueStatus, current, a.instances(ctx), capacity, now)
+
+	status := a.nextStatus(capacity, models)
+	target, direction := a.applyCooldown(status, current, desired, now)
+
+	if target != current {
+		if err := a.manager.ScaleWorkers(ctx, target); err != nil {
+			a.logger.Error("Failed to scale analysis workers", zap.Int("from", current), zap.Int("to", target), zap.Error(err))
+			return
+		}
+		a.logger.Info("Analysis workers scaled",
+			zap.String("direction", direction),
+			zap.Int("from", current),
+			zap.Int("to", target),
+			zap.String("reason", reason))
+		a.lastChange = now
+		if direction == analysis.ScaleUp {
+			status.LastScaleUp = &now
+		} else {
+			status.LastScaleDown = &now
+		}
+	}
+
+	if direction != "" {
+		decision := &analysis.ScalingDecision{
+			Time:            now,
+			Direction:       direction,
+			From:            current,
+			To:              target,
+			Desired:         desired,
+			Reason:          reason,
+			PendingCount:    queueStatus.PendingCount,
+			ProcessingCount: queueStatus.ProcessingCount,
+			OldestTaskAgeMs: oldestTaskAge(queueStatus, now).Milliseconds(),
+		}
+		a.record(status, decision)
+	}
+
+	status.Workers = a.manager.GetWorkerStatuses()
+	status.Current = len(status.Workers)
+	status.Desired = desired
+	status.UpdatedAt = now
+	a.mu.Lock()
+	a.status = status
+	a.mu.Unlock()
+
+	if a.metrics != nil {
+		a.metrics.ObserveStatus(status, queueStatus)
+	}
+	if a.store != nil {
+		if err := a.store.Save(ctx, status); err != nil && ctx.Err() == nil {
+			a.logger.Warn("Failed to save autoscaler status", zap.Error(err))
+		}
+	}
+}
+
+// nextStatus 基于上一次状态创建本轮状态
+func (a *WorkerAutoscaler) nextStatus(capacity int, models map[string]analysis.ModelSlots) *analysis.AutoscalerStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	status := &analysis.AutoscalerStatus{
+		Instance:   a.instance,
+		MinWorkers: a.config.MinWorkers,
+		MaxWorkers: a.config.MaxWorkers,
+		Capacity:   capacity,
+		Models:     models,
+	}
+	if a.status != nil {
+		status.LastScaleUp = a.status.LastScaleUp
+		status.LastScaleDown = a.status.LastScaleDown
+		status.Decisions = a.status.Decisions
+	}
+	return status
+}
+
+// applyCooldown 按冷却期决定本轮的目标数与方向，无需调整时方向为空
+func (a *WorkerAutoscaler) applyCooldown(status *analysis.AutoscalerStatus, current, desired int, now time.Time) (int, string) {
+	switch {
+	case desired > current:
+		if status.LastScaleUp != nil && now.Sub(*status.LastScaleUp) < a.config.ScaleUpCooldown {
+			return current, analysis.ScaleHold
+		}
+		return desired, analysis.ScaleUp
+	case desired < current:
+		if now.Sub(a.lastChange) < a.config.ScaleDownCooldown {
+			return current, analysis.ScaleHold
+		}
+		return desired, analysis.ScaleDown
+	default:
+		return current, ""
+	}
+}
+
+// record 记录决策，连续的冷却等待只保留第一次
+func (a *WorkerAutoscaler) record(status *analysis.AutoscalerStatus, decision *analysis.ScalingDecision) {
+	if a.metrics != nil {
+		a.metrics.RecordDecision(decision)
+	}
+	if decision.Direction == analysis.ScaleHold && len(status.Decisions) > 0 &&
+		status.Decisions[0].Direction == analysis.ScaleHold && status.Decisions[0].Desired == decision.Desired {
+		return
+	}
+	decisions := append([]*analysis.ScalingDecision{decision}, status.Decisions...)
+	if len(decisions) > maxScalingDecisions {
+		decisions = decisions[:maxScalingDecisions]
+	}
+	status.Decisions = decisions
+}
+
+// capacity 受模型并发限制的最大工作器数，有模型不限制并发时使用配置的最大值
+func (a *WorkerAutoscaler) capacity() (int, map[string]analysis.ModelSlots) {
+	if a.limiter == nil {
+		return a.config.MaxWorkers, nil
+	}
+	models := a.limiter.Snapshot()
+	if len(models) == 0 {
+		return a.config.MaxWorkers, models
+	}
+	total := 0
+	for _, slots := range models {
+		if slots.Limit <= 0 {
+			return a.config.MaxWorkers, models
+		}
+		total += slots.Limit
+	}
+	if total < a.config.MaxWorkers {
+		return total, models
+	}
+	return a.config.MaxWorkers, models
+}
+
+// instances 仍在上报状态的 worker 进程数，包含本进程
+func (a *WorkerAutoscaler) instances(ctx context.Context) int {
+	if a.store == nil {
+		return 1
+	}
+	statuses, err := a.store.List(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to list autoscaler statuses", zap.Error(err))
+		return 1
+	}
+	count := 1
+	for _, status := range statuses {
+		if status.Instance != a.instance {
+			count++
+		}
+	}
+	return count
+}
+
+// desiredWorkers 按积压计算目标工作器数
+// 积压由各进程平分，最老任务等待过久时至少增加一个工作器，结果限制在 [MinWorkers, capacity]
+func desiredWorkers(config analysis.AutoscalerConfig, queue *analysis.QueueStatus, current, instances, capacity int, now time.Time) (int, string) {
+	backlog := queue.PendingCount + queue.ProcessingCount
+	share := ceilDiv(backlog, int64(instances))
+	desired := int(ceilDiv(share, int64(config.TasksPerWorker)))
+	reason := fmt.Sprintf("%d pending, %d processing across %d instance(s)", queue.PendingCount, queue.ProcessingCount, instances)
+
+	if age := oldestTaskAge(queue, now); config.MaxTaskAge > 0 && age > config.MaxTaskAge && queue.PendingCount > 0 && desired <= current {
+		desired = current + 1
+		reason = fmt.Sprintf("oldest task waiting %s", age.Round(time.Second))
+	}
+
+	if capacity < config.MinWorkers {
+		capacity = config.MinWorkers
+	}
+	if desired > capacity {
+		desired = capacity
+		reason += fmt.Sprintf(", capped at %d", capacity)
+	}
+	if desired < config.MinWorkers {
+		desired = config.MinWorkers
+	}
+	return desired, reason
+}
+
+// oldestTaskAge 最老待处理任务的等待时间
+func oldestTaskAge(queue *analysis.QueueStatus, now time.Time) time.Duration {
+	if queue.OldestTask == nil || queue.PendingCount == 0 {
+		return 0
+	}
+	return now.Sub(*queue.OldestTask)
+}
+
+func ceilDiv(a, b int64) int64 {
+	if b <= 0 {
+		return a
+	}
+	return (a + b - 1) / b
+}
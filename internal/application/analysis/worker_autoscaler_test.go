@@ -0,0 +1,150 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// staticQueue 返回预设状态的任务队列
+type staticQueue struct {
+	analysis.AnalysisTaskQueue
+	status *analysis.QueueStatus
+}
+
+func (q *staticQueue) GetStatus(ctx context.Context) (*analysis.QueueStatus, error) {
+	return q.status, nil
+}
+
+// countingWorkerManager 只记录工作器数量的管理器
+type countingWorkerManager struct {
+	analysis.AnalysisWorkerManager
+	count int
+	scale []int
+}
+
+func (m *countingWorkerManager) GetWorkerStatuses() []*analysis.WorkerStatus {
+	statuses := make([]*analysis.WorkerStatus, m.count)
+	for i := range statuses {
+		statuses[i] = &analysis.WorkerStatus{Status: "running"}
+	}
+	return statuses
+}
+
+func (m *countingWorkerManager) ScaleWorkers(ctx context.Context, targetCount int) error {
+	m.count = targetCount
+	m.scale = append(m.scale, targetCount)
+	return nil
+}
+
+type staticLimiter map[string]analysis.ModelSlots
+
+func (l staticLimiter) Snapshot() map[string]analysis.ModelSlots {
+	return l
+}
+
+// memoryStatusStore 内存扩缩容状态存储
+type memoryStatusStore struct {
+	statuses map[string]*analysis.AutoscalerStatus
+}
+
+func (s *memoryStatusStore) Save(ctx context.Context, status *analysis.AutoscalerStatus) error {
+	s.statuses[status.Instance] = status
+	return nil
+}
+
+func (s *memoryStatusStore) List(ctx context.Context) ([]*analysis.AutoscalerStatus, error) {
+	var statuses []*analysis.AutoscalerStatus
+	for _, status := range s.statuses {
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func TestDesiredWorkers(t *testing.T) {
+	config := analysis.AutoscalerConfig{MinWorkers: 1, MaxWorkers: 10, TasksPerWorker: 5, MaxTaskAge: time.Minute}
+	now := time.Now()
+	oldest := now.Add(-2 * time.Minute)
+
+	desired, _ := desiredWorkers(config, &analysis.QueueStatus{}, 3, 1, 10, now)
+	assert.Equal(t, 1, desired, "empty queue scales down to min")
+
+	desired, _ = desiredWorkers(config, &analysis.QueueStatus{PendingCount: 18, ProcessingCount: 3}, 1, 1, 10, now)
+	assert.Equal(t, 5, desired)
+
+	// 积压由两个进程分摊
+	desired, _ = desiredWorkers(config, &analysis.QueueStatus{PendingCount: 18, ProcessingCount: 3}, 1, 2, 10, now)
+	assert.Equal(t, 3, desired)
+
+	// 积压不多但任务等待过久时增加一个工作器
+	desired, reason := desiredWorkers(config, &analysis.QueueStatus{PendingCount: 2, OldestTask: &oldest}, 1, 1, 10, now)
+	assert.Equal(t, 2, desired)
+	assert.Contains(t, reason, "oldest task waiting 2m0s")
+
+	desired, reason = desiredWorkers(config, &analysis.QueueStatus{PendingCount: 100}, 1, 1, 4, now)
+	assert.Equal(t, 4, desired)
+	assert.Contains(t, reason, "capped at 4")
+}
+
+func TestWorkerAutoscaler_Evaluate(t *testing.T) {
+	queue := &staticQueue{status: &analysis.QueueStatus{PendingCount: 40}}
+	manager := &countingWorkerManager{count: 1}
+	store := &memoryStatusStore{statuses: map[string]*analysis.AutoscalerStatus{}}
+	// 两个模型各限制 3 个并发，工作器数不超过 6
+	limiter := staticLimiter{"qwen2.5:7b": {Limit: 3}, "deepseek-chat": {Limit: 3, InFlight: 1}}
+	config := analysis.AutoscalerConfig{
+		MinWorkers:        1,
+		MaxWorkers:        10,
+		TasksPerWorker:    5,
+		ScaleUpCooldown:   time.Minute,
+		ScaleDownCooldown: 5 * time.Minute,
+	}
+	autoscaler := NewWorkerAutoscaler(queue, manager, limiter, store, nil, config, zap.NewNop())
+	now := time.Now()
+	autoscaler.now = func() time.Time { return now }
+	autoscaler.lastChange = now
+	ctx := context.Background()
+
+	autoscaler.evaluate(ctx)
+	assert.Equal(t, 6, manager.count)
+	status := autoscaler.Status()
+	assert.Equal(t, 6, status.Capacity)
+	assert.Equal(t, 6, status.Current)
+	require.Len(t, status.Decisions, 1)
+	assert.Equal(t, analysis.ScaleUp, status.Decisions[0].Direction)
+	assert.Equal(t, 1, status.Decisions[0].From)
+	assert.Same(t, status, store.statuses[status.Instance])
+
+	// 队列清空后在缩容冷却期内保持不变，连续等待只记录一次
+	queue.status = &analysis.QueueStatus{}
+	now = now.Add(time.Minute)
+	autoscaler.evaluate(ctx)
+	now = now.Add(time.Minute)
+	autoscaler.evaluate(ctx)
+	assert.Equal(t, 6, manager.count)
+	status = autoscaler.Status()
+	require.Len(t, status.Decisions, 2)
+	assert.Equal(t, analysis.ScaleHold, status.Decisions[0].Direction)
+
+	now = now.Add(5 * time.Minute)
+	autoscaler.evaluate(ctx)
+	assert.Equal(t, 1, manager.count)
+	status = autoscaler.Status()
+	assert.Equal(t, analysis.ScaleDown, status.Decisions[0].Direction)
+	require.NotNil(t, status.LastScaleDown)
+
+	// 扩容冷却期内再次积压只等待
+	queue.status = &analysis.QueueStatus{PendingCount: 40}
+	autoscaler.config.ScaleUpCooldown = 10 * time.Minute
+	now = now.Add(time.Minute)
+	autoscaler.evaluate(ctx)
+	assert.Equal(t, 1, manager.count)
+	assert.Equal(t, []int{6, 1}, manager.scale)
+	assert.Equal(t, 6, autoscaler.Status().Desired)
+}
@@ -0,0 +1,465 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/analytics"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// 预聚合刷新租约与进度名称
+const (
+	rollupLeaseKey = "analytics:rollup:lease"
+	rollupName     = "alert_rollups_hourly"
+)
+
+// 默认统计范围与返回条数
+const (
+	defaultRange      = 7 * 24 * time.Hour
+	defaultTopLimit   = 10
+	maxDimensionValue = 128
+	week              = 7 * 24 * time.Hour
+)
+
+// Service 告警分析服务实现，查询均基于小时预聚合
+type Service struct {
+	repo   analytics.Repository
+	leases analytics.LeaseManager
+	config analytics.Config
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewService 创建告警分析服务
+func NewService(repo analytics.Repository, leases analytics.LeaseManager, config analytics.Config, logger *zap.Logger) *Service {
+	defaults := analytics.DefaultConfig()
+	if config.Owner == "" {
+		hostname, _ := os.Hostname()
+		config.Owner = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if config.Location == nil {
+		config.Location = defaults.Location
+	}
+	if config.ShiftHours <= 0 || config.ShiftHours > 24 {
+		config.ShiftHours = defaults.ShiftHours
+	}
+	if config.MaxHoursPerRun <= 0 {
+		config.MaxHoursPerRun = defaults.MaxHoursPerRun
+	}
+	return &Service{
+		repo:   repo,
+		leases: leases,
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run 周期性刷新小时聚合，持有租约的 worker 才会刷新，直到 ctx 取消
+func (s *Service) Run(ctx context.Context) error {
+	s.logger.Info("Analytics rollup refresher started",
+		zap.String("owner", s.config.Owner),
+		zap.Duration("interval", s.config.Interval))
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := s.leases.Acquire(ctx, rollupLeaseKey, s.config.Owner, s.config.LeaseTTL)
+		if err != nil {
+			s.logger.Warn("Failed to acquire analytics rollup lease", zap.Error(err))
+		} else if acquired {
+			if err := s.refresh(ctx, s.now()); err != nil && ctx.Err() == nil {
+				s.logger.Error("Failed to refresh analytics rollups", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.leases.Release(releaseCtx, rollupLeaseKey, s.config.Owner); err != nil {
+				s.logger.Warn("Failed to release analytics rollup lease", zap.Error(err))
+			}
+			cancel()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshRollups 立即刷新小时聚合，其他 worker 正在刷新时返回冲突
+func (s *Service) RefreshRollups(ctx context.Context) error {
+	acquired, err := s.leases.Acquire(ctx, rollupLeaseKey, s.config.Owner, s.config.LeaseTTL)
+	if err != nil {
+		return errors.NewInternalError("failed to acquire analytics rollup lease", err)
+	}
+	if !acquired {
+		return errors.NewConflictError("Analytics rollups are being refreshed by another worker")
+	}
+	if err := s.refresh(ctx, s.now()); err != nil {
+		return errors.NewInternalError("failed to refresh analytics rollups", err)
+	}
+	return nil
+}
+
+// refresh 从上次进度开始按天重新聚合，最近 Lookback 内的小时桶每次都会重算
+func (s *Service) refresh(ctx context.Context, now time.Time) error {
+	end := now.UTC().Truncate(time.Hour).Add(time.Hour)
+	start := end.Add(-s.config.Backfill)
+	watermark, err := s.repo.GetWatermark(ctx, rollupName)
+	if err != nil {
+		return err
+	}
+	if watermark != nil {
+		start = watermark.UTC().Truncate(time.Hour)
+		if recent := end.Add(-s.config.Lookback); recent.Before(start) {
+			start = recent
+		}
+	}
+	stop := end
+	if limit := start.Add(time.Duration(s.config.MaxHoursPerRun) * time.Hour); limit.Before(stop) {
+		stop = limit
+	}
+
+	for chunkStart := start; chunkStart.Before(stop); {
+		chunkEnd := chunkStart.Add(24 * time.Hour)
+		if chunkEnd.After(stop) {
+			chunkEnd = stop
+		}
+		alerts, err := s.repo.ListAlertsCreatedBetween(ctx, chunkStart, chunkEnd)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.ReplaceRollups(ctx, chunkStart, chunkEnd, s.aggregate(alerts, now)); err != nil {
+			return err
+		}
+		if err := s.repo.SetWatermark(ctx, rollupName, chunkEnd); err != nil {
+			return err
+		}
+		chunkStart = chunkEnd
+	}
+
+	s.logger.Debug("Analytics rollups refreshed", zap.Time("from", start), zap.Time("to", stop))
+	return nil
+}
+
+type rollupKey struct {
+	bucket   time.Time
+	ruleID   uint
+	service  string
+	team     string
+	instance string
+	severity string
+}
+
+// aggregate 按小时与维度聚合告警；处理时间视为确认时间，已恢复告警的处理时间视为恢复时间
+func (s *Service) aggregate(alerts []*model.Alert, now time.Time) []*analytics.HourlyRollup {
+	rollups := make(map[rollupKey]*analytics.HourlyRollup)
+	for _, alert := range alerts {
+		labels := gateway.ParseAlertLabels(alert)
+		severity := alert.Severity
+		if severity == "" {
+			severity = alert.Level
+		}
+		key := rollupKey{
+			bucket:   alert.CreatedAt.UTC().Truncate(time.Hour),
+			ruleID:   alert.RuleID,
+			service:  firstLabel(labels, s.config.ServiceLabels),
+			team:     firstLabel(labels, s.config.TeamLabels),
+			instance: firstLabel(labels, s.config.InstanceLabels),
+			severity: severity,
+		}
+		rollup, ok := rollups[key]
+		if !ok {
+			rollup = &analytics.HourlyRollup{
+				Bucket:    key.bucket,
+				RuleID:    key.ruleID,
+				Service:   key.service,
+				Team:      key.team,
+				Instance:  key.instance,
+				Severity:  key.severity,
+				UpdatedAt: now,
+			}
+			rollups[key] = rollup
+		}
+
+		rollup.AlertCount++
+		if alert.HandleTime == nil || alert.Status == model.AlertStatusNew || alert.HandleTime.Before(alert.CreatedAt) {
+			continue
+		}
+		elapsed := alert.HandleTime.Sub(alert.CreatedAt).Seconds()
+		rollup.AckedCount++
+		rollup.AckSeconds += elapsed
+		if alert.Status == model.AlertStatusResolved {
+			rollup.ResolvedCount++
+			rollup.ResolveSeconds += elapsed
+		}
+	}
+
+	result := make([]*analytics.HourlyRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		result = append(result, rollup)
+	}
+	return result
+}
+
+// ResponseMetrics 按规则、服务或团队统计 MTTA/MTTR
+func (s *Service) ResponseMetrics(ctx context.Context, dimension analytics.Dimension, filter *analytics.Filter, limit int) ([]*analytics.DimensionStats, error) {
+	return s.aggregateBy(ctx, dimension, filter, limit)
+}
+
+// TopNoisy 告警数最多的规则或实例
+func (s *Service) TopNoisy(ctx context.Context, dimension analytics.Dimension, filter *analytics.Filter, limit int) ([]*analytics.DimensionStats, error) {
+	if limit <= 0 {
+		limit = defaultTopLimit
+	}
+	return s.aggregateBy(ctx, dimension, filter, limit)
+}
+
+func (s *Service) aggregateBy(ctx context.Context, dimension analytics.Dimension, filter *analytics.Filter, limit int) ([]*analytics.DimensionStats, error) {
+	if !dimension.IsValid() {
+		return nil, errors.NewValidationError("INVALID_DIMENSION", fmt.Sprintf("Invalid analytics dimension: %s", dimension))
+	}
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.AggregateBy(ctx, dimension, filter, limit)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to aggregate analytics", err)
+	}
+	for _, stat := range stats {
+		stat.MTTASeconds = stat.MTTA()
+		stat.MTTRSeconds = stat.MTTR()
+	}
+	if dimension == analytics.DimensionRule {
+		s.fillRuleNames(ctx, stats)
+	}
+	return stats, nil
+}
+
+// AlertsPerShift 按值班班次统计告警数
+func (s *Service) AlertsPerShift(ctx context.Context, filter *analytics.Filter) ([]*analytics.ShiftStats, error) {
+	buckets, err := s.bucketTotals(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := make(map[time.Time]*analytics.ShiftStats)
+	for _, bucket := range buckets {
+		start, end := s.shiftOf(bucket.Bucket)
+		shift, ok := shifts[start]
+		if !ok {
+			shift = &analytics.ShiftStats{Start: start, End: end}
+			shifts[start] = shift
+		}
+		shift.Alerts += bucket.Alerts
+	}
+
+	result := make([]*analytics.ShiftStats, 0, len(shifts))
+	for _, shift := range shifts {
+		result = append(result, shift)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result, nil
+}
+
+// shiftOf 返回时间所在班次的起止时间，最后一个班次截止到次日第一个班次开始
+func (s *Service) shiftOf(t time.Time) (time.Time, time.Time) {
+	local := t.In(s.config.Location)
+	base := time.Date(local.Year(), local.Month(), local.Day(), s.config.ShiftStartHour, 0, 0, 0, s.config.Location)
+	if local.Before(base) {
+		base = base.AddDate(0, 0, -1)
+	}
+	length := time.Duration(s.config.ShiftHours) * time.Hour
+	start := base.Add(local.Sub(base) / length * length)
+	end := start.Add(length)
+	if next := base.AddDate(0, 0, 1); end.After(next) {
+		end = next
+	}
+	return start, end
+}
+
+// Heatmap 星期 × 小时告警热力图
+func (s *Service) Heatmap(ctx context.Context, filter *analytics.Filter) (*analytics.Heatmap, error) {
+	buckets, err := s.bucketTotals(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := &analytics.Heatmap{Timezone: s.config.Location.String()}
+	for _, bucket := range buckets {
+		local := bucket.Bucket.In(s.config.Location)
+		cell := &heatmap.Cells[local.Weekday()][local.Hour()]
+		*cell += bucket.Alerts
+		if *cell > heatmap.Max {
+			heatmap.Max = *cell
+		}
+	}
+	return heatmap, nil
+}
+
+// WeekOverWeek 截至 end 的最近一周与上一周对比
+func (s *Service) WeekOverWeek(ctx context.Context, end time.Time, filter *analytics.Filter) (*analytics.PeriodComparison, error) {
+	if end.IsZero() {
+		end = s.now()
+	}
+	base := analytics.Filter{}
+	if filter != nil {
+		base = *filter
+	}
+
+	current, currentRules, err := s.period(ctx, base, end.Add(-week), end)
+	if err != nil {
+		return nil, err
+	}
+	previous, previousRules, err := s.period(ctx, base, end.Add(-2*week), end.Add(-week))
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &analytics.PeriodComparison{
+		Current:         *current,
+		Previous:        *previous,
+		AlertsChangePct: changePct(float64(current.Alerts), float64(previous.Alerts)),
+		MTTAChangePct:   changePct(current.MTTASeconds, previous.MTTASeconds),
+		MTTRChangePct:   changePct(current.MTTRSeconds, previous.MTTRSeconds),
+	}
+
+	changes := make(map[string]*analytics.RuleChange)
+	for _, stat := range currentRules {
+		changes[stat.Key] = &analytics.RuleChange{RuleID: stat.Key, Name: stat.Name, Current: stat.Alerts}
+	}
+	for _, stat := range previousRules {
+		change, ok := changes[stat.Key]
+		if !ok {
+			change = &analytics.RuleChange{RuleID: stat.Key, Name: stat.Name}
+			changes[stat.Key] = change
+		}
+		change.Previous = stat.Alerts
+	}
+	for _, change := range changes {
+		change.Delta = change.Current - change.Previous
+		if change.Delta != 0 {
+			comparison.TopRuleChanges = append(comparison.TopRuleChanges, change)
+		}
+	}
+	sort.Slice(comparison.TopRuleChanges, func(i, j int) bool {
+		a, b := comparison.TopRuleChanges[i], comparison.TopRuleChanges[j]
+		if abs(a.Delta) != abs(b.Delta) {
+			return abs(a.Delta) > abs(b.Delta)
+		}
+		return a.RuleID < b.RuleID
+	})
+	if len(comparison.TopRuleChanges) > defaultTopLimit {
+		comparison.TopRuleChanges = comparison.TopRuleChanges[:defaultTopLimit]
+	}
+	return comparison, nil
+}
+
+// period 汇总一个统计周期，同时返回按规则的告警数
+func (s *Service) period(ctx context.Context, filter analytics.Filter, from, to time.Time) (*analytics.PeriodStats, []*analytics.DimensionStats, error) {
+	filter.From, filter.To = from, to
+	rules, err := s.aggregateBy(ctx, analytics.DimensionRule, &filter, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	stats := &analytics.PeriodStats{From: from, To: to}
+	for _, rule := range rules {
+		stats.Add(rule.Totals)
+	}
+	stats.MTTASeconds = stats.MTTA()
+	stats.MTTRSeconds = stats.MTTR()
+	return stats, rules, nil
+}
+
+func (s *Service) bucketTotals(ctx context.Context, filter *analytics.Filter) ([]*analytics.BucketTotals, error) {
+	filter, err := s.normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := s.repo.BucketTotals(ctx, filter)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to aggregate analytics", err)
+	}
+	return buckets, nil
+}
+
+// normalizeFilter 补全默认时间范围（最近 7 天）
+func (s *Service) normalizeFilter(filter *analytics.Filter) (*analytics.Filter, error) {
+	normalized := analytics.Filter{}
+	if filter != nil {
+		normalized = *filter
+	}
+	if normalized.To.IsZero() {
+		normalized.To = s.now()
+	}
+	if normalized.From.IsZero() {
+		normalized.From = normalized.To.Add(-defaultRange)
+	}
+	if !normalized.From.Before(normalized.To) {
+		return nil, errors.NewValidationError("INVALID_RANGE", "from must be before to")
+	}
+	return &normalized, nil
+}
+
+// fillRuleNames 补充规则名称，失败时只记录日志
+func (s *Service) fillRuleNames(ctx context.Context, stats []*analytics.DimensionStats) {
+	ids := make([]uint, 0, len(stats))
+	for _, stat := range stats {
+		if id, err := strconv.ParseUint(stat.Key, 10, 64); err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	names, err := s.repo.RuleNames(ctx, ids)
+	if err != nil {
+		s.logger.Warn("Failed to load rule names for analytics", zap.Error(err))
+		return
+	}
+	for _, stat := range stats {
+		if id, err := strconv.ParseUint(stat.Key, 10, 64); err == nil {
+			stat.Name = names[uint(id)]
+		}
+	}
+}
+
+// firstLabel 按顺序取第一个存在的标签值
+func firstLabel(labels map[string]string, names []string) string {
+	for _, name := range names {
+		if value := labels[name]; value != "" {
+			if len(value) > maxDimensionValue {
+				value = value[:maxDimensionValue]
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// changePct 计算变化百分比，基期为 0 时返回 nil
+func changePct(current, previous float64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	pct := (current - previous) / previous * 100
+	return &pct
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,287 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analytics"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryRepository 内存告警分析仓储
+type memoryRepository struct {
+	alerts     []*model.Alert
+	rollups    []*analytics.HourlyRollup
+	watermarks map[string]time.Time
+	rules      map[uint]string
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{watermarks: make(map[string]time.Time), rules: make(map[uint]string)}
+}
+
+func (r *memoryRepository) ListAlertsCreatedBetween(ctx context.Context, from, to time.Time) ([]*model.Alert, error) {
+	var alerts []*model.Alert
+	for _, alert := range r.alerts {
+		if !alert.CreatedAt.Before(from) && alert.CreatedAt.Before(to) {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (r *memoryRepository) ReplaceRollups(ctx context.Context, from, to time.Time, rollups []*analytics.HourlyRollup) error {
+	kept := r.rollups[:0]
+	for _, rollup := range r.rollups {
+		if rollup.Bucket.Before(from) || !rollup.Bucket.Before(to) {
+			kept = append(kept, rollup)
+		}
+	}
+	r.rollups = append(kept, rollups...)
+	return nil
+}
+
+func (r *memoryRepository) GetWatermark(ctx context.Context, name string) (*time.Time, error) {
+	if watermark, ok := r.watermarks[name]; ok {
+		return &watermark, nil
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) SetWatermark(ctx context.Context, name string, watermark time.Time) error {
+	r.watermarks[name] = watermark
+	return nil
+}
+
+func (r *memoryRepository) matches(rollup *analytics.HourlyRollup, filter *analytics.Filter) bool {
+	return !rollup.Bucket.Before(filter.From.Truncate(time.Hour)) && rollup.Bucket.Before(filter.To) &&
+		(filter.RuleID == 0 || rollup.RuleID == filter.RuleID) &&
+		(filter.Service == "" || rollup.Service == filter.Service)
+}
+
+func totalsOf(rollup *analytics.HourlyRollup) analytics.Totals {
+	return analytics.Totals{
+		Alerts: rollup.AlertCount, Acked: rollup.AckedCount, AckSeconds: rollup.AckSeconds,
+		Resolved: rollup.ResolvedCount, ResolveSeconds: rollup.ResolveSeconds,
+	}
+}
+
+func (r *memoryRepository) AggregateBy(ctx context.Context, dimension analytics.Dimension, filter *analytics.Filter, limit int) ([]*analytics.DimensionStats, error) {
+	stats := make(map[string]*analytics.DimensionStats)
+	for _, rollup := range r.rollups {
+		if !r.matches(rollup, filter) {
+			continue
+		}
+		key := map[analytics.Dimension]string{
+			analytics.DimensionRule:     strconv.FormatUint(uint64(rollup.RuleID), 10),
+			analytics.DimensionService:  rollup.Service,
+			analytics.DimensionTeam:     rollup.Team,
+			analytics.DimensionInstance: rollup.Instance,
+			analytics.DimensionSeverity: rollup.Severity,
+		}[dimension]
+		if _, ok := stats[key]; !ok {
+			stats[key] = &analytics.DimensionStats{Key: key}
+		}
+		stats[key].Add(totalsOf(rollup))
+	}
+	result := make([]*analytics.DimensionStats, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Alerts != result[j].Alerts {
+			return result[i].Alerts > result[j].Alerts
+		}
+		return result[i].Key < result[j].Key
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *memoryRepository) BucketTotals(ctx context.Context, filter *analytics.Filter) ([]*analytics.BucketTotals, error) {
+	buckets := make(map[time.Time]*analytics.BucketTotals)
+	for _, rollup := range r.rollups {
+		if !r.matches(rollup, filter) {
+			continue
+		}
+		if _, ok := buckets[rollup.Bucket]; !ok {
+			buckets[rollup.Bucket] = &analytics.BucketTotals{Bucket: rollup.Bucket}
+		}
+		buckets[rollup.Bucket].Add(totalsOf(rollup))
+	}
+	result := make([]*analytics.BucketTotals, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, bucket)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Bucket.Before(result[j].Bucket) })
+	return result, nil
+}
+
+func (r *memoryRepository) RuleNames(ctx context.Context, ruleIDs []uint) (map[uint]string, error) {
+	return r.rules, nil
+}
+
+// memoryLeases 内存租约
+type memoryLeases map[string]string
+
+func (l memoryLeases) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	if current, ok := l[key]; ok && current != owner {
+		return false, nil
+	}
+	l[key] = owner
+	return true, nil
+}
+
+func (l memoryLeases) Release(ctx context.Context, key, owner string) error {
+	if l[key] == owner {
+		delete(l, key)
+	}
+	return nil
+}
+
+func newTestService(repo *memoryRepository, leases memoryLeases, now time.Time) *Service {
+	config := analytics.DefaultConfig()
+	config.Owner = "worker-1"
+	config.Location = time.UTC
+	config.Backfill = 30 * 24 * time.Hour
+	config.MaxHoursPerRun = 30 * 24
+	service := NewService(repo, leases, config, zap.NewNop())
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func alertAt(id, ruleID uint, created time.Time, status string, handled time.Duration, labels string) *model.Alert {
+	alert := &model.Alert{ID: id, RuleID: ruleID, Level: model.AlertLevelCritical, Status: status, Labels: labels, CreatedAt: created}
+	if handled > 0 {
+		handleTime := created.Add(handled)
+		alert.HandleTime = &handleTime
+	}
+	return alert
+}
+
+func TestService_RefreshAndResponseMetrics(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 15, 10, 30, 0, 0, time.UTC)
+	repo := newMemoryRepository()
+	repo.rules[1] = "HighCPU"
+	repo.alerts = []*model.Alert{
+		alertAt(1, 1, now.Add(-2*time.Hour), model.AlertStatusResolved, 10*time.Minute, `{"service":"api","team":"sre","instance":"a"}`),
+		alertAt(2, 1, now.Add(-90*time.Minute), model.AlertStatusAcknowledged, 2*time.Minute, `{"app":"api","owner":"sre","host":"b"}`),
+		alertAt(3, 1, now.Add(-time.Hour), model.AlertStatusNew, 0, `{"service":"api","instance":"a"}`),
+		alertAt(4, 2, now.Add(-3*time.Hour), model.AlertStatusResolved, 30*time.Minute, `{"service":"db","team":"dba"}`),
+	}
+	service := newTestService(repo, memoryLeases{}, now)
+
+	require.NoError(t, service.RefreshRollups(ctx))
+	assert.Equal(t, now.Truncate(time.Hour).Add(time.Hour), repo.watermarks[rollupName])
+
+	byRule, err := service.ResponseMetrics(ctx, analytics.DimensionRule, nil, 0)
+	require.NoError(t, err)
+	require.Len(t, byRule, 2)
+	assert.Equal(t, "1", byRule[0].Key)
+	assert.Equal(t, "HighCPU", byRule[0].Name)
+	assert.Equal(t, int64(3), byRule[0].Alerts)
+	assert.Equal(t, int64(2), byRule[0].Acked)
+	assert.InDelta(t, 360, byRule[0].MTTASeconds, 0.001)
+	assert.InDelta(t, 600, byRule[0].MTTRSeconds, 0.001)
+
+	byService, err := service.ResponseMetrics(ctx, analytics.DimensionService, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "api", byService[0].Key)
+	assert.Equal(t, int64(3), byService[0].Alerts)
+
+	top, err := service.TopNoisy(ctx, analytics.DimensionInstance, nil, 1)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, "a", top[0].Key)
+	assert.Equal(t, int64(2), top[0].Alerts)
+
+	// 延迟确认的告警在 Lookback 内重新聚合
+	repo.alerts[2] = alertAt(3, 1, now.Add(-time.Hour), model.AlertStatusResolved, 20*time.Minute, `{"service":"api","instance":"a"}`)
+	service.now = func() time.Time { return now.Add(time.Hour) }
+	require.NoError(t, service.RefreshRollups(ctx))
+	byRule, err = service.ResponseMetrics(ctx, analytics.DimensionRule, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), byRule[0].Acked)
+	assert.Equal(t, int64(2), byRule[0].Resolved)
+
+	_, err = service.ResponseMetrics(ctx, analytics.Dimension("cluster"), nil, 0)
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+}
+
+func TestService_RefreshRollupsLeaseHeld(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 30, 0, 0, time.UTC)
+	service := newTestService(newMemoryRepository(), memoryLeases{rollupLeaseKey: "worker-2"}, now)
+
+	err := service.RefreshRollups(context.Background())
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeConflict))
+}
+
+func TestService_ShiftsAndHeatmap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC) // 周三
+	repo := newMemoryRepository()
+	repo.alerts = []*model.Alert{
+		alertAt(1, 1, time.Date(2024, 5, 14, 8, 10, 0, 0, time.UTC), model.AlertStatusNew, 0, ""),  // 13 日 21:00 班次
+		alertAt(2, 1, time.Date(2024, 5, 14, 9, 5, 0, 0, time.UTC), model.AlertStatusNew, 0, ""),   // 14 日 09:00 班次
+		alertAt(3, 1, time.Date(2024, 5, 14, 20, 59, 0, 0, time.UTC), model.AlertStatusNew, 0, ""), // 14 日 09:00 班次
+		alertAt(4, 1, time.Date(2024, 5, 15, 2, 0, 0, 0, time.UTC), model.AlertStatusNew, 0, ""),   // 14 日 21:00 班次
+	}
+	service := newTestService(repo, memoryLeases{}, now)
+	require.NoError(t, service.RefreshRollups(ctx))
+
+	shifts, err := service.AlertsPerShift(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, shifts, 3)
+	assert.Equal(t, time.Date(2024, 5, 13, 21, 0, 0, 0, time.UTC), shifts[0].Start)
+	assert.Equal(t, time.Date(2024, 5, 14, 9, 0, 0, 0, time.UTC), shifts[1].Start)
+	assert.Equal(t, time.Date(2024, 5, 14, 21, 0, 0, 0, time.UTC), shifts[1].End)
+	assert.Equal(t, int64(2), shifts[1].Alerts)
+	assert.Equal(t, int64(1), shifts[2].Alerts)
+
+	heatmap, err := service.Heatmap(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", heatmap.Timezone)
+	assert.Equal(t, int64(1), heatmap.Cells[time.Tuesday][8])
+	assert.Equal(t, int64(1), heatmap.Cells[time.Wednesday][2])
+	assert.Equal(t, int64(1), heatmap.Max)
+}
+
+func TestService_WeekOverWeek(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	repo := newMemoryRepository()
+	repo.rules = map[uint]string{1: "HighCPU", 2: "DiskFull"}
+	repo.alerts = []*model.Alert{
+		alertAt(1, 1, now.Add(-10*24*time.Hour), model.AlertStatusResolved, 10*time.Minute, ""),
+		alertAt(2, 1, now.Add(-2*24*time.Hour), model.AlertStatusResolved, 5*time.Minute, ""),
+		alertAt(3, 2, now.Add(-2*24*time.Hour), model.AlertStatusNew, 0, ""),
+		alertAt(4, 2, now.Add(-24*time.Hour), model.AlertStatusNew, 0, ""),
+	}
+	service := newTestService(repo, memoryLeases{}, now)
+	require.NoError(t, service.RefreshRollups(ctx))
+
+	comparison, err := service.WeekOverWeek(ctx, now, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), comparison.Current.Alerts)
+	assert.Equal(t, int64(1), comparison.Previous.Alerts)
+	require.NotNil(t, comparison.AlertsChangePct)
+	assert.InDelta(t, 200, *comparison.AlertsChangePct, 0.001)
+	require.NotNil(t, comparison.MTTRChangePct)
+	assert.InDelta(t, -50, *comparison.MTTRChangePct, 0.001)
+
+	require.Len(t, comparison.TopRuleChanges, 1)
+	assert.Equal(t, "2", comparison.TopRuleChanges[0].RuleID)
+	assert.Equal(t, "DiskFull", comparison.TopRuleChanges[0].Name)
+	assert.Equal(t, int64(2), comparison.TopRuleChanges[0].Delta)
+}
@@ -0,0 +1,355 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"alert_agent/internal/domain/automation"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxResultLength 操作日志中保存的输出长度上限
+const maxResultLength = 4096
+
+// runnerBatchSize 每轮领取的执行数
+const runnerBatchSize = 10
+
+// sensitiveHeaders 操作日志中隐藏取值的请求头
+var sensitiveHeaders = []string{"authorization", "token", "key", "secret", "cookie"}
+
+// Runner 执行已排队的运行手册，步骤失败时按相反顺序执行回滚操作
+type Runner struct {
+	executions automation.ExecutionRepository
+	executors  map[automation.ActionType]automation.ActionExecutor
+	config     automation.Config
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewRunner 创建运行手册执行器，未注册执行器的操作类型执行时失败
+func NewRunner(executions automation.ExecutionRepository, executors map[automation.ActionType]automation.ActionExecutor,
+	config automation.Config, logger *zap.Logger) *Runner {
+	return &Runner{
+		executions: executions,
+		executors:  executors,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run 定期领取排队的执行，将超时未审批的执行标记为过期、worker 退出后遗留的执行标记为失败，直到 ctx 取消
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.expireApprovals(ctx)
+			r.failAbandoned(ctx)
+			if err := r.Poll(ctx); err != nil {
+				r.logger.Warn("Failed to poll runbook executions", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Poll 领取并执行一批排队的执行，多个 worker 通过状态条件更新避免重复执行
+func (r *Runner) Poll(ctx context.Context) error {
+	queued, err := r.executions.ListByStatus(ctx, automation.ExecutionQueued, runnerBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, execution := range queued {
+		if ctx.Err() != nil {
+			return nil
+		}
+		now := r.now()
+		execution.Status = automation.ExecutionRunning
+		execution.StartedAt = &now
+		execution.UpdatedAt = now
+		claimed, err := r.executions.Transition(ctx, execution, automation.ExecutionQueued)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		r.Execute(ctx, execution)
+	}
+	return nil
+}
+
+// Execute 依次执行步骤，失败时先回滚失败的步骤，再按相反顺序回滚已完成的步骤
+// 所有回滚成功时状态为 rolled_back，没有回滚操作或回滚失败时为 failed
+func (r *Runner) Execute(ctx context.Context, execution *automation.Execution) {
+	failed := -1
+	for i := range execution.Steps {
+		step := &execution.Steps[i]
+		if err := r.perform(ctx, execution, step.Name, automation.PhaseExecute, &step.Action); err != nil {
+			failed = i
+			execution.Error = fmt.Sprintf("step %s: %v", step.Name, err)
+			break
+		}
+	}
+
+	execution.Status = automation.ExecutionSucceeded
+	if failed >= 0 {
+		execution.Status = r.rollback(ctx, execution, failed)
+	}
+
+	now := r.now()
+	execution.FinishedAt = &now
+	execution.UpdatedAt = now
+	if err := r.executions.Update(ctx, execution); err != nil {
+		r.logger.Error("Failed to update runbook execution", zap.String("execution_id", execution.ID), zap.Error(err))
+	}
+	r.logger.Info("runbook execution finished",
+		zap.String("execution_id", execution.ID),
+		zap.String("runbook", execution.RunbookName),
+		zap.String("status", string(execution.Status)),
+		zap.Bool("dry_run", execution.DryRun))
+}
+
+// rollback 从失败的步骤开始倒序执行回滚操作，单个回滚失败不影响其余回滚
+func (r *Runner) rollback(ctx context.Context, execution *automation.Execution, failed int) automation.ExecutionStatus {
+	rolledBack, complete := false, true
+	for i := failed; i >= 0; i-- {
+		step := &execution.Steps[i]
+		if step.Rollback == nil {
+			continue
+		}
+		rolledBack = true
+		if err := r.perform(ctx, execution, step.Name, automation.PhaseRollback, step.Rollback); err != nil {
+			complete = false
+			r.logger.Warn("Runbook rollback failed",
+				zap.String("execution_id", execution.ID), zap.String("step", step.Name), zap.Error(err))
+		}
+	}
+	if rolledBack && complete {
+		return automation.ExecutionRolledBack
+	}
+	return automation.ExecutionFailed
+}
+
+// perform 渲染并执行单个操作，试运行时只记录操作内容；每个操作都写入告警的操作日志
+func (r *Runner) perform(ctx context.Context, execution *automation.Execution, stepName string, phase automation.ActionPhase, action *automation.Action) error {
+	start := r.now()
+	entry := &automation.AutomationAction{
+		ID:          uuid.New().String(),
+		ExecutionID: execution.ID,
+		AlertID:     strconv.FormatUint(uint64(execution.AlertID), 10),
+		Step:        stepName,
+		Phase:       phase,
+		ActionType:  action.Type,
+		DryRun:      execution.DryRun,
+		CreatedAt:   start,
+	}
+
+	rendered, err := renderAction(action, execution.Context)
+	if err == nil {
+		entry.TargetInfo = map[string]string{"type": string(rendered.Type), "target": rendered.Describe()}
+		entry.Parameters = redactAction(rendered)
+		var result *automation.ActionResult
+		result, err = r.execute(automation.WithActionID(ctx, entry.ID), execution, rendered)
+		if result != nil {
+			entry.Result = truncate(result.Output, maxResultLength)
+		}
+	}
+
+	entry.Status = automation.ActionCompleted
+	if err != nil {
+		entry.Status = automation.ActionFailed
+		entry.ErrorMessage = err.Error()
+	}
+	entry.ExecutionTime = r.now().Sub(start).Milliseconds()
+	entry.UpdatedAt = r.now()
+	if addErr := r.executions.AddAction(ctx, entry); addErr != nil {
+		r.logger.Warn("Failed to save automation action log", zap.String("execution_id", execution.ID), zap.Error(addErr))
+	}
+	return err
+}
+
+func (r *Runner) execute(ctx context.Context, execution *automation.Execution, action *automation.Action) (*automation.ActionResult, error) {
+	if execution.DryRun {
+		return &automation.ActionResult{Output: "dry run: " + action.Describe()}, nil
+	}
+	executor, ok := r.executors[action.Type]
+	if !ok {
+		return nil, fmt.Errorf("no executor for action type %s", action.Type)
+	}
+	timeout := r.config.DefaultTimeout
+	if action.Timeout > 0 {
+		timeout = time.Duration(action.Timeout) * time.Second
+	}
+	actionCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return executor.Execute(actionCtx, action)
+}
+
+// expireApprovals 将超时未审批的执行标记为过期
+func (r *Runner) expireApprovals(ctx context.Context) {
+	if r.config.ApprovalTimeout <= 0 {
+		return
+	}
+	pending, err := r.executions.ListPendingBefore(ctx, r.now().Add(-r.config.ApprovalTimeout))
+	if err != nil {
+		r.logger.Warn("Failed to list pending approvals", zap.Error(err))
+		return
+	}
+	for _, execution := range pending {
+		now := r.now()
+		execution.Status = automation.ExecutionExpired
+		execution.FinishedAt = &now
+		execution.UpdatedAt = now
+		if _, err := r.executions.Transition(ctx, execution, automation.ExecutionPendingApproval); err != nil {
+			r.logger.Warn("Failed to expire execution", zap.String("execution_id", execution.ID), zap.Error(err))
+		}
+	}
+}
+
+// failAbandoned 将运行时间超过所有操作超时之和的执行标记为失败。
+// 这类执行的 worker 已在执行中途退出，不重新排队以免重复执行已完成的操作。
+func (r *Runner) failAbandoned(ctx context.Context) {
+	running, err := r.executions.ListByStatus(ctx, automation.ExecutionRunning, 0)
+	if err != nil {
+		r.logger.Warn("Failed to list running executions", zap.Error(err))
+		return
+	}
+	for _, execution := range running {
+		if execution.StartedAt == nil || r.now().Before(execution.StartedAt.Add(r.maxDuration(execution))) {
+			continue
+		}
+		now := r.now()
+		execution.Status = automation.ExecutionFailed
+		execution.Error = "execution abandoned: worker stopped before it finished"
+		execution.FinishedAt = &now
+		execution.UpdatedAt = now
+		failed, err := r.executions.Transition(ctx, execution, automation.ExecutionRunning)
+		if err != nil {
+			r.logger.Warn("Failed to fail abandoned execution", zap.String("execution_id", execution.ID), zap.Error(err))
+			continue
+		}
+		if failed {
+			r.logger.Warn("Runbook execution abandoned",
+				zap.String("execution_id", execution.ID),
+				zap.String("runbook", execution.RunbookName),
+				zap.Time("started_at", *execution.StartedAt))
+		}
+	}
+}
+
+// maxDuration 执行所有步骤与回滚操作的最长耗时
+func (r *Runner) maxDuration(execution *automation.Execution) time.Duration {
+	timeout := func(action *automation.Action) time.Duration {
+		if action.Timeout > 0 {
+			return time.Duration(action.Timeout) * time.Second
+		}
+		return r.config.DefaultTimeout
+	}
+	total := r.config.RunningGrace
+	for i := range execution.Steps {
+		total += timeout(&execution.Steps[i].Action)
+		if execution.Steps[i].Rollback != nil {
+			total += timeout(execution.Steps[i].Rollback)
+		}
+	}
+	return total
+}
+
+// renderAction 渲染操作中的模板字段，返回新的操作
+func renderAction(action *automation.Action, data *automation.ActionContext) (*automation.Action, error) {
+	rendered := *action
+	var err error
+	render := func(value string) string {
+		if err != nil || !strings.Contains(value, "{{") {
+			return value
+		}
+		var tmpl *template.Template
+		if tmpl, err = template.New("action").Option("missingkey=zero").Parse(value); err != nil {
+			return value
+		}
+		var out strings.Builder
+		if err = tmpl.Execute(&out, data); err != nil {
+			return value
+		}
+		return out.String()
+	}
+	renderMap := func(values map[string]string) map[string]string {
+		if values == nil {
+			return nil
+		}
+		out := make(map[string]string, len(values))
+		for k, v := range values {
+			out[k] = render(v)
+		}
+		return out
+	}
+
+	if action.HTTP != nil {
+		http := *action.HTTP
+		http.URL = render(http.URL)
+		http.Body = render(http.Body)
+		http.Headers = renderMap(http.Headers)
+		rendered.HTTP = &http
+	}
+	if action.Script != nil {
+		script := *action.Script
+		script.Script = render(script.Script)
+		script.Env = renderMap(script.Env)
+		rendered.Script = &script
+	}
+	if action.Kubernetes != nil {
+		k8s := *action.Kubernetes
+		k8s.Namespace = render(k8s.Namespace)
+		k8s.Name = render(k8s.Name)
+		rendered.Kubernetes = &k8s
+	}
+	if action.N8N != nil {
+		n8n := *action.N8N
+		n8n.WorkflowID = render(n8n.WorkflowID)
+		n8n.Data = renderMap(n8n.Data)
+		rendered.N8N = &n8n
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return &rendered, nil
+}
+
+// redactAction 隐藏请求头中的凭据，用于保存操作日志
+func redactAction(action *automation.Action) *automation.Action {
+	if action.HTTP == nil || len(action.HTTP.Headers) == 0 {
+		return action
+	}
+	redacted := *action
+	http := *action.HTTP
+	http.Headers = make(map[string]string, len(action.HTTP.Headers))
+	for k, v := range action.HTTP.Headers {
+		lower := strings.ToLower(k)
+		for _, sensitive := range sensitiveHeaders {
+			if strings.Contains(lower, sensitive) {
+				v = "***"
+				break
+			}
+		}
+		http.Headers[k] = v
+	}
+	redacted.HTTP = &http
+	return &redacted
+}
+
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit] + "...(truncated)"
+}
@@ -0,0 +1,505 @@
+package automation
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gatewayApp "alert_agent/internal/application/gateway"
+	"alert_agent/internal/domain/automation"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+	"alert_agent/pkg/types"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// gatewayActor 网关自动触发执行时记录的操作人
+const gatewayActor = "gateway"
+
+// Service 自动化服务实现
+type Service struct {
+	runbooks   automation.RunbookRepository
+	executions automation.ExecutionRepository
+	alerts     automation.AlertLoader
+	sender     automation.MessageSender
+	config     automation.Config
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewService 创建自动化服务，sender 为 nil 时不发送审批消息
+func NewService(runbooks automation.RunbookRepository, executions automation.ExecutionRepository, alerts automation.AlertLoader,
+	sender automation.MessageSender, config automation.Config, logger *zap.Logger) *Service {
+	return &Service{
+		runbooks:   runbooks,
+		executions: executions,
+		alerts:     alerts,
+		sender:     sender,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// CreateRunbook 创建运行手册
+func (s *Service) CreateRunbook(ctx context.Context, req *automation.RunbookRequest) (*automation.Runbook, error) {
+	if err := validateRunbook(req); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	runbook := &automation.Runbook{
+		ID:        uuid.New().String(),
+		Enabled:   true,
+		CreatedBy: req.CreatedBy,
+		CreatedAt: now,
+	}
+	applyRunbookRequest(runbook, req, now)
+	if err := s.runbooks.Create(ctx, runbook); err != nil {
+		return nil, errors.NewInternalError("failed to create runbook", err)
+	}
+	s.logger.Info("runbook created", zap.String("runbook_id", runbook.ID), zap.String("name", runbook.Name))
+	return runbook, nil
+}
+
+// GetRunbook 获取运行手册
+func (s *Service) GetRunbook(ctx context.Context, id string) (*automation.Runbook, error) {
+	runbook, err := s.runbooks.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get runbook", err)
+	}
+	if runbook == nil {
+		return nil, errors.NewNotFoundError("runbook")
+	}
+	return runbook, nil
+}
+
+// ListRunbooks 获取运行手册列表
+func (s *Service) ListRunbooks(ctx context.Context) ([]*automation.Runbook, error) {
+	runbooks, err := s.runbooks.List(ctx, false)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list runbooks", err)
+	}
+	return runbooks, nil
+}
+
+// UpdateRunbook 更新运行手册，已创建的执行仍使用触发时的步骤
+func (s *Service) UpdateRunbook(ctx context.Context, id string, req *automation.RunbookRequest) (*automation.Runbook, error) {
+	runbook, err := s.GetRunbook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRunbook(req); err != nil {
+		return nil, err
+	}
+	applyRunbookRequest(runbook, req, s.now())
+	if err := s.runbooks.Update(ctx, runbook); err != nil {
+		return nil, errors.NewInternalError("failed to update runbook", err)
+	}
+	return runbook, nil
+}
+
+// DeleteRunbook 删除运行手册
+func (s *Service) DeleteRunbook(ctx context.Context, id string) error {
+	if _, err := s.GetRunbook(ctx, id); err != nil {
+		return err
+	}
+	if err := s.runbooks.Delete(ctx, id); err != nil {
+		return errors.NewInternalError("failed to delete runbook", err)
+	}
+	return nil
+}
+
+// TriggerRunbook 针对告警手工触发运行手册
+func (s *Service) TriggerRunbook(ctx context.Context, id string, req *automation.TriggerRequest) (*automation.Execution, error) {
+	runbook, err := s.GetRunbook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	alert, err := s.alerts.GetByID(ctx, req.AlertID)
+	if err != nil || alert == nil {
+		return nil, errors.NewNotFoundError("alert")
+	}
+	return s.start(ctx, runbook, alert, req.TriggeredBy, req.DryRun)
+}
+
+// TriggerForAlert 触发与告警匹配的已启用运行手册
+// 运行手册对同一告警仍在进行中或在冷却时间内执行过时跳过，避免告警重复投递时重复执行
+func (s *Service) TriggerForAlert(ctx context.Context, alert *model.Alert) ([]*automation.Execution, error) {
+	runbooks, err := s.runbooks.List(ctx, true)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list runbooks", err)
+	}
+
+	labels := gateway.ParseAlertLabels(alert)
+	var executions []*automation.Execution
+	for _, runbook := range runbooks {
+		if len(runbook.Matchers) == 0 {
+			continue
+		}
+		matchers, err := gatewayApp.CompileMatchers(runbook.Matchers)
+		if err != nil {
+			s.logger.Warn("Invalid runbook matchers", zap.String("runbook_id", runbook.ID), zap.Error(err))
+			continue
+		}
+		if !matchers.Matches(labels) {
+			continue
+		}
+
+		latest, err := s.executions.GetLatest(ctx, runbook.ID, alert.ID)
+		if err != nil {
+			return executions, errors.NewInternalError("failed to get latest execution", err)
+		}
+		if latest != nil && (!latest.Status.IsFinal() || s.now().Sub(latest.CreatedAt) < s.cooldown(runbook)) {
+			s.logger.Debug("Runbook skipped in cooldown",
+				zap.String("runbook_id", runbook.ID), zap.Uint("alert_id", alert.ID), zap.String("latest_execution", latest.ID))
+			continue
+		}
+
+		execution, err := s.start(ctx, runbook, alert, gatewayActor, false)
+		if err != nil {
+			return executions, err
+		}
+		executions = append(executions, execution)
+	}
+	return executions, nil
+}
+
+// TriggerRunbooks 供告警网关在路由后调用，返回创建的执行ID
+func (s *Service) TriggerRunbooks(ctx context.Context, alert *model.Alert) ([]string, error) {
+	executions, err := s.TriggerForAlert(ctx, alert)
+	ids := make([]string, 0, len(executions))
+	for _, execution := range executions {
+		ids = append(ids, execution.ID)
+	}
+	return ids, err
+}
+
+// GetExecution 获取执行记录及操作日志
+func (s *Service) GetExecution(ctx context.Context, id string) (*automation.Execution, []*automation.AutomationAction, error) {
+	execution, err := s.getExecution(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	actions, err := s.executions.ListActions(ctx, id)
+	if err != nil {
+		return nil, nil, errors.NewInternalError("failed to list execution actions", err)
+	}
+	return execution, actions, nil
+}
+
+// ListExecutions 获取执行记录
+func (s *Service) ListExecutions(ctx context.Context, filter *automation.ExecutionFilter) ([]*automation.Execution, int64, error) {
+	if filter == nil {
+		filter = &automation.ExecutionFilter{}
+	}
+	executions, total, err := s.executions.List(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list executions", err)
+	}
+	return executions, total, nil
+}
+
+// Approve 审批通过，执行交由 worker 处理
+func (s *Service) Approve(ctx context.Context, id string, req *automation.ApprovalRequest) (*automation.Execution, error) {
+	return s.decide(ctx, id, req, automation.ExecutionQueued)
+}
+
+// Reject 拒绝执行
+func (s *Service) Reject(ctx context.Context, id string, req *automation.ApprovalRequest) (*automation.Execution, error) {
+	return s.decide(ctx, id, req, automation.ExecutionRejected)
+}
+
+// VerifyCallback 校验聊天消息中的签名审批链接，链接在审批超时后失效。
+// 打开链接只用于展示确认页，链接预取与消息预览不会改变执行状态。
+func (s *Service) VerifyCallback(ctx context.Context, id, decision, expires, signature string) (*automation.Execution, error) {
+	if s.config.ApprovalSecret == "" {
+		return nil, errors.NewForbiddenError("Chat approval is disabled")
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, errors.NewValidationError("INVALID_EXPIRES", "Invalid callback expiry")
+	}
+	expected := s.sign(id, decision, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, errors.NewForbiddenError("Invalid callback signature")
+	}
+	if s.now().Unix() > expiresAt {
+		return nil, errors.NewForbiddenError("Callback link has expired")
+	}
+	if decision != automation.DecisionApprove && decision != automation.DecisionReject {
+		return nil, errors.NewValidationError("INVALID_DECISION", fmt.Sprintf("Invalid decision: %s", decision))
+	}
+	return s.getExecution(ctx, id)
+}
+
+// HandleCallback 按签名审批链接记录审批结果，审批人为确认时认证的用户
+func (s *Service) HandleCallback(ctx context.Context, id, decision, expires, signature, approver string) (*automation.Execution, error) {
+	if _, err := s.VerifyCallback(ctx, id, decision, expires, signature); err != nil {
+		return nil, err
+	}
+
+	req := &automation.ApprovalRequest{Approver: approver}
+	if decision == automation.DecisionApprove {
+		req.Comment = "approved via chat"
+		return s.Approve(ctx, id, req)
+	}
+	req.Comment = "rejected via chat"
+	return s.Reject(ctx, id, req)
+}
+
+// ListAlertActions 获取告警的操作日志
+func (s *Service) ListAlertActions(ctx context.Context, alertID uint) ([]*automation.AutomationAction, error) {
+	actions, err := s.executions.ListActionsByAlert(ctx, alertID)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list alert actions", err)
+	}
+	return actions, nil
+}
+
+// start 创建执行，包含高风险操作的执行等待审批，试运行直接排队
+func (s *Service) start(ctx context.Context, runbook *automation.Runbook, alert *model.Alert, actor string, dryRun bool) (*automation.Execution, error) {
+	now := s.now()
+	execution := &automation.Execution{
+		ID:          uuid.New().String(),
+		RunbookID:   runbook.ID,
+		RunbookName: runbook.Name,
+		AlertID:     alert.ID,
+		Status:      automation.ExecutionQueued,
+		DryRun:      s.config.DryRun || runbook.DryRun || dryRun,
+		Steps:       runbook.Steps,
+		TriggeredBy: actor,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	execution.Context = newActionContext(execution.ID, alert)
+	if !execution.DryRun && s.requiresApproval(runbook) {
+		execution.Status = automation.ExecutionPendingApproval
+	}
+	if err := s.executions.Create(ctx, execution); err != nil {
+		return nil, errors.NewInternalError("failed to create execution", err)
+	}
+
+	s.logger.Info("runbook execution created",
+		zap.String("execution_id", execution.ID),
+		zap.String("runbook", runbook.Name),
+		zap.Uint("alert_id", alert.ID),
+		zap.String("status", string(execution.Status)),
+		zap.Bool("dry_run", execution.DryRun))
+	if execution.Status == automation.ExecutionPendingApproval {
+		s.requestApproval(ctx, runbook, execution)
+	}
+	return execution, nil
+}
+
+// decide 记录审批结果，只有待审批的执行可以审批
+func (s *Service) decide(ctx context.Context, id string, req *automation.ApprovalRequest, status automation.ExecutionStatus) (*automation.Execution, error) {
+	if strings.TrimSpace(req.Approver) == "" {
+		return nil, errors.NewValidationError("APPROVER_REQUIRED", "Approver is required")
+	}
+	execution, err := s.getExecution(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if execution.Status != automation.ExecutionPendingApproval {
+		return nil, errors.NewConflictError(fmt.Sprintf("Execution is %s, not pending approval", execution.Status))
+	}
+
+	now := s.now()
+	execution.Status = status
+	execution.ApprovedBy = req.Approver
+	execution.ApprovedAt = &now
+	execution.Comment = req.Comment
+	execution.UpdatedAt = now
+	if status.IsFinal() {
+		execution.FinishedAt = &now
+	}
+	ok, err := s.executions.Transition(ctx, execution, automation.ExecutionPendingApproval)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to update execution", err)
+	}
+	if !ok {
+		return nil, errors.NewConflictError("Execution has already been decided")
+	}
+	s.logger.Info("runbook execution decided",
+		zap.String("execution_id", id), zap.String("status", string(status)), zap.String("approver", req.Approver))
+	return execution, nil
+}
+
+// requestApproval 向运行手册配置的渠道发送审批消息，消息附带签名的批准与拒绝链接
+func (s *Service) requestApproval(ctx context.Context, runbook *automation.Runbook, execution *automation.Execution) {
+	if s.sender == nil || len(runbook.ApprovalChannelIDs) == 0 {
+		return
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "告警：%s（#%d）\n\n", execution.Context.Alert.Title, execution.AlertID)
+	content.WriteString("步骤：\n")
+	for i, step := range execution.Steps {
+		fmt.Fprintf(&content, "%d. %s：%s\n", i+1, step.Name, step.Action.Describe())
+	}
+
+	data := map[string]interface{}{
+		"execution_id": execution.ID,
+		"runbook_id":   runbook.ID,
+		"alert_id":     execution.AlertID,
+	}
+	if s.config.ApprovalSecret != "" && s.config.CallbackBaseURL != "" {
+		approveURL := s.callbackURL(execution, automation.DecisionApprove)
+		rejectURL := s.callbackURL(execution, automation.DecisionReject)
+		fmt.Fprintf(&content, "\n[批准](%s) | [拒绝](%s)\n", approveURL, rejectURL)
+		data["actions"] = []map[string]string{
+			{"title": "批准", "url": approveURL},
+			{"title": "拒绝", "url": rejectURL},
+		}
+	} else {
+		fmt.Fprintf(&content, "\n请通过 API 审批执行 %s\n", execution.ID)
+	}
+
+	message := &types.Message{
+		ID:        execution.ID,
+		Type:      "automation_approval",
+		Title:     fmt.Sprintf("运行手册待审批：%s", runbook.Name),
+		Content:   content.String(),
+		Data:      data,
+		Priority:  types.PriorityHigh,
+		CreatedAt: s.now(),
+	}
+	if _, err := s.sender.BroadcastMessage(ctx, runbook.ApprovalChannelIDs, message); err != nil {
+		s.logger.Warn("Failed to send approval request", zap.String("execution_id", execution.ID), zap.Error(err))
+	}
+}
+
+// callbackURL 生成聊天审批链接，链接在审批超时时失效
+func (s *Service) callbackURL(execution *automation.Execution, decision string) string {
+	expires := execution.CreatedAt.Add(s.config.ApprovalTimeout).Unix()
+	return fmt.Sprintf("%s/api/v1/automation/executions/%s/callback?decision=%s&expires=%d&signature=%s",
+		strings.TrimRight(s.config.CallbackBaseURL, "/"), execution.ID, decision, expires, s.sign(execution.ID, decision, expires))
+}
+
+// sign 对执行、决定与过期时间签名
+func (s *Service) sign(id, decision string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.config.ApprovalSecret))
+	fmt.Fprintf(mac, "%s:%s:%d", id, decision, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// requiresApproval 运行手册要求审批或任一步骤（含回滚）为高风险操作时需要审批
+func (s *Service) requiresApproval(runbook *automation.Runbook) bool {
+	if runbook.RequireApproval {
+		return true
+	}
+	for _, step := range runbook.Steps {
+		if s.isRisky(&step.Action) || (step.Rollback != nil && s.isRisky(step.Rollback)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRisky 判断操作是否需要审批，引用告警数据的 HTTP 与 n8n 操作也需要审批，
+// 告警标签来自 webhook，渲染后的地址和内容不可信
+func (s *Service) isRisky(action *automation.Action) bool {
+	if action.Templated() {
+		return true
+	}
+	for _, risky := range s.config.RiskyActions {
+		if risky == action.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) cooldown(runbook *automation.Runbook) time.Duration {
+	if runbook.Cooldown > 0 {
+		return time.Duration(runbook.Cooldown) * time.Second
+	}
+	return s.config.Cooldown
+}
+
+func (s *Service) getExecution(ctx context.Context, id string) (*automation.Execution, error) {
+	execution, err := s.executions.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get execution", err)
+	}
+	if execution == nil {
+		return nil, errors.NewNotFoundError("execution")
+	}
+	return execution, nil
+}
+
+// validateRunbook 校验名称、匹配器、步骤及步骤中的模板
+func validateRunbook(req *automation.RunbookRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.NewValidationError("INVALID_NAME", "Runbook name is required")
+	}
+	if _, err := gatewayApp.CompileMatchers(req.Matchers); err != nil {
+		return errors.NewValidationError("INVALID_MATCHER", err.Error())
+	}
+	if len(req.Steps) == 0 {
+		return errors.NewValidationError("INVALID_STEPS", "Runbook requires at least one step")
+	}
+	if req.Cooldown < 0 {
+		return errors.NewValidationError("INVALID_COOLDOWN", "Cooldown cannot be negative")
+	}
+
+	empty := &automation.ActionContext{}
+	for i, step := range req.Steps {
+		if strings.TrimSpace(step.Name) == "" {
+			return errors.NewValidationError("INVALID_STEPS", fmt.Sprintf("Step %d requires a name", i+1))
+		}
+		actions := []*automation.Action{&req.Steps[i].Action}
+		if step.Rollback != nil {
+			actions = append(actions, step.Rollback)
+		}
+		for _, action := range actions {
+			if err := action.Validate(); err != nil {
+				return errors.NewValidationError("INVALID_ACTION", fmt.Sprintf("Step %s: %v", step.Name, err))
+			}
+			if _, err := renderAction(action, empty); err != nil {
+				return errors.NewValidationError("INVALID_TEMPLATE", fmt.Sprintf("Step %s: %v", step.Name, err))
+			}
+		}
+	}
+	return nil
+}
+
+func applyRunbookRequest(runbook *automation.Runbook, req *automation.RunbookRequest, now time.Time) {
+	runbook.Name = req.Name
+	runbook.Description = req.Description
+	if req.Enabled != nil {
+		runbook.Enabled = *req.Enabled
+	}
+	runbook.Matchers = req.Matchers
+	runbook.Steps = req.Steps
+	runbook.DryRun = req.DryRun
+	runbook.RequireApproval = req.RequireApproval
+	runbook.ApprovalChannelIDs = req.ApprovalChannelIDs
+	runbook.Cooldown = req.Cooldown
+	runbook.UpdatedAt = now
+}
+
+// newActionContext 保存渲染操作模板所需的告警数据
+func newActionContext(executionID string, alert *model.Alert) *automation.ActionContext {
+	return &automation.ActionContext{
+		ExecutionID: executionID,
+		Alert: automation.AlertSummary{
+			ID:       alert.ID,
+			Name:     alert.Name,
+			Title:    alert.Title,
+			Level:    alert.Level,
+			Severity: alert.Severity,
+			Source:   alert.Source,
+			Content:  alert.Content,
+		},
+		Labels: gateway.ParseAlertLabels(alert),
+	}
+}
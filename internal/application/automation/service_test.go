@@ -0,0 +1,451 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/automation"
+	"alert_agent/internal/domain/channel"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	sharedErrors "alert_agent/internal/shared/errors"
+	"alert_agent/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryRunbookRepository 内存运行手册仓储
+type memoryRunbookRepository struct {
+	automation.RunbookRepository
+	runbooks []*automation.Runbook
+}
+
+func (r *memoryRunbookRepository) List(ctx context.Context, enabledOnly bool) ([]*automation.Runbook, error) {
+	var result []*automation.Runbook
+	for _, runbook := range r.runbooks {
+		if runbook.Enabled || !enabledOnly {
+			result = append(result, runbook)
+		}
+	}
+	return result, nil
+}
+
+// memoryExecutionRepository 内存执行仓储
+type memoryExecutionRepository struct {
+	executions map[string]*automation.Execution
+	actions    []*automation.AutomationAction
+}
+
+func newMemoryExecutionRepository() *memoryExecutionRepository {
+	return &memoryExecutionRepository{executions: map[string]*automation.Execution{}}
+}
+
+func (r *memoryExecutionRepository) Create(ctx context.Context, execution *automation.Execution) error {
+	copied := *execution
+	r.executions[execution.ID] = &copied
+	return nil
+}
+
+func (r *memoryExecutionRepository) GetByID(ctx context.Context, id string) (*automation.Execution, error) {
+	execution, ok := r.executions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *execution
+	return &copied, nil
+}
+
+func (r *memoryExecutionRepository) GetLatest(ctx context.Context, runbookID string, alertID uint) (*automation.Execution, error) {
+	var latest *automation.Execution
+	for _, execution := range r.executions {
+		if execution.RunbookID == runbookID && execution.AlertID == alertID &&
+			(latest == nil || execution.CreatedAt.After(latest.CreatedAt)) {
+			latest = execution
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return r.GetByID(ctx, latest.ID)
+}
+
+func (r *memoryExecutionRepository) List(ctx context.Context, filter *automation.ExecutionFilter) ([]*automation.Execution, int64, error) {
+	executions, _ := r.ListByStatus(ctx, filter.Status, 0)
+	return executions, int64(len(executions)), nil
+}
+
+func (r *memoryExecutionRepository) Update(ctx context.Context, execution *automation.Execution) error {
+	return r.Create(ctx, execution)
+}
+
+func (r *memoryExecutionRepository) Transition(ctx context.Context, execution *automation.Execution, from automation.ExecutionStatus) (bool, error) {
+	current, ok := r.executions[execution.ID]
+	if !ok || current.Status != from {
+		return false, nil
+	}
+	return true, r.Create(ctx, execution)
+}
+
+func (r *memoryExecutionRepository) ListByStatus(ctx context.Context, status automation.ExecutionStatus, limit int) ([]*automation.Execution, error) {
+	var result []*automation.Execution
+	for id, execution := range r.executions {
+		if status == "" || execution.Status == status {
+			copied, _ := r.GetByID(ctx, id)
+			result = append(result, copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (r *memoryExecutionRepository) ListPendingBefore(ctx context.Context, before time.Time) ([]*automation.Execution, error) {
+	pending, _ := r.ListByStatus(ctx, automation.ExecutionPendingApproval, 0)
+	var result []*automation.Execution
+	for _, execution := range pending {
+		if execution.CreatedAt.Before(before) {
+			result = append(result, execution)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryExecutionRepository) AddAction(ctx context.Context, action *automation.AutomationAction) error {
+	r.actions = append(r.actions, action)
+	return nil
+}
+
+func (r *memoryExecutionRepository) ListActions(ctx context.Context, executionID string) ([]*automation.AutomationAction, error) {
+	var result []*automation.AutomationAction
+	for _, action := range r.actions {
+		if action.ExecutionID == executionID {
+			result = append(result, action)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryExecutionRepository) ListActionsByAlert(ctx context.Context, alertID uint) ([]*automation.AutomationAction, error) {
+	var result []*automation.AutomationAction
+	for _, action := range r.actions {
+		if action.AlertID == strconv.FormatUint(uint64(alertID), 10) {
+			result = append(result, action)
+		}
+	}
+	return result, nil
+}
+
+// recordingSender 记录发送的审批消息
+type recordingSender struct {
+	messages []*types.Message
+	channels [][]string
+}
+
+func (s *recordingSender) BroadcastMessage(ctx context.Context, channelIDs []string, message *types.Message) ([]*channel.SendResult, error) {
+	s.messages = append(s.messages, message)
+	s.channels = append(s.channels, channelIDs)
+	return nil, nil
+}
+
+// recordingExecutor 记录执行的操作，目标在 failOn 中时返回错误
+type recordingExecutor struct {
+	calls  *[]string
+	failOn map[string]bool
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, action *automation.Action) (*automation.ActionResult, error) {
+	target := action.Describe()
+	*e.calls = append(*e.calls, target)
+	if e.failOn[target] {
+		return &automation.ActionResult{Output: "boom"}, errors.New("action failed")
+	}
+	return &automation.ActionResult{Output: "ok"}, nil
+}
+
+func replicas(n int) *int {
+	return &n
+}
+
+// restartRunbook 内存告警时扩容并重启，失败时缩回原副本数
+func restartRunbook() *automation.Runbook {
+	return &automation.Runbook{
+		ID:                 "rb-1",
+		Name:               "restart-on-oom",
+		Enabled:            true,
+		Matchers:           []gateway.Matcher{{Name: "alertname", Type: gateway.MatchEqual, Value: "PodOOMKilled"}, {Name: "env", Type: gateway.MatchRegexp, Value: "prod|staging"}},
+		ApprovalChannelIDs: []string{"ops"},
+		Steps: []automation.Step{
+			{
+				Name:     "notify",
+				Action:   automation.Action{Type: automation.ActionHTTP, HTTP: &automation.HTTPAction{URL: "http://hooks/{{ .Labels.deployment }}", Headers: map[string]string{"Authorization": "Bearer secret"}}},
+				Rollback: &automation.Action{Type: automation.ActionHTTP, HTTP: &automation.HTTPAction{URL: "http://hooks/{{ .Labels.deployment }}/cancel"}},
+			},
+			{
+				Name:     "scale",
+				Action:   automation.Action{Type: automation.ActionKubernetes, Kubernetes: &automation.KubernetesAction{Operation: automation.KubernetesScaleDeployment, Namespace: "{{ .Labels.namespace }}", Name: "{{ .Labels.deployment }}", Replicas: replicas(4)}},
+				Rollback: &automation.Action{Type: automation.ActionKubernetes, Kubernetes: &automation.KubernetesAction{Operation: automation.KubernetesScaleDeployment, Namespace: "{{ .Labels.namespace }}", Name: "{{ .Labels.deployment }}", Replicas: replicas(2)}},
+			},
+			{
+				Name:   "restart",
+				Action: automation.Action{Type: automation.ActionKubernetes, Kubernetes: &automation.KubernetesAction{Operation: automation.KubernetesRestartDeployment, Namespace: "{{ .Labels.namespace }}", Name: "{{ .Labels.deployment }}"}},
+			},
+		},
+	}
+}
+
+func oomAlert() *model.Alert {
+	return &model.Alert{ID: 7, Name: "PodOOMKilled", Title: "api OOM", Level: model.AlertLevelHigh, Labels: `{"env":"prod","namespace":"shop","deployment":"api"}`}
+}
+
+func newTestService(runbooks ...*automation.Runbook) (*Service, *memoryExecutionRepository, *recordingSender) {
+	config := automation.DefaultConfig()
+	config.DryRun = false
+	config.ApprovalSecret = "secret"
+	config.CallbackBaseURL = "https://alerts.example.com/"
+	executions := newMemoryExecutionRepository()
+	sender := &recordingSender{}
+	service := NewService(&memoryRunbookRepository{runbooks: runbooks}, executions, nil, sender, config, zap.NewNop())
+	return service, executions, sender
+}
+
+func TestService_TriggerForAlert(t *testing.T) {
+	ctx := context.Background()
+	webhook := &automation.Runbook{
+		ID:       "rb-2",
+		Name:     "webhook",
+		Enabled:  true,
+		DryRun:   true,
+		Matchers: []gateway.Matcher{{Name: "alertname", Value: "PodOOMKilled"}},
+		Steps:    []automation.Step{{Name: "call", Action: automation.Action{Type: automation.ActionHTTP, HTTP: &automation.HTTPAction{URL: "http://hooks"}}}},
+	}
+	manual := &automation.Runbook{ID: "rb-3", Name: "manual", Enabled: true, Steps: webhook.Steps}
+	service, _, sender := newTestService(restartRunbook(), webhook, manual)
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return start }
+
+	executions, err := service.TriggerForAlert(ctx, oomAlert())
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+
+	// Kubernetes 操作需要审批，审批消息附带签名链接
+	assert.Equal(t, automation.ExecutionPendingApproval, executions[0].Status)
+	assert.Equal(t, "shop", executions[0].Context.Labels["namespace"])
+	require.Len(t, sender.messages, 1)
+	assert.Equal(t, []string{"ops"}, sender.channels[0])
+	actions := sender.messages[0].Data["actions"].([]map[string]string)
+	require.Len(t, actions, 2)
+	assert.Contains(t, actions[0]["url"], "https://alerts.example.com/api/v1/automation/executions/"+executions[0].ID+"/callback?decision=approve")
+
+	// 试运行的运行手册不需要审批
+	assert.Equal(t, automation.ExecutionQueued, executions[1].Status)
+	assert.True(t, executions[1].DryRun)
+
+	// 重复投递时运行手册仍在进行中，不再触发
+	executions, err = service.TriggerForAlert(ctx, oomAlert())
+	require.NoError(t, err)
+	assert.Empty(t, executions)
+
+	// 标签不满足匹配器
+	alert := oomAlert()
+	alert.Labels = `{"env":"dev"}`
+	alert.ID = 8
+	executions, err = service.TriggerForAlert(ctx, alert)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, "webhook", executions[0].RunbookName)
+}
+
+func TestService_TemplatedHTTPRequiresApproval(t *testing.T) {
+	service, _, _ := newTestService()
+	static := &automation.Runbook{Steps: []automation.Step{{Name: "call", Action: automation.Action{Type: automation.ActionHTTP, HTTP: &automation.HTTPAction{URL: "http://hooks"}}}}}
+	assert.False(t, service.requiresApproval(static))
+
+	// 渲染告警标签的 HTTP 与 n8n 操作需要审批
+	templated := &automation.Runbook{Steps: []automation.Step{{Name: "call", Action: automation.Action{Type: automation.ActionHTTP, HTTP: &automation.HTTPAction{URL: "http://hooks", Body: `{"service":"{{ .Labels.service }}"}`}}}}}
+	assert.True(t, service.requiresApproval(templated))
+	workflow := &automation.Runbook{Steps: []automation.Step{{Name: "n8n", Action: automation.Action{Type: automation.ActionN8N, N8N: &automation.N8NAction{WorkflowID: "wf-1", Data: map[string]string{"host": "{{ .Labels.instance }}"}}}}}}
+	assert.True(t, service.requiresApproval(workflow))
+}
+
+func TestService_Approval(t *testing.T) {
+	ctx := context.Background()
+	service, executions, sender := newTestService(restartRunbook())
+	triggered, err := service.TriggerForAlert(ctx, oomAlert())
+	require.NoError(t, err)
+	id := triggered[0].ID
+
+	_, err = service.Approve(ctx, id, &automation.ApprovalRequest{})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+
+	// 篡改的链接被拒绝
+	link, err := url.Parse(sender.messages[0].Data["actions"].([]map[string]string)[1]["url"])
+	require.NoError(t, err)
+	query := link.Query()
+	_, err = service.HandleCallback(ctx, id, automation.DecisionApprove, query.Get("expires"), query.Get("signature"), "bob")
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeForbidden))
+
+	// 打开链接只校验签名，不改变执行状态；确认时必须有认证的审批人
+	execution, err := service.VerifyCallback(ctx, id, query.Get("decision"), query.Get("expires"), query.Get("signature"))
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionPendingApproval, execution.Status)
+	_, err = service.HandleCallback(ctx, id, query.Get("decision"), query.Get("expires"), query.Get("signature"), "")
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+
+	// 拒绝链接有效，执行被拒绝后不能再审批
+	execution, err = service.HandleCallback(ctx, id, query.Get("decision"), query.Get("expires"), query.Get("signature"), "bob")
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionRejected, execution.Status)
+	assert.Equal(t, "bob", execution.ApprovedBy)
+	_, err = service.Approve(ctx, id, &automation.ApprovalRequest{Approver: "alice"})
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeConflict))
+
+	// 冷却时间过后再次触发，通过 API 审批
+	service.now = func() time.Time { return time.Now().Add(time.Hour) }
+	triggered, err = service.TriggerForAlert(ctx, oomAlert())
+	require.NoError(t, err)
+	require.Len(t, triggered, 1)
+	execution, err = service.Approve(ctx, triggered[0].ID, &automation.ApprovalRequest{Approver: "alice", Comment: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionQueued, execution.Status)
+	stored, _ := executions.GetByID(ctx, execution.ID)
+	assert.Equal(t, "alice", stored.ApprovedBy)
+
+	// 过期的链接失效
+	service.now = func() time.Time { return time.Now().Add(3 * time.Hour) }
+	_, err = service.HandleCallback(ctx, id, query.Get("decision"), query.Get("expires"), query.Get("signature"), "bob")
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeForbidden))
+}
+
+func TestRunner_Execute(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	executor := &recordingExecutor{calls: &calls, failOn: map[string]bool{"restart_deployment shop/api": true}}
+	service, executions, _ := newTestService(restartRunbook())
+	runner := NewRunner(executions, map[automation.ActionType]automation.ActionExecutor{
+		automation.ActionHTTP:       executor,
+		automation.ActionKubernetes: executor,
+	}, service.config, zap.NewNop())
+
+	triggered, err := service.TriggerForAlert(ctx, oomAlert())
+	require.NoError(t, err)
+	_, err = service.Approve(ctx, triggered[0].ID, &automation.ApprovalRequest{Approver: "alice"})
+	require.NoError(t, err)
+
+	// 重启失败，按相反顺序回滚扩容与通知
+	require.NoError(t, runner.Poll(ctx))
+	assert.Equal(t, []string{
+		"POST http://hooks/api",
+		"scale_deployment shop/api to 4",
+		"restart_deployment shop/api",
+		"scale_deployment shop/api to 2",
+		"POST http://hooks/api/cancel",
+	}, calls)
+
+	execution, logs, err := service.GetExecution(ctx, triggered[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionRolledBack, execution.Status)
+	assert.Contains(t, execution.Error, "step restart")
+	require.Len(t, logs, 5)
+	assert.Equal(t, automation.ActionFailed, logs[2].Status)
+	assert.Equal(t, automation.PhaseRollback, logs[3].Phase)
+	assert.Equal(t, "7", logs[3].AlertID)
+	assert.Equal(t, "***", logs[0].Parameters.HTTP.Headers["Authorization"])
+
+	// 已完成的执行不会被再次领取
+	require.NoError(t, runner.Poll(ctx))
+	assert.Len(t, calls, 5)
+
+	alertLogs, err := service.ListAlertActions(ctx, 7)
+	require.NoError(t, err)
+	assert.Len(t, alertLogs, 5)
+}
+
+func TestRunner_DryRunAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	var calls []string
+	service, executions, _ := newTestService(restartRunbook())
+	runner := NewRunner(executions, map[automation.ActionType]automation.ActionExecutor{
+		automation.ActionKubernetes: &recordingExecutor{calls: &calls},
+	}, service.config, zap.NewNop())
+
+	// 试运行只记录操作内容
+	execution, err := service.start(ctx, restartRunbook(), oomAlert(), "alice", true)
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionQueued, execution.Status)
+	require.NoError(t, runner.Poll(ctx))
+	assert.Empty(t, calls)
+	stored, logs, err := service.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionSucceeded, stored.Status)
+	require.Len(t, logs, 3)
+	assert.True(t, logs[1].DryRun)
+	assert.Equal(t, "dry run: scale_deployment shop/api to 4", logs[1].Result)
+
+	// 超时未审批的执行过期
+	pending, err := service.start(ctx, restartRunbook(), oomAlert(), "alice", false)
+	require.NoError(t, err)
+	runner.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	runner.expireApprovals(ctx)
+	stored, _, err = service.GetExecution(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionExpired, stored.Status)
+}
+
+func TestRunner_FailAbandoned(t *testing.T) {
+	ctx := context.Background()
+	service, executions, _ := newTestService(restartRunbook())
+	runner := NewRunner(executions, nil, service.config, zap.NewNop())
+
+	// 模拟 worker 领取后崩溃，执行停留在 running
+	execution, err := service.start(ctx, restartRunbook(), oomAlert(), "alice", true)
+	require.NoError(t, err)
+	started := time.Now()
+	execution.Status = automation.ExecutionRunning
+	execution.StartedAt = &started
+	claimed, err := executions.Transition(ctx, execution, automation.ExecutionQueued)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// 未超过所有操作超时之和时保持运行
+	runner.failAbandoned(ctx)
+	stored, _, err := service.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionRunning, stored.Status)
+
+	runner.now = func() time.Time { return started.Add(runner.maxDuration(execution) + time.Second) }
+	runner.failAbandoned(ctx)
+	stored, _, err = service.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, automation.ExecutionFailed, stored.Status)
+	assert.Contains(t, stored.Error, "abandoned")
+	require.NotNil(t, stored.FinishedAt)
+
+	// 冷却期过后运行手册不再被遗留的执行阻塞
+	service.now = func() time.Time { return time.Now().Add(time.Hour) }
+	triggered, err := service.TriggerForAlert(ctx, oomAlert())
+	require.NoError(t, err)
+	assert.NotEmpty(t, triggered)
+}
+
+func TestValidateRunbook(t *testing.T) {
+	valid := restartRunbook()
+	req := &automation.RunbookRequest{Name: valid.Name, Matchers: valid.Matchers, Steps: valid.Steps}
+	require.NoError(t, validateRunbook(req))
+
+	invalid := []*automation.RunbookRequest{
+		{Name: "", Steps: valid.Steps},
+		{Name: "x"},
+		{Name: "x", Steps: valid.Steps, Matchers: []gateway.Matcher{{Name: "env", Type: gateway.MatchRegexp, Value: "("}}},
+		{Name: "x", Steps: []automation.Step{{Name: "k8s", Action: automation.Action{Type: automation.ActionKubernetes, Kubernetes: &automation.KubernetesAction{Operation: "drain", Namespace: "a", Name: "b"}}}}},
+		{Name: "x", Steps: []automation.Step{{Name: "tpl", Action: automation.Action{Type: automation.ActionHTTP, HTTP: &automation.HTTPAction{URL: "http://{{ .Labels"}}}}},
+	}
+	for _, req := range invalid {
+		assert.True(t, sharedErrors.IsErrorType(validateRunbook(req), sharedErrors.ErrorTypeValidation), req.Name)
+	}
+}
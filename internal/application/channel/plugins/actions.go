@@ -0,0 +1,41 @@
+package plugins
+
+import (
+	"fmt"
+
+	"alert_agent/pkg/types"
+)
+
+// messageActionsKey 消息数据中按钮列表的键，每个按钮包含 title 与 url
+const messageActionsKey = "actions"
+
+// messageAction 消息中的链接按钮，如运行手册审批的批准与拒绝
+type messageAction struct {
+	Title string
+	URL   string
+}
+
+// messageActions 读取消息数据中的按钮，兼容经过 JSON 序列化的消息
+func messageActions(message *types.Message) []messageAction {
+	var actions []messageAction
+	switch raw := message.Data[messageActionsKey].(type) {
+	case []map[string]string:
+		for _, item := range raw {
+			actions = append(actions, messageAction{Title: item["title"], URL: item["url"]})
+		}
+	case []interface{}:
+		for _, item := range raw {
+			if m, ok := item.(map[string]interface{}); ok {
+				actions = append(actions, messageAction{Title: fmt.Sprint(m["title"]), URL: fmt.Sprint(m["url"])})
+			}
+		}
+	}
+
+	valid := actions[:0]
+	for _, action := range actions {
+		if action.Title != "" && action.URL != "" {
+			valid = append(valid, action)
+		}
+	}
+	return valid
+}
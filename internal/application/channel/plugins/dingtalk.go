@@ -332,6 +332,22 @@ func (p *DingTalkPlugin) buildDingTalkMessage(config map[string]interface{}, mes
 	if len(atInfo) > 0 {
 		dingMsg["at"] = atInfo
 	}
+
+	// 带按钮的消息使用 ActionCard
+	if actions := messageActions(message); len(actions) > 0 {
+		btns := make([]map[string]string, 0, len(actions))
+		for _, action := range actions {
+			btns = append(btns, map[string]string{"title": action.Title, "actionURL": action.URL})
+		}
+		dingMsg["msgtype"] = "actionCard"
+		dingMsg["actionCard"] = map[string]interface{}{
+			"title":          message.Title,
+			"text":           p.formatMarkdownContent(message),
+			"btnOrientation": "1",
+			"btns":           btns,
+		}
+		delete(dingMsg, "markdown")
+	}
 	
 	return dingMsg, nil
 }
@@ -371,6 +387,9 @@ func (p *DingTalkPlugin) formatMarkdownContent(message *types.Message) string {
 	if len(message.Data) > 0 {
 		content += "**详细信息**:\n\n"
 		for key, value := range message.Data {
+			if key == messageActionsKey {
+				continue
+			}
 			content += fmt.Sprintf("- **%s**: %v\n", key, value)
 		}
 	}
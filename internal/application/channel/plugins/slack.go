@@ -51,8 +51,16 @@ type SlackField struct {
 
 // SlackBlock Slack块
 type SlackBlock struct {
-	Type string      `json:"type"`
-	Text *SlackText  `json:"text,omitempty"`
+	Type     string         `json:"type"`
+	Text     *SlackText     `json:"text,omitempty"`
+	Elements []SlackElement `json:"elements,omitempty"`
+}
+
+// SlackElement Slack交互元素，用于链接按钮
+type SlackElement struct {
+	Type string     `json:"type"`
+	Text *SlackText `json:"text,omitempty"`
+	URL  string     `json:"url,omitempty"`
 }
 
 // SlackText Slack文本
@@ -342,6 +350,11 @@ func (p *SlackPlugin) buildSlackMessage(config *channel.ChannelConfig, message *
 		}
 	}
 	
+	// 按钮只能放在 Blocks 中
+	if len(messageActions(message)) > 0 {
+		useBlocks = true
+	}
+
 	if useBlocks {
 		// 使用Blocks格式
 		slackMsg.Blocks = p.buildSlackBlocks(message)
@@ -467,6 +480,18 @@ func (p *SlackPlugin) buildSlackBlocks(message *types.Message) []SlackBlock {
 			Text: infoText,
 		},
 	})
+
+	if actions := messageActions(message); len(actions) > 0 {
+		elements := make([]SlackElement, 0, len(actions))
+		for _, action := range actions {
+			elements = append(elements, SlackElement{
+				Type: "button",
+				Text: &SlackText{Type: "plain_text", Text: action.Title},
+				URL:  action.URL,
+			})
+		}
+		blocks = append(blocks, SlackBlock{Type: "actions", Elements: elements})
+	}
 	
 	return blocks
 }
@@ -0,0 +1,392 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// ruleConfidence 关键词规则分类的置信度，低于默认阈值以便 LLM 兜底
+const ruleConfidence = 0.5
+
+// naiveBayesHead 多项式朴素贝叶斯的一个预测目标，词按是否出现计数
+type naiveBayesHead struct {
+	Documents  map[string]int            `json:"documents"`   // 各类别样本数
+	TermCounts map[string]map[string]int `json:"term_counts"` // 各类别中词出现的样本数
+	TermTotals map[string]int            `json:"term_totals"` // 各类别词出现总数
+	Vocabulary int                       `json:"vocabulary"`
+}
+
+func newNaiveBayesHead() *naiveBayesHead {
+	return &naiveBayesHead{
+		Documents:  make(map[string]int),
+		TermCounts: make(map[string]map[string]int),
+		TermTotals: make(map[string]int),
+	}
+}
+
+// add 加入一个样本
+func (h *naiveBayesHead) add(class string, tokens []string) {
+	h.Documents[class]++
+	counts, ok := h.TermCounts[class]
+	if !ok {
+		counts = make(map[string]int)
+		h.TermCounts[class] = counts
+	}
+	for _, token := range tokens {
+		counts[token]++
+		h.TermTotals[class]++
+	}
+}
+
+// finish 统计词表大小
+func (h *naiveBayesHead) finish() {
+	vocabulary := make(map[string]struct{})
+	for _, counts := range h.TermCounts {
+		for token := range counts {
+			vocabulary[token] = struct{}{}
+		}
+	}
+	h.Vocabulary = len(vocabulary)
+}
+
+// known 词是否在训练集中出现过，未出现的词不参与计算
+func (h *naiveBayesHead) known(token string) bool {
+	for _, counts := range h.TermCounts {
+		if counts[token] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// predict 返回后验概率最大的类别及其概率
+func (h *naiveBayesHead) predict(tokens []string, smoothing float64) (string, float64) {
+	total := 0
+	for _, n := range h.Documents {
+		total += n
+	}
+	if total == 0 {
+		return "", 0
+	}
+
+	var known []string
+	for _, token := range tokens {
+		if h.known(token) {
+			known = append(known, token)
+		}
+	}
+
+	classes := make([]string, 0, len(h.Documents))
+	for class := range h.Documents {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	scores := make([]float64, len(classes))
+	best := 0
+	for i, class := range classes {
+		score := math.Log(float64(h.Documents[class]) / float64(total))
+		denominator := float64(h.TermTotals[class]) + smoothing*float64(h.Vocabulary)
+		for _, token := range known {
+			score += math.Log((float64(h.TermCounts[class][token]) + smoothing) / denominator)
+		}
+		scores[i] = score
+		if score > scores[best] {
+			best = i
+		}
+	}
+
+	// 对数概率归一化为后验概率
+	sum := 0.0
+	for _, score := range scores {
+		sum += math.Exp(score - scores[best])
+	}
+	return classes[best], 1 / sum
+}
+
+// classifierParams 序列化保存的模型参数
+type classifierParams struct {
+	Smoothing float64         `json:"smoothing"`
+	Category  *naiveBayesHead `json:"category"`
+	Severity  *naiveBayesHead `json:"severity"`
+}
+
+// classify 预测类别与严重程度，严重程度的概率低于 minSeverity 时保留告警级别
+func (p *classifierParams) classify(alert *model.Alert, minSeverity float64) *gateway.Classification {
+	tokens := classifierTokens(alert)
+	category, confidence := p.Category.predict(tokens, p.Smoothing)
+	severity, probability := p.Severity.predict(tokens, p.Smoothing)
+	if severity == "" || probability < minSeverity {
+		severity = alert.Level
+	}
+	return &gateway.Classification{
+		Category:   category,
+		Severity:   severity,
+		Confidence: confidence,
+		Source:     gateway.ClassificationSourceModel,
+	}
+}
+
+// classifierTokens 分类特征：名称、标题与内容的词，以及来源、级别和标签
+func classifierTokens(alert *model.Alert) []string {
+	tokens := make([]string, 0, 32)
+	for term := range termFrequency(alert.Name + "\n" + alertText(alert)) {
+		tokens = append(tokens, term)
+	}
+	if alert.Source != "" {
+		tokens = append(tokens, "source:"+alert.Source)
+	}
+	if alert.Level != "" {
+		tokens = append(tokens, "level:"+alert.Level)
+	}
+	for key, value := range gateway.ParseAlertLabels(alert) {
+		tokens = append(tokens, "label:"+key+"="+value)
+	}
+	return tokens
+}
+
+// ClassifierTrainConfig 分类模型训练配置
+type ClassifierTrainConfig struct {
+	Smoothing  float64 `json:"smoothing"`   // 拉普拉斯平滑系数
+	Holdout    float64 `json:"holdout"`     // 用于评估的样本比例，为 0 时不评估
+	MinSamples int     `json:"min_samples"` // 训练所需的最少样本数
+}
+
+// DefaultClassifierTrainConfig 默认训练配置
+func DefaultClassifierTrainConfig() ClassifierTrainConfig {
+	return ClassifierTrainConfig{Smoothing: 1, Holdout: 0.2, MinSamples: 50}
+}
+
+// ClassifierReport 训练报告
+type ClassifierReport struct {
+	Version          string         `json:"version"`
+	Samples          int            `json:"samples"`
+	Evaluated        int            `json:"evaluated"`         // 留出集样本数
+	Accuracy         float64        `json:"accuracy"`          // 留出集类别准确率
+	SeverityAccuracy float64        `json:"severity_accuracy"` // 留出集严重程度准确率
+	Categories       map[string]int `json:"categories"`        // 各类别样本数
+}
+
+// TrainClassifier 训练分类模型，在留出集上评估后使用全部样本生成新版本
+func TrainClassifier(samples []*gateway.TrainingSample, version string, config ClassifierTrainConfig) (*gateway.ClassifierModel, *ClassifierReport, error) {
+	if len(samples) < config.MinSamples {
+		return nil, nil, sharedErrors.NewValidationError("INSUFFICIENT_SAMPLES",
+			fmt.Sprintf("need at least %d training samples, got %d", config.MinSamples, len(samples)))
+	}
+	report := &ClassifierReport{Version: version, Samples: len(samples), Categories: make(map[string]int)}
+	for _, sample := range samples {
+		report.Categories[sample.Category]++
+	}
+	if len(report.Categories) < 2 {
+		return nil, nil, sharedErrors.NewValidationError("INSUFFICIENT_CATEGORIES", "training samples must cover at least two categories")
+	}
+
+	// 按固定间隔抽取留出集，保证同一批样本的评估结果可复现
+	if config.Holdout > 0 {
+		every := int(math.Round(1 / config.Holdout))
+		var train, holdout []*gateway.TrainingSample
+		for i, sample := range samples {
+			if every > 1 && i%every == every-1 {
+				holdout = append(holdout, sample)
+			} else {
+				train = append(train, sample)
+			}
+		}
+		if len(holdout) > 0 {
+			report.Evaluated = len(holdout)
+			report.Accuracy, report.SeverityAccuracy = evaluateClassifier(fitClassifier(train, config.Smoothing), holdout)
+		}
+	}
+
+	params, err := json.Marshal(fitClassifier(samples, config.Smoothing))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal classifier params: %w", err)
+	}
+	return &gateway.ClassifierModel{
+		Version:  version,
+		Samples:  len(samples),
+		Accuracy: report.Accuracy,
+		Params:   string(params),
+	}, report, nil
+}
+
+// fitClassifier 统计样本生成模型参数
+func fitClassifier(samples []*gateway.TrainingSample, smoothing float64) *classifierParams {
+	params := &classifierParams{Smoothing: smoothing, Category: newNaiveBayesHead(), Severity: newNaiveBayesHead()}
+	for _, sample := range samples {
+		tokens := classifierTokens(sample.Alert)
+		params.Category.add(sample.Category, tokens)
+		if sample.Severity != "" {
+			params.Severity.add(sample.Severity, tokens)
+		}
+	}
+	params.Category.finish()
+	params.Severity.finish()
+	return params
+}
+
+// evaluateClassifier 计算类别与严重程度准确率，严重程度只统计有标注的样本
+func evaluateClassifier(params *classifierParams, samples []*gateway.TrainingSample) (float64, float64) {
+	correct, severityCorrect, severityTotal := 0, 0, 0
+	for _, sample := range samples {
+		result := params.classify(sample.Alert, 0)
+		if result.Category == sample.Category {
+			correct++
+		}
+		if sample.Severity != "" {
+			severityTotal++
+			if result.Severity == sample.Severity {
+				severityCorrect++
+			}
+		}
+	}
+	severityAccuracy := 0.0
+	if severityTotal > 0 {
+		severityAccuracy = float64(severityCorrect) / float64(severityTotal)
+	}
+	return float64(correct) / float64(len(samples)), severityAccuracy
+}
+
+// AlertClassifierService 基于朴素贝叶斯的告警分类服务
+// 使用启用的模型版本分类，未训练模型时退回关键词规则，置信度不足时调用 LLM 兜底
+type AlertClassifierService struct {
+	repository gateway.ClassifierModelRepository
+	fallback   gateway.AlertClassifier
+	config     gateway.ClassifierConfig
+	logger     *zap.Logger
+
+	mu      sync.RWMutex
+	params  *classifierParams
+	version string
+}
+
+// NewAlertClassifierService 创建告警分类服务，fallback 为 nil 时不调用 LLM
+func NewAlertClassifierService(
+	repository gateway.ClassifierModelRepository,
+	fallback gateway.AlertClassifier,
+	config gateway.ClassifierConfig,
+	logger *zap.Logger,
+) *AlertClassifierService {
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = gateway.DefaultClassifierConfig().ReloadInterval
+	}
+	return &AlertClassifierService{
+		repository: repository,
+		fallback:   fallback,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// Run 加载启用的模型版本，并定期检查版本变化直到 ctx 结束
+func (s *AlertClassifierService) Run(ctx context.Context) error {
+	if err := s.Reload(ctx); err != nil {
+		s.logger.Warn("Failed to load alert classifier model", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				s.logger.Warn("Failed to reload alert classifier model", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reload 启用版本变化时重新加载模型参数，没有启用版本时使用关键词规则
+func (s *AlertClassifierService) Reload(ctx context.Context) error {
+	active, err := s.repository.GetActive(ctx)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		s.mu.Lock()
+		s.params, s.version = nil, ""
+		s.mu.Unlock()
+		return nil
+	}
+	if active.Version == s.Version() {
+		return nil
+	}
+
+	var params classifierParams
+	if err := json.Unmarshal([]byte(active.Params), &params); err != nil {
+		return fmt.Errorf("failed to unmarshal classifier model %s: %w", active.Version, err)
+	}
+	if params.Category == nil || params.Severity == nil {
+		return fmt.Errorf("classifier model %s is incomplete", active.Version)
+	}
+
+	s.mu.Lock()
+	s.params, s.version = &params, active.Version
+	s.mu.Unlock()
+	s.logger.Info("Alert classifier model loaded",
+		zap.String("version", active.Version),
+		zap.Int("samples", active.Samples),
+		zap.Float64("accuracy", active.Accuracy))
+	return nil
+}
+
+// Version 当前使用的模型版本，未加载时为空
+func (s *AlertClassifierService) Version() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.version
+}
+
+// Classify 预测告警类别并重新评估严重程度
+func (s *AlertClassifierService) Classify(ctx context.Context, alert *model.Alert) (*gateway.Classification, error) {
+	s.mu.RLock()
+	params, version := s.params, s.version
+	s.mu.RUnlock()
+
+	var result *gateway.Classification
+	if params != nil {
+		result = params.classify(alert, s.config.ConfidenceThreshold)
+		result.ModelVersion = version
+	} else {
+		result = classifyByRules(alert)
+	}
+	if result.Confidence >= s.config.ConfidenceThreshold || s.fallback == nil {
+		return result, nil
+	}
+
+	fallback, err := s.fallback.Classify(ctx, alert)
+	if err != nil {
+		s.logger.Warn("LLM classification fallback failed",
+			zap.Uint("alert_id", alert.ID),
+			zap.Float64("confidence", result.Confidence),
+			zap.Error(err))
+		return result, nil
+	}
+	if fallback.Confidence < result.Confidence {
+		return result, nil
+	}
+	return fallback, nil
+}
+
+// classifyByRules 关键词规则分类，严重程度沿用告警级别
+func classifyByRules(alert *model.Alert) *gateway.Classification {
+	return &gateway.Classification{
+		Category:   categorizeAlertByName(alert),
+		Severity:   alert.Level,
+		Confidence: ruleConfidence,
+		Source:     gateway.ClassificationSourceRules,
+	}
+}
@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryClassifierRepository 内存模型版本仓储
+type memoryClassifierRepository struct {
+	gateway.ClassifierModelRepository
+	active *gateway.ClassifierModel
+}
+
+func (r *memoryClassifierRepository) GetActive(ctx context.Context) (*gateway.ClassifierModel, error) {
+	return r.active, nil
+}
+
+// stubClassifier 返回固定结果的分类器，记录调用次数
+type stubClassifier struct {
+	result *gateway.Classification
+	err    error
+	calls  int
+}
+
+func (c *stubClassifier) Classify(ctx context.Context, alert *model.Alert) (*gateway.Classification, error) {
+	c.calls++
+	return c.result, c.err
+}
+
+// trainingSamples 生成三个类别的历史告警，生产环境的慢查询被处理为严重
+func trainingSamples() []*gateway.TrainingSample {
+	var samples []*gateway.TrainingSample
+	for i := 0; i < 20; i++ {
+		samples = append(samples,
+			&gateway.TrainingSample{
+				Alert:    &model.Alert{Name: "HostHighLoad", Title: fmt.Sprintf("node-%d cpu usage high", i), Content: "cpu usage above 90%", Level: model.AlertLevelHigh},
+				Category: "performance",
+				Severity: model.AlertLevelHigh,
+			},
+			&gateway.TrainingSample{
+				Alert:    &model.Alert{Name: "VolumeFull", Title: fmt.Sprintf("volume %d almost full", i), Content: "free space below 5%", Level: model.AlertLevelMedium},
+				Category: "storage",
+				Severity: model.AlertLevelMedium,
+			},
+			&gateway.TrainingSample{
+				Alert:    &model.Alert{Name: "SlowQueries", Title: fmt.Sprintf("mysql slow queries on db-%d", i), Content: "slow query count increasing", Level: model.AlertLevelMedium, Labels: `{"env":"prod"}`},
+				Category: "database",
+				Severity: model.AlertLevelCritical,
+			},
+		)
+	}
+	return samples
+}
+
+func TestTrainClassifier(t *testing.T) {
+	config := DefaultClassifierTrainConfig()
+
+	_, _, err := TrainClassifier(trainingSamples()[:10], "v1", config)
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+
+	var single []*gateway.TrainingSample
+	for _, sample := range trainingSamples() {
+		if sample.Category == "storage" {
+			single = append(single, sample)
+		}
+	}
+	config.MinSamples = 10
+	_, _, err = TrainClassifier(single, "v1", config)
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeValidation))
+
+	trained, report, err := TrainClassifier(trainingSamples(), "v1", config)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", trained.Version)
+	assert.Equal(t, 60, trained.Samples)
+	assert.Equal(t, 12, report.Evaluated)
+	assert.Equal(t, 1.0, report.Accuracy)
+	assert.Equal(t, 1.0, report.SeverityAccuracy)
+	assert.Equal(t, map[string]int{"performance": 20, "storage": 20, "database": 20}, report.Categories)
+}
+
+func TestAlertClassifierService_Classify(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultClassifierTrainConfig()
+	trained, _, err := TrainClassifier(trainingSamples(), "v1", config)
+	require.NoError(t, err)
+
+	repo := &memoryClassifierRepository{}
+	fallback := &stubClassifier{result: &gateway.Classification{Category: "network", Severity: model.AlertLevelHigh, Confidence: 0.9, Source: gateway.ClassificationSourceLLM}}
+	service := NewAlertClassifierService(repo, fallback, gateway.DefaultClassifierConfig(), zap.NewNop())
+
+	// 未训练模型时使用关键词规则，置信度不足由 LLM 兜底
+	require.NoError(t, service.Reload(ctx))
+	result, err := service.Classify(ctx, &model.Alert{Name: "disk full", Level: model.AlertLevelLow})
+	require.NoError(t, err)
+	assert.Equal(t, gateway.ClassificationSourceLLM, result.Source)
+	assert.Equal(t, 1, fallback.calls)
+
+	repo.active = trained
+	require.NoError(t, service.Reload(ctx))
+	assert.Equal(t, "v1", service.Version())
+
+	// 生产环境的慢查询重新评估为严重
+	result, err = service.Classify(ctx, &model.Alert{Name: "SlowQueries", Title: "mysql slow queries on db-99", Level: model.AlertLevelMedium, Labels: `{"env":"prod"}`})
+	require.NoError(t, err)
+	assert.Equal(t, "database", result.Category)
+	assert.Equal(t, model.AlertLevelCritical, result.Severity)
+	assert.Equal(t, gateway.ClassificationSourceModel, result.Source)
+	assert.Equal(t, "v1", result.ModelVersion)
+	assert.Greater(t, result.Confidence, 0.9)
+	assert.Equal(t, 1, fallback.calls)
+
+	// 没有已知特征的告警置信度低，LLM 失败时保留模型结果
+	fallback.err = errors.New("timeout")
+	result, err = service.Classify(ctx, &model.Alert{Name: "Unknown", Title: "something happened", Level: model.AlertLevelLow})
+	require.NoError(t, err)
+	assert.Equal(t, gateway.ClassificationSourceModel, result.Source)
+	assert.Equal(t, model.AlertLevelLow, result.Severity, "uncertain severity keeps alert level")
+	assert.Less(t, result.Confidence, 0.6)
+	assert.Equal(t, 2, fallback.calls)
+
+	// 停用所有版本后回到关键词规则
+	repo.active = nil
+	require.NoError(t, service.Reload(ctx))
+	assert.Empty(t, service.Version())
+}
+
+func TestSmartRoutingStrategy_Classification(t *testing.T) {
+	ctx := context.Background()
+	alert := &model.Alert{Name: "SlowQueries", Level: model.AlertLevelMedium}
+	classifier := &stubClassifier{result: &gateway.Classification{
+		Category:     "database",
+		Severity:     model.AlertLevelCritical,
+		Confidence:   0.93,
+		Source:       gateway.ClassificationSourceModel,
+		ModelVersion: "v2",
+	}}
+
+	result, err := NewSmartRoutingStrategy(nil, nil, classifier, zap.NewNop()).performIntelligentAnalysis(ctx, &gateway.AlertContext{Alert: alert})
+	require.NoError(t, err)
+	assert.Equal(t, "database", result.Category)
+	assert.Equal(t, model.AlertLevelCritical, result.Severity)
+	assert.Equal(t, model.AlertLevelMedium, result.OriginalSeverity)
+	assert.Equal(t, 0.93, result.Confidence)
+	assert.Equal(t, "v2", result.ModelVersion)
+	assert.Equal(t, true, result.Metadata["severity_adjusted"])
+	assert.Equal(t, "immediate_escalation", result.Metadata["recommended_action"])
+
+	// 分类失败时使用关键词规则
+	classifier.err = errors.New("unavailable")
+	result, err = NewSmartRoutingStrategy(nil, nil, classifier, zap.NewNop()).performIntelligentAnalysis(ctx, &gateway.AlertContext{Alert: &model.Alert{Name: "disk usage", Level: model.AlertLevelLow}})
+	require.NoError(t, err)
+	assert.Equal(t, "storage", result.Category)
+	assert.Equal(t, model.AlertLevelLow, result.Severity)
+	assert.Equal(t, gateway.ClassificationSourceRules, result.Metadata["classification_source"])
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"alert_agent/internal/domain/gateway"
@@ -13,12 +14,18 @@ import (
 
 // AlertConvergerService 告警收敛服务实现
 type AlertConvergerService struct {
-	featureToggle feature.ToggleManager
+	featureToggle *feature.ToggleManager
 	metricsCollector gateway.MetricsCollector
 	processingRepo gateway.AlertProcessingRepository
+	scorer         gateway.SimilarityScorer
+	correlator     gateway.TopologyCorrelator
 	convergenceWindows map[string]*ConvergenceWindow // 收敛窗口
+	mu                 sync.Mutex                     // 保护收敛窗口，管道会并发调用
 }
 
+// defaultConvergenceWindow 未匹配路由树时的收敛窗口时长
+const defaultConvergenceWindow = 5 * time.Minute
+
 // ConvergenceWindow 收敛窗口
 type ConvergenceWindow struct {
 	Alerts    []*model.Alert
@@ -30,29 +37,77 @@ type ConvergenceWindow struct {
 
 // NewAlertConvergerService 创建新的告警收敛服务
 func NewAlertConvergerService(
-	featureToggle feature.ToggleManager,
+	featureToggle *feature.ToggleManager,
 	metricsCollector gateway.MetricsCollector,
 	processingRepo gateway.AlertProcessingRepository,
+	scorer gateway.SimilarityScorer,
+	correlator gateway.TopologyCorrelator,
 ) gateway.AlertConverger {
+	if scorer == nil {
+		scorer = NewSimilarityEngine(gateway.DefaultSimilarityConfig(), nil, nil)
+	}
 	return &AlertConvergerService{
 		featureToggle: featureToggle,
 		metricsCollector: metricsCollector,
 		processingRepo: processingRepo,
+		scorer:         scorer,
+		correlator:     correlator,
 		convergenceWindows: make(map[string]*ConvergenceWindow),
 	}
 }
 
-// ShouldConverge 判断是否应该收敛告警
+// ShouldConverge 判断是否应该收敛告警，同组重复告警优先按分组收敛，其次按服务拓扑关联
 func (acs *AlertConvergerService) ShouldConverge(ctx context.Context, alertCtx *gateway.AlertContext) (bool, *gateway.ConvergenceResult, error) {
-	alert := alertCtx.Alert
-	
 	// 检查收敛功能是否启用
 	if !acs.featureToggle.IsEnabled(ctx, feature.FeatureBasicConvergence) {
 		return false, nil, nil
 	}
 
+	var correlation *gateway.ConvergenceResult
+	if acs.correlator != nil {
+		var err error
+		correlation, err = acs.correlator.Correlate(ctx, alertCtx)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to correlate alert by topology: %w", err)
+		}
+		if correlation != nil {
+			alertCtx.RootCause = correlation.RootCause
+		}
+	}
+
+	converged, result := acs.convergeByGroupKey(alertCtx.Alert, alertCtx.Grouping)
+	if converged {
+		if correlation != nil {
+			result.RootCause = correlation.RootCause
+		}
+		return true, result, nil
+	}
+	if correlation != nil {
+		return correlation.Converged, correlation, nil
+	}
+	return false, nil, nil
+}
+
+// convergeByGroupKey 按分组键在收敛窗口内收敛重复告警。
+// 匹配到路由树时使用路由的 group_by 生成分组键，窗口时长取路由的 group_interval
+func (acs *AlertConvergerService) convergeByGroupKey(alert *model.Alert, grouping *gateway.RouteGrouping) (bool, *gateway.ConvergenceResult) {
+	// 告警文本加入语料，用于相似度计算
+	acs.scorer.Observe(alert)
+
 	// 生成收敛分组键
 	groupKey := acs.generateGroupKey(alert)
+	windowSize := defaultConvergenceWindow
+	if grouping != nil {
+		if len(grouping.GroupBy) > 0 {
+			groupKey = routeGroupKey(alert, grouping)
+		}
+		if grouping.GroupInterval > 0 {
+			windowSize = time.Duration(grouping.GroupInterval) * time.Second
+		}
+	}
+
+	acs.mu.Lock()
+	defer acs.mu.Unlock()
 	
 	// 检查是否存在活跃的收敛窗口
 	window, exists := acs.convergenceWindows[groupKey]
@@ -61,12 +116,12 @@ func (acs *AlertConvergerService) ShouldConverge(ctx context.Context, alertCtx *
 		window = &ConvergenceWindow{
 			Alerts:    []*model.Alert{alert},
 			StartTime: time.Now(),
-			EndTime:   time.Now().Add(5 * time.Minute), // 默认5分钟收敛窗口
+			EndTime:   time.Now().Add(windowSize),
 			GroupKey:  groupKey,
 			Count:     1,
 		}
 		acs.convergenceWindows[groupKey] = window
-		return false, nil, nil // 第一个告警不收敛
+		return false, nil // 第一个告警不收敛
 	}
 	
 	// 检查收敛窗口是否仍然有效
@@ -76,12 +131,12 @@ func (acs *AlertConvergerService) ShouldConverge(ctx context.Context, alertCtx *
 		window = &ConvergenceWindow{
 			Alerts:    []*model.Alert{alert},
 			StartTime: time.Now(),
-			EndTime:   time.Now().Add(5 * time.Minute),
+			EndTime:   time.Now().Add(windowSize),
 			GroupKey:  groupKey,
 			Count:     1,
 		}
 		acs.convergenceWindows[groupKey] = window
-		return false, nil, nil
+		return false, nil
 	}
 	
 	// 添加到现有窗口
@@ -104,7 +159,7 @@ func (acs *AlertConvergerService) ShouldConverge(ctx context.Context, alertCtx *
 	
 
 	
-	return true, result, nil
+	return true, result
 }
 
 // Converge 执行告警收敛
@@ -167,6 +222,16 @@ func (acs *AlertConvergerService) generateGroupKey(alert *model.Alert) string {
 	return key
 }
 
+// routeGroupKey 按路由 group_by 标签的取值生成分组键，同一接收者下标签取值相同的告警归为一组
+func routeGroupKey(alert *model.Alert, grouping *gateway.RouteGrouping) string {
+	labels := gateway.ParseAlertLabels(alert)
+	parts := make([]string, 0, len(grouping.GroupBy))
+	for _, name := range grouping.GroupBy {
+		parts = append(parts, name+"="+labels[name])
+	}
+	return "route:" + grouping.Receiver + ":" + strings.Join(parts, ",")
+}
+
 // groupAlertsBySimilarity 按相似性对告警分组
 func (acs *AlertConvergerService) groupAlertsBySimilarity(alerts []*gateway.AlertContext) map[string][]*gateway.AlertContext {
 	groups := make(map[string][]*gateway.AlertContext)
@@ -220,6 +285,9 @@ func (acs *AlertConvergerService) FindSimilarAlerts(ctx context.Context, alert *
 	// 生成分组键
 	groupKey := acs.generateGroupKey(alert)
 
+	acs.mu.Lock()
+	defer acs.mu.Unlock()
+
 	// 检查是否存在收敛窗口
 	window, exists := acs.convergenceWindows[groupKey]
 
@@ -237,31 +305,20 @@ func (acs *AlertConvergerService) FindSimilarAlerts(ctx context.Context, alert *
 	return window.Alerts, nil
 }
 
-// CalculateSimilarity 计算相似度
+// CalculateSimilarity 计算相似度，综合标签、文本、时间接近度和向量信号
 func (acs *AlertConvergerService) CalculateSimilarity(ctx context.Context, alert1, alert2 *model.Alert) (float64, error) {
-	// 简单的相似度计算逻辑
-	similarity := 0.0
-
-	// 比较告警名称
-	if alert1.Name == alert2.Name {
-		similarity += 0.4
-	}
-
-	// 比较告警级别
-	if alert1.Level == alert2.Level {
-		similarity += 0.3
+	score, err := acs.scorer.Score(ctx, alert1, alert2)
+	if err != nil {
+		return 0, err
 	}
-
-	// 比较告警来源
-	if alert1.Source == alert2.Source {
-		similarity += 0.3
-	}
-
-	return similarity, nil
+	return score.Total, nil
 }
 
 // cleanupExpiredWindows 清理过期的收敛窗口
 func (acs *AlertConvergerService) cleanupExpiredWindows() {
+	acs.mu.Lock()
+	defer acs.mu.Unlock()
+
 	now := time.Now()
 	for groupKey, window := range acs.convergenceWindows {
 		if now.After(window.EndTime) {
@@ -17,21 +17,24 @@ type AlertProcessorService struct {
 	repository         gateway.AlertProcessingRepository
 	featureToggle      gateway.FeatureToggleService
 	metricsCollector   gateway.MetricsCollector
+	classifier         gateway.AlertClassifier
 	strategies         map[gateway.ProcessingMode]gateway.ProcessingStrategy
 	logger             *zap.Logger
 }
 
-// NewAlertProcessorService 创建告警处理器服务
+// NewAlertProcessorService 创建告警处理器服务，classifier 为 nil 时智能路由使用关键词规则分类
 func NewAlertProcessorService(
 	repository gateway.AlertProcessingRepository,
 	featureToggle gateway.FeatureToggleService,
 	metricsCollector gateway.MetricsCollector,
+	classifier gateway.AlertClassifier,
 	logger *zap.Logger,
 ) *AlertProcessorService {
 	aps := &AlertProcessorService{
 		repository:       repository,
 		featureToggle:    featureToggle,
 		metricsCollector: metricsCollector,
+		classifier:       classifier,
 		strategies:       make(map[gateway.ProcessingMode]gateway.ProcessingStrategy),
 		logger:           logger,
 	}
@@ -42,36 +45,12 @@ func NewAlertProcessorService(
 	return aps
 }
 
-// Process 处理告警
+// Process 按处理模式执行处理策略，返回策略的处理结果，由调用方合并到处理记录并保存
 func (aps *AlertProcessorService) Process(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.AlertProcessingRecord, error) {
 	start := time.Now()
 
-	// 创建处理记录
-	record := &gateway.AlertProcessingRecord{
-		AlertID:        alertCtx.Alert.ID,
-		OriginalAlert:  alertCtx.Alert,
-		Status:         gateway.AlertStatusProcessing,
-		ReceivedAt:     time.Now(),
-		ProcessingSteps: []gateway.ProcessingStep{},
-		Metadata:       make(map[string]interface{}),
-		CreatedAt:      time.Now(),
-		UpdatedAt:      time.Now(),
-	}
-
 	// 确定处理模式
 	mode := aps.GetProcessingMode(ctx, alertCtx)
-	record.ProcessingMode = mode
-
-	// 添加处理步骤
-	step := gateway.ProcessingStep{
-		Step:      "processing_started",
-		Status:    "in_progress",
-		StartTime: start,
-		Details: map[string]interface{}{
-			"processing_mode": string(mode),
-		},
-	}
-	record.ProcessingSteps = append(record.ProcessingSteps, step)
 
 	// 获取对应的处理策略
 	strategy, exists := aps.strategies[mode]
@@ -84,46 +63,13 @@ func (aps *AlertProcessorService) Process(ctx context.Context, alertCtx *gateway
 	processedRecord, err := strategy.Process(ctx, alertCtx)
 	if err != nil {
 		aps.logger.Error("Strategy processing failed", zap.Error(err), zap.String("mode", string(mode)))
-		
-		// 更新失败状态
-		record.Status = gateway.AlertStatusFailed
-		step.Status = "failed"
-		step.ErrorMsg = err.Error()
-		endTime := time.Now()
-		step.EndTime = &endTime
-		step.Duration = time.Since(start)
-		
-		// 更新处理记录
-		record.ProcessingSteps[len(record.ProcessingSteps)-1] = step
-		record.UpdatedAt = time.Now()
-		
-		if updateErr := aps.repository.Update(ctx, record); updateErr != nil {
-			aps.logger.Error("Failed to update processing record", zap.Error(updateErr))
-		}
-		
 		aps.metricsCollector.RecordError(ctx, "alert_processing", err)
 		return nil, fmt.Errorf("strategy processing failed: %w", err)
 	}
+	processedRecord.ProcessingMode = mode
 
-	// 更新成功状态
-	record.Status = gateway.AlertStatusRouted
-	step.Status = "completed"
-	endTime := time.Now()
-	step.EndTime = &endTime
-	step.Duration = time.Since(start)
-
-	// 更新处理记录
-	record.ProcessingSteps[len(record.ProcessingSteps)-1] = step
-	record.ProcessedAt = &endTime
-	record.UpdatedAt = endTime
-
-	if err := aps.repository.Update(ctx, record); err != nil {
-		aps.logger.Error("Failed to update processing record", zap.Error(err))
-		return nil, fmt.Errorf("failed to update processing record: %w", err)
-	}
-
-	aps.logger.Info("Alert processed successfully", 
-		zap.String("record_id", record.ID),
+	aps.logger.Debug("Alert processed successfully",
+		zap.Uint("alert_id", alertCtx.Alert.ID),
 		zap.String("processing_mode", string(mode)),
 		zap.Duration("duration", time.Since(start)))
 
@@ -186,6 +132,7 @@ func (aps *AlertProcessorService) registerStrategies() {
 	aps.strategies[gateway.ModeSmartRouting] = NewSmartRoutingStrategy(
 		aps.repository,
 		aps.metricsCollector,
+		aps.classifier,
 		aps.logger,
 	)
 }
@@ -367,18 +314,21 @@ func (bcs *BasicConvergenceStrategy) findSimilarAlerts(ctx context.Context, aler
 type SmartRoutingStrategy struct {
 	repository       gateway.AlertProcessingRepository
 	metricsCollector gateway.MetricsCollector
+	classifier       gateway.AlertClassifier
 	logger           *zap.Logger
 }
 
-// NewSmartRoutingStrategy 创建智能路由策略
+// NewSmartRoutingStrategy 创建智能路由策略，classifier 为 nil 时使用关键词规则分类
 func NewSmartRoutingStrategy(
 	repository gateway.AlertProcessingRepository,
 	metricsCollector gateway.MetricsCollector,
+	classifier gateway.AlertClassifier,
 	logger *zap.Logger,
 ) *SmartRoutingStrategy {
 	return &SmartRoutingStrategy{
 		repository:       repository,
 		metricsCollector: metricsCollector,
+		classifier:       classifier,
 		logger:           logger,
 	}
 }
@@ -406,7 +356,7 @@ func (srs *SmartRoutingStrategy) Process(ctx context.Context, alertCtx *gateway.
 	}
 
 	// 智能路由逻辑：基于AI分析结果进行处理
-	analysisResult, err := srs.performIntelligentAnalysis(ctx, alertCtx.Alert)
+	analysisResult, err := srs.performIntelligentAnalysis(ctx, alertCtx)
 	if err != nil {
 		srs.logger.Error("Failed to perform intelligent analysis", zap.Error(err))
 		return nil, fmt.Errorf("failed to perform intelligent analysis: %w", err)
@@ -423,6 +373,7 @@ func (srs *SmartRoutingStrategy) Process(ctx context.Context, alertCtx *gateway.
 			"ai_confidence":   analysisResult.Confidence,
 			"severity":        analysisResult.Severity,
 			"category":        analysisResult.Category,
+			"model_version":   analysisResult.ModelVersion,
 		},
 	}
 
@@ -446,29 +397,49 @@ func (srs *SmartRoutingStrategy) GetMode() gateway.ProcessingMode {
 }
 
 // performIntelligentAnalysis 执行智能分析
-func (srs *SmartRoutingStrategy) performIntelligentAnalysis(ctx context.Context, alert *model.Alert) (*gateway.AnalysisResult, error) {
-	// 简化的AI分析实现
-	// 在实际实现中，这里会调用AI服务进行分析
-	
+func (srs *SmartRoutingStrategy) performIntelligentAnalysis(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.AnalysisResult, error) {
+	alert := alertCtx.Alert
+	classification := srs.classify(ctx, alert)
+
 	result := &gateway.AnalysisResult{
-		Severity:     alert.Level,
-		Category:     categorizeAlertByName(alert),
-		RootCause:    "unknown",
-		Impact:       "medium",
-		Recommendations: []string{"investigate", "monitor"},
-		Confidence:   0.85, // 模拟置信度
+		Severity:         classification.Severity,
+		OriginalSeverity: alert.Level,
+		Category:         classification.Category,
+		RootCause:        "unknown",
+		Impact:           "medium",
+		Recommendations:  []string{"investigate", "monitor"},
+		Confidence:       classification.Confidence,
+		ModelVersion:     classification.ModelVersion,
 		Metadata: map[string]interface{}{
-			"alert_pattern": "known_pattern",
-			"historical_match": true,
-			"severity_assessment": "medium",
-			"risk_score": calculateRiskScore(alert),
-			"recommended_action": determineRecommendedAction(alert),
+			"classification_source": classification.Source,
+			"severity_adjusted":     classification.Severity != alert.Level,
+			"risk_score":            calculateRiskScore(classification.Severity),
+			"recommended_action":    determineRecommendedAction(classification.Severity),
 		},
 	}
 
+	// 拓扑关联推断出根因时使用关联结果
+	if alertCtx.RootCause != nil {
+		result.RootCause = alertCtx.RootCause.String()
+		result.Metadata["root_cause"] = alertCtx.RootCause
+	}
+
 	return result, nil
 }
 
+// classify 对告警分类，分类器不可用或失败时使用关键词规则
+func (srs *SmartRoutingStrategy) classify(ctx context.Context, alert *model.Alert) *gateway.Classification {
+	if srs.classifier == nil {
+		return classifyByRules(alert)
+	}
+	classification, err := srs.classifier.Classify(ctx, alert)
+	if err != nil {
+		srs.logger.Warn("Failed to classify alert, using rules", zap.Uint("alert_id", alert.ID), zap.Error(err))
+		return classifyByRules(alert)
+	}
+	return classification
+}
+
 // categorizeAlertByName 对告警进行分类
 func categorizeAlertByName(alert *model.Alert) string {
 	name := alert.Name
@@ -510,9 +481,9 @@ func containsAny(s string, substrings ...string) bool {
 }
 
 // calculateRiskScore 计算风险评分
-func calculateRiskScore(alert *model.Alert) float64 {
-	// 基于告警级别计算风险评分
-	switch alert.Level {
+func calculateRiskScore(severity string) float64 {
+	// 基于严重程度计算风险评分
+	switch severity {
 	case model.AlertLevelCritical:
 		return 0.9
 	case model.AlertLevelHigh:
@@ -527,9 +498,9 @@ func calculateRiskScore(alert *model.Alert) float64 {
 }
 
 // determineRecommendedAction 确定推荐操作
-func determineRecommendedAction(alert *model.Alert) string {
-	// 基于告警级别确定推荐操作
-	switch alert.Level {
+func determineRecommendedAction(severity string) string {
+	// 基于严重程度确定推荐操作
+	switch severity {
 	case model.AlertLevelCritical:
 		return "immediate_escalation"
 	case model.AlertLevelHigh:
@@ -16,18 +16,21 @@ import (
 type AlertReceiverService struct {
 	repository       gateway.AlertProcessingRepository
 	metricsCollector gateway.MetricsCollector
+	snapshotter      gateway.MetricSnapshotter
 	logger           *zap.Logger
 }
 
-// NewAlertReceiverService 创建告警接收器服务
+// NewAlertReceiverService 创建告警接收器服务，snapshotter 为空时不采集指标快照
 func NewAlertReceiverService(
 	repository gateway.AlertProcessingRepository,
 	metricsCollector gateway.MetricsCollector,
+	snapshotter gateway.MetricSnapshotter,
 	logger *zap.Logger,
 ) *AlertReceiverService {
 	return &AlertReceiverService{
 		repository:       repository,
 		metricsCollector: metricsCollector,
+		snapshotter:      snapshotter,
 		logger:           logger,
 	}
 }
@@ -156,6 +159,24 @@ func (ars *AlertReceiverService) EnrichAlert(ctx context.Context, alert *model.A
 	alertCtx.RelatedMetrics["alert_frequency"] = len(historicalAlerts)
 	alertCtx.RelatedMetrics["last_occurrence"] = time.Now().Add(-time.Hour).Unix()
 
+	// 采集触发时间附近的指标快照，失败不影响告警处理
+	if ars.snapshotter != nil {
+		snapshot, err := ars.snapshotter.Snapshot(ctx, alert)
+		if err != nil {
+			ars.logger.Warn("Failed to capture metric snapshot", zap.Uint("alert_id", alert.ID), zap.Error(err))
+			ars.metricsCollector.RecordError(ctx, "metric_snapshot", err)
+		} else if snapshot != nil {
+			alertCtx.MetricSnapshot = snapshot
+			for _, series := range snapshot.Series {
+				if !series.Related {
+					alertCtx.RelatedMetrics["metric_last"] = series.Stats.Last
+					alertCtx.RelatedMetrics["metric_max"] = series.Stats.Max
+					break
+				}
+			}
+		}
+	}
+
 	ars.logger.Debug("Alert context enriched", 
 		zap.Uint("alert_id", alert.ID),
 		zap.String("environment", alertCtx.Environment),
@@ -12,18 +12,21 @@ import (
 
 // AlertRouterService 告警路由服务实现
 type AlertRouterService struct {
-	featureToggle feature.ToggleManager
+	featureToggle *feature.ToggleManager
 	metricsCollector gateway.MetricsCollector
+	routingService gateway.RoutingConfigService
 }
 
 // NewAlertRouterService 创建新的告警路由服务
 func NewAlertRouterService(
-	featureToggle feature.ToggleManager,
+	featureToggle *feature.ToggleManager,
 	metricsCollector gateway.MetricsCollector,
+	routingService gateway.RoutingConfigService,
 ) gateway.AlertRouter {
 	return &AlertRouterService{
 		featureToggle: featureToggle,
 		metricsCollector: metricsCollector,
+		routingService: routingService,
 	}
 }
 
@@ -34,6 +37,13 @@ func (ars *AlertRouterService) Route(ctx context.Context, alertCtx *gateway.Aler
 		ars.metricsCollector.RecordProcessingLatency(ctx, gateway.ModeDirectPassthrough, time.Since(start).Milliseconds())
 	}()
 
+	// 优先使用声明式路由树
+	if decision, err := ars.performTreeRouting(ctx, alertCtx); err != nil {
+		return nil, err
+	} else if decision != nil {
+		return decision, nil
+	}
+
 	// 检查直接路由功能是否启用
 	if ars.featureToggle.IsEnabled(ctx, feature.FeatureDirectRouting) {
 		return ars.performDirectRouting(ctx, alertCtx)
@@ -53,14 +63,72 @@ func (ars *AlertRouterService) GetAvailableChannels(ctx context.Context, alertCt
 	return []string{"email", "sms", "slack", "webhook"}, nil
 }
 
-// ValidateRouting 验证路由决策
+// ValidateRouting 验证路由决策，渠道与通知组至少需要一个
 func (ars *AlertRouterService) ValidateRouting(ctx context.Context, decision *gateway.RoutingDecision) error {
-	if len(decision.ChannelIDs) == 0 {
+	if len(decision.ChannelIDs) == 0 && len(decision.NotifyGroupIDs) == 0 {
 		return fmt.Errorf("no targets specified in routing decision")
 	}
 	return nil
 }
 
+// Grouping 获取告警匹配路由的分组设置，没有生效的路由树或未匹配时返回 nil
+func (ars *AlertRouterService) Grouping(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.RouteGrouping, error) {
+	if ars.routingService == nil {
+		return nil, nil
+	}
+	matches, err := ars.routingService.Match(ctx, alertCtx.Alert, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to match routing tree: %w", err)
+	}
+	return groupingOf(matches), nil
+}
+
+// performTreeRouting 按生效的路由树进行路由，没有生效配置时返回 nil
+func (ars *AlertRouterService) performTreeRouting(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.RoutingDecision, error) {
+	if ars.routingService == nil {
+		return nil, nil
+	}
+
+	alert := alertCtx.Alert
+	matches, err := ars.routingService.Match(ctx, alert, time.Now())
+	if err != nil {
+		ars.metricsCollector.RecordError(ctx, "routing_tree_match", err)
+		return nil, fmt.Errorf("failed to match routing tree: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	receivers, channels, groups := collectReceivers(matches)
+	decision := &gateway.RoutingDecision{
+		ChannelIDs:     channels,
+		NotifyGroupIDs: groups,
+		Grouping:       groupingOf(matches),
+		Priority:       ars.calculatePriority(alert),
+		Reason:         fmt.Sprintf("Routing tree matched receivers %v", receivers),
+		Confidence:     1.0,
+		DecisionTime:   time.Now(),
+		Metadata: map[string]interface{}{
+			"routing_type": "tree",
+			"receivers":    receivers,
+			"matches":      matches,
+		},
+	}
+	if decision.Grouping != nil {
+		// 首次通知前等待 group_wait，让同组告警一起发送
+		decision.Delay = time.Duration(decision.Grouping.GroupWait) * time.Second
+	}
+
+	// 匹配到的路由均不在生效时间内
+	if len(receivers) == 0 {
+		decision.Suppressed = true
+		decision.Reason = "Matched routes are outside their active time intervals"
+	}
+
+	ars.metricsCollector.RecordAlertRouted(ctx, decision)
+	return decision, nil
+}
+
 // performDirectRouting 执行直接路由
 func (ars *AlertRouterService) performDirectRouting(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.RoutingDecision, error) {
 	alert := alertCtx.Alert
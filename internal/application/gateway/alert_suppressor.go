@@ -13,14 +13,14 @@ import (
 
 // AlertSuppressorService 告警抑制服务实现
 type AlertSuppressorService struct {
-	featureToggle feature.ToggleManager
+	featureToggle *feature.ToggleManager
 	metricsCollector gateway.MetricsCollector
 	suppressionRules map[string]*gateway.SuppressionRule // 内存中的抑制规则
 }
 
 // NewAlertSuppressorService 创建新的告警抑制服务
 func NewAlertSuppressorService(
-	featureToggle feature.ToggleManager,
+	featureToggle *feature.ToggleManager,
 	metricsCollector gateway.MetricsCollector,
 ) gateway.AlertSuppressor {
 	return &AlertSuppressorService{
@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"context"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/pkg/feature"
+)
+
+// FeatureToggleAdapter 将功能开关管理器适配为网关功能开关服务
+type FeatureToggleAdapter struct {
+	manager *feature.ToggleManager
+}
+
+// NewFeatureToggleAdapter 创建网关功能开关服务
+func NewFeatureToggleAdapter(manager *feature.ToggleManager) *FeatureToggleAdapter {
+	return &FeatureToggleAdapter{manager: manager}
+}
+
+// IsEnabled 检查功能是否启用
+func (fta *FeatureToggleAdapter) IsEnabled(ctx context.Context, name string) bool {
+	return fta.manager.IsEnabled(ctx, feature.FeatureName(name))
+}
+
+// GetProcessingMode 根据已启用的功能获取当前处理模式
+func (fta *FeatureToggleAdapter) GetProcessingMode(ctx context.Context) gateway.ProcessingMode {
+	switch {
+	case fta.manager.IsEnabled(ctx, feature.FeatureSmartRouting):
+		return gateway.ModeSmartRouting
+	case fta.manager.IsEnabled(ctx, feature.FeatureBasicConvergence):
+		return gateway.ModeBasicConvergence
+	default:
+		return gateway.ModeDirectPassthrough
+	}
+}
+
+// CanUseSmartFeatures 是否可以使用智能功能
+func (fta *FeatureToggleAdapter) CanUseSmartFeatures(ctx context.Context) bool {
+	return fta.manager.IsEnabled(ctx, feature.FeatureSmartRouting)
+}
@@ -0,0 +1,196 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// FlapDetectorService 抖动检测服务实现
+type FlapDetectorService struct {
+	store  gateway.FlapStateStore
+	config gateway.FlappingConfig
+	logger *zap.Logger
+}
+
+// NewFlapDetectorService 创建抖动检测服务
+func NewFlapDetectorService(store gateway.FlapStateStore, config gateway.FlappingConfig, logger *zap.Logger) *FlapDetectorService {
+	defaults := gateway.DefaultFlappingConfig()
+	if config.Window <= 0 {
+		config.Window = defaults.Window
+	}
+	if config.HistorySize < 2 {
+		config.HistorySize = defaults.HistorySize
+	}
+	if config.MinSamples < 2 {
+		config.MinSamples = defaults.MinSamples
+	}
+	if config.HighThreshold <= 0 {
+		config.HighThreshold = defaults.HighThreshold
+	}
+	if config.LowThreshold <= 0 || config.LowThreshold > config.HighThreshold {
+		config.LowThreshold = config.HighThreshold / 2
+	}
+
+	return &FlapDetectorService{
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Observe 记录告警状态并返回最新的抖动状态
+func (fds *FlapDetectorService) Observe(ctx context.Context, alert *model.Alert, at time.Time) (*gateway.FlapState, error) {
+	fingerprint := gateway.AlertFingerprint(alert)
+	firing := alert.Status != model.AlertStatusResolved
+
+	state, err := fds.store.Update(ctx, fingerprint, func(state *gateway.FlapState) (*gateway.FlapState, error) {
+		if state == nil {
+			state = &gateway.FlapState{
+				Fingerprint: fingerprint,
+				AlertName:   alert.Name,
+				Labels:      gateway.ParseAlertLabels(alert),
+			}
+		}
+
+		state.LastAlertID = alert.ID
+		state.Samples = append(state.Samples, gateway.StateSample{Firing: firing, Timestamp: at})
+		state.Samples = fds.pruneSamples(state.Samples, at)
+		state.Transitions, state.PercentStateChange = calculateStateChange(state.Samples)
+		state.UpdatedAt = at
+
+		wasFlapping := state.Flapping
+		switch {
+		case !wasFlapping && len(state.Samples) >= fds.config.MinSamples && state.PercentStateChange >= fds.config.HighThreshold:
+			state.Flapping = true
+			state.FlappingSince = &at
+			state.StabilizedAt = nil
+			state.HeldNotifications = 0
+		case wasFlapping && state.PercentStateChange < fds.config.LowThreshold:
+			state.Flapping = false
+			state.StabilizedAt = &at
+		}
+
+		return state, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update flap state: %w", err)
+	}
+
+	if state.Flapping && state.FlappingSince != nil && state.FlappingSince.Equal(at) {
+		fds.logger.Info("alert started flapping",
+			zap.String("fingerprint", fingerprint),
+			zap.String("alert_name", alert.Name),
+			zap.Float64("percent_state_change", state.PercentStateChange))
+	} else if !state.Flapping && state.StabilizedAt != nil && state.StabilizedAt.Equal(at) {
+		fds.logger.Info("alert stopped flapping",
+			zap.String("fingerprint", fingerprint),
+			zap.String("alert_name", alert.Name),
+			zap.Int("held_notifications", state.HeldNotifications))
+	}
+
+	return state, nil
+}
+
+// ShouldHold 判断是否暂停该状态下的通知
+func (fds *FlapDetectorService) ShouldHold(state *gateway.FlapState) bool {
+	return fds.config.HoldNotifications && state != nil && state.Flapping
+}
+
+// RecordHeld 记录一次被暂停的通知
+func (fds *FlapDetectorService) RecordHeld(ctx context.Context, fingerprint string) error {
+	_, err := fds.store.Update(ctx, fingerprint, func(state *gateway.FlapState) (*gateway.FlapState, error) {
+		if state == nil {
+			return nil, fmt.Errorf("flap state %s not found", fingerprint)
+		}
+		state.HeldNotifications++
+		return state, nil
+	})
+	return err
+}
+
+// Reevaluate 按时间 at 丢弃窗口外的采样并重新计算状态变化百分比。
+// 抖动中的告警可能不再发送，只有定时重新评估才能让它结束抖动；状态已结束抖动时原样返回。
+func (fds *FlapDetectorService) Reevaluate(ctx context.Context, fingerprint string, at time.Time) (*gateway.FlapState, error) {
+	state, err := fds.store.Update(ctx, fingerprint, func(state *gateway.FlapState) (*gateway.FlapState, error) {
+		if state == nil {
+			return nil, fmt.Errorf("flap state %s not found", fingerprint)
+		}
+		if !state.Flapping {
+			return state, nil
+		}
+
+		state.Samples = fds.pruneSamples(state.Samples, at)
+		state.Transitions, state.PercentStateChange = calculateStateChange(state.Samples)
+		if state.PercentStateChange < fds.config.LowThreshold {
+			state.Flapping = false
+			state.StabilizedAt = &at
+		}
+		return state, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reevaluate flap state: %w", err)
+	}
+
+	if !state.Flapping && state.StabilizedAt != nil && state.StabilizedAt.Equal(at) {
+		fds.logger.Info("alert stopped flapping",
+			zap.String("fingerprint", fingerprint),
+			zap.String("alert_name", state.AlertName),
+			zap.Int("held_notifications", state.HeldNotifications))
+	}
+
+	return state, nil
+}
+
+// GetState 获取指纹的抖动状态
+func (fds *FlapDetectorService) GetState(ctx context.Context, fingerprint string) (*gateway.FlapState, error) {
+	return fds.store.Get(ctx, fingerprint)
+}
+
+// ListFlapping 获取正在抖动的告警
+func (fds *FlapDetectorService) ListFlapping(ctx context.Context) ([]*gateway.FlapState, error) {
+	return fds.store.ListFlapping(ctx)
+}
+
+// pruneSamples 丢弃窗口外以及超过历史长度的采样
+func (fds *FlapDetectorService) pruneSamples(samples []gateway.StateSample, now time.Time) []gateway.StateSample {
+	cutoff := now.Add(-fds.config.Window)
+	start := 0
+	for start < len(samples) && samples[start].Timestamp.Before(cutoff) {
+		start++
+	}
+	if len(samples)-start > fds.config.HistorySize {
+		start = len(samples) - fds.config.HistorySize
+	}
+	return append([]gateway.StateSample(nil), samples[start:]...)
+}
+
+// calculateStateChange 按 Nagios 算法计算加权状态变化百分比，
+// 最旧的状态变化权重为 0.8，最新的为 1.2
+func calculateStateChange(samples []gateway.StateSample) (int, float64) {
+	if len(samples) < 2 {
+		return 0, 0
+	}
+
+	intervals := len(samples) - 1
+	transitions := 0
+	weighted := 0.0
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Firing == samples[i-1].Firing {
+			continue
+		}
+		transitions++
+		weight := 1.0
+		if intervals > 1 {
+			weight = 0.8 + 0.4*float64(i-1)/float64(intervals-1)
+		}
+		weighted += weight
+	}
+
+	return transitions, weighted / float64(intervals) * 100
+}
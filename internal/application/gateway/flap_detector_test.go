@@ -0,0 +1,136 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryFlapStore 测试用内存抖动状态存储
+type memoryFlapStore struct {
+	states map[string]*gateway.FlapState
+}
+
+func newMemoryFlapStore() *memoryFlapStore {
+	return &memoryFlapStore{states: make(map[string]*gateway.FlapState)}
+}
+
+func (s *memoryFlapStore) Get(_ context.Context, fingerprint string) (*gateway.FlapState, error) {
+	return s.states[fingerprint], nil
+}
+
+func (s *memoryFlapStore) Update(_ context.Context, fingerprint string, fn func(*gateway.FlapState) (*gateway.FlapState, error)) (*gateway.FlapState, error) {
+	state, err := fn(s.states[fingerprint])
+	if err != nil {
+		return nil, err
+	}
+	s.states[fingerprint] = state
+	return state, nil
+}
+
+func (s *memoryFlapStore) ListFlapping(_ context.Context) ([]*gateway.FlapState, error) {
+	var states []*gateway.FlapState
+	for _, state := range s.states {
+		if state.Flapping {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+func TestCalculateStateChange(t *testing.T) {
+	now := time.Now()
+	sample := func(firing bool) gateway.StateSample {
+		return gateway.StateSample{Firing: firing, Timestamp: now}
+	}
+
+	tests := []struct {
+		name        string
+		samples     []gateway.StateSample
+		transitions int
+		percent     float64
+	}{
+		{"single sample", []gateway.StateSample{sample(true)}, 0, 0},
+		{"stable", []gateway.StateSample{sample(true), sample(true), sample(true)}, 0, 0},
+		{"always changing", []gateway.StateSample{sample(true), sample(false), sample(true), sample(false)}, 3, 100},
+		{"recent change weighs more", []gateway.StateSample{sample(true), sample(true), sample(false)}, 1, 60},
+		{"old change weighs less", []gateway.StateSample{sample(true), sample(false), sample(false)}, 1, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transitions, percent := calculateStateChange(tt.samples)
+			assert.Equal(t, tt.transitions, transitions)
+			assert.InDelta(t, tt.percent, percent, 0.001)
+		})
+	}
+}
+
+func TestFlapDetector_Hysteresis(t *testing.T) {
+	detector := NewFlapDetectorService(newMemoryFlapStore(), gateway.FlappingConfig{
+		Window:            time.Hour,
+		HistorySize:       6,
+		MinSamples:        4,
+		HighThreshold:     50,
+		LowThreshold:      25,
+		HoldNotifications: true,
+	}, zap.NewNop())
+
+	ctx := context.Background()
+	alert := &model.Alert{Name: "HighCPU", Labels: `{"instance":"node-1"}`}
+	at := time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+
+	observe := func(status string) *gateway.FlapState {
+		at = at.Add(time.Minute)
+		alert.Status = status
+		state, err := detector.Observe(ctx, alert, at)
+		require.NoError(t, err)
+		return state
+	}
+
+	// 样本数不足时不判定为抖动
+	observe(model.AlertStatusNew)
+	observe(model.AlertStatusResolved)
+	state := observe(model.AlertStatusNew)
+	assert.False(t, state.Flapping)
+
+	state = observe(model.AlertStatusResolved)
+	assert.True(t, state.Flapping)
+	assert.True(t, detector.ShouldHold(state))
+	require.NoError(t, detector.RecordHeld(ctx, state.Fingerprint))
+
+	// 低于高阈值但高于低阈值时保持抖动
+	for i := 0; i < 2; i++ {
+		state = observe(model.AlertStatusResolved)
+	}
+	assert.True(t, state.Flapping)
+
+	// 低于低阈值后结束抖动
+	for i := 0; i < 3; i++ {
+		state = observe(model.AlertStatusResolved)
+	}
+	assert.False(t, state.Flapping)
+	assert.NotNil(t, state.StabilizedAt)
+	assert.Equal(t, 1, state.HeldNotifications)
+	assert.False(t, detector.ShouldHold(state))
+
+	flapping, err := detector.ListFlapping(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, flapping)
+}
+
+func TestAlertFingerprint_IgnoresSeverity(t *testing.T) {
+	a := &model.Alert{Name: "HighCPU", Level: "warning", Labels: `{"instance":"node-1"}`}
+	b := &model.Alert{Name: "HighCPU", Level: "critical", Labels: `{"instance":"node-1"}`}
+	c := &model.Alert{Name: "HighCPU", Level: "warning", Labels: `{"instance":"node-2"}`}
+
+	assert.Equal(t, gateway.AlertFingerprint(a), gateway.AlertFingerprint(b))
+	assert.NotEqual(t, gateway.AlertFingerprint(a), gateway.AlertFingerprint(c))
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+
+	"go.uber.org/zap"
+)
+
+// FlapReleaser 定期重新评估抖动中的告警，抖动结束时为暂停过的通知补发一条。
+// 抖动中的告警停止发送后不会再经过 Observe，没有定时评估时暂停的通知会被丢弃。
+// 结束抖动的状态更新是原子的，多个 worker 同时运行时只有一个会补发。
+type FlapReleaser struct {
+	detector gateway.FlapDetector
+	alerts   gateway.AlertLoader
+	stream   gateway.AlertStream
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewFlapReleaser 创建抖动通知补发器
+func NewFlapReleaser(detector gateway.FlapDetector, alerts gateway.AlertLoader, stream gateway.AlertStream, interval time.Duration, logger *zap.Logger) *FlapReleaser {
+	if interval <= 0 {
+		interval = gateway.DefaultFlappingConfig().ReleaseInterval
+	}
+	return &FlapReleaser{
+		detector: detector,
+		alerts:   alerts,
+		stream:   stream,
+		interval: interval,
+		logger:   logger.Named("flap-releaser"),
+	}
+}
+
+// Run 按间隔重新评估抖动状态，直到上下文取消
+func (r *FlapReleaser) Run(ctx context.Context) error {
+	r.logger.Info("Flap releaser started", zap.Duration("interval", r.interval))
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Flap releaser stopped")
+			return nil
+		case <-ticker.C:
+			r.Release(ctx, time.Now())
+		}
+	}
+}
+
+// Release 重新评估所有抖动中的告警，返回补发的通知数
+func (r *FlapReleaser) Release(ctx context.Context, now time.Time) int {
+	states, err := r.detector.ListFlapping(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("Failed to list flapping alerts", zap.Error(err))
+		}
+		return 0
+	}
+
+	released := 0
+	for _, flapping := range states {
+		state, err := r.detector.Reevaluate(ctx, flapping.Fingerprint, now)
+		if err != nil {
+			r.logger.Warn("Failed to reevaluate flapping alert", zap.String("fingerprint", flapping.Fingerprint), zap.Error(err))
+			continue
+		}
+		// 只有本次评估结束抖动的调用方补发，抖动期间没有暂停通知时无需补发
+		if state.Flapping || state.StabilizedAt == nil || !state.StabilizedAt.Equal(now) || state.HeldNotifications == 0 {
+			continue
+		}
+		if r.publish(ctx, state, now) {
+			released++
+		}
+	}
+	return released
+}
+
+// publish 以抖动期间最后一条告警发布补发通知
+func (r *FlapReleaser) publish(ctx context.Context, state *gateway.FlapState, now time.Time) bool {
+	alert, err := r.alerts.GetByID(ctx, state.LastAlertID)
+	if err != nil {
+		r.logger.Error("Failed to load alert for stabilized notification",
+			zap.String("fingerprint", state.Fingerprint),
+			zap.Uint("alert_id", state.LastAlertID),
+			zap.Error(err))
+		return false
+	}
+
+	id, err := r.stream.Publish(ctx, &gateway.PipelineMessage{
+		Alert:             alert,
+		EnqueuedAt:        now,
+		Stabilized:        true,
+		HeldNotifications: state.HeldNotifications,
+	})
+	if err != nil {
+		r.logger.Error("Failed to publish stabilized notification",
+			zap.String("fingerprint", state.Fingerprint),
+			zap.Uint("alert_id", alert.ID),
+			zap.Error(err))
+		return false
+	}
+
+	r.logger.Info("Released held notifications for stabilized alert",
+		zap.String("fingerprint", state.Fingerprint),
+		zap.Uint("alert_id", alert.ID),
+		zap.Int("held_notifications", state.HeldNotifications),
+		zap.String("stream_id", id))
+	return true
+}
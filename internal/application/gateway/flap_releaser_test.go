@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/infrastructure/queue"
+	"alert_agent/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type alertLoaderFunc func(ctx context.Context, id uint) (*model.Alert, error)
+
+func (f alertLoaderFunc) GetByID(ctx context.Context, id uint) (*model.Alert, error) {
+	return f(ctx, id)
+}
+
+func TestFlapReleaser_ReleasesHeldNotificationsOnce(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	stream := queue.NewRedisAlertStream(client)
+	ctx := context.Background()
+	require.NoError(t, stream.EnsureGroup(ctx))
+
+	detector := NewFlapDetectorService(newMemoryFlapStore(), gateway.FlappingConfig{
+		Window:            10 * time.Minute,
+		HistorySize:       6,
+		MinSamples:        4,
+		HighThreshold:     50,
+		LowThreshold:      25,
+		HoldNotifications: true,
+	}, zap.NewNop())
+	alerts := alertLoaderFunc(func(_ context.Context, id uint) (*model.Alert, error) {
+		return &model.Alert{ID: id, Name: "HighCPU", Labels: `{"instance":"node-1"}`, Status: model.AlertStatusResolved}, nil
+	})
+	releaser := NewFlapReleaser(detector, alerts, stream, time.Minute, zap.NewNop())
+
+	// 告警抖动期间暂停两条通知后不再发送
+	at := time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+	alert := &model.Alert{Name: "HighCPU", Labels: `{"instance":"node-1"}`}
+	var state *gateway.FlapState
+	for i, status := range []string{model.AlertStatusNew, model.AlertStatusResolved, model.AlertStatusNew, model.AlertStatusResolved} {
+		at = at.Add(time.Minute)
+		alert.ID = uint(i + 1)
+		alert.Status = status
+		var err error
+		state, err = detector.Observe(ctx, alert, at)
+		require.NoError(t, err)
+	}
+	require.True(t, state.Flapping)
+	require.NoError(t, detector.RecordHeld(ctx, state.Fingerprint))
+	require.NoError(t, detector.RecordHeld(ctx, state.Fingerprint))
+
+	// 窗口内仍在抖动时不补发
+	assert.Equal(t, 0, releaser.Release(ctx, at.Add(time.Minute)))
+
+	// 采样移出窗口后结束抖动，只补发一条携带暂停数的通知
+	later := at.Add(15 * time.Minute)
+	assert.Equal(t, 1, releaser.Release(ctx, later))
+	assert.Equal(t, 0, releaser.Release(ctx, later.Add(time.Minute)))
+
+	state, err := detector.GetState(ctx, state.Fingerprint)
+	require.NoError(t, err)
+	assert.False(t, state.Flapping)
+
+	msgs, err := stream.Read(ctx, "worker-1", 10, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.True(t, msgs[0].Stabilized)
+	assert.Equal(t, 2, msgs[0].HeldNotifications)
+	assert.Equal(t, uint(4), msgs[0].Alert.ID)
+}
@@ -0,0 +1,281 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// minSnapshotStep 快照查询的最小步长
+const minSnapshotStep = 15 * time.Second
+
+// snapshotOversample 查询点数相对保留点数的倍数，降采样时按桶取均值平滑毛刺
+const snapshotOversample = 4
+
+// relatedQueryPlaceholder 关联查询模板中的标签占位符，如 {{instance}}
+var relatedQueryPlaceholder = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// MetricSnapshotService 指标快照采集服务，查询告警规则表达式在触发时间附近的数据
+type MetricSnapshotService struct {
+	repository gateway.MetricSnapshotRepository
+	clients    gateway.RangeQueryClientFactory
+	config     gateway.MetricSnapshotConfig
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewMetricSnapshotService 创建指标快照采集服务
+func NewMetricSnapshotService(
+	repository gateway.MetricSnapshotRepository,
+	clients gateway.RangeQueryClientFactory,
+	config gateway.MetricSnapshotConfig,
+	logger *zap.Logger,
+) *MetricSnapshotService {
+	defaults := gateway.DefaultMetricSnapshotConfig()
+	if config.Lookback <= 0 {
+		config.Lookback = defaults.Lookback
+	}
+	if config.MaxPoints <= 0 {
+		config.MaxPoints = defaults.MaxPoints
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	return &MetricSnapshotService{
+		repository: repository,
+		clients:    clients,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Snapshot 采集并保存告警的指标快照。已有快照时直接返回，管道重新投递不会重复查询。
+func (s *MetricSnapshotService) Snapshot(ctx context.Context, alert *model.Alert) (*model.MetricSnapshot, error) {
+	if snapshot, err := alert.GetMetricSnapshot(); err != nil || snapshot != nil {
+		return snapshot, err
+	}
+	if alert.RuleID == 0 {
+		return nil, nil
+	}
+
+	rule, err := s.repository.GetRule(ctx, alert.RuleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.QueryExpr == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	client, provider, err := s.clients.RangeQueryClient(ctx, rule.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider client: %w", err)
+	}
+
+	now := s.now()
+	firedAt := alert.CreatedAt
+	if firedAt.IsZero() || firedAt.After(now) {
+		firedAt = now
+	}
+	start := firedAt.Add(-s.config.Lookback)
+	end := firedAt.Add(s.config.Lookahead)
+	if end.After(now) {
+		end = now
+	}
+	step := end.Sub(start) / time.Duration(s.config.MaxPoints*snapshotOversample)
+	if step < minSnapshotStep {
+		step = minSnapshotStep
+	}
+	step = step.Round(time.Second)
+
+	snapshot := &model.MetricSnapshot{
+		ProviderID: provider.ID,
+		Provider:   provider.Name,
+		Query:      rule.QueryExpr,
+		Start:      start,
+		End:        end,
+		Step:       int64(step / time.Second),
+		FiredAt:    firedAt,
+		CapturedAt: now,
+	}
+
+	series, err := s.queryRange(ctx, client, rule.QueryExpr, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	alertLabels := gateway.ParseAlertLabels(alert)
+	snapshot.Series = s.selectSeries(series, alertLabels)
+
+	// 关联查询失败不影响告警序列快照
+	for _, template := range s.config.RelatedQueries {
+		query, ok := expandRelatedQuery(template, alertLabels)
+		if !ok {
+			continue
+		}
+		related, err := s.queryRange(ctx, client, query, start, end, step)
+		if err != nil {
+			s.logger.Warn("Failed to query related series",
+				zap.Uint("alert_id", alert.ID),
+				zap.String("query", query),
+				zap.Error(err))
+			continue
+		}
+		if len(related) > s.config.MaxRelatedSeries {
+			related = related[:s.config.MaxRelatedSeries]
+		}
+		for _, r := range related {
+			r.Query = query
+			r.Related = true
+			snapshot.Series = append(snapshot.Series, r)
+		}
+	}
+
+	if len(snapshot.Series) == 0 {
+		return nil, nil
+	}
+	if err := s.repository.SaveMetricSnapshot(ctx, alert.ID, snapshot); err != nil {
+		return nil, err
+	}
+	if err := alert.SetMetricSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// queryRange 执行范围查询并降采样
+func (s *MetricSnapshotService) queryRange(ctx context.Context, client gateway.RangeQueryClient, query string, start, end time.Time, step time.Duration) ([]model.MetricSeries, error) {
+	resp, err := client.QueryRange(ctx, url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(start.Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"step":  {strconv.FormatInt(int64(step/time.Second), 10)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query range: %w", err)
+	}
+
+	var result struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]interface{}  `json:"values"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("invalid range query result: %w", err)
+	}
+	if result.ResultType != "matrix" {
+		return nil, fmt.Errorf("unsupported result type: %s", result.ResultType)
+	}
+
+	series := make([]model.MetricSeries, 0, len(result.Result))
+	for _, r := range result.Result {
+		points := make([]model.MetricPoint, 0, len(r.Values))
+		for _, v := range r.Values {
+			ts, ok := v[0].(float64)
+			if !ok {
+				continue
+			}
+			str, ok := v[1].(string)
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseFloat(str, 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			points = append(points, model.MetricPoint{T: int64(ts), V: value})
+		}
+		if len(points) == 0 {
+			continue
+		}
+		series = append(series, model.NewMetricSeries(r.Metric, points, s.config.MaxPoints))
+	}
+	return series, nil
+}
+
+// selectSeries 选出与告警标签一致的序列，其余序列按共同标签数排序作为关联序列
+func (s *MetricSnapshotService) selectSeries(series []model.MetricSeries, alertLabels map[string]string) []model.MetricSeries {
+	var primary, peers []model.MetricSeries
+	for _, ser := range series {
+		if labelsConsistent(ser.Labels, alertLabels) {
+			primary = append(primary, ser)
+		} else {
+			ser.Related = true
+			peers = append(peers, ser)
+		}
+	}
+	// 告警标签无法对应到序列时，单条结果视为告警序列
+	if len(primary) == 0 && len(peers) == 1 {
+		peers[0].Related = false
+		return peers
+	}
+
+	sort.SliceStable(peers, func(i, j int) bool {
+		return sharedLabels(peers[i].Labels, alertLabels) > sharedLabels(peers[j].Labels, alertLabels)
+	})
+	if len(peers) > s.config.MaxRelatedSeries {
+		peers = peers[:s.config.MaxRelatedSeries]
+	}
+	return append(primary, peers...)
+}
+
+// labelsConsistent 序列标签与告警标签中同名标签的值均相同，且至少有一个同名标签
+func labelsConsistent(seriesLabels, alertLabels map[string]string) bool {
+	matched := 0
+	for name, value := range seriesLabels {
+		if alertValue, ok := alertLabels[name]; ok {
+			if alertValue != value {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched > 0
+}
+
+// sharedLabels 序列与告警相同的标签数
+func sharedLabels(seriesLabels, alertLabels map[string]string) int {
+	n := 0
+	for name, value := range seriesLabels {
+		if alertLabels[name] == value {
+			n++
+		}
+	}
+	return n
+}
+
+// expandRelatedQuery 用告警标签替换关联查询模板中的占位符，缺少标签时跳过该查询。
+// 占位符应位于双引号字符串中，标签值按 PromQL 字符串转义，无法闭合引号注入其他选择器。
+func expandRelatedQuery(template string, labels map[string]string) (string, bool) {
+	ok := true
+	query := relatedQueryPlaceholder.ReplaceAllStringFunc(template, func(m string) string {
+		name := relatedQueryPlaceholder.FindStringSubmatch(m)[1]
+		value, exists := labels[name]
+		if !exists {
+			ok = false
+		}
+		return escapePromQLString(value)
+	})
+	return query, ok
+}
+
+// escapePromQLString 转义双引号字符串中的值，PromQL 字符串与 Go 使用相同的转义规则
+func escapePromQLString(value string) string {
+	quoted := strconv.Quote(value)
+	return quoted[1 : len(quoted)-1]
+}
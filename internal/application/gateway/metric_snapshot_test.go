@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/provider"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeSnapshotRepository struct {
+	rule  *model.Rule
+	saved map[uint]*model.MetricSnapshot
+}
+
+func (r *fakeSnapshotRepository) GetRule(ctx context.Context, ruleID uint) (*model.Rule, error) {
+	if r.rule == nil || r.rule.ID != ruleID {
+		return nil, nil
+	}
+	return r.rule, nil
+}
+
+func (r *fakeSnapshotRepository) SaveMetricSnapshot(ctx context.Context, alertID uint, snapshot *model.MetricSnapshot) error {
+	r.saved[alertID] = snapshot
+	return nil
+}
+
+// fakeRangeClient 按查询表达式返回预置的 matrix 结果
+type fakeRangeClient struct {
+	results map[string]string
+	queries []url.Values
+}
+
+func (c *fakeRangeClient) RangeQueryClient(ctx context.Context, providerID uint) (gateway.RangeQueryClient, *model.Provider, error) {
+	return c, &model.Provider{ID: providerID, Name: "prometheus"}, nil
+}
+
+func (c *fakeRangeClient) QueryRange(ctx context.Context, params url.Values) (*provider.APIResponse, error) {
+	c.queries = append(c.queries, params)
+	data, ok := c.results[params.Get("query")]
+	if !ok {
+		return nil, fmt.Errorf("unexpected query %s", params.Get("query"))
+	}
+	return &provider.APIResponse{Status: "success", Data: json.RawMessage(data)}, nil
+}
+
+// matrixResult 生成 matrix 结果，每条序列 count 个递增数据点
+func matrixResult(start time.Time, count int, metrics ...string) string {
+	series := make([]string, 0, len(metrics))
+	for i, metric := range metrics {
+		values := make([]string, 0, count)
+		for j := 0; j < count; j++ {
+			values = append(values, fmt.Sprintf(`[%d,"%d"]`, start.Unix()+int64(j*15), (i+1)*j))
+		}
+		series = append(series, fmt.Sprintf(`{"metric":%s,"values":[%s]}`, metric, strings.Join(values, ",")))
+	}
+	return fmt.Sprintf(`{"resultType":"matrix","result":[%s]}`, strings.Join(series, ","))
+}
+
+func TestMetricSnapshotService_Snapshot(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	firedAt := now.Add(-10 * time.Minute)
+	rule := &model.Rule{ProviderID: 3, QueryExpr: "cpu_usage"}
+	rule.ID = 9
+	repo := &fakeSnapshotRepository{rule: rule, saved: map[uint]*model.MetricSnapshot{}}
+	client := &fakeRangeClient{results: map[string]string{
+		"cpu_usage": matrixResult(firedAt.Add(-30*time.Minute), 200,
+			`{"instance":"node-1"}`, `{"instance":"node-2"}`, `{"instance":"node-3"}`),
+		`node_load1{instance="node-1"}`: matrixResult(firedAt.Add(-30*time.Minute), 10, `{"__name__":"node_load1","instance":"node-1"}`),
+	}}
+
+	config := gateway.DefaultMetricSnapshotConfig()
+	config.MaxRelatedSeries = 1
+	config.RelatedQueries = []string{`node_load1{instance="{{instance}}"}`, `up{pod="{{pod}}"}`}
+	service := NewMetricSnapshotService(repo, client, config, zap.NewNop())
+	service.now = func() time.Time { return now }
+
+	alert := &model.Alert{ID: 5, RuleID: 9, Labels: `{"instance":"node-1"}`, CreatedAt: firedAt}
+	snapshot, err := service.Snapshot(context.Background(), alert)
+	require.NoError(t, err)
+	require.NotNil(t, snapshot)
+
+	// 查询范围为触发前 30 分钟到触发后 5 分钟
+	assert.Equal(t, firedAt.Add(-30*time.Minute), snapshot.Start)
+	assert.Equal(t, firedAt.Add(5*time.Minute), snapshot.End)
+	assert.Equal(t, int64(15), snapshot.Step)
+
+	// 告警序列 + 1 条同表达式关联序列 + 1 条关联查询序列，缺少 pod 标签的模板被跳过
+	require.Len(t, snapshot.Series, 3)
+	assert.Len(t, client.queries, 2)
+	primary := snapshot.Series[0]
+	assert.False(t, primary.Related)
+	assert.Equal(t, "node-1", primary.Labels["instance"])
+	assert.Len(t, primary.Points, config.MaxPoints)
+	assert.Equal(t, 200, primary.Stats.Count)
+	assert.Equal(t, 0.0, primary.Stats.Min)
+	assert.Equal(t, 199.0, primary.Stats.Last)
+	assert.True(t, snapshot.Series[1].Related)
+	assert.Equal(t, `node_load1{instance="node-1"}`, snapshot.Series[2].Query)
+
+	assert.Same(t, snapshot, repo.saved[5])
+	assert.NotEmpty(t, alert.MetricSnapshot)
+	chart := snapshot.TextChart()
+	assert.Contains(t, chart, `[告警序列] {instance="node-1"}`)
+	assert.Contains(t, chart, "▁")
+	assert.Contains(t, chart, "█")
+
+	// 已有快照时不重复查询
+	_, err = service.Snapshot(context.Background(), alert)
+	require.NoError(t, err)
+	assert.Len(t, client.queries, 2)
+}
+
+func TestMetricSnapshotService_NoRuleExpression(t *testing.T) {
+	repo := &fakeSnapshotRepository{saved: map[uint]*model.MetricSnapshot{}}
+	client := &fakeRangeClient{}
+	service := NewMetricSnapshotService(repo, client, gateway.DefaultMetricSnapshotConfig(), zap.NewNop())
+
+	snapshot, err := service.Snapshot(context.Background(), &model.Alert{ID: 1, RuleID: 42})
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+	assert.Empty(t, client.queries)
+}
+
+func TestExpandRelatedQuery_EscapesLabelValues(t *testing.T) {
+	query, ok := expandRelatedQuery(`up{instance="{{instance}}"}`, map[string]string{"instance": `node-1"} or vector(1) or up{a="\`})
+	require.True(t, ok)
+	assert.Equal(t, `up{instance="node-1\"} or vector(1) or up{a=\"\\"}`, query)
+
+	_, ok = expandRelatedQuery(`up{pod="{{pod}}"}`, map[string]string{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,238 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+
+	"go.uber.org/zap"
+)
+
+// PipelineConsumer 网关处理流消费者，以消费组方式从处理流读取告警并按阶段处理
+type PipelineConsumer struct {
+	stream  gateway.AlertStream
+	handler gateway.PipelineHandler
+	metrics gateway.PipelineMetrics
+	config  gateway.PipelineConfig
+	logger  *zap.Logger
+}
+
+// NewPipelineConsumer 创建处理流消费者
+func NewPipelineConsumer(
+	stream gateway.AlertStream,
+	handler gateway.PipelineHandler,
+	metrics gateway.PipelineMetrics,
+	config gateway.PipelineConfig,
+	logger *zap.Logger,
+) *PipelineConsumer {
+	defaults := gateway.DefaultPipelineConfig()
+	if config.Consumer == "" {
+		hostname, _ := os.Hostname()
+		config.Consumer = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.BlockTimeout <= 0 {
+		config.BlockTimeout = defaults.BlockTimeout
+	}
+	if config.ClaimMinIdle <= 0 {
+		config.ClaimMinIdle = defaults.ClaimMinIdle
+	}
+	if config.ClaimInterval <= 0 {
+		config.ClaimInterval = defaults.ClaimInterval
+	}
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaults.MaxDeliveries
+	}
+	if config.StatsInterval <= 0 {
+		config.StatsInterval = defaults.StatsInterval
+	}
+
+	return &PipelineConsumer{
+		stream:  stream,
+		handler: handler,
+		metrics: metrics,
+		config:  config,
+		logger:  logger.With(zap.String("consumer", config.Consumer)),
+	}
+}
+
+// Run 运行消费者直到 ctx 取消。
+// 读取协程与处理协程之间使用无缓冲通道，处理能力不足时自动停止读取形成背压。
+func (pc *PipelineConsumer) Run(ctx context.Context) error {
+	if err := pc.stream.EnsureGroup(ctx); err != nil {
+		return err
+	}
+
+	jobs := make(chan *gateway.PipelineMessage)
+	var wg sync.WaitGroup
+
+	for i := 0; i < pc.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-jobs:
+					pc.handle(ctx, msg)
+				}
+			}
+		}()
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		pc.reclaimLoop(ctx, jobs)
+	}()
+	go func() {
+		defer wg.Done()
+		pc.statsLoop(ctx)
+	}()
+
+	pc.logger.Info("Pipeline consumer started",
+		zap.Int("concurrency", pc.config.Concurrency),
+		zap.Int64("max_deliveries", pc.config.MaxDeliveries))
+
+	for ctx.Err() == nil {
+		messages, err := pc.stream.Read(ctx, pc.config.Consumer, pc.config.BatchSize, pc.config.BlockTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			pc.logger.Error("Failed to read pipeline messages", zap.Error(err))
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		pc.dispatch(ctx, jobs, messages)
+	}
+
+	wg.Wait()
+	pc.logger.Info("Pipeline consumer stopped")
+	return nil
+}
+
+// dispatch 将消息分发给处理协程，ctx 取消后未分发的消息留在待确认列表中等待接管
+func (pc *PipelineConsumer) dispatch(ctx context.Context, jobs chan<- *gateway.PipelineMessage, messages []*gateway.PipelineMessage) {
+	for _, msg := range messages {
+		select {
+		case <-ctx.Done():
+			return
+		case jobs <- msg:
+		}
+	}
+}
+
+// reclaimLoop 定期接管其他消费者崩溃后遗留的待确认消息
+func (pc *PipelineConsumer) reclaimLoop(ctx context.Context, jobs chan<- *gateway.PipelineMessage) {
+	ticker := time.NewTicker(pc.config.ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			messages, err := pc.stream.Claim(ctx, pc.config.Consumer, pc.config.ClaimMinIdle, pc.config.BatchSize)
+			if err != nil {
+				pc.logger.Error("Failed to claim pending messages", zap.Error(err))
+				continue
+			}
+			if len(messages) > 0 {
+				pc.logger.Info("Claimed pending messages", zap.Int("count", len(messages)))
+			}
+			pc.dispatch(ctx, jobs, messages)
+		}
+	}
+}
+
+// statsLoop 定期裁剪已确认的消息并采集积压指标
+func (pc *PipelineConsumer) statsLoop(ctx context.Context) {
+	ticker := time.NewTicker(pc.config.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if trimmed, err := pc.stream.TrimAcked(ctx); err != nil {
+				pc.logger.Warn("Failed to trim acknowledged messages", zap.Error(err))
+			} else if trimmed > 0 {
+				pc.logger.Debug("Trimmed acknowledged messages", zap.Int64("count", trimmed))
+			}
+
+			stats, err := pc.stream.Stats(ctx)
+			if err != nil {
+				pc.logger.Warn("Failed to collect pipeline stats", zap.Error(err))
+				continue
+			}
+			if pc.metrics != nil {
+				pc.metrics.ObserveStats(stats)
+			}
+		}
+	}
+}
+
+// handle 处理单条消息：成功则确认，超过最大投递次数则转入死信，否则保留待重新投递
+func (pc *PipelineConsumer) handle(ctx context.Context, msg *gateway.PipelineMessage) {
+	start := time.Now()
+	stage, err := pc.handler.ProcessMessage(ctx, msg)
+	if err == nil {
+		if err := pc.stream.Ack(ctx, msg.ID); err != nil {
+			pc.logger.Error("Failed to ack pipeline message", zap.Error(err), zap.String("message_id", msg.ID))
+			return
+		}
+		pc.recordMessage("acked", start)
+		return
+	}
+
+	// 关闭过程中的失败不计入投递次数判断
+	if ctx.Err() != nil {
+		return
+	}
+
+	if msg.Deliveries >= pc.config.MaxDeliveries {
+		if dlErr := pc.stream.DeadLetter(ctx, msg, stage, pc.config.Consumer, err); dlErr != nil {
+			pc.logger.Error("Failed to dead letter pipeline message", zap.Error(dlErr), zap.String("message_id", msg.ID))
+			return
+		}
+		if pc.metrics != nil {
+			pc.metrics.RecordDeadLetter(stage)
+		}
+		pc.recordMessage("dead_letter", start)
+		pc.logger.Warn("Pipeline message moved to dead letter stream",
+			zap.String("message_id", msg.ID),
+			zap.String("stage", string(stage)),
+			zap.Int64("deliveries", msg.Deliveries),
+			zap.Error(err))
+		return
+	}
+
+	pc.recordMessage("retry", start)
+	pc.logger.Warn("Pipeline message processing failed, will be retried",
+		zap.String("message_id", msg.ID),
+		zap.String("stage", string(stage)),
+		zap.Int64("deliveries", msg.Deliveries),
+		zap.Error(err))
+}
+
+// recordMessage 记录消息处理结果
+func (pc *PipelineConsumer) recordMessage(status string, start time.Time) {
+	if pc.metrics != nil {
+		pc.metrics.RecordMessage(status, time.Since(start))
+	}
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/infrastructure/queue"
+	"alert_agent/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakePipelineHandler 测试用消息处理器，failing 中的记录始终处理失败
+type fakePipelineHandler struct {
+	mu        sync.Mutex
+	failing   map[string]bool
+	processed []string
+}
+
+func (h *fakePipelineHandler) ProcessMessage(_ context.Context, msg *gateway.PipelineMessage) (gateway.PipelineStage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.failing[msg.RecordID] {
+		return gateway.StageRouting, errors.New("no channel available")
+	}
+	h.processed = append(h.processed, msg.RecordID)
+	return "", nil
+}
+
+func (h *fakePipelineHandler) processedCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.processed)
+}
+
+func newTestPipeline(t *testing.T, handler gateway.PipelineHandler) (*PipelineConsumer, *queue.RedisAlertStream, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	stream := queue.NewRedisAlertStream(client)
+	require.NoError(t, stream.EnsureGroup(context.Background()))
+
+	consumer := NewPipelineConsumer(stream, handler, nil, gateway.PipelineConfig{
+		Consumer:      "worker-1",
+		Concurrency:   2,
+		BlockTimeout:  50 * time.Millisecond,
+		ClaimMinIdle:  time.Minute,
+		ClaimInterval: 20 * time.Millisecond,
+		MaxDeliveries: 2,
+		StatsInterval: time.Hour,
+	}, zap.NewNop())
+	return consumer, stream, mr
+}
+
+func publishPipelineMessage(t *testing.T, stream gateway.AlertStream, recordID string) {
+	_, err := stream.Publish(context.Background(), &gateway.PipelineMessage{
+		RecordID: recordID,
+		Alert:    &model.Alert{Name: "HighCPU"},
+	})
+	require.NoError(t, err)
+}
+
+func TestPipelineConsumer_RetryThenDeadLetter(t *testing.T) {
+	handler := &fakePipelineHandler{failing: map[string]bool{"bad": true}}
+	consumer, stream, mr := newTestPipeline(t, handler)
+	ctx := context.Background()
+
+	publishPipelineMessage(t, stream, "good")
+	publishPipelineMessage(t, stream, "bad")
+
+	messages, err := stream.Read(ctx, "worker-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	for _, msg := range messages {
+		consumer.handle(ctx, msg)
+	}
+
+	// 成功的消息被确认，失败的消息保留待重新投递
+	stats, err := stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Pending)
+	assert.Equal(t, int64(0), stats.DeadLetters)
+
+	// 其他消费者接管后再次失败，达到最大投递次数转入死信
+	mr.SetTime(time.Now().Add(2 * time.Minute))
+	claimed, err := stream.Claim(ctx, "worker-2", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	consumer.handle(ctx, claimed[0])
+
+	stats, err = stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Pending)
+	assert.Equal(t, int64(1), stats.DeadLetters)
+
+	deadLetters, err := stream.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "bad", deadLetters[0].Message.RecordID)
+	assert.Equal(t, gateway.StageRouting, deadLetters[0].Stage)
+	assert.Equal(t, "worker-1", deadLetters[0].Consumer)
+}
+
+func TestPipelineConsumer_Run(t *testing.T) {
+	handler := &fakePipelineHandler{}
+	consumer, stream, _ := newTestPipeline(t, handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	for _, id := range []string{"r1", "r2", "r3"} {
+		publishPipelineMessage(t, stream, id)
+	}
+
+	assert.Eventually(t, func() bool { return handler.processedCount() == 3 }, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	stats, err := stream.Stats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Pending)
+	assert.Equal(t, int64(0), stats.Lag)
+}
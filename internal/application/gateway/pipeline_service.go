@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// maxDeadLetterListSize 死信列表单次返回上限
+const maxDeadLetterListSize = 500
+
+// PipelineManagementService 处理管道管理服务实现
+type PipelineManagementService struct {
+	stream gateway.AlertStream
+	logger *zap.Logger
+}
+
+// NewPipelineManagementService 创建处理管道管理服务
+func NewPipelineManagementService(stream gateway.AlertStream, logger *zap.Logger) *PipelineManagementService {
+	return &PipelineManagementService{
+		stream: stream,
+		logger: logger,
+	}
+}
+
+// GetStats 获取处理管道统计
+func (s *PipelineManagementService) GetStats(ctx context.Context) (*gateway.PipelineStats, error) {
+	stats, err := s.stream.Stats(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get pipeline stats", err)
+	}
+	return stats, nil
+}
+
+// ListDeadLetters 获取最新的死信消息
+func (s *PipelineManagementService) ListDeadLetters(ctx context.Context, limit int64) ([]*gateway.DeadLetter, error) {
+	if limit <= 0 || limit > maxDeadLetterListSize {
+		limit = maxDeadLetterListSize
+	}
+
+	deadLetters, err := s.stream.ListDeadLetters(ctx, limit)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list dead letters", err)
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter 将死信消息重新发布到处理流
+func (s *PipelineManagementService) ReplayDeadLetter(ctx context.Context, id string) (string, error) {
+	deadLetter, err := s.stream.GetDeadLetter(ctx, id)
+	if err != nil {
+		return "", errors.NewInternalError("failed to get dead letter", err)
+	}
+	if deadLetter == nil || deadLetter.Message == nil {
+		return "", errors.NewNotFoundError(fmt.Sprintf("dead letter %s", id))
+	}
+
+	messageID, err := s.stream.Replay(ctx, deadLetter)
+	if err != nil {
+		return "", errors.NewInternalError("failed to replay dead letter", err)
+	}
+
+	s.logger.Info("dead letter replayed",
+		zap.String("dead_letter_id", id),
+		zap.String("message_id", messageID),
+		zap.String("record_id", deadLetter.Message.RecordID))
+	return messageID, nil
+}
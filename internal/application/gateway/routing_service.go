@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// activeTreeRefreshInterval 生效路由树的刷新间隔，保证多副本最终一致
+const activeTreeRefreshInterval = 30 * time.Second
+
+// RoutingConfigService 路由配置服务实现
+type RoutingConfigService struct {
+	repo   gateway.RoutingConfigRepository
+	logger *zap.Logger
+
+	mu         sync.RWMutex
+	activeTree *RoutingTree
+	loadedAt   time.Time
+}
+
+// NewRoutingConfigService 创建路由配置服务
+func NewRoutingConfigService(repo gateway.RoutingConfigRepository, logger *zap.Logger) *RoutingConfigService {
+	return &RoutingConfigService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateConfig 创建新版本的路由配置
+func (s *RoutingConfigService) CreateConfig(ctx context.Context, req *gateway.CreateRoutingConfigRequest) (*gateway.RoutingConfig, error) {
+	latest, err := s.repo.LatestVersion(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get latest routing config version", err)
+	}
+
+	now := time.Now()
+	config := &gateway.RoutingConfig{
+		ID:            uuid.New().String(),
+		Version:       latest + 1,
+		Name:          req.Name,
+		Description:   req.Description,
+		Route:         req.Route,
+		Receivers:     req.Receivers,
+		TimeIntervals: req.TimeIntervals,
+		CreatedBy:     req.CreatedBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// 保存前校验配置可编译
+	if _, err := CompileRoutingTree(config); err != nil {
+		return nil, errors.NewValidationErrorWithDetails("INVALID_ROUTING_CONFIG", "Invalid routing config", err.Error())
+	}
+
+	if err := s.repo.Create(ctx, config); err != nil {
+		return nil, errors.NewInternalError("failed to create routing config", err)
+	}
+
+	s.logger.Info("routing config created", zap.Int("version", config.Version), zap.String("created_by", config.CreatedBy))
+
+	if req.Activate {
+		if err := s.ActivateConfig(ctx, config.Version); err != nil {
+			return nil, err
+		}
+		config.Active = true
+	}
+
+	return config, nil
+}
+
+// GetConfig 获取指定版本的路由配置
+func (s *RoutingConfigService) GetConfig(ctx context.Context, version int) (*gateway.RoutingConfig, error) {
+	config, err := s.repo.GetByVersion(ctx, version)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get routing config", err)
+	}
+	if config == nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("routing config version %d", version))
+	}
+	return config, nil
+}
+
+// GetActiveConfig 获取当前生效的路由配置
+func (s *RoutingConfigService) GetActiveConfig(ctx context.Context) (*gateway.RoutingConfig, error) {
+	config, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get active routing config", err)
+	}
+	if config == nil {
+		return nil, errors.NewNotFoundError("active routing config")
+	}
+	return config, nil
+}
+
+// ListConfigs 获取路由配置版本列表
+func (s *RoutingConfigService) ListConfigs(ctx context.Context, limit, offset int) ([]*gateway.RoutingConfig, int64, error) {
+	configs, total, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list routing configs", err)
+	}
+	return configs, total, nil
+}
+
+// ActivateConfig 激活指定版本
+func (s *RoutingConfigService) ActivateConfig(ctx context.Context, version int) error {
+	config, err := s.GetConfig(ctx, version)
+	if err != nil {
+		return err
+	}
+
+	tree, err := CompileRoutingTree(config)
+	if err != nil {
+		return errors.NewValidationErrorWithDetails("INVALID_ROUTING_CONFIG", "Invalid routing config", err.Error())
+	}
+
+	if err := s.repo.Activate(ctx, version); err != nil {
+		return errors.NewInternalError("failed to activate routing config", err)
+	}
+
+	s.mu.Lock()
+	s.activeTree = tree
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	s.logger.Info("routing config activated", zap.Int("version", version))
+	return nil
+}
+
+// TestRoute 测试示例告警会被路由到哪些接收者
+func (s *RoutingConfigService) TestRoute(ctx context.Context, req *gateway.RouteTestRequest) (*gateway.RouteTestResult, error) {
+	var config *gateway.RoutingConfig
+	var err error
+	if req.Version > 0 {
+		config, err = s.GetConfig(ctx, req.Version)
+	} else {
+		config, err = s.GetActiveConfig(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := CompileRoutingTree(config)
+	if err != nil {
+		return nil, errors.NewValidationErrorWithDetails("INVALID_ROUTING_CONFIG", "Invalid routing config", err.Error())
+	}
+
+	labels := gateway.ParseAlertLabels(req.Alert)
+	for k, v := range req.Labels {
+		labels[k] = v
+	}
+
+	at := time.Now()
+	if req.Time != nil {
+		at = *req.Time
+	}
+
+	matches := tree.Match(labels, at)
+	receivers, channels, groups := collectReceivers(matches)
+
+	return &gateway.RouteTestResult{
+		Version:        config.Version,
+		Labels:         labels,
+		Matches:        matches,
+		Receivers:      receivers,
+		ChannelIDs:     channels,
+		NotifyGroupIDs: groups,
+	}, nil
+}
+
+// Match 使用当前生效配置匹配告警，没有生效配置时返回 nil
+func (s *RoutingConfigService) Match(ctx context.Context, alert *model.Alert, at time.Time) ([]*gateway.RouteMatch, error) {
+	tree, err := s.getActiveTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tree == nil {
+		return nil, nil
+	}
+	return tree.Match(gateway.ParseAlertLabels(alert), at), nil
+}
+
+// getActiveTree 获取缓存的生效路由树，过期后从仓储重新加载
+func (s *RoutingConfigService) getActiveTree(ctx context.Context) (*RoutingTree, error) {
+	s.mu.RLock()
+	tree, loadedAt := s.activeTree, s.loadedAt
+	s.mu.RUnlock()
+
+	if !loadedAt.IsZero() && time.Since(loadedAt) < activeTreeRefreshInterval {
+		return tree, nil
+	}
+
+	config, err := s.repo.GetActive(ctx)
+	if err != nil {
+		// 加载失败时继续使用旧的路由树，并等到下个刷新间隔再重试，避免每条告警都访问仓储
+		s.logger.Warn("failed to reload active routing config", zap.Error(err))
+		s.mu.Lock()
+		if s.loadedAt.Equal(loadedAt) {
+			s.loadedAt = time.Now()
+		}
+		s.mu.Unlock()
+		return tree, nil
+	}
+
+	tree = nil
+	if config != nil {
+		tree, err = CompileRoutingTree(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile active routing config v%d: %w", config.Version, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.activeTree = tree
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return tree, nil
+}
@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// flakyRoutingRepository 记录加载次数，err 非空时加载失败
+type flakyRoutingRepository struct {
+	gateway.RoutingConfigRepository
+	config *gateway.RoutingConfig
+	err    error
+	loads  int
+}
+
+func (r *flakyRoutingRepository) GetActive(ctx context.Context) (*gateway.RoutingConfig, error) {
+	r.loads++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.config, nil
+}
+
+func TestRoutingConfigService_BacksOffAfterLoadFailure(t *testing.T) {
+	repo := &flakyRoutingRepository{err: errors.New("connection refused")}
+	service := NewRoutingConfigService(repo, zap.NewNop())
+	ctx := context.Background()
+	alert := &model.Alert{Name: "HighCPU", Labels: `{"severity":"critical"}`}
+
+	// 仓储不可用时不为每条告警重试加载
+	for i := 0; i < 3; i++ {
+		matches, err := service.Match(ctx, alert, alert.CreatedAt)
+		require.NoError(t, err)
+		assert.Nil(t, matches)
+	}
+	assert.Equal(t, 1, repo.loads)
+
+	// 刷新间隔过后重新加载
+	repo.err = nil
+	repo.config = newTestRoutingConfig()
+	service.mu.Lock()
+	service.loadedAt = service.loadedAt.Add(-activeTreeRefreshInterval)
+	service.mu.Unlock()
+
+	matches, err := service.Match(ctx, alert, alert.CreatedAt)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.loads)
+	assert.Contains(t, receiversOf(matches), "oncall")
+}
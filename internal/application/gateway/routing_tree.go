@@ -0,0 +1,456 @@
+package gateway
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+)
+
+// RoutingTree 编译后的路由树
+type RoutingTree struct {
+	version       int
+	root          *routeNode
+	receivers     map[string]gateway.Receiver
+	timeIntervals map[string]*compiledTimeInterval
+}
+
+// routeNode 编译后的路由节点，分组参数已继承父节点
+type routeNode struct {
+	path                string
+	receiver            string
+	matchers            []*compiledMatcher
+	continueMatching    bool
+	groupBy             []string
+	groupWait           int64
+	groupInterval       int64
+	repeatInterval      int64
+	activeTimeIntervals []string
+	children            []*routeNode
+}
+
+// compiledMatcher 编译后的标签匹配器
+type compiledMatcher struct {
+	gateway.Matcher
+	re *regexp.Regexp
+}
+
+// compiledTimeInterval 编译后的时间区间
+type compiledTimeInterval struct {
+	weekdays []weekdayRange
+	times    []minuteRange
+	location *time.Location
+}
+
+type weekdayRange struct {
+	start, end time.Weekday
+}
+
+type minuteRange struct {
+	start, end int
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// CompileRoutingTree 校验并编译路由配置
+func CompileRoutingTree(config *gateway.RoutingConfig) (*RoutingTree, error) {
+	if config == nil || config.Route == nil {
+		return nil, fmt.Errorf("routing config must contain a root route")
+	}
+	if config.Route.Receiver == "" {
+		return nil, fmt.Errorf("root route must specify a receiver")
+	}
+
+	tree := &RoutingTree{
+		version:       config.Version,
+		receivers:     make(map[string]gateway.Receiver),
+		timeIntervals: make(map[string]*compiledTimeInterval),
+	}
+
+	for _, r := range config.Receivers {
+		if r.Name == "" {
+			return nil, fmt.Errorf("receiver name cannot be empty")
+		}
+		if _, exists := tree.receivers[r.Name]; exists {
+			return nil, fmt.Errorf("duplicate receiver %q", r.Name)
+		}
+		tree.receivers[r.Name] = r
+	}
+
+	for _, ti := range config.TimeIntervals {
+		if ti.Name == "" {
+			return nil, fmt.Errorf("time interval name cannot be empty")
+		}
+		if _, exists := tree.timeIntervals[ti.Name]; exists {
+			return nil, fmt.Errorf("duplicate time interval %q", ti.Name)
+		}
+		compiled, err := compileTimeInterval(ti)
+		if err != nil {
+			return nil, fmt.Errorf("time interval %q: %w", ti.Name, err)
+		}
+		tree.timeIntervals[ti.Name] = compiled
+	}
+
+	root, err := tree.compileRoute(config.Route, nil, "root")
+	if err != nil {
+		return nil, err
+	}
+	tree.root = root
+
+	return tree, nil
+}
+
+// compileRoute 递归编译路由节点
+func (t *RoutingTree) compileRoute(route *gateway.Route, parent *routeNode, path string) (*routeNode, error) {
+	node := &routeNode{
+		path:                path,
+		receiver:            route.Receiver,
+		continueMatching:    route.Continue,
+		groupBy:             route.GroupBy,
+		groupWait:           route.GroupWait,
+		groupInterval:       route.GroupInterval,
+		repeatInterval:      route.RepeatInterval,
+		activeTimeIntervals: route.ActiveTimeIntervals,
+	}
+
+	// 继承父节点参数
+	if parent != nil {
+		if node.receiver == "" {
+			node.receiver = parent.receiver
+		}
+		if node.groupBy == nil {
+			node.groupBy = parent.groupBy
+		}
+		if node.groupWait == 0 {
+			node.groupWait = parent.groupWait
+		}
+		if node.groupInterval == 0 {
+			node.groupInterval = parent.groupInterval
+		}
+		if node.repeatInterval == 0 {
+			node.repeatInterval = parent.repeatInterval
+		}
+		if node.activeTimeIntervals == nil {
+			node.activeTimeIntervals = parent.activeTimeIntervals
+		}
+	}
+
+	if _, exists := t.receivers[node.receiver]; !exists {
+		return nil, fmt.Errorf("route %s references unknown receiver %q", path, node.receiver)
+	}
+	for _, name := range node.activeTimeIntervals {
+		if _, exists := t.timeIntervals[name]; !exists {
+			return nil, fmt.Errorf("route %s references unknown time interval %q", path, name)
+		}
+	}
+
+	for _, m := range route.Matchers {
+		cm, err := compileMatcher(m)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", path, err)
+		}
+		node.matchers = append(node.matchers, cm)
+	}
+
+	for i, child := range route.Routes {
+		if child == nil {
+			continue
+		}
+		childNode, err := t.compileRoute(child, node, fmt.Sprintf("%s/%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+		node.children = append(node.children, childNode)
+	}
+
+	return node, nil
+}
+
+// Version 返回路由树对应的配置版本
+func (t *RoutingTree) Version() int {
+	return t.version
+}
+
+// Match 返回告警标签在指定时间匹配到的路由
+func (t *RoutingTree) Match(labels map[string]string, at time.Time) []*gateway.RouteMatch {
+	nodes := t.root.match(labels)
+	matches := make([]*gateway.RouteMatch, 0, len(nodes))
+	for _, node := range nodes {
+		receiver := t.receivers[node.receiver]
+		matches = append(matches, &gateway.RouteMatch{
+			Path:           node.path,
+			Receiver:       node.receiver,
+			ChannelIDs:     receiver.ChannelIDs,
+			NotifyGroupIDs: receiver.NotifyGroupIDs,
+			GroupBy:        node.groupBy,
+			GroupWait:      node.groupWait,
+			GroupInterval:  node.groupInterval,
+			RepeatInterval: node.repeatInterval,
+			Active:         t.isActive(node, at),
+		})
+	}
+	return matches
+}
+
+// match 深度优先匹配，语义与 Alertmanager 一致
+func (n *routeNode) match(labels map[string]string) []*routeNode {
+	for _, m := range n.matchers {
+		if !m.matches(labels[m.Name]) {
+			return nil
+		}
+	}
+
+	var result []*routeNode
+	for _, child := range n.children {
+		matched := child.match(labels)
+		result = append(result, matched...)
+		if len(matched) > 0 && !child.continueMatching {
+			break
+		}
+	}
+
+	// 没有子路由匹配时由当前节点接收
+	if len(result) == 0 {
+		result = append(result, n)
+	}
+	return result
+}
+
+// isActive 判断路由在指定时间是否生效
+func (t *RoutingTree) isActive(node *routeNode, at time.Time) bool {
+	if len(node.activeTimeIntervals) == 0 {
+		return true
+	}
+	for _, name := range node.activeTimeIntervals {
+		if t.timeIntervals[name].contains(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileMatcher 编译标签匹配器，正则表达式整体锚定
+func compileMatcher(m gateway.Matcher) (*compiledMatcher, error) {
+	if m.Name == "" {
+		return nil, fmt.Errorf("matcher name cannot be empty")
+	}
+	if m.Type == "" {
+		m.Type = gateway.MatchEqual
+	}
+
+	cm := &compiledMatcher{Matcher: m}
+	switch m.Type {
+	case gateway.MatchEqual, gateway.MatchNotEqual:
+	case gateway.MatchRegexp, gateway.MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp for matcher %q: %w", m.Name, err)
+		}
+		cm.re = re
+	default:
+		return nil, fmt.Errorf("unsupported match type %q", m.Type)
+	}
+	return cm, nil
+}
+
+// LabelMatchers 编译后的一组匹配器，供路由以外的模块复用路由匹配语义
+type LabelMatchers []*compiledMatcher
+
+// CompileMatchers 编译匹配器，匹配器无效时返回错误
+func CompileMatchers(matchers []gateway.Matcher) (LabelMatchers, error) {
+	compiled := make(LabelMatchers, 0, len(matchers))
+	for _, m := range matchers {
+		cm, err := compileMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, cm)
+	}
+	return compiled, nil
+}
+
+// Matches 标签是否满足所有匹配器，缺失的标签视为空值
+func (ms LabelMatchers) Matches(labels map[string]string) bool {
+	for _, m := range ms {
+		if !m.matches(labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches 检查标签值是否满足匹配器
+func (m *compiledMatcher) matches(value string) bool {
+	switch m.Type {
+	case gateway.MatchEqual:
+		return value == m.Value
+	case gateway.MatchNotEqual:
+		return value != m.Value
+	case gateway.MatchRegexp:
+		return m.re.MatchString(value)
+	case gateway.MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	return false
+}
+
+// compileTimeInterval 编译时间区间
+func compileTimeInterval(ti gateway.TimeInterval) (*compiledTimeInterval, error) {
+	compiled := &compiledTimeInterval{location: time.Local}
+	if ti.Location != "" {
+		loc, err := time.LoadLocation(ti.Location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid location: %w", err)
+		}
+		compiled.location = loc
+	}
+
+	for _, wd := range ti.Weekdays {
+		parts := strings.SplitN(strings.ToLower(strings.TrimSpace(wd)), ":", 2)
+		start, ok := weekdayNames[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", wd)
+		}
+		end := start
+		if len(parts) == 2 {
+			if end, ok = weekdayNames[parts[1]]; !ok {
+				return nil, fmt.Errorf("invalid weekday %q", wd)
+			}
+		}
+		compiled.weekdays = append(compiled.weekdays, weekdayRange{start: start, end: end})
+	}
+
+	for _, tr := range ti.Times {
+		start, err := parseClock(tr.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(tr.End)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("time range %s-%s must end after it starts", tr.Start, tr.End)
+		}
+		compiled.times = append(compiled.times, minuteRange{start: start, end: end})
+	}
+
+	return compiled, nil
+}
+
+// contains 检查时间是否落在区间内
+func (ti *compiledTimeInterval) contains(at time.Time) bool {
+	at = at.In(ti.location)
+
+	if len(ti.weekdays) > 0 {
+		matched := false
+		for _, wr := range ti.weekdays {
+			if wr.start <= wr.end {
+				matched = at.Weekday() >= wr.start && at.Weekday() <= wr.end
+			} else {
+				// 跨周，如 saturday:monday
+				matched = at.Weekday() >= wr.start || at.Weekday() <= wr.end
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(ti.times) > 0 {
+		minute := at.Hour()*60 + at.Minute()
+		for _, tr := range ti.times {
+			if minute >= tr.start && minute < tr.end {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// parseClock 解析 HH:MM 为当天分钟数，允许 24:00
+func parseClock(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	total := hour*60 + minute
+	if hour < 0 || minute < 0 || minute > 59 || total > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return total, nil
+}
+
+// collectReceivers 按匹配顺序汇总生效路由的接收者、渠道与通知组并去重
+func collectReceivers(matches []*gateway.RouteMatch) ([]string, []string, []uint) {
+	receiverSet := make(map[string]struct{})
+	channelSet := make(map[string]struct{})
+	groupSet := make(map[uint]struct{})
+	var receivers, channels []string
+	var groups []uint
+
+	for _, m := range matches {
+		if !m.Active {
+			continue
+		}
+		if _, seen := receiverSet[m.Receiver]; !seen {
+			receiverSet[m.Receiver] = struct{}{}
+			receivers = append(receivers, m.Receiver)
+		}
+		for _, id := range m.ChannelIDs {
+			if _, seen := channelSet[id]; !seen {
+				channelSet[id] = struct{}{}
+				channels = append(channels, id)
+			}
+		}
+		for _, id := range m.NotifyGroupIDs {
+			if _, seen := groupSet[id]; !seen {
+				groupSet[id] = struct{}{}
+				groups = append(groups, id)
+			}
+		}
+	}
+
+	return receivers, channels, groups
+}
+
+// groupingOf 返回第一个生效路由的分组设置，没有生效路由时返回 nil
+func groupingOf(matches []*gateway.RouteMatch) *gateway.RouteGrouping {
+	for _, m := range matches {
+		if !m.Active {
+			continue
+		}
+		return &gateway.RouteGrouping{
+			Receiver:       m.Receiver,
+			GroupBy:        m.GroupBy,
+			GroupWait:      m.GroupWait,
+			GroupInterval:  m.GroupInterval,
+			RepeatInterval: m.RepeatInterval,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRoutingConfig() *gateway.RoutingConfig {
+	return &gateway.RoutingConfig{
+		Version: 1,
+		Route: &gateway.Route{
+			Receiver:  "default",
+			GroupBy:   []string{"alertname"},
+			GroupWait: 30,
+			Routes: []*gateway.Route{
+				{
+					Receiver: "dba",
+					Matchers: []gateway.Matcher{{Name: "service", Type: gateway.MatchRegexp, Value: "mysql|postgres"}},
+					Continue: true,
+				},
+				{
+					Receiver: "oncall",
+					Matchers: []gateway.Matcher{{Name: "severity", Type: gateway.MatchEqual, Value: "critical"}},
+					GroupBy:  []string{"service"},
+					Routes: []*gateway.Route{
+						{
+							Receiver:            "business-hours",
+							Matchers:            []gateway.Matcher{{Name: "team", Value: "web"}},
+							ActiveTimeIntervals: []string{"workdays"},
+						},
+					},
+				},
+				{
+					Receiver: "never",
+					Matchers: []gateway.Matcher{{Name: "severity", Type: gateway.MatchNotEqual, Value: ""}},
+				},
+			},
+		},
+		Receivers: []gateway.Receiver{
+			{Name: "default", ChannelIDs: []string{"ch-default"}},
+			{Name: "dba", ChannelIDs: []string{"ch-dba"}},
+			{Name: "oncall", ChannelIDs: []string{"ch-oncall", "ch-sms"}},
+			{Name: "business-hours", ChannelIDs: []string{"ch-web"}},
+			{Name: "never", ChannelIDs: []string{"ch-never"}},
+		},
+		TimeIntervals: []gateway.TimeInterval{
+			{
+				Name:     "workdays",
+				Weekdays: []string{"monday:friday"},
+				Times:    []gateway.DayTimeRange{{Start: "09:00", End: "18:00"}},
+				Location: "UTC",
+			},
+		},
+	}
+}
+
+func receiversOf(matches []*gateway.RouteMatch) []string {
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.Receiver)
+	}
+	return names
+}
+
+func TestRoutingTree_Match(t *testing.T) {
+	tree, err := CompileRoutingTree(newTestRoutingConfig())
+	require.NoError(t, err)
+
+	monday := time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected []string
+	}{
+		{
+			name:     "continue flag keeps matching siblings",
+			labels:   map[string]string{"service": "mysql", "severity": "critical"},
+			expected: []string{"dba", "oncall"},
+		},
+		{
+			name:     "first non-continue match stops evaluation",
+			labels:   map[string]string{"severity": "warning"},
+			expected: []string{"never"},
+		},
+		{
+			name:     "nested route is preferred over its parent",
+			labels:   map[string]string{"severity": "critical", "team": "web"},
+			expected: []string{"business-hours"},
+		},
+		{
+			name:     "root receives unmatched alerts",
+			labels:   map[string]string{"service": "redis"},
+			expected: []string{"default"},
+		},
+		{
+			name:     "regexp matchers are anchored",
+			labels:   map[string]string{"service": "mysql-proxy"},
+			expected: []string{"default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, receiversOf(tree.Match(tt.labels, monday)))
+		})
+	}
+}
+
+func TestRoutingTree_Inheritance(t *testing.T) {
+	tree, err := CompileRoutingTree(newTestRoutingConfig())
+	require.NoError(t, err)
+
+	matches := tree.Match(map[string]string{"severity": "critical", "team": "web"}, time.Now())
+	require.Len(t, matches, 1)
+	assert.Equal(t, []string{"service"}, matches[0].GroupBy)
+	assert.Equal(t, int64(30), matches[0].GroupWait)
+	assert.Equal(t, "root/1/0", matches[0].Path)
+}
+
+func TestRoutingTree_ActiveTimeIntervals(t *testing.T) {
+	tree, err := CompileRoutingTree(newTestRoutingConfig())
+	require.NoError(t, err)
+
+	labels := map[string]string{"severity": "critical", "team": "web"}
+
+	inHours := tree.Match(labels, time.Date(2026, 10, 12, 10, 0, 0, 0, time.UTC))
+	assert.True(t, inHours[0].Active)
+
+	weekend := tree.Match(labels, time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC))
+	assert.False(t, weekend[0].Active)
+
+	receivers, channels, groups := collectReceivers(weekend)
+	assert.Empty(t, receivers)
+	assert.Empty(t, channels)
+	assert.Empty(t, groups)
+}
+
+func TestCompileRoutingTree_Validation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(cfg *gateway.RoutingConfig)
+	}{
+		{
+			name:   "unknown receiver",
+			mutate: func(cfg *gateway.RoutingConfig) { cfg.Route.Routes[0].Receiver = "missing" },
+		},
+		{
+			name:   "unknown time interval",
+			mutate: func(cfg *gateway.RoutingConfig) { cfg.Route.ActiveTimeIntervals = []string{"missing"} },
+		},
+		{
+			name:   "invalid regexp",
+			mutate: func(cfg *gateway.RoutingConfig) { cfg.Route.Routes[0].Matchers[0].Value = "(" },
+		},
+		{
+			name:   "invalid weekday",
+			mutate: func(cfg *gateway.RoutingConfig) { cfg.TimeIntervals[0].Weekdays = []string{"someday"} },
+		},
+		{
+			name:   "root without receiver",
+			mutate: func(cfg *gateway.RoutingConfig) { cfg.Route.Receiver = "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestRoutingConfig()
+			tt.mutate(cfg)
+			_, err := CompileRoutingTree(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
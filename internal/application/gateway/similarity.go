@@ -0,0 +1,323 @@
+package gateway
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// 相似度信号名称
+const (
+	SignalLabels    = "labels"
+	SignalText      = "text"
+	SignalTime      = "time"
+	SignalEmbedding = "embedding"
+)
+
+// SimilarityEngine 多信号告警相似度计算，信号不可用时按剩余权重归一化
+type SimilarityEngine struct {
+	config   gateway.SimilarityConfig
+	embedder gateway.EmbeddingProvider
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	docFreq  map[string]int // 词项出现的文档数
+	docCount int
+	window   [][]string // 最近观察的文档词项，按环形缓冲淘汰
+	next     int        // 下一次写入窗口的位置
+
+	cacheMu sync.Mutex
+	cache   map[uint64][]float32 // 文本哈希 -> 向量
+}
+
+// NewSimilarityEngine 创建相似度计算引擎，embedder 为空时不使用向量信号
+func NewSimilarityEngine(config gateway.SimilarityConfig, embedder gateway.EmbeddingProvider, logger *zap.Logger) *SimilarityEngine {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SimilarityEngine{
+		config:   config,
+		embedder: embedder,
+		logger:   logger,
+		docFreq:  make(map[string]int),
+		cache:    make(map[uint64][]float32),
+	}
+}
+
+// Observe 将告警文本加入语料，语料只保留最近 CorpusWindow 条告警，超出时淘汰最早的告警
+func (e *SimilarityEngine) Observe(alert *model.Alert) {
+	if alert == nil {
+		return
+	}
+	terms := termFrequency(alertText(alert))
+	if len(terms) == 0 {
+		return
+	}
+	doc := make([]string, 0, len(terms))
+	for term := range terms {
+		doc = append(doc, term)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if size := e.config.CorpusWindow; size > 0 {
+		if len(e.window) < size {
+			e.window = append(e.window, doc)
+		} else {
+			e.forget(e.window[e.next])
+			e.window[e.next] = doc
+			e.next = (e.next + 1) % size
+		}
+	}
+	e.docCount++
+	for _, term := range doc {
+		e.docFreq[term]++
+	}
+}
+
+// forget 从语料中移除一条告警的词项，不再出现的词项一并删除
+func (e *SimilarityEngine) forget(doc []string) {
+	e.docCount--
+	for _, term := range doc {
+		if e.docFreq[term] <= 1 {
+			delete(e.docFreq, term)
+		} else {
+			e.docFreq[term]--
+		}
+	}
+}
+
+// Score 计算两条告警的相似度
+func (e *SimilarityEngine) Score(ctx context.Context, a, b *model.Alert) (*gateway.SimilarityScore, error) {
+	signals := make(map[string]float64)
+	weights := e.config.Weights
+
+	if weights.Labels > 0 {
+		if score, ok := labelJaccard(a, b); ok {
+			signals[SignalLabels] = score
+		}
+	}
+	if weights.Text > 0 {
+		if score, ok := e.textCosine(a, b); ok {
+			signals[SignalText] = score
+		}
+	}
+	if weights.Time > 0 {
+		if score, ok := e.timeProximity(a, b); ok {
+			signals[SignalTime] = score
+		}
+	}
+	if weights.Embedding > 0 && e.embedder != nil {
+		score, err := e.embeddingCosine(ctx, a, b)
+		if err != nil {
+			// 向量服务不可用时退化为其余信号
+			e.logger.Warn("embedding similarity unavailable", zap.Error(err))
+		} else {
+			signals[SignalEmbedding] = score
+		}
+	}
+
+	return &gateway.SimilarityScore{
+		Total:   weightedTotal(signals, weights),
+		Signals: signals,
+	}, nil
+}
+
+// weightedTotal 按可用信号的权重加权平均
+func weightedTotal(signals map[string]float64, weights gateway.SimilarityWeights) float64 {
+	byName := map[string]float64{
+		SignalLabels:    weights.Labels,
+		SignalText:      weights.Text,
+		SignalTime:      weights.Time,
+		SignalEmbedding: weights.Embedding,
+	}
+
+	var total, weightSum float64
+	for name, score := range signals {
+		total += byName[name] * score
+		weightSum += byName[name]
+	}
+	if weightSum == 0 {
+		return 0
+	}
+	return total / weightSum
+}
+
+// labelJaccard 标签键值对的 Jaccard 相似度
+func labelJaccard(a, b *model.Alert) (float64, bool) {
+	left := gateway.ParseAlertLabels(a)
+	right := gateway.ParseAlertLabels(b)
+	if len(left) == 0 && len(right) == 0 {
+		return 0, false
+	}
+
+	intersection := 0
+	for k, v := range left {
+		if rv, ok := right[k]; ok && rv == v {
+			intersection++
+		}
+	}
+	union := len(left) + len(right) - intersection
+	return float64(intersection) / float64(union), true
+}
+
+// textCosine 标题与内容的 TF-IDF 余弦相似度
+func (e *SimilarityEngine) textCosine(a, b *model.Alert) (float64, bool) {
+	left := termFrequency(alertText(a))
+	right := termFrequency(alertText(b))
+	if len(left) == 0 || len(right) == 0 {
+		return 0, false
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var dot, normA, normB float64
+	for term, tf := range left {
+		w := tf * e.idf(term)
+		normA += w * w
+		if rtf, ok := right[term]; ok {
+			dot += w * rtf * e.idf(term)
+		}
+	}
+	for term, tf := range right {
+		w := tf * e.idf(term)
+		normB += w * w
+	}
+	if normA == 0 || normB == 0 {
+		return 0, true
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), true
+}
+
+// idf 平滑的逆文档频率，语料为空时所有词项权重相同
+func (e *SimilarityEngine) idf(term string) float64 {
+	return math.Log(float64(e.docCount+1)/float64(e.docFreq[term]+1)) + 1
+}
+
+// timeProximity 按半衰期指数衰减的时间接近度
+func (e *SimilarityEngine) timeProximity(a, b *model.Alert) (float64, bool) {
+	if a.CreatedAt.IsZero() || b.CreatedAt.IsZero() || e.config.TimeDecayHalfLife <= 0 {
+		return 0, false
+	}
+	delta := math.Abs(a.CreatedAt.Sub(b.CreatedAt).Seconds())
+	return math.Exp(-math.Ln2 * delta / e.config.TimeDecayHalfLife.Seconds()), true
+}
+
+// embeddingCosine 文本向量的余弦相似度，负值截断为 0
+func (e *SimilarityEngine) embeddingCosine(ctx context.Context, a, b *model.Alert) (float64, error) {
+	left, err := e.embed(ctx, alertText(a))
+	if err != nil {
+		return 0, err
+	}
+	right, err := e.embed(ctx, alertText(b))
+	if err != nil {
+		return 0, err
+	}
+	if len(left) == 0 || len(left) != len(right) {
+		return 0, nil
+	}
+
+	var dot, normA, normB float64
+	for i := range left {
+		dot += float64(left[i]) * float64(right[i])
+		normA += float64(left[i]) * float64(left[i])
+		normB += float64(right[i]) * float64(right[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return math.Max(0, dot/(math.Sqrt(normA)*math.Sqrt(normB))), nil
+}
+
+// embed 生成文本向量，结果按文本哈希缓存
+func (e *SimilarityEngine) embed(ctx context.Context, text string) ([]float32, error) {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	key := h.Sum64()
+
+	e.cacheMu.Lock()
+	vector, ok := e.cache[key]
+	e.cacheMu.Unlock()
+	if ok {
+		return vector, nil
+	}
+
+	vector, err := e.embedder.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	e.cacheMu.Lock()
+	defer e.cacheMu.Unlock()
+	// 缓存满时整体清空，避免维护淘汰顺序
+	if e.config.EmbeddingCache > 0 && len(e.cache) >= e.config.EmbeddingCache {
+		e.cache = make(map[uint64][]float32)
+	}
+	e.cache[key] = vector
+	return vector, nil
+}
+
+// alertText 参与文本相似度计算的告警文本
+func alertText(alert *model.Alert) string {
+	return alert.Title + "\n" + alert.Content
+}
+
+// termFrequency 分词并计算归一化词频，中文按相邻字符二元组切分
+func termFrequency(text string) map[string]float64 {
+	var terms []string
+	var word []rune
+	var han []rune
+
+	flushWord := func() {
+		if len(word) > 1 {
+			terms = append(terms, string(word))
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		if len(han) == 1 {
+			terms = append(terms, string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			terms = append(terms, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	if len(terms) == 0 {
+		return nil
+	}
+	tf := make(map[string]float64, len(terms))
+	for _, term := range terms {
+		tf[term]++
+	}
+	for term := range tf {
+		tf[term] /= float64(len(terms))
+	}
+	return tf
+}
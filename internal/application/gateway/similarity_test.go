@@ -0,0 +1,230 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// similarityThreshold 判定为相似告警的阈值
+const similarityThreshold = 0.5
+
+type fixtureAlert struct {
+	Name    string            `json:"name"`
+	Level   string            `json:"level"`
+	Source  string            `json:"source"`
+	Title   string            `json:"title"`
+	Content string            `json:"content"`
+	Labels  map[string]string `json:"labels"`
+	Minute  int               `json:"minute"`
+}
+
+type similarityPair struct {
+	Name    string       `json:"name"`
+	Similar bool         `json:"similar"`
+	A       fixtureAlert `json:"a"`
+	B       fixtureAlert `json:"b"`
+}
+
+func (f fixtureAlert) toAlert(base time.Time) *model.Alert {
+	labels, _ := json.Marshal(f.Labels)
+	alert := &model.Alert{
+		Name:    f.Name,
+		Level:   f.Level,
+		Source:  f.Source,
+		Title:   f.Title,
+		Content: f.Content,
+		Labels:  string(labels),
+	}
+	alert.CreatedAt = base.Add(time.Duration(f.Minute) * time.Minute)
+	return alert
+}
+
+func loadSimilarityPairs(tb testing.TB) []similarityPair {
+	data, err := os.ReadFile("testdata/similarity_pairs.json")
+	require.NoError(tb, err)
+	var pairs []similarityPair
+	require.NoError(tb, json.Unmarshal(data, &pairs))
+	return pairs
+}
+
+// legacySimilarity 原有的名称/级别/来源相似度，作为对比基线
+func legacySimilarity(a, b *model.Alert) float64 {
+	score := 0.0
+	if a.Name == b.Name {
+		score += 0.4
+	}
+	if a.Level == b.Level {
+		score += 0.3
+	}
+	if a.Source == b.Source {
+		score += 0.3
+	}
+	return score
+}
+
+type confusion struct {
+	tp, fp, tn, fn int
+}
+
+func (c *confusion) add(predicted, actual bool) {
+	switch {
+	case predicted && actual:
+		c.tp++
+	case predicted && !actual:
+		c.fp++
+	case !predicted && actual:
+		c.fn++
+	default:
+		c.tn++
+	}
+}
+
+func (c confusion) accuracy() float64 {
+	return float64(c.tp+c.tn) / float64(c.tp+c.fp+c.tn+c.fn)
+}
+
+func (c confusion) precision() float64 {
+	if c.tp+c.fp == 0 {
+		return 0
+	}
+	return float64(c.tp) / float64(c.tp+c.fp)
+}
+
+func (c confusion) recall() float64 {
+	if c.tp+c.fn == 0 {
+		return 0
+	}
+	return float64(c.tp) / float64(c.tp+c.fn)
+}
+
+func TestSimilarityEngine_LabelledFixtures(t *testing.T) {
+	pairs := loadSimilarityPairs(t)
+	engine := NewSimilarityEngine(gateway.DefaultSimilarityConfig(), nil, nil)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, pair := range pairs {
+		engine.Observe(pair.A.toAlert(base))
+		engine.Observe(pair.B.toAlert(base))
+	}
+
+	var engineResult, legacyResult confusion
+	for _, pair := range pairs {
+		a, b := pair.A.toAlert(base), pair.B.toAlert(base)
+		score, err := engine.Score(context.Background(), a, b)
+		require.NoError(t, err)
+
+		engineResult.add(score.Total >= similarityThreshold, pair.Similar)
+		legacyResult.add(legacySimilarity(a, b) >= similarityThreshold, pair.Similar)
+		t.Logf("%-45s similar=%-5v score=%.3f signals=%v", pair.Name, pair.Similar, score.Total, score.Signals)
+	}
+
+	t.Logf("engine: accuracy=%.2f precision=%.2f recall=%.2f", engineResult.accuracy(), engineResult.precision(), engineResult.recall())
+	t.Logf("legacy: accuracy=%.2f precision=%.2f recall=%.2f", legacyResult.accuracy(), legacyResult.precision(), legacyResult.recall())
+
+	assert.GreaterOrEqual(t, engineResult.accuracy(), 0.9)
+	assert.Greater(t, engineResult.accuracy(), legacyResult.accuracy())
+}
+
+type fakeEmbedder struct {
+	vectors map[string][]float32
+	err     error
+	calls   int
+}
+
+func (f *fakeEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.vectors[text], nil
+}
+
+func TestSimilarityEngine_Embedding(t *testing.T) {
+	a := &model.Alert{Title: "disk full", Content: "node-1"}
+	b := &model.Alert{Title: "no space left", Content: "node-1"}
+	embedder := &fakeEmbedder{vectors: map[string][]float32{
+		alertText(a): {1, 0},
+		alertText(b): {1, 0},
+	}}
+
+	config := gateway.SimilarityConfig{Weights: gateway.SimilarityWeights{Embedding: 1}}
+	engine := NewSimilarityEngine(config, embedder, nil)
+
+	score, err := engine.Score(context.Background(), a, b)
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, score.Total, 1e-9)
+	assert.Contains(t, score.Signals, SignalEmbedding)
+
+	// 向量按文本缓存
+	_, err = engine.Score(context.Background(), a, b)
+	require.NoError(t, err)
+	assert.Equal(t, 2, embedder.calls)
+}
+
+func TestSimilarityEngine_EmbeddingFailureFallsBack(t *testing.T) {
+	a := &model.Alert{Name: "HighCPU", Title: "cpu high"}
+	b := &model.Alert{Name: "HighCPU", Title: "cpu high"}
+
+	config := gateway.SimilarityConfig{Weights: gateway.SimilarityWeights{Labels: 0.5, Embedding: 0.5}}
+	engine := NewSimilarityEngine(config, &fakeEmbedder{err: errors.New("connection refused")}, nil)
+
+	score, err := engine.Score(context.Background(), a, b)
+	require.NoError(t, err)
+	assert.NotContains(t, score.Signals, SignalEmbedding)
+	// 仅剩标签信号，权重归一化后总分等于标签相似度
+	assert.InDelta(t, 1.0, score.Total, 1e-9)
+}
+
+func BenchmarkSimilarityEngine_Score(b *testing.B) {
+	pairs := loadSimilarityPairs(b)
+	engine := NewSimilarityEngine(gateway.DefaultSimilarityConfig(), nil, nil)
+	base := time.Now()
+
+	alerts := make([][2]*model.Alert, len(pairs))
+	for i, pair := range pairs {
+		alerts[i] = [2]*model.Alert{pair.A.toAlert(base), pair.B.toAlert(base)}
+		engine.Observe(alerts[i][0])
+		engine.Observe(alerts[i][1])
+	}
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := alerts[i%len(alerts)]
+		if _, err := engine.Score(ctx, pair[0], pair[1]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSimilarityEngine_CorpusWindowEvictsOldAlerts(t *testing.T) {
+	config := gateway.DefaultSimilarityConfig()
+	config.CorpusWindow = 2
+	engine := NewSimilarityEngine(config, nil, nil)
+
+	engine.Observe(&model.Alert{Title: "disk full"})
+	engine.Observe(&model.Alert{Title: "cpu high"})
+	engine.Observe(&model.Alert{Title: "memory high"})
+
+	// 最早的告警移出语料，只在该告警出现的词项一并删除
+	assert.Equal(t, 2, engine.docCount)
+	assert.NotContains(t, engine.docFreq, "disk")
+	assert.NotContains(t, engine.docFreq, "full")
+	assert.Equal(t, 2, engine.docFreq["high"])
+	assert.Equal(t, 1, engine.docFreq["memory"])
+
+	engine.Observe(&model.Alert{Title: "disk full"})
+	assert.Equal(t, 2, engine.docCount)
+	assert.NotContains(t, engine.docFreq, "cpu")
+	assert.Equal(t, 1, engine.docFreq["high"])
+	assert.Equal(t, 1, engine.docFreq["disk"])
+}
@@ -23,10 +23,11 @@ type SmartGatewayService struct {
 	repository       gateway.AlertProcessingRepository
 	featureToggle    gateway.FeatureToggleService
 	metricsCollector gateway.MetricsCollector
+	flapDetector     gateway.FlapDetector
+	incidents        gateway.IncidentTracker
+	runbooks         gateway.RunbookTrigger
+	stream           gateway.AlertStream
 	logger           *zap.Logger
-
-	// 处理策略
-	strategies map[gateway.ProcessingMode]gateway.ProcessingStrategy
 }
 
 // NewSmartGatewayService 创建智能告警网关服务
@@ -39,6 +40,10 @@ func NewSmartGatewayService(
 	repository gateway.AlertProcessingRepository,
 	featureToggle gateway.FeatureToggleService,
 	metricsCollector gateway.MetricsCollector,
+	flapDetector gateway.FlapDetector,
+	incidents gateway.IncidentTracker,
+	runbooks gateway.RunbookTrigger,
+	stream gateway.AlertStream,
 	logger *zap.Logger,
 ) *SmartGatewayService {
 	sgs := &SmartGatewayService{
@@ -50,18 +55,16 @@ func NewSmartGatewayService(
 		repository:       repository,
 		featureToggle:    featureToggle,
 		metricsCollector: metricsCollector,
+		flapDetector:     flapDetector,
+		incidents:        incidents,
+		runbooks:         runbooks,
+		stream:           stream,
 		logger:           logger,
-		strategies:       make(map[gateway.ProcessingMode]gateway.ProcessingStrategy),
 	}
 
 	return sgs
 }
 
-// RegisterStrategy 注册处理策略
-func (sgs *SmartGatewayService) RegisterStrategy(strategy gateway.ProcessingStrategy) {
-	sgs.strategies[strategy.GetMode()] = strategy
-}
-
 // ReceiveAlert 接收告警
 func (sgs *SmartGatewayService) ReceiveAlert(ctx context.Context, alert *model.Alert) (*gateway.AlertProcessingRecord, error) {
 	start := time.Now()
@@ -107,12 +110,30 @@ func (sgs *SmartGatewayService) ReceiveAlert(ctx context.Context, alert *model.A
 		return record, fmt.Errorf("failed to receive alert: %w", err)
 	}
 
-	// 异步处理告警
+	// 配置了处理流时写入流，由 worker 消费组处理，保证重启不丢失
+	if sgs.stream != nil {
+		if _, err := sgs.stream.Publish(ctx, &gateway.PipelineMessage{
+			RecordID: processingRecord.ID,
+			Alert:    alert,
+		}); err != nil {
+			sgs.logger.Error("Failed to publish alert to pipeline", zap.Error(err), zap.Uint("alert_id", alert.ID))
+			sgs.metricsCollector.RecordError(ctx, "publish_alert", err)
+			return processingRecord, fmt.Errorf("failed to publish alert: %w", err)
+		}
+		return processingRecord, nil
+	}
+
+	// 未配置处理流时在进程内按同一管道处理，重启会丢失处理中的告警
 	go func() {
-		processCtx := context.Background() // 使用新的context避免超时
-		if err := sgs.processAlertAsync(processCtx, processingRecord); err != nil {
-			sgs.logger.Error("Failed to process alert asynchronously", 
-				zap.Error(err), 
+		msg := &gateway.PipelineMessage{
+			RecordID:   processingRecord.ID,
+			Alert:      alert,
+			EnqueuedAt: processingRecord.ReceivedAt,
+		}
+		if stage, err := sgs.ProcessMessage(context.Background(), msg); err != nil {
+			sgs.logger.Error("Failed to process alert",
+				zap.Error(err),
+				zap.String("stage", string(stage)),
 				zap.String("record_id", processingRecord.ID))
 		}
 	}()
@@ -120,97 +141,85 @@ func (sgs *SmartGatewayService) ReceiveAlert(ctx context.Context, alert *model.A
 	return processingRecord, nil
 }
 
-// processAlertAsync 异步处理告警
-func (sgs *SmartGatewayService) processAlertAsync(ctx context.Context, record *gateway.AlertProcessingRecord) error {
-	// 丰富告警上下文
-	alertCtx, err := sgs.receiver.EnrichAlert(ctx, record.OriginalAlert)
-	if err != nil {
-		sgs.logger.Warn("Failed to enrich alert context", zap.Error(err), zap.String("record_id", record.ID))
-		// 创建基础上下文
-		alertCtx = &gateway.AlertContext{
-			Alert: record.OriginalAlert,
-		}
+// observeFlapping 记录告警状态变化，当告警处于抖动且需要暂停通知时返回 true。
+// 重新投递的消息已在首次投递时记录过状态，只读取当前抖动状态，避免重复计入采样与暂停次数。
+func (sgs *SmartGatewayService) observeFlapping(ctx context.Context, record *gateway.AlertProcessingRecord, alertCtx *gateway.AlertContext, redelivered bool) bool {
+	if sgs.flapDetector == nil {
+		return false
 	}
 
-	// 获取处理模式
-	processingMode := sgs.processor.GetProcessingMode(ctx, alertCtx)
-	record.ProcessingMode = processingMode
-
-	// 添加处理步骤
-	step := gateway.ProcessingStep{
-		Step:      "determine_processing_mode",
-		Status:    "completed",
-		StartTime: time.Now(),
-		EndTime:   &[]time.Time{time.Now()}[0],
-		Duration:  0,
-		Details: map[string]interface{}{
-			"mode": string(processingMode),
-		},
+	now := time.Now()
+	var state *gateway.FlapState
+	var err error
+	if redelivered {
+		state, err = sgs.flapDetector.GetState(ctx, gateway.AlertFingerprint(record.OriginalAlert))
 	}
-	record.ProcessingSteps = append(record.ProcessingSteps, step)
-
-	// 更新记录状态
-	record.Status = gateway.AlertStatusProcessing
-	record.UpdatedAt = time.Now()
-	if err := sgs.repository.Update(ctx, record); err != nil {
-		sgs.logger.Error("Failed to update processing record", zap.Error(err))
+	if err == nil && state == nil {
+		redelivered = false
+		state, err = sgs.flapDetector.Observe(ctx, record.OriginalAlert, now)
+	}
+	if err != nil {
+		sgs.logger.Warn("Failed to observe alert flapping", zap.Error(err), zap.String("record_id", record.ID))
+		sgs.metricsCollector.RecordError(ctx, "observe_flapping", err)
+		return false
 	}
 
-	// 根据处理模式选择策略
-	strategy, exists := sgs.strategies[processingMode]
-	if !exists {
-		// 默认使用直通模式
-		strategy = sgs.strategies[gateway.ModeDirectPassthrough]
-		if strategy == nil {
-			return fmt.Errorf("no processing strategy available")
-		}
+	alertCtx.Flapping = state.Flapping
+	alertCtx.FlapState = state
+	record.Metadata["fingerprint"] = state.Fingerprint
+	record.Metadata["percent_state_change"] = state.PercentStateChange
+	if state.StabilizedAt != nil && state.StabilizedAt.Equal(now) {
+		// 抖动结束后的首条通知携带稳定标记
+		record.Metadata["flapping_stabilized"] = true
+		record.Metadata["held_notifications"] = state.HeldNotifications
 	}
 
-	// 执行处理策略
-	processedRecord, err := strategy.Process(ctx, alertCtx)
-	if err != nil {
-		sgs.logger.Error("Failed to process alert with strategy", 
-			zap.Error(err), 
-			zap.String("mode", string(processingMode)))
-		
-		// 更新记录状态为失败
-		record.Status = gateway.AlertStatusFailed
-		record.ErrorMessage = err.Error()
-		record.UpdatedAt = time.Now()
-		sgs.repository.Update(ctx, record)
-		sgs.metricsCollector.RecordError(ctx, "process_alert", err)
-		return err
+	if !sgs.flapDetector.ShouldHold(state) {
+		return false
 	}
 
-	// 合并处理结果
-	if processedRecord != nil {
-		record.ProcessingSteps = append(record.ProcessingSteps, processedRecord.ProcessingSteps...)
-		record.Status = processedRecord.Status
-		record.ProcessedAt = processedRecord.ProcessedAt
-		record.RoutedAt = processedRecord.RoutedAt
-		if processedRecord.ErrorMessage != "" {
-			record.ErrorMessage = processedRecord.ErrorMessage
-		}
-		// 合并元数据
-		for k, v := range processedRecord.Metadata {
-			record.Metadata[k] = v
+	if !redelivered {
+		if err := sgs.flapDetector.RecordHeld(ctx, state.Fingerprint); err != nil {
+			sgs.logger.Warn("Failed to record held notification", zap.Error(err), zap.String("fingerprint", state.Fingerprint))
 		}
 	}
 
-	// 最终更新记录
+	record.ProcessingSteps = append(record.ProcessingSteps, gateway.ProcessingStep{
+		Step:      "flap_detection",
+		Status:    "completed",
+		StartTime: now,
+		EndTime:   &[]time.Time{time.Now()}[0],
+		Details: map[string]interface{}{
+			"flapping":             true,
+			"percent_state_change": state.PercentStateChange,
+		},
+	})
+	record.Status = gateway.AlertStatusSuppressed
+	record.Metadata["suppression_reason"] = "flapping"
 	record.UpdatedAt = time.Now()
 	if err := sgs.repository.Update(ctx, record); err != nil {
-		sgs.logger.Error("Failed to update final processing record", zap.Error(err))
+		sgs.logger.Error("Failed to update processing record", zap.Error(err))
 	}
-
-	// 记录处理完成指标
 	sgs.metricsCollector.RecordAlertProcessed(ctx, record)
 
-	return nil
+	return true
 }
 
 // RouteAlert 路由告警
 func (sgs *SmartGatewayService) RouteAlert(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.RoutingDecision, error) {
+	// 抖动中的告警暂停通知
+	if sgs.flapDetector != nil && sgs.flapDetector.ShouldHold(alertCtx.FlapState) {
+		return &gateway.RoutingDecision{
+			Suppressed:   true,
+			Reason:       "flapping",
+			DecisionTime: time.Now(),
+			Metadata: map[string]interface{}{
+				"fingerprint":          alertCtx.FlapState.Fingerprint,
+				"percent_state_change": alertCtx.FlapState.PercentStateChange,
+			},
+		}, nil
+	}
+
 	// 检查是否应该抑制
 	if sgs.featureToggle.IsEnabled(ctx, string(feature.FeatureAutoSuppression)) {
 		suppressed, reason, err := sgs.suppressor.ShouldSuppress(ctx, alertCtx)
@@ -18,7 +18,7 @@ type SmartGatewayImpl struct {
 	alertSuppressor  gateway.AlertSuppressor
 	alertConverger   gateway.AlertConverger
 	processingRepo   gateway.AlertProcessingRepository
-	featureToggle    feature.ToggleManager
+	featureToggle    *feature.ToggleManager
 	metricsCollector gateway.MetricsCollector
 }
 
@@ -30,7 +30,7 @@ func NewSmartGatewayImpl(
 	alertSuppressor gateway.AlertSuppressor,
 	alertConverger gateway.AlertConverger,
 	processingRepo gateway.AlertProcessingRepository,
-	featureToggle feature.ToggleManager,
+	featureToggle *feature.ToggleManager,
 	metricsCollector gateway.MetricsCollector,
 ) gateway.SmartGateway {
 	return &SmartGatewayImpl{
@@ -0,0 +1,287 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ProcessMessage 按 丰富→抑制→处理→收敛→路由 的顺序处理管道消息。
+// 返回错误时消息不会被确认，由消费组重新投递，因此各阶段需要可重入。
+func (sgs *SmartGatewayService) ProcessMessage(ctx context.Context, msg *gateway.PipelineMessage) (gateway.PipelineStage, error) {
+	if msg.Alert == nil {
+		return gateway.StageEnrichment, fmt.Errorf("pipeline message %s has no alert", msg.ID)
+	}
+
+	record, err := sgs.loadRecord(ctx, msg)
+	if err != nil {
+		return gateway.StageEnrichment, err
+	}
+	if isFinalStatus(record.Status) {
+		// 上次处理已完成但未确认，直接确认
+		return "", nil
+	}
+	record.Metadata["stream_id"] = msg.ID
+	record.Metadata["deliveries"] = msg.Deliveries
+
+	// 丰富告警上下文
+	start := time.Now()
+	alertCtx, err := sgs.receiver.EnrichAlert(ctx, msg.Alert)
+	if err != nil {
+		return gateway.StageEnrichment, fmt.Errorf("failed to enrich alert: %w", err)
+	}
+	record.Status = gateway.AlertStatusProcessing
+	appendStageStep(record, gateway.StageEnrichment, start, nil)
+
+	// 抑制：抖动检测与抑制规则
+	start = time.Now()
+	if msg.Stabilized {
+		// 抖动结束后补发的通知不是新的告警状态，不计入抖动采样
+		record.Metadata["flapping_stabilized"] = true
+		record.Metadata["held_notifications"] = msg.HeldNotifications
+	} else if held := sgs.observeFlapping(ctx, record, alertCtx, msg.Deliveries > 1); held {
+		return "", nil
+	}
+	suppressed, reason, err := sgs.suppressor.ShouldSuppress(ctx, alertCtx)
+	if err != nil {
+		return gateway.StageSuppression, fmt.Errorf("failed to check suppression: %w", err)
+	}
+	appendStageStep(record, gateway.StageSuppression, start, map[string]interface{}{
+		"suppressed": suppressed,
+		"reason":     reason,
+	})
+	if suppressed {
+		record.Status = gateway.AlertStatusSuppressed
+		record.Metadata["suppression_reason"] = reason
+		return sgs.completeRecord(ctx, record, gateway.StageSuppression)
+	}
+
+	// 处理：按处理模式执行处理策略
+	start = time.Now()
+	processed, err := sgs.processor.Process(ctx, alertCtx)
+	if err != nil {
+		return gateway.StageProcessing, fmt.Errorf("failed to process alert: %w", err)
+	}
+	record.ProcessingMode = processed.ProcessingMode
+	record.ProcessingSteps = append(record.ProcessingSteps, processed.ProcessingSteps...)
+	for k, v := range processed.Metadata {
+		record.Metadata[k] = v
+	}
+	details := map[string]interface{}{
+		"mode": string(processed.ProcessingMode),
+	}
+	if classification := classificationOf(processed); classification != nil {
+		// 分类结果单独保存，便于按类别检索与统计
+		record.Metadata["classification"] = classification
+		details["category"] = classification.Category
+		details["severity"] = classification.Severity
+		details["confidence"] = classification.Confidence
+	}
+	appendStageStep(record, gateway.StageProcessing, start, details)
+
+	// 收敛：匹配到路由树时按路由的分组设置收敛
+	start = time.Now()
+	grouping, err := sgs.router.Grouping(ctx, alertCtx)
+	if err != nil {
+		return gateway.StageConvergence, fmt.Errorf("failed to get route grouping: %w", err)
+	}
+	alertCtx.Grouping = grouping
+	converged, result, err := sgs.converger.ShouldConverge(ctx, alertCtx)
+	if err != nil {
+		return gateway.StageConvergence, fmt.Errorf("failed to check convergence: %w", err)
+	}
+	appendStageStep(record, gateway.StageConvergence, start, map[string]interface{}{
+		"converged": converged,
+	})
+	if result != nil && result.RootCause != nil {
+		record.Metadata["root_cause"] = result.RootCause
+		attachRootCause(record, msg.Alert, result.RootCause)
+	}
+	sgs.trackIncident(ctx, record, msg.Alert, result)
+	if converged && result != nil {
+		record.Status = gateway.AlertStatusConverged
+		record.Metadata["convergence_group"] = result.GroupID
+		record.Metadata["convergence_rule"] = result.ConvergenceRule
+		return sgs.completeRecord(ctx, record, gateway.StageConvergence)
+	}
+
+	// 路由
+	start = time.Now()
+	decision, err := sgs.router.Route(ctx, alertCtx)
+	if err != nil {
+		sgs.metricsCollector.RecordError(ctx, "route_alert", err)
+		return gateway.StageRouting, fmt.Errorf("failed to route alert: %w", err)
+	}
+	appendStageStep(record, gateway.StageRouting, start, map[string]interface{}{
+		"channel_ids":      decision.ChannelIDs,
+		"notify_group_ids": decision.NotifyGroupIDs,
+		"reason":           decision.Reason,
+	})
+	sgs.metricsCollector.RecordAlertRouted(ctx, decision)
+
+	if decision.Suppressed {
+		record.Status = gateway.AlertStatusSuppressed
+		record.Metadata["suppression_reason"] = decision.Reason
+	} else {
+		now := time.Now()
+		record.Status = gateway.AlertStatusRouted
+		record.RoutedAt = &now
+		record.Metadata["channel_ids"] = decision.ChannelIDs
+		if len(decision.NotifyGroupIDs) > 0 {
+			record.Metadata["notify_group_ids"] = decision.NotifyGroupIDs
+		}
+		if decision.Grouping != nil {
+			// 通知按路由的 group_wait 与 repeat_interval 发送
+			record.Metadata["grouping"] = decision.Grouping
+		}
+		sgs.triggerRunbooks(ctx, record, msg.Alert)
+	}
+	return sgs.completeRecord(ctx, record, gateway.StageRouting)
+}
+
+// trackIncident 将收敛分组归入事件，失败不影响告警处理
+func (sgs *SmartGatewayService) trackIncident(ctx context.Context, record *gateway.AlertProcessingRecord, alert *model.Alert, result *gateway.ConvergenceResult) {
+	if sgs.incidents == nil || result == nil {
+		return
+	}
+	if err := sgs.incidents.TrackConvergence(ctx, alert, result); err != nil {
+		sgs.logger.Warn("Failed to track incident for convergence group",
+			zap.String("record_id", record.ID),
+			zap.String("group_id", result.GroupID),
+			zap.Error(err))
+		sgs.metricsCollector.RecordError(ctx, "track_incident", err)
+	}
+}
+
+// triggerRunbooks 为路由的告警触发运行手册，失败不影响告警处理
+func (sgs *SmartGatewayService) triggerRunbooks(ctx context.Context, record *gateway.AlertProcessingRecord, alert *model.Alert) {
+	if sgs.runbooks == nil {
+		return
+	}
+	executionIDs, err := sgs.runbooks.TriggerRunbooks(ctx, alert)
+	if err != nil {
+		sgs.logger.Warn("Failed to trigger runbooks",
+			zap.String("record_id", record.ID),
+			zap.Uint("alert_id", alert.ID),
+			zap.Error(err))
+		sgs.metricsCollector.RecordError(ctx, "trigger_runbooks", err)
+	}
+	if len(executionIDs) > 0 {
+		record.Metadata["runbook_executions"] = executionIDs
+	}
+}
+
+// loadRecord 获取消息对应的处理记录，记录不存在时（如死信重放）重新创建
+func (sgs *SmartGatewayService) loadRecord(ctx context.Context, msg *gateway.PipelineMessage) (*gateway.AlertProcessingRecord, error) {
+	if msg.RecordID != "" {
+		record, err := sgs.repository.GetByID(ctx, msg.RecordID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get processing record: %w", err)
+		}
+		if record != nil {
+			record.OriginalAlert = msg.Alert
+			if record.Metadata == nil {
+				record.Metadata = make(map[string]interface{})
+			}
+			return record, nil
+		}
+	}
+
+	now := time.Now()
+	record := &gateway.AlertProcessingRecord{
+		ID:             uuid.New().String(),
+		AlertID:        msg.Alert.ID,
+		OriginalAlert:  msg.Alert,
+		ProcessingMode: gateway.ModeDirectPassthrough,
+		Status:         gateway.AlertStatusReceived,
+		ReceivedAt:     msg.EnqueuedAt,
+		Metadata:       make(map[string]interface{}),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := sgs.repository.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to create processing record: %w", err)
+	}
+	msg.RecordID = record.ID
+	return record, nil
+}
+
+// completeRecord 保存处理完成的记录
+func (sgs *SmartGatewayService) completeRecord(ctx context.Context, record *gateway.AlertProcessingRecord, stage gateway.PipelineStage) (gateway.PipelineStage, error) {
+	now := time.Now()
+	record.ProcessedAt = &now
+	record.UpdatedAt = now
+	if err := sgs.repository.Update(ctx, record); err != nil {
+		return stage, fmt.Errorf("failed to update processing record: %w", err)
+	}
+
+	sgs.metricsCollector.RecordAlertProcessed(ctx, record)
+	sgs.logger.Debug("Alert processed by pipeline",
+		zap.String("record_id", record.ID),
+		zap.String("status", string(record.Status)),
+		zap.String("stage", string(stage)))
+	return "", nil
+}
+
+// classificationOf 获取智能路由策略的分类结果，其他处理模式返回 nil
+func classificationOf(processed *gateway.AlertProcessingRecord) *gateway.Classification {
+	analysis, ok := processed.Metadata["ai_analysis"].(*gateway.AnalysisResult)
+	if !ok || analysis == nil {
+		return nil
+	}
+	source, _ := analysis.Metadata["classification_source"].(string)
+	return &gateway.Classification{
+		Category:     analysis.Category,
+		Severity:     analysis.Severity,
+		Confidence:   analysis.Confidence,
+		Source:       source,
+		ModelVersion: analysis.ModelVersion,
+	}
+}
+
+// attachRootCause 将拓扑关联推断的根因写入分析结果，处理模式未产生分析结果时单独创建。
+// 处理阶段在收敛之前执行，此时还没有关联结果，因此在收敛后补写。
+func attachRootCause(record *gateway.AlertProcessingRecord, alert *model.Alert, rootCause *gateway.RootCause) {
+	analysis, ok := record.Metadata["ai_analysis"].(*gateway.AnalysisResult)
+	if !ok || analysis == nil {
+		analysis = &gateway.AnalysisResult{
+			Severity:         alert.Level,
+			OriginalSeverity: alert.Level,
+		}
+		record.Metadata["ai_analysis"] = analysis
+	}
+	if analysis.Metadata == nil {
+		analysis.Metadata = make(map[string]interface{})
+	}
+	analysis.RootCause = rootCause.String()
+	analysis.Metadata["root_cause"] = rootCause
+}
+
+// appendStageStep 追加管道阶段处理步骤
+func appendStageStep(record *gateway.AlertProcessingRecord, stage gateway.PipelineStage, start time.Time, details map[string]interface{}) {
+	end := time.Now()
+	record.ProcessingSteps = append(record.ProcessingSteps, gateway.ProcessingStep{
+		Step:      string(stage),
+		Status:    "completed",
+		StartTime: start,
+		EndTime:   &end,
+		Duration:  end.Sub(start),
+		Details:   details,
+	})
+}
+
+// isFinalStatus 判断处理记录是否已处于终态
+func isFinalStatus(status gateway.AlertStatus) bool {
+	switch status {
+	case gateway.AlertStatusRouted, gateway.AlertStatusSuppressed, gateway.AlertStatusConverged:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,251 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/infrastructure/queue"
+	"alert_agent/internal/model"
+	"alert_agent/internal/observability/metrics"
+	"alert_agent/internal/pkg/feature"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryProcessingRepository 测试用内存处理记录仓储
+type memoryProcessingRepository struct {
+	gateway.AlertProcessingRepository
+	mu      sync.Mutex
+	records map[string]*gateway.AlertProcessingRecord
+}
+
+func newMemoryProcessingRepository() *memoryProcessingRepository {
+	return &memoryProcessingRepository{records: make(map[string]*gateway.AlertProcessingRecord)}
+}
+
+func (r *memoryProcessingRepository) Create(_ context.Context, record *gateway.AlertProcessingRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[record.ID] = record
+	return nil
+}
+
+func (r *memoryProcessingRepository) Update(_ context.Context, record *gateway.AlertProcessingRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[record.ID] = record
+	return nil
+}
+
+func (r *memoryProcessingRepository) GetByID(_ context.Context, id string) (*gateway.AlertProcessingRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.records[id], nil
+}
+
+// enableFeature 启用功能开关，去掉依赖与成熟度要求
+func enableFeature(t *testing.T, tm *feature.ToggleManager, name feature.FeatureName) {
+	config, err := tm.GetFeature(name)
+	require.NoError(t, err)
+	enabled := *config
+	enabled.State = feature.StateEnabled
+	enabled.Dependencies = nil
+	enabled.AIMaturityRequirement = nil
+	enabled.RolloutConfig = nil
+	require.NoError(t, tm.UpdateFeature(name, &enabled))
+}
+
+// newTestSmartGateway 创建不连接外部依赖的网关，classifier 为 nil 时使用关键词规则分类
+func newTestSmartGateway(t *testing.T, tm *feature.ToggleManager, classifier gateway.AlertClassifier, routing gateway.RoutingConfigService, correlator gateway.TopologyCorrelator) (*SmartGatewayService, *memoryProcessingRepository) {
+	repo := newMemoryProcessingRepository()
+	gatewayMetrics := metrics.NewGatewayMetrics(prometheus.NewRegistry())
+	toggles := NewFeatureToggleAdapter(tm)
+	logger := zap.NewNop()
+
+	sgs := NewSmartGatewayService(
+		NewAlertReceiverService(repo, gatewayMetrics, nil, logger),
+		NewAlertProcessorService(repo, toggles, gatewayMetrics, classifier, logger),
+		NewAlertRouterService(tm, gatewayMetrics, routing),
+		NewAlertSuppressorService(tm, gatewayMetrics),
+		NewAlertConvergerService(tm, gatewayMetrics, repo, nil, correlator),
+		repo,
+		toggles,
+		gatewayMetrics,
+		nil,
+		nil,
+		nil,
+		nil,
+		logger,
+	)
+	return sgs, repo
+}
+
+func TestSmartGateway_ProcessMessageRunsProcessingStage(t *testing.T) {
+	tm := feature.NewToggleManagerWithRegistry(zap.NewNop(), prometheus.NewRegistry())
+	enableFeature(t, tm, feature.FeatureSmartRouting)
+	sgs, repo := newTestSmartGateway(t, tm, nil, nil, nil)
+
+	ctx := context.Background()
+	msg := &gateway.PipelineMessage{
+		Alert: &model.Alert{ID: 1, Name: "HighCPU", Level: model.AlertLevelHigh, Status: model.AlertStatusNew},
+	}
+	stage, err := sgs.ProcessMessage(ctx, msg)
+	require.NoError(t, err)
+	assert.Empty(t, stage)
+
+	record := repo.records[msg.RecordID]
+	require.NotNil(t, record)
+	assert.Equal(t, gateway.ModeSmartRouting, record.ProcessingMode)
+	assert.Equal(t, gateway.AlertStatusRouted, record.Status)
+
+	var steps []string
+	for _, step := range record.ProcessingSteps {
+		steps = append(steps, step.Step)
+	}
+	assert.Equal(t, []string{"enrichment", "suppression", "smart_routing", "processing", "convergence", "routing"}, steps)
+}
+
+func TestSmartGateway_ClassifiesAlertsConsumedFromStream(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	stream := queue.NewRedisAlertStream(client)
+	ctx := context.Background()
+	require.NoError(t, stream.EnsureGroup(ctx))
+
+	tm := feature.NewToggleManagerWithRegistry(zap.NewNop(), prometheus.NewRegistry())
+	enableFeature(t, tm, feature.FeatureSmartRouting)
+	classifier := &stubClassifier{result: &gateway.Classification{
+		Category:     "database",
+		Severity:     model.AlertLevelCritical,
+		Confidence:   0.92,
+		Source:       gateway.ClassificationSourceModel,
+		ModelVersion: "v3",
+	}}
+	sgs, repo := newTestSmartGateway(t, tm, classifier, nil, nil)
+	sgs.stream = stream
+
+	received, err := sgs.ReceiveAlert(ctx, &model.Alert{
+		ID:      7,
+		RuleID:  1,
+		Name:    "SlowQueries",
+		Title:   "Slow queries on orders",
+		Content: "p99 latency above 2s",
+		Source:  "prometheus",
+		Level:   model.AlertLevelMedium,
+		Status:  model.AlertStatusNew,
+	})
+	require.NoError(t, err)
+
+	msgs, err := stream.Read(ctx, "worker-1", 10, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	_, err = sgs.ProcessMessage(ctx, msgs[0])
+	require.NoError(t, err)
+
+	// 消费的告警经过分类，分类结果保存在处理记录上
+	assert.Equal(t, 1, classifier.calls)
+	record := repo.records[received.ID]
+	require.NotNil(t, record)
+	classification, ok := record.Metadata["classification"].(*gateway.Classification)
+	require.True(t, ok)
+	assert.Equal(t, "database", classification.Category)
+	assert.Equal(t, model.AlertLevelCritical, classification.Severity)
+	assert.InDelta(t, 0.92, classification.Confidence, 0.001)
+	assert.Equal(t, gateway.ClassificationSourceModel, classification.Source)
+
+	analysis, ok := record.Metadata["ai_analysis"].(*gateway.AnalysisResult)
+	require.True(t, ok)
+	assert.Equal(t, model.AlertLevelMedium, analysis.OriginalSeverity)
+}
+
+func TestSmartGateway_ProcessMessageRecordsTopologyRootCause(t *testing.T) {
+	tm := feature.NewToggleManagerWithRegistry(zap.NewNop(), prometheus.NewRegistry())
+	enableFeature(t, tm, feature.FeatureBasicConvergence)
+	enableFeature(t, tm, feature.FeatureSmartRouting)
+	correlator := NewTopologyCorrelatorService(&gateway.TopologyDefinition{
+		Services: []gateway.ServiceDependency{{Name: "api", DependsOn: []string{"db"}}},
+	}, gateway.DefaultTopologyConfig(), zap.NewNop())
+	sgs, repo := newTestSmartGateway(t, tm, nil, nil, correlator)
+	ctx := context.Background()
+
+	process := func(id uint, name, service string) *gateway.AlertProcessingRecord {
+		msg := &gateway.PipelineMessage{Alert: &model.Alert{
+			ID:     id,
+			Name:   name,
+			Level:  model.AlertLevelHigh,
+			Status: model.AlertStatusNew,
+			Labels: `{"service":"` + service + `"}`,
+		}}
+		_, err := sgs.ProcessMessage(ctx, msg)
+		require.NoError(t, err)
+		return repo.records[msg.RecordID]
+	}
+
+	process(1, "DBDown", "db")
+	record := process(2, "APIErrors", "api")
+
+	// 下游告警的分析结果标记上游数据库为根因
+	assert.Equal(t, gateway.AlertStatusConverged, record.Status)
+	analysis, ok := record.Metadata["ai_analysis"].(*gateway.AnalysisResult)
+	require.True(t, ok)
+	assert.Equal(t, "service db (DBDown)", analysis.RootCause)
+	assert.Equal(t, "general", analysis.Category)
+}
+
+func TestSmartGateway_ProcessMessageRoutesToNotifyGroups(t *testing.T) {
+	tm := feature.NewToggleManagerWithRegistry(zap.NewNop(), prometheus.NewRegistry())
+	enableFeature(t, tm, feature.FeatureBasicConvergence)
+	routing := NewRoutingConfigService(&flakyRoutingRepository{config: &gateway.RoutingConfig{
+		Version: 1,
+		Route: &gateway.Route{
+			Receiver:      "oncall",
+			GroupBy:       []string{"cluster"},
+			GroupWait:     30,
+			GroupInterval: 600,
+		},
+		Receivers: []gateway.Receiver{{Name: "oncall", NotifyGroupIDs: []uint{3}}},
+	}}, zap.NewNop())
+	sgs, repo := newTestSmartGateway(t, tm, nil, routing, nil)
+	ctx := context.Background()
+
+	process := func(id uint, name string) *gateway.AlertProcessingRecord {
+		msg := &gateway.PipelineMessage{Alert: &model.Alert{
+			ID:     id,
+			Name:   name,
+			Level:  model.AlertLevelHigh,
+			Status: model.AlertStatusNew,
+			Labels: `{"cluster":"prod-1"}`,
+		}}
+		_, err := sgs.ProcessMessage(ctx, msg)
+		require.NoError(t, err)
+		return repo.records[msg.RecordID]
+	}
+
+	// 只配置通知组的接收者也能路由
+	record := process(1, "HighCPU")
+	assert.Equal(t, gateway.AlertStatusRouted, record.Status)
+	assert.Equal(t, []uint{3}, record.Metadata["notify_group_ids"])
+	grouping, ok := record.Metadata["grouping"].(*gateway.RouteGrouping)
+	require.True(t, ok)
+	assert.Equal(t, int64(30), grouping.GroupWait)
+
+	decision, err := sgs.router.Route(ctx, &gateway.AlertContext{Alert: record.OriginalAlert})
+	require.NoError(t, err)
+	assert.Empty(t, decision.ChannelIDs)
+	assert.Equal(t, 30*time.Second, decision.Delay)
+	assert.NoError(t, sgs.router.ValidateRouting(ctx, decision))
+
+	// 按路由的 group_by 收敛，同一集群的不同告警归为一组
+	record = process(2, "HighMemory")
+	assert.Equal(t, gateway.AlertStatusConverged, record.Status)
+	assert.Equal(t, "route:oncall:cluster=prod-1", record.Metadata["convergence_group"])
+}
@@ -0,0 +1,323 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadTopologyDefinition 从 YAML 文件加载服务依赖拓扑
+func LoadTopologyDefinition(path string) (*gateway.TopologyDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology file: %w", err)
+	}
+	var definition gateway.TopologyDefinition
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse topology file: %w", err)
+	}
+	for i, service := range definition.Services {
+		if service.Name == "" {
+			return nil, fmt.Errorf("services[%d]: name is required", i)
+		}
+	}
+	return &definition, nil
+}
+
+// ServiceGraph 服务依赖图，边从服务指向其上游依赖
+type ServiceGraph struct {
+	upstreams map[string]map[string]struct{}
+}
+
+// NewServiceGraph 根据拓扑定义创建依赖图
+func NewServiceGraph(definition *gateway.TopologyDefinition) *ServiceGraph {
+	g := &ServiceGraph{upstreams: make(map[string]map[string]struct{})}
+	if definition != nil {
+		for _, service := range definition.Services {
+			g.addService(service.Name)
+			for _, upstream := range service.DependsOn {
+				g.AddDependency(service.Name, upstream)
+			}
+		}
+	}
+	return g
+}
+
+func (g *ServiceGraph) addService(service string) {
+	if _, ok := g.upstreams[service]; !ok {
+		g.upstreams[service] = make(map[string]struct{})
+	}
+}
+
+// AddDependency 添加依赖关系，返回是否为新增的边
+func (g *ServiceGraph) AddDependency(service, upstream string) bool {
+	if service == "" || upstream == "" || service == upstream {
+		return false
+	}
+	g.addService(service)
+	g.addService(upstream)
+	if _, ok := g.upstreams[service][upstream]; ok {
+		return false
+	}
+	g.upstreams[service][upstream] = struct{}{}
+	return true
+}
+
+// DependsOn 判断 service 是否直接或间接依赖 upstream
+func (g *ServiceGraph) DependsOn(service, upstream string) bool {
+	visited := map[string]bool{service: true}
+	queue := []string{service}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for next := range g.upstreams[current] {
+			if next == upstream {
+				return true
+			}
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// Related 判断两个服务是否相同或存在依赖路径
+func (g *ServiceGraph) Related(a, b string) bool {
+	return a == b || g.DependsOn(a, b) || g.DependsOn(b, a)
+}
+
+// Definition 导出为拓扑定义
+func (g *ServiceGraph) Definition() *gateway.TopologyDefinition {
+	definition := &gateway.TopologyDefinition{Services: make([]gateway.ServiceDependency, 0, len(g.upstreams))}
+	for service, upstreams := range g.upstreams {
+		dependsOn := make([]string, 0, len(upstreams))
+		for upstream := range upstreams {
+			dependsOn = append(dependsOn, upstream)
+		}
+		sort.Strings(dependsOn)
+		definition.Services = append(definition.Services, gateway.ServiceDependency{Name: service, DependsOn: dependsOn})
+	}
+	sort.Slice(definition.Services, func(i, j int) bool {
+		return definition.Services[i].Name < definition.Services[j].Name
+	})
+	return definition
+}
+
+// topologyIncident 时间窗口内拓扑相关的告警集合
+type topologyIncident struct {
+	id        string
+	alerts    map[string][]*model.Alert // 服务 -> 告警
+	count     int                       // 保留的告警总数
+	firstSeen map[string]time.Time      // 服务首次告警时间
+	lastSeen  time.Time
+}
+
+// add 记录服务的告警，达到上限后只保留每个服务的首个告警用于推断根因
+func (i *topologyIncident) add(service string, alert *model.Alert, limit int) {
+	if len(i.alerts[service]) > 0 && limit > 0 && i.count >= limit {
+		return
+	}
+	i.alerts[service] = append(i.alerts[service], alert)
+	i.count++
+}
+
+// TopologyCorrelatorService 基于服务依赖拓扑的告警关联实现
+type TopologyCorrelatorService struct {
+	config    gateway.TopologyConfig
+	logger    *zap.Logger
+	now       func() time.Time
+	mu        sync.Mutex
+	graph     *ServiceGraph
+	incidents []*topologyIncident
+}
+
+// NewTopologyCorrelatorService 创建拓扑关联服务
+func NewTopologyCorrelatorService(definition *gateway.TopologyDefinition, config gateway.TopologyConfig, logger *zap.Logger) *TopologyCorrelatorService {
+	return &TopologyCorrelatorService{
+		config: config,
+		logger: logger,
+		now:    time.Now,
+		graph:  NewServiceGraph(definition),
+	}
+}
+
+// Topology 获取当前服务依赖拓扑
+func (tcs *TopologyCorrelatorService) Topology() *gateway.TopologyDefinition {
+	tcs.mu.Lock()
+	defer tcs.mu.Unlock()
+	return tcs.graph.Definition()
+}
+
+// Correlate 将告警并入拓扑相关的事件并推断根因。
+// 告警所在服务不是根因时视为已收敛；新的根因告警不收敛，以便携带根因继续通知。
+func (tcs *TopologyCorrelatorService) Correlate(ctx context.Context, alertCtx *gateway.AlertContext) (*gateway.ConvergenceResult, error) {
+	alert := alertCtx.Alert
+	labels := gateway.ParseAlertLabels(alert)
+	service := labels[tcs.config.ServiceLabel]
+	// 恢复通知不代表服务仍在故障，不参与关联
+	if service == "" || alert.Status == model.AlertStatusResolved {
+		return nil, nil
+	}
+
+	tcs.mu.Lock()
+	defer tcs.mu.Unlock()
+
+	// 从告警标签学习依赖关系
+	tcs.graph.addService(service)
+	for _, upstream := range strings.Split(labels[tcs.config.UpstreamLabel], ",") {
+		if tcs.graph.AddDependency(service, strings.TrimSpace(upstream)) {
+			tcs.logger.Debug("Learned service dependency from alert labels",
+				zap.String("service", service),
+				zap.String("upstream", strings.TrimSpace(upstream)))
+		}
+	}
+
+	now := tcs.now()
+	incident := tcs.attach(service, now)
+	repeated := len(incident.alerts[service]) > 0
+	incident.add(service, alert, tcs.config.MaxAlerts)
+	if _, ok := incident.firstSeen[service]; !ok {
+		incident.firstSeen[service] = now
+	}
+	incident.lastSeen = now
+
+	if len(incident.alerts) < 2 {
+		return nil, nil
+	}
+
+	rootCause := tcs.rootCause(incident)
+	rootAlerts := incident.alerts[rootCause.Service]
+	similar := make([]*model.Alert, 0)
+	for _, alerts := range incident.alerts {
+		for _, a := range alerts {
+			if a != rootAlerts[0] {
+				similar = append(similar, a)
+			}
+		}
+	}
+
+	// 告警服务不是根因，或根因服务此前已告警时收敛
+	converged := service != rootCause.Service || repeated
+	return &gateway.ConvergenceResult{
+		Converged:       converged,
+		GroupID:         "topology:" + incident.id,
+		Representative:  rootAlerts[0],
+		SimilarAlerts:   similar,
+		ConvergenceRule: fmt.Sprintf("Correlated %d services by dependency topology, probable root cause %s", len(incident.alerts), rootCause.Service),
+		RootCause:       rootCause,
+		Metadata: map[string]interface{}{
+			"algorithm": "topology",
+			"services":  len(incident.alerts),
+			"window":    tcs.config.Window.String(),
+		},
+	}, nil
+}
+
+// attach 查找与服务拓扑相关的活跃事件，多个事件同时相关时合并
+func (tcs *TopologyCorrelatorService) attach(service string, now time.Time) *topologyIncident {
+	var matched *topologyIncident
+	active := tcs.incidents[:0]
+	for _, incident := range tcs.incidents {
+		if now.Sub(incident.lastSeen) > tcs.config.Window {
+			continue
+		}
+		if !tcs.relatedTo(incident, service) {
+			active = append(active, incident)
+			continue
+		}
+		if matched == nil {
+			matched = incident
+			active = append(active, incident)
+			continue
+		}
+		// 合并到先创建的事件
+		for s, alerts := range incident.alerts {
+			for _, a := range alerts {
+				matched.add(s, a, tcs.config.MaxAlerts)
+			}
+			if first, ok := matched.firstSeen[s]; !ok || incident.firstSeen[s].Before(first) {
+				matched.firstSeen[s] = incident.firstSeen[s]
+			}
+		}
+	}
+	tcs.incidents = active
+
+	if matched == nil {
+		matched = &topologyIncident{
+			id:        uuid.New().String(),
+			alerts:    make(map[string][]*model.Alert),
+			firstSeen: make(map[string]time.Time),
+		}
+		tcs.incidents = append(tcs.incidents, matched)
+	}
+	return matched
+}
+
+func (tcs *TopologyCorrelatorService) relatedTo(incident *topologyIncident, service string) bool {
+	for s := range incident.alerts {
+		if tcs.graph.Related(s, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// rootCause 选择最上游的告警服务：没有其他告警服务位于其上游，且影响的下游告警服务最多
+func (tcs *TopologyCorrelatorService) rootCause(incident *topologyIncident) *gateway.RootCause {
+	services := make([]string, 0, len(incident.alerts))
+	for s := range incident.alerts {
+		services = append(services, s)
+	}
+	sort.Strings(services)
+
+	var best string
+	var bestAffected []string
+	for _, candidate := range services {
+		upstreamFailing := false
+		var affected []string
+		for _, other := range services {
+			if other == candidate {
+				continue
+			}
+			if tcs.graph.DependsOn(candidate, other) && !tcs.graph.DependsOn(other, candidate) {
+				upstreamFailing = true
+				break
+			}
+			if tcs.graph.DependsOn(other, candidate) {
+				affected = append(affected, other)
+			}
+		}
+		if upstreamFailing {
+			continue
+		}
+		if best == "" || len(affected) > len(bestAffected) ||
+			(len(affected) == len(bestAffected) && incident.firstSeen[candidate].Before(incident.firstSeen[best])) {
+			best, bestAffected = candidate, affected
+		}
+	}
+
+	if best == "" {
+		best = services[0]
+	}
+	alert := incident.alerts[best][0]
+	return &gateway.RootCause{
+		Service:          best,
+		AlertID:          alert.ID,
+		AlertName:        alert.Name,
+		AffectedServices: bestAffected,
+		Confidence:       float64(len(bestAffected)) / float64(len(services)-1),
+	}
+}
@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/feature"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func serviceAlert(name string, labels map[string]string) *gateway.AlertContext {
+	data, _ := json.Marshal(labels)
+	return &gateway.AlertContext{Alert: &model.Alert{Name: name, Labels: string(data)}}
+}
+
+func TestTopologyCorrelator_DatabaseOutage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topology.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+services:
+  - name: checkout
+    depends_on: [payment]
+  - name: payment
+    depends_on: [mysql]
+  - name: search
+`), 0o644))
+	definition, err := LoadTopologyDefinition(path)
+	require.NoError(t, err)
+
+	correlator := NewTopologyCorrelatorService(definition, gateway.DefaultTopologyConfig(), zap.NewNop())
+	now := time.Now()
+	correlator.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// 第一个告警没有可关联的服务
+	result, err := correlator.Correlate(ctx, serviceAlert("HighErrorRate", map[string]string{"service": "checkout"}))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	// 无依赖关系的服务不关联
+	result, err = correlator.Correlate(ctx, serviceAlert("SlowQuery", map[string]string{"service": "search"}))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	// payment 是 checkout 的上游，成为新的根因，不收敛
+	result, err = correlator.Correlate(ctx, serviceAlert("PaymentTimeout", map[string]string{"service": "payment"}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Converged)
+	assert.Equal(t, "payment", result.RootCause.Service)
+
+	// 数据库告警到达后根因移动到 mysql
+	now = now.Add(time.Minute)
+	result, err = correlator.Correlate(ctx, serviceAlert("MySQLDown", map[string]string{"service": "mysql"}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Converged)
+	assert.Equal(t, "mysql", result.RootCause.Service)
+	assert.Equal(t, "MySQLDown", result.RootCause.AlertName)
+	assert.ElementsMatch(t, []string{"checkout", "payment"}, result.RootCause.AffectedServices)
+	assert.InDelta(t, 1.0, result.RootCause.Confidence, 1e-9)
+	assert.Len(t, result.SimilarAlerts, 2)
+
+	// 下游的后续告警收敛到同一事件
+	now = now.Add(time.Minute)
+	next, err := correlator.Correlate(ctx, serviceAlert("HighLatency", map[string]string{"service": "checkout"}))
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.True(t, next.Converged)
+	assert.Equal(t, result.GroupID, next.GroupID)
+	assert.Equal(t, "mysql", next.RootCause.Service)
+
+	// 超出时间窗口后开始新的事件
+	now = now.Add(time.Hour)
+	result, err = correlator.Correlate(ctx, serviceAlert("HighLatency", map[string]string{"service": "checkout"}))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestTopologyCorrelator_LearnsFromLabels(t *testing.T) {
+	correlator := NewTopologyCorrelatorService(nil, gateway.DefaultTopologyConfig(), zap.NewNop())
+	ctx := context.Background()
+
+	_, err := correlator.Correlate(ctx, serviceAlert("RedisDown", map[string]string{"service": "redis"}))
+	require.NoError(t, err)
+
+	alertCtx := serviceAlert("SessionErrors", map[string]string{"service": "web", "upstream": "redis, auth"})
+	result, err := correlator.Correlate(ctx, alertCtx)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "redis", result.RootCause.Service)
+
+	topology := correlator.Topology()
+	require.Len(t, topology.Services, 3)
+	assert.Equal(t, gateway.ServiceDependency{Name: "web", DependsOn: []string{"auth", "redis"}}, topology.Services[2])
+}
+
+func TestTopologyCorrelator_BoundsIncidentAndSkipsResolved(t *testing.T) {
+	config := gateway.DefaultTopologyConfig()
+	config.MaxAlerts = 3
+	correlator := NewTopologyCorrelatorService(&gateway.TopologyDefinition{
+		Services: []gateway.ServiceDependency{{Name: "api", DependsOn: []string{"db"}}},
+	}, config, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := correlator.Correlate(ctx, serviceAlert("DBDown", map[string]string{"service": "db"}))
+	require.NoError(t, err)
+
+	// 恢复通知不加入事件
+	resolved := serviceAlert("APIErrors", map[string]string{"service": "api"})
+	resolved.Alert.Status = model.AlertStatusResolved
+	result, err := correlator.Correlate(ctx, resolved)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	for i := 0; i < 5; i++ {
+		result, err = correlator.Correlate(ctx, serviceAlert("APIErrors", map[string]string{"service": "api"}))
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.True(t, result.Converged)
+	}
+	// 达到上限后不再保留新告警
+	assert.Len(t, result.SimilarAlerts, 2)
+
+	// 根因服务的重复告警仍然收敛
+	result, err = correlator.Correlate(ctx, serviceAlert("DBDown", map[string]string{"service": "db"}))
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Converged)
+	assert.Equal(t, "db", result.RootCause.Service)
+}
+
+func TestAlertConverger_AttachesRootCause(t *testing.T) {
+	correlator := NewTopologyCorrelatorService(&gateway.TopologyDefinition{
+		Services: []gateway.ServiceDependency{{Name: "api", DependsOn: []string{"db"}}},
+	}, gateway.DefaultTopologyConfig(), zap.NewNop())
+	converger := NewAlertConvergerService(newTestToggleManager(t), nil, nil, nil, correlator)
+	ctx := context.Background()
+
+	converged, _, err := converger.ShouldConverge(ctx, serviceAlert("DBDown", map[string]string{"service": "db"}))
+	require.NoError(t, err)
+	assert.False(t, converged)
+
+	alertCtx := serviceAlert("APIErrors", map[string]string{"service": "api"})
+	converged, result, err := converger.ShouldConverge(ctx, alertCtx)
+	require.NoError(t, err)
+	assert.True(t, converged)
+	assert.Equal(t, "db", result.RootCause.Service)
+	assert.Equal(t, "db", alertCtx.RootCause.Service)
+
+	analysis, err := NewSmartRoutingStrategy(nil, nil, nil, zap.NewNop()).performIntelligentAnalysis(ctx, alertCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "service db (DBDown)", analysis.RootCause)
+}
+
+// newTestToggleManager 创建启用基础收敛的功能开关
+func newTestToggleManager(t *testing.T) *feature.ToggleManager {
+	tm := feature.NewToggleManagerWithRegistry(zap.NewNop(), prometheus.NewRegistry())
+	config, err := tm.GetFeature(feature.FeatureBasicConvergence)
+	require.NoError(t, err)
+	enabled := *config
+	enabled.State = feature.StateEnabled
+	require.NoError(t, tm.UpdateFeature(feature.FeatureBasicConvergence, &enabled))
+	return tm
+}
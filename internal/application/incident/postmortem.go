@@ -0,0 +1,509 @@
+package incident
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// 知识库条目的来源与分类
+const (
+	postmortemKnowledgeSource   = "postmortem"
+	postmortemKnowledgeCategory = "故障复盘"
+)
+
+// DefaultPostmortemTemplate 内置复盘报告模板
+const DefaultPostmortemTemplate = `# 故障复盘：{{.Title}}
+
+> 本文档由系统根据事件时间线自动生成（{{fmtTime .GeneratedAt}}），请补充完善后归档。
+
+## 概要
+
+| 项目 | 内容 |
+| --- | --- |
+{{- if .Incident}}
+| 事件ID | {{.Incident.ID}} |
+| 严重程度 | {{.Incident.Severity}} |
+| 当前状态 | {{.Incident.Status}} |
+| 处理人 | {{or .Incident.Assignee "待定"}} |
+{{- end}}
+| 开始时间 | {{fmtTime .StartedAt}} |
+| 确认时间 | {{fmtTime .AcknowledgedAt}} |
+| 恢复时间 | {{fmtTime .ResolvedAt}} |
+| MTTA | {{fmtDuration .MTTA}} |
+| MTTR | {{fmtDuration .MTTR}} |
+| 关联告警 | {{len .Alerts}} 条 |
+
+## 影响范围
+
+{{range .ImpactedServices}}- {{.}}
+{{else}}- 待补充
+{{end}}
+## 时间线
+
+| 时间 | 类型 | 操作人 | 描述 |
+| --- | --- | --- | --- |
+{{range .Timeline}}| {{fmtTime .Time}} | {{.Kind}} | {{cell (or .Actor "-")}} | {{cell .Message}} |
+{{end}}
+## 关联告警
+
+{{range .Alerts}}- #{{.ID}} [{{.Level}}] {{.Title}}（来源 {{.Source}}，状态 {{.Status}}）
+{{else}}- 无
+{{end}}
+## 根因分析
+
+{{if and .Incident .Incident.RootCause}}初步定位：{{.Incident.RootCause}}
+
+{{end}}{{range .Findings}}### 告警 #{{.AlertID}} {{.AnalysisType}} 分析（置信度 {{printf "%.0f%%" (percent .Confidence)}}）
+
+{{if .RootCause}}**根因**：{{.RootCause}}
+
+{{end}}{{if .Impact}}**影响**：{{.Impact}}
+
+{{end}}{{if .Recommendations}}**建议**：
+{{range .Recommendations}}- {{.}}
+{{end}}
+{{end}}{{else}}暂无 AI 分析结果，待补充。
+
+{{end}}## 改进措施
+
+| 措施 | 负责人 | 截止日期 | 状态 |
+| --- | --- | --- | --- |
+{{range .ActionItems}}| {{cell .}} | 待定 | 待定 | 待开始 |
+{{else}}| 待补充 | 待定 | 待定 | 待开始 |
+{{end}}
+## 经验教训
+
+- 做得好的：待补充
+- 需要改进的：待补充
+`
+
+// PostmortemConfig 复盘报告生成配置
+type PostmortemConfig struct {
+	// Template 自定义 Markdown 模板，为空使用内置模板
+	Template string
+	// ServiceLabels 标识受影响服务的标签，按顺序取第一个存在的
+	ServiceLabels []string
+}
+
+// DefaultPostmortemConfig 默认复盘报告配置
+func DefaultPostmortemConfig() PostmortemConfig {
+	return PostmortemConfig{
+		ServiceLabels: []string{"service", "app", "application"},
+	}
+}
+
+// PostmortemGenerator 复盘报告生成器
+type PostmortemGenerator struct {
+	incidents     incident.Repository
+	repo          incident.PostmortemRepository
+	analyses      incident.AnalysisReader
+	template      *template.Template
+	serviceLabels []string
+	logger        *zap.Logger
+	now           func() time.Time
+}
+
+// NewPostmortemGenerator 创建复盘报告生成器，模板无法解析时返回错误
+func NewPostmortemGenerator(
+	incidents incident.Repository,
+	repo incident.PostmortemRepository,
+	analyses incident.AnalysisReader,
+	config PostmortemConfig,
+	logger *zap.Logger,
+) (*PostmortemGenerator, error) {
+	text := config.Template
+	if strings.TrimSpace(text) == "" {
+		text = DefaultPostmortemTemplate
+	}
+	tmpl, err := parsePostmortemTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.ServiceLabels) == 0 {
+		config.ServiceLabels = DefaultPostmortemConfig().ServiceLabels
+	}
+	return &PostmortemGenerator{
+		incidents:     incidents,
+		repo:          repo,
+		analyses:      analyses,
+		template:      tmpl,
+		serviceLabels: config.ServiceLabels,
+		logger:        logger,
+		now:           time.Now,
+	}, nil
+}
+
+// GeneratePostmortem 根据事件或告警集合生成 Markdown 复盘草稿
+func (g *PostmortemGenerator) GeneratePostmortem(ctx context.Context, req *incident.PostmortemRequest) (*incident.Postmortem, error) {
+	if req.IncidentID == "" && len(req.AlertIDs) == 0 {
+		return nil, errors.NewValidationError("INVALID_REQUEST", "Either incident_id or alert_ids is required")
+	}
+
+	tmpl := g.template
+	if strings.TrimSpace(req.Template) != "" {
+		custom, err := parsePostmortemTemplate(req.Template)
+		if err != nil {
+			return nil, errors.NewValidationError("INVALID_TEMPLATE", err.Error())
+		}
+		tmpl = custom
+	}
+
+	data, err := g.collect(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, errors.NewValidationError("INVALID_TEMPLATE", fmt.Sprintf("failed to render postmortem: %v", err))
+	}
+
+	pm := &incident.Postmortem{
+		Title:            data.Title,
+		ImpactedServices: data.ImpactedServices,
+		MTTA:             data.MTTA,
+		MTTR:             data.MTTR,
+		Markdown:         buf.String(),
+		GeneratedAt:      data.GeneratedAt,
+	}
+	if data.Incident != nil {
+		pm.IncidentID = data.Incident.ID
+	}
+	for _, alert := range data.Alerts {
+		pm.AlertIDs = append(pm.AlertIDs, alert.ID)
+	}
+
+	if req.SaveToKnowledge {
+		if err := g.saveKnowledge(ctx, pm, data); err != nil {
+			return nil, err
+		}
+	}
+	return pm, nil
+}
+
+// collect 汇总事件、告警、通知与分析结果
+func (g *PostmortemGenerator) collect(ctx context.Context, req *incident.PostmortemRequest) (*incident.PostmortemData, error) {
+	data := &incident.PostmortemData{
+		Title:       req.Title,
+		GeneratedAt: g.now(),
+		GeneratedBy: req.Actor,
+	}
+
+	alertIDs := append([]uint(nil), req.AlertIDs...)
+	var entries []*incident.TimelineEntry
+	if req.IncidentID != "" {
+		inc, err := g.incidents.GetByID(ctx, req.IncidentID)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to get incident", err)
+		}
+		if inc == nil {
+			return nil, errors.NewNotFoundError(fmt.Sprintf("incident %s", req.IncidentID))
+		}
+		linked, err := g.incidents.ListAlertIDs(ctx, inc.ID)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to list incident alerts", err)
+		}
+		if entries, err = g.incidents.ListTimeline(ctx, inc.ID); err != nil {
+			return nil, errors.NewInternalError("failed to list incident timeline", err)
+		}
+		inc.AlertIDs = linked
+		data.Incident = inc
+		alertIDs = append(alertIDs, linked...)
+	}
+
+	alerts, err := g.repo.ListAlerts(ctx, uniqueIDs(alertIDs))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list alerts", err)
+	}
+	if data.Incident == nil && len(alerts) == 0 {
+		return nil, errors.NewNotFoundError("alerts")
+	}
+	sort.SliceStable(alerts, func(i, j int) bool { return alerts[i].CreatedAt.Before(alerts[j].CreatedAt) })
+	data.Alerts = alerts
+
+	if data.Title == "" {
+		switch {
+		case data.Incident != nil:
+			data.Title = data.Incident.Title
+		default:
+			data.Title = alerts[0].Title
+		}
+	}
+
+	var records []*model.NotifyRecord
+	if len(alerts) > 0 {
+		ids := make([]uint, 0, len(alerts))
+		for _, alert := range alerts {
+			ids = append(ids, alert.ID)
+		}
+		if records, err = g.repo.ListNotifyRecords(ctx, ids); err != nil {
+			return nil, errors.NewInternalError("failed to list notify records", err)
+		}
+	}
+
+	g.fillDurations(data)
+	data.ImpactedServices = g.impactedServices(alerts)
+	data.Timeline = buildTimeline(alerts, records, entries)
+	data.Findings, data.ActionItems = g.findings(ctx, alerts)
+	return data, nil
+}
+
+// fillDurations 计算开始、确认与恢复时间；事件优先使用事件状态时间，否则取告警处理时间
+func (g *PostmortemGenerator) fillDurations(data *incident.PostmortemData) {
+	if inc := data.Incident; inc != nil {
+		data.StartedAt = inc.CreatedAt
+		data.AcknowledgedAt = inc.AcknowledgedAt
+		data.ResolvedAt = inc.ResolvedAt
+	}
+	for _, alert := range data.Alerts {
+		if data.StartedAt.IsZero() || alert.CreatedAt.Before(data.StartedAt) {
+			data.StartedAt = alert.CreatedAt
+		}
+	}
+
+	if data.Incident == nil {
+		allResolved := len(data.Alerts) > 0
+		var lastHandled *time.Time
+		for _, alert := range data.Alerts {
+			if alert.Status != model.AlertStatusResolved || alert.HandleTime == nil {
+				allResolved = false
+			}
+			if alert.HandleTime == nil || alert.Status == model.AlertStatusNew {
+				continue
+			}
+			if data.AcknowledgedAt == nil || alert.HandleTime.Before(*data.AcknowledgedAt) {
+				data.AcknowledgedAt = alert.HandleTime
+			}
+			if lastHandled == nil || alert.HandleTime.After(*lastHandled) {
+				lastHandled = alert.HandleTime
+			}
+		}
+		if allResolved {
+			data.ResolvedAt = lastHandled
+		}
+	}
+
+	if data.AcknowledgedAt != nil && data.AcknowledgedAt.After(data.StartedAt) {
+		data.MTTA = data.AcknowledgedAt.Sub(data.StartedAt)
+	}
+	if data.ResolvedAt != nil && data.ResolvedAt.After(data.StartedAt) {
+		data.MTTR = data.ResolvedAt.Sub(data.StartedAt)
+	}
+}
+
+// impactedServices 从告警标签中提取受影响服务，没有服务标签时使用告警来源
+func (g *PostmortemGenerator) impactedServices(alerts []*model.Alert) []string {
+	seen := make(map[string]bool)
+	services := []string{}
+	for _, alert := range alerts {
+		labels := gateway.ParseAlertLabels(alert)
+		service := ""
+		for _, name := range g.serviceLabels {
+			if labels[name] != "" {
+				service = labels[name]
+				break
+			}
+		}
+		if service == "" {
+			service = alert.Source
+		}
+		if service != "" && !seen[service] {
+			seen[service] = true
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// findings 读取已保存的 AI 分析结论，并将去重后的建议作为改进措施占位
+func (g *PostmortemGenerator) findings(ctx context.Context, alerts []*model.Alert) ([]*incident.PostmortemFinding, []string) {
+	findings := []*incident.PostmortemFinding{}
+	actions := []string{}
+	seen := make(map[string]bool)
+	if g.analyses == nil {
+		return findings, actions
+	}
+
+	for _, alert := range alerts {
+		results, err := g.analyses.GetAnalysisResultsByAlertID(ctx, alert.ID)
+		if err != nil {
+			g.logger.Warn("failed to load analysis results for postmortem",
+				zap.Uint("alert_id", alert.ID), zap.Error(err))
+			continue
+		}
+		for _, result := range results {
+			if result == nil || (result.RootCause == "" && result.Impact == "" && len(result.Recommendations) == 0) {
+				continue
+			}
+			findings = append(findings, &incident.PostmortemFinding{
+				AlertID:         alert.ID,
+				AnalysisType:    result.AnalysisType,
+				RootCause:       result.RootCause,
+				Impact:          result.Impact,
+				Recommendations: result.Recommendations,
+				Confidence:      result.Confidence,
+				CreatedAt:       result.CreatedAt,
+			})
+			for _, rec := range result.Recommendations {
+				rec = strings.TrimSpace(rec)
+				if rec != "" && !seen[rec] {
+					seen[rec] = true
+					actions = append(actions, rec)
+				}
+			}
+		}
+	}
+	return findings, actions
+}
+
+// saveKnowledge 将复盘报告保存为知识库条目，事件复盘按事件ID覆盖，告警复盘按首个告警覆盖
+func (g *PostmortemGenerator) saveKnowledge(ctx context.Context, pm *incident.Postmortem, data *incident.PostmortemData) error {
+	knowledge := &model.Knowledge{
+		Title:    "[复盘] " + pm.Title,
+		Content:  pm.Markdown,
+		Category: postmortemKnowledgeCategory,
+		Tags:     strings.Join(append([]string{postmortemKnowledgeSource}, pm.ImpactedServices...), ","),
+		Source:   postmortemKnowledgeSource,
+		Summary:  fmt.Sprintf("%s，影响 %d 个服务，MTTR %s", pm.Title, len(pm.ImpactedServices), formatDuration(pm.MTTR)),
+	}
+	if len(pm.AlertIDs) > 0 {
+		knowledge.SourceID = pm.AlertIDs[0]
+	}
+	if pm.IncidentID != "" {
+		knowledge.Source = postmortemKnowledgeSource + ":" + pm.IncidentID
+	}
+
+	if err := g.repo.SaveKnowledge(ctx, knowledge); err != nil {
+		return errors.NewInternalError("failed to save postmortem to knowledge base", err)
+	}
+	pm.KnowledgeID = knowledge.ID
+
+	if data.Incident != nil {
+		entry := &incident.TimelineEntry{
+			IncidentID: data.Incident.ID,
+			Type:       incident.TimelineUpdate,
+			Actor:      data.GeneratedBy,
+			Message:    "复盘报告已保存到知识库",
+			Data:       map[string]interface{}{"knowledge_id": knowledge.ID},
+			CreatedAt:  g.now(),
+		}
+		if err := g.incidents.AddTimelineEntry(ctx, entry); err != nil {
+			g.logger.Warn("failed to record postmortem timeline entry",
+				zap.String("incident_id", data.Incident.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// buildTimeline 合并告警触发/处理、通知发送与事件时间线，按时间排序
+func buildTimeline(alerts []*model.Alert, records []*model.NotifyRecord, entries []*incident.TimelineEntry) []*incident.PostmortemEvent {
+	events := []*incident.PostmortemEvent{}
+	for _, alert := range alerts {
+		events = append(events, &incident.PostmortemEvent{
+			Time:    alert.CreatedAt,
+			Kind:    "alert",
+			Message: fmt.Sprintf("告警 #%d 触发：[%s] %s", alert.ID, alert.Level, alert.Title),
+		})
+		if alert.HandleTime != nil && alert.Status != model.AlertStatusNew {
+			message := fmt.Sprintf("告警 #%d 变更为 %s", alert.ID, alert.Status)
+			if alert.HandleNote != "" {
+				message += "：" + alert.HandleNote
+			}
+			events = append(events, &incident.PostmortemEvent{
+				Time:    *alert.HandleTime,
+				Kind:    "alert_" + alert.Status,
+				Actor:   alert.Handler,
+				Message: message,
+			})
+		}
+	}
+	for _, record := range records {
+		events = append(events, &incident.PostmortemEvent{
+			Time:    record.CreatedAt,
+			Kind:    "notification",
+			Message: fmt.Sprintf("告警 #%d 通过 %s 通知 %s（%s）", record.AlertID, record.Type, record.Target, record.Status),
+		})
+	}
+	for _, entry := range entries {
+		events = append(events, &incident.PostmortemEvent{
+			Time:    entry.CreatedAt,
+			Kind:    string(entry.Type),
+			Actor:   entry.Actor,
+			Message: entry.Message,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events
+}
+
+// parsePostmortemTemplate 解析复盘模板
+func parsePostmortemTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("postmortem").Funcs(template.FuncMap{
+		"fmtTime":     formatTime,
+		"fmtDuration": formatDuration,
+		"cell":        markdownCell,
+		"join":        strings.Join,
+		"percent":     func(v float64) float64 { return v * 100 },
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postmortem template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// formatTime 格式化时间，支持 time.Time 与 *time.Time，零值显示为 -
+func formatTime(v interface{}) string {
+	var t time.Time
+	switch value := v.(type) {
+	case time.Time:
+		t = value
+	case *time.Time:
+		if value != nil {
+			t = *value
+		}
+	}
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// formatDuration 格式化时长，精确到秒，零值显示为 -
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// markdownCell 转义表格单元格中的竖线与换行
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "\n", " ")), " ")
+}
+
+// uniqueIDs 去重并保持顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
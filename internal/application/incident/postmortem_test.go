@@ -0,0 +1,132 @@
+package incident
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryPostmortemRepository 内存复盘数据仓储
+type memoryPostmortemRepository struct {
+	alerts    map[uint]*model.Alert
+	records   []*model.NotifyRecord
+	knowledge []*model.Knowledge
+}
+
+func (r *memoryPostmortemRepository) ListAlerts(ctx context.Context, alertIDs []uint) ([]*model.Alert, error) {
+	var alerts []*model.Alert
+	for _, id := range alertIDs {
+		if alert, ok := r.alerts[id]; ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (r *memoryPostmortemRepository) ListNotifyRecords(ctx context.Context, alertIDs []uint) ([]*model.NotifyRecord, error) {
+	return r.records, nil
+}
+
+func (r *memoryPostmortemRepository) SaveKnowledge(ctx context.Context, knowledge *model.Knowledge) error {
+	knowledge.ID = uint(len(r.knowledge) + 1)
+	r.knowledge = append(r.knowledge, knowledge)
+	return nil
+}
+
+type staticAnalysisReader map[uint][]*analysis.DifyAnalysisResult
+
+func (r staticAnalysisReader) GetAnalysisResultsByAlertID(ctx context.Context, alertID uint) ([]*analysis.DifyAnalysisResult, error) {
+	return r[alertID], nil
+}
+
+func TestPostmortemGenerator_Incident(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ack := start.Add(4 * time.Minute)
+	resolved := start.Add(42 * time.Minute)
+
+	incidents := newMemoryRepository()
+	require.NoError(t, incidents.Create(ctx, &incident.Incident{
+		ID: "inc-1", Title: "checkout errors", Status: incident.StatusResolved, Severity: incident.SeverityHigh,
+		RootCause: "service mysql (db down)", CreatedAt: start, AcknowledgedAt: &ack, ResolvedAt: &resolved,
+	}))
+	_, _ = incidents.AttachAlerts(ctx, "inc-1", []uint{1, 2}, "alice")
+	incidents.timeline = append(incidents.timeline, &incident.TimelineEntry{
+		IncidentID: "inc-1", Type: incident.TimelineStatusChange, Actor: "alice", Message: "状态 triggered → acknowledged", CreatedAt: ack,
+	})
+
+	repo := &memoryPostmortemRepository{
+		alerts: map[uint]*model.Alert{
+			1: {ID: 1, Title: "db down", Level: model.AlertLevelCritical, Source: "prometheus", Status: model.AlertStatusResolved,
+				Labels: `{"service":"mysql"}`, CreatedAt: start.Add(time.Minute)},
+			2: {ID: 2, Title: "checkout 5xx", Level: model.AlertLevelHigh, Source: "prometheus", Status: model.AlertStatusResolved,
+				Labels: `{"app":"checkout"}`, CreatedAt: start},
+		},
+		records: []*model.NotifyRecord{{AlertID: 2, Type: model.NotifyTypeWebhook, Target: "oncall", Status: model.NotifyStatusSent}},
+	}
+	repo.records[0].CreatedAt = start.Add(30 * time.Second)
+	analyses := staticAnalysisReader{1: {{
+		AnalysisType: "root_cause", RootCause: "主库磁盘写满", Impact: "下单失败",
+		Recommendations: []string{"增加磁盘容量告警", "增加磁盘容量告警"}, Confidence: 0.85,
+	}}}
+
+	generator, err := NewPostmortemGenerator(incidents, repo, analyses, DefaultPostmortemConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	pm, err := generator.GeneratePostmortem(ctx, &incident.PostmortemRequest{IncidentID: "inc-1", SaveToKnowledge: true, Actor: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "checkout errors", pm.Title)
+	assert.Equal(t, []uint{2, 1}, pm.AlertIDs)
+	assert.Equal(t, []string{"checkout", "mysql"}, pm.ImpactedServices)
+	assert.Equal(t, 4*time.Minute, pm.MTTA)
+	assert.Equal(t, 42*time.Minute, pm.MTTR)
+
+	assert.Contains(t, pm.Markdown, "# 故障复盘：checkout errors")
+	assert.Contains(t, pm.Markdown, "| MTTR | 42m0s |")
+	assert.Contains(t, pm.Markdown, "通过 webhook 通知 oncall")
+	assert.Contains(t, pm.Markdown, "**根因**：主库磁盘写满")
+	assert.Contains(t, pm.Markdown, "置信度 85%")
+	assert.Equal(t, 1, strings.Count(pm.Markdown, "| 增加磁盘容量告警 | 待定 | 待定 | 待开始 |"))
+
+	// 保存到知识库并记录到事件时间线
+	require.Len(t, repo.knowledge, 1)
+	assert.Equal(t, pm.KnowledgeID, repo.knowledge[0].ID)
+	assert.Equal(t, "postmortem:inc-1", repo.knowledge[0].Source)
+	last := incidents.timeline[len(incidents.timeline)-1]
+	assert.Equal(t, incident.TimelineUpdate, last.Type)
+}
+
+func TestPostmortemGenerator_AlertsAndTemplate(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	handled := start.Add(10 * time.Minute)
+	repo := &memoryPostmortemRepository{alerts: map[uint]*model.Alert{
+		5: {ID: 5, Title: "disk full", Level: model.AlertLevelHigh, Source: "node", Status: model.AlertStatusResolved,
+			CreatedAt: start, HandleTime: &handled, Handler: "bob"},
+	}}
+	generator, err := NewPostmortemGenerator(newMemoryRepository(), repo, nil, DefaultPostmortemConfig(), zap.NewNop())
+	require.NoError(t, err)
+
+	pm, err := generator.GeneratePostmortem(ctx, &incident.PostmortemRequest{
+		AlertIDs: []uint{5},
+		Template: `{{.Title}} {{fmtDuration .MTTR}} {{join .ImpactedServices ","}}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "disk full 10m0s node", pm.Markdown)
+
+	_, err = generator.GeneratePostmortem(ctx, &incident.PostmortemRequest{AlertIDs: []uint{5}, Template: "{{.Title"})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+
+	_, err = generator.GeneratePostmortem(ctx, &incident.PostmortemRequest{AlertIDs: []uint{9}})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+}
@@ -0,0 +1,461 @@
+package incident
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// gatewayActor 网关自动维护事件时记录的操作人
+const gatewayActor = "gateway"
+
+// Service 事件服务实现
+type Service struct {
+	repo     incident.Repository
+	resolver incident.AlertResolver
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+// NewService 创建事件服务
+func NewService(repo incident.Repository, resolver incident.AlertResolver, logger *zap.Logger) *Service {
+	return &Service{
+		repo:     repo,
+		resolver: resolver,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// CreateIncident 手工创建事件
+func (s *Service) CreateIncident(ctx context.Context, req *incident.CreateIncidentRequest) (*incident.Incident, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, errors.NewValidationError("INVALID_TITLE", "Incident title is required")
+	}
+	severity := req.Severity
+	if severity == "" {
+		severity = incident.SeverityMedium
+	}
+	if !severity.IsValid() {
+		return nil, errors.NewValidationError("INVALID_SEVERITY", fmt.Sprintf("Invalid incident severity: %s", severity))
+	}
+
+	now := s.now()
+	inc := &incident.Incident{
+		ID:          uuid.New().String(),
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      incident.StatusTriggered,
+		Severity:    severity,
+		Assignee:    req.Assignee,
+		Source:      incident.SourceManual,
+		CreatedBy:   req.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, inc); err != nil {
+		return nil, errors.NewInternalError("failed to create incident", err)
+	}
+	s.addEntry(ctx, inc.ID, incident.TimelineCreated, req.CreatedBy, "事件已创建", nil)
+	if inc.Assignee != "" {
+		s.addEntry(ctx, inc.ID, incident.TimelineAssignment, req.CreatedBy, fmt.Sprintf("指派给 %s", inc.Assignee), nil)
+	}
+
+	if len(req.AlertIDs) > 0 {
+		if _, err := s.attach(ctx, inc, req.AlertIDs, req.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("incident created", zap.String("incident_id", inc.ID), zap.String("created_by", req.CreatedBy))
+	return s.GetIncident(ctx, inc.ID)
+}
+
+// GetIncident 获取事件及关联告警
+func (s *Service) GetIncident(ctx context.Context, id string) (*incident.Incident, error) {
+	inc, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	alertIDs, err := s.repo.ListAlertIDs(ctx, id)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list incident alerts", err)
+	}
+	inc.AlertIDs = alertIDs
+	return inc, nil
+}
+
+// ListIncidents 获取事件列表
+func (s *Service) ListIncidents(ctx context.Context, filter *incident.Filter) ([]*incident.Incident, int64, error) {
+	if filter == nil {
+		filter = &incident.Filter{}
+	}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, 0, errors.NewValidationError("INVALID_STATUS", fmt.Sprintf("Invalid incident status: %s", filter.Status))
+	}
+	incidents, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, errors.NewInternalError("failed to list incidents", err)
+	}
+	return incidents, total, nil
+}
+
+// UpdateIncident 更新标题、描述、严重程度和处理人
+func (s *Service) UpdateIncident(ctx context.Context, id string, req *incident.UpdateIncidentRequest) (*incident.Incident, error) {
+	inc, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	if req.Title != nil && *req.Title != inc.Title {
+		if strings.TrimSpace(*req.Title) == "" {
+			return nil, errors.NewValidationError("INVALID_TITLE", "Incident title is required")
+		}
+		inc.Title = *req.Title
+		changes = append(changes, "标题")
+	}
+	if req.Description != nil && *req.Description != inc.Description {
+		inc.Description = *req.Description
+		changes = append(changes, "描述")
+	}
+	if req.Severity != nil && *req.Severity != inc.Severity {
+		if !req.Severity.IsValid() {
+			return nil, errors.NewValidationError("INVALID_SEVERITY", fmt.Sprintf("Invalid incident severity: %s", *req.Severity))
+		}
+		changes = append(changes, fmt.Sprintf("严重程度 %s → %s", inc.Severity, *req.Severity))
+		inc.Severity = *req.Severity
+	}
+	reassigned := req.Assignee != nil && *req.Assignee != inc.Assignee
+	if reassigned {
+		inc.Assignee = *req.Assignee
+	}
+	if len(changes) == 0 && !reassigned {
+		return s.GetIncident(ctx, id)
+	}
+
+	inc.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, inc); err != nil {
+		return nil, errors.NewInternalError("failed to update incident", err)
+	}
+	if len(changes) > 0 {
+		s.addEntry(ctx, id, incident.TimelineUpdate, req.Actor, "更新"+strings.Join(changes, "、"), nil)
+	}
+	if reassigned {
+		message := "取消指派"
+		if inc.Assignee != "" {
+			message = fmt.Sprintf("指派给 %s", inc.Assignee)
+		}
+		s.addEntry(ctx, id, incident.TimelineAssignment, req.Actor, message, map[string]interface{}{"assignee": inc.Assignee})
+	}
+	return s.GetIncident(ctx, id)
+}
+
+// UpdateStatus 变更事件状态，解决时可同时恢复关联告警
+func (s *Service) UpdateStatus(ctx context.Context, id string, req *incident.UpdateStatusRequest) (*incident.Incident, error) {
+	inc, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Status.IsValid() {
+		return nil, errors.NewValidationError("INVALID_STATUS", fmt.Sprintf("Invalid incident status: %s", req.Status))
+	}
+	if !inc.Status.CanTransitionTo(req.Status) {
+		return nil, errors.NewConflictError(fmt.Sprintf("Cannot change incident status from %s to %s", inc.Status, req.Status))
+	}
+
+	from := inc.Status
+	now := s.now()
+	switch req.Status {
+	case incident.StatusTriggered:
+		// 重新打开
+		inc.AcknowledgedAt = nil
+		inc.MitigatedAt = nil
+		inc.ResolvedAt = nil
+	case incident.StatusAcknowledged:
+		inc.AcknowledgedAt = &now
+	case incident.StatusMitigated:
+		if inc.AcknowledgedAt == nil {
+			inc.AcknowledgedAt = &now
+		}
+		inc.MitigatedAt = &now
+	case incident.StatusResolved:
+		if inc.AcknowledgedAt == nil {
+			inc.AcknowledgedAt = &now
+		}
+		inc.ResolvedAt = &now
+	}
+	inc.Status = req.Status
+	inc.UpdatedAt = now
+	if err := s.repo.Update(ctx, inc); err != nil {
+		return nil, errors.NewInternalError("failed to update incident status", err)
+	}
+
+	data := map[string]interface{}{"from": from, "to": req.Status}
+	if req.Status == incident.StatusResolved && req.ResolveAlerts {
+		resolved, failed := s.resolveAlerts(ctx, id, req.Note)
+		data["resolved_alerts"] = resolved
+		if len(failed) > 0 {
+			data["failed_alerts"] = failed
+		}
+	}
+	message := fmt.Sprintf("状态 %s → %s", from, req.Status)
+	if req.Note != "" {
+		message += "：" + req.Note
+	}
+	s.addEntry(ctx, id, incident.TimelineStatusChange, req.Actor, message, data)
+
+	s.logger.Info("incident status changed",
+		zap.String("incident_id", id),
+		zap.String("from", string(from)),
+		zap.String("to", string(req.Status)),
+		zap.String("actor", req.Actor))
+	return s.GetIncident(ctx, id)
+}
+
+// resolveAlerts 恢复事件关联的告警，单个告警失败不影响其他告警
+func (s *Service) resolveAlerts(ctx context.Context, id, note string) (resolved, failed []uint) {
+	alertIDs, err := s.repo.ListAlertIDs(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to list incident alerts", zap.String("incident_id", id), zap.Error(err))
+		return nil, nil
+	}
+	if note == "" {
+		note = fmt.Sprintf("resolved with incident %s", id)
+	}
+	for _, alertID := range alertIDs {
+		if err := s.resolver.ResolveAlert(ctx, alertID, note); err != nil {
+			s.logger.Warn("failed to resolve incident alert",
+				zap.String("incident_id", id), zap.Uint("alert_id", alertID), zap.Error(err))
+			failed = append(failed, alertID)
+			continue
+		}
+		resolved = append(resolved, alertID)
+	}
+	return resolved, failed
+}
+
+// AttachAlerts 关联告警
+func (s *Service) AttachAlerts(ctx context.Context, id string, req *incident.AttachAlertsRequest) (*incident.Incident, error) {
+	if len(req.AlertIDs) == 0 {
+		return nil, errors.NewValidationError("INVALID_ALERT_IDS", "At least one alert ID is required")
+	}
+	inc, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.attach(ctx, inc, req.AlertIDs, req.Actor); err != nil {
+		return nil, err
+	}
+	return s.GetIncident(ctx, id)
+}
+
+// attach 关联告警并记录时间线
+func (s *Service) attach(ctx context.Context, inc *incident.Incident, alertIDs []uint, actor string) ([]uint, error) {
+	attached, err := s.repo.AttachAlerts(ctx, inc.ID, alertIDs, actor)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to attach alerts", err)
+	}
+	if len(attached) > 0 {
+		s.addEntry(ctx, inc.ID, incident.TimelineAlertAttached, actor,
+			fmt.Sprintf("关联 %d 个告警", len(attached)), map[string]interface{}{"alert_ids": attached})
+	}
+	return attached, nil
+}
+
+// DetachAlerts 解除关联告警
+func (s *Service) DetachAlerts(ctx context.Context, id string, req *incident.AttachAlertsRequest) (*incident.Incident, error) {
+	if len(req.AlertIDs) == 0 {
+		return nil, errors.NewValidationError("INVALID_ALERT_IDS", "At least one alert ID is required")
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+	detached, err := s.repo.DetachAlerts(ctx, id, req.AlertIDs)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to detach alerts", err)
+	}
+	if len(detached) > 0 {
+		s.addEntry(ctx, id, incident.TimelineAlertDetached, req.Actor,
+			fmt.Sprintf("解除关联 %d 个告警", len(detached)), map[string]interface{}{"alert_ids": detached})
+	}
+	return s.GetIncident(ctx, id)
+}
+
+// AddTimelineEntry 添加时间线条目，仅允许评论、通知和分析结果，其余条目由事件变更自动生成
+func (s *Service) AddTimelineEntry(ctx context.Context, id string, req *incident.AddTimelineEntryRequest) (*incident.TimelineEntry, error) {
+	entryType := req.Type
+	if entryType == "" {
+		entryType = incident.TimelineComment
+	}
+	switch entryType {
+	case incident.TimelineComment, incident.TimelineNotification, incident.TimelineAnalysis:
+	default:
+		return nil, errors.NewValidationError("INVALID_ENTRY_TYPE", fmt.Sprintf("Timeline entry type %s cannot be added manually", entryType))
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		return nil, errors.NewValidationError("INVALID_MESSAGE", "Timeline entry message is required")
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	entry := &incident.TimelineEntry{
+		IncidentID: id,
+		Type:       entryType,
+		Actor:      req.Actor,
+		Message:    req.Message,
+		Data:       req.Data,
+		CreatedAt:  s.now(),
+	}
+	if err := s.repo.AddTimelineEntry(ctx, entry); err != nil {
+		return nil, errors.NewInternalError("failed to add timeline entry", err)
+	}
+	return entry, nil
+}
+
+// GetTimeline 获取事件时间线
+func (s *Service) GetTimeline(ctx context.Context, id string) ([]*incident.TimelineEntry, error) {
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListTimeline(ctx, id)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get incident timeline", err)
+	}
+	return entries, nil
+}
+
+// TrackConvergence 为收敛分组创建或更新事件。分组内只有一个告警时不创建事件。
+func (s *Service) TrackConvergence(ctx context.Context, alert *model.Alert, result *gateway.ConvergenceResult) error {
+	if result == nil || result.GroupID == "" {
+		return nil
+	}
+	alerts := groupAlerts(alert, result)
+	if len(alerts) < 2 {
+		return nil
+	}
+
+	inc, err := s.repo.GetOpenByGroupID(ctx, result.GroupID)
+	if err != nil {
+		return err
+	}
+	severity := maxSeverity(alerts)
+	rootCause := result.RootCause.String()
+
+	if inc == nil {
+		now := s.now()
+		title := alerts[0].Title
+		if result.Representative != nil {
+			title = result.Representative.Title
+		}
+		inc = &incident.Incident{
+			ID:          uuid.New().String(),
+			Title:       title,
+			Description: result.ConvergenceRule,
+			Status:      incident.StatusTriggered,
+			Severity:    severity,
+			Source:      incident.SourceConvergence,
+			GroupID:     result.GroupID,
+			RootCause:   rootCause,
+			CreatedBy:   gatewayActor,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.repo.Create(ctx, inc); err != nil {
+			return err
+		}
+		s.addEntry(ctx, inc.ID, incident.TimelineCreated, gatewayActor, "由告警收敛分组自动创建",
+			map[string]interface{}{"group_id": result.GroupID, "convergence_rule": result.ConvergenceRule})
+		s.logger.Info("incident created from convergence group",
+			zap.String("incident_id", inc.ID), zap.String("group_id", result.GroupID))
+	} else if severity.Higher(inc.Severity) || (rootCause != "" && rootCause != inc.RootCause) {
+		var changes []string
+		if severity.Higher(inc.Severity) {
+			changes = append(changes, fmt.Sprintf("严重程度 %s → %s", inc.Severity, severity))
+			inc.Severity = severity
+		}
+		if rootCause != "" && rootCause != inc.RootCause {
+			changes = append(changes, "可能根因 "+rootCause)
+			inc.RootCause = rootCause
+		}
+		inc.UpdatedAt = s.now()
+		if err := s.repo.Update(ctx, inc); err != nil {
+			return err
+		}
+		s.addEntry(ctx, inc.ID, incident.TimelineUpdate, gatewayActor, "更新"+strings.Join(changes, "、"), nil)
+	}
+
+	alertIDs := make([]uint, 0, len(alerts))
+	for _, a := range alerts {
+		alertIDs = append(alertIDs, a.ID)
+	}
+	_, err = s.attach(ctx, inc, alertIDs, gatewayActor)
+	return err
+}
+
+// get 获取事件，不存在时返回未找到错误
+func (s *Service) get(ctx context.Context, id string) (*incident.Incident, error) {
+	inc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to get incident", err)
+	}
+	if inc == nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("incident %s", id))
+	}
+	return inc, nil
+}
+
+// addEntry 记录系统生成的时间线条目，失败只记录日志
+func (s *Service) addEntry(ctx context.Context, id string, entryType incident.TimelineEntryType, actor, message string, data map[string]interface{}) {
+	entry := &incident.TimelineEntry{
+		IncidentID: id,
+		Type:       entryType,
+		Actor:      actor,
+		Message:    message,
+		Data:       data,
+		CreatedAt:  s.now(),
+	}
+	if err := s.repo.AddTimelineEntry(ctx, entry); err != nil {
+		s.logger.Warn("failed to add incident timeline entry",
+			zap.String("incident_id", id), zap.String("type", string(entryType)), zap.Error(err))
+	}
+}
+
+// groupAlerts 收敛分组内的告警，按ID去重
+func groupAlerts(alert *model.Alert, result *gateway.ConvergenceResult) []*model.Alert {
+	candidates := append([]*model.Alert{result.Representative}, result.SimilarAlerts...)
+	candidates = append(candidates, alert)
+
+	seen := make(map[uint]bool, len(candidates))
+	alerts := make([]*model.Alert, 0, len(candidates))
+	for _, a := range candidates {
+		if a == nil || a.ID == 0 || seen[a.ID] {
+			continue
+		}
+		seen[a.ID] = true
+		alerts = append(alerts, a)
+	}
+	return alerts
+}
+
+// maxSeverity 分组内最高的告警级别
+func maxSeverity(alerts []*model.Alert) incident.Severity {
+	severity := incident.SeverityLow
+	for _, a := range alerts {
+		level := incident.Severity(a.Level)
+		if level.IsValid() && level.Higher(severity) {
+			severity = level
+		}
+	}
+	return severity
+}
@@ -0,0 +1,204 @@
+package incident
+
+import (
+	"context"
+	"testing"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/model"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryRepository 内存事件仓储
+type memoryRepository struct {
+	incidents map[string]*incident.Incident
+	links     map[uint]string
+	timeline  []*incident.TimelineEntry
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		incidents: map[string]*incident.Incident{},
+		links:     map[uint]string{},
+	}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, inc *incident.Incident) error {
+	copied := *inc
+	r.incidents[inc.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id string) (*incident.Incident, error) {
+	inc, ok := r.incidents[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *inc
+	return &copied, nil
+}
+
+func (r *memoryRepository) GetOpenByGroupID(ctx context.Context, groupID string) (*incident.Incident, error) {
+	for _, inc := range r.incidents {
+		if inc.GroupID == groupID && inc.Status != incident.StatusResolved {
+			return r.GetByID(ctx, inc.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) GetByAlertID(ctx context.Context, alertID uint) (*incident.Incident, error) {
+	return r.GetByID(ctx, r.links[alertID])
+}
+
+func (r *memoryRepository) List(ctx context.Context, filter *incident.Filter) ([]*incident.Incident, int64, error) {
+	var result []*incident.Incident
+	for _, inc := range r.incidents {
+		result = append(result, inc)
+	}
+	return result, int64(len(result)), nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, inc *incident.Incident) error {
+	return r.Create(ctx, inc)
+}
+
+func (r *memoryRepository) AttachAlerts(ctx context.Context, incidentID string, alertIDs []uint, actor string) ([]uint, error) {
+	var attached []uint
+	for _, id := range alertIDs {
+		if _, ok := r.links[id]; ok {
+			continue
+		}
+		r.links[id] = incidentID
+		attached = append(attached, id)
+	}
+	return attached, nil
+}
+
+func (r *memoryRepository) DetachAlerts(ctx context.Context, incidentID string, alertIDs []uint) ([]uint, error) {
+	var detached []uint
+	for _, id := range alertIDs {
+		if r.links[id] == incidentID {
+			delete(r.links, id)
+			detached = append(detached, id)
+		}
+	}
+	return detached, nil
+}
+
+func (r *memoryRepository) ListAlertIDs(ctx context.Context, incidentID string) ([]uint, error) {
+	var ids []uint
+	for id, owner := range r.links {
+		if owner == incidentID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryRepository) AddTimelineEntry(ctx context.Context, entry *incident.TimelineEntry) error {
+	r.timeline = append(r.timeline, entry)
+	return nil
+}
+
+func (r *memoryRepository) ListTimeline(ctx context.Context, incidentID string) ([]*incident.TimelineEntry, error) {
+	var entries []*incident.TimelineEntry
+	for _, e := range r.timeline {
+		if e.IncidentID == incidentID {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+type recordingResolver struct {
+	resolved []uint
+}
+
+func (r *recordingResolver) ResolveAlert(ctx context.Context, alertID uint, note string) error {
+	r.resolved = append(r.resolved, alertID)
+	return nil
+}
+
+func TestService_TrackConvergence(t *testing.T) {
+	repo := newMemoryRepository()
+	service := NewService(repo, &recordingResolver{}, zap.NewNop())
+	ctx := context.Background()
+
+	first := &model.Alert{ID: 1, Title: "db latency high", Level: model.AlertLevelMedium}
+	second := &model.Alert{ID: 2, Title: "db latency high", Level: model.AlertLevelMedium}
+
+	// 分组内只有一个告警时不创建事件
+	require.NoError(t, service.TrackConvergence(ctx, first, &gateway.ConvergenceResult{GroupID: "g1", Representative: first}))
+	assert.Empty(t, repo.incidents)
+
+	require.NoError(t, service.TrackConvergence(ctx, second, &gateway.ConvergenceResult{
+		GroupID: "g1", Representative: first, SimilarAlerts: []*model.Alert{first},
+	}))
+	require.Len(t, repo.incidents, 1)
+
+	// 同一分组的后续告警归入已有事件，严重程度随之升级
+	third := &model.Alert{ID: 3, Title: "db down", Level: model.AlertLevelCritical}
+	require.NoError(t, service.TrackConvergence(ctx, third, &gateway.ConvergenceResult{
+		GroupID: "g1", Representative: first, SimilarAlerts: []*model.Alert{first, second},
+		RootCause: &gateway.RootCause{Service: "mysql", AlertName: "db down"},
+	}))
+	require.Len(t, repo.incidents, 1)
+
+	var inc *incident.Incident
+	for _, i := range repo.incidents {
+		inc = i
+	}
+	assert.Equal(t, incident.SourceConvergence, inc.Source)
+	assert.Equal(t, incident.SeverityCritical, inc.Severity)
+	assert.Equal(t, "service mysql (db down)", inc.RootCause)
+
+	got, err := service.GetIncident(ctx, inc.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint{1, 2, 3}, got.AlertIDs)
+}
+
+func TestService_UpdateStatusResolvesAlerts(t *testing.T) {
+	repo := newMemoryRepository()
+	resolver := &recordingResolver{}
+	service := NewService(repo, resolver, zap.NewNop())
+	ctx := context.Background()
+
+	inc, err := service.CreateIncident(ctx, &incident.CreateIncidentRequest{
+		Title: "checkout errors", AlertIDs: []uint{7, 8}, CreatedBy: "alice",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, incident.StatusTriggered, inc.Status)
+	assert.Equal(t, incident.SeverityMedium, inc.Severity)
+
+	inc, err = service.UpdateStatus(ctx, inc.ID, &incident.UpdateStatusRequest{Status: incident.StatusAcknowledged, Actor: "alice"})
+	require.NoError(t, err)
+	assert.NotNil(t, inc.AcknowledgedAt)
+
+	// 不能回退到已经过的状态
+	_, err = service.UpdateStatus(ctx, inc.ID, &incident.UpdateStatusRequest{Status: incident.StatusTriggered})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeConflict))
+
+	inc, err = service.UpdateStatus(ctx, inc.ID, &incident.UpdateStatusRequest{
+		Status: incident.StatusResolved, ResolveAlerts: true, Note: "rolled back", Actor: "alice",
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, inc.ResolvedAt)
+	assert.ElementsMatch(t, []uint{7, 8}, resolver.resolved)
+
+	timeline, err := service.GetTimeline(ctx, inc.ID)
+	require.NoError(t, err)
+	types := make([]incident.TimelineEntryType, 0, len(timeline))
+	for _, e := range timeline {
+		types = append(types, e.Type)
+	}
+	assert.Equal(t, []incident.TimelineEntryType{
+		incident.TimelineCreated, incident.TimelineAlertAttached,
+		incident.TimelineStatusChange, incident.TimelineStatusChange,
+	}, types)
+}
@@ -0,0 +1,355 @@
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"alert_agent/internal/domain/retention"
+	"alert_agent/internal/shared/errors"
+
+	"go.uber.org/zap"
+)
+
+// archiveLeaseKey 归档租约
+const archiveLeaseKey = "retention:archive:lease"
+
+// Service 数据保留服务实现，先写归档文件与记录，再物理删除
+type Service struct {
+	repo    retention.Repository
+	storage retention.Storage
+	leases  retention.LeaseManager
+	config  retention.Config
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewService 创建数据保留服务
+func NewService(repo retention.Repository, storage retention.Storage, leases retention.LeaseManager, config retention.Config, logger *zap.Logger) *Service {
+	defaults := retention.DefaultConfig()
+	if config.Owner == "" {
+		hostname, _ := os.Hostname()
+		config.Owner = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxBatchesPerRun <= 0 {
+		config.MaxBatchesPerRun = defaults.MaxBatchesPerRun
+	}
+	if config.LeaseTTL <= 0 {
+		config.LeaseTTL = defaults.LeaseTTL
+	}
+	return &Service{
+		repo:    repo,
+		storage: storage,
+		leases:  leases,
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Policies 获取生效的保留策略
+func (s *Service) Policies() []retention.Policy {
+	return s.config.Policies
+}
+
+// Run 周期性归档过期数据，持有租约的 worker 才会归档，直到 ctx 取消
+func (s *Service) Run(ctx context.Context) error {
+	s.logger.Info("Retention archiver started",
+		zap.String("owner", s.config.Owner),
+		zap.String("storage", s.storage.Name()),
+		zap.Duration("interval", s.config.Interval))
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := s.leases.Acquire(ctx, archiveLeaseKey, s.config.Owner, s.config.LeaseTTL)
+		if err != nil {
+			s.logger.Warn("Failed to acquire retention lease", zap.Error(err))
+		} else if acquired {
+			if _, err := s.run(ctx, s.now()); err != nil && ctx.Err() == nil {
+				s.logger.Error("Failed to archive expired data", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.leases.Release(releaseCtx, archiveLeaseKey, s.config.Owner); err != nil {
+				s.logger.Warn("Failed to release retention lease", zap.Error(err))
+			}
+			cancel()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Archive 立即执行一次归档，其他 worker 正在归档时返回冲突
+func (s *Service) Archive(ctx context.Context) (*retention.RunResult, error) {
+	acquired, err := s.leases.Acquire(ctx, archiveLeaseKey, s.config.Owner, s.config.LeaseTTL)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to acquire retention lease", err)
+	}
+	if !acquired {
+		return nil, errors.NewConflictError("Expired data is being archived by another worker")
+	}
+	result, err := s.run(ctx, s.now())
+	if err != nil {
+		return nil, errors.NewInternalError("failed to archive expired data", err)
+	}
+	return result, nil
+}
+
+// ListArchives 查询归档文件
+func (s *Service) ListArchives(ctx context.Context, filter *retention.ArchiveFilter) ([]*retention.Archive, error) {
+	if filter.Table != "" && !filter.Table.Valid() {
+		return nil, errors.NewValidationError("INVALID_TABLE", fmt.Sprintf("Unsupported table: %s", filter.Table))
+	}
+	archives, err := s.repo.ListArchives(ctx, filter)
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list archives", err)
+	}
+	return archives, nil
+}
+
+// Restore 将归档中创建时间在 [from, to) 内的记录写回数据库，已存在的记录跳过
+func (s *Service) Restore(ctx context.Context, req *retention.RestoreRequest) (*retention.RestoreResult, error) {
+	if !req.Table.Valid() {
+		return nil, errors.NewValidationError("INVALID_TABLE", fmt.Sprintf("Unsupported table: %s", req.Table))
+	}
+	if !req.From.Before(req.To) {
+		return nil, errors.NewValidationError("INVALID_RANGE", "from must be before to")
+	}
+	archives, err := s.repo.ListArchives(ctx, &retention.ArchiveFilter{Table: req.Table, From: req.From, To: req.To})
+	if err != nil {
+		return nil, errors.NewInternalError("failed to list archives", err)
+	}
+	if len(archives) == 0 {
+		return nil, errors.NewNotFoundError("archive")
+	}
+
+	result := &retention.RestoreResult{Table: req.Table, Archives: len(archives)}
+	for _, archive := range archives {
+		rows, err := s.readArchive(ctx, archive, req.From, req.To)
+		if err != nil {
+			return nil, errors.NewInternalError(fmt.Sprintf("failed to read archive %s", archive.Key), err)
+		}
+		result.Matched += len(rows)
+		for start := 0; start < len(rows); start += s.config.BatchSize {
+			end := start + s.config.BatchSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			restored, err := s.repo.Restore(ctx, req.Table, rows[start:end])
+			if err != nil {
+				return nil, errors.NewInternalError(fmt.Sprintf("failed to restore archive %s", archive.Key), err)
+			}
+			result.Restored += restored
+		}
+		if err := s.repo.MarkRestored(ctx, archive.ID, s.now()); err != nil {
+			s.logger.Warn("Failed to mark archive restored", zap.String("key", archive.Key), zap.Error(err))
+		}
+	}
+	result.Skipped = int64(result.Matched) - result.Restored
+
+	s.logger.Info("Archived data restored",
+		zap.String("table", string(req.Table)),
+		zap.Time("from", req.From),
+		zap.Time("to", req.To),
+		zap.Int("archives", result.Archives),
+		zap.Int64("restored", result.Restored))
+	return result, nil
+}
+
+// run 依次归档每张表的过期数据并补齐告警表月分区
+func (s *Service) run(ctx context.Context, now time.Time) (*retention.RunResult, error) {
+	result := &retention.RunResult{StartedAt: now, Tables: []*retention.TableResult{}}
+	for _, table := range retention.Tables {
+		tableResult, err := s.archiveTable(ctx, table, now)
+		if tableResult != nil {
+			result.Tables = append(result.Tables, tableResult)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to archive %s: %w", table, err)
+		}
+	}
+
+	if s.config.PartitionMonthsAhead > 0 {
+		added, err := s.repo.EnsurePartitions(ctx, now.AddDate(0, s.config.PartitionMonthsAhead, 0))
+		if err != nil {
+			s.logger.Warn("Failed to ensure alert partitions", zap.Error(err))
+		}
+		result.Partitions = added
+	}
+	return result, nil
+}
+
+// archiveTable 按批扫描早于最短保留期的记录，每批过期记录写一个归档文件后删除
+func (s *Service) archiveTable(ctx context.Context, table retention.Table, now time.Time) (*retention.TableResult, error) {
+	var minAge time.Duration
+	for _, policy := range s.config.Policies {
+		if policy.Table == table && (minAge == 0 || policy.MaxAge < minAge) {
+			minAge = policy.MaxAge
+		}
+	}
+	if minAge == 0 {
+		return nil, nil
+	}
+
+	result := &retention.TableResult{Table: table, Archives: []string{}}
+	before := now.Add(-minAge)
+	var cursor *retention.Cursor
+	for batch := 0; batch < s.config.MaxBatchesPerRun; batch++ {
+		rows, err := s.repo.ScanCandidates(ctx, table, before, cursor, s.config.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		result.Scanned += len(rows)
+		last := rows[len(rows)-1]
+		cursor = &retention.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+
+		var expired []*retention.Row
+		for _, row := range rows {
+			policy, ok := retention.Resolve(s.config.Policies, table, row.Status, row.Severity)
+			if ok && row.CreatedAt.Before(now.Add(-policy.MaxAge)) {
+				expired = append(expired, row)
+			}
+		}
+		if len(expired) > 0 {
+			key, deleted, err := s.archiveRows(ctx, table, expired, now)
+			if err != nil {
+				return result, err
+			}
+			result.Archived += len(expired)
+			result.Deleted += deleted
+			result.Archives = append(result.Archives, key)
+		}
+		if len(rows) < s.config.BatchSize {
+			break
+		}
+	}
+
+	if result.Archived > 0 {
+		s.logger.Info("Expired data archived",
+			zap.String("table", string(table)),
+			zap.Int("archived", result.Archived),
+			zap.Int64("deleted", result.Deleted),
+			zap.Int("files", len(result.Archives)))
+	}
+	return result, nil
+}
+
+// archiveRows 写入归档文件与记录，两者都成功后才删除数据
+func (s *Service) archiveRows(ctx context.Context, table retention.Table, rows []*retention.Row, now time.Time) (string, int64, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	from, to := rows[0].CreatedAt, rows[0].CreatedAt
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if _, err := writer.Write(row.Data); err != nil {
+			return "", 0, fmt.Errorf("failed to compress archive: %w", err)
+		}
+		if _, err := writer.Write([]byte{'\n'}); err != nil {
+			return "", 0, fmt.Errorf("failed to compress archive: %w", err)
+		}
+		if row.CreatedAt.Before(from) {
+			from = row.CreatedAt
+		}
+		if row.CreatedAt.After(to) {
+			to = row.CreatedAt
+		}
+		ids = append(ids, row.ID)
+	}
+	if err := writer.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	key := archiveKey(table, from, to, now)
+	if err := s.storage.Put(ctx, key, buf.Bytes()); err != nil {
+		return "", 0, fmt.Errorf("failed to write archive %s: %w", key, err)
+	}
+	archive := &retention.Archive{
+		Table:     table,
+		Backend:   s.storage.Name(),
+		Key:       key,
+		From:      from,
+		To:        to,
+		Rows:      len(rows),
+		Bytes:     int64(buf.Len()),
+		CreatedAt: now,
+	}
+	if err := s.repo.SaveArchive(ctx, archive); err != nil {
+		return "", 0, fmt.Errorf("failed to save archive %s: %w", key, err)
+	}
+
+	deleted, err := s.repo.Delete(ctx, table, ids)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to delete archived rows: %w", err)
+	}
+	if deleted != int64(len(ids)) {
+		s.logger.Warn("Archived rows changed before deletion",
+			zap.String("table", string(table)),
+			zap.Int("archived", len(ids)),
+			zap.Int64("deleted", deleted))
+	}
+	return key, deleted, nil
+}
+
+// readArchive 读取归档文件中创建时间在 [from, to) 内的记录
+func (s *Service) readArchive(ctx context.Context, archive *retention.Archive, from, to time.Time) ([]json.RawMessage, error) {
+	data, err := s.storage.Get(ctx, archive.Key)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer reader.Close()
+
+	var rows []json.RawMessage
+	lines := bufio.NewReader(reader)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var header struct {
+				CreatedAt time.Time `json:"created_at"`
+			}
+			if err := json.Unmarshal(line, &header); err != nil {
+				return nil, fmt.Errorf("failed to decode archived row: %w", err)
+			}
+			if !header.CreatedAt.Before(from) && header.CreatedAt.Before(to) {
+				rows = append(rows, json.RawMessage(line))
+			}
+		}
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress archive: %w", err)
+		}
+	}
+}
+
+// archiveKey 归档文件路径，按表与月份分目录
+func archiveKey(table retention.Table, from, to, now time.Time) string {
+	const compact = "20060102T150405Z"
+	return fmt.Sprintf("%s/%s/%s_%s_%d.jsonl.gz",
+		table, from.UTC().Format("2006/01"), from.UTC().Format(compact), to.UTC().Format(compact), now.UnixNano())
+}
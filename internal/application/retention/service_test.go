@@ -0,0 +1,221 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/retention"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryRepository 内存数据保留仓储，只存放告警
+type memoryRepository struct {
+	rows     map[string]*retention.Row
+	archives []*retention.Archive
+}
+
+func (r *memoryRepository) sorted() []*retention.Row {
+	var rows []*retention.Row
+	for _, row := range r.rows {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].CreatedAt.Equal(rows[j].CreatedAt) {
+			return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	return rows
+}
+
+func (r *memoryRepository) ScanCandidates(ctx context.Context, table retention.Table, before time.Time, after *retention.Cursor, limit int) ([]*retention.Row, error) {
+	var rows []*retention.Row
+	for _, row := range r.sorted() {
+		if table != retention.TableAlerts || !row.CreatedAt.Before(before) {
+			continue
+		}
+		if after != nil && (row.CreatedAt.Before(after.CreatedAt) || row.CreatedAt.Equal(after.CreatedAt) && row.ID <= after.ID) {
+			continue
+		}
+		if len(rows) == limit {
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, table retention.Table, ids []string) (int64, error) {
+	for _, id := range ids {
+		delete(r.rows, id)
+	}
+	return int64(len(ids)), nil
+}
+
+func (r *memoryRepository) Restore(ctx context.Context, table retention.Table, rows []json.RawMessage) (int64, error) {
+	var restored int64
+	for _, data := range rows {
+		var row retention.Row
+		if err := json.Unmarshal(data, &row); err != nil {
+			return 0, err
+		}
+		if _, ok := r.rows[row.ID]; ok {
+			continue
+		}
+		row.Data = data
+		r.rows[row.ID] = &row
+		restored++
+	}
+	return restored, nil
+}
+
+func (r *memoryRepository) SaveArchive(ctx context.Context, archive *retention.Archive) error {
+	archive.ID = uint(len(r.archives) + 1)
+	r.archives = append(r.archives, archive)
+	return nil
+}
+
+func (r *memoryRepository) ListArchives(ctx context.Context, filter *retention.ArchiveFilter) ([]*retention.Archive, error) {
+	var archives []*retention.Archive
+	for _, archive := range r.archives {
+		if archive.Table == filter.Table && !archive.To.Before(filter.From) && archive.From.Before(filter.To) {
+			archives = append(archives, archive)
+		}
+	}
+	return archives, nil
+}
+
+func (r *memoryRepository) MarkRestored(ctx context.Context, id uint, at time.Time) error {
+	r.archives[id-1].RestoredAt = &at
+	return nil
+}
+
+func (r *memoryRepository) EnsurePartitions(ctx context.Context, through time.Time) (int, error) {
+	return 0, nil
+}
+
+type memoryStorage map[string][]byte
+
+func (s memoryStorage) Name() string { return "memory" }
+
+func (s memoryStorage) Put(ctx context.Context, key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s memoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	return s[key], nil
+}
+
+type memoryLeases struct{ holder string }
+
+func (l *memoryLeases) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	if l.holder == "" || l.holder == owner {
+		l.holder = owner
+		return true, nil
+	}
+	return false, nil
+}
+
+func (l *memoryLeases) Release(ctx context.Context, key, owner string) error {
+	if l.holder == owner {
+		l.holder = ""
+	}
+	return nil
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := retention.ParsePolicies("alerts:resolved:*:90, alerts:*:critical:365,alert_processing_records::*:30")
+	require.NoError(t, err)
+	require.Len(t, policies, 3)
+	assert.Equal(t, retention.Any, policies[2].Status)
+
+	policy, ok := retention.Resolve(policies, retention.TableAlerts, "resolved", "critical")
+	require.True(t, ok)
+	assert.Equal(t, 90*24*time.Hour, policy.MaxAge, "status is more specific than severity")
+	_, ok = retention.Resolve(policies, retention.TableAlerts, "new", "warning")
+	assert.False(t, ok)
+
+	_, err = retention.ParsePolicies("alerts:resolved:*")
+	assert.Error(t, err)
+	_, err = retention.ParsePolicies("incidents:*:*:30")
+	assert.Error(t, err)
+}
+
+func TestService_ArchiveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	repo := &memoryRepository{rows: make(map[string]*retention.Row)}
+	add := func(id int, status, severity string, age time.Duration) {
+		row := &retention.Row{ID: strconv.Itoa(id), Status: status, Severity: severity, CreatedAt: now.Add(-age)}
+		row.Data, _ = json.Marshal(row)
+		repo.rows[row.ID] = row
+	}
+	add(1, "resolved", "warning", 40*day)  // 超过 resolved 的 30 天
+	add(2, "resolved", "critical", 40*day) // critical 保留 60 天
+	add(3, "new", "warning", 50*day)       // 没有适用策略，永久保留
+	add(4, "resolved", "info", 31*day)
+	add(5, "resolved", "info", 10*day)
+	add(6, "resolved", "critical", 70*day)
+
+	storage := memoryStorage{}
+	leases := &memoryLeases{}
+	service := NewService(repo, storage, leases, retention.Config{
+		Owner:            "worker-1",
+		BatchSize:        2,
+		MaxBatchesPerRun: 10,
+		Policies: []retention.Policy{
+			{Table: retention.TableAlerts, Status: "resolved", Severity: retention.Any, MaxAge: 30 * day},
+			{Table: retention.TableAlerts, Status: "resolved", Severity: "critical", MaxAge: 60 * day},
+		},
+	}, zap.NewNop())
+	service.now = func() time.Time { return now }
+
+	result, err := service.Archive(ctx)
+	require.NoError(t, err)
+	require.Len(t, result.Tables, 1)
+	assert.Equal(t, 5, result.Tables[0].Scanned)
+	assert.Equal(t, 3, result.Tables[0].Archived)
+	assert.Len(t, storage, len(result.Tables[0].Archives))
+	assert.Len(t, repo.rows, 3)
+	for _, id := range []string{"2", "3", "5"} {
+		assert.Contains(t, repo.rows, id)
+	}
+
+	leases.holder = "worker-2"
+	_, err = service.Archive(ctx)
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeConflict))
+
+	// 只恢复 35~45 天前的记录，已存在的记录跳过
+	add(1, "resolved", "warning", 40*day)
+	restored, err := service.Restore(ctx, &retention.RestoreRequest{
+		Table: retention.TableAlerts, From: now.Add(-45 * day), To: now.Add(-35 * day),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, restored.Matched)
+	assert.Equal(t, int64(0), restored.Restored)
+	assert.Equal(t, int64(1), restored.Skipped)
+
+	restored, err = service.Restore(ctx, &retention.RestoreRequest{
+		Table: retention.TableAlerts, From: now.Add(-90 * day), To: now,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Matched)
+	assert.Equal(t, int64(2), restored.Restored)
+	assert.Contains(t, repo.rows, "6")
+	assert.NotNil(t, repo.archives[0].RestoredAt)
+
+	_, err = service.Restore(ctx, &retention.RestoreRequest{Table: retention.TableAlerts, From: now, To: now.Add(day)})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeNotFound))
+	_, err = service.Restore(ctx, &retention.RestoreRequest{Table: "incidents", From: now, To: now.Add(day)})
+	assert.True(t, errors.IsErrorType(err, errors.ErrorTypeValidation))
+}
@@ -0,0 +1,55 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// conditionPattern 条件表达式，如 "cpu_usage > 90"、"up == 0"，左侧名称仅用于说明
+var conditionPattern = regexp.MustCompile(`^\s*(?:[a-zA-Z_:][\w:]*\s*)?(>=|<=|==|!=|>|<)\s*(-?[0-9.]+(?:[eE][-+]?[0-9]+)?)\s*$`)
+
+// Condition 对查询结果取值的阈值判断
+type Condition struct {
+	Operator  string
+	Threshold float64
+}
+
+// ParseCondition 解析条件表达式，表达式为空时查询返回的每条序列都视为满足条件
+func ParseCondition(expr string) (*Condition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	matches := conditionPattern.FindStringSubmatch(expr)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid condition expression: %q", expr)
+	}
+	threshold, err := strconv.ParseFloat(matches[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition threshold: %q", matches[2])
+	}
+	return &Condition{Operator: matches[1], Threshold: threshold}, nil
+}
+
+// Match 判断取值是否满足条件
+func (c *Condition) Match(value float64) bool {
+	if c == nil {
+		return true
+	}
+	switch c.Operator {
+	case ">":
+		return value > c.Threshold
+	case ">=":
+		return value >= c.Threshold
+	case "<":
+		return value < c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	case "==":
+		return value == c.Threshold
+	case "!=":
+		return value != c.Threshold
+	}
+	return false
+}
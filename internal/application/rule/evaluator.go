@@ -0,0 +1,261 @@
+package rule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/rule"
+	"alert_agent/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// Evaluator 规则评估器，执行规则查询并维护 pending/firing/resolved 状态
+type Evaluator struct {
+	clients rule.QueryClientFactory
+	states  rule.StateStore
+	sink    rule.AlertSink
+	logger  *zap.Logger
+}
+
+// NewEvaluator 创建规则评估器
+func NewEvaluator(clients rule.QueryClientFactory, states rule.StateStore, sink rule.AlertSink, logger *zap.Logger) *Evaluator {
+	return &Evaluator{
+		clients: clients,
+		states:  states,
+		sink:    sink,
+		logger:  logger,
+	}
+}
+
+// sample 查询结果中的一条序列
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// Evaluate 评估规则。查询失败时保持已有状态不变，避免误恢复。
+func (e *Evaluator) Evaluate(ctx context.Context, r *model.Rule, now time.Time) error {
+	condition, err := ParseCondition(r.ConditionExpr)
+	if err != nil {
+		return err
+	}
+
+	client, provider, err := e.clients.QueryClient(ctx, r.ProviderID)
+	if err != nil {
+		return fmt.Errorf("failed to get provider client: %w", err)
+	}
+	resp, err := client.Query(ctx, url.Values{
+		"query": {r.QueryExpr},
+		"time":  {strconv.FormatInt(now.Unix(), 10)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query provider: %w", err)
+	}
+	samples, err := parseSamples(resp.Data)
+	if err != nil {
+		return err
+	}
+
+	states, err := e.states.List(ctx, r.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load rule state: %w", err)
+	}
+
+	var errs []error
+	active := make(map[string]bool)
+	for _, s := range samples {
+		if !condition.Match(s.value) {
+			continue
+		}
+		fingerprint := labelsFingerprint(s.labels)
+		active[fingerprint] = true
+
+		state, exists := states[fingerprint]
+		if !exists {
+			state = &rule.SeriesState{
+				Fingerprint: fingerprint,
+				Labels:      s.labels,
+				State:       rule.AlertStatePending,
+				ActiveAt:    now,
+			}
+		}
+		state.Value = s.value
+		state.LastEvalAt = now
+
+		if state.State == rule.AlertStatePending && now.Sub(state.ActiveAt) >= time.Duration(r.For)*time.Second {
+			if err := e.fire(ctx, r, provider, state, now); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := e.states.Save(ctx, r.ID, state); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save rule state: %w", err))
+		}
+	}
+
+	// 不再满足条件的序列：pending 直接清除，firing 恢复告警
+	for fingerprint, state := range states {
+		if active[fingerprint] {
+			continue
+		}
+		if state.State == rule.AlertStateFiring && state.AlertID != 0 {
+			note := fmt.Sprintf("rule %q no longer matches, last value %s", r.Name, formatValue(state.Value))
+			if err := e.sink.ResolveAlert(ctx, state.AlertID, note); err != nil {
+				errs = append(errs, fmt.Errorf("failed to resolve alert %d: %w", state.AlertID, err))
+				continue
+			}
+			e.logger.Info("Rule alert resolved",
+				zap.Uint("rule_id", r.ID),
+				zap.Uint("alert_id", state.AlertID),
+				zap.String("fingerprint", fingerprint))
+		}
+		if err := e.states.Delete(ctx, r.ID, fingerprint); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete rule state: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// fire 为达到持续时长的序列创建告警
+func (e *Evaluator) fire(ctx context.Context, r *model.Rule, provider *model.Provider, state *rule.SeriesState, now time.Time) error {
+	labels := make(map[string]string, len(state.Labels)+1)
+	for k, v := range state.Labels {
+		labels[k] = v
+	}
+	labels["rule_id"] = strconv.FormatUint(uint64(r.ID), 10)
+	labelsJSON, _ := json.Marshal(labels)
+
+	level := alertLevel(r.Level)
+	alert := &model.Alert{
+		Name:     r.Name,
+		Title:    alertTitle(r, state.Labels),
+		Level:    level,
+		Severity: level,
+		Status:   model.AlertStatusNew,
+		Source:   provider.Name,
+		Content: fmt.Sprintf("%s\n查询表达式: %s\n触发条件: %s\n当前值: %s",
+			r.Description, r.QueryExpr, r.ConditionExpr, formatValue(state.Value)),
+		Labels: string(labelsJSON),
+		RuleID: r.ID,
+	}
+	if err := e.sink.CreateAlert(ctx, alert); err != nil {
+		// 保持 pending，下次评估重试
+		return fmt.Errorf("failed to create alert for rule %d: %w", r.ID, err)
+	}
+
+	state.State = rule.AlertStateFiring
+	state.AlertID = alert.ID
+	state.FiredAt = &now
+	e.logger.Info("Rule alert firing",
+		zap.Uint("rule_id", r.ID),
+		zap.Uint("alert_id", alert.ID),
+		zap.String("fingerprint", state.Fingerprint),
+		zap.Float64("value", state.Value))
+	return nil
+}
+
+// parseSamples 解析 vector 或 scalar 查询结果
+func parseSamples(data json.RawMessage) ([]sample, error) {
+	var result struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid query result: %w", err)
+	}
+
+	switch result.ResultType {
+	case "vector":
+		var vector []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]interface{}    `json:"value"`
+		}
+		if err := json.Unmarshal(result.Result, &vector); err != nil {
+			return nil, fmt.Errorf("invalid vector result: %w", err)
+		}
+		samples := make([]sample, 0, len(vector))
+		for _, v := range vector {
+			value, err := parseSampleValue(v.Value)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, sample{labels: v.Metric, value: value})
+		}
+		return samples, nil
+	case "scalar":
+		var scalar [2]interface{}
+		if err := json.Unmarshal(result.Result, &scalar); err != nil {
+			return nil, fmt.Errorf("invalid scalar result: %w", err)
+		}
+		value, err := parseSampleValue(scalar)
+		if err != nil {
+			return nil, err
+		}
+		return []sample{{labels: map[string]string{}, value: value}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported result type: %s", result.ResultType)
+	}
+}
+
+func parseSampleValue(pair [2]interface{}) (float64, error) {
+	s, ok := pair[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value: %v", pair[1])
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// labelsFingerprint 计算序列标签指纹
+func labelsFingerprint(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[name]))
+		h.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// alertLevel 将规则级别映射为告警级别，如 warning 映射为 medium
+func alertLevel(level string) string {
+	switch strings.ToLower(level) {
+	case model.AlertLevelCritical, model.AlertLevelHigh, model.AlertLevelMedium, model.AlertLevelLow:
+		return strings.ToLower(level)
+	case "error":
+		return model.AlertLevelHigh
+	case "info":
+		return model.AlertLevelLow
+	default:
+		return model.AlertLevelMedium
+	}
+}
+
+// alertTitle 告警标题，包含 instance 等区分序列的标签
+func alertTitle(r *model.Rule, labels map[string]string) string {
+	for _, key := range []string{"instance", "service", "job"} {
+		if v := labels[key]; v != "" {
+			return fmt.Sprintf("%s (%s=%s)", r.Name, key, v)
+		}
+	}
+	return r.Name
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
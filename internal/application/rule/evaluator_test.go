@@ -0,0 +1,125 @@
+package rule
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/rule"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/provider"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeQueryClient struct {
+	data string
+}
+
+func (c *fakeQueryClient) Query(ctx context.Context, params url.Values) (*provider.APIResponse, error) {
+	return &provider.APIResponse{Status: "success", Data: json.RawMessage(c.data)}, nil
+}
+
+type fakeClientFactory struct {
+	client *fakeQueryClient
+}
+
+func (f *fakeClientFactory) QueryClient(ctx context.Context, providerID uint) (rule.QueryClient, *model.Provider, error) {
+	return f.client, &model.Provider{Name: "prometheus"}, nil
+}
+
+type memoryStateStore struct {
+	states map[string]*rule.SeriesState
+}
+
+func (s *memoryStateStore) List(ctx context.Context, ruleID uint) (map[string]*rule.SeriesState, error) {
+	result := make(map[string]*rule.SeriesState, len(s.states))
+	for k, v := range s.states {
+		copied := *v
+		result[k] = &copied
+	}
+	return result, nil
+}
+
+func (s *memoryStateStore) Save(ctx context.Context, ruleID uint, state *rule.SeriesState) error {
+	copied := *state
+	s.states[state.Fingerprint] = &copied
+	return nil
+}
+
+func (s *memoryStateStore) Delete(ctx context.Context, ruleID uint, fingerprint string) error {
+	delete(s.states, fingerprint)
+	return nil
+}
+
+type recordingSink struct {
+	created  []*model.Alert
+	resolved []uint
+}
+
+func (s *recordingSink) CreateAlert(ctx context.Context, alert *model.Alert) error {
+	alert.ID = uint(len(s.created) + 1)
+	s.created = append(s.created, alert)
+	return nil
+}
+
+func (s *recordingSink) ResolveAlert(ctx context.Context, alertID uint, note string) error {
+	s.resolved = append(s.resolved, alertID)
+	return nil
+}
+
+func TestEvaluator_PendingFiringResolved(t *testing.T) {
+	client := &fakeQueryClient{data: `{"resultType":"vector","result":[{"metric":{"instance":"node-1"},"value":[0,"95"]}]}`}
+	states := &memoryStateStore{states: map[string]*rule.SeriesState{}}
+	sink := &recordingSink{}
+	evaluator := NewEvaluator(&fakeClientFactory{client: client}, states, sink, zap.NewNop())
+
+	r := &model.Rule{Name: "HighCPU", QueryExpr: "cpu_usage", ConditionExpr: "cpu_usage > 90", Level: "warning", For: 60}
+	r.ID = 7
+	start := time.Unix(1700000000, 0)
+
+	// 首次满足条件进入 pending
+	require.NoError(t, evaluator.Evaluate(context.Background(), r, start))
+	require.Len(t, states.states, 1)
+	for _, s := range states.states {
+		assert.Equal(t, rule.AlertStatePending, s.State)
+	}
+	assert.Empty(t, sink.created)
+
+	// 持续时长达到后触发
+	require.NoError(t, evaluator.Evaluate(context.Background(), r, start.Add(time.Minute)))
+	require.Len(t, sink.created, 1)
+	alert := sink.created[0]
+	assert.Equal(t, "HighCPU (instance=node-1)", alert.Title)
+	assert.Equal(t, model.AlertLevelMedium, alert.Level)
+	assert.Equal(t, uint(7), alert.RuleID)
+	assert.Contains(t, alert.Labels, `"rule_id":"7"`)
+
+	// 持续触发不重复创建告警
+	require.NoError(t, evaluator.Evaluate(context.Background(), r, start.Add(2*time.Minute)))
+	assert.Len(t, sink.created, 1)
+
+	// 不再满足条件后恢复
+	client.data = `{"resultType":"vector","result":[{"metric":{"instance":"node-1"},"value":[0,"40"]}]}`
+	require.NoError(t, evaluator.Evaluate(context.Background(), r, start.Add(3*time.Minute)))
+	assert.Equal(t, []uint{1}, sink.resolved)
+	assert.Empty(t, states.states)
+}
+
+func TestParseCondition(t *testing.T) {
+	c, err := ParseCondition("value >= 0.5")
+	require.NoError(t, err)
+	assert.True(t, c.Match(0.5))
+	assert.False(t, c.Match(0.4))
+
+	c, err = ParseCondition("")
+	require.NoError(t, err)
+	assert.True(t, c.Match(-1))
+
+	_, err = ParseCondition("value is high")
+	assert.Error(t, err)
+}
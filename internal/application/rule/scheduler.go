@@ -0,0 +1,159 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/rule"
+
+	"go.uber.org/zap"
+)
+
+// leaseKeyPrefix 规则评估租约键前缀
+const leaseKeyPrefix = "rule:eval:lease:"
+
+// maxConcurrentEvaluations 单个 worker 同时评估的规则数
+const maxConcurrentEvaluations = 8
+
+// Scheduler 规则评估调度器，每个评估周期按规则获取租约，仅评估持有租约的规则
+type Scheduler struct {
+	rules     rule.RuleSource
+	leases    rule.LeaseManager
+	evaluator *Evaluator
+	config    rule.EvaluationConfig
+	logger    *zap.Logger
+
+	mu   sync.Mutex
+	held map[uint]bool // 当前持有租约的规则
+}
+
+// NewScheduler 创建规则评估调度器
+func NewScheduler(rules rule.RuleSource, leases rule.LeaseManager, evaluator *Evaluator, config rule.EvaluationConfig, logger *zap.Logger) *Scheduler {
+	if config.Owner == "" {
+		hostname, _ := os.Hostname()
+		config.Owner = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return &Scheduler{
+		rules:     rules,
+		leases:    leases,
+		evaluator: evaluator,
+		config:    config,
+		logger:    logger,
+		held:      make(map[uint]bool),
+	}
+}
+
+// Run 周期性评估规则直到 ctx 取消，退出时释放持有的租约
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.logger.Info("Rule evaluation scheduler started",
+		zap.String("owner", s.config.Owner),
+		zap.Duration("interval", s.config.Interval))
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			s.releaseAll()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick 执行一轮评估
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	rules, err := s.rules.ListEnabledRules(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list enabled rules", zap.Error(err))
+		return
+	}
+
+	enabled := make(map[uint]bool, len(rules))
+	sem := make(chan struct{}, maxConcurrentEvaluations)
+	var wg sync.WaitGroup
+	for _, r := range rules {
+		enabled[r.ID] = true
+
+		acquired, err := s.leases.Acquire(ctx, leaseKey(r.ID), s.config.Owner, s.config.LeaseTTL)
+		if err != nil {
+			s.logger.Warn("Failed to acquire rule lease", zap.Uint("rule_id", r.ID), zap.Error(err))
+			continue
+		}
+		s.setHeld(r.ID, acquired)
+		if !acquired {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			evalCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+			defer cancel()
+			if err := s.evaluator.Evaluate(evalCtx, r, now); err != nil {
+				s.logger.Warn("Rule evaluation failed", zap.Uint("rule_id", r.ID), zap.String("rule", r.Name), zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 已禁用或删除的规则释放租约
+	s.mu.Lock()
+	var stale []uint
+	for id := range s.held {
+		if !enabled[id] {
+			stale = append(stale, id)
+		}
+	}
+	s.mu.Unlock()
+	for _, id := range stale {
+		s.release(ctx, id)
+	}
+}
+
+func (s *Scheduler) setHeld(id uint, held bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if held {
+		s.held[id] = true
+	} else {
+		delete(s.held, id)
+	}
+}
+
+func (s *Scheduler) release(ctx context.Context, id uint) {
+	if err := s.leases.Release(ctx, leaseKey(id), s.config.Owner); err != nil {
+		s.logger.Warn("Failed to release rule lease", zap.Uint("rule_id", id), zap.Error(err))
+	}
+	s.setHeld(id, false)
+}
+
+// releaseAll 释放全部租约，其他 worker 无需等待租约过期即可接管
+func (s *Scheduler) releaseAll() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.mu.Lock()
+	ids := make([]uint, 0, len(s.held))
+	for id := range s.held {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	for _, id := range ids {
+		s.release(ctx, id)
+	}
+}
+
+func leaseKey(ruleID uint) string {
+	return fmt.Sprintf("%s%d", leaseKeyPrefix, ruleID)
+}
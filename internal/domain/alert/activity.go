@@ -0,0 +1,129 @@
+package alert
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// ActivityType 告警动态类型
+type ActivityType string
+
+const (
+	ActivityCreated      ActivityType = "created"       // 告警触发，由告警创建时间生成
+	ActivityComment      ActivityType = "comment"       // 评论，可回复形成讨论串
+	ActivityAssignment   ActivityType = "assignment"    // 指派与转派处理人
+	ActivityStatusChange ActivityType = "status_change" // 状态变更
+	ActivityNotification ActivityType = "notification"  // 通知发送，由通知记录生成
+)
+
+// Activity 告警动态，评论、指派与状态变更持久化，创建与通知事件在查询时合并
+type Activity struct {
+	ID        uint                   `json:"id,omitempty" gorm:"primaryKey"`
+	AlertID   uint                   `json:"alert_id" gorm:"not null;index"`
+	Type      ActivityType           `json:"type" gorm:"type:varchar(30);not null"`
+	Actor     string                 `json:"actor" gorm:"type:varchar(100)"`
+	Message   string                 `json:"message" gorm:"type:text"`
+	ParentID  *uint                  `json:"parent_id,omitempty" gorm:"index"` // 回复的评论
+	Mentions  []string               `json:"mentions,omitempty" gorm:"type:text;serializer:json"`
+	Data      map[string]interface{} `json:"data,omitempty" gorm:"type:text;serializer:json"`
+	CreatedAt time.Time              `json:"created_at" gorm:"index"`
+
+	Replies []*Activity `json:"replies,omitempty" gorm:"-"`
+}
+
+// TableName 指定表名
+func (Activity) TableName() string {
+	return "alert_activities"
+}
+
+// mentionPattern 评论中的 @用户名
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][\w.-]*)`)
+
+// ParseMentions 解析评论中提到的用户，按出现顺序去重
+func ParseMentions(text string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		if name != "" && !seen[name] {
+			seen[name] = true
+			mentions = append(mentions, name)
+		}
+	}
+	return mentions
+}
+
+// CommentRequest 添加评论请求
+type CommentRequest struct {
+	Body     string `json:"body" binding:"required"`
+	ParentID *uint  `json:"parent_id"` // 回复的评论ID
+	Actor    string `json:"-"`         // 操作人，取自认证用户
+}
+
+// AssignRequest 指派请求，Assignee 为空表示取消指派
+type AssignRequest struct {
+	Assignee string `json:"assignee"`
+	Note     string `json:"note"`
+	Actor    string `json:"-"` // 操作人，取自认证用户
+}
+
+// StatusRequest 变更告警状态请求
+type StatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+	Actor  string `json:"-"` // 操作人，取自认证用户
+}
+
+// AlertDetail 告警详情与动态时间线
+type AlertDetail struct {
+	Alert    *model.AlertResponse `json:"alert"`
+	Assignee string               `json:"assignee,omitempty"`
+	Timeline []*Activity          `json:"timeline"`
+}
+
+// ActivityRepository 告警动态数据仓储接口
+type ActivityRepository interface {
+	// GetAlert 获取告警，不存在时返回 nil
+	GetAlert(ctx context.Context, id uint) (*model.Alert, error)
+
+	// Create 保存动态
+	Create(ctx context.Context, activities ...*Activity) error
+
+	// GetByID 获取动态，不存在时返回 nil
+	GetByID(ctx context.Context, id uint) (*Activity, error)
+
+	// ListByAlert 获取告警的动态，按时间正序
+	ListByAlert(ctx context.Context, alertID uint) ([]*Activity, error)
+
+	// ListNotifyRecords 获取告警的通知记录，按时间正序
+	ListNotifyRecords(ctx context.Context, alertID uint) ([]*model.NotifyRecord, error)
+}
+
+// ActivityRecorder 记录其他操作产生的告警动态，如批量操作
+type ActivityRecorder interface {
+	Record(ctx context.Context, activities ...*Activity)
+}
+
+// ActivityService 告警动态服务接口
+type ActivityService interface {
+	ActivityRecorder
+
+	// GetDetail 获取告警详情与时间线
+	GetDetail(ctx context.Context, alertID uint) (*AlertDetail, error)
+
+	// Timeline 获取告警时间线，回复嵌套在所属评论下
+	Timeline(ctx context.Context, alertID uint) ([]*Activity, error)
+
+	// AddComment 添加评论或回复
+	AddComment(ctx context.Context, alertID uint, req *CommentRequest) (*Activity, error)
+
+	// Assign 指派或转派告警
+	Assign(ctx context.Context, alertID uint, req *AssignRequest) (*Activity, error)
+
+	// ChangeStatus 变更告警状态
+	ChangeStatus(ctx context.Context, alertID uint, req *StatusRequest) (*Activity, error)
+}
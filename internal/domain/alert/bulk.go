@@ -0,0 +1,134 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// BulkAction 批量操作类型
+type BulkAction string
+
+const (
+	BulkActionAcknowledge BulkAction = "acknowledge"
+	BulkActionResolve     BulkAction = "resolve"
+	BulkActionAssign      BulkAction = "assign"
+	BulkActionNote        BulkAction = "note"
+	BulkActionReanalyze   BulkAction = "reanalyze"
+)
+
+// IsValid 检查批量操作类型是否有效
+func (a BulkAction) IsValid() bool {
+	switch a {
+	case BulkActionAcknowledge, BulkActionResolve, BulkActionAssign, BulkActionNote, BulkActionReanalyze:
+		return true
+	}
+	return false
+}
+
+// BulkItemStatus 单条告警的处理结果
+type BulkItemStatus string
+
+const (
+	BulkItemPending  BulkItemStatus = "pending" // 预演时表示将被处理
+	BulkItemUpdated  BulkItemStatus = "updated"
+	BulkItemSkipped  BulkItemStatus = "skipped"
+	BulkItemFailed   BulkItemStatus = "failed"
+	BulkItemNotFound BulkItemStatus = "not_found"
+)
+
+// BulkSelector 批量操作的告警选择器，同时指定时取交集，不能都为空
+type BulkSelector struct {
+	IDs   []uint `json:"ids,omitempty"`
+	Query string `json:"query,omitempty"` // 告警搜索查询语句
+}
+
+// BulkRequest 批量操作请求
+type BulkRequest struct {
+	Action   BulkAction   `json:"action" binding:"required"`
+	Selector BulkSelector `json:"selector"`
+	Assignee string       `json:"assignee,omitempty"`  // assign 操作的处理人
+	Note     string       `json:"note,omitempty"`      // note 操作必填，其他操作可选，追加到处理备注
+	DryRun   bool         `json:"dry_run"`             // 只统计匹配数量与预期结果，不做修改
+	MaxItems int          `json:"max_items,omitempty"` // 本次最多处理的告警数，不能超过服务端上限
+}
+
+// BulkActor 批量操作的执行者
+type BulkActor struct {
+	UserID    string
+	Username  string
+	IPAddress string
+	UserAgent string
+}
+
+// BulkItemResult 单条告警的批量操作结果
+type BulkItemResult struct {
+	AlertID uint           `json:"alert_id"`
+	Status  BulkItemStatus `json:"status"`
+	Reason  string         `json:"reason,omitempty"`
+	TaskID  string         `json:"task_id,omitempty"` // reanalyze 创建的分析任务
+}
+
+// BulkResult 批量操作结果
+type BulkResult struct {
+	Action       BulkAction        `json:"action"`
+	DryRun       bool              `json:"dry_run"`
+	Matched      int64             `json:"matched"`
+	Limit        int               `json:"limit"`
+	ExceedsLimit bool              `json:"exceeds_limit"` // 仅预演时返回，超出上限时不列出明细
+	Succeeded    int               `json:"succeeded"`
+	Skipped      int               `json:"skipped"`
+	Failed       int               `json:"failed"`
+	Items        []*BulkItemResult `json:"items"`
+}
+
+// BulkSelection 解析后的告警选择条件
+type BulkSelection struct {
+	IDs  []uint
+	Expr QueryExpr
+}
+
+// BulkAuditRecord 批量操作审计记录
+type BulkAuditRecord struct {
+	Actor     BulkActor
+	Action    BulkAction
+	Selector  BulkSelector
+	Matched   int64
+	Succeeded int
+	Skipped   int
+	Failed    int
+	AlertIDs  []uint
+	CreatedAt time.Time
+}
+
+// BulkRepository 批量操作数据仓储接口
+type BulkRepository interface {
+	// Count 统计匹配的告警数量
+	Count(ctx context.Context, selection *BulkSelection) (int64, error)
+
+	// List 获取匹配的告警，按ID正序
+	List(ctx context.Context, selection *BulkSelection, limit int) ([]*model.Alert, error)
+
+	// AppendNote 向告警的处理备注追加一行
+	AppendNote(ctx context.Context, ids []uint, line string) error
+
+	// RecordAudit 记录批量操作审计日志
+	RecordAudit(ctx context.Context, record *BulkAuditRecord) error
+}
+
+// BulkService 告警批量操作服务接口
+type BulkService interface {
+	// Execute 执行或预演批量操作
+	Execute(ctx context.Context, req *BulkRequest, actor BulkActor) (*BulkResult, error)
+}
+
+// BulkConfig 批量操作配置
+type BulkConfig struct {
+	MaxItems int `json:"max_items"` // 单次最多处理的告警数，防止误操作整表
+}
+
+// DefaultBulkConfig 默认批量操作配置
+func DefaultBulkConfig() BulkConfig {
+	return BulkConfig{MaxItems: 1000}
+}
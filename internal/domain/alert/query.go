@@ -0,0 +1,449 @@
+package alert
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 查询语言限制
+const (
+	MaxQueryLength     = 4096
+	maxQueryDepth      = 32
+	maxQueryConditions = 64
+)
+
+// QueryOp 查询比较运算符
+type QueryOp string
+
+const (
+	OpEq       QueryOp = "="
+	OpNe       QueryOp = "!="
+	OpRegex    QueryOp = "=~"
+	OpNotRegex QueryOp = "!~"
+	OpGt       QueryOp = ">"
+	OpGte      QueryOp = ">="
+	OpLt       QueryOp = "<"
+	OpLte      QueryOp = "<="
+	OpContains QueryOp = ":"
+)
+
+// FieldKind 字段值类型
+type FieldKind int
+
+const (
+	FieldString FieldKind = iota
+	FieldNumber
+	FieldTime
+)
+
+// SearchField 可查询的告警字段
+type SearchField struct {
+	Name     string    `json:"name"`
+	Column   string    `json:"-"` // 数据库列名，标签字段为空
+	Label    string    `json:"-"` // 标签名，仅标签字段
+	Kind     FieldKind `json:"kind"`
+	Sortable bool      `json:"sortable"`
+}
+
+// IsLabel 是否为标签字段
+func (f SearchField) IsLabel() bool {
+	return f.Label != ""
+}
+
+// labelFieldPrefix 标签字段前缀，如 labels.namespace
+const labelFieldPrefix = "labels."
+
+var labelNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-/]*$`)
+
+// searchFields 可查询字段白名单，列名只来自这里，不拼接用户输入
+var searchFields = map[string]SearchField{
+	"id":           {Name: "id", Column: "id", Kind: FieldNumber, Sortable: true},
+	"name":         {Name: "name", Column: "name", Kind: FieldString, Sortable: true},
+	"title":        {Name: "title", Column: "title", Kind: FieldString, Sortable: true},
+	"content":      {Name: "content", Column: "content", Kind: FieldString},
+	"level":        {Name: "level", Column: "level", Kind: FieldString, Sortable: true},
+	"severity":     {Name: "severity", Column: "severity", Kind: FieldString, Sortable: true},
+	"status":       {Name: "status", Column: "status", Kind: FieldString, Sortable: true},
+	"source":       {Name: "source", Column: "source", Kind: FieldString, Sortable: true},
+	"handler":      {Name: "handler", Column: "handler", Kind: FieldString},
+	"rule_id":      {Name: "rule_id", Column: "rule_id", Kind: FieldNumber, Sortable: true},
+	"group_id":     {Name: "group_id", Column: "group_id", Kind: FieldNumber},
+	"notify_count": {Name: "notify_count", Column: "notify_count", Kind: FieldNumber, Sortable: true},
+	"created_at":   {Name: "created_at", Column: "created_at", Kind: FieldTime, Sortable: true},
+	"updated_at":   {Name: "updated_at", Column: "updated_at", Kind: FieldTime, Sortable: true},
+	"handle_time":  {Name: "handle_time", Column: "handle_time", Kind: FieldTime},
+}
+
+// LookupSearchField 查找可查询字段，labels.<name> 表示告警标签
+func LookupSearchField(name string) (SearchField, bool) {
+	if strings.HasPrefix(name, labelFieldPrefix) {
+		label := strings.TrimPrefix(name, labelFieldPrefix)
+		if !labelNamePattern.MatchString(label) {
+			return SearchField{}, false
+		}
+		return SearchField{Name: name, Label: label, Kind: FieldString, Sortable: true}, true
+	}
+	field, ok := searchFields[name]
+	return field, ok
+}
+
+// QueryExpr 查询表达式
+type QueryExpr interface {
+	String() string
+}
+
+// AndExpr 逻辑与
+type AndExpr struct {
+	Left, Right QueryExpr
+}
+
+func (e *AndExpr) String() string {
+	return fmt.Sprintf("(%s AND %s)", e.Left, e.Right)
+}
+
+// OrExpr 逻辑或
+type OrExpr struct {
+	Left, Right QueryExpr
+}
+
+func (e *OrExpr) String() string {
+	return fmt.Sprintf("(%s OR %s)", e.Left, e.Right)
+}
+
+// NotExpr 逻辑非
+type NotExpr struct {
+	Expr QueryExpr
+}
+
+func (e *NotExpr) String() string {
+	return fmt.Sprintf("NOT %s", e.Expr)
+}
+
+// Condition 字段条件，Value 按字段类型为 string、int64 或 time.Time
+type Condition struct {
+	Field SearchField
+	Op    QueryOp
+	Value interface{}
+	Raw   string
+}
+
+func (c *Condition) String() string {
+	return fmt.Sprintf("%s%s%s", c.Field.Name, c.Op, strconv.Quote(c.Raw))
+}
+
+// TextExpr 全文检索，匹配名称、标题与内容
+type TextExpr struct {
+	Text string
+}
+
+func (e *TextExpr) String() string {
+	return strconv.Quote(e.Text)
+}
+
+// QueryError 查询语法错误
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query error at position %d: %s", e.Pos, e.Msg)
+}
+
+// ParseQuery 解析告警查询语言，空查询返回 nil
+//
+// 语法示例：severity=critical AND labels.namespace=~"prod-.*" AND NOT title:"disk"
+//   - 运算符：= != =~ !~ > >= < <= 以及 : （包含）
+//   - 逻辑：AND、OR、NOT 与括号，相邻条件默认为 AND
+//   - 不带字段的词或引号字符串为全文检索
+//   - 时间值为 RFC3339（需加引号）或相对时间，如 -1h、-30m
+func ParseQuery(query string) (QueryExpr, error) {
+	return ParseQueryAt(query, time.Now())
+}
+
+// ParseQueryAt 以指定时间为基准解析查询，用于相对时间
+func ParseQueryAt(query string, now time.Time) (QueryExpr, error) {
+	if len(query) > MaxQueryLength {
+		return nil, &QueryError{Pos: MaxQueryLength, Msg: fmt.Sprintf("query exceeds %d characters", MaxQueryLength)}
+	}
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &queryParser{tokens: tokens, now: now}
+	expr, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenLParen
+	tokenRParen
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexQuery 词法分析
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '"':
+			start := i
+			var b strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					b.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &QueryError{Pos: start, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, queryToken{kind: tokenString, text: b.String(), pos: start})
+		case strings.ContainsRune("=!~<>:", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && strings.ContainsRune("=~", runes[i+1]) {
+				op += string(runes[i+1])
+			}
+			switch QueryOp(op) {
+			case OpEq, OpNe, OpRegex, OpNotRegex, OpGt, OpGte, OpLt, OpLte, OpContains:
+			default:
+				// 仅取单字符运算符，如 >~ 中的 >
+				op = string(r)
+				if QueryOp(op) != OpEq && QueryOp(op) != OpGt && QueryOp(op) != OpLt && QueryOp(op) != OpContains {
+					return nil, &QueryError{Pos: start, Msg: fmt.Sprintf("unknown operator %q", op)}
+				}
+			}
+			tokens = append(tokens, queryToken{kind: tokenOp, text: op, pos: start})
+			i += len([]rune(op))
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()\"=!~<>:", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenWord, text: string(runes[start:i]), pos: start})
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+type queryParser struct {
+	tokens     []queryToken
+	pos        int
+	conditions int
+	now        time.Time
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) isKeyword(tok queryToken, keyword string) bool {
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *queryParser) parseOr(depth int) (QueryExpr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &OrExpr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd(depth int) (QueryExpr, error) {
+	left, err := p.parseNot(depth)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if p.isKeyword(tok, "AND") {
+			p.next()
+		} else if tok.kind == tokenEOF || tok.kind == tokenRParen || p.isKeyword(tok, "OR") {
+			return left, nil
+		}
+		right, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &AndExpr{Left: left, Right: right}
+	}
+}
+
+func (p *queryParser) parseNot(depth int) (QueryExpr, error) {
+	if depth > maxQueryDepth {
+		return nil, &QueryError{Pos: p.peek().pos, Msg: "query is nested too deeply"}
+	}
+	if p.isKeyword(p.peek(), "NOT") {
+		p.next()
+		expr, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Expr: expr}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *queryParser) parsePrimary(depth int) (QueryExpr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenLParen:
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &QueryError{Pos: closing.pos, Msg: "missing closing parenthesis"}
+		}
+		return expr, nil
+	case tokenWord, tokenString:
+		if p.conditions++; p.conditions > maxQueryConditions {
+			return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("query has more than %d conditions", maxQueryConditions)}
+		}
+		if p.peek().kind == tokenOp {
+			if tok.kind != tokenWord {
+				return nil, &QueryError{Pos: tok.pos, Msg: "field name must not be quoted"}
+			}
+			return p.parseCondition(tok)
+		}
+		if tok.kind == tokenWord && (p.isKeyword(tok, "AND") || p.isKeyword(tok, "OR")) {
+			return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", strings.ToUpper(tok.text))}
+		}
+		return &TextExpr{Text: tok.text}, nil
+	case tokenEOF:
+		return nil, &QueryError{Pos: tok.pos, Msg: "unexpected end of query"}
+	default:
+		return nil, &QueryError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+}
+
+func (p *queryParser) parseCondition(fieldTok queryToken) (QueryExpr, error) {
+	field, ok := LookupSearchField(fieldTok.text)
+	if !ok {
+		return nil, &QueryError{Pos: fieldTok.pos, Msg: fmt.Sprintf("unknown field %q", fieldTok.text)}
+	}
+	opTok := p.next()
+	op := QueryOp(opTok.text)
+	valueTok := p.next()
+	if valueTok.kind != tokenWord && valueTok.kind != tokenString {
+		return nil, &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("missing value for %s", field.Name)}
+	}
+
+	switch op {
+	case OpRegex, OpNotRegex, OpContains:
+		if field.Kind != FieldString {
+			return nil, &QueryError{Pos: opTok.pos, Msg: fmt.Sprintf("operator %s requires a text field", op)}
+		}
+	case OpGt, OpGte, OpLt, OpLte:
+		if field.Kind == FieldString {
+			return nil, &QueryError{Pos: opTok.pos, Msg: fmt.Sprintf("operator %s requires a numeric or time field", op)}
+		}
+	}
+
+	cond := &Condition{Field: field, Op: op, Raw: valueTok.text}
+	switch field.Kind {
+	case FieldNumber:
+		n, err := strconv.ParseInt(valueTok.text, 10, 64)
+		if err != nil {
+			return nil, &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("%s expects a number", field.Name)}
+		}
+		cond.Value = n
+	case FieldTime:
+		t, err := parseQueryTime(valueTok.text, p.now)
+		if err != nil {
+			return nil, &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("%s expects an RFC3339 or relative time", field.Name)}
+		}
+		cond.Value = t
+	default:
+		if op == OpRegex || op == OpNotRegex {
+			if _, err := regexp.Compile(valueTok.text); err != nil {
+				return nil, &QueryError{Pos: valueTok.pos, Msg: fmt.Sprintf("invalid regular expression: %v", err)}
+			}
+		}
+		cond.Value = valueTok.text
+	}
+	return cond, nil
+}
+
+// parseQueryTime 解析 RFC3339、日期或相对时间（如 -1h、now-30m）
+func parseQueryTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if value == "now" {
+		return now, nil
+	}
+	relative := strings.TrimPrefix(value, "now")
+	if strings.HasPrefix(relative, "-") {
+		d, err := time.ParseDuration(relative[1:])
+		if err == nil {
+			return now.Add(-d), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
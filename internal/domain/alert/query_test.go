@@ -0,0 +1,79 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	expr, err := ParseQueryAt(`severity=critical AND labels.namespace=~"prod-.*" AND NOT title:"disk"`, now)
+	require.NoError(t, err)
+	assert.Equal(t, `((severity="critical" AND labels.namespace=~"prod-.*") AND NOT title:"disk")`, expr.String())
+
+	// 相邻条件默认 AND，OR 优先级低于 AND，不带字段的词为全文检索
+	expr, err = ParseQueryAt(`status=new OR (rule_id>=3 created_at>-1h) timeout`, now)
+	require.NoError(t, err)
+	assert.Equal(t, `(status="new" OR ((rule_id>="3" AND created_at>"-1h") AND "timeout"))`, expr.String())
+	or := expr.(*OrExpr)
+	and := or.Right.(*AndExpr).Left.(*AndExpr)
+	assert.Equal(t, int64(3), and.Left.(*Condition).Value)
+	assert.Equal(t, now.Add(-time.Hour), and.Right.(*Condition).Value)
+
+	expr, err = ParseQueryAt("  ", now)
+	require.NoError(t, err)
+	assert.Nil(t, expr)
+
+	for query, msg := range map[string]string{
+		`labels=foo`:                 `unknown field "labels"`,
+		`password="x"`:               `unknown field "password"`,
+		`labels.a'b="x"`:             "unknown field",
+		`severity>critical`:          "requires a numeric or time field",
+		`rule_id:1`:                  "requires a text field",
+		`rule_id=abc`:                "expects a number",
+		`created_at>yesterday`:       "expects an RFC3339 or relative time",
+		`title=~"("`:                 "invalid regular expression",
+		`(status=new`:                "missing closing parenthesis",
+		`status=`:                    "missing value",
+		`title:"disk`:                "unterminated string",
+		`status=new AND`:             "unexpected end of query",
+		`status=new ) OR level=high`: `unexpected ")"`,
+		`status!new`:                 `unknown operator "!"`,
+	} {
+		_, err := ParseQueryAt(query, now)
+		require.Error(t, err, query)
+		assert.Contains(t, err.Error(), msg, query)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort, err := ParseSort("-created_at")
+	require.NoError(t, err)
+	created := time.Date(2024, 5, 1, 12, 0, 0, 123000000, time.UTC)
+
+	cursor := NewCursor(sort, &model.Alert{ID: 42, CreatedAt: created})
+	decoded, err := DecodeCursor(cursor.Encode(), sort)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), decoded.ID)
+	assert.True(t, created.Equal(decoded.Value.(time.Time)))
+
+	// 游标与排序方式不一致时拒绝
+	other, err := ParseSort("labels.team")
+	require.NoError(t, err)
+	_, err = DecodeCursor(cursor.Encode(), other)
+	assert.Error(t, err)
+
+	labelCursor := NewCursor(other, &model.Alert{ID: 7, Labels: `{"team":"sre"}`})
+	decoded, err = DecodeCursor(labelCursor.Encode(), other)
+	require.NoError(t, err)
+	assert.Equal(t, "sre", decoded.Value)
+
+	_, err = ParseSort("content")
+	assert.Error(t, err)
+}
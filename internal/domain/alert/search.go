@@ -0,0 +1,268 @@
+package alert
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// 搜索分页限制
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 500
+)
+
+// SortSpec 排序方式，相同值按ID排序保证分页稳定
+type SortSpec struct {
+	Field SearchField
+	Desc  bool
+}
+
+// String 返回排序表达式，降序以 - 开头
+func (s SortSpec) String() string {
+	if s.Desc {
+		return "-" + s.Field.Name
+	}
+	return s.Field.Name
+}
+
+// DefaultSort 默认按创建时间倒序
+func DefaultSort() SortSpec {
+	return SortSpec{Field: searchFields["created_at"], Desc: true}
+}
+
+// ParseSort 解析排序表达式，如 -created_at、severity、labels.team
+func ParseSort(sort string) (SortSpec, error) {
+	sort = strings.TrimSpace(sort)
+	if sort == "" {
+		return DefaultSort(), nil
+	}
+	spec := SortSpec{}
+	switch {
+	case strings.HasPrefix(sort, "-"):
+		spec.Desc = true
+		sort = sort[1:]
+	case strings.HasPrefix(sort, "+"):
+		sort = sort[1:]
+	}
+	field, ok := LookupSearchField(sort)
+	if !ok || !field.Sortable {
+		return SortSpec{}, fmt.Errorf("field %q is not sortable", sort)
+	}
+	spec.Field = field
+	return spec, nil
+}
+
+// Cursor 分页游标，记录上一页最后一条告警的排序值与ID
+type Cursor struct {
+	Sort  string      `json:"s"`
+	Value interface{} `json:"v"`
+	ID    uint        `json:"id"`
+}
+
+// NewCursor 根据排序方式与告警生成游标
+func NewCursor(sort SortSpec, alert *model.Alert) *Cursor {
+	return &Cursor{Sort: sort.String(), Value: SortValue(sort.Field, alert), ID: alert.ID}
+}
+
+// Encode 编码游标
+func (c *Cursor) Encode() string {
+	value := c.Value
+	if t, ok := value.(time.Time); ok {
+		value = t.Format(time.RFC3339Nano)
+	}
+	data, _ := json.Marshal(&Cursor{Sort: c.Sort, Value: value, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解码游标并按排序字段类型还原排序值，游标必须与排序方式一致
+func DecodeCursor(encoded string, sort SortSpec) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var raw struct {
+		Sort  string          `json:"s"`
+		Value json.RawMessage `json:"v"`
+		ID    uint            `json:"id"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if raw.Sort != sort.String() {
+		return nil, fmt.Errorf("cursor was created for sort %q", raw.Sort)
+	}
+
+	cursor := &Cursor{Sort: raw.Sort, ID: raw.ID}
+	switch sort.Field.Kind {
+	case FieldNumber:
+		var n int64
+		if err := json.Unmarshal(raw.Value, &n); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		cursor.Value = n
+	case FieldTime:
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		cursor.Value = t
+	default:
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+		cursor.Value = s
+	}
+	return cursor, nil
+}
+
+// SortValue 获取告警在排序字段上的值，与数据库中的取值保持一致
+func SortValue(field SearchField, alert *model.Alert) interface{} {
+	if field.IsLabel() {
+		var labels map[string]interface{}
+		if err := json.Unmarshal([]byte(alert.Labels), &labels); err != nil {
+			return ""
+		}
+		switch v := labels[field.Label].(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	switch field.Name {
+	case "id":
+		return int64(alert.ID)
+	case "name":
+		return alert.Name
+	case "title":
+		return alert.Title
+	case "level":
+		return alert.Level
+	case "severity":
+		return alert.Severity
+	case "status":
+		return alert.Status
+	case "source":
+		return alert.Source
+	case "rule_id":
+		return int64(alert.RuleID)
+	case "notify_count":
+		return int64(alert.NotifyCount)
+	case "created_at":
+		return alert.CreatedAt
+	case "updated_at":
+		return alert.UpdatedAt
+	}
+	return nil
+}
+
+// SearchQuery 仓储层搜索条件
+type SearchQuery struct {
+	Expr  QueryExpr
+	Sort  SortSpec
+	After *Cursor
+	Limit int
+}
+
+// SearchRepository 告警搜索仓储接口
+type SearchRepository interface {
+	// Search 按查询表达式搜索告警
+	Search(ctx context.Context, query *SearchQuery) ([]*model.Alert, error)
+}
+
+// SearchRequest 告警搜索请求
+type SearchRequest struct {
+	Query  string `json:"query" form:"q"`
+	Sort   string `json:"sort" form:"sort"`
+	Cursor string `json:"cursor" form:"cursor"`
+	Limit  int    `json:"limit" form:"limit"`
+}
+
+// SearchResult 告警搜索结果
+type SearchResult struct {
+	Alerts     []*model.AlertResponse `json:"alerts"`
+	Query      string                 `json:"query"`
+	Sort       string                 `json:"sort"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	HasMore    bool                   `json:"has_more"`
+}
+
+// SavedSearch 用户保存的搜索
+type SavedSearch struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Owner       string    `json:"owner" gorm:"size:100;not null;uniqueIndex:idx_alert_saved_search_owner_name"`
+	Name        string    `json:"name" gorm:"size:100;not null;uniqueIndex:idx_alert_saved_search_owner_name"`
+	Description string    `json:"description,omitempty" gorm:"type:text"`
+	Query       string    `json:"query" gorm:"type:text;not null"`
+	Sort        string    `json:"sort,omitempty" gorm:"size:100"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SavedSearch) TableName() string {
+	return "alert_saved_searches"
+}
+
+// SavedSearchRequest 创建或更新保存的搜索
+type SavedSearchRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	Query       string `json:"query"`
+	Sort        string `json:"sort,omitempty"`
+}
+
+// SavedSearchRepository 保存的搜索仓储接口
+type SavedSearchRepository interface {
+	// Create 创建保存的搜索
+	Create(ctx context.Context, search *SavedSearch) error
+
+	// GetByID 获取保存的搜索，不存在时返回 nil
+	GetByID(ctx context.Context, id uint) (*SavedSearch, error)
+
+	// GetByName 按用户与名称获取保存的搜索，不存在时返回 nil
+	GetByName(ctx context.Context, owner, name string) (*SavedSearch, error)
+
+	// ListByOwner 获取用户保存的搜索
+	ListByOwner(ctx context.Context, owner string) ([]*SavedSearch, error)
+
+	// Update 更新保存的搜索
+	Update(ctx context.Context, search *SavedSearch) error
+
+	// Delete 删除保存的搜索
+	Delete(ctx context.Context, id uint) error
+}
+
+// SearchService 告警搜索服务接口
+type SearchService interface {
+	// Search 按查询语言搜索告警
+	Search(ctx context.Context, req *SearchRequest) (*SearchResult, error)
+
+	// ListSavedSearches 获取用户保存的搜索
+	ListSavedSearches(ctx context.Context, owner string) ([]*SavedSearch, error)
+
+	// CreateSavedSearch 保存搜索
+	CreateSavedSearch(ctx context.Context, owner string, req *SavedSearchRequest) (*SavedSearch, error)
+
+	// UpdateSavedSearch 更新保存的搜索
+	UpdateSavedSearch(ctx context.Context, owner string, id uint, req *SavedSearchRequest) (*SavedSearch, error)
+
+	// DeleteSavedSearch 删除保存的搜索
+	DeleteSavedSearch(ctx context.Context, owner string, id uint) error
+
+	// RunSavedSearch 执行保存的搜索
+	RunSavedSearch(ctx context.Context, owner string, id uint, cursor string, limit int) (*SearchResult, error)
+}
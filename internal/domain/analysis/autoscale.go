@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"context"
+	"time"
+)
+
+// AutoscalerConfig 分析工作器自动扩缩容配置
+type AutoscalerConfig struct {
+	MinWorkers        int            // 最少工作器数
+	MaxWorkers        int            // 最多工作器数
+	TasksPerWorker    int            // 每个工作器承担的积压任务数
+	MaxTaskAge        time.Duration  // 最老任务等待超过该时长时至少增加一个工作器
+	Interval          time.Duration  // 检查队列的间隔
+	ScaleUpCooldown   time.Duration  // 两次扩容的最短间隔
+	ScaleDownCooldown time.Duration  // 任意一次调整后到缩容的最短间隔
+	ModelConcurrency  map[string]int // 每个模型的最大并发调用数
+}
+
+// DefaultAutoscalerConfig 默认扩缩容配置
+func DefaultAutoscalerConfig() AutoscalerConfig {
+	return AutoscalerConfig{
+		MinWorkers:        1,
+		MaxWorkers:        8,
+		TasksPerWorker:    5,
+		MaxTaskAge:        time.Minute,
+		Interval:          10 * time.Second,
+		ScaleUpCooldown:   30 * time.Second,
+		ScaleDownCooldown: 5 * time.Minute,
+	}
+}
+
+// 扩缩容方向
+const (
+	ScaleUp   = "up"
+	ScaleDown = "down"
+	ScaleHold = "hold" // 需要调整但处于冷却期
+)
+
+// ScalingDecision 一次扩缩容决策
+type ScalingDecision struct {
+	Time            time.Time `json:"time"`
+	Direction       string    `json:"direction"`
+	From            int       `json:"from"`
+	To              int       `json:"to"`
+	Desired         int       `json:"desired"` // 按队列计算、未受冷却限制的目标数
+	Reason          string    `json:"reason"`
+	PendingCount    int64     `json:"pending_count"`
+	ProcessingCount int64     `json:"processing_count"`
+	OldestTaskAgeMs int64     `json:"oldest_task_age_ms"`
+}
+
+// ModelSlots 模型的并发上限与当前调用数，上限为 0 表示不限制
+type ModelSlots struct {
+	Limit    int `json:"limit"`
+	InFlight int `json:"in_flight"`
+}
+
+// ModelLimiter 按模型限制并发调用
+type ModelLimiter interface {
+	// Snapshot 返回所有模型的并发情况
+	Snapshot() map[string]ModelSlots
+}
+
+// AutoscalerStatus 单个 worker 进程的扩缩容状态
+type AutoscalerStatus struct {
+	Instance      string                `json:"instance"`
+	MinWorkers    int                   `json:"min_workers"`
+	MaxWorkers    int                   `json:"max_workers"`
+	Capacity      int                   `json:"capacity"` // 受模型并发限制后的最大工作器数
+	Current       int                   `json:"current"`
+	Desired       int                   `json:"desired"`
+	LastScaleUp   *time.Time            `json:"last_scale_up,omitempty"`
+	LastScaleDown *time.Time            `json:"last_scale_down,omitempty"`
+	Decisions     []*ScalingDecision    `json:"decisions"` // 最近的决策，按时间倒序
+	Models        map[string]ModelSlots `json:"models,omitempty"`
+	Workers       []*WorkerStatus       `json:"workers"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+// AutoscalerStatusStore 保存各 worker 进程的扩缩容状态，供 API 查询
+type AutoscalerStatusStore interface {
+	// Save 保存进程的状态
+	Save(ctx context.Context, status *AutoscalerStatus) error
+
+	// List 获取仍在上报的进程状态
+	List(ctx context.Context) ([]*AutoscalerStatus, error)
+}
+
+// AutoscalerMetrics 扩缩容指标
+type AutoscalerMetrics interface {
+	// ObserveStatus 记录队列积压与工作器数
+	ObserveStatus(status *AutoscalerStatus, queue *QueueStatus)
+
+	// RecordDecision 记录扩缩容决策
+	RecordDecision(decision *ScalingDecision)
+}
+
+// WorkerPoolStatus 工作器状态，包含本进程的工作器与各 worker 进程上报的扩缩容状态
+type WorkerPoolStatus struct {
+	Workers     []*WorkerStatus     `json:"workers"`
+	Autoscalers []*AutoscalerStatus `json:"autoscalers"`
+}
@@ -0,0 +1,146 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BudgetMode 租户当日的模型预算状态
+type BudgetMode string
+
+const (
+	BudgetModeNormal    BudgetMode = "normal"    // 使用分析类型配置的模型
+	BudgetModeDowngrade BudgetMode = "downgrade" // 用量超过降级阈值，使用低成本模型
+	BudgetModeFallback  BudgetMode = "fallback"  // 预算耗尽，使用规则分析，不调用模型
+)
+
+// UsageDateLayout 用量统计的日期格式
+const UsageDateLayout = "2006-01-02"
+
+// DefaultTenant 无法识别租户时使用的租户
+const DefaultTenant = "default"
+
+// BudgetConfig 令牌预算配置
+type BudgetConfig struct {
+	DailyTokens       int64            // 每个租户每日令牌预算，0 表示不限制
+	TenantDailyTokens map[string]int64 // 按租户覆盖每日预算
+	DowngradeRatio    float64          // 用量达到预算的该比例后切换到低成本模型
+	TenantLabel       string           // 告警标签中标识租户的标签名
+}
+
+// DefaultBudgetConfig 默认令牌预算配置
+func DefaultBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		DowngradeRatio: 0.8,
+		TenantLabel:    "tenant",
+	}
+}
+
+// ParseTenantBudgets 解析按租户指定的每日预算，格式为 tenant:tokens，逗号分隔
+func ParseTenantBudgets(spec string) (map[string]int64, error) {
+	budgets := make(map[string]int64)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid tenant budget %q, expected tenant:tokens", item)
+		}
+		tokens, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || tokens < 0 {
+			return nil, fmt.Errorf("invalid token budget in %q", item)
+		}
+		budgets[strings.TrimSpace(parts[0])] = tokens
+	}
+	return budgets, nil
+}
+
+// BudgetFor 获取租户每日预算，0 表示不限制
+func (c BudgetConfig) BudgetFor(tenant string) int64 {
+	if budget, ok := c.TenantDailyTokens[tenant]; ok {
+		return budget
+	}
+	return c.DailyTokens
+}
+
+// Mode 根据当日已用令牌计算预算状态
+func (c BudgetConfig) Mode(tenant string, used int64) BudgetMode {
+	budget := c.BudgetFor(tenant)
+	switch {
+	case budget <= 0:
+		return BudgetModeNormal
+	case used >= budget:
+		return BudgetModeFallback
+	case c.DowngradeRatio > 0 && float64(used) >= c.DowngradeRatio*float64(budget):
+		return BudgetModeDowngrade
+	default:
+		return BudgetModeNormal
+	}
+}
+
+// Apply 填充用量的预算、剩余额度与预算状态
+func (c BudgetConfig) Apply(usage *TenantUsage) *TenantUsage {
+	usage.Budget = c.BudgetFor(usage.Tenant)
+	usage.Mode = c.Mode(usage.Tenant, usage.TotalTokens)
+	if usage.Budget > 0 {
+		usage.Remaining = usage.Budget - usage.TotalTokens
+		if usage.Remaining < 0 {
+			usage.Remaining = 0
+		}
+	}
+	return usage
+}
+
+// UsageEvent 一次分析的模型用量
+type UsageEvent struct {
+	Tenant           string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	CacheHit         bool       // 命中响应缓存，未调用模型
+	Mode             BudgetMode // 分析时的预算状态
+}
+
+// TenantUsage 租户单日的模型用量
+type TenantUsage struct {
+	Tenant           string           `json:"tenant"`
+	Date             string           `json:"date"`
+	PromptTokens     int64            `json:"prompt_tokens"`
+	CompletionTokens int64            `json:"completion_tokens"`
+	TotalTokens      int64            `json:"total_tokens"`
+	Requests         int64            `json:"requests"`   // 分析次数，包含缓存命中与规则分析
+	CacheHits        int64            `json:"cache_hits"` // 命中响应缓存的次数
+	Downgrades       int64            `json:"downgrades"` // 使用低成本模型的次数
+	Fallbacks        int64            `json:"fallbacks"`  // 预算耗尽后使用规则分析的次数
+	Models           map[string]int64 `json:"models"`     // 按模型统计的令牌数
+	Budget           int64            `json:"budget"`     // 每日预算，0 表示不限制
+	Remaining        int64            `json:"remaining"`  // 剩余额度，不限制时为 0
+	Mode             BudgetMode       `json:"mode"`       // 当前预算状态
+}
+
+// UsageStore 按租户与自然日统计模型用量
+type UsageStore interface {
+	// Record 累加一次分析的用量
+	Record(ctx context.Context, day time.Time, event *UsageEvent) error
+
+	// Get 获取租户单日用量，无记录时返回零值用量
+	Get(ctx context.Context, tenant string, day time.Time) (*TenantUsage, error)
+
+	// List 获取单日所有租户的用量，按令牌数倒序
+	List(ctx context.Context, day time.Time) ([]*TenantUsage, error)
+}
+
+// UsageService 模型用量与预算查询
+type UsageService interface {
+	// ListUsage 获取单日所有租户的用量与预算状态
+	ListUsage(ctx context.Context, day time.Time) ([]*TenantUsage, error)
+
+	// TenantUsage 获取租户在日期范围内每天的用量，包含起止日期
+	TenantUsage(ctx context.Context, tenant string, from, to time.Time) ([]*TenantUsage, error)
+}
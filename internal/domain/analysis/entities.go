@@ -57,6 +57,8 @@ type AnalysisResult struct {
 	Result          map[string]interface{} `json:"result"`           // 分析结果数据
 	Summary         string                 `json:"summary"`          // 结果摘要
 	Recommendations []string               `json:"recommendations"`  // 推荐操作
+	Structured      *StructuredOutput      `json:"structured,omitempty"` // 经过 schema 校验的结构化输出
+	Citations       []*Citation            `json:"citations,omitempty"` // 注入提示的参考资料
 	ErrorMessage    string                 `json:"error_message"`    // 错误信息
 	CreatedAt       time.Time              `json:"created_at"`       // 创建时间
 	UpdatedAt       time.Time              `json:"updated_at"`       // 更新时间
@@ -127,6 +129,7 @@ type QueueStatus struct {
 	CompletedCount  int64     `json:"completed_count"`  // 已完成任务数
 	FailedCount     int64     `json:"failed_count"`     // 失败任务数
 	TotalCount      int64     `json:"total_count"`      // 总任务数
+	DeadLetterCount int64     `json:"dead_letter_count"` // 死信任务数
 	OldestTask      *time.Time `json:"oldest_task"`      // 最老任务时间
 	LastUpdated     time.Time `json:"last_updated"`     // 最后更新时间
 }
\ No newline at end of file
@@ -0,0 +1,122 @@
+package analysis
+
+import (
+	"context"
+	"time"
+)
+
+// FeedbackRating 响应人对分析结果的评价
+type FeedbackRating string
+
+const (
+	FeedbackUp   FeedbackRating = "up"   // 分析正确、有帮助
+	FeedbackDown FeedbackRating = "down" // 分析错误或没有帮助
+)
+
+// Valid 检查评价是否有效
+func (r FeedbackRating) Valid() bool {
+	return r == FeedbackUp || r == FeedbackDown
+}
+
+// AnalysisFeedback 分析结果反馈
+// 提交时记录结果的模型、模板、置信度与耗时，按模型统计准确率时不必关联结果表
+type AnalysisFeedback struct {
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	ResultID           string         `json:"result_id" gorm:"type:varchar(255);not null;index"`
+	TaskID             string         `json:"task_id" gorm:"type:varchar(255)"`
+	AlertID            string         `json:"alert_id" gorm:"type:varchar(255);index"`
+	Type               AnalysisType   `json:"type" gorm:"type:varchar(50)"`
+	Rating             FeedbackRating `json:"rating" gorm:"type:varchar(8);not null"`
+	CorrectedRootCause string         `json:"corrected_root_cause,omitempty" gorm:"type:text"` // 响应人确认的真实根因
+	CorrectedCategory  string         `json:"corrected_category,omitempty" gorm:"type:varchar(32)"`
+	Comment            string         `json:"comment,omitempty" gorm:"type:text"`
+	User               string         `json:"user,omitempty" gorm:"column:submitted_by;type:varchar(128)"`
+	ModelUsed          string         `json:"model_used" gorm:"type:varchar(128);index"`
+	TemplateID         string         `json:"template_id" gorm:"type:varchar(64)"`
+	Confidence         float64        `json:"confidence"`
+	ProcessingTimeMs   int64          `json:"processing_time_ms"`
+	CreatedAt          time.Time      `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (AnalysisFeedback) TableName() string {
+	return "analysis_feedback"
+}
+
+// FeedbackRequest 提交反馈请求
+type FeedbackRequest struct {
+	Rating             FeedbackRating `json:"rating" binding:"required"`
+	CorrectedRootCause string         `json:"corrected_root_cause"`
+	CorrectedCategory  string         `json:"corrected_category"`
+	Comment            string         `json:"comment"`
+	User               string         `json:"user"`
+}
+
+// FeedbackFilter 反馈统计条件，零值不限制
+type FeedbackFilter struct {
+	From      time.Time    `json:"from"`
+	To        time.Time    `json:"to"`
+	ModelUsed string       `json:"model_used"`
+	Type      AnalysisType `json:"type"`
+}
+
+// FeedbackStats 反馈统计，准确率为好评占比
+type FeedbackStats struct {
+	ModelUsed     string  `json:"model_used,omitempty"`
+	Total         int64   `json:"total"`
+	Positive      int64   `json:"positive"`
+	Negative      int64   `json:"negative"`
+	Corrected     int64   `json:"corrected"` // 附带真实根因的差评数
+	Accuracy      float64 `json:"accuracy"`
+	AvgConfidence float64 `json:"avg_confidence"`
+	AvgLatencyMs  float64 `json:"avg_latency_ms"`
+}
+
+// FeedbackSummary 反馈汇总与按模型的明细
+type FeedbackSummary struct {
+	Overall *FeedbackStats   `json:"overall"`
+	ByModel []*FeedbackStats `json:"by_model"`
+}
+
+// SummarizeFeedback 合并各模型的统计，平均值按样本数加权
+func SummarizeFeedback(byModel []*FeedbackStats) *FeedbackSummary {
+	overall := &FeedbackStats{}
+	for _, stats := range byModel {
+		overall.Total += stats.Total
+		overall.Positive += stats.Positive
+		overall.Negative += stats.Negative
+		overall.Corrected += stats.Corrected
+		overall.AvgConfidence += stats.AvgConfidence * float64(stats.Total)
+		overall.AvgLatencyMs += stats.AvgLatencyMs * float64(stats.Total)
+	}
+	if overall.Total > 0 {
+		overall.Accuracy = float64(overall.Positive) / float64(overall.Total)
+		overall.AvgConfidence /= float64(overall.Total)
+		overall.AvgLatencyMs /= float64(overall.Total)
+	}
+	return &FeedbackSummary{Overall: overall, ByModel: byModel}
+}
+
+// FeedbackRepository 分析反馈仓储接口
+type FeedbackRepository interface {
+	// Create 保存反馈
+	Create(ctx context.Context, feedback *AnalysisFeedback) error
+
+	// ListByResult 获取分析结果的反馈，按提交时间倒序
+	ListByResult(ctx context.Context, resultID string) ([]*AnalysisFeedback, error)
+
+	// StatsByModel 按模型统计反馈，准确率与平均值由仓储计算
+	StatsByModel(ctx context.Context, filter *FeedbackFilter) ([]*FeedbackStats, error)
+}
+
+// FeedbackService 分析反馈服务接口
+type FeedbackService interface {
+	// SubmitFeedback 对分析结果提交评价，并更新 AI 成熟度指标
+	SubmitFeedback(ctx context.Context, resultID string, req *FeedbackRequest) (*AnalysisFeedback, error)
+
+	// ListFeedback 获取分析结果的反馈
+	ListFeedback(ctx context.Context, resultID string) ([]*AnalysisFeedback, error)
+
+	// GetStats 获取反馈统计
+	GetStats(ctx context.Context, filter *FeedbackFilter) (*FeedbackSummary, error)
+}
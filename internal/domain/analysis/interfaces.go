@@ -3,11 +3,13 @@ package analysis
 import (
 	"context"
 	"time"
+
+	"alert_agent/internal/model"
 )
 
 // AnalysisTaskQueue 分析任务队列接口
 type AnalysisTaskQueue interface {
-	// Push 推送任务到队列
+	// Push 推送任务到队列，重复推送的任务重新计算投递次数并移出死信
 	Push(ctx context.Context, task *AnalysisTask) error
 	
 	// Pop 从队列中获取任务
@@ -113,6 +115,11 @@ type AnalysisProgressTracker interface {
 	GetProgressByTasks(ctx context.Context, taskIDs []string) (map[string]*AnalysisProgress, error)
 }
 
+// AlertLoader 按 ID 读取告警，由告警仓储实现
+type AlertLoader interface {
+	GetByID(ctx context.Context, id uint) (*model.Alert, error)
+}
+
 // AnalysisEngine 分析引擎接口
 type AnalysisEngine interface {
 	// Analyze 执行分析
@@ -187,6 +194,9 @@ type AnalysisService interface {
 	// GetAnalysisProgress 获取分析进度
 	GetAnalysisProgress(ctx context.Context, taskID string) (*AnalysisProgress, error)
 	
+	// StreamAnalysis 订阅任务的进度与模型输出，首个事件为当前进度，任务结束或上下文取消后通道关闭
+	StreamAnalysis(ctx context.Context, taskID string) (<-chan *StreamEvent, error)
+	
 	// CancelAnalysis 取消分析任务
 	CancelAnalysis(ctx context.Context, taskID string) error
 	
@@ -208,6 +218,15 @@ type AnalysisService interface {
 	// GetWorkerStatuses 获取工作器状态
 	GetWorkerStatuses(ctx context.Context) ([]*WorkerStatus, error)
 	
+	// GetWorkerPoolStatus 获取本进程的工作器与各 worker 进程上报的扩缩容状态
+	GetWorkerPoolStatus(ctx context.Context) (*WorkerPoolStatus, error)
+	
+	// ListDeadLetters 获取死信任务
+	ListDeadLetters(ctx context.Context, limit int64) ([]*DeadLetterTask, error)
+	
+	// RequeueDeadLetter 重新投递死信任务
+	RequeueDeadLetter(ctx context.Context, taskID string) error
+	
 	// HealthCheck 健康检查
 	HealthCheck(ctx context.Context) error
 }
@@ -0,0 +1,71 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost 任务租约已过期并被重新投递
+var ErrLeaseLost = errors.New("task lease lost")
+
+// ReliableTaskQueue 至少一次投递的分析任务队列
+// 出队的任务持有租约，处理完成前需定期续约，租约过期未确认的任务会被重新投递，
+// 超过最大投递次数后转入死信队列
+type ReliableTaskQueue interface {
+	AnalysisTaskQueue
+
+	// Ack 确认任务处理完成，释放租约与幂等键
+	Ack(ctx context.Context, taskID string) error
+
+	// Nack 任务处理失败，未超过最大投递次数时重新入队，否则转入死信队列，返回是否已转入死信
+	Nack(ctx context.Context, taskID string, cause error) (bool, error)
+
+	// ExtendLease 续约处理中的任务，租约已失效时返回 ErrLeaseLost
+	ExtendLease(ctx context.Context, taskID string) error
+
+	// RequeueExpired 重新投递租约过期的任务，返回重新入队与转入死信的数量
+	RequeueExpired(ctx context.Context) (requeued int, deadLettered int, err error)
+
+	// ClaimIdempotencyKey 为任务占用幂等键，返回键当前归属的任务ID
+	ClaimIdempotencyKey(ctx context.Context, key, taskID string) (string, error)
+
+	// ListDeadLetters 获取死信任务，按进入死信的时间倒序
+	ListDeadLetters(ctx context.Context, limit int64) ([]*DeadLetterTask, error)
+
+	// RequeueDeadLetter 将死信任务重新入队并重置投递次数
+	RequeueDeadLetter(ctx context.Context, taskID string) error
+}
+
+// DeadLetterTask 死信任务
+type DeadLetterTask struct {
+	Task     *AnalysisTask `json:"task"`
+	Error    string        `json:"error"`     // 最后一次失败原因
+	Attempts int64         `json:"attempts"`  // 投递次数
+	FailedAt time.Time     `json:"failed_at"` // 进入死信的时间
+}
+
+// QueueConfig 分析任务队列配置
+type QueueConfig struct {
+	VisibilityTimeout time.Duration // 出队任务的租约时长，处理中需在过期前续约
+	MaxAttempts       int           // 最大投递次数，超过后转入死信
+	ReapInterval      time.Duration // 检查过期租约的间隔
+	ReapBatchSize     int           // 每次最多处理的过期任务数
+	IdempotencyTTL    time.Duration // 幂等键有效期，任务确认或转入死信时提前释放
+}
+
+// DefaultQueueConfig 默认队列配置
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		VisibilityTimeout: 2 * time.Minute,
+		MaxAttempts:       3,
+		ReapInterval:      15 * time.Second,
+		ReapBatchSize:     100,
+		IdempotencyTTL:    24 * time.Hour,
+	}
+}
+
+// IdempotencyKey 同一告警同一分析类型的幂等键，重复提交合并为同一任务
+func IdempotencyKey(alertID string, analysisType AnalysisType) string {
+	return alertID + ":" + string(analysisType)
+}
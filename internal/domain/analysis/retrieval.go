@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// 知识来源类型
+const (
+	KnowledgeSourceKnowledge = "knowledge" // 知识库条目
+	KnowledgeSourceAlert     = "alert"     // 已解决且有处理说明的告警
+)
+
+// KnowledgeDocument 可检索的知识文档
+type KnowledgeDocument struct {
+	ID         string    `json:"id"`          // 文档ID，见 KnowledgeDocumentID
+	SourceType string    `json:"source_type"` // 来源类型
+	SourceID   uint      `json:"source_id"`   // 来源记录ID
+	Title      string    `json:"title"`       // 标题
+	Content    string    `json:"content"`     // 用于生成向量与引用片段的正文
+	UpdatedAt  time.Time `json:"updated_at"`  // 来源记录更新时间
+}
+
+// KnowledgeDocumentID 知识文档ID，如 knowledge:12、alert:34
+func KnowledgeDocumentID(sourceType string, sourceID uint) string {
+	return fmt.Sprintf("%s:%d", sourceType, sourceID)
+}
+
+// Cursor 文档在增量同步顺序中的位置
+func (d *KnowledgeDocument) Cursor() KnowledgeCursor {
+	return KnowledgeCursor{UpdatedAt: d.UpdatedAt, SourceType: d.SourceType, SourceID: d.SourceID}
+}
+
+// KnowledgeCursor 增量同步位置，文档按 (更新时间, 来源类型, 来源ID) 排序，
+// 更新时间相同的文档由来源类型与来源ID区分，分批读取时不会被跳过
+type KnowledgeCursor struct {
+	UpdatedAt  time.Time `json:"updated_at"`
+	SourceType string    `json:"source_type"`
+	SourceID   uint      `json:"source_id"`
+}
+
+// Before 判断游标是否排在 other 之前
+func (c KnowledgeCursor) Before(other KnowledgeCursor) bool {
+	if !c.UpdatedAt.Equal(other.UpdatedAt) {
+		return c.UpdatedAt.Before(other.UpdatedAt)
+	}
+	if c.SourceType != other.SourceType {
+		return c.SourceType < other.SourceType
+	}
+	return c.SourceID < other.SourceID
+}
+
+// Citation 注入分析提示的参考资料
+type Citation struct {
+	Ref        string  `json:"ref"`         // 提示中的引用编号，如 K1
+	DocumentID string  `json:"document_id"` // 知识文档ID
+	SourceType string  `json:"source_type"` // 来源类型
+	SourceID   uint    `json:"source_id"`   // 来源记录ID
+	Title      string  `json:"title"`       // 标题
+	Snippet    string  `json:"snippet"`     // 注入提示的片段
+	Score      float64 `json:"score"`       // 与告警的相似度
+	Cited      bool    `json:"cited"`       // 模型输出是否引用了该资料
+}
+
+// KnowledgeRetriever 为告警检索相关知识
+type KnowledgeRetriever interface {
+	// Retrieve 检索与告警最相关的知识，按相似度倒序
+	Retrieve(ctx context.Context, alert *model.Alert, limit int) ([]*Citation, error)
+}
+
+// KnowledgeSource 知识文档来源
+type KnowledgeSource interface {
+	// ListDocuments 获取排在 after 之后的文档，按 KnowledgeCursor 顺序正序
+	ListDocuments(ctx context.Context, after KnowledgeCursor, limit int) ([]*KnowledgeDocument, error)
+
+	// ListDocumentIDs 获取当前所有文档ID，用于清理已删除的文档
+	ListDocumentIDs(ctx context.Context) ([]string, error)
+}
+
+// VectorMeta 向量索引中文档的元数据
+type VectorMeta struct {
+	SourceType  string    `json:"source_type"`
+	SourceID    uint      `json:"source_id"`
+	Title       string    `json:"title"`
+	Snippet     string    `json:"snippet"`
+	ContentHash string    `json:"content_hash"` // 正文摘要，未变化的文档不重新生成向量
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// VectorHit 向量检索结果
+type VectorHit struct {
+	ID    string
+	Score float64 // 余弦相似度
+	Meta  VectorMeta
+}
+
+// VectorIndex 近似最近邻向量索引
+type VectorIndex interface {
+	// Upsert 写入或替换文档向量
+	Upsert(id string, vector []float32, meta VectorMeta) error
+
+	// Delete 删除文档，返回文档是否存在
+	Delete(id string) bool
+
+	// Search 检索与向量最相似的 k 个文档，按相似度倒序
+	Search(vector []float32, k int) []VectorHit
+
+	// Get 获取文档元数据
+	Get(id string) (VectorMeta, bool)
+
+	// IDs 获取所有文档ID
+	IDs() []string
+
+	// Len 文档数量
+	Len() int
+
+	// Persist 将索引快照写入存储，重启后无需重新生成向量
+	Persist() error
+}
+
+// RetrievalConfig 检索增强配置
+type RetrievalConfig struct {
+	TopK          int           // 注入提示的参考资料数量
+	MinScore      float64       // 最低相似度，低于该值的资料不注入
+	SnippetLength int           // 每条参考资料注入的最大字符数
+	SyncInterval  time.Duration // 从数据库同步文档的间隔
+	SyncBatchSize int           // 每次同步最多处理的文档数
+}
+
+// DefaultRetrievalConfig 默认检索增强配置
+func DefaultRetrievalConfig() RetrievalConfig {
+	return RetrievalConfig{
+		TopK:          3,
+		MinScore:      0.6,
+		SnippetLength: 500,
+		SyncInterval:  5 * time.Minute,
+		SyncBatchSize: 200,
+	}
+}
@@ -0,0 +1,66 @@
+package analysis
+
+import "context"
+
+// 分析进度阶段
+const (
+	ProgressStagePending      = "pending"      // 等待处理
+	ProgressStageInitializing = "initializing" // 开始处理
+	ProgressStageAnalyzing    = "analyzing"    // 分析中
+	ProgressStageCompleted    = "completed"    // 分析完成
+	ProgressStageFailed       = "failed"       // 分析失败
+	ProgressStageCancelled    = "cancelled"    // 任务已取消
+)
+
+// IsTerminalStage 阶段是否为最终状态，之后不再有事件
+func IsTerminalStage(stage string) bool {
+	switch stage {
+	case ProgressStageCompleted, ProgressStageFailed, ProgressStageCancelled:
+		return true
+	}
+	return false
+}
+
+// 分析事件类型
+const (
+	StreamEventProgress = "progress" // 进度更新
+	StreamEventToken    = "token"    // 模型输出增量
+)
+
+// StreamEvent 分析任务的实时事件
+type StreamEvent struct {
+	TaskID   string            `json:"task_id"`
+	Type     string            `json:"type"`
+	Progress *AnalysisProgress `json:"progress,omitempty"` // Type 为 progress 时有值
+	Delta    string            `json:"delta,omitempty"`    // Type 为 token 时为新增的模型输出
+}
+
+// IsTerminal 事件是否表示任务已结束
+func (e *StreamEvent) IsTerminal() bool {
+	return e.Type == StreamEventProgress && e.Progress != nil && IsTerminalStage(e.Progress.Stage)
+}
+
+// AnalysisEventBus 分析事件发布订阅，处理任务的 worker 与提供订阅的 API 副本可以不同
+type AnalysisEventBus interface {
+	// Publish 发布事件，没有订阅者时事件被丢弃
+	Publish(ctx context.Context, event *StreamEvent) error
+
+	// Subscribe 订阅任务事件，返回时订阅已生效，上下文取消后通道关闭
+	Subscribe(ctx context.Context, taskID string) (<-chan *StreamEvent, error)
+}
+
+// TokenSink 接收模型的流式输出增量
+type TokenSink func(delta string)
+
+type tokenSinkKey struct{}
+
+// WithTokenSink 在上下文中携带流式输出接收器，支持流式输出的模型后端据此逐段回传
+func WithTokenSink(ctx context.Context, sink TokenSink) context.Context {
+	return context.WithValue(ctx, tokenSinkKey{}, sink)
+}
+
+// TokenSinkFromContext 获取上下文中的流式输出接收器，没有时返回 nil
+func TokenSinkFromContext(ctx context.Context) TokenSink {
+	sink, _ := ctx.Value(tokenSinkKey{}).(TokenSink)
+	return sink
+}
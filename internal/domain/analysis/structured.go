@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputSchemaVersion 结构化分析输出的 schema 版本，字段变更时递增
+const OutputSchemaVersion = "1"
+
+// 告警严重程度
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+// 告警类别，与网关智能路由的分类保持一致
+const (
+	CategoryPerformance = "performance"
+	CategoryStorage     = "storage"
+	CategoryNetwork     = "network"
+	CategoryApplication = "application"
+	CategoryDatabase    = "database"
+	CategorySecurity    = "security"
+	CategoryGeneral     = "general"
+)
+
+// 处理优先级
+const (
+	PriorityP0 = "P0"
+	PriorityP1 = "P1"
+	PriorityP2 = "P2"
+	PriorityP3 = "P3"
+)
+
+// Severities 所有严重程度
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// Categories 所有告警类别
+var Categories = []string{
+	CategoryPerformance, CategoryStorage, CategoryNetwork, CategoryApplication,
+	CategoryDatabase, CategorySecurity, CategoryGeneral,
+}
+
+// Priorities 所有处理优先级
+var Priorities = []string{PriorityP0, PriorityP1, PriorityP2, PriorityP3}
+
+// StructuredOutput 经过 schema 校验的结构化分析输出
+// 各分析类型共用，未要求的字段为空
+type StructuredOutput struct {
+	Summary    string              `json:"summary"`               // 结论摘要
+	Severity   string              `json:"severity"`              // 严重程度
+	Confidence float64             `json:"confidence"`            // 置信度 (0-1)
+	Category   string              `json:"category,omitempty"`    // 告警类别，分类分析必填
+	Priority   string              `json:"priority,omitempty"`    // 处理优先级，优先级评估必填
+	Reason     string              `json:"reason,omitempty"`      // 分类或定级依据
+	RootCauses []RootCause         `json:"root_causes,omitempty"` // 可能根因，根因分析必填
+	Impact     *ImpactAssessment   `json:"impact,omitempty"`      // 影响范围，影响评估必填
+	Actions    []RecommendedAction `json:"actions,omitempty"`     // 推荐操作，按执行顺序
+	Citations  []string            `json:"citations,omitempty"`   // 引用的参考资料编号
+}
+
+// RootCause 可能的根因
+type RootCause struct {
+	Description string   `json:"description"`        // 根因描述
+	Likelihood  float64  `json:"likelihood"`         // 可能性 (0-1)
+	Evidence    []string `json:"evidence,omitempty"` // 支撑证据
+}
+
+// ImpactAssessment 影响评估
+type ImpactAssessment struct {
+	Scope            string   `json:"scope"`                       // 影响范围：single_instance、service、multi_service、global
+	AffectedServices []string `json:"affected_services,omitempty"` // 受影响的服务
+	UserImpact       string   `json:"user_impact,omitempty"`       // 对用户的影响
+}
+
+// RecommendedAction 推荐操作
+type RecommendedAction struct {
+	Title   string `json:"title"`             // 操作说明
+	Command string `json:"command,omitempty"` // 可执行的命令
+	Risk    string `json:"risk,omitempty"`    // 操作风险：low、medium、high
+}
+
+// ActionTitles 推荐操作的说明列表
+func (o *StructuredOutput) ActionTitles() []string {
+	titles := make([]string, 0, len(o.Actions))
+	for _, action := range o.Actions {
+		titles = append(titles, action.Title)
+	}
+	return titles
+}
+
+// Markdown 渲染为便于阅读的 Markdown，用于告警详情与知识库
+func (o *StructuredOutput) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**结论**：%s\n\n", o.Summary)
+	if o.Severity != "" {
+		fmt.Fprintf(&b, "- 严重程度：%s\n", o.Severity)
+	}
+	if o.Category != "" {
+		fmt.Fprintf(&b, "- 类别：%s\n", o.Category)
+	}
+	if o.Priority != "" {
+		fmt.Fprintf(&b, "- 优先级：%s\n", o.Priority)
+	}
+	fmt.Fprintf(&b, "- 置信度：%.0f%%\n", o.Confidence*100)
+	if o.Reason != "" {
+		fmt.Fprintf(&b, "- 依据：%s\n", o.Reason)
+	}
+
+	if len(o.RootCauses) > 0 {
+		b.WriteString("\n### 可能根因\n\n")
+		for i, cause := range o.RootCauses {
+			fmt.Fprintf(&b, "%d. %s（可能性 %.0f%%）\n", i+1, cause.Description, cause.Likelihood*100)
+			for _, evidence := range cause.Evidence {
+				fmt.Fprintf(&b, "   - %s\n", evidence)
+			}
+		}
+	}
+
+	if o.Impact != nil {
+		b.WriteString("\n### 影响范围\n\n")
+		fmt.Fprintf(&b, "- 范围：%s\n", o.Impact.Scope)
+		if len(o.Impact.AffectedServices) > 0 {
+			fmt.Fprintf(&b, "- 受影响服务：%s\n", strings.Join(o.Impact.AffectedServices, "、"))
+		}
+		if o.Impact.UserImpact != "" {
+			fmt.Fprintf(&b, "- 用户影响：%s\n", o.Impact.UserImpact)
+		}
+	}
+
+	if len(o.Actions) > 0 {
+		b.WriteString("\n### 建议操作\n\n")
+		for i, action := range o.Actions {
+			fmt.Fprintf(&b, "%d. %s", i+1, action.Title)
+			if action.Risk != "" {
+				fmt.Fprintf(&b, "（风险：%s）", action.Risk)
+			}
+			b.WriteString("\n")
+			if action.Command != "" {
+				fmt.Fprintf(&b, "   `%s`\n", action.Command)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package analytics
+
+import (
+	"time"
+)
+
+// Config 告警分析配置
+type Config struct {
+	Owner          string         `json:"owner"`             // 刷新者标识，默认为主机名与进程号
+	Interval       time.Duration  `json:"interval"`          // 刷新间隔
+	Lookback       time.Duration  `json:"lookback"`          // 每次重新聚合的最近时间窗口，覆盖延迟的确认与恢复
+	Backfill       time.Duration  `json:"backfill"`          // 首次运行时回填的历史时长
+	MaxHoursPerRun int            `json:"max_hours_per_run"` // 单次刷新最多聚合的小时数，回填分多次完成
+	LeaseTTL       time.Duration  `json:"lease_ttl"`         // 刷新租约时长
+	ServiceLabels  []string       `json:"service_labels"`    // 标识服务的标签，按顺序取第一个存在的
+	TeamLabels     []string       `json:"team_labels"`       // 标识团队的标签
+	InstanceLabels []string       `json:"instance_labels"`   // 标识实例的标签
+	Location       *time.Location `json:"-"`                 // 热力图与班次使用的时区
+	ShiftHours     int            `json:"shift_hours"`       // 值班班次时长（小时）
+	ShiftStartHour int            `json:"shift_start_hour"`  // 每天第一个班次的开始时间（小时）
+}
+
+// DefaultConfig 默认告警分析配置
+func DefaultConfig() Config {
+	return Config{
+		Interval:       5 * time.Minute,
+		Lookback:       48 * time.Hour,
+		Backfill:       90 * 24 * time.Hour,
+		MaxHoursPerRun: 7 * 24,
+		LeaseTTL:       10 * time.Minute,
+		ServiceLabels:  []string{"service", "app", "application"},
+		TeamLabels:     []string{"team", "owner"},
+		InstanceLabels: []string{"instance", "host", "pod"},
+		Location:       time.Local,
+		ShiftHours:     12,
+		ShiftStartHour: 9,
+	}
+}
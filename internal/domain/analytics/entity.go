@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"time"
+)
+
+// HourlyRollup 按小时预聚合的告警统计，时间桶为告警创建时间所在小时（UTC）
+type HourlyRollup struct {
+	Bucket         time.Time `json:"bucket" gorm:"primaryKey"`
+	RuleID         uint      `json:"rule_id" gorm:"primaryKey;autoIncrement:false"`
+	Service        string    `json:"service" gorm:"primaryKey;size:128"`
+	Team           string    `json:"team" gorm:"primaryKey;size:128"`
+	Instance       string    `json:"instance" gorm:"primaryKey;size:128"`
+	Severity       string    `json:"severity" gorm:"primaryKey;size:20"`
+	AlertCount     int64     `json:"alert_count"`
+	AckedCount     int64     `json:"acked_count"`
+	AckSeconds     float64   `json:"ack_seconds"` // 已确认告警的确认耗时之和
+	ResolvedCount  int64     `json:"resolved_count"`
+	ResolveSeconds float64   `json:"resolve_seconds"` // 已恢复告警的恢复耗时之和
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (HourlyRollup) TableName() string {
+	return "alert_rollups_hourly"
+}
+
+// RollupState 预聚合进度
+type RollupState struct {
+	Name      string    `json:"name" gorm:"primaryKey;size:100"`
+	Watermark time.Time `json:"watermark"` // 该时间之前的小时桶已聚合
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RollupState) TableName() string {
+	return "analytics_rollup_state"
+}
+
+// Dimension 统计维度
+type Dimension string
+
+const (
+	DimensionRule     Dimension = "rule"
+	DimensionService  Dimension = "service"
+	DimensionTeam     Dimension = "team"
+	DimensionInstance Dimension = "instance"
+	DimensionSeverity Dimension = "severity"
+)
+
+// IsValid 检查维度是否有效
+func (d Dimension) IsValid() bool {
+	switch d {
+	case DimensionRule, DimensionService, DimensionTeam, DimensionInstance, DimensionSeverity:
+		return true
+	}
+	return false
+}
+
+// Filter 统计过滤条件，时间范围为 [From, To)
+type Filter struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	RuleID   uint      `json:"rule_id,omitempty"`
+	Service  string    `json:"service,omitempty"`
+	Team     string    `json:"team,omitempty"`
+	Instance string    `json:"instance,omitempty"`
+	Severity string    `json:"severity,omitempty"`
+}
+
+// Totals 聚合计数
+type Totals struct {
+	Alerts         int64   `json:"alerts"`
+	Acked          int64   `json:"acked"`
+	AckSeconds     float64 `json:"-"`
+	Resolved       int64   `json:"resolved"`
+	ResolveSeconds float64 `json:"-"`
+}
+
+// Add 累加计数
+func (t *Totals) Add(other Totals) {
+	t.Alerts += other.Alerts
+	t.Acked += other.Acked
+	t.AckSeconds += other.AckSeconds
+	t.Resolved += other.Resolved
+	t.ResolveSeconds += other.ResolveSeconds
+}
+
+// MTTA 平均确认时长（秒），无确认告警时为 0
+func (t Totals) MTTA() float64 {
+	if t.Acked == 0 {
+		return 0
+	}
+	return t.AckSeconds / float64(t.Acked)
+}
+
+// MTTR 平均恢复时长（秒），无恢复告警时为 0
+func (t Totals) MTTR() float64 {
+	if t.Resolved == 0 {
+		return 0
+	}
+	return t.ResolveSeconds / float64(t.Resolved)
+}
+
+// DimensionStats 单个维度取值的统计
+type DimensionStats struct {
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"` // 规则维度为规则名称
+	Totals
+	MTTASeconds float64 `json:"mtta_seconds"`
+	MTTRSeconds float64 `json:"mttr_seconds"`
+}
+
+// BucketTotals 单个小时桶的统计
+type BucketTotals struct {
+	Bucket time.Time `json:"bucket"`
+	Totals
+}
+
+// ShiftStats 值班班次的告警数
+type ShiftStats struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Alerts int64     `json:"alerts"`
+}
+
+// Heatmap 星期 × 小时告警热力图，Cells[weekday][hour]，weekday 0 为周日
+type Heatmap struct {
+	Timezone string       `json:"timezone"`
+	Cells    [7][24]int64 `json:"cells"`
+	Max      int64        `json:"max"`
+}
+
+// PeriodStats 统计周期汇总
+type PeriodStats struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	Totals
+	MTTASeconds float64 `json:"mtta_seconds"`
+	MTTRSeconds float64 `json:"mttr_seconds"`
+}
+
+// RuleChange 规则告警数的周期变化
+type RuleChange struct {
+	RuleID   string `json:"rule_id"`
+	Name     string `json:"name,omitempty"`
+	Current  int64  `json:"current"`
+	Previous int64  `json:"previous"`
+	Delta    int64  `json:"delta"`
+}
+
+// PeriodComparison 环比对比
+type PeriodComparison struct {
+	Current         PeriodStats   `json:"current"`
+	Previous        PeriodStats   `json:"previous"`
+	AlertsChangePct *float64      `json:"alerts_change_pct"`
+	MTTAChangePct   *float64      `json:"mtta_change_pct"`
+	MTTRChangePct   *float64      `json:"mttr_change_pct"`
+	TopRuleChanges  []*RuleChange `json:"top_rule_changes"`
+}
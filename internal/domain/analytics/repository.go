@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// Repository 告警分析数据仓储接口
+type Repository interface {
+	// ListAlertsCreatedBetween 获取创建时间在 [from, to) 内的告警，仅包含聚合所需字段
+	ListAlertsCreatedBetween(ctx context.Context, from, to time.Time) ([]*model.Alert, error)
+
+	// ReplaceRollups 在一个事务内替换 [from, to) 内的小时聚合
+	ReplaceRollups(ctx context.Context, from, to time.Time, rollups []*HourlyRollup) error
+
+	// GetWatermark 获取聚合进度，从未聚合时返回 nil
+	GetWatermark(ctx context.Context, name string) (*time.Time, error)
+
+	// SetWatermark 保存聚合进度
+	SetWatermark(ctx context.Context, name string, watermark time.Time) error
+
+	// AggregateBy 按维度汇总，按告警数倒序，limit 为 0 时不限制
+	AggregateBy(ctx context.Context, dimension Dimension, filter *Filter, limit int) ([]*DimensionStats, error)
+
+	// BucketTotals 按小时桶汇总，按时间正序
+	BucketTotals(ctx context.Context, filter *Filter) ([]*BucketTotals, error)
+
+	// RuleNames 获取规则名称
+	RuleNames(ctx context.Context, ruleIDs []uint) (map[uint]string, error)
+}
+
+// LeaseManager 基于租约的选主，保证同一时间只有一个 worker 刷新聚合
+type LeaseManager interface {
+	// Acquire 获取或续约租约，返回是否持有
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release 释放自己持有的租约
+	Release(ctx context.Context, key, owner string) error
+}
+
+// Service 告警分析服务接口
+type Service interface {
+	// ResponseMetrics 按规则、服务或团队统计 MTTA/MTTR
+	ResponseMetrics(ctx context.Context, dimension Dimension, filter *Filter, limit int) ([]*DimensionStats, error)
+
+	// TopNoisy 告警数最多的规则或实例
+	TopNoisy(ctx context.Context, dimension Dimension, filter *Filter, limit int) ([]*DimensionStats, error)
+
+	// AlertsPerShift 按值班班次统计告警数
+	AlertsPerShift(ctx context.Context, filter *Filter) ([]*ShiftStats, error)
+
+	// Heatmap 星期 × 小时告警热力图
+	Heatmap(ctx context.Context, filter *Filter) (*Heatmap, error)
+
+	// WeekOverWeek 截至 end 的最近一周与上一周对比
+	WeekOverWeek(ctx context.Context, end time.Time, filter *Filter) (*PeriodComparison, error)
+
+	// RefreshRollups 刷新小时聚合
+	RefreshRollups(ctx context.Context) error
+}
@@ -0,0 +1,357 @@
+package automation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+)
+
+// ActionType 操作类型
+type ActionType string
+
+const (
+	ActionHTTP       ActionType = "http"       // 调用 HTTP 接口
+	ActionScript     ActionType = "script"     // 在沙箱容器中执行脚本
+	ActionKubernetes ActionType = "kubernetes" // Kubernetes 操作
+	ActionN8N        ActionType = "n8n"        // 触发 n8n 工作流
+)
+
+// Kubernetes 操作
+const (
+	KubernetesRestartDeployment = "restart_deployment"
+	KubernetesScaleDeployment   = "scale_deployment"
+	KubernetesDeletePod         = "delete_pod"
+)
+
+// HTTPAction HTTP 调用
+type HTTPAction struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// ScriptAction 沙箱容器中执行的脚本，容器无网络、只读文件系统
+type ScriptAction struct {
+	Image  string            `json:"image,omitempty"` // 为空时使用默认镜像
+	Script string            `json:"script"`
+	Env    map[string]string `json:"env,omitempty"`
+}
+
+// KubernetesAction Kubernetes 操作
+type KubernetesAction struct {
+	Operation string `json:"operation"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Replicas  *int   `json:"replicas,omitempty"` // 扩缩容目标副本数
+}
+
+// N8NAction 触发 n8n 工作流
+type N8NAction struct {
+	WorkflowID string            `json:"workflow_id"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+// Action 一个可执行操作，字符串字段支持 Go 模板，可引用 .Alert、.Labels 与 .ExecutionID
+type Action struct {
+	Type       ActionType        `json:"type"`
+	Timeout    int               `json:"timeout,omitempty"` // 超时（秒），为 0 时使用默认值
+	HTTP       *HTTPAction       `json:"http,omitempty"`
+	Script     *ScriptAction     `json:"script,omitempty"`
+	Kubernetes *KubernetesAction `json:"kubernetes,omitempty"`
+	N8N        *N8NAction        `json:"n8n,omitempty"`
+}
+
+// Validate 检查操作类型与对应配置
+func (a *Action) Validate() error {
+	switch a.Type {
+	case ActionHTTP:
+		if a.HTTP == nil || a.HTTP.URL == "" {
+			return fmt.Errorf("http action requires url")
+		}
+	case ActionScript:
+		if a.Script == nil || a.Script.Script == "" {
+			return fmt.Errorf("script action requires script")
+		}
+	case ActionKubernetes:
+		if a.Kubernetes == nil || a.Kubernetes.Namespace == "" || a.Kubernetes.Name == "" {
+			return fmt.Errorf("kubernetes action requires namespace and name")
+		}
+		switch a.Kubernetes.Operation {
+		case KubernetesRestartDeployment, KubernetesDeletePod:
+		case KubernetesScaleDeployment:
+			if a.Kubernetes.Replicas == nil || *a.Kubernetes.Replicas < 0 {
+				return fmt.Errorf("scale_deployment requires non-negative replicas")
+			}
+		default:
+			return fmt.Errorf("unsupported kubernetes operation %q", a.Kubernetes.Operation)
+		}
+	case ActionN8N:
+		if a.N8N == nil || a.N8N.WorkflowID == "" {
+			return fmt.Errorf("n8n action requires workflow_id")
+		}
+	default:
+		return fmt.Errorf("unsupported action type %q", a.Type)
+	}
+	return nil
+}
+
+// Describe 操作摘要，用于试运行日志与审批消息
+func (a *Action) Describe() string {
+	switch a.Type {
+	case ActionHTTP:
+		method := a.HTTP.Method
+		if method == "" {
+			method = "POST"
+		}
+		return fmt.Sprintf("%s %s", method, a.HTTP.URL)
+	case ActionScript:
+		if a.Script.Image == "" {
+			return "run script in sandbox"
+		}
+		return fmt.Sprintf("run script in %s", a.Script.Image)
+	case ActionKubernetes:
+		k := a.Kubernetes
+		if k.Operation == KubernetesScaleDeployment {
+			return fmt.Sprintf("%s %s/%s to %d", k.Operation, k.Namespace, k.Name, *k.Replicas)
+		}
+		return fmt.Sprintf("%s %s/%s", k.Operation, k.Namespace, k.Name)
+	case ActionN8N:
+		return fmt.Sprintf("trigger n8n workflow %s", a.N8N.WorkflowID)
+	}
+	return string(a.Type)
+}
+
+// Templated 判断 HTTP 或 n8n 操作是否引用模板，这类操作的地址与内容来自告警数据
+func (a *Action) Templated() bool {
+	var fields []string
+	switch {
+	case a.Type == ActionHTTP && a.HTTP != nil:
+		fields = append(fields, a.HTTP.URL, a.HTTP.Body)
+		for _, v := range a.HTTP.Headers {
+			fields = append(fields, v)
+		}
+	case a.Type == ActionN8N && a.N8N != nil:
+		fields = append(fields, a.N8N.WorkflowID)
+		for _, v := range a.N8N.Data {
+			fields = append(fields, v)
+		}
+	}
+	for _, field := range fields {
+		if strings.Contains(field, "{{") {
+			return true
+		}
+	}
+	return false
+}
+
+// Step 运行手册步骤，失败时按相反顺序执行已完成步骤的回滚操作
+type Step struct {
+	Name     string  `json:"name"`
+	Action   Action  `json:"action"`
+	Rollback *Action `json:"rollback,omitempty"`
+}
+
+// Runbook 运行手册，告警标签满足所有匹配器时触发，未配置匹配器时只能手工触发
+type Runbook struct {
+	ID                 string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Name               string            `json:"name" gorm:"type:varchar(255);not null;uniqueIndex"`
+	Description        string            `json:"description" gorm:"type:text"`
+	Enabled            bool              `json:"enabled" gorm:"not null;default:true;index"`
+	Matchers           []gateway.Matcher `json:"matchers" gorm:"type:text;serializer:json"`
+	Steps              []Step            `json:"steps" gorm:"type:text;serializer:json"`
+	DryRun             bool              `json:"dry_run"`                                                         // 只记录将执行的操作
+	RequireApproval    bool              `json:"require_approval"`                                                // 未包含高风险操作时也需要审批
+	ApprovalChannelIDs []string          `json:"approval_channel_ids,omitempty" gorm:"type:text;serializer:json"` // 发送审批消息的通知渠道
+	Cooldown           int               `json:"cooldown"`                                                        // 同一告警再次触发的间隔（秒）
+	CreatedBy          string            `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Runbook) TableName() string {
+	return "runbooks"
+}
+
+// ExecutionStatus 执行状态
+type ExecutionStatus string
+
+const (
+	ExecutionPendingApproval ExecutionStatus = "pending_approval" // 等待审批
+	ExecutionQueued          ExecutionStatus = "queued"           // 等待 worker 执行
+	ExecutionRunning         ExecutionStatus = "running"
+	ExecutionSucceeded       ExecutionStatus = "succeeded"
+	ExecutionFailed          ExecutionStatus = "failed"      // 失败且回滚未完成
+	ExecutionRolledBack      ExecutionStatus = "rolled_back" // 失败后已回滚
+	ExecutionRejected        ExecutionStatus = "rejected"
+	ExecutionExpired         ExecutionStatus = "expired" // 审批超时
+)
+
+// IsFinal 是否为终止状态
+func (s ExecutionStatus) IsFinal() bool {
+	switch s {
+	case ExecutionSucceeded, ExecutionFailed, ExecutionRolledBack, ExecutionRejected, ExecutionExpired:
+		return true
+	}
+	return false
+}
+
+// Execution 运行手册的一次执行，执行时使用触发时的运行手册快照
+type Execution struct {
+	ID          string          `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	RunbookID   string          `json:"runbook_id" gorm:"type:varchar(36);not null;index"`
+	RunbookName string          `json:"runbook_name" gorm:"type:varchar(255)"`
+	AlertID     uint            `json:"alert_id" gorm:"not null;index"`
+	Status      ExecutionStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	DryRun      bool            `json:"dry_run"`
+	Steps       []Step          `json:"steps" gorm:"type:text;serializer:json"`
+	Context     *ActionContext  `json:"context" gorm:"type:text;serializer:json"` // 渲染操作模板的告警数据
+	TriggeredBy string          `json:"triggered_by" gorm:"type:varchar(100)"`
+	ApprovedBy  string          `json:"approved_by,omitempty" gorm:"type:varchar(100)"`
+	Comment     string          `json:"comment,omitempty" gorm:"type:text"` // 审批或拒绝说明
+	Error       string          `json:"error,omitempty" gorm:"type:text"`
+	ApprovedAt  *time.Time      `json:"approved_at,omitempty"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Execution) TableName() string {
+	return "runbook_executions"
+}
+
+// ExecutionFilter 执行查询条件
+type ExecutionFilter struct {
+	RunbookID string          `json:"runbook_id"`
+	AlertID   uint            `json:"alert_id"`
+	Status    ExecutionStatus `json:"status"`
+	Limit     int             `json:"limit"`
+	Offset    int             `json:"offset"`
+}
+
+// ActionContext 操作模板可引用的数据
+type ActionContext struct {
+	ExecutionID string            `json:"execution_id"`
+	Alert       AlertSummary      `json:"alert"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// AlertSummary 触发执行的告警
+type AlertSummary struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	Level    string `json:"level"`
+	Severity string `json:"severity"`
+	Source   string `json:"source"`
+	Content  string `json:"content"`
+}
+
+// ActionPhase 操作阶段
+type ActionPhase string
+
+const (
+	PhaseExecute  ActionPhase = "execute"
+	PhaseRollback ActionPhase = "rollback"
+)
+
+// ActionStatus 操作状态，与 automation_actions 表定义一致
+type ActionStatus string
+
+const (
+	ActionCompleted ActionStatus = "completed"
+	ActionFailed    ActionStatus = "failed"
+)
+
+// AutomationAction 操作执行日志，按告警保存在 automation_actions 表
+type AutomationAction struct {
+	ID            string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	ExecutionID   string            `json:"execution_id" gorm:"type:varchar(36);not null;index"`
+	AlertID       string            `json:"alert_id" gorm:"type:varchar(100);not null;index"`
+	Step          string            `json:"step" gorm:"type:varchar(255)"`
+	Phase         ActionPhase       `json:"phase" gorm:"type:varchar(20);not null"`
+	ActionType    ActionType        `json:"action_type" gorm:"type:varchar(100);not null;index"`
+	TargetInfo    map[string]string `json:"target_info" gorm:"type:json;serializer:json"` // 操作类型与目标摘要
+	Parameters    *Action           `json:"parameters" gorm:"type:json;serializer:json"`  // 渲染后的操作，已隐藏凭据
+	DryRun        bool              `json:"dry_run"`
+	Status        ActionStatus      `json:"execution_status" gorm:"column:execution_status;type:varchar(20);not null;index"`
+	Result        string            `json:"execution_result,omitempty" gorm:"column:execution_result;type:text"`
+	ExecutionTime int64             `json:"execution_time" gorm:"column:execution_time"` // 耗时（毫秒）
+	ErrorMessage  string            `json:"error_message,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AutomationAction) TableName() string {
+	return "automation_actions"
+}
+
+// ActionResult 操作执行结果
+type ActionResult struct {
+	Output string `json:"output"`
+}
+
+// RunbookRequest 创建或更新运行手册请求
+type RunbookRequest struct {
+	Name               string            `json:"name" binding:"required"`
+	Description        string            `json:"description"`
+	Enabled            *bool             `json:"enabled"`
+	Matchers           []gateway.Matcher `json:"matchers"`
+	Steps              []Step            `json:"steps" binding:"required"`
+	DryRun             bool              `json:"dry_run"`
+	RequireApproval    bool              `json:"require_approval"`
+	ApprovalChannelIDs []string          `json:"approval_channel_ids"`
+	Cooldown           int               `json:"cooldown"`
+	CreatedBy          string            `json:"created_by"`
+}
+
+// TriggerRequest 手工触发运行手册请求
+type TriggerRequest struct {
+	AlertID     uint   `json:"alert_id" binding:"required"`
+	DryRun      bool   `json:"dry_run"`
+	TriggeredBy string `json:"triggered_by"`
+}
+
+// 聊天审批链接的决定
+const (
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+// ApprovalRequest 审批请求，审批人取自认证信息
+type ApprovalRequest struct {
+	Approver string `json:"-"`
+	Comment  string `json:"comment"`
+}
+
+// Config 自动化配置
+type Config struct {
+	DryRun          bool          `json:"dry_run"`           // 全局试运行，所有执行只记录不操作
+	RiskyActions    []ActionType  `json:"risky_actions"`     // 需要审批的操作类型
+	DefaultTimeout  time.Duration `json:"default_timeout"`   // 单个操作的默认超时
+	ApprovalTimeout time.Duration `json:"approval_timeout"`  // 超时未审批的执行标记为过期
+	RunningGrace    time.Duration `json:"running_grace"`     // 执行超过所有操作超时之和再加该时长仍未结束时视为 worker 已退出
+	PollInterval    time.Duration `json:"poll_interval"`     // worker 领取执行的间隔
+	Cooldown        time.Duration `json:"cooldown"`          // 运行手册未配置时的触发间隔
+	CallbackBaseURL string        `json:"callback_base_url"` // 聊天审批链接的服务地址
+	ApprovalSecret  string        `json:"-"`                 // 聊天审批链接签名密钥，为空时只能通过 API 审批
+}
+
+// DefaultConfig 默认自动化配置
+func DefaultConfig() Config {
+	return Config{
+		DryRun:          true,
+		RiskyActions:    []ActionType{ActionScript, ActionKubernetes},
+		DefaultTimeout:  time.Minute,
+		ApprovalTimeout: time.Hour,
+		RunningGrace:    5 * time.Minute,
+		PollInterval:    5 * time.Second,
+		Cooldown:        10 * time.Minute,
+	}
+}
@@ -0,0 +1,60 @@
+package automation
+
+import (
+	"context"
+	"time"
+)
+
+// RunbookRepository 运行手册仓储接口
+type RunbookRepository interface {
+	// Create 创建运行手册
+	Create(ctx context.Context, runbook *Runbook) error
+
+	// GetByID 根据ID获取运行手册，不存在时返回 nil
+	GetByID(ctx context.Context, id string) (*Runbook, error)
+
+	// List 获取运行手册列表，enabledOnly 为 true 时只返回启用的运行手册
+	List(ctx context.Context, enabledOnly bool) ([]*Runbook, error)
+
+	// Update 更新运行手册
+	Update(ctx context.Context, runbook *Runbook) error
+
+	// Delete 删除运行手册
+	Delete(ctx context.Context, id string) error
+}
+
+// ExecutionRepository 执行记录与操作日志仓储接口
+type ExecutionRepository interface {
+	// Create 创建执行记录
+	Create(ctx context.Context, execution *Execution) error
+
+	// GetByID 根据ID获取执行记录，不存在时返回 nil
+	GetByID(ctx context.Context, id string) (*Execution, error)
+
+	// GetLatest 获取运行手册针对告警的最近一次执行，不存在时返回 nil
+	GetLatest(ctx context.Context, runbookID string, alertID uint) (*Execution, error)
+
+	// List 获取执行记录，按创建时间倒序
+	List(ctx context.Context, filter *ExecutionFilter) ([]*Execution, int64, error)
+
+	// Update 更新执行记录
+	Update(ctx context.Context, execution *Execution) error
+
+	// Transition 仅当执行处于 from 状态时更新，返回是否更新成功，用于审批和 worker 领取的并发控制
+	Transition(ctx context.Context, execution *Execution, from ExecutionStatus) (bool, error)
+
+	// ListByStatus 获取指定状态的执行，按创建时间正序
+	ListByStatus(ctx context.Context, status ExecutionStatus, limit int) ([]*Execution, error)
+
+	// ListPendingBefore 获取创建时间早于 before 的待审批执行
+	ListPendingBefore(ctx context.Context, before time.Time) ([]*Execution, error)
+
+	// AddAction 保存操作日志
+	AddAction(ctx context.Context, action *AutomationAction) error
+
+	// ListActions 获取执行的操作日志，按时间正序
+	ListActions(ctx context.Context, executionID string) ([]*AutomationAction, error)
+
+	// ListActionsByAlert 获取告警的操作日志，按时间正序
+	ListActionsByAlert(ctx context.Context, alertID uint) ([]*AutomationAction, error)
+}
@@ -0,0 +1,96 @@
+package automation
+
+import (
+	"context"
+
+	"alert_agent/internal/domain/channel"
+	"alert_agent/internal/model"
+	"alert_agent/pkg/types"
+)
+
+// Service 自动化服务接口
+type Service interface {
+	// CreateRunbook 创建运行手册
+	CreateRunbook(ctx context.Context, req *RunbookRequest) (*Runbook, error)
+
+	// GetRunbook 获取运行手册
+	GetRunbook(ctx context.Context, id string) (*Runbook, error)
+
+	// ListRunbooks 获取运行手册列表
+	ListRunbooks(ctx context.Context) ([]*Runbook, error)
+
+	// UpdateRunbook 更新运行手册
+	UpdateRunbook(ctx context.Context, id string, req *RunbookRequest) (*Runbook, error)
+
+	// DeleteRunbook 删除运行手册
+	DeleteRunbook(ctx context.Context, id string) error
+
+	// TriggerRunbook 针对告警手工触发运行手册
+	TriggerRunbook(ctx context.Context, id string, req *TriggerRequest) (*Execution, error)
+
+	// TriggerForAlert 触发与告警匹配的运行手册
+	TriggerForAlert(ctx context.Context, alert *model.Alert) ([]*Execution, error)
+
+	// GetExecution 获取执行记录及操作日志
+	GetExecution(ctx context.Context, id string) (*Execution, []*AutomationAction, error)
+
+	// ListExecutions 获取执行记录
+	ListExecutions(ctx context.Context, filter *ExecutionFilter) ([]*Execution, int64, error)
+
+	// Approve 审批通过，执行交由 worker 处理
+	Approve(ctx context.Context, id string, req *ApprovalRequest) (*Execution, error)
+
+	// Reject 拒绝执行
+	Reject(ctx context.Context, id string, req *ApprovalRequest) (*Execution, error)
+
+	// VerifyCallback 校验聊天消息中的签名审批链接，不改变执行状态
+	VerifyCallback(ctx context.Context, id, decision, expires, signature string) (*Execution, error)
+
+	// HandleCallback 按签名审批链接记录审批结果，approver 为确认时认证的用户
+	HandleCallback(ctx context.Context, id, decision, expires, signature, approver string) (*Execution, error)
+
+	// ListAlertActions 获取告警的操作日志
+	ListAlertActions(ctx context.Context, alertID uint) ([]*AutomationAction, error)
+}
+
+// AlertLoader 按 ID 读取告警，由告警仓储实现
+type AlertLoader interface {
+	GetByID(ctx context.Context, id uint) (*model.Alert, error)
+}
+
+// ActionExecutor 执行某一类型的操作
+type ActionExecutor interface {
+	// Execute 执行已渲染模板的操作
+	Execute(ctx context.Context, action *Action) (*ActionResult, error)
+}
+
+type actionIDKey struct{}
+
+// WithActionID 在上下文中携带操作日志ID，执行器据此命名外部资源以便超时后清理
+func WithActionID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, actionIDKey{}, id)
+}
+
+// ActionIDFromContext 获取上下文中的操作日志ID，没有时返回空字符串
+func ActionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(actionIDKey{}).(string)
+	return id
+}
+
+// KubernetesClient Kubernetes 操作客户端，测试时可替换为桩实现
+type KubernetesClient interface {
+	// RestartDeployment 滚动重启 Deployment
+	RestartDeployment(ctx context.Context, namespace, name string) error
+
+	// ScaleDeployment 调整 Deployment 副本数，返回调整前的副本数
+	ScaleDeployment(ctx context.Context, namespace, name string, replicas int) (int, error)
+
+	// DeletePod 删除 Pod
+	DeletePod(ctx context.Context, namespace, name string) error
+}
+
+// MessageSender 发送审批消息，由通知渠道管理器实现
+type MessageSender interface {
+	// BroadcastMessage 向多个渠道发送消息
+	BroadcastMessage(ctx context.Context, channelIDs []string, message *types.Message) ([]*channel.SendResult, error)
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// 分类结果来源
+const (
+	ClassificationSourceModel = "model" // 本地模型
+	ClassificationSourceLLM   = "llm"   // 低置信度时由 LLM 兜底
+	ClassificationSourceRules = "rules" // 未训练模型时的关键词规则
+)
+
+// ClassifierConfig 告警分类配置
+type ClassifierConfig struct {
+	ConfidenceThreshold float64       `json:"confidence_threshold"` // 低于该置信度时调用 LLM 兜底
+	ReloadInterval      time.Duration `json:"reload_interval"`      // 检查启用模型版本的间隔
+	Smoothing           float64       `json:"smoothing"`            // 朴素贝叶斯拉普拉斯平滑系数
+}
+
+// DefaultClassifierConfig 默认告警分类配置
+func DefaultClassifierConfig() ClassifierConfig {
+	return ClassifierConfig{
+		ConfidenceThreshold: 0.6,
+		ReloadInterval:      5 * time.Minute,
+		Smoothing:           1,
+	}
+}
+
+// Classification 告警分类结果
+type Classification struct {
+	Category     string  `json:"category"`
+	Severity     string  `json:"severity"`   // 重新评估后的严重程度
+	Confidence   float64 `json:"confidence"` // 类别置信度 (0-1)
+	Source       string  `json:"source"`
+	ModelVersion string  `json:"model_version,omitempty"`
+}
+
+// AlertClassifier 告警分类器接口
+type AlertClassifier interface {
+	// Classify 预测告警类别并重新评估严重程度
+	Classify(ctx context.Context, alert *model.Alert) (*Classification, error)
+}
+
+// TrainingSample 带处理结论的历史告警
+type TrainingSample struct {
+	Alert    *model.Alert `json:"alert"`
+	Category string       `json:"category"`
+	Severity string       `json:"severity,omitempty"` // 为空时不参与严重程度训练
+}
+
+// ClassifierModel 分类模型版本，同一时间只有一个版本启用
+type ClassifierModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Version   string    `json:"version" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Active    bool      `json:"active" gorm:"index"`
+	Samples   int       `json:"samples"`
+	Accuracy  float64   `json:"accuracy"`                    // 留出集上的类别准确率
+	Params    string    `json:"-" gorm:"type:text;not null"` // 序列化的模型参数
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (ClassifierModel) TableName() string {
+	return "alert_classifier_models"
+}
+
+// ClassifierModelRepository 分类模型版本仓储接口
+type ClassifierModelRepository interface {
+	// Create 保存新版本
+	Create(ctx context.Context, model *ClassifierModel) error
+
+	// GetActive 获取启用的版本，没有时返回 nil
+	GetActive(ctx context.Context) (*ClassifierModel, error)
+
+	// List 获取最近的版本，按创建时间倒序
+	List(ctx context.Context, limit int) ([]*ClassifierModel, error)
+
+	// Activate 启用指定版本并停用其他版本，版本不存在时返回 NotFound 错误
+	Activate(ctx context.Context, version string) error
+
+	// ListTrainingSamples 获取 since 之后处理完成的告警及其确认的类别
+	ListTrainingSamples(ctx context.Context, since time.Time, limit int) ([]*TrainingSample, error)
+}
@@ -66,6 +66,10 @@ type RoutingDecision struct {
 	Confidence   float64                `json:"confidence"`
 	Metadata     map[string]interface{} `json:"metadata"`
 	DecisionTime time.Time              `json:"decision_time"`
+
+	// NotifyGroupIDs 路由树接收者的通知组，Grouping 为匹配路由的分组设置
+	NotifyGroupIDs []uint         `json:"notify_group_ids,omitempty"`
+	Grouping       *RouteGrouping `json:"grouping,omitempty"`
 }
 
 // ConvergenceResult 收敛结果
@@ -75,6 +79,7 @@ type ConvergenceResult struct {
 	Representative  *model.Alert           `json:"representative"`
 	SimilarAlerts   []*model.Alert         `json:"similar_alerts"`
 	ConvergenceRule string                 `json:"convergence_rule"`
+	RootCause       *RootCause             `json:"root_cause,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata"`
 }
 
@@ -87,6 +92,11 @@ type AlertContext struct {
 	HistoricalData  []HistoricalAlert      `json:"historical_data"`
 	RelatedMetrics  map[string]interface{} `json:"related_metrics"`
 	ProcessingHints map[string]interface{} `json:"processing_hints"`
+	Flapping        bool                   `json:"flapping"`
+	FlapState       *FlapState             `json:"flap_state,omitempty"`
+	RootCause       *RootCause             `json:"root_cause,omitempty"`
+	MetricSnapshot  *model.MetricSnapshot  `json:"metric_snapshot,omitempty"`
+	Grouping        *RouteGrouping         `json:"grouping,omitempty"` // 匹配路由的分组设置，收敛时使用
 }
 
 // HistoricalAlert 历史告警
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// FlappingConfig 抖动检测配置
+type FlappingConfig struct {
+	Window            time.Duration `json:"window"`             // 滑动窗口
+	HistorySize       int           `json:"history_size"`       // 参与计算的最大状态数
+	MinSamples        int           `json:"min_samples"`        // 判定抖动所需的最少状态数
+	HighThreshold     float64       `json:"high_threshold"`     // 状态变化百分比超过该值开始抖动
+	LowThreshold      float64       `json:"low_threshold"`      // 状态变化百分比低于该值结束抖动
+	HoldNotifications bool          `json:"hold_notifications"` // 抖动期间暂停通知
+	ReleaseInterval   time.Duration `json:"release_interval"`   // 重新评估抖动状态的间隔
+}
+
+// DefaultFlappingConfig 默认抖动检测配置，阈值与 Nagios 默认值一致
+func DefaultFlappingConfig() FlappingConfig {
+	return FlappingConfig{
+		Window:            time.Hour,
+		HistorySize:       21,
+		MinSamples:        5,
+		HighThreshold:     50,
+		LowThreshold:      25,
+		HoldNotifications: true,
+		ReleaseInterval:   time.Minute,
+	}
+}
+
+// StateSample 告警状态采样
+type StateSample struct {
+	Firing    bool      `json:"firing"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// FlapState 告警指纹的抖动状态
+type FlapState struct {
+	Fingerprint        string            `json:"fingerprint"`
+	AlertName          string            `json:"alert_name"`
+	Labels             map[string]string `json:"labels"`
+	LastAlertID        uint              `json:"last_alert_id"`
+	Samples            []StateSample     `json:"samples"`
+	Transitions        int               `json:"transitions"`
+	PercentStateChange float64           `json:"percent_state_change"`
+	Flapping           bool              `json:"flapping"`
+	FlappingSince      *time.Time        `json:"flapping_since,omitempty"`
+	StabilizedAt       *time.Time        `json:"stabilized_at,omitempty"`
+	HeldNotifications  int               `json:"held_notifications"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// FlapStateStore 抖动状态存储接口
+type FlapStateStore interface {
+	// Get 获取指纹的抖动状态，不存在时返回 nil
+	Get(ctx context.Context, fingerprint string) (*FlapState, error)
+
+	// Update 以原子方式读取并更新指纹的抖动状态
+	Update(ctx context.Context, fingerprint string, fn func(state *FlapState) (*FlapState, error)) (*FlapState, error)
+
+	// ListFlapping 获取所有正在抖动的状态
+	ListFlapping(ctx context.Context) ([]*FlapState, error)
+}
+
+// FlapDetector 抖动检测器接口
+type FlapDetector interface {
+	// Observe 记录告警状态并返回最新的抖动状态
+	Observe(ctx context.Context, alert *model.Alert, at time.Time) (*FlapState, error)
+
+	// ShouldHold 判断是否暂停该状态下的通知
+	ShouldHold(state *FlapState) bool
+
+	// RecordHeld 记录一次被暂停的通知
+	RecordHeld(ctx context.Context, fingerprint string) error
+
+	// Reevaluate 按时间 at 重新计算抖动状态，告警不再发送时由此结束抖动
+	Reevaluate(ctx context.Context, fingerprint string, at time.Time) (*FlapState, error)
+
+	// GetState 获取指纹的抖动状态
+	GetState(ctx context.Context, fingerprint string) (*FlapState, error)
+
+	// ListFlapping 获取正在抖动的告警
+	ListFlapping(ctx context.Context) ([]*FlapState, error)
+}
+
+// AlertLoader 按 ID 读取告警，由告警仓储实现
+type AlertLoader interface {
+	GetByID(ctx context.Context, id uint) (*model.Alert, error)
+}
@@ -58,6 +58,9 @@ type AlertRouter interface {
 	
 	// ValidateRouting 验证路由决策
 	ValidateRouting(ctx context.Context, decision *RoutingDecision) error
+
+	// Grouping 获取告警匹配路由的分组设置，没有生效的路由树或未匹配时返回 nil
+	Grouping(ctx context.Context, alertCtx *AlertContext) (*RouteGrouping, error)
 }
 
 // AlertSuppressor 告警抑制器接口
@@ -77,6 +80,9 @@ type AlertConverger interface {
 	// Converge 收敛告警
 	Converge(ctx context.Context, alerts []*model.Alert) (*ConvergenceResult, error)
 	
+	// ShouldConverge 将告警加入收敛窗口并判断是否被收敛
+	ShouldConverge(ctx context.Context, alertCtx *AlertContext) (bool, *ConvergenceResult, error)
+	
 	// FindSimilarAlerts 查找相似告警
 	FindSimilarAlerts(ctx context.Context, alert *model.Alert) ([]*model.Alert, error)
 	
@@ -138,11 +144,13 @@ type SmartRoutingStrategy interface {
 // AnalysisResult 分析结果
 type AnalysisResult struct {
 	Severity    string                 `json:"severity"`
+	OriginalSeverity string            `json:"original_severity,omitempty"` // 重新评估前的告警级别
 	Category    string                 `json:"category"`
 	RootCause   string                 `json:"root_cause"`
 	Impact      string                 `json:"impact"`
 	Recommendations []string           `json:"recommendations"`
 	Confidence  float64                `json:"confidence"`
+	ModelVersion string                `json:"model_version,omitempty"` // 分类模型版本，规则分类时为空
 	Metadata    map[string]interface{} `json:"metadata"`
 }
 
@@ -174,4 +182,16 @@ type MetricsCollector interface {
 	
 	// RecordError 记录错误
 	RecordError(ctx context.Context, operation string, err error)
-}
\ No newline at end of file
+}
+
+// IncidentTracker 根据收敛分组维护事件，收敛到同一分组的告警归入同一事件
+type IncidentTracker interface {
+	// TrackConvergence 为收敛分组创建或更新事件并关联分组内的告警
+	TrackConvergence(ctx context.Context, alert *model.Alert, result *ConvergenceResult) error
+}
+
+// RunbookTrigger 为路由后的告警触发匹配的运行手册
+type RunbookTrigger interface {
+	// TriggerRunbooks 触发与告警匹配的运行手册，返回创建的执行ID
+	TriggerRunbooks(ctx context.Context, alert *model.Alert) ([]string, error)
+}
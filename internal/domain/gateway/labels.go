@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"alert_agent/internal/model"
+)
+
+// ParseAlertLabels 解析告警标签并补充 alertname/severity/level/source 等内置标签
+func ParseAlertLabels(alert *model.Alert) map[string]string {
+	labels := make(map[string]string)
+	if alert == nil {
+		return labels
+	}
+
+	if alert.Labels != "" {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(alert.Labels), &raw); err == nil {
+			for k, v := range raw {
+				if s, ok := v.(string); ok {
+					labels[k] = s
+				} else if v != nil {
+					labels[k] = fmt.Sprint(v)
+				}
+			}
+		}
+	}
+
+	// 内置标签不覆盖显式标签
+	builtins := map[string]string{
+		"alertname": alert.Name,
+		"severity":  alert.Severity,
+		"level":     alert.Level,
+		"source":    alert.Source,
+	}
+	if alert.RuleID != 0 {
+		builtins["rule_id"] = fmt.Sprint(alert.RuleID)
+	}
+	for k, v := range builtins {
+		if _, exists := labels[k]; !exists && v != "" {
+			labels[k] = v
+		}
+	}
+
+	return labels
+}
+
+// AlertFingerprint 根据告警标签计算稳定的指纹，同一告警的多次触发指纹相同
+func AlertFingerprint(alert *model.Alert) string {
+	labels := ParseAlertLabels(alert)
+	// 级别可能随触发次数变化，不参与指纹计算
+	delete(labels, "level")
+	delete(labels, "severity")
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[name]))
+		h.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
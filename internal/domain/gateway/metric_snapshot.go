@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/provider"
+)
+
+// MetricSnapshotConfig 指标快照配置
+type MetricSnapshotConfig struct {
+	Lookback         time.Duration `json:"lookback"`           // 触发时间之前的查询范围
+	Lookahead        time.Duration `json:"lookahead"`          // 触发时间之后的查询范围，不超过当前时间
+	MaxPoints        int           `json:"max_points"`         // 每条序列保留的数据点数
+	MaxRelatedSeries int           `json:"max_related_series"` // 关联序列最大数量
+	RelatedQueries   []string      `json:"related_queries"`    // 关联查询模板，双引号中的 {{label}} 替换为转义后的告警标签值
+	Timeout          time.Duration `json:"timeout"`            // 采集超时
+}
+
+// DefaultMetricSnapshotConfig 默认指标快照配置
+func DefaultMetricSnapshotConfig() MetricSnapshotConfig {
+	return MetricSnapshotConfig{
+		Lookback:         30 * time.Minute,
+		Lookahead:        5 * time.Minute,
+		MaxPoints:        60,
+		MaxRelatedSeries: 3,
+		Timeout:          10 * time.Second,
+	}
+}
+
+// MetricSnapshotter 指标快照采集接口
+type MetricSnapshotter interface {
+	// Snapshot 查询告警规则表达式在触发时间附近的数据并保存到告警，无规则表达式时返回 nil
+	Snapshot(ctx context.Context, alert *model.Alert) (*model.MetricSnapshot, error)
+}
+
+// MetricSnapshotRepository 指标快照所需的规则查询与快照存储
+type MetricSnapshotRepository interface {
+	// GetRule 获取告警规则，不存在时返回 nil
+	GetRule(ctx context.Context, ruleID uint) (*model.Rule, error)
+
+	// SaveMetricSnapshot 保存告警指标快照，并附加到尚未发送的通知内容
+	SaveMetricSnapshot(ctx context.Context, alertID uint, snapshot *model.MetricSnapshot) error
+}
+
+// RangeQueryClientFactory 根据数据源创建范围查询客户端
+type RangeQueryClientFactory interface {
+	// RangeQueryClient 获取数据源范围查询客户端及数据源信息
+	RangeQueryClient(ctx context.Context, providerID uint) (RangeQueryClient, *model.Provider, error)
+}
+
+// RangeQueryClient 范围查询客户端
+type RangeQueryClient interface {
+	QueryRange(ctx context.Context, params url.Values) (*provider.APIResponse, error)
+}
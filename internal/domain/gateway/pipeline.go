@@ -0,0 +1,152 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// PipelineStage 网关处理管道阶段
+type PipelineStage string
+
+const (
+	StageEnrichment  PipelineStage = "enrichment"  // 上下文丰富
+	StageSuppression PipelineStage = "suppression" // 抑制与抖动检测
+	StageProcessing  PipelineStage = "processing"  // 按处理模式执行处理策略
+	StageConvergence PipelineStage = "convergence" // 收敛
+	StageRouting     PipelineStage = "routing"     // 路由
+)
+
+// PipelineConfig 处理管道配置
+type PipelineConfig struct {
+	Consumer      string        `json:"consumer"`       // 消费者名称，需在消费组内唯一
+	Concurrency   int           `json:"concurrency"`    // 并发处理数
+	BatchSize     int64         `json:"batch_size"`     // 单次读取的消息数
+	BlockTimeout  time.Duration `json:"block_timeout"`  // 读取阻塞时长
+	ClaimMinIdle  time.Duration `json:"claim_min_idle"` // 超过该空闲时长的待确认消息可被接管
+	ClaimInterval time.Duration `json:"claim_interval"` // 接管检查间隔
+	MaxDeliveries int64         `json:"max_deliveries"` // 超过投递次数转入死信
+	StatsInterval time.Duration `json:"stats_interval"` // 积压指标采集间隔
+}
+
+// DefaultPipelineConfig 默认处理管道配置
+func DefaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{
+		Concurrency:   4,
+		BatchSize:     16,
+		BlockTimeout:  5 * time.Second,
+		ClaimMinIdle:  time.Minute,
+		ClaimInterval: 30 * time.Second,
+		MaxDeliveries: 5,
+		StatsInterval: 15 * time.Second,
+	}
+}
+
+// PipelineMessage 管道消息
+type PipelineMessage struct {
+	ID         string       `json:"id"` // 流消息ID，由存储生成
+	RecordID   string       `json:"record_id"`
+	Alert      *model.Alert `json:"alert"`
+	EnqueuedAt time.Time    `json:"enqueued_at"`
+	Replayed   int          `json:"replayed"`   // 从死信重放的次数
+	Deliveries int64        `json:"deliveries"` // 当前投递次数，由存储填充
+
+	// Stabilized 抖动结束后补发的通知，HeldNotifications 为抖动期间暂停的通知数
+	Stabilized        bool `json:"stabilized,omitempty"`
+	HeldNotifications int  `json:"held_notifications,omitempty"`
+}
+
+// DeadLetter 死信消息
+type DeadLetter struct {
+	ID       string           `json:"id"`
+	Message  *PipelineMessage `json:"message"`
+	Stage    PipelineStage    `json:"stage"`
+	Error    string           `json:"error"`
+	FailedAt time.Time        `json:"failed_at"`
+	Consumer string           `json:"consumer"`
+}
+
+// ConsumerStats 消费者统计
+type ConsumerStats struct {
+	Name    string        `json:"name"`
+	Pending int64         `json:"pending"`
+	Idle    time.Duration `json:"idle"`
+}
+
+// PipelineStats 处理管道统计
+type PipelineStats struct {
+	Stream      string          `json:"stream"`
+	Group       string          `json:"group"`
+	Length      int64           `json:"length"`
+	Pending     int64           `json:"pending"`
+	Lag         int64           `json:"lag"`
+	DeadLetters int64           `json:"dead_letters"`
+	Consumers   []ConsumerStats `json:"consumers"`
+}
+
+// AlertStream 告警处理流接口
+type AlertStream interface {
+	// EnsureGroup 确保消费组存在
+	EnsureGroup(ctx context.Context) error
+
+	// Publish 发布消息，返回消息ID
+	Publish(ctx context.Context, msg *PipelineMessage) (string, error)
+
+	// Read 以消费者身份读取新消息
+	Read(ctx context.Context, consumer string, count int64, block time.Duration) ([]*PipelineMessage, error)
+
+	// Claim 接管空闲超过 minIdle 的待确认消息
+	Claim(ctx context.Context, consumer string, minIdle time.Duration, count int64) ([]*PipelineMessage, error)
+
+	// Ack 确认消息处理完成
+	Ack(ctx context.Context, ids ...string) error
+
+	// TrimAcked 删除已确认的历史消息，返回删除的消息数
+	TrimAcked(ctx context.Context) (int64, error)
+
+	// DeadLetter 将消息转入死信流并确认原消息
+	DeadLetter(ctx context.Context, msg *PipelineMessage, stage PipelineStage, consumer string, cause error) error
+
+	// ListDeadLetters 获取死信消息
+	ListDeadLetters(ctx context.Context, limit int64) ([]*DeadLetter, error)
+
+	// GetDeadLetter 获取死信消息，不存在时返回 nil
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+
+	// Replay 将死信消息重新发布到处理流并从死信流删除，返回新的消息ID
+	Replay(ctx context.Context, deadLetter *DeadLetter) (string, error)
+
+	// Stats 获取处理流统计
+	Stats(ctx context.Context) (*PipelineStats, error)
+}
+
+// PipelineHandler 管道消息处理接口
+type PipelineHandler interface {
+	// ProcessMessage 按阶段处理消息，失败时返回失败所在的阶段
+	ProcessMessage(ctx context.Context, msg *PipelineMessage) (PipelineStage, error)
+}
+
+// PipelineService 处理管道管理服务接口
+type PipelineService interface {
+	// GetStats 获取处理管道统计
+	GetStats(ctx context.Context) (*PipelineStats, error)
+
+	// ListDeadLetters 获取死信消息
+	ListDeadLetters(ctx context.Context, limit int64) ([]*DeadLetter, error)
+
+	// ReplayDeadLetter 重放死信消息
+	ReplayDeadLetter(ctx context.Context, id string) (string, error)
+}
+
+// PipelineMetrics 处理管道指标接口
+type PipelineMetrics interface {
+	// ObserveStats 记录积压统计
+	ObserveStats(stats *PipelineStats)
+
+	// RecordMessage 记录消息处理结果（acked/retry/dead_letter）
+	RecordMessage(status string, duration time.Duration)
+
+	// RecordDeadLetter 记录死信
+	RecordDeadLetter(stage PipelineStage)
+}
@@ -0,0 +1,164 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// MatchType 标签匹配类型
+type MatchType string
+
+const (
+	MatchEqual     MatchType = "="  // 等于
+	MatchNotEqual  MatchType = "!=" // 不等于
+	MatchRegexp    MatchType = "=~" // 正则匹配
+	MatchNotRegexp MatchType = "!~" // 正则不匹配
+)
+
+// Matcher 标签匹配器
+type Matcher struct {
+	Name  string    `json:"name"`
+	Type  MatchType `json:"type"`
+	Value string    `json:"value"`
+}
+
+// Route 路由树节点
+type Route struct {
+	Receiver            string    `json:"receiver,omitempty"`
+	Matchers            []Matcher `json:"matchers,omitempty"`
+	Continue            bool      `json:"continue,omitempty"`
+	GroupBy             []string  `json:"group_by,omitempty"`
+	GroupWait           int64     `json:"group_wait,omitempty"`      // 分组等待时长（秒）
+	GroupInterval       int64     `json:"group_interval,omitempty"`  // 分组通知间隔（秒）
+	RepeatInterval      int64     `json:"repeat_interval,omitempty"` // 重复通知间隔（秒）
+	ActiveTimeIntervals []string  `json:"active_time_intervals,omitempty"`
+	Routes              []*Route  `json:"routes,omitempty"`
+}
+
+// Receiver 路由接收者
+type Receiver struct {
+	Name           string   `json:"name"`
+	ChannelIDs     []string `json:"channel_ids,omitempty"`
+	NotifyGroupIDs []uint   `json:"notify_group_ids,omitempty"`
+}
+
+// DayTimeRange 一天内的时间段，格式 HH:MM
+type DayTimeRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// TimeInterval 命名时间区间
+type TimeInterval struct {
+	Name     string         `json:"name"`
+	Weekdays []string       `json:"weekdays,omitempty"` // 如 "monday" 或 "monday:friday"
+	Times    []DayTimeRange `json:"times,omitempty"`
+	Location string         `json:"location,omitempty"` // 时区，默认 Local
+}
+
+// RoutingConfig 版本化的路由配置
+type RoutingConfig struct {
+	ID            string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Version       int            `json:"version" gorm:"not null;uniqueIndex"`
+	Name          string         `json:"name" gorm:"type:varchar(255)"`
+	Description   string         `json:"description" gorm:"type:text"`
+	Route         *Route         `json:"route" gorm:"type:text;serializer:json"`
+	Receivers     []Receiver     `json:"receivers" gorm:"type:text;serializer:json"`
+	TimeIntervals []TimeInterval `json:"time_intervals" gorm:"type:text;serializer:json"`
+	Active        bool           `json:"active" gorm:"not null;default:false;index"`
+	CreatedBy     string         `json:"created_by" gorm:"type:varchar(100)"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RoutingConfig) TableName() string {
+	return "routing_configs"
+}
+
+// RouteMatch 路由匹配结果
+type RouteMatch struct {
+	Path           string   `json:"path"`
+	Receiver       string   `json:"receiver"`
+	ChannelIDs     []string `json:"channel_ids"`
+	NotifyGroupIDs []uint   `json:"notify_group_ids"`
+	GroupBy        []string `json:"group_by"`
+	GroupWait      int64    `json:"group_wait"`
+	GroupInterval  int64    `json:"group_interval"`
+	RepeatInterval int64    `json:"repeat_interval"`
+	Active         bool     `json:"active"`
+}
+
+// RouteGrouping 匹配路由的分组设置，收敛按 GroupBy 与 GroupInterval 分组，通知按 GroupWait 与 RepeatInterval 发送
+type RouteGrouping struct {
+	Receiver       string   `json:"receiver"`
+	GroupBy        []string `json:"group_by,omitempty"`
+	GroupWait      int64    `json:"group_wait,omitempty"`      // 分组等待时长（秒）
+	GroupInterval  int64    `json:"group_interval,omitempty"`  // 分组通知间隔（秒）
+	RepeatInterval int64    `json:"repeat_interval,omitempty"` // 重复通知间隔（秒）
+}
+
+// CreateRoutingConfigRequest 创建路由配置请求
+type CreateRoutingConfigRequest struct {
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Route         *Route         `json:"route"`
+	Receivers     []Receiver     `json:"receivers"`
+	TimeIntervals []TimeInterval `json:"time_intervals"`
+	Activate      bool           `json:"activate"`
+	CreatedBy     string         `json:"created_by"`
+}
+
+// RouteTestRequest 路由测试请求
+type RouteTestRequest struct {
+	Version int               `json:"version,omitempty"` // 为空时使用当前生效版本
+	Labels  map[string]string `json:"labels,omitempty"`
+	Alert   *model.Alert      `json:"alert,omitempty"`
+	Time    *time.Time        `json:"time,omitempty"`
+}
+
+// RouteTestResult 路由测试结果
+type RouteTestResult struct {
+	Version        int               `json:"version"`
+	Labels         map[string]string `json:"labels"`
+	Matches        []*RouteMatch     `json:"matches"`
+	Receivers      []string          `json:"receivers"`
+	ChannelIDs     []string          `json:"channel_ids"`
+	NotifyGroupIDs []uint            `json:"notify_group_ids"`
+}
+
+// RoutingConfigRepository 路由配置仓储接口
+type RoutingConfigRepository interface {
+	Create(ctx context.Context, config *RoutingConfig) error
+	GetByVersion(ctx context.Context, version int) (*RoutingConfig, error)
+	GetActive(ctx context.Context) (*RoutingConfig, error)
+	List(ctx context.Context, limit, offset int) ([]*RoutingConfig, int64, error)
+	LatestVersion(ctx context.Context) (int, error)
+	Activate(ctx context.Context, version int) error
+}
+
+// RoutingConfigService 路由配置服务接口
+type RoutingConfigService interface {
+	// CreateConfig 创建新版本的路由配置
+	CreateConfig(ctx context.Context, req *CreateRoutingConfigRequest) (*RoutingConfig, error)
+
+	// GetConfig 获取指定版本的路由配置
+	GetConfig(ctx context.Context, version int) (*RoutingConfig, error)
+
+	// GetActiveConfig 获取当前生效的路由配置
+	GetActiveConfig(ctx context.Context) (*RoutingConfig, error)
+
+	// ListConfigs 获取路由配置版本列表
+	ListConfigs(ctx context.Context, limit, offset int) ([]*RoutingConfig, int64, error)
+
+	// ActivateConfig 激活指定版本
+	ActivateConfig(ctx context.Context, version int) error
+
+	// TestRoute 测试示例告警会被路由到哪些接收者
+	TestRoute(ctx context.Context, req *RouteTestRequest) (*RouteTestResult, error)
+
+	// Match 使用当前生效配置匹配告警，没有生效配置时返回 nil
+	Match(ctx context.Context, alert *model.Alert, at time.Time) ([]*RouteMatch, error)
+}
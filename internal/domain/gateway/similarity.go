@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// SimilarityWeights 相似度各信号权重，不可用的信号不参与加权
+type SimilarityWeights struct {
+	Labels    float64 `json:"labels"`    // 标签 Jaccard 相似度
+	Text      float64 `json:"text"`      // 标题与内容的 TF-IDF 余弦相似度
+	Time      float64 `json:"time"`      // 时间接近度
+	Embedding float64 `json:"embedding"` // 向量余弦相似度
+}
+
+// SimilarityConfig 相似度计算配置
+type SimilarityConfig struct {
+	Weights           SimilarityWeights `json:"weights"`
+	TimeDecayHalfLife time.Duration     `json:"time_decay_half_life"` // 时间差达到该值时时间相似度衰减为 0.5
+	EmbeddingCache    int               `json:"embedding_cache"`      // 向量缓存条数
+	CorpusWindow      int               `json:"corpus_window"`        // 计算逆文档频率的最近告警数，为 0 时不限制
+}
+
+// DefaultSimilarityConfig 默认相似度计算配置
+func DefaultSimilarityConfig() SimilarityConfig {
+	return SimilarityConfig{
+		Weights: SimilarityWeights{
+			Labels:    0.45,
+			Text:      0.35,
+			Time:      0.2,
+			Embedding: 0,
+		},
+		TimeDecayHalfLife: 30 * time.Minute,
+		EmbeddingCache:    1024,
+		CorpusWindow:      10000,
+	}
+}
+
+// SimilarityScore 相似度计算结果，Signals 仅包含参与计算的信号
+type SimilarityScore struct {
+	Total   float64            `json:"total"`
+	Signals map[string]float64 `json:"signals"`
+}
+
+// SimilarityScorer 告警相似度计算接口
+type SimilarityScorer interface {
+	// Score 计算两条告警的相似度
+	Score(ctx context.Context, a, b *model.Alert) (*SimilarityScore, error)
+
+	// Observe 将告警加入文本语料，用于计算逆文档频率
+	Observe(alert *model.Alert)
+}
+
+// EmbeddingProvider 文本向量提供者
+type EmbeddingProvider interface {
+	// Embed 生成文本向量
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
@@ -0,0 +1,62 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ServiceDependency 服务及其依赖的上游服务
+type ServiceDependency struct {
+	Name      string   `json:"name" yaml:"name"`
+	DependsOn []string `json:"depends_on" yaml:"depends_on"`
+}
+
+// TopologyDefinition 服务依赖拓扑定义
+type TopologyDefinition struct {
+	Services []ServiceDependency `json:"services" yaml:"services"`
+}
+
+// TopologyConfig 拓扑关联配置
+type TopologyConfig struct {
+	Window        time.Duration `json:"window"`         // 跨服务关联的时间窗口
+	ServiceLabel  string        `json:"service_label"`  // 标识服务的标签
+	UpstreamLabel string        `json:"upstream_label"` // 标识上游依赖的标签，多个以逗号分隔
+	MaxAlerts     int           `json:"max_alerts"`     // 每个关联事件保留的告警数上限，为 0 时不限制
+}
+
+// DefaultTopologyConfig 默认拓扑关联配置
+func DefaultTopologyConfig() TopologyConfig {
+	return TopologyConfig{
+		Window:        10 * time.Minute,
+		ServiceLabel:  "service",
+		UpstreamLabel: "upstream",
+		MaxAlerts:     100,
+	}
+}
+
+// RootCause 拓扑关联推断的可能根因
+type RootCause struct {
+	Service          string   `json:"service"`
+	AlertID          uint     `json:"alert_id"`
+	AlertName        string   `json:"alert_name"`
+	AffectedServices []string `json:"affected_services"` // 受根因影响的下游告警服务
+	Confidence       float64  `json:"confidence"`
+}
+
+// String 根因描述
+func (r *RootCause) String() string {
+	if r == nil {
+		return ""
+	}
+	return fmt.Sprintf("service %s (%s)", r.Service, r.AlertName)
+}
+
+// TopologyCorrelator 基于服务依赖拓扑的跨服务告警关联接口
+type TopologyCorrelator interface {
+	// Correlate 将告警并入时间窗口内拓扑相关的事件，未关联到其他服务时返回 nil
+	Correlate(ctx context.Context, alertCtx *AlertContext) (*ConvergenceResult, error)
+
+	// Topology 获取当前服务依赖拓扑，包括从标签学习到的依赖
+	Topology() *TopologyDefinition
+}
@@ -0,0 +1,207 @@
+package incident
+
+import (
+	"time"
+)
+
+// Status 事件状态
+type Status string
+
+const (
+	StatusTriggered    Status = "triggered"    // 已触发
+	StatusAcknowledged Status = "acknowledged" // 已确认
+	StatusMitigated    Status = "mitigated"    // 已缓解
+	StatusResolved     Status = "resolved"     // 已解决
+)
+
+// statusOrder 状态推进顺序，已解决的事件可以重新打开为 triggered
+var statusOrder = map[Status]int{
+	StatusTriggered:    0,
+	StatusAcknowledged: 1,
+	StatusMitigated:    2,
+	StatusResolved:     3,
+}
+
+// IsValid 判断状态是否合法
+func (s Status) IsValid() bool {
+	_, ok := statusOrder[s]
+	return ok
+}
+
+// CanTransitionTo 判断能否从当前状态变更到目标状态：只能向前推进，或将已解决的事件重新打开
+func (s Status) CanTransitionTo(target Status) bool {
+	if !target.IsValid() || s == target {
+		return false
+	}
+	if s == StatusResolved {
+		return target == StatusTriggered
+	}
+	return statusOrder[target] > statusOrder[s]
+}
+
+// Severity 事件严重程度，与告警级别一致
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+)
+
+// severityRank 严重程度排序，数值越大越严重
+var severityRank = map[Severity]int{
+	SeverityLow:      1,
+	SeverityMedium:   2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// IsValid 判断严重程度是否合法
+func (s Severity) IsValid() bool {
+	_, ok := severityRank[s]
+	return ok
+}
+
+// Higher 判断是否比另一严重程度更严重
+func (s Severity) Higher(other Severity) bool {
+	return severityRank[s] > severityRank[other]
+}
+
+// Source 事件来源
+type Source string
+
+const (
+	SourceManual      Source = "manual"      // 手工创建
+	SourceConvergence Source = "convergence" // 由告警收敛分组自动创建
+)
+
+// Incident 事件，聚合相关告警并记录处理时间线
+type Incident struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Title          string     `json:"title" gorm:"type:varchar(255);not null"`
+	Description    string     `json:"description" gorm:"type:text"`
+	Status         Status     `json:"status" gorm:"type:varchar(20);not null;index"`
+	Severity       Severity   `json:"severity" gorm:"type:varchar(20);not null;index"`
+	Assignee       string     `json:"assignee" gorm:"type:varchar(100);index"`
+	Source         Source     `json:"source" gorm:"type:varchar(20);not null"`
+	GroupID        string     `json:"group_id,omitempty" gorm:"type:varchar(255);index"` // 自动创建时对应的收敛分组
+	RootCause      string     `json:"root_cause,omitempty" gorm:"type:text"`
+	CreatedBy      string     `json:"created_by" gorm:"type:varchar(100)"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	MitigatedAt    *time.Time `json:"mitigated_at,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	AlertIDs []uint `json:"alert_ids" gorm:"-"`
+}
+
+// TableName 指定表名
+func (Incident) TableName() string {
+	return "incidents"
+}
+
+// IncidentAlert 事件与告警的关联，一个告警同一时间只属于一个事件
+type IncidentAlert struct {
+	IncidentID string    `json:"incident_id" gorm:"type:varchar(36);not null;index"`
+	AlertID    uint      `json:"alert_id" gorm:"primaryKey;autoIncrement:false"`
+	AttachedBy string    `json:"attached_by" gorm:"type:varchar(100)"`
+	AttachedAt time.Time `json:"attached_at"`
+}
+
+// TableName 指定表名
+func (IncidentAlert) TableName() string {
+	return "incident_alerts"
+}
+
+// TimelineEntryType 时间线条目类型
+type TimelineEntryType string
+
+const (
+	TimelineCreated       TimelineEntryType = "created"        // 事件创建
+	TimelineStatusChange  TimelineEntryType = "status_change"  // 状态变更，包括确认、缓解、解决
+	TimelineAssignment    TimelineEntryType = "assignment"     // 指派处理人
+	TimelineUpdate        TimelineEntryType = "update"         // 标题、描述、严重程度变更
+	TimelineAlertAttached TimelineEntryType = "alert_attached" // 关联告警
+	TimelineAlertDetached TimelineEntryType = "alert_detached" // 解除关联告警
+	TimelineNotification  TimelineEntryType = "notification"   // 通知发送
+	TimelineAnalysis      TimelineEntryType = "analysis"       // 分析结果
+	TimelineComment       TimelineEntryType = "comment"        // 评论
+)
+
+// IsValid 判断条目类型是否合法
+func (t TimelineEntryType) IsValid() bool {
+	switch t {
+	case TimelineCreated, TimelineStatusChange, TimelineAssignment, TimelineUpdate,
+		TimelineAlertAttached, TimelineAlertDetached, TimelineNotification, TimelineAnalysis, TimelineComment:
+		return true
+	}
+	return false
+}
+
+// TimelineEntry 事件时间线条目
+type TimelineEntry struct {
+	ID         uint                   `json:"id" gorm:"primaryKey"`
+	IncidentID string                 `json:"incident_id" gorm:"type:varchar(36);not null;index"`
+	Type       TimelineEntryType      `json:"type" gorm:"type:varchar(30);not null"`
+	Actor      string                 `json:"actor" gorm:"type:varchar(100)"`
+	Message    string                 `json:"message" gorm:"type:text"`
+	Data       map[string]interface{} `json:"data,omitempty" gorm:"type:text;serializer:json"`
+	CreatedAt  time.Time              `json:"created_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (TimelineEntry) TableName() string {
+	return "incident_timeline"
+}
+
+// CreateIncidentRequest 创建事件请求
+type CreateIncidentRequest struct {
+	Title       string   `json:"title" binding:"required"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Assignee    string   `json:"assignee"`
+	AlertIDs    []uint   `json:"alert_ids"`
+	CreatedBy   string   `json:"-"` // 创建人，取自认证用户
+}
+
+// UpdateIncidentRequest 更新事件请求，为空的字段不修改
+type UpdateIncidentRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Severity    *Severity `json:"severity"`
+	Assignee    *string   `json:"assignee"`
+	Actor       string    `json:"-"` // 操作人，取自认证用户
+}
+
+// UpdateStatusRequest 变更事件状态请求
+type UpdateStatusRequest struct {
+	Status        Status `json:"status" binding:"required"`
+	Note          string `json:"note"`
+	ResolveAlerts bool   `json:"resolve_alerts"` // 解决事件时同时恢复关联告警
+	Actor         string `json:"-"`              // 操作人，取自认证用户
+}
+
+// AttachAlertsRequest 关联或解除关联告警请求
+type AttachAlertsRequest struct {
+	AlertIDs []uint `json:"alert_ids" binding:"required"`
+	Actor    string `json:"-"` // 操作人，取自认证用户
+}
+
+// AddTimelineEntryRequest 添加时间线条目请求
+type AddTimelineEntryRequest struct {
+	Type    TimelineEntryType      `json:"type"`
+	Message string                 `json:"message" binding:"required"`
+	Data    map[string]interface{} `json:"data"`
+	Actor   string                 `json:"-"` // 操作人，取自认证用户
+}
+
+// Filter 事件查询条件
+type Filter struct {
+	Status   Status   `json:"status"`
+	Severity Severity `json:"severity"`
+	Assignee string   `json:"assignee"`
+	Limit    int      `json:"limit"`
+	Offset   int      `json:"offset"`
+}
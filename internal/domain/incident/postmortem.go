@@ -0,0 +1,93 @@
+package incident
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+)
+
+// PostmortemRequest 生成复盘报告请求，IncidentID 与 AlertIDs 至少提供一个
+type PostmortemRequest struct {
+	IncidentID      string `json:"incident_id,omitempty"`
+	AlertIDs        []uint `json:"alert_ids,omitempty"`
+	Title           string `json:"title,omitempty"`
+	Template        string `json:"template,omitempty"`
+	SaveToKnowledge bool   `json:"save_to_knowledge"`
+	Actor           string `json:"-"` // 操作人，取自认证用户
+}
+
+// PostmortemEvent 复盘时间线事件
+type PostmortemEvent struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Actor   string    `json:"actor,omitempty"`
+	Message string    `json:"message"`
+}
+
+// PostmortemFinding AI 分析结论
+type PostmortemFinding struct {
+	AlertID         uint      `json:"alert_id"`
+	AnalysisType    string    `json:"analysis_type"`
+	RootCause       string    `json:"root_cause,omitempty"`
+	Impact          string    `json:"impact,omitempty"`
+	Recommendations []string  `json:"recommendations,omitempty"`
+	Confidence      float64   `json:"confidence"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// PostmortemData 复盘模板数据
+type PostmortemData struct {
+	Title            string               `json:"title"`
+	Incident         *Incident            `json:"incident,omitempty"`
+	Alerts           []*model.Alert       `json:"alerts"`
+	ImpactedServices []string             `json:"impacted_services"`
+	StartedAt        time.Time            `json:"started_at"`
+	AcknowledgedAt   *time.Time           `json:"acknowledged_at,omitempty"`
+	ResolvedAt       *time.Time           `json:"resolved_at,omitempty"`
+	MTTA             time.Duration        `json:"mtta"`
+	MTTR             time.Duration        `json:"mttr"`
+	Timeline         []*PostmortemEvent   `json:"timeline"`
+	Findings         []*PostmortemFinding `json:"findings"`
+	ActionItems      []string             `json:"action_items"`
+	GeneratedAt      time.Time            `json:"generated_at"`
+	GeneratedBy      string               `json:"generated_by,omitempty"`
+}
+
+// Postmortem 复盘报告草稿
+type Postmortem struct {
+	Title            string        `json:"title"`
+	IncidentID       string        `json:"incident_id,omitempty"`
+	AlertIDs         []uint        `json:"alert_ids"`
+	ImpactedServices []string      `json:"impacted_services"`
+	MTTA             time.Duration `json:"mtta"`
+	MTTR             time.Duration `json:"mttr"`
+	Markdown         string        `json:"markdown"`
+	KnowledgeID      uint          `json:"knowledge_id,omitempty"`
+	GeneratedAt      time.Time     `json:"generated_at"`
+}
+
+// PostmortemService 复盘报告服务接口
+type PostmortemService interface {
+	// GeneratePostmortem 根据事件或告警集合生成 Markdown 复盘草稿
+	GeneratePostmortem(ctx context.Context, req *PostmortemRequest) (*Postmortem, error)
+}
+
+// PostmortemRepository 复盘报告所需的告警、通知与知识库数据访问
+type PostmortemRepository interface {
+	// ListAlerts 批量获取告警，按创建时间正序
+	ListAlerts(ctx context.Context, alertIDs []uint) ([]*model.Alert, error)
+
+	// ListNotifyRecords 获取告警的通知记录，按时间正序
+	ListNotifyRecords(ctx context.Context, alertIDs []uint) ([]*model.NotifyRecord, error)
+
+	// SaveKnowledge 保存知识库条目，相同来源的条目被覆盖
+	SaveKnowledge(ctx context.Context, knowledge *model.Knowledge) error
+}
+
+// AnalysisReader 读取 Dify 分析服务已保存的分析结果
+type AnalysisReader interface {
+	// GetAnalysisResultsByAlertID 获取告警的分析结果
+	GetAnalysisResultsByAlertID(ctx context.Context, alertID uint) ([]*analysis.DifyAnalysisResult, error)
+}
@@ -0,0 +1,41 @@
+package incident
+
+import (
+	"context"
+)
+
+// Repository 事件仓储接口
+type Repository interface {
+	// Create 创建事件
+	Create(ctx context.Context, incident *Incident) error
+
+	// GetByID 根据ID获取事件，不存在时返回 nil
+	GetByID(ctx context.Context, id string) (*Incident, error)
+
+	// GetOpenByGroupID 获取收敛分组对应的未解决事件，不存在时返回 nil
+	GetOpenByGroupID(ctx context.Context, groupID string) (*Incident, error)
+
+	// GetByAlertID 获取告警所属的事件，不存在时返回 nil
+	GetByAlertID(ctx context.Context, alertID uint) (*Incident, error)
+
+	// List 获取事件列表，按创建时间倒序
+	List(ctx context.Context, filter *Filter) ([]*Incident, int64, error)
+
+	// Update 更新事件
+	Update(ctx context.Context, incident *Incident) error
+
+	// AttachAlerts 关联告警，已属于其他事件的告警被跳过，返回实际关联的告警
+	AttachAlerts(ctx context.Context, incidentID string, alertIDs []uint, actor string) ([]uint, error)
+
+	// DetachAlerts 解除关联告警，返回实际解除的告警
+	DetachAlerts(ctx context.Context, incidentID string, alertIDs []uint) ([]uint, error)
+
+	// ListAlertIDs 获取事件关联的告警
+	ListAlertIDs(ctx context.Context, incidentID string) ([]uint, error)
+
+	// AddTimelineEntry 添加时间线条目
+	AddTimelineEntry(ctx context.Context, entry *TimelineEntry) error
+
+	// ListTimeline 获取事件时间线，按时间正序
+	ListTimeline(ctx context.Context, incidentID string) ([]*TimelineEntry, error)
+}
@@ -0,0 +1,41 @@
+package incident
+
+import (
+	"context"
+)
+
+// Service 事件服务接口
+type Service interface {
+	// CreateIncident 手工创建事件
+	CreateIncident(ctx context.Context, req *CreateIncidentRequest) (*Incident, error)
+
+	// GetIncident 获取事件及关联告警
+	GetIncident(ctx context.Context, id string) (*Incident, error)
+
+	// ListIncidents 获取事件列表
+	ListIncidents(ctx context.Context, filter *Filter) ([]*Incident, int64, error)
+
+	// UpdateIncident 更新标题、描述、严重程度和处理人
+	UpdateIncident(ctx context.Context, id string, req *UpdateIncidentRequest) (*Incident, error)
+
+	// UpdateStatus 变更事件状态，解决时可同时恢复关联告警
+	UpdateStatus(ctx context.Context, id string, req *UpdateStatusRequest) (*Incident, error)
+
+	// AttachAlerts 关联告警
+	AttachAlerts(ctx context.Context, id string, req *AttachAlertsRequest) (*Incident, error)
+
+	// DetachAlerts 解除关联告警
+	DetachAlerts(ctx context.Context, id string, req *AttachAlertsRequest) (*Incident, error)
+
+	// AddTimelineEntry 添加时间线条目，如评论、通知、分析结果
+	AddTimelineEntry(ctx context.Context, id string, req *AddTimelineEntryRequest) (*TimelineEntry, error)
+
+	// GetTimeline 获取事件时间线
+	GetTimeline(ctx context.Context, id string) ([]*TimelineEntry, error)
+}
+
+// AlertResolver 恢复事件关联的告警
+type AlertResolver interface {
+	// ResolveAlert 恢复告警
+	ResolveAlert(ctx context.Context, alertID uint, note string) error
+}
@@ -0,0 +1,32 @@
+package retention
+
+import (
+	"time"
+)
+
+// Config 数据保留配置
+type Config struct {
+	Owner                string        `json:"owner"`                  // 归档者标识，默认为主机名与进程号
+	Interval             time.Duration `json:"interval"`               // 归档间隔
+	BatchSize            int           `json:"batch_size"`             // 每批扫描的记录数，每批写一个归档文件
+	MaxBatchesPerRun     int           `json:"max_batches_per_run"`    // 每张表单次最多处理的批次数
+	LeaseTTL             time.Duration `json:"lease_ttl"`              // 归档租约时长
+	Policies             []Policy      `json:"policies"`               // 保留策略，没有适用策略的数据永久保留
+	PartitionMonthsAhead int           `json:"partition_months_ahead"` // 告警表提前创建的月分区数
+}
+
+// DefaultConfig 默认数据保留配置
+func DefaultConfig() Config {
+	return Config{
+		Interval:         time.Hour,
+		BatchSize:        1000,
+		MaxBatchesPerRun: 20,
+		LeaseTTL:         30 * time.Minute,
+		Policies: []Policy{
+			{Table: TableAlerts, Status: "resolved", Severity: Any, MaxAge: 90 * 24 * time.Hour},
+			{Table: TableAlerts, Status: Any, Severity: Any, MaxAge: 180 * 24 * time.Hour},
+			{Table: TableProcessingRecords, Status: Any, Severity: Any, MaxAge: 30 * 24 * time.Hour},
+		},
+		PartitionMonthsAhead: 3,
+	}
+}
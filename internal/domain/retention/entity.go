@@ -0,0 +1,186 @@
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table 可归档的数据表
+type Table string
+
+const (
+	TableAlerts            Table = "alerts"                   // 告警
+	TableProcessingRecords Table = "alert_processing_records" // 网关告警处理记录
+)
+
+// Tables 按归档顺序列出的数据表
+var Tables = []Table{TableAlerts, TableProcessingRecords}
+
+// Valid 检查数据表是否支持归档
+func (t Table) Valid() bool {
+	for _, table := range Tables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// Any 策略中匹配任意状态或级别的通配符
+const Any = "*"
+
+// Policy 保留策略，同一条数据匹配多条策略时以最具体的为准
+type Policy struct {
+	Table    Table         `json:"table"`
+	Status   string        `json:"status"`   // 状态，* 匹配任意状态
+	Severity string        `json:"severity"` // 级别，* 匹配任意级别；处理记录没有级别
+	MaxAge   time.Duration `json:"max_age"`  // 超过该时长的数据归档后删除
+}
+
+// Matches 检查策略是否适用于指定状态与级别
+func (p Policy) Matches(status, severity string) bool {
+	return (p.Status == Any || strings.EqualFold(p.Status, status)) &&
+		(p.Severity == Any || strings.EqualFold(p.Severity, severity))
+}
+
+// specificity 策略的具体程度，同时限定状态与级别的最具体
+func (p Policy) specificity() int {
+	score := 0
+	if p.Status != Any {
+		score += 2
+	}
+	if p.Severity != Any {
+		score++
+	}
+	return score
+}
+
+// ParsePolicies 解析逗号分隔的保留策略，格式为 table:status:severity:days
+func ParsePolicies(spec string) ([]Policy, error) {
+	var policies []Policy
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("invalid retention policy %q, expected table:status:severity:days", item)
+		}
+		table := Table(strings.TrimSpace(parts[0]))
+		if !table.Valid() {
+			return nil, fmt.Errorf("invalid retention policy %q: unsupported table %s", item, table)
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(parts[3]))
+		if err != nil || days <= 0 {
+			return nil, fmt.Errorf("invalid retention policy %q: days must be a positive integer", item)
+		}
+		policy := Policy{
+			Table:    table,
+			Status:   strings.TrimSpace(parts[1]),
+			Severity: strings.TrimSpace(parts[2]),
+			MaxAge:   time.Duration(days) * 24 * time.Hour,
+		}
+		if policy.Status == "" {
+			policy.Status = Any
+		}
+		if policy.Severity == "" {
+			policy.Severity = Any
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// Resolve 返回适用于指定状态与级别的最具体策略，没有适用策略时数据永久保留
+func Resolve(policies []Policy, table Table, status, severity string) (Policy, bool) {
+	var matched Policy
+	found := false
+	for _, policy := range policies {
+		if policy.Table != table || !policy.Matches(status, severity) {
+			continue
+		}
+		if !found || policy.specificity() > matched.specificity() {
+			matched = policy
+			found = true
+		}
+	}
+	return matched, found
+}
+
+// Row 待归档的一条数据
+type Row struct {
+	ID        string          `json:"id"`
+	Status    string          `json:"status"`
+	Severity  string          `json:"severity"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"` // 完整记录，原样写入归档文件的一行
+}
+
+// Cursor 扫描位置，按创建时间与主键排序
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Archive 归档文件记录，文件为 gzip 压缩的 JSONL，每行一条记录
+type Archive struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Table      Table      `json:"table" gorm:"column:source_table;type:varchar(64);not null;index:idx_retention_archives_range"`
+	Backend    string     `json:"backend" gorm:"type:varchar(20);not null"`
+	Key        string     `json:"key" gorm:"column:object_key;type:varchar(512);not null;uniqueIndex"`
+	From       time.Time  `json:"from" gorm:"column:range_from;not null;index:idx_retention_archives_range"` // 文件内最早的创建时间
+	To         time.Time  `json:"to" gorm:"column:range_to;not null"`                                        // 文件内最晚的创建时间
+	Rows       int        `json:"rows" gorm:"column:row_count"`
+	Bytes      int64      `json:"bytes" gorm:"column:byte_size"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RestoredAt *time.Time `json:"restored_at,omitempty"` // 最近一次恢复时间
+}
+
+// TableName 指定表名
+func (Archive) TableName() string {
+	return "retention_archives"
+}
+
+// ArchiveFilter 归档文件查询条件，From/To 为零值时不限制
+type ArchiveFilter struct {
+	Table Table     `json:"table"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Limit int       `json:"limit"`
+}
+
+// RestoreRequest 恢复归档请求，恢复创建时间在 [from, to) 内的记录
+type RestoreRequest struct {
+	Table Table     `json:"table" binding:"required"`
+	From  time.Time `json:"from" binding:"required"`
+	To    time.Time `json:"to" binding:"required"`
+}
+
+// RestoreResult 恢复结果
+type RestoreResult struct {
+	Table    Table `json:"table"`
+	Archives int   `json:"archives"` // 读取的归档文件数
+	Matched  int   `json:"matched"`  // 时间范围内的记录数
+	Restored int64 `json:"restored"` // 写回数据库的记录数
+	Skipped  int64 `json:"skipped"`  // 数据库中已存在而跳过的记录数
+}
+
+// TableResult 单张表的一次归档结果
+type TableResult struct {
+	Table    Table    `json:"table"`
+	Scanned  int      `json:"scanned"`  // 扫描的候选记录数
+	Archived int      `json:"archived"` // 归档的记录数
+	Deleted  int64    `json:"deleted"`  // 归档后删除的记录数
+	Archives []string `json:"archives"` // 新写入的归档文件
+}
+
+// RunResult 一次归档的结果
+type RunResult struct {
+	StartedAt  time.Time      `json:"started_at"`
+	Tables     []*TableResult `json:"tables"`
+	Partitions int            `json:"partitions"` // 新增的月分区数
+}
@@ -0,0 +1,67 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Repository 数据保留仓储接口
+type Repository interface {
+	// ScanCandidates 按创建时间与主键顺序获取 after 之后、创建时间早于 before 的记录
+	ScanCandidates(ctx context.Context, table Table, before time.Time, after *Cursor, limit int) ([]*Row, error)
+
+	// Delete 物理删除记录，返回删除数量
+	Delete(ctx context.Context, table Table, ids []string) (int64, error)
+
+	// Restore 写回归档记录，主键已存在的跳过，返回写入数量
+	Restore(ctx context.Context, table Table, rows []json.RawMessage) (int64, error)
+
+	// SaveArchive 保存归档文件记录
+	SaveArchive(ctx context.Context, archive *Archive) error
+
+	// ListArchives 获取时间范围与 [from, to) 重叠的归档文件，按时间正序
+	ListArchives(ctx context.Context, filter *ArchiveFilter) ([]*Archive, error)
+
+	// MarkRestored 记录归档文件的恢复时间
+	MarkRestored(ctx context.Context, id uint, at time.Time) error
+
+	// EnsurePartitions 为告警表补齐截至 through 所在月份的月分区，返回新增分区数；未分区的表不处理
+	EnsurePartitions(ctx context.Context, through time.Time) (int, error)
+}
+
+// Storage 归档文件存储
+type Storage interface {
+	// Name 存储类型，记录在归档文件记录中
+	Name() string
+
+	// Put 写入归档文件
+	Put(ctx context.Context, key string, data []byte) error
+
+	// Get 读取归档文件
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// LeaseManager 基于租约的选主，保证同一时间只有一个 worker 归档
+type LeaseManager interface {
+	// Acquire 获取或续约租约，返回是否持有
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release 释放自己持有的租约
+	Release(ctx context.Context, key, owner string) error
+}
+
+// Service 数据保留服务接口
+type Service interface {
+	// Policies 获取生效的保留策略
+	Policies() []Policy
+
+	// Archive 立即执行一次归档，其他 worker 正在归档时返回冲突
+	Archive(ctx context.Context) (*RunResult, error)
+
+	// ListArchives 查询归档文件
+	ListArchives(ctx context.Context, filter *ArchiveFilter) ([]*Archive, error)
+
+	// Restore 将归档中指定时间范围的记录写回数据库，用于排查问题
+	Restore(ctx context.Context, req *RestoreRequest) (*RestoreResult, error)
+}
@@ -0,0 +1,94 @@
+package rule
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/provider"
+)
+
+// AlertState 规则评估中单条时间序列的告警状态
+type AlertState string
+
+const (
+	AlertStatePending AlertState = "pending" // 满足条件但未达到持续时长
+	AlertStateFiring  AlertState = "firing"  // 已触发并创建告警
+)
+
+// SeriesState 时间序列的评估状态，恢复后删除
+type SeriesState struct {
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+	State       AlertState        `json:"state"`
+	Value       float64           `json:"value"`
+	ActiveAt    time.Time         `json:"active_at"`
+	FiredAt     *time.Time        `json:"fired_at,omitempty"`
+	AlertID     uint              `json:"alert_id,omitempty"`
+	LastEvalAt  time.Time         `json:"last_eval_at"`
+}
+
+// EvaluationConfig 规则评估配置
+type EvaluationConfig struct {
+	Owner    string        `json:"owner"`     // 评估者标识，默认为主机名与进程号
+	Interval time.Duration `json:"interval"`  // 评估间隔
+	LeaseTTL time.Duration `json:"lease_ttl"` // 规则评估租约时长，超过后其他 worker 可接管
+	Timeout  time.Duration `json:"timeout"`   // 单条规则评估超时
+}
+
+// DefaultEvaluationConfig 默认规则评估配置
+func DefaultEvaluationConfig() EvaluationConfig {
+	return EvaluationConfig{
+		Interval: 30 * time.Second,
+		LeaseTTL: 90 * time.Second,
+		Timeout:  20 * time.Second,
+	}
+}
+
+// StateStore 规则评估状态存储，worker 切换后可继续 pending/firing 状态
+type StateStore interface {
+	// List 获取规则下所有时间序列状态
+	List(ctx context.Context, ruleID uint) (map[string]*SeriesState, error)
+
+	// Save 保存时间序列状态
+	Save(ctx context.Context, ruleID uint, state *SeriesState) error
+
+	// Delete 删除时间序列状态
+	Delete(ctx context.Context, ruleID uint, fingerprint string) error
+}
+
+// LeaseManager 基于租约的选主，同一规则同一时间只由一个 worker 评估
+type LeaseManager interface {
+	// Acquire 获取或续约租约，返回是否持有
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release 释放自己持有的租约
+	Release(ctx context.Context, key, owner string) error
+}
+
+// RuleSource 待评估规则来源
+type RuleSource interface {
+	// ListEnabledRules 获取启用的规则
+	ListEnabledRules(ctx context.Context) ([]*model.Rule, error)
+}
+
+// QueryClientFactory 根据数据源创建查询客户端
+type QueryClientFactory interface {
+	// QueryClient 获取数据源查询客户端及数据源信息
+	QueryClient(ctx context.Context, providerID uint) (QueryClient, *model.Provider, error)
+}
+
+// QueryClient 即时查询客户端
+type QueryClient interface {
+	Query(ctx context.Context, params url.Values) (*provider.APIResponse, error)
+}
+
+// AlertSink 告警写入，创建和恢复规则产生的告警
+type AlertSink interface {
+	// CreateAlert 创建告警
+	CreateAlert(ctx context.Context, alert *model.Alert) error
+
+	// ResolveAlert 恢复告警
+	ResolveAlert(ctx context.Context, alertID uint, note string) error
+}
@@ -0,0 +1,131 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+	securityDomain "alert_agent/internal/security/domain"
+
+	"gorm.io/gorm"
+)
+
+// GORMBulkRepository GORM 实现的告警批量操作仓储
+type GORMBulkRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMBulkRepository 创建告警批量操作仓储
+func NewGORMBulkRepository(db *gorm.DB) alert.BulkRepository {
+	return &GORMBulkRepository{db: db}
+}
+
+// Count 统计匹配的告警数量
+func (r *GORMBulkRepository) Count(ctx context.Context, selection *alert.BulkSelection) (int64, error) {
+	db, err := buildSelection(r.db.WithContext(ctx).Model(&model.Alert{}), selection)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	if err := db.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count alerts: %w", err)
+	}
+	return count, nil
+}
+
+// List 获取匹配的告警，按ID正序
+func (r *GORMBulkRepository) List(ctx context.Context, selection *alert.BulkSelection, limit int) ([]*model.Alert, error) {
+	db, err := buildSelection(r.db.WithContext(ctx).Model(&model.Alert{}), selection)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	var alerts []*model.Alert
+	if err := db.Order("id ASC").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// AppendNote 向告警的处理备注追加一行，原备注为空时直接写入
+func (r *GORMBulkRepository) AppendNote(ctx context.Context, ids []uint, line string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	note := gorm.Expr("CONCAT(COALESCE(handle_note, ''), CASE WHEN COALESCE(handle_note, '') = '' THEN '' ELSE ? END, ?)", "\n", line)
+	if err := r.db.WithContext(ctx).Model(&model.Alert{}).Where("id IN ?", ids).
+		Update("handle_note", note).Error; err != nil {
+		return fmt.Errorf("failed to append alert note: %w", err)
+	}
+	return nil
+}
+
+// RecordAudit 将批量操作写入审计日志表
+func (r *GORMBulkRepository) RecordAudit(ctx context.Context, record *alert.BulkAuditRecord) error {
+	details, err := json.Marshal(map[string]interface{}{
+		"username":  record.Actor.Username,
+		"selector":  record.Selector,
+		"matched":   record.Matched,
+		"succeeded": record.Succeeded,
+		"skipped":   record.Skipped,
+		"failed":    record.Failed,
+		"alert_ids": record.AlertIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit details: %w", err)
+	}
+
+	status := "success"
+	if record.Failed > 0 {
+		status = "failed"
+		if record.Succeeded > 0 {
+			status = "partial"
+		}
+	}
+	log := &securityDomain.AuditLog{
+		Action:     "ALERT_BULK_" + strings.ToUpper(string(record.Action)),
+		Resource:   "alert",
+		ResourceID: "bulk",
+		IPAddress:  record.Actor.IPAddress,
+		UserAgent:  record.Actor.UserAgent,
+		Details:    string(details),
+		Status:     status,
+	}
+
+	// 未登录或用户ID不是数字时不关联用户
+	db := r.db.WithContext(ctx).Omit("User")
+	if userID, err := strconv.ParseUint(record.Actor.UserID, 10, 64); err == nil && userID > 0 {
+		log.UserID = uint(userID)
+	} else {
+		db = db.Omit("User", "UserID")
+	}
+	if err := db.Create(log).Error; err != nil {
+		return fmt.Errorf("failed to record audit log: %w", err)
+	}
+	return nil
+}
+
+// buildSelection 应用ID列表与查询表达式，两者都为空时拒绝以免选中整表
+func buildSelection(db *gorm.DB, selection *alert.BulkSelection) (*gorm.DB, error) {
+	if len(selection.IDs) == 0 && selection.Expr == nil {
+		return nil, fmt.Errorf("empty alert selection")
+	}
+	if len(selection.IDs) > 0 {
+		db = db.Where("id IN ?", selection.IDs)
+	}
+	if selection.Expr != nil {
+		compiler := &queryCompiler{dialect: db.Dialector.Name()}
+		sql, vars, err := compiler.compile(selection.Expr)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(sql, vars...)
+	}
+	return db, nil
+}
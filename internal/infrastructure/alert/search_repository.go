@@ -0,0 +1,201 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMSearchRepository GORM 实现的告警搜索仓储
+type GORMSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewGORMSearchRepository 创建告警搜索仓储
+func NewGORMSearchRepository(db *gorm.DB) alert.SearchRepository {
+	return &GORMSearchRepository{db: db}
+}
+
+// Search 按查询表达式搜索告警，使用键集分页
+func (r *GORMSearchRepository) Search(ctx context.Context, query *alert.SearchQuery) ([]*model.Alert, error) {
+	db, err := buildSearch(r.db.WithContext(ctx).Model(&model.Alert{}), query)
+	if err != nil {
+		return nil, err
+	}
+	var alerts []*model.Alert
+	if err := db.Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to search alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// buildSearch 将查询表达式、游标与排序应用到查询
+func buildSearch(db *gorm.DB, query *alert.SearchQuery) (*gorm.DB, error) {
+	compiler := &queryCompiler{dialect: db.Dialector.Name()}
+	if query.Expr != nil {
+		sql, vars, err := compiler.compile(query.Expr)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(sql, vars...)
+	}
+
+	column, columnVars := compiler.column(query.Sort.Field)
+	if query.After != nil {
+		cmp := ">"
+		if query.Sort.Desc {
+			cmp = "<"
+		}
+		if query.Sort.Field.Name == "id" {
+			db = db.Where("id "+cmp+" ?", query.After.ID)
+		} else {
+			var vars []interface{}
+			vars = append(vars, columnVars...)
+			vars = append(vars, query.After.Value)
+			vars = append(vars, columnVars...)
+			vars = append(vars, query.After.Value, query.After.ID)
+			db = db.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, cmp, column, cmp), vars...)
+		}
+	}
+
+	direction := "ASC"
+	if query.Sort.Desc {
+		direction = "DESC"
+	}
+	order := "id " + direction
+	if query.Sort.Field.Name != "id" {
+		order = column + " " + direction + ", " + order
+	}
+	db = db.Clauses(clause.OrderBy{Expression: clause.Expr{SQL: order, Vars: columnVars, WithoutParentheses: true}})
+
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	return db, nil
+}
+
+// queryCompiler 将查询表达式编译为参数化 SQL，列名只来自字段白名单
+type queryCompiler struct {
+	dialect string
+}
+
+func (c *queryCompiler) compile(expr alert.QueryExpr) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case *alert.AndExpr:
+		return c.binary("AND", e.Left, e.Right)
+	case *alert.OrExpr:
+		return c.binary("OR", e.Left, e.Right)
+	case *alert.NotExpr:
+		sql, vars, err := c.compile(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", vars, nil
+	case *alert.TextExpr:
+		pattern := likePattern(e.Text)
+		like := c.like()
+		return fmt.Sprintf("(name %s OR title %s OR content %s)", like, like, like),
+			[]interface{}{pattern, pattern, pattern}, nil
+	case *alert.Condition:
+		return c.condition(e)
+	default:
+		return "", nil, fmt.Errorf("unsupported query expression %T", expr)
+	}
+}
+
+func (c *queryCompiler) binary(op string, left, right alert.QueryExpr) (string, []interface{}, error) {
+	leftSQL, leftVars, err := c.compile(left)
+	if err != nil {
+		return "", nil, err
+	}
+	rightSQL, rightVars, err := c.compile(right)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("(%s %s %s)", leftSQL, op, rightSQL), append(leftVars, rightVars...), nil
+}
+
+func (c *queryCompiler) condition(cond *alert.Condition) (string, []interface{}, error) {
+	column, vars := c.column(cond.Field)
+	switch cond.Op {
+	case alert.OpEq, alert.OpGt, alert.OpGte, alert.OpLt, alert.OpLte:
+		return fmt.Sprintf("%s %s ?", column, cond.Op), append(vars, cond.Value), nil
+	case alert.OpNe:
+		return fmt.Sprintf("%s <> ?", column), append(vars, cond.Value), nil
+	case alert.OpContains:
+		return fmt.Sprintf("%s %s", column, c.like()), append(vars, likePattern(cond.Value.(string))), nil
+	case alert.OpRegex, alert.OpNotRegex:
+		regexOp, err := c.regexOp(cond.Op == alert.OpNotRegex)
+		if err != nil {
+			return "", nil, err
+		}
+		// 与 Prometheus 一致，正则匹配整个值
+		return fmt.Sprintf("%s %s ?", column, regexOp), append(vars, "^("+cond.Value.(string)+")$"), nil
+	default:
+		return "", nil, fmt.Errorf("unsupported operator %s", cond.Op)
+	}
+}
+
+// column 返回字段的 SQL 表达式，标签字段按数据库方言提取 JSON 值，缺失时为空字符串
+func (c *queryCompiler) column(field alert.SearchField) (string, []interface{}) {
+	if !field.IsLabel() {
+		return field.Column, nil
+	}
+	switch c.dialect {
+	case "mysql":
+		return "COALESCE(CASE WHEN JSON_VALID(labels) THEN JSON_UNQUOTE(JSON_EXTRACT(labels, ?)) END, '')",
+			[]interface{}{jsonPath(field.Label)}
+	case "postgres":
+		return "COALESCE(CASE WHEN labels IS NULL OR labels = '' THEN NULL ELSE labels::jsonb ->> ? END, '')",
+			[]interface{}{field.Label}
+	default:
+		return "COALESCE(json_extract(labels, ?), '')", []interface{}{jsonPath(field.Label)}
+	}
+}
+
+// like 返回包含匹配条件，显式指定 likePattern 使用的转义字符，SQLite 默认没有转义字符
+func (c *queryCompiler) like() string {
+	switch c.dialect {
+	case "postgres":
+		return `ILIKE ? ESCAPE '\'`
+	case "mysql":
+		// MySQL 字符串字面量中反斜杠本身需要转义
+		return `LIKE ? ESCAPE '\\'`
+	default:
+		return `LIKE ? ESCAPE '\'`
+	}
+}
+
+func (c *queryCompiler) regexOp(negate bool) (string, error) {
+	switch c.dialect {
+	case "mysql":
+		if negate {
+			return "NOT REGEXP", nil
+		}
+		return "REGEXP", nil
+	case "postgres":
+		if negate {
+			return "!~", nil
+		}
+		return "~", nil
+	default:
+		return "", fmt.Errorf("regular expression match is not supported on %s", c.dialect)
+	}
+}
+
+// jsonPath 生成标签的 JSON 路径，标签名已通过白名单校验
+func jsonPath(label string) string {
+	return `$."` + label + `"`
+}
+
+// likePattern 转义通配符后生成包含匹配模式
+func likePattern(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(text) + "%"
+}
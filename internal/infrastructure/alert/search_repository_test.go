@@ -0,0 +1,66 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunSQL 生成查询语句而不访问数据库
+func dryRunSQL(t *testing.T, dialector gorm.Dialector, query *alert.SearchQuery) (string, []interface{}) {
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	tx, err := buildSearch(db.Model(&model.Alert{}), query)
+	require.NoError(t, err)
+	stmt := tx.Find(&[]*model.Alert{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestBuildSearch_MySQL(t *testing.T) {
+	expr, err := alert.ParseQuery(`severity=critical AND labels.namespace=~"prod-.*" AND NOT title:"50%_disk"`)
+	require.NoError(t, err)
+	sort, err := alert.ParseSort("labels.team")
+	require.NoError(t, err)
+
+	dialector := mysql.New(mysql.Config{DSN: "user:pass@tcp(127.0.0.1:3306)/alert_agent", SkipInitializeWithVersion: true})
+	sql, vars := dryRunSQL(t, dialector, &alert.SearchQuery{
+		Expr:  expr,
+		Sort:  sort,
+		After: &alert.Cursor{Value: "sre", ID: 10},
+		Limit: 51,
+	})
+
+	label := "COALESCE(CASE WHEN JSON_VALID(labels) THEN JSON_UNQUOTE(JSON_EXTRACT(labels, ?)) END, '')"
+	assert.Contains(t, sql, "((severity = ? AND "+label+" REGEXP ?) AND NOT (title LIKE ? ESCAPE '\\\\'))")
+	assert.Contains(t, sql, "("+label+" > ? OR ("+label+" = ? AND id > ?))")
+	assert.Contains(t, sql, "ORDER BY "+label+" ASC, id ASC LIMIT 51")
+	assert.Contains(t, sql, "`alerts`.`deleted_at` IS NULL")
+	assert.Equal(t, []interface{}{
+		"critical", `$."namespace"`, "^(prod-.*)$", `%50\%\_disk%`,
+		`$."team"`, "sre", `$."team"`, "sre", uint(10),
+		`$."team"`,
+	}, vars)
+}
+
+func TestBuildSearch_Postgres(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expr, err := alert.ParseQueryAt(`labels.env!~"dev|test" OR (created_at>=-2h "timeout")`, now)
+	require.NoError(t, err)
+
+	dialector := postgres.New(postgres.Config{DSN: "host=127.0.0.1 user=u password=p dbname=alert_agent"})
+	sql, vars := dryRunSQL(t, dialector, &alert.SearchQuery{Expr: expr, Sort: alert.DefaultSort(), Limit: 10})
+
+	assert.Contains(t, sql, "labels::jsonb ->> $1 END, '') !~ $2")
+	assert.Contains(t, sql, `(created_at >= $3 AND (name ILIKE $4 ESCAPE '\' OR title ILIKE $5 ESCAPE '\' OR content ILIKE $6 ESCAPE '\'))`)
+	assert.Contains(t, sql, "ORDER BY created_at DESC, id DESC")
+	assert.Equal(t, []interface{}{"env", "^(dev|test)$", now.Add(-2 * time.Hour), "%timeout%", "%timeout%", "%timeout%"}, vars)
+}
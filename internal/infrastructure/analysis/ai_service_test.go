@@ -0,0 +1,296 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/infrastructure/dify"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const fakeAnswer = `{"summary":"磁盘写满导致服务不可用","severity":"critical","confidence":0.9,"recommendations":["清理日志","扩容磁盘"]}`
+
+const rootCauseAnswer = `{"summary":"磁盘写满导致服务不可用","severity":"critical","confidence":0.9,` +
+	`"root_causes":[{"description":"日志未轮转","likelihood":0.8}],"recommendations":["清理日志","扩容磁盘"]}`
+
+const classificationAnswer = `{"summary":"磁盘容量告警","confidence":0.95,"category":"storage","reason":"告警名称包含 Disk"}`
+
+// fakeModelServer 模拟模型服务，记录收到的请求体
+func fakeModelServer(t *testing.T, path string, respond func(w http.ResponseWriter, body map[string]interface{})) (*httptest.Server, *map[string]interface{}) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		require.Equal(t, path, r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		received["authorization"] = r.Header.Get("Authorization")
+		respond(w, received)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func TestOllamaAIService(t *testing.T) {
+	server, received := fakeModelServer(t, "/api/chat", func(w http.ResponseWriter, body map[string]interface{}) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":             body["model"],
+			"message":           map[string]string{"role": "assistant", "content": fakeAnswer},
+			"done":              true,
+			"prompt_eval_count": 120,
+			"eval_count":        30,
+		})
+	})
+
+	service := NewOllamaAIService(ChatConfig{BaseURL: server.URL + "/", Model: "qwen2.5:7b", Timeout: time.Second})
+	response, err := service.Analyze(context.Background(), "分析告警", nil)
+	require.NoError(t, err)
+	assert.Equal(t, fakeAnswer, response.Content)
+	assert.Equal(t, "qwen2.5:7b", response.ModelUsed)
+	assert.Equal(t, 150, response.Tokens.TotalTokens)
+	assert.Equal(t, false, (*received)["stream"])
+	assert.Equal(t, "json", (*received)["format"])
+	assert.True(t, service.IsHealthy())
+}
+
+func TestOpenAIAIService(t *testing.T) {
+	server, received := fakeModelServer(t, "/v1/chat/completions", func(w http.ResponseWriter, body map[string]interface{}) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":    "chatcmpl-1",
+			"model": "deepseek-chat",
+			"choices": []map[string]interface{}{{
+				"message":       map[string]string{"role": "assistant", "content": "```json\n" + fakeAnswer + "\n```"},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]int{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120},
+		})
+	})
+
+	service := NewOpenAIAIService(ChatConfig{BaseURL: server.URL + "/v1", APIKey: "sk-test", Model: "deepseek-chat", MaxTokens: 512})
+	response, err := service.Analyze(context.Background(), "分析告警", nil)
+	require.NoError(t, err)
+	assert.Equal(t, fakeAnswer, response.Content, "code fence is stripped")
+	assert.Equal(t, 120, response.Tokens.TotalTokens)
+	assert.Equal(t, "Bearer sk-test", (*received)["authorization"])
+	assert.Equal(t, float64(512), (*received)["max_tokens"])
+	messages := (*received)["messages"].([]interface{})
+	require.Len(t, messages, 2)
+	assert.Equal(t, "分析告警", messages[1].(map[string]interface{})["content"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"rate limited"}`, http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+	_, err = NewOpenAIAIService(ChatConfig{BaseURL: failing.URL}).Analyze(context.Background(), "分析告警", nil)
+	assert.ErrorContains(t, err, "status 429")
+	assert.False(t, NewOpenAIAIService(ChatConfig{BaseURL: failing.URL}).IsHealthy())
+}
+
+func TestDifyAIService(t *testing.T) {
+	server, received := fakeModelServer(t, "/v1/chat-messages", func(w http.ResponseWriter, body map[string]interface{}) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message_id":      "msg-1",
+			"conversation_id": "conv-1",
+			"answer":          fakeAnswer,
+		})
+	})
+
+	service := NewDifyAIService(dify.NewDifyClient(server.URL, "app-key", zap.NewNop()), "")
+	response, err := service.Analyze(context.Background(), "分析告警", &model.Alert{Name: "DiskFull"})
+	require.NoError(t, err)
+	assert.Equal(t, fakeAnswer, response.Content)
+	assert.Equal(t, "msg-1", response.Metadata["message_id"])
+	assert.Equal(t, "blocking", (*received)["response_mode"])
+	assert.Equal(t, "Bearer app-key", (*received)["authorization"])
+	assert.Contains(t, (*received)["inputs"].(map[string]interface{})["alert"], "DiskFull")
+}
+
+// staticAIService 返回固定内容并记录调用次数
+type staticAIService struct {
+	name    string
+	content string
+	calls   int
+	fails   int
+}
+
+func (s *staticAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	s.calls++
+	if s.calls <= s.fails {
+		return nil, assert.AnError
+	}
+	return &AIResponse{Content: s.content, ModelUsed: s.name}, nil
+}
+
+func (s *staticAIService) IsHealthy() bool { return true }
+
+func (s *staticAIService) GetModelInfo() *ModelInfo { return &ModelInfo{Name: s.name} }
+
+type memoryTemplateRepository map[analysis.AnalysisType]*AnalysisTemplate
+
+func (r memoryTemplateRepository) GetTemplate(analysisType analysis.AnalysisType) (*AnalysisTemplate, error) {
+	if template, ok := r[analysisType]; ok {
+		return template, nil
+	}
+	return nil, assert.AnError
+}
+
+func (r memoryTemplateRepository) GetDefaultTemplate() (*AnalysisTemplate, error) {
+	return DefaultTemplates[analysis.AnalysisTypeRootCause], nil
+}
+
+func TestParseTypeBackends(t *testing.T) {
+	backends, err := ParseTypeBackends("root_cause_analysis:openai, classification:ollama,")
+	require.NoError(t, err)
+	assert.Equal(t, map[analysis.AnalysisType]string{
+		analysis.AnalysisTypeRootCause:      BackendOpenAI,
+		analysis.AnalysisTypeClassification: BackendOllama,
+	}, backends)
+
+	_, err = ParseTypeBackends("root_cause_analysis")
+	assert.Error(t, err)
+	_, err = ParseTypeBackends("root_cause_analysis:gemini")
+	assert.Error(t, err)
+}
+
+func TestAnalysisEngine_RoutesByType(t *testing.T) {
+	logger.L = zap.NewNop()
+	defaultService := &staticAIService{name: "ollama", content: classificationAnswer}
+	rootCause := &staticAIService{name: "deepseek", content: rootCauseAnswer, fails: 1}
+	router := NewAIServiceRouter(defaultService, map[analysis.AnalysisType]AIService{
+		analysis.AnalysisTypeRootCause: rootCause,
+	}, nil)
+	engine := NewAnalysisEngine(router, memoryTemplateRepository{
+		analysis.AnalysisTypeRootCause: {ID: "tpl-1", Version: "3", Prompt: "告警 {{alert_name}} 级别 {{alert_level}}"},
+	}, nil, nil, nil, &EngineConfig{Timeout: time.Second, MaxRetries: 1, MaxPromptLength: 8000})
+
+	alert := &model.Alert{Name: "DiskFull", Level: "critical"}
+	result, err := engine.Analyze(context.Background(), &analysis.AnalysisRequest{Alert: alert, Type: analysis.AnalysisTypeRootCause})
+	require.NoError(t, err)
+	assert.Equal(t, 2, rootCause.calls, "first attempt fails and is retried")
+	assert.Equal(t, 0, defaultService.calls)
+	assert.Equal(t, 0.9, result.ConfidenceScore)
+	assert.Equal(t, []string{"清理日志", "扩容磁盘"}, result.Recommendations)
+	assert.Equal(t, "deepseek", result.Metadata["model_used"])
+	assert.Equal(t, "tpl-1", result.Metadata["template_id"])
+
+	// 未配置的类型走默认后端，缺少模板时使用默认模板
+	result, err = engine.Analyze(context.Background(), &analysis.AnalysisRequest{Alert: alert, Type: analysis.AnalysisTypeClassification})
+	require.NoError(t, err)
+	assert.Equal(t, 1, defaultService.calls)
+	assert.Equal(t, "builtin-root-cause", result.Metadata["template_id"])
+	assert.Equal(t, analysis.CategoryStorage, result.Structured.Category)
+	assert.Equal(t, analysis.CategoryStorage, result.Metadata["category"])
+}
+
+func TestChatServices_StreamTokens(t *testing.T) {
+	ollama, received := fakeModelServer(t, "/api/chat", func(w http.ResponseWriter, body map[string]interface{}) {
+		for _, part := range []string{`{"summary":`, `"磁盘写满"}`} {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"model":   body["model"],
+				"message": map[string]string{"role": "assistant", "content": part},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":             body["model"],
+			"message":           map[string]string{"role": "assistant", "content": ""},
+			"done":              true,
+			"prompt_eval_count": 100,
+			"eval_count":        8,
+		})
+	})
+	var deltas []string
+	ctx := analysis.WithTokenSink(context.Background(), func(delta string) { deltas = append(deltas, delta) })
+
+	service := NewOllamaAIService(ChatConfig{BaseURL: ollama.URL, Model: "qwen2.5:7b", Timeout: time.Second})
+	response, err := service.Analyze(ctx, "分析告警", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, (*received)["stream"])
+	assert.Equal(t, []string{`{"summary":`, `"磁盘写满"}`}, deltas)
+	assert.Equal(t, `{"summary":"磁盘写满"}`, response.Content)
+	assert.Equal(t, 108, response.Tokens.TotalTokens)
+
+	openai, received := fakeModelServer(t, "/v1/chat/completions", func(w http.ResponseWriter, body map[string]interface{}) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"id\":\"c1\",\"model\":\"deepseek-chat\",\"choices\":[{\"delta\":{\"content\":\"```json\\n{\\\"summary\\\":\"}}]}\n\n" +
+			"data: {\"id\":\"c1\",\"choices\":[{\"delta\":{\"content\":\"\\\"磁盘写满\\\"}\\n```\"},\"finish_reason\":\"stop\"}]}\n\n" +
+			"data: {\"id\":\"c1\",\"choices\":[],\"usage\":{\"prompt_tokens\":90,\"completion_tokens\":6,\"total_tokens\":96}}\n\n" +
+			"data: [DONE]\n\n"))
+	})
+	deltas = nil
+	service = NewOpenAIAIService(ChatConfig{BaseURL: openai.URL + "/v1", Model: "deepseek-chat", Timeout: time.Second})
+	response, err = service.Analyze(ctx, "分析告警", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, (*received)["stream"])
+	assert.Equal(t, map[string]interface{}{"include_usage": true}, (*received)["stream_options"])
+	assert.Len(t, deltas, 2)
+	assert.Equal(t, `{"summary":"磁盘写满"}`, response.Content)
+	assert.Equal(t, "deepseek-chat", response.ModelUsed)
+	assert.Equal(t, "stop", response.Metadata["finish_reason"])
+	assert.Equal(t, 96, response.Tokens.TotalTokens)
+}
+
+// blockingAIService 调用阻塞到 release 关闭
+type blockingAIService struct {
+	name    string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return &AIResponse{Content: fakeAnswer, ModelUsed: s.name}, nil
+}
+
+func (s *blockingAIService) IsHealthy() bool { return true }
+
+func (s *blockingAIService) GetModelInfo() *ModelInfo { return &ModelInfo{Name: s.name} }
+
+func TestModelLimiter(t *testing.T) {
+	limits, err := ParseModelConcurrency("qwen2.5:7b:1, deepseek-chat:4")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"qwen2.5:7b": 1, "deepseek-chat": 4}, limits)
+	_, err = ParseModelConcurrency("qwen2.5:7b")
+	assert.Error(t, err)
+	_, err = ParseModelConcurrency("deepseek-chat:0")
+	assert.Error(t, err)
+
+	limiter := NewModelLimiter(limits)
+	backend := &blockingAIService{name: "qwen2.5:7b", started: make(chan struct{}, 2), release: make(chan struct{})}
+	// 同名模型的多个包装共享并发额度
+	first, second := limiter.Wrap(backend), limiter.Wrap(backend)
+	assert.Nil(t, limiter.Wrap(nil))
+	limiter.Wrap(&staticAIService{name: "dify-app"})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := first.Analyze(context.Background(), "prompt", nil)
+		assert.NoError(t, err)
+	}()
+	<-backend.started
+	assert.Equal(t, analysis.ModelSlots{Limit: 1, InFlight: 1}, limiter.Snapshot()["qwen2.5:7b"])
+	assert.Equal(t, analysis.ModelSlots{}, limiter.Snapshot()["dify-app"])
+
+	// 额度用尽时等待，上下文取消后返回
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = second.Analyze(ctx, "prompt", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(backend.release)
+	<-done
+	assert.Equal(t, 0, limiter.Snapshot()["qwen2.5:7b"].InFlight)
+}
@@ -0,0 +1,543 @@
+package analysis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+)
+
+// 模型后端类型
+const (
+	BackendOllama = "ollama" // Ollama /api/chat
+	BackendOpenAI = "openai" // OpenAI 兼容的 chat completions，如 vLLM、DeepSeek、Qwen
+	BackendDify   = "dify"   // Dify 对话应用
+)
+
+// healthCheckTimeout 健康检查超时
+const healthCheckTimeout = 3 * time.Second
+
+// maxStreamLineSize 流式响应单行的最大长度
+const maxStreamLineSize = 1024 * 1024
+
+// systemPrompt 要求模型按用户消息中的 schema 返回 JSON
+const systemPrompt = "你是资深 SRE，负责分析监控告警。请只返回符合用户消息中 JSON Schema 的 JSON 对象，不要输出其他内容。"
+
+// ChatConfig 对话模型配置
+type ChatConfig struct {
+	BaseURL     string        // 服务地址，OpenAI 兼容服务需包含 /v1
+	APIKey      string        // 鉴权密钥，Ollama 可为空
+	Model       string        // 模型名称
+	Temperature float64       // 采样温度
+	MaxTokens   int           // 最大生成长度，0 为服务默认值
+	Timeout     time.Duration // 请求超时
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// doJSON 发送 JSON 请求并解析响应，非 2xx 响应返回包含响应体的错误
+func doJSON(ctx context.Context, client *http.Client, method, url, apiKey string, body, out interface{}) error {
+	resp, err := doRequest(ctx, client, method, url, apiKey, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response failed: %w", err)
+	}
+	return nil
+}
+
+// doStream 发送 JSON 请求并逐行读取流式响应，跳过空行
+func doStream(ctx context.Context, client *http.Client, url, apiKey string, body interface{}, handle func(line []byte) error) error {
+	resp, err := doRequest(ctx, client, http.MethodPost, url, apiKey, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if err := handle(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream failed: %w", err)
+	}
+	return nil
+}
+
+// doRequest 发送 JSON 请求，非 2xx 响应返回包含响应体的错误，调用方负责关闭响应体
+func doRequest(ctx context.Context, client *http.Client, method, url, apiKey string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request failed: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("API error: status %d, body: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return resp, nil
+}
+
+// chatMessage 对话消息
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+func chatMessages(prompt string) []chatMessage {
+	return []chatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: prompt},
+	}
+}
+
+// OllamaAIService 基于 Ollama /api/chat 的 AI 服务
+type OllamaAIService struct {
+	config     ChatConfig
+	httpClient *http.Client
+}
+
+// NewOllamaAIService 创建 Ollama AI 服务
+func NewOllamaAIService(config ChatConfig) AIService {
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &OllamaAIService{config: config, httpClient: newHTTPClient(config.Timeout)}
+}
+
+// ollamaChatResponse Ollama /api/chat 响应，流式输出时为每一行的增量
+type ollamaChatResponse struct {
+	Model   string      `json:"model"`
+	Message chatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	// 提示与生成的 token 数，流式输出时只在最后一行返回
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	TotalDuration   int64  `json:"total_duration"`
+	Error           string `json:"error,omitempty"`
+}
+
+// Analyze 执行分析，要求模型以 JSON 格式输出，上下文带有流式输出接收器时逐段回传
+func (s *OllamaAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	options := map[string]interface{}{"temperature": s.config.Temperature}
+	if s.config.MaxTokens > 0 {
+		options["num_predict"] = s.config.MaxTokens
+	}
+	sink := analysis.TokenSinkFromContext(ctx)
+	request := map[string]interface{}{
+		"model":    s.config.Model,
+		"messages": chatMessages(prompt),
+		"stream":   sink != nil,
+		"format":   "json",
+		"options":  options,
+	}
+
+	var response *ollamaChatResponse
+	var err error
+	if sink != nil {
+		response, err = s.chatStream(ctx, request, sink)
+	} else {
+		response = &ollamaChatResponse{}
+		err = doJSON(ctx, s.httpClient, http.MethodPost, s.config.BaseURL+"/api/chat", "", request, response)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ollama chat failed: %w", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("ollama API error: %s", response.Error)
+	}
+	if strings.TrimSpace(response.Message.Content) == "" {
+		return nil, fmt.Errorf("ollama returned empty content")
+	}
+
+	return &AIResponse{
+		Content:   response.Message.Content,
+		ModelUsed: response.Model,
+		Metadata: map[string]interface{}{
+			"provider":       BackendOllama,
+			"total_duration": time.Duration(response.TotalDuration).String(),
+		},
+		Tokens: &TokenUsage{
+			PromptTokens:     response.PromptEvalCount,
+			CompletionTokens: response.EvalCount,
+			TotalTokens:      response.PromptEvalCount + response.EvalCount,
+		},
+	}, nil
+}
+
+// chatStream 读取 NDJSON 流式响应，合并为完整响应
+func (s *OllamaAIService) chatStream(ctx context.Context, request map[string]interface{}, sink analysis.TokenSink) (*ollamaChatResponse, error) {
+	var content strings.Builder
+	final := &ollamaChatResponse{}
+	err := doStream(ctx, s.httpClient, s.config.BaseURL+"/api/chat", "", request, func(line []byte) error {
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("decode stream chunk failed: %w", err)
+		}
+		if chunk.Error != "" {
+			final.Error = chunk.Error
+			return nil
+		}
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			sink(chunk.Message.Content)
+		}
+		if chunk.Done {
+			*final = chunk
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if final.Model == "" {
+		final.Model = s.config.Model
+	}
+	final.Message.Content = content.String()
+	return final, nil
+}
+
+// IsHealthy 检查 Ollama 是否可用
+func (s *OllamaAIService) IsHealthy() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return doJSON(ctx, s.httpClient, http.MethodGet, s.config.BaseURL+"/api/tags", "", nil, nil) == nil
+}
+
+// GetModelInfo 获取模型信息
+func (s *OllamaAIService) GetModelInfo() *ModelInfo {
+	return &ModelInfo{Name: s.config.Model, Provider: BackendOllama, Capabilities: []string{"chat", "json"}}
+}
+
+// OpenAIAIService 基于 OpenAI 兼容 chat completions 接口的 AI 服务
+type OpenAIAIService struct {
+	config     ChatConfig
+	httpClient *http.Client
+}
+
+// NewOpenAIAIService 创建 OpenAI 兼容 AI 服务
+func NewOpenAIAIService(config ChatConfig) AIService {
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &OpenAIAIService{config: config, httpClient: newHTTPClient(config.Timeout)}
+}
+
+// chatCompletionResponse chat completions 响应，流式输出时每个 data 行的 choices 只包含 delta
+type chatCompletionResponse struct {
+	ID      string       `json:"id"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   *TokenUsage  `json:"usage"`
+}
+
+// chatChoice chat completions 的候选结果
+type chatChoice struct {
+	Message      chatMessage `json:"message"`
+	Delta        chatMessage `json:"delta"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// Analyze 执行分析，上下文带有流式输出接收器时逐段回传
+func (s *OpenAIAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	sink := analysis.TokenSinkFromContext(ctx)
+	request := map[string]interface{}{
+		"model":       s.config.Model,
+		"messages":    chatMessages(prompt),
+		"temperature": s.config.Temperature,
+		"stream":      sink != nil,
+	}
+	if s.config.MaxTokens > 0 {
+		request["max_tokens"] = s.config.MaxTokens
+	}
+
+	var response *chatCompletionResponse
+	var err error
+	if sink != nil {
+		// 要求在最后一个数据块返回用量，不支持的服务会忽略
+		request["stream_options"] = map[string]interface{}{"include_usage": true}
+		response, err = s.completionStream(ctx, request, sink)
+	} else {
+		response = &chatCompletionResponse{}
+		err = doJSON(ctx, s.httpClient, http.MethodPost, s.config.BaseURL+"/chat/completions", s.config.APIKey, request, response)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("chat completion failed: %w", err)
+	}
+	if len(response.Choices) == 0 || strings.TrimSpace(response.Choices[0].Message.Content) == "" {
+		return nil, fmt.Errorf("chat completion returned no content")
+	}
+
+	model := response.Model
+	if model == "" {
+		model = s.config.Model
+	}
+	return &AIResponse{
+		Content:   stripCodeFence(response.Choices[0].Message.Content),
+		ModelUsed: model,
+		Metadata: map[string]interface{}{
+			"provider":      BackendOpenAI,
+			"completion_id": response.ID,
+			"finish_reason": response.Choices[0].FinishReason,
+		},
+		Tokens: response.Usage,
+	}, nil
+}
+
+// completionStream 读取 SSE 流式响应，合并为完整响应
+func (s *OpenAIAIService) completionStream(ctx context.Context, request map[string]interface{}, sink analysis.TokenSink) (*chatCompletionResponse, error) {
+	var content strings.Builder
+	final := &chatCompletionResponse{}
+	finishReason := ""
+	err := doStream(ctx, s.httpClient, s.config.BaseURL+"/chat/completions", s.config.APIKey, request, func(line []byte) error {
+		payload, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			return nil
+		}
+		payload = bytes.TrimSpace(payload)
+		if string(payload) == "[DONE]" {
+			return nil
+		}
+		var chunk chatCompletionResponse
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			return fmt.Errorf("decode stream chunk failed: %w", err)
+		}
+		if chunk.ID != "" {
+			final.ID = chunk.ID
+		}
+		if chunk.Model != "" {
+			final.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			final.Usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				sink(choice.Delta.Content)
+			}
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content.Len() > 0 {
+		final.Choices = []chatChoice{{
+			Message:      chatMessage{Role: "assistant", Content: content.String()},
+			FinishReason: finishReason,
+		}}
+	}
+	return final, nil
+}
+
+// IsHealthy 检查模型列表接口是否可用
+func (s *OpenAIAIService) IsHealthy() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return doJSON(ctx, s.httpClient, http.MethodGet, s.config.BaseURL+"/models", s.config.APIKey, nil, nil) == nil
+}
+
+// GetModelInfo 获取模型信息
+func (s *OpenAIAIService) GetModelInfo() *ModelInfo {
+	return &ModelInfo{Name: s.config.Model, Provider: BackendOpenAI, Capabilities: []string{"chat"}}
+}
+
+// DifyAIService 基于 Dify 对话应用的 AI 服务
+type DifyAIService struct {
+	client analysis.DifyClient
+	user   string
+}
+
+// NewDifyAIService 创建 Dify AI 服务
+func NewDifyAIService(client analysis.DifyClient, user string) AIService {
+	if user == "" {
+		user = "alert-agent"
+	}
+	return &DifyAIService{client: client, user: user}
+}
+
+// Analyze 以阻塞模式发送对话消息，告警数据作为应用输入变量
+func (s *DifyAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	inputs := map[string]interface{}{}
+	if data != nil {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("marshal alert failed: %w", err)
+		}
+		inputs["alert"] = string(encoded)
+	}
+
+	response, err := s.client.ChatMessage(ctx, &analysis.DifyChatRequest{
+		Inputs:       inputs,
+		Query:        prompt,
+		ResponseMode: "blocking",
+		User:         s.user,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dify chat failed: %w", err)
+	}
+	if strings.TrimSpace(response.Answer) == "" {
+		return nil, fmt.Errorf("dify returned empty answer")
+	}
+
+	result := &AIResponse{
+		Content:   stripCodeFence(response.Answer),
+		ModelUsed: BackendDify,
+		Metadata: map[string]interface{}{
+			"provider":        BackendDify,
+			"message_id":      response.MessageID,
+			"conversation_id": response.ConversationID,
+		},
+	}
+	if response.Usage != nil {
+		result.Tokens = &TokenUsage{
+			PromptTokens:     response.Usage.PromptTokens,
+			CompletionTokens: response.Usage.CompletionTokens,
+			TotalTokens:      response.Usage.TotalTokens,
+		}
+	}
+	return result, nil
+}
+
+// IsHealthy 检查 Dify 是否可用
+func (s *DifyAIService) IsHealthy() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	return s.client.HealthCheck(ctx) == nil
+}
+
+// GetModelInfo 获取模型信息
+func (s *DifyAIService) GetModelInfo() *ModelInfo {
+	return &ModelInfo{Name: "dify-app", Provider: BackendDify, Capabilities: []string{"chat", "knowledge"}}
+}
+
+// AIServiceSelector 按分析类型选择 AI 服务，引擎的 AIService 实现该接口时按类型路由
+type AIServiceSelector interface {
+	Select(analysisType analysis.AnalysisType) AIService
+}
+
+// AIServiceDowngrader 提供低成本模型，租户用量接近预算时引擎改用该模型
+type AIServiceDowngrader interface {
+	Downgrade() AIService
+}
+
+// AIServiceRouter 按分析类型路由到不同模型后端，未配置的类型使用默认后端
+type AIServiceRouter struct {
+	defaultService   AIService
+	services         map[analysis.AnalysisType]AIService
+	downgradeService AIService
+}
+
+// NewAIServiceRouter 创建 AI 服务路由，downgradeService 为 nil 时不降级
+func NewAIServiceRouter(defaultService AIService, services map[analysis.AnalysisType]AIService, downgradeService AIService) *AIServiceRouter {
+	if services == nil {
+		services = make(map[analysis.AnalysisType]AIService)
+	}
+	return &AIServiceRouter{defaultService: defaultService, services: services, downgradeService: downgradeService}
+}
+
+// Downgrade 获取低成本模型
+func (r *AIServiceRouter) Downgrade() AIService {
+	return r.downgradeService
+}
+
+// Select 获取分析类型对应的 AI 服务
+func (r *AIServiceRouter) Select(analysisType analysis.AnalysisType) AIService {
+	if service, ok := r.services[analysisType]; ok {
+		return service
+	}
+	return r.defaultService
+}
+
+// Analyze 使用默认后端分析
+func (r *AIServiceRouter) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	return r.defaultService.Analyze(ctx, prompt, data)
+}
+
+// IsHealthy 默认后端是否可用
+func (r *AIServiceRouter) IsHealthy() bool {
+	return r.defaultService.IsHealthy()
+}
+
+// GetModelInfo 默认后端的模型信息
+func (r *AIServiceRouter) GetModelInfo() *ModelInfo {
+	return r.defaultService.GetModelInfo()
+}
+
+// stripCodeFence 去掉模型输出中包裹 JSON 的 Markdown 代码块
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") {
+		return content
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+		trimmed = trimmed[newline+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(trimmed), "```"))
+}
+
+// ParseTypeBackends 解析按分析类型指定的后端，格式为 type:backend，逗号分隔
+func ParseTypeBackends(spec string) (map[analysis.AnalysisType]string, error) {
+	backends := make(map[analysis.AnalysisType]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid type backend %q, expected type:backend", item)
+		}
+		backend := strings.TrimSpace(parts[1])
+		switch backend {
+		case BackendOllama, BackendOpenAI, BackendDify:
+		default:
+			return nil, fmt.Errorf("unsupported AI backend %q", backend)
+		}
+		backends[analysis.AnalysisType(strings.TrimSpace(parts[0]))] = backend
+	}
+	return backends, nil
+}
@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+)
+
+// ErrClassifierBusy 同时进行的 LLM 分类已达上限，调用方应使用本地分类结果
+var ErrClassifierBusy = errors.New("llm classifier is busy")
+
+// LLMAlertClassifier 通过分析引擎完成告警分类，作为本地分类模型置信度不足时的兜底。
+// 调用经过引擎的响应缓存、租户预算与模型并发限制；分类在告警处理路径上同步执行，
+// 因此使用独立的短超时，并发已满时立即放弃而不是排队等待。
+type LLMAlertClassifier struct {
+	engine  analysis.AnalysisEngine
+	timeout time.Duration
+	slots   chan struct{}
+}
+
+// NewLLMAlertClassifier 创建 LLM 告警分类器，engine 为 nil 时返回 nil
+func NewLLMAlertClassifier(engine analysis.AnalysisEngine, timeout time.Duration, concurrency int) gateway.AlertClassifier {
+	if engine == nil {
+		return nil
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &LLMAlertClassifier{
+		engine:  engine,
+		timeout: timeout,
+		slots:   make(chan struct{}, concurrency),
+	}
+}
+
+// Classify 按分类 schema 分析告警，预算耗尽时引擎只能给出规则结果，视为兜底失败
+func (c *LLMAlertClassifier) Classify(ctx context.Context, alert *model.Alert) (*gateway.Classification, error) {
+	select {
+	case c.slots <- struct{}{}:
+		defer func() { <-c.slots }()
+	default:
+		return nil, ErrClassifierBusy
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	result, err := c.engine.Analyze(ctx, &analysis.AnalysisRequest{
+		Alert:   alert,
+		Type:    analysis.AnalysisTypeClassification,
+		Timeout: c.timeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify alert: %w", err)
+	}
+	if mode, _ := result.Metadata["budget_mode"].(string); mode == string(analysis.BudgetModeFallback) {
+		return nil, fmt.Errorf("failed to classify alert: token budget exhausted")
+	}
+	if result.Structured == nil {
+		return nil, fmt.Errorf("failed to classify alert: no structured output")
+	}
+
+	modelUsed, _ := result.Metadata["model_used"].(string)
+	return &gateway.Classification{
+		Category:     result.Structured.Category,
+		Severity:     result.Structured.Severity,
+		Confidence:   result.Structured.Confidence,
+		Source:       gateway.ClassificationSourceLLM,
+		ModelVersion: modelUsed,
+	}, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"alert_agent/internal/domain/analysis"
+	gatewayDomain "alert_agent/internal/domain/gateway"
 	"alert_agent/internal/model"
 	"alert_agent/internal/pkg/logger"
 
@@ -18,6 +19,10 @@ import (
 type AnalysisEngineImpl struct {
 	aiService    AIService
 	templateRepo TemplateRepository
+	retriever    analysis.KnowledgeRetriever
+	cache        ResponseCache
+	usage        analysis.UsageStore
+	fallback     AIService
 	logger       *zap.Logger
 	config       *EngineConfig
 }
@@ -60,14 +65,21 @@ type ModelInfo struct {
 
 // AnalysisTemplate 分析模板
 type AnalysisTemplate struct {
-	ID          string                 `json:"id"`
-	Name        string                 `json:"name"`
-	Type        analysis.AnalysisType  `json:"type"`
-	Prompt      string                 `json:"prompt"`
-	Parameters  map[string]interface{} `json:"parameters"`
-	Version     string                 `json:"version"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
+	ID         string                 `json:"id" gorm:"primaryKey;size:64"`
+	Name       string                 `json:"name" gorm:"size:128;not null"`
+	Type       analysis.AnalysisType  `json:"type" gorm:"size:64;index;not null"`
+	Prompt     string                 `json:"prompt" gorm:"type:text;not null"`
+	Parameters map[string]interface{} `json:"parameters" gorm:"serializer:json;type:text"`
+	Version    string                 `json:"version" gorm:"size:32"`
+	IsDefault  bool                   `json:"is_default" gorm:"default:false"`
+	Enabled    bool                   `json:"enabled" gorm:"default:true"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AnalysisTemplate) TableName() string {
+	return "analysis_templates"
 }
 
 // EngineConfig 引擎配置
@@ -76,8 +88,10 @@ type EngineConfig struct {
 	MaxRetries      int           `json:"max_retries"`
 	RetryDelay      time.Duration `json:"retry_delay"`
 	MaxPromptLength int           `json:"max_prompt_length"`
-	EnableCache     bool          `json:"enable_cache"`
-	CacheTTL        time.Duration `json:"cache_ttl"`
+	RepairAttempts  int                   `json:"repair_attempts"` // 输出不符合 schema 时要求模型修正的次数
+	EnableCache     bool                  `json:"enable_cache"`
+	CacheTTL        time.Duration         `json:"cache_ttl"`
+	Budget          analysis.BudgetConfig `json:"budget"` // 按租户的每日令牌预算
 }
 
 // DefaultEngineConfig 默认引擎配置
@@ -87,15 +101,21 @@ func DefaultEngineConfig() *EngineConfig {
 		MaxRetries:      3,
 		RetryDelay:      time.Second,
 		MaxPromptLength: 8000,
+		RepairAttempts:  1,
 		EnableCache:     true,
 		CacheTTL:        10 * time.Minute,
+		Budget:          analysis.DefaultBudgetConfig(),
 	}
 }
 
 // NewAnalysisEngine 创建分析引擎
+// retriever 为 nil 时提示中不注入参考资料，cache 为 nil 时不缓存响应，usage 为 nil 时不统计用量也不限制预算
 func NewAnalysisEngine(
 	aiService AIService,
 	templateRepo TemplateRepository,
+	retriever analysis.KnowledgeRetriever,
+	cache ResponseCache,
+	usage analysis.UsageStore,
 	config *EngineConfig,
 ) analysis.AnalysisEngine {
 	if config == nil {
@@ -105,6 +125,10 @@ func NewAnalysisEngine(
 	return &AnalysisEngineImpl{
 		aiService:    aiService,
 		templateRepo: templateRepo,
+		retriever:    retriever,
+		cache:        cache,
+		usage:        usage,
+		fallback:     NewRuleBasedAIService(),
 		logger:       logger.L.Named("analysis-engine"),
 		config:       config,
 	}
@@ -152,8 +176,9 @@ func (e *AnalysisEngineImpl) Analyze(ctx context.Context, request *analysis.Anal
 		return nil, fmt.Errorf("failed to get analysis template: %w", err)
 	}
 
-	// 构建分析提示
-	prompt, err := e.buildAnalysisPrompt(template, request.Alert, request.Options)
+	// 检索参考资料并构建分析提示
+	citations := e.retrieveKnowledge(ctx, request.Alert)
+	prompt, err := e.buildAnalysisPrompt(template, task.Type, request.Alert, request.Options, citations)
 	if err != nil {
 		e.logger.Error("Failed to build analysis prompt",
 			zap.String("task_id", task.ID),
@@ -161,23 +186,55 @@ func (e *AnalysisEngineImpl) Analyze(ctx context.Context, request *analysis.Anal
 		return nil, fmt.Errorf("failed to build analysis prompt: %w", err)
 	}
 
-	// 执行AI分析（带重试）
-	aiResponse, err := e.executeAnalysisWithRetry(ctx, prompt, request.Alert)
+	// 按租户当日预算选择模型，预算耗尽时使用规则分析
+	tenant := e.tenantOf(request)
+	mode := e.budgetMode(ctx, tenant)
+	service := e.serviceFor(task.Type, mode)
+
+	// 优先使用缓存的响应，未命中时执行AI分析（带重试）
+	cacheKey := ResponseCacheKey(service.GetModelInfo().Name, prompt)
+	aiResponse := e.cachedResponse(ctx, mode, cacheKey)
+	cacheHit := aiResponse != nil
+	if !cacheHit {
+		aiResponse, err = e.executeAnalysisWithRetry(ctx, service, prompt, request.Alert)
+		if err != nil {
+			e.logger.Error("Failed to execute AI analysis",
+				zap.String("task_id", task.ID),
+				zap.Error(err))
+			return nil, fmt.Errorf("failed to execute AI analysis: %w", err)
+		}
+	}
+
+	// 提取并校验结构化输出，不合格时要求模型修正
+	output, raw, aiResponse, repairs, err := e.decodeOutput(ctx, service, task.Type, aiResponse, request.Alert)
+	e.recordUsage(ctx, tenant, mode, cacheHit, aiResponse)
 	if err != nil {
-		e.logger.Error("Failed to execute AI analysis",
+		e.logger.Error("Failed to get structured analysis output",
 			zap.String("task_id", task.ID),
+			zap.Int("repair_attempts", repairs),
 			zap.Error(err))
-		return nil, fmt.Errorf("failed to execute AI analysis: %w", err)
+		return nil, fmt.Errorf("failed to get structured analysis output: %w", err)
 	}
 
 	// 解析分析结果
-	result, err := e.parseAnalysisResult(task, aiResponse, template)
+	result, err := e.parseAnalysisResult(task, aiResponse, template, output, raw)
 	if err != nil {
 		e.logger.Error("Failed to parse analysis result",
 			zap.String("task_id", task.ID),
 			zap.Error(err))
 		return nil, fmt.Errorf("failed to parse analysis result: %w", err)
 	}
+	result.Metadata["repair_attempts"] = repairs
+	result.Metadata["tenant"] = tenant
+	result.Metadata["budget_mode"] = string(mode)
+	result.Metadata["cache_hit"] = cacheHit
+	if !cacheHit {
+		e.cacheResponse(ctx, mode, cacheKey, aiResponse)
+	}
+	if len(citations) > 0 {
+		result.Citations = markCited(citations, output.Citations)
+		result.Metadata["knowledge_ids"] = citedDocumentIDs(result.Citations)
+	}
 
 	e.logger.Info("Alert analysis completed",
 		zap.String("task_id", task.ID),
@@ -192,6 +249,104 @@ func (e *AnalysisEngineImpl) IsHealthy() bool {
 	return e.aiService.IsHealthy()
 }
 
+// serviceFor 获取分析类型与预算状态对应的 AI 服务
+func (e *AnalysisEngineImpl) serviceFor(analysisType analysis.AnalysisType, mode analysis.BudgetMode) AIService {
+	switch mode {
+	case analysis.BudgetModeFallback:
+		return e.fallback
+	case analysis.BudgetModeDowngrade:
+		// 未配置低成本模型时继续使用原模型，预算耗尽后再切换到规则分析
+		if downgrader, ok := e.aiService.(AIServiceDowngrader); ok && downgrader.Downgrade() != nil {
+			return downgrader.Downgrade()
+		}
+	}
+	if selector, ok := e.aiService.(AIServiceSelector); ok {
+		return selector.Select(analysisType)
+	}
+	return e.aiService
+}
+
+// tenantOf 获取请求所属租户，只使用告警中配置的租户标签。
+// 分析选项来自提交请求，不能用于决定按哪个租户计费。
+func (e *AnalysisEngineImpl) tenantOf(request *analysis.AnalysisRequest) string {
+	if label := e.config.Budget.TenantLabel; label != "" {
+		if tenant := gatewayDomain.ParseAlertLabels(request.Alert)[label]; tenant != "" {
+			return tenant
+		}
+	}
+	return analysis.DefaultTenant
+}
+
+// budgetMode 获取租户当日预算状态，读取用量失败时不限制
+func (e *AnalysisEngineImpl) budgetMode(ctx context.Context, tenant string) analysis.BudgetMode {
+	if e.usage == nil || e.config.Budget.BudgetFor(tenant) <= 0 {
+		return analysis.BudgetModeNormal
+	}
+	usage, err := e.usage.Get(ctx, tenant, time.Now())
+	if err != nil {
+		e.logger.Warn("Failed to get token usage, skipping budget check",
+			zap.String("tenant", tenant),
+			zap.Error(err))
+		return analysis.BudgetModeNormal
+	}
+	mode := e.config.Budget.Mode(tenant, usage.TotalTokens)
+	if mode != analysis.BudgetModeNormal {
+		e.logger.Info("Token budget limit reached",
+			zap.String("tenant", tenant),
+			zap.String("mode", string(mode)),
+			zap.Int64("used", usage.TotalTokens),
+			zap.Int64("budget", e.config.Budget.BudgetFor(tenant)))
+	}
+	return mode
+}
+
+// recordUsage 记录分析的模型用量，统计失败不影响分析
+func (e *AnalysisEngineImpl) recordUsage(ctx context.Context, tenant string, mode analysis.BudgetMode, cacheHit bool, response *AIResponse) {
+	if e.usage == nil || response == nil {
+		return
+	}
+	event := &analysis.UsageEvent{
+		Tenant:   tenant,
+		Model:    response.ModelUsed,
+		CacheHit: cacheHit,
+		Mode:     mode,
+	}
+	if response.Tokens != nil && !cacheHit {
+		event.PromptTokens = response.Tokens.PromptTokens
+		event.CompletionTokens = response.Tokens.CompletionTokens
+		event.TotalTokens = response.Tokens.TotalTokens
+	}
+	if err := e.usage.Record(ctx, time.Now(), event); err != nil {
+		e.logger.Warn("Failed to record token usage",
+			zap.String("tenant", tenant),
+			zap.Error(err))
+	}
+}
+
+// cachedResponse 获取缓存的模型响应，规则分析不使用缓存
+func (e *AnalysisEngineImpl) cachedResponse(ctx context.Context, mode analysis.BudgetMode, key string) *AIResponse {
+	if e.cache == nil || !e.config.EnableCache || mode == analysis.BudgetModeFallback {
+		return nil
+	}
+	response, err := e.cache.Get(ctx, key)
+	if err != nil {
+		e.logger.Warn("Failed to get cached response", zap.Error(err))
+		return nil
+	}
+	return response
+}
+
+// cacheResponse 缓存通过校验的模型响应，不缓存令牌用量
+func (e *AnalysisEngineImpl) cacheResponse(ctx context.Context, mode analysis.BudgetMode, key string, response *AIResponse) {
+	if e.cache == nil || !e.config.EnableCache || mode == analysis.BudgetModeFallback {
+		return
+	}
+	cached := &AIResponse{Content: response.Content, Metadata: response.Metadata, ModelUsed: response.ModelUsed}
+	if err := e.cache.Set(ctx, key, cached, e.config.CacheTTL); err != nil {
+		e.logger.Warn("Failed to cache response", zap.Error(err))
+	}
+}
+
 // getAnalysisTemplate 获取分析模板
 func (e *AnalysisEngineImpl) getAnalysisTemplate(analysisType analysis.AnalysisType) (*AnalysisTemplate, error) {
 	template, err := e.templateRepo.GetTemplate(analysisType)
@@ -210,11 +365,28 @@ func (e *AnalysisEngineImpl) getAnalysisTemplate(analysisType analysis.AnalysisT
 	return template, nil
 }
 
-// buildAnalysisPrompt 构建分析提示
+// retrieveKnowledge 检索告警相关的参考资料，检索失败不影响分析
+func (e *AnalysisEngineImpl) retrieveKnowledge(ctx context.Context, alert *model.Alert) []*analysis.Citation {
+	if e.retriever == nil {
+		return nil
+	}
+	citations, err := e.retriever.Retrieve(ctx, alert, 0)
+	if err != nil {
+		e.logger.Warn("Failed to retrieve knowledge, analyzing without references",
+			zap.Uint("alert_id", alert.ID),
+			zap.Error(err))
+		return nil
+	}
+	return citations
+}
+
+// buildAnalysisPrompt 构建分析提示，末尾追加参考资料与分析类型的输出 schema
 func (e *AnalysisEngineImpl) buildAnalysisPrompt(
 	template *AnalysisTemplate,
+	analysisType analysis.AnalysisType,
 	alert *model.Alert,
 	parameters map[string]interface{},
+	citations []*analysis.Citation,
 ) (string, error) {
 	// 准备模板变量
 	vars := map[string]interface{}{
@@ -239,15 +411,52 @@ func (e *AnalysisEngineImpl) buildAnalysisPrompt(
 		prompt = strings.ReplaceAll(prompt, placeholder, valueStr)
 	}
 
-	// 检查提示长度
+	// 检查提示长度，参考资料与输出格式说明不参与截断
 	if len(prompt) > e.config.MaxPromptLength {
 		e.logger.Warn("Prompt length exceeds maximum, truncating",
 			zap.Int("length", len(prompt)),
 			zap.Int("max_length", e.config.MaxPromptLength))
-		prompt = prompt[:e.config.MaxPromptLength]
+		prompt = strings.ToValidUTF8(prompt[:e.config.MaxPromptLength], "")
 	}
 
-	return prompt, nil
+	return prompt + knowledgeSection(citations) + OutputInstruction(analysisType), nil
+}
+
+// knowledgeSection 参考资料的提示词片段，要求模型在 citations 中列出引用的编号
+func knowledgeSection(citations []*analysis.Citation) string {
+	if len(citations) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n参考资料（来自知识库与已处理的历史告警，仅在相关时采用，结论中引用时在 citations 字段列出编号）：\n")
+	for _, citation := range citations {
+		fmt.Fprintf(&b, "[%s] %s（%s #%d，相似度 %.2f）\n%s\n",
+			citation.Ref, citation.Title, citation.SourceType, citation.SourceID, citation.Score, citation.Snippet)
+	}
+	return b.String()
+}
+
+// markCited 标记模型输出引用的参考资料
+func markCited(citations []*analysis.Citation, refs []string) []*analysis.Citation {
+	cited := make(map[string]struct{}, len(refs))
+	for _, ref := range refs {
+		cited[strings.Trim(strings.TrimSpace(ref), "[]")] = struct{}{}
+	}
+	for _, citation := range citations {
+		_, citation.Cited = cited[citation.Ref]
+	}
+	return citations
+}
+
+// citedDocumentIDs 被引用的知识文档ID
+func citedDocumentIDs(citations []*analysis.Citation) []string {
+	ids := make([]string, 0, len(citations))
+	for _, citation := range citations {
+		if citation.Cited {
+			ids = append(ids, citation.DocumentID)
+		}
+	}
+	return ids
 }
 
 // formatValue 格式化值为字符串
@@ -273,6 +482,7 @@ func (e *AnalysisEngineImpl) formatValue(value interface{}) string {
 // executeAnalysisWithRetry 执行AI分析（带重试）
 func (e *AnalysisEngineImpl) executeAnalysisWithRetry(
 	ctx context.Context,
+	service AIService,
 	prompt string,
 	alert *model.Alert,
 ) (*AIResponse, error) {
@@ -292,7 +502,7 @@ func (e *AnalysisEngineImpl) executeAnalysisWithRetry(
 			}
 		}
 
-		response, err := e.aiService.Analyze(ctx, prompt, alert)
+		response, err := service.Analyze(ctx, prompt, alert)
 		if err == nil {
 			return response, nil
 		}
@@ -307,44 +517,64 @@ func (e *AnalysisEngineImpl) executeAnalysisWithRetry(
 	return nil, fmt.Errorf("AI analysis failed after %d attempts: %w", e.config.MaxRetries+1, lastErr)
 }
 
-// parseAnalysisResult 解析分析结果
-func (e *AnalysisEngineImpl) parseAnalysisResult(
-	task *analysis.AnalysisTask,
+// decodeOutput 解析结构化输出，不符合 schema 时携带问题要求模型修正，返回最后一次响应与修正次数
+func (e *AnalysisEngineImpl) decodeOutput(
+	ctx context.Context,
+	service AIService,
+	analysisType analysis.AnalysisType,
 	aiResponse *AIResponse,
-	template *AnalysisTemplate,
-) (*analysis.AnalysisResult, error) {
-	// 尝试解析结构化结果
-	var structuredResult map[string]interface{}
-	if err := json.Unmarshal([]byte(aiResponse.Content), &structuredResult); err != nil {
-		// 如果不是JSON，作为纯文本处理
-		structuredResult = map[string]interface{}{
-			"analysis": aiResponse.Content,
-			"type":     "text",
+	alert *model.Alert,
+) (*analysis.StructuredOutput, map[string]interface{}, *AIResponse, int, error) {
+	tokens := aiResponse.Tokens
+	for repairs := 0; ; repairs++ {
+		output, raw, err := ParseStructuredOutput(analysisType, aiResponse.Content)
+		if err == nil {
+			aiResponse.Tokens = tokens
+			return output, raw, aiResponse, repairs, nil
+		}
+		if repairs >= e.config.RepairAttempts {
+			return nil, nil, aiResponse, repairs, err
 		}
-	}
 
-	// 提取置信度
-	confidence := 0.5 // 默认置信度
-	if conf, ok := structuredResult["confidence"].(float64); ok {
-		confidence = conf
-	} else if conf, ok := structuredResult["confidence"].(int); ok {
-		confidence = float64(conf) / 100.0
+		e.logger.Warn("Analysis output failed schema validation, requesting repair",
+			zap.Uint("alert_id", alert.ID),
+			zap.String("analysis_type", string(analysisType)),
+			zap.Error(err))
+		repaired, repairErr := e.executeAnalysisWithRetry(ctx, service, RepairPrompt(analysisType, aiResponse.Content, err), alert)
+		if repairErr != nil {
+			return nil, nil, aiResponse, repairs + 1, fmt.Errorf("%v; repair request failed: %w", err, repairErr)
+		}
+		tokens = addTokenUsage(tokens, repaired.Tokens)
+		aiResponse = repaired
 	}
+}
 
-	// 提取严重程度
-	severity := "medium"
-	if sev, ok := structuredResult["severity"].(string); ok {
-		severity = sev
+// addTokenUsage 累加修正请求的令牌用量
+func addTokenUsage(total, usage *TokenUsage) *TokenUsage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		return usage
+	}
+	return &TokenUsage{
+		PromptTokens:     total.PromptTokens + usage.PromptTokens,
+		CompletionTokens: total.CompletionTokens + usage.CompletionTokens,
+		TotalTokens:      total.TotalTokens + usage.TotalTokens,
 	}
+}
 
-	// 提取建议
-	recommendations := []string{}
-	if recs, ok := structuredResult["recommendations"].([]interface{}); ok {
-		for _, rec := range recs {
-			if recStr, ok := rec.(string); ok {
-				recommendations = append(recommendations, recStr)
-			}
-		}
+// parseAnalysisResult 由结构化输出构建分析结果
+func (e *AnalysisEngineImpl) parseAnalysisResult(
+	task *analysis.AnalysisTask,
+	aiResponse *AIResponse,
+	template *AnalysisTemplate,
+	output *analysis.StructuredOutput,
+	structuredResult map[string]interface{},
+) (*analysis.AnalysisResult, error) {
+	severity := output.Severity
+	if severity == "" {
+		severity = analysis.SeverityMedium
 	}
 
 	// 构建分析结果
@@ -355,9 +585,10 @@ func (e *AnalysisEngineImpl) parseAnalysisResult(
 		Type:            task.Type,
 		Status:          analysis.AnalysisStatusCompleted,
 		Result:          structuredResult,
-		Summary:         aiResponse.Content,
-		ConfidenceScore: confidence,
-		Recommendations: recommendations,
+		Summary:         output.Summary,
+		ConfidenceScore: output.Confidence,
+		Recommendations: output.ActionTitles(),
+		Structured:      output,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 		Metadata: map[string]interface{}{
@@ -366,8 +597,15 @@ func (e *AnalysisEngineImpl) parseAnalysisResult(
 			"model_used":       aiResponse.ModelUsed,
 			"analysis_time":    time.Now().Format(time.RFC3339),
 			"severity":         severity,
+			"schema_version":   analysis.OutputSchemaVersion,
 		},
 	}
+	if output.Category != "" {
+		result.Metadata["category"] = output.Category
+	}
+	if output.Priority != "" {
+		result.Metadata["priority"] = output.Priority
+	}
 
 	// 添加令牌使用信息
 	if aiResponse.Tokens != nil {
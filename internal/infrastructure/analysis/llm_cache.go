@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// responseCacheKey 模型响应缓存键模板
+const responseCacheKey = "analysis:llm_cache:%s"
+
+// ResponseCache 模型响应缓存，只缓存通过 schema 校验的响应
+type ResponseCache interface {
+	// Get 获取缓存的响应，未命中时返回 nil
+	Get(ctx context.Context, key string) (*AIResponse, error)
+
+	// Set 缓存响应
+	Set(ctx context.Context, key string, response *AIResponse, ttl time.Duration) error
+}
+
+var (
+	// 提示中的时间戳每次分析都不同，归一化后同一类告警风暴可以共享缓存
+	promptTimestampPattern  = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	promptWhitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// NormalizePrompt 归一化提示，去掉时间戳并合并空白
+func NormalizePrompt(prompt string) string {
+	prompt = promptTimestampPattern.ReplaceAllString(prompt, "<time>")
+	return strings.TrimSpace(promptWhitespacePattern.ReplaceAllString(prompt, " "))
+}
+
+// ResponseCacheKey 由模型与归一化后的提示生成缓存键
+func ResponseCacheKey(model, prompt string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + NormalizePrompt(prompt)))
+	return hex.EncodeToString(sum[:])
+}
+
+// RedisResponseCache 基于 Redis 的模型响应缓存
+type RedisResponseCache struct {
+	client *redis.Client
+}
+
+// NewRedisResponseCache 创建模型响应缓存
+func NewRedisResponseCache(client *redis.Client) ResponseCache {
+	return &RedisResponseCache{client: client}
+}
+
+// Get 获取缓存的响应
+func (c *RedisResponseCache) Get(ctx context.Context, key string) (*AIResponse, error) {
+	data, err := c.client.Get(ctx, fmt.Sprintf(responseCacheKey, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached response: %w", err)
+	}
+
+	var response AIResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached response: %w", err)
+	}
+	return &response, nil
+}
+
+// Set 缓存响应
+func (c *RedisResponseCache) Set(ctx context.Context, key string, response *AIResponse, ttl time.Duration) error {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+	if err := c.client.Set(ctx, fmt.Sprintf(responseCacheKey, key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestResponseCacheKey_NormalizesPrompt(t *testing.T) {
+	a := ResponseCacheKey("qwen", "告警 DiskFull\n时间 2024-05-01T10:00:00+08:00")
+	b := ResponseCacheKey("qwen", "告警   DiskFull 时间 2024-05-01T10:05:31.123Z ")
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, ResponseCacheKey("deepseek", "告警 DiskFull 时间 2024-05-01T10:00:00Z"))
+	assert.NotEqual(t, a, ResponseCacheKey("qwen", "告警 CPUHigh 时间 2024-05-01T10:00:00Z"))
+}
+
+func TestParseTenantBudgets(t *testing.T) {
+	budgets, err := analysis.ParseTenantBudgets("team-a:100000, team-b:0,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"team-a": 100000, "team-b": 0}, budgets)
+
+	_, err = analysis.ParseTenantBudgets("team-a")
+	assert.Error(t, err)
+	_, err = analysis.ParseTenantBudgets("team-a:-1")
+	assert.Error(t, err)
+}
+
+func TestAnalysisEngine_CacheAndTokenBudget(t *testing.T) {
+	logger.L = zap.NewNop()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	answer := `{"summary":"磁盘将满","severity":"high","confidence":0.8,"category":"storage","reason":"使用率 95%"}`
+	primary := &scriptedAIService{contents: []string{answer, answer}}
+	cheap := &scriptedAIService{contents: []string{answer}}
+	usage := NewRedisUsageStore(client, 0)
+	config := DefaultEngineConfig()
+	config.Timeout = time.Second
+	config.Budget.DailyTokens = 40
+	config.Budget.DowngradeRatio = 0.5
+	engine := NewAnalysisEngine(
+		NewAIServiceRouter(primary, nil, cheap),
+		memoryTemplateRepository{
+			analysis.AnalysisTypeClassification: {ID: "tpl", Prompt: "告警 {{alert_name}} 时间 {{timestamp}}"},
+		},
+		nil,
+		NewRedisResponseCache(client),
+		usage,
+		config,
+	)
+	analyze := func(name string) *analysis.AnalysisResult {
+		result, err := engine.Analyze(context.Background(), &analysis.AnalysisRequest{
+			Alert: &model.Alert{Name: name, Title: name + " on node-1", Level: "high", Labels: `{"tenant":"team-a"}`},
+			Type:  analysis.AnalysisTypeClassification,
+			// 提交请求中的租户选项不影响计费租户
+			Options: map[string]interface{}{"tenant": "team-b"},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	result := analyze("DiskFull")
+	assert.Equal(t, false, result.Metadata["cache_hit"])
+	assert.Equal(t, "team-a", result.Metadata["tenant"])
+
+	// 同样的告警命中缓存，不调用模型也不消耗令牌
+	result = analyze("DiskFull")
+	assert.Equal(t, true, result.Metadata["cache_hit"])
+	assert.Len(t, primary.prompts, 1)
+	assert.Equal(t, analysis.CategoryStorage, result.Structured.Category)
+
+	analyze("DiskFull2")
+	assert.Len(t, primary.prompts, 2)
+
+	// 用量 30 超过降级阈值 20，改用低成本模型
+	result = analyze("DiskFull3")
+	assert.Equal(t, string(analysis.BudgetModeDowngrade), result.Metadata["budget_mode"])
+	assert.Len(t, cheap.prompts, 1)
+
+	// 用量 45 超过预算 40，使用规则分析
+	result = analyze("DiskFull4")
+	assert.Equal(t, string(analysis.BudgetModeFallback), result.Metadata["budget_mode"])
+	assert.Equal(t, BackendRules, result.Metadata["model_used"])
+	assert.Equal(t, analysis.CategoryStorage, result.Structured.Category)
+	assert.Equal(t, analysis.SeverityHigh, result.Structured.Severity)
+	assert.Len(t, primary.prompts, 2)
+	assert.Len(t, cheap.prompts, 1)
+
+	today, err := usage.Get(context.Background(), "team-a", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(45), today.TotalTokens)
+	assert.Equal(t, int64(5), today.Requests)
+	assert.Equal(t, int64(1), today.CacheHits)
+	assert.Equal(t, int64(1), today.Downgrades)
+	assert.Equal(t, int64(1), today.Fallbacks)
+	assert.Equal(t, map[string]int64{"scripted": 45}, today.Models)
+
+	config.Budget.Apply(today)
+	assert.Equal(t, int64(0), today.Remaining)
+	assert.Equal(t, analysis.BudgetModeFallback, today.Mode)
+
+	all, err := usage.List(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "team-a", all[0].Tenant)
+}
+
+func TestLLMAlertClassifier_UsesEngineCacheAndBudget(t *testing.T) {
+	logger.L = zap.NewNop()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	answer := `{"summary":"磁盘将满","severity":"high","confidence":0.8,"category":"storage","reason":"使用率 95%"}`
+	primary := &scriptedAIService{contents: []string{answer, answer}}
+	config := DefaultEngineConfig()
+	config.Timeout = time.Second
+	config.Budget.DailyTokens = 20
+	config.Budget.DowngradeRatio = 0
+	engine := NewAnalysisEngine(
+		primary,
+		memoryTemplateRepository{
+			analysis.AnalysisTypeClassification: {ID: "tpl", Prompt: "告警 {{alert_name}}"},
+		},
+		nil,
+		NewRedisResponseCache(client),
+		NewRedisUsageStore(client, 0),
+		config,
+	)
+	classifier := NewLLMAlertClassifier(engine, time.Second, 1)
+
+	result, err := classifier.Classify(context.Background(), &model.Alert{Name: "DiskFull", Level: "high"})
+	require.NoError(t, err)
+	assert.Equal(t, analysis.CategoryStorage, result.Category)
+	assert.Equal(t, "scripted", result.ModelVersion)
+
+	// 相同告警命中引擎缓存，不再调用模型
+	_, err = classifier.Classify(context.Background(), &model.Alert{Name: "DiskFull", Level: "high"})
+	require.NoError(t, err)
+	assert.Len(t, primary.prompts, 1)
+
+	_, err = classifier.Classify(context.Background(), &model.Alert{Name: "CPUHigh", Level: "high"})
+	require.NoError(t, err)
+	assert.Len(t, primary.prompts, 2)
+
+	// 用量 30 超过预算 20，不再调用模型，调用方沿用本地分类
+	_, err = classifier.Classify(context.Background(), &model.Alert{Name: "MemoryHigh", Level: "high"})
+	assert.Error(t, err)
+	assert.Len(t, primary.prompts, 2)
+}
+
+// blockingEngine 在上下文取消前阻塞的分析引擎
+type blockingEngine struct {
+	analysis.AnalysisEngine
+	started chan struct{}
+}
+
+func (e *blockingEngine) Analyze(ctx context.Context, request *analysis.AnalysisRequest) (*analysis.AnalysisResult, error) {
+	close(e.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLLMAlertClassifier_TimeoutAndConcurrencyLimit(t *testing.T) {
+	engine := &blockingEngine{started: make(chan struct{})}
+	classifier := NewLLMAlertClassifier(engine, 200*time.Millisecond, 1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := classifier.Classify(context.Background(), &model.Alert{Name: "DiskFull"})
+		done <- err
+	}()
+	<-engine.started
+
+	// 已有分类在进行时立即放弃，不排队等待
+	_, err := classifier.Classify(context.Background(), &model.Alert{Name: "CPUHigh"})
+	assert.ErrorIs(t, err, ErrClassifierBusy)
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(2 * time.Second):
+		t.Fatal("classification did not time out")
+	}
+}
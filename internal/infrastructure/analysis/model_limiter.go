@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"alert_agent/internal/domain/analysis"
+)
+
+// ModelLimiter 按模型名称限制并发调用，同名模型共享并发额度
+type ModelLimiter struct {
+	limits map[string]int
+	mu     sync.Mutex
+	models map[string]*modelSlot
+}
+
+type modelSlot struct {
+	limit    int
+	sem      chan struct{} // 不限制并发时为 nil
+	inFlight int
+}
+
+// NewModelLimiter 创建模型并发限制，limits 中没有的模型不限制并发
+func NewModelLimiter(limits map[string]int) *ModelLimiter {
+	return &ModelLimiter{limits: limits, models: make(map[string]*modelSlot)}
+}
+
+// Wrap 返回限制并发的 AI 服务，service 为 nil 时返回 nil
+func (l *ModelLimiter) Wrap(service AIService) AIService {
+	if service == nil {
+		return nil
+	}
+	name := service.GetModelInfo().Name
+	l.mu.Lock()
+	slot, ok := l.models[name]
+	if !ok {
+		slot = &modelSlot{limit: l.limits[name]}
+		if slot.limit > 0 {
+			slot.sem = make(chan struct{}, slot.limit)
+		}
+		l.models[name] = slot
+	}
+	l.mu.Unlock()
+	return &limitedAIService{AIService: service, limiter: l, slot: slot}
+}
+
+// Snapshot 返回已接入模型的并发上限与当前调用数
+func (l *ModelLimiter) Snapshot() map[string]analysis.ModelSlots {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	snapshot := make(map[string]analysis.ModelSlots, len(l.models))
+	for name, slot := range l.models {
+		snapshot[name] = analysis.ModelSlots{Limit: slot.limit, InFlight: slot.inFlight}
+	}
+	return snapshot
+}
+
+func (l *ModelLimiter) track(slot *modelSlot, delta int) {
+	l.mu.Lock()
+	slot.inFlight += delta
+	l.mu.Unlock()
+}
+
+// limitedAIService 调用前占用模型的并发额度，额度用尽时等待
+type limitedAIService struct {
+	AIService
+	limiter *ModelLimiter
+	slot    *modelSlot
+}
+
+// Analyze 占用并发额度后调用模型
+func (s *limitedAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	if s.slot.sem != nil {
+		select {
+		case s.slot.sem <- struct{}{}:
+			defer func() { <-s.slot.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	s.limiter.track(s.slot, 1)
+	defer s.limiter.track(s.slot, -1)
+	return s.AIService.Analyze(ctx, prompt, data)
+}
+
+// ParseModelConcurrency 解析模型并发上限，格式为 model:limit，逗号分隔，模型名可包含冒号
+func ParseModelConcurrency(spec string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		idx := strings.LastIndex(item, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid model concurrency %q, expected model:limit", item)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(item[idx+1:]))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid model concurrency %q, limit must be a positive integer", item)
+		}
+		limits[strings.TrimSpace(item[:idx])] = limit
+	}
+	return limits, nil
+}
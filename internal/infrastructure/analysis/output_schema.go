@@ -0,0 +1,360 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"alert_agent/internal/domain/analysis"
+)
+
+// JSONSchema JSON Schema 的子集，用于在提示词中描述输出格式并校验模型输出
+type JSONSchema struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	Minimum     *float64               `json:"minimum,omitempty"`
+	Maximum     *float64               `json:"maximum,omitempty"`
+	MinItems    int                    `json:"minItems,omitempty"`
+	MinLength   int                    `json:"minLength,omitempty"`
+}
+
+// OutputError 模型输出无法提取 JSON 或不符合 schema
+type OutputError struct {
+	Problems []string
+}
+
+func (e *OutputError) Error() string {
+	return "invalid analysis output: " + strings.Join(e.Problems, "; ")
+}
+
+// maxRepairEcho 修复提示中回显上一次输出的最大字符数
+const maxRepairEcho = 4000
+
+func float64Ptr(v float64) *float64 { return &v }
+
+func stringSchema(description string, enum ...string) *JSONSchema {
+	return &JSONSchema{Type: "string", Description: description, Enum: enum, MinLength: 1}
+}
+
+func ratioSchema(description string) *JSONSchema {
+	return &JSONSchema{Type: "number", Description: description, Minimum: float64Ptr(0), Maximum: float64Ptr(1)}
+}
+
+func stringArraySchema(description string) *JSONSchema {
+	return &JSONSchema{Type: "array", Description: description, Items: &JSONSchema{Type: "string"}}
+}
+
+func actionsSchema(minItems int) *JSONSchema {
+	return &JSONSchema{
+		Type:        "array",
+		Description: "推荐操作，按执行顺序排列",
+		MinItems:    minItems,
+		Items: &JSONSchema{
+			Type: "object",
+			Properties: map[string]*JSONSchema{
+				"title":   stringSchema("操作说明"),
+				"command": {Type: "string", Description: "可直接执行的命令，没有时留空"},
+				"risk":    stringSchema("操作风险", "low", "medium", "high"),
+			},
+			Required: []string{"title"},
+		},
+	}
+}
+
+// objectSchema 结构化输出的对象 schema，包含所有类型共有的 summary、severity、confidence
+func objectSchema(required []string, properties map[string]*JSONSchema) *JSONSchema {
+	schema := &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"summary":    stringSchema("一句话结论"),
+			"severity":   stringSchema("严重程度", analysis.Severities...),
+			"confidence": ratioSchema("结论的置信度，0~1"),
+			"citations":  stringArraySchema("结论引用的参考资料编号，如 K1，没有参考资料时省略"),
+		},
+		Required: append([]string{"summary", "confidence"}, required...),
+	}
+	for name, property := range properties {
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+// OutputSchemas 各分析类型的输出 schema
+var OutputSchemas = map[analysis.AnalysisType]*JSONSchema{
+	analysis.AnalysisTypeRootCause: objectSchema([]string{"severity", "root_causes"}, map[string]*JSONSchema{
+		"root_causes": {
+			Type:        "array",
+			Description: "可能的根因，按可能性从高到低排列",
+			MinItems:    1,
+			Items: &JSONSchema{
+				Type: "object",
+				Properties: map[string]*JSONSchema{
+					"description": stringSchema("根因描述"),
+					"likelihood":  ratioSchema("可能性，0~1"),
+					"evidence":    stringArraySchema("支撑该判断的告警内容或指标"),
+				},
+				Required: []string{"description", "likelihood"},
+			},
+		},
+		"actions": actionsSchema(0),
+	}),
+	analysis.AnalysisTypeImpactAssess: objectSchema([]string{"severity", "impact"}, map[string]*JSONSchema{
+		"impact": {
+			Type: "object",
+			Properties: map[string]*JSONSchema{
+				"scope":             stringSchema("影响范围", "single_instance", "service", "multi_service", "global"),
+				"affected_services": stringArraySchema("受影响的服务"),
+				"user_impact":       {Type: "string", Description: "对用户的影响"},
+			},
+			Required: []string{"scope"},
+		},
+		"actions": actionsSchema(0),
+	}),
+	analysis.AnalysisTypeSolution: objectSchema([]string{"severity", "actions"}, map[string]*JSONSchema{
+		"actions": actionsSchema(1),
+	}),
+	analysis.AnalysisTypeClassification: objectSchema([]string{"category", "reason"}, map[string]*JSONSchema{
+		"category": stringSchema("告警类别", analysis.Categories...),
+		"reason":   stringSchema("分类依据"),
+	}),
+	analysis.AnalysisTypePriority: objectSchema([]string{"priority", "severity", "reason"}, map[string]*JSONSchema{
+		"priority": stringSchema("处理优先级，P0 最高", analysis.Priorities...),
+		"reason":   stringSchema("定级依据"),
+		"actions":  actionsSchema(0),
+	}),
+}
+
+// OutputSchemaFor 获取分析类型的输出 schema，未知类型使用根因分析 schema
+func OutputSchemaFor(analysisType analysis.AnalysisType) *JSONSchema {
+	if schema, ok := OutputSchemas[analysisType]; ok {
+		return schema
+	}
+	return OutputSchemas[analysis.AnalysisTypeRootCause]
+}
+
+// OutputInstruction 要求模型按 schema 输出 JSON 的提示词片段
+func OutputInstruction(analysisType analysis.AnalysisType) string {
+	schema, _ := json.MarshalIndent(OutputSchemaFor(analysisType), "", "  ")
+	return "\n请只输出一个 JSON 对象，不要输出解释或 Markdown，字段需符合以下 JSON Schema：\n" + string(schema) + "\n"
+}
+
+// RepairPrompt 模型输出不合格时要求其修正的提示词
+func RepairPrompt(analysisType analysis.AnalysisType, previous string, cause error) string {
+	if runes := []rune(previous); len(runes) > maxRepairEcho {
+		previous = string(runes[:maxRepairEcho])
+	}
+	return fmt.Sprintf("你上一次的输出不符合要求：%s\n\n上一次的输出：\n%s\n\n请修正后重新输出。%s",
+		cause.Error(), previous, OutputInstruction(analysisType))
+}
+
+// ParseStructuredOutput 从模型输出中提取 JSON，规范化后按分析类型的 schema 校验
+// 返回类型化结果与规范化后的 JSON 对象
+func ParseStructuredOutput(analysisType analysis.AnalysisType, content string) (*analysis.StructuredOutput, map[string]interface{}, error) {
+	object, err := extractJSONObject(content)
+	if err != nil {
+		return nil, nil, &OutputError{Problems: []string{err.Error()}}
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(object), &raw); err != nil {
+		return nil, nil, &OutputError{Problems: []string{"output is not a valid JSON object: " + err.Error()}}
+	}
+	normalizeOutput(raw)
+
+	if problems := OutputSchemaFor(analysisType).Validate(raw); len(problems) > 0 {
+		return nil, nil, &OutputError{Problems: problems}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal analysis output: %w", err)
+	}
+	var output analysis.StructuredOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, nil, &OutputError{Problems: []string{err.Error()}}
+	}
+	return &output, raw, nil
+}
+
+// extractJSONObject 提取内容中第一个完整的 JSON 对象，容忍代码块与前后说明文字
+func extractJSONObject(content string) (string, error) {
+	content = stripCodeFence(content)
+	start := strings.IndexByte(content, '{')
+	if start < 0 {
+		return "", fmt.Errorf("no JSON object found in output")
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return content[start : i+1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("JSON object in output is incomplete")
+}
+
+// normalizeOutput 修正模型常见的格式偏差：百分制置信度、大小写、字符串数字、旧版 recommendations 字段
+func normalizeOutput(raw map[string]interface{}) {
+	normalizeRatio(raw, "confidence")
+	normalizeCase(raw, "severity", strings.ToLower)
+	normalizeCase(raw, "category", strings.ToLower)
+	normalizeCase(raw, "priority", strings.ToUpper)
+
+	if causes, ok := raw["root_causes"].([]interface{}); ok {
+		for _, cause := range causes {
+			if object, ok := cause.(map[string]interface{}); ok {
+				normalizeRatio(object, "likelihood")
+			}
+		}
+	}
+	if impact, ok := raw["impact"].(map[string]interface{}); ok {
+		normalizeCase(impact, "scope", strings.ToLower)
+	}
+
+	if _, ok := raw["actions"]; !ok {
+		if recommendations, ok := raw["recommendations"].([]interface{}); ok {
+			actions := make([]interface{}, 0, len(recommendations))
+			for _, recommendation := range recommendations {
+				if title, ok := recommendation.(string); ok {
+					actions = append(actions, map[string]interface{}{"title": title})
+				}
+			}
+			raw["actions"] = actions
+		}
+	}
+	delete(raw, "recommendations")
+
+	if actions, ok := raw["actions"].([]interface{}); ok {
+		for _, action := range actions {
+			if object, ok := action.(map[string]interface{}); ok {
+				normalizeCase(object, "risk", strings.ToLower)
+			}
+		}
+	}
+}
+
+// normalizeRatio 将字符串数字与百分制数值转换为 0~1
+func normalizeRatio(object map[string]interface{}, key string) {
+	var value float64
+	switch v := object[key].(type) {
+	case float64:
+		value = v
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(v), "%"), 64)
+		if err != nil {
+			return
+		}
+		value = parsed
+	default:
+		return
+	}
+	if value > 1 && value <= 100 {
+		value /= 100
+	}
+	object[key] = value
+}
+
+// normalizeCase 统一枚举字段的大小写
+func normalizeCase(object map[string]interface{}, key string, convert func(string) string) {
+	if value, ok := object[key].(string); ok {
+		object[key] = convert(strings.TrimSpace(value))
+	}
+}
+
+// Validate 校验值是否符合 schema，返回所有问题
+func (s *JSONSchema) Validate(value interface{}) []string {
+	return s.validate("$", value)
+}
+
+func (s *JSONSchema) validate(path string, value interface{}) []string {
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{path + " must be an object"}
+		}
+		var problems []string
+		for _, name := range s.Required {
+			if v, ok := object[name]; !ok || v == nil {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+		for name, property := range s.Properties {
+			if v, ok := object[name]; ok && v != nil {
+				problems = append(problems, property.validate(path+"."+name, v)...)
+			}
+		}
+		return problems
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{path + " must be an array"}
+		}
+		var problems []string
+		if len(items) < s.MinItems {
+			problems = append(problems, fmt.Sprintf("%s must have at least %d items", path, s.MinItems))
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				problems = append(problems, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+		return problems
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []string{path + " must be a string"}
+		}
+		if len([]rune(strings.TrimSpace(str))) < s.MinLength {
+			return []string{path + " must not be empty"}
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			return []string{fmt.Sprintf("%s must be one of %s", path, strings.Join(s.Enum, ", "))}
+		}
+		return nil
+	case "number":
+		number, ok := value.(float64)
+		if !ok {
+			return []string{path + " must be a number"}
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			return []string{fmt.Sprintf("%s must be >= %g", path, *s.Minimum)}
+		}
+		if s.Maximum != nil && number > *s.Maximum {
+			return []string{fmt.Sprintf("%s must be <= %g", path, *s.Maximum)}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
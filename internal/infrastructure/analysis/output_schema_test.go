@@ -0,0 +1,161 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestParseStructuredOutput_TolerantExtraction(t *testing.T) {
+	content := "分析如下：\n```json\n" + `{
+  "summary": "数据库连接池耗尽 {原因见下}",
+  "severity": "HIGH",
+  "confidence": 85,
+  "root_causes": [{"description": "慢查询占用连接", "likelihood": "70%", "evidence": ["active=100/100"]}],
+  "actions": [{"title": "终止慢查询", "command": "KILL 123", "risk": "Medium"}]
+}` + "\n```\n以上供参考。"
+
+	output, raw, err := ParseStructuredOutput(analysis.AnalysisTypeRootCause, content)
+	require.NoError(t, err)
+	assert.Equal(t, "数据库连接池耗尽 {原因见下}", output.Summary)
+	assert.Equal(t, analysis.SeverityHigh, output.Severity)
+	assert.InDelta(t, 0.85, output.Confidence, 1e-9)
+	require.Len(t, output.RootCauses, 1)
+	assert.InDelta(t, 0.7, output.RootCauses[0].Likelihood, 1e-9)
+	assert.Equal(t, "medium", output.Actions[0].Risk)
+	assert.Equal(t, []string{"终止慢查询"}, output.ActionTitles())
+	assert.Equal(t, "high", raw["severity"])
+	assert.Contains(t, output.Markdown(), "终止慢查询")
+}
+
+func TestParseStructuredOutput_SchemaViolations(t *testing.T) {
+	_, _, err := ParseStructuredOutput(analysis.AnalysisTypeClassification, "无法判断")
+	var outputErr *OutputError
+	require.ErrorAs(t, err, &outputErr)
+	assert.Contains(t, outputErr.Problems[0], "no JSON object")
+
+	_, _, err = ParseStructuredOutput(analysis.AnalysisTypeClassification,
+		`{"summary":"","confidence":150,"category":"disk"}`)
+	require.ErrorAs(t, err, &outputErr)
+	assert.ElementsMatch(t, []string{
+		"$.reason is required",
+		"$.summary must not be empty",
+		"$.confidence must be <= 1",
+		"$.category must be one of performance, storage, network, application, database, security, general",
+	}, outputErr.Problems)
+
+	_, _, err = ParseStructuredOutput(analysis.AnalysisTypeSolution,
+		`{"summary":"重启服务","severity":"low","confidence":0.6,"actions":[]}`)
+	assert.ErrorContains(t, err, "$.actions must have at least 1 items")
+}
+
+func TestOutputInstruction_IncludesSchema(t *testing.T) {
+	instruction := OutputInstruction(analysis.AnalysisTypePriority)
+	assert.Contains(t, instruction, `"priority"`)
+	assert.Contains(t, instruction, `"P0"`)
+	assert.Contains(t, instruction, `"required"`)
+}
+
+// scriptedAIService 按顺序返回预设内容并记录提示词
+type scriptedAIService struct {
+	contents []string
+	prompts  []string
+}
+
+func (s *scriptedAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	s.prompts = append(s.prompts, prompt)
+	if len(s.prompts) > len(s.contents) {
+		return nil, errors.New("no more responses")
+	}
+	return &AIResponse{
+		Content:   s.contents[len(s.prompts)-1],
+		ModelUsed: "scripted",
+		Tokens:    &TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func (s *scriptedAIService) IsHealthy() bool { return true }
+
+func (s *scriptedAIService) GetModelInfo() *ModelInfo { return &ModelInfo{Name: "scripted"} }
+
+func TestAnalysisEngine_RepairsInvalidOutput(t *testing.T) {
+	logger.L = zap.NewNop()
+	request := &analysis.AnalysisRequest{
+		Alert: &model.Alert{Name: "P99LatencyHigh", Level: "warning"},
+		Type:  analysis.AnalysisTypePriority,
+	}
+	newEngine := func(service AIService) analysis.AnalysisEngine {
+		return NewAnalysisEngine(service, memoryTemplateRepository{}, nil, nil, nil, &EngineConfig{
+			Timeout: time.Second, MaxPromptLength: 8000, RepairAttempts: 1,
+		})
+	}
+
+	service := &scriptedAIService{contents: []string{
+		`{"summary":"延迟升高","confidence":0.7,"priority":"urgent"}`,
+		`{"summary":"延迟升高","severity":"high","confidence":0.7,"priority":"p1","reason":"核心接口受影响"}`,
+	}}
+	result, err := newEngine(service).Analyze(context.Background(), request)
+	require.NoError(t, err)
+	require.Len(t, service.prompts, 2)
+	assert.Contains(t, service.prompts[0], "JSON Schema")
+	assert.Contains(t, service.prompts[1], "$.priority must be one of P0, P1, P2, P3")
+	assert.Contains(t, service.prompts[1], `"priority":"urgent"`)
+	assert.Equal(t, analysis.PriorityP1, result.Structured.Priority)
+	assert.Equal(t, "核心接口受影响", result.Structured.Reason)
+	assert.Equal(t, 1, result.Metadata["repair_attempts"])
+	assert.Equal(t, 30, result.Metadata["tokens"].(*TokenUsage).TotalTokens)
+
+	// 修正后仍不合格时分析失败
+	service = &scriptedAIService{contents: []string{"优先级 P1", "优先级 P1"}}
+	_, err = newEngine(service).Analyze(context.Background(), request)
+	assert.ErrorContains(t, err, "no JSON object found")
+	assert.Len(t, service.prompts, 2)
+}
+
+// staticRetriever 返回固定的参考资料
+type staticRetriever []*analysis.Citation
+
+func (r staticRetriever) Retrieve(ctx context.Context, alert *model.Alert, limit int) ([]*analysis.Citation, error) {
+	return r, nil
+}
+
+func TestAnalysisEngine_InjectsKnowledgeCitations(t *testing.T) {
+	logger.L = zap.NewNop()
+	retriever := staticRetriever{
+		{Ref: "K1", DocumentID: "knowledge:3", SourceType: analysis.KnowledgeSourceKnowledge, SourceID: 3, Title: "磁盘清理手册", Snippet: "清理 /var/log", Score: 0.82},
+		{Ref: "K2", DocumentID: "alert:41", SourceType: analysis.KnowledgeSourceAlert, SourceID: 41, Title: "DiskFull node-3", Snippet: "处理说明：扩容数据盘", Score: 0.71},
+	}
+	service := &scriptedAIService{contents: []string{
+		`{"summary":"日志占满磁盘","severity":"high","confidence":0.8,"category":"storage","reason":"磁盘使用率 95%","citations":["[K2]"]}`,
+	}}
+	engine := NewAnalysisEngine(service, memoryTemplateRepository{}, retriever, nil, nil, &EngineConfig{
+		Timeout: time.Second, MaxPromptLength: 8000,
+	})
+
+	result, err := engine.Analyze(context.Background(), &analysis.AnalysisRequest{
+		Alert: &model.Alert{ID: 50, Name: "DiskFull", Level: "critical"},
+		Type:  analysis.AnalysisTypeClassification,
+	})
+	require.NoError(t, err)
+	require.Len(t, service.prompts, 1)
+	prompt := service.prompts[0]
+	assert.Contains(t, prompt, "[K1] 磁盘清理手册")
+	assert.Contains(t, prompt, "处理说明：扩容数据盘")
+	assert.Less(t, strings.Index(prompt, "[K2]"), strings.Index(prompt, "JSON Schema"))
+
+	require.Len(t, result.Citations, 2)
+	assert.False(t, result.Citations[0].Cited)
+	assert.True(t, result.Citations[1].Cited)
+	assert.Equal(t, []string{"alert:41"}, result.Metadata["knowledge_ids"])
+	assert.Equal(t, []string{"[K2]"}, result.Structured.Citations)
+}
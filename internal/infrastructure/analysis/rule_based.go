@@ -0,0 +1,124 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+)
+
+// BackendRules 规则分析，预算耗尽时代替模型
+const BackendRules = "rules"
+
+// ruleBasedConfidence 规则分析的置信度
+const ruleBasedConfidence = 0.3
+
+// categoryKeywords 按告警名称关键词分类，与网关的告警分类保持一致
+var categoryKeywords = []struct {
+	category string
+	keywords []string
+}{
+	{analysis.CategoryPerformance, []string{"cpu", "memory", "ram", "load", "latency"}},
+	{analysis.CategoryStorage, []string{"disk", "storage", "volume", "inode"}},
+	{analysis.CategoryNetwork, []string{"network", "connection", "packet", "dns"}},
+	{analysis.CategoryDatabase, []string{"database", "mysql", "postgres", "redis", "db"}},
+	{analysis.CategorySecurity, []string{"security", "login", "auth", "attack"}},
+	{analysis.CategoryApplication, []string{"service", "application", "http", "pod"}},
+}
+
+// categoryActions 各类别的通用处置建议
+var categoryActions = map[string]string{
+	analysis.CategoryPerformance: "检查资源使用率最高的进程并评估扩容",
+	analysis.CategoryStorage:     "清理日志与临时文件，必要时扩容磁盘",
+	analysis.CategoryNetwork:     "检查网络连通性、丢包与依赖服务状态",
+	analysis.CategoryDatabase:    "检查慢查询、连接数与主从状态",
+	analysis.CategorySecurity:    "核查访问来源并按安全预案处置",
+	analysis.CategoryApplication: "检查服务日志与最近的变更，必要时回滚",
+	analysis.CategoryGeneral:     "按告警内容人工排查",
+}
+
+// severityPriorities 级别对应的处理优先级
+var severityPriorities = map[string]string{
+	analysis.SeverityCritical: analysis.PriorityP0,
+	analysis.SeverityHigh:     analysis.PriorityP1,
+	analysis.SeverityMedium:   analysis.PriorityP2,
+	analysis.SeverityLow:      analysis.PriorityP3,
+	analysis.SeverityInfo:     analysis.PriorityP3,
+}
+
+// RuleBasedAIService 根据告警级别与名称生成分析结果，不调用模型
+// 输出包含所有分析类型 schema 要求的字段，可直接走结构化解析
+type RuleBasedAIService struct{}
+
+// NewRuleBasedAIService 创建规则分析服务
+func NewRuleBasedAIService() *RuleBasedAIService {
+	return &RuleBasedAIService{}
+}
+
+// Analyze 生成规则分析结果
+func (s *RuleBasedAIService) Analyze(ctx context.Context, prompt string, data interface{}) (*AIResponse, error) {
+	alert, ok := data.(*model.Alert)
+	if !ok || alert == nil {
+		return nil, fmt.Errorf("rule based analysis requires an alert")
+	}
+
+	severity := ruleSeverity(alert)
+	category := ruleCategory(alert)
+	action := categoryActions[category]
+	content, err := json.Marshal(map[string]interface{}{
+		"summary":    fmt.Sprintf("%s（规则分析，未调用模型）", alert.Title),
+		"severity":   severity,
+		"confidence": ruleBasedConfidence,
+		"category":   category,
+		"priority":   severityPriorities[severity],
+		"reason":     fmt.Sprintf("按告警级别 %s 与名称 %s 判定", alert.Level, alert.Name),
+		"root_causes": []map[string]interface{}{
+			{"description": "模型预算已耗尽，需人工确认根因", "likelihood": ruleBasedConfidence},
+		},
+		"impact": map[string]interface{}{"scope": "service"},
+		"actions": []map[string]interface{}{
+			{"title": action, "risk": "low"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AIResponse{Content: string(content), ModelUsed: BackendRules}, nil
+}
+
+// IsHealthy 规则分析始终可用
+func (s *RuleBasedAIService) IsHealthy() bool {
+	return true
+}
+
+// GetModelInfo 获取模型信息
+func (s *RuleBasedAIService) GetModelInfo() *ModelInfo {
+	return &ModelInfo{Name: BackendRules, Provider: BackendRules, Capabilities: []string{"fallback"}}
+}
+
+// ruleSeverity 告警级别对应的严重程度
+func ruleSeverity(alert *model.Alert) string {
+	level := strings.ToLower(alert.Level)
+	for _, severity := range analysis.Severities {
+		if level == severity {
+			return severity
+		}
+	}
+	return analysis.SeverityMedium
+}
+
+// ruleCategory 按告警名称关键词分类
+func ruleCategory(alert *model.Alert) string {
+	name := strings.ToLower(alert.Name + " " + alert.Title)
+	for _, rule := range categoryKeywords {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(name, keyword) {
+				return rule.category
+			}
+		}
+	}
+	return analysis.CategoryGeneral
+}
@@ -0,0 +1,128 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 内置模板使用的告警上下文
+const alertContextPrompt = `告警名称：{{alert_name}}
+告警级别：{{alert_level}}
+告警状态：{{alert_status}}
+告警来源：{{alert_source}}
+触发时间：{{alert_created_at}}
+告警内容：{{alert_content}}
+`
+
+// DefaultTemplates 内置分析模板，数据库中没有启用的模板时使用
+var DefaultTemplates = map[analysis.AnalysisType]*AnalysisTemplate{
+	analysis.AnalysisTypeRootCause: {
+		ID: "builtin-root-cause", Name: "根因分析", Type: analysis.AnalysisTypeRootCause, Version: "builtin",
+		Prompt: "请分析以下告警的可能根因，按可能性从高到低列出根因，并在 actions 中给出排查步骤。\n" + alertContextPrompt,
+	},
+	analysis.AnalysisTypeImpactAssess: {
+		ID: "builtin-impact", Name: "影响评估", Type: analysis.AnalysisTypeImpactAssess, Version: "builtin",
+		Prompt: "请评估以下告警对业务与下游系统的影响范围和严重程度，列出受影响的服务。\n" + alertContextPrompt,
+	},
+	analysis.AnalysisTypeSolution: {
+		ID: "builtin-solution", Name: "处理建议", Type: analysis.AnalysisTypeSolution, Version: "builtin",
+		Prompt: "请针对以下告警给出可执行的处理步骤，先止损再根治。\n" + alertContextPrompt,
+	},
+	analysis.AnalysisTypeClassification: {
+		ID: "builtin-classification", Name: "告警分类", Type: analysis.AnalysisTypeClassification, Version: "builtin",
+		Prompt: "请判断以下告警所属类别，在 category 中给出类别，在 reason 中给出依据。\n" + alertContextPrompt,
+	},
+	analysis.AnalysisTypePriority: {
+		ID: "builtin-priority", Name: "优先级评估", Type: analysis.AnalysisTypePriority, Version: "builtin",
+		Prompt: "请评估以下告警的处理优先级（P0~P3），在 priority 中给出优先级，在 reason 中给出依据。\n" + alertContextPrompt,
+	},
+}
+
+// GORMTemplateRepository 基于数据库的分析模板仓库
+type GORMTemplateRepository struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+// NewGORMTemplateRepository 创建分析模板仓库
+func NewGORMTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &GORMTemplateRepository{db: db, timeout: 5 * time.Second}
+}
+
+// GetTemplate 获取分析类型最新启用的模板，没有时使用内置模板
+func (r *GORMTemplateRepository) GetTemplate(analysisType analysis.AnalysisType) (*AnalysisTemplate, error) {
+	template, err := r.latest(r.db.Where("type = ?", analysisType))
+	if err != nil {
+		return nil, err
+	}
+	if template != nil {
+		return template, nil
+	}
+	if builtin, ok := DefaultTemplates[analysisType]; ok {
+		return builtin, nil
+	}
+	return nil, fmt.Errorf("no template for analysis type: %s", analysisType)
+}
+
+// GetDefaultTemplate 获取标记为默认的模板，没有时使用内置根因分析模板
+func (r *GORMTemplateRepository) GetDefaultTemplate() (*AnalysisTemplate, error) {
+	template, err := r.latest(r.db.Where("is_default = ?", true))
+	if err != nil {
+		return nil, err
+	}
+	if template != nil {
+		return template, nil
+	}
+	return DefaultTemplates[analysis.AnalysisTypeRootCause], nil
+}
+
+// latest 获取满足条件的最近更新的启用模板，不存在时返回 nil
+func (r *GORMTemplateRepository) latest(query *gorm.DB) (*AnalysisTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	var template AnalysisTemplate
+	err := query.WithContext(ctx).Where("enabled = ?", true).
+		Clauses(clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "updated_at"}, Desc: true}}}).
+		First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis template: %w", err)
+	}
+	return &template, nil
+}
+
+// StaticTemplateRepository 使用给定模板的仓库，未指定的分析类型使用内置模板，用于离线评测
+type StaticTemplateRepository struct {
+	templates map[analysis.AnalysisType]*AnalysisTemplate
+}
+
+// NewStaticTemplateRepository 创建固定模板仓库
+func NewStaticTemplateRepository(templates map[analysis.AnalysisType]*AnalysisTemplate) TemplateRepository {
+	return &StaticTemplateRepository{templates: templates}
+}
+
+// GetTemplate 获取分析类型的模板
+func (r *StaticTemplateRepository) GetTemplate(analysisType analysis.AnalysisType) (*AnalysisTemplate, error) {
+	if template, ok := r.templates[analysisType]; ok {
+		return template, nil
+	}
+	if builtin, ok := DefaultTemplates[analysisType]; ok {
+		return builtin, nil
+	}
+	return nil, fmt.Errorf("no template for analysis type: %s", analysisType)
+}
+
+// GetDefaultTemplate 获取根因分析模板
+func (r *StaticTemplateRepository) GetDefaultTemplate() (*AnalysisTemplate, error) {
+	return r.GetTemplate(analysis.AnalysisTypeRootCause)
+}
@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	usageTenantsKey = "analysis:usage:%s:tenants" // 单日有用量的租户集合
+	usageTenantKey  = "analysis:usage:%s:%s"      // 租户单日用量哈希
+	usageModelField = "model:"                    // 按模型统计的令牌字段前缀
+)
+
+// defaultUsageRetention 用量记录默认保留时长，覆盖一个自然月的查询
+const defaultUsageRetention = 35 * 24 * time.Hour
+
+// RedisUsageStore 基于 Redis 哈希的模型用量统计，多个进程共享同一份计数
+type RedisUsageStore struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+// NewRedisUsageStore 创建模型用量统计
+func NewRedisUsageStore(client *redis.Client, retention time.Duration) analysis.UsageStore {
+	if retention <= 0 {
+		retention = defaultUsageRetention
+	}
+	return &RedisUsageStore{client: client, retention: retention}
+}
+
+// Record 累加一次分析的用量
+func (s *RedisUsageStore) Record(ctx context.Context, day time.Time, event *analysis.UsageEvent) error {
+	date := day.Format(analysis.UsageDateLayout)
+	tenantKey := fmt.Sprintf(usageTenantKey, date, event.Tenant)
+	tenantsKey := fmt.Sprintf(usageTenantsKey, date)
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, tenantKey, "requests", 1)
+		if event.TotalTokens > 0 {
+			pipe.HIncrBy(ctx, tenantKey, "prompt_tokens", int64(event.PromptTokens))
+			pipe.HIncrBy(ctx, tenantKey, "completion_tokens", int64(event.CompletionTokens))
+			pipe.HIncrBy(ctx, tenantKey, "total_tokens", int64(event.TotalTokens))
+			pipe.HIncrBy(ctx, tenantKey, usageModelField+event.Model, int64(event.TotalTokens))
+		}
+		if event.CacheHit {
+			pipe.HIncrBy(ctx, tenantKey, "cache_hits", 1)
+		}
+		switch event.Mode {
+		case analysis.BudgetModeDowngrade:
+			pipe.HIncrBy(ctx, tenantKey, "downgrades", 1)
+		case analysis.BudgetModeFallback:
+			pipe.HIncrBy(ctx, tenantKey, "fallbacks", 1)
+		}
+		pipe.SAdd(ctx, tenantsKey, event.Tenant)
+		pipe.Expire(ctx, tenantKey, s.retention)
+		pipe.Expire(ctx, tenantsKey, s.retention)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record token usage: %w", err)
+	}
+	return nil
+}
+
+// Get 获取租户单日用量
+func (s *RedisUsageStore) Get(ctx context.Context, tenant string, day time.Time) (*analysis.TenantUsage, error) {
+	date := day.Format(analysis.UsageDateLayout)
+	fields, err := s.client.HGetAll(ctx, fmt.Sprintf(usageTenantKey, date, tenant)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token usage: %w", err)
+	}
+	return parseTenantUsage(tenant, date, fields), nil
+}
+
+// List 获取单日所有租户的用量
+func (s *RedisUsageStore) List(ctx context.Context, day time.Time) ([]*analysis.TenantUsage, error) {
+	date := day.Format(analysis.UsageDateLayout)
+	tenants, err := s.client.SMembers(ctx, fmt.Sprintf(usageTenantsKey, date)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage tenants: %w", err)
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(tenants))
+	for i, tenant := range tenants {
+		cmds[i] = pipe.HGetAll(ctx, fmt.Sprintf(usageTenantKey, date, tenant))
+	}
+	if len(tenants) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to list token usage: %w", err)
+		}
+	}
+
+	usages := make([]*analysis.TenantUsage, 0, len(tenants))
+	for i, tenant := range tenants {
+		usages = append(usages, parseTenantUsage(tenant, date, cmds[i].Val()))
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].TotalTokens != usages[j].TotalTokens {
+			return usages[i].TotalTokens > usages[j].TotalTokens
+		}
+		return usages[i].Tenant < usages[j].Tenant
+	})
+	return usages, nil
+}
+
+// parseTenantUsage 解析用量哈希
+func parseTenantUsage(tenant, date string, fields map[string]string) *analysis.TenantUsage {
+	usage := &analysis.TenantUsage{
+		Tenant: tenant,
+		Date:   date,
+		Models: make(map[string]int64),
+	}
+	for field, value := range fields {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch field {
+		case "prompt_tokens":
+			usage.PromptTokens = n
+		case "completion_tokens":
+			usage.CompletionTokens = n
+		case "total_tokens":
+			usage.TotalTokens = n
+		case "requests":
+			usage.Requests = n
+		case "cache_hits":
+			usage.CacheHits = n
+		case "downgrades":
+			usage.Downgrades = n
+		case "fallbacks":
+			usage.Fallbacks = n
+		default:
+			if model := strings.TrimPrefix(field, usageModelField); model != field {
+				usage.Models[model] = n
+			}
+		}
+	}
+	return usage
+}
@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"alert_agent/internal/domain/retention"
+)
+
+// LocalStorage 本地目录归档存储
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage 创建本地目录归档存储
+func NewLocalStorage(dir string) retention.Storage {
+	return &LocalStorage{dir: dir}
+}
+
+// Name 存储类型
+func (s *LocalStorage) Name() string {
+	return "local"
+}
+
+// Put 先写临时文件再重命名，避免留下不完整的归档文件
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// Get 读取归档文件
+func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return data, nil
+}
+
+// path 归档文件路径，拒绝跳出归档目录的 key
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid archive key: %s", key)
+	}
+	return filepath.Join(s.dir, cleaned), nil
+}
@@ -0,0 +1,231 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/domain/automation"
+
+	"github.com/google/uuid"
+)
+
+// maxResponseBytes 读取的 HTTP 响应体上限
+const maxResponseBytes = 64 * 1024
+
+// containerCleanupTimeout 脚本超时后删除容器的超时时间
+const containerCleanupTimeout = 10 * time.Second
+
+// HTTPExecutor 执行 HTTP 调用，非 2xx 响应视为失败
+type HTTPExecutor struct {
+	client *http.Client
+}
+
+// NewHTTPExecutor 创建 HTTP 执行器，超时由操作的 ctx 控制
+func NewHTTPExecutor() *HTTPExecutor {
+	return &HTTPExecutor{client: &http.Client{}}
+}
+
+// Execute 发送请求并返回响应体
+func (e *HTTPExecutor) Execute(ctx context.Context, action *automation.Action) (*automation.ActionResult, error) {
+	method := action.HTTP.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	var body io.Reader
+	if action.HTTP.Body != "" {
+		body = strings.NewReader(action.HTTP.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, action.HTTP.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range action.HTTP.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	result := &automation.ActionResult{Output: fmt.Sprintf("%d %s", resp.StatusCode, data)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// SandboxConfig 脚本沙箱容器配置
+type SandboxConfig struct {
+	Runtime string // 容器运行时命令，如 docker、podman
+	Image   string // 默认镜像
+	Memory  string // 内存上限，如 256m
+	CPUs    string // CPU 上限，如 0.5
+	Pids    int    // 进程数上限
+}
+
+// CommandRunner 执行命令，测试时可替换
+type CommandRunner func(ctx context.Context, name string, args []string, stdin string) (string, error)
+
+// ScriptExecutor 在一次性容器中执行脚本
+// 容器无网络、根文件系统只读、移除所有 capability，只有 /tmp 可写
+type ScriptExecutor struct {
+	config SandboxConfig
+	run    CommandRunner
+}
+
+// NewScriptExecutor 创建脚本执行器
+func NewScriptExecutor(config SandboxConfig) *ScriptExecutor {
+	return &ScriptExecutor{config: config, run: runCommand}
+}
+
+// Execute 通过标准输入把脚本交给容器内的 sh 执行
+// 超时或取消时终止的只是运行时命令本身，容器需要按名称强制删除
+func (e *ScriptExecutor) Execute(ctx context.Context, action *automation.Action) (*automation.ActionResult, error) {
+	name := containerName(ctx)
+	output, err := e.run(ctx, e.config.Runtime, e.args(name, action.Script), action.Script.Script)
+	if ctx.Err() != nil {
+		e.removeContainer(name)
+	}
+	result := &automation.ActionResult{Output: output}
+	if err != nil {
+		return result, fmt.Errorf("script failed: %w", err)
+	}
+	return result, nil
+}
+
+// removeContainer 强制删除容器，运行中的容器会先被终止
+func (e *ScriptExecutor) removeContainer(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), containerCleanupTimeout)
+	defer cancel()
+	// 容器已随 --rm 退出时删除会失败，忽略即可
+	_, _ = e.run(ctx, e.config.Runtime, []string{"rm", "-f", name}, "")
+}
+
+// containerName 按操作日志ID命名容器，没有时生成随机名称
+func containerName(ctx context.Context) string {
+	id := automation.ActionIDFromContext(ctx)
+	if id == "" {
+		id = uuid.New().String()
+	}
+	return "alertagent-action-" + id
+}
+
+// args 生成容器运行参数
+func (e *ScriptExecutor) args(name string, script *automation.ScriptAction) []string {
+	image := script.Image
+	if image == "" {
+		image = e.config.Image
+	}
+	args := []string{"run", "--rm", "-i",
+		"--name", name,
+		"--network", "none",
+		"--read-only",
+		"--tmpfs", "/tmp:rw,noexec,nosuid,size=16m",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--user", "65534:65534",
+	}
+	if e.config.Memory != "" {
+		args = append(args, "--memory", e.config.Memory)
+	}
+	if e.config.CPUs != "" {
+		args = append(args, "--cpus", e.config.CPUs)
+	}
+	if e.config.Pids > 0 {
+		args = append(args, "--pids-limit", fmt.Sprint(e.config.Pids))
+	}
+	keys := make([]string, 0, len(script.Env))
+	for k := range script.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--env", k+"="+script.Env[k])
+	}
+	return append(args, image, "sh", "-s")
+}
+
+// runCommand 执行命令并合并标准输出与标准错误
+func runCommand(ctx context.Context, name string, args []string, stdin string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	return output.String(), err
+}
+
+// KubernetesExecutor 通过 Kubernetes 客户端执行操作
+type KubernetesExecutor struct {
+	client automation.KubernetesClient
+}
+
+// NewKubernetesExecutor 创建 Kubernetes 执行器
+func NewKubernetesExecutor(client automation.KubernetesClient) *KubernetesExecutor {
+	return &KubernetesExecutor{client: client}
+}
+
+// Execute 执行 Kubernetes 操作
+func (e *KubernetesExecutor) Execute(ctx context.Context, action *automation.Action) (*automation.ActionResult, error) {
+	k := action.Kubernetes
+	switch k.Operation {
+	case automation.KubernetesRestartDeployment:
+		if err := e.client.RestartDeployment(ctx, k.Namespace, k.Name); err != nil {
+			return nil, err
+		}
+		return &automation.ActionResult{Output: fmt.Sprintf("deployment %s/%s restarted", k.Namespace, k.Name)}, nil
+	case automation.KubernetesScaleDeployment:
+		previous, err := e.client.ScaleDeployment(ctx, k.Namespace, k.Name, *k.Replicas)
+		if err != nil {
+			return nil, err
+		}
+		return &automation.ActionResult{Output: fmt.Sprintf("deployment %s/%s scaled from %d to %d", k.Namespace, k.Name, previous, *k.Replicas)}, nil
+	case automation.KubernetesDeletePod:
+		if err := e.client.DeletePod(ctx, k.Namespace, k.Name); err != nil {
+			return nil, err
+		}
+		return &automation.ActionResult{Output: fmt.Sprintf("pod %s/%s deleted", k.Namespace, k.Name)}, nil
+	}
+	return nil, fmt.Errorf("unsupported kubernetes operation %q", k.Operation)
+}
+
+// N8NExecutor 触发 n8n 工作流
+type N8NExecutor struct {
+	client analysis.N8NClient
+}
+
+// NewN8NExecutor 创建 n8n 执行器
+func NewN8NExecutor(client analysis.N8NClient) *N8NExecutor {
+	return &N8NExecutor{client: client}
+}
+
+// Execute 触发工作流，不等待工作流完成
+func (e *N8NExecutor) Execute(ctx context.Context, action *automation.Action) (*automation.ActionResult, error) {
+	input := make(map[string]interface{}, len(action.N8N.Data))
+	for k, v := range action.N8N.Data {
+		input[k] = v
+	}
+	execution, err := e.client.TriggerWorkflow(ctx, &analysis.N8NWorkflowTriggerRequest{
+		WorkflowID: action.N8N.WorkflowID,
+		InputData:  input,
+		Metadata:   map[string]interface{}{"source": "automation"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to trigger n8n workflow: %w", err)
+	}
+	return &automation.ActionResult{Output: fmt.Sprintf("n8n execution %s %s", execution.ID, execution.Status)}, nil
+}
@@ -0,0 +1,108 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/automation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptExecutor_Sandbox(t *testing.T) {
+	var gotName, gotStdin string
+	var gotArgs []string
+	executor := NewScriptExecutor(SandboxConfig{Runtime: "docker", Image: "alpine:3.19", Memory: "128m", Pids: 64})
+	executor.run = func(ctx context.Context, name string, args []string, stdin string) (string, error) {
+		gotName, gotArgs, gotStdin = name, args, stdin
+		return "done\n", nil
+	}
+
+	result, err := executor.Execute(context.Background(), &automation.Action{
+		Type:   automation.ActionScript,
+		Script: &automation.ScriptAction{Script: "echo $TARGET", Env: map[string]string{"TARGET": "api"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "done\n", result.Output)
+	assert.Equal(t, "docker", gotName)
+	assert.Equal(t, "echo $TARGET", gotStdin)
+	assert.Subset(t, gotArgs, []string{"--network", "none", "--read-only", "--cap-drop", "ALL", "--memory", "128m", "--pids-limit", "64", "--env", "TARGET=api"})
+	assert.Equal(t, []string{"alpine:3.19", "sh", "-s"}, gotArgs[len(gotArgs)-3:])
+}
+
+func TestScriptExecutor_RemovesContainerOnTimeout(t *testing.T) {
+	var calls [][]string
+	executor := NewScriptExecutor(SandboxConfig{Runtime: "docker", Image: "alpine:3.19"})
+	executor.run = func(ctx context.Context, name string, args []string, stdin string) (string, error) {
+		calls = append(calls, args)
+		if args[0] == "run" {
+			// 模拟脚本一直运行，直到超时后运行时命令被终止
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		assert.NoError(t, ctx.Err())
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(automation.WithActionID(context.Background(), "action-1"), 20*time.Millisecond)
+	defer cancel()
+	_, err := executor.Execute(ctx, &automation.Action{
+		Type:   automation.ActionScript,
+		Script: &automation.ScriptAction{Script: "sleep 3600"},
+	})
+	require.Error(t, err)
+
+	// 容器按操作ID命名，超时后使用未取消的上下文强制删除
+	require.Len(t, calls, 2)
+	assert.Subset(t, calls[0], []string{"--name", "alertagent-action-action-1"})
+	assert.Equal(t, []string{"rm", "-f", "alertagent-action-action-1"}, calls[1])
+}
+
+func TestKubernetesRESTClient(t *testing.T) {
+	var requests []string
+	var patches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		if r.Method == http.MethodPatch {
+			assert.Equal(t, "application/merge-patch+json", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			var patch map[string]interface{}
+			require.NoError(t, json.Unmarshal(body, &patch))
+			patches = append(patches, patch)
+		}
+		if r.URL.Path == "/api/v1/namespaces/shop/pods/missing" {
+			http.Error(w, `{"reason":"NotFound"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"spec":{"replicas":2}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewKubernetesRESTClient(KubernetesConfig{APIServer: server.URL, Token: "token"})
+	require.NoError(t, err)
+	client.now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	previous, err := client.ScaleDeployment(ctx, "shop", "api", 4)
+	require.NoError(t, err)
+	assert.Equal(t, 2, previous)
+	require.NoError(t, client.RestartDeployment(ctx, "shop", "api"))
+	assert.Error(t, client.DeletePod(ctx, "shop", "missing"))
+
+	assert.Equal(t, []string{
+		"GET /apis/apps/v1/namespaces/shop/deployments/api/scale",
+		"PATCH /apis/apps/v1/namespaces/shop/deployments/api/scale",
+		"PATCH /apis/apps/v1/namespaces/shop/deployments/api",
+		"DELETE /api/v1/namespaces/shop/pods/missing",
+	}, requests)
+	assert.Equal(t, float64(4), patches[0]["spec"].(map[string]interface{})["replicas"])
+	annotations := patches[1]["spec"].(map[string]interface{})["template"].(map[string]interface{})["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})
+	assert.Equal(t, "2024-05-01T10:00:00Z", annotations["kubectl.kubernetes.io/restartedAt"])
+}
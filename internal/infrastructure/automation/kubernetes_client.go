@@ -0,0 +1,162 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// 集群内运行时的服务账号凭据
+const (
+	inClusterTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// KubernetesConfig Kubernetes API 连接配置
+type KubernetesConfig struct {
+	APIServer          string // 为空时使用集群内地址与服务账号
+	Token              string
+	CAFile             string
+	InsecureSkipVerify bool
+}
+
+// KubernetesRESTClient 通过 Kubernetes REST API 执行操作，只依赖标准库
+type KubernetesRESTClient struct {
+	server string
+	token  string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewKubernetesRESTClient 创建 Kubernetes 客户端，未配置地址时读取集群内服务账号
+func NewKubernetesRESTClient(config KubernetesConfig) (*KubernetesRESTClient, error) {
+	server, token, caFile := config.APIServer, config.Token, config.CAFile
+	if server == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("kubernetes api server is not configured and not running in cluster")
+		}
+		server = "https://" + host + ":" + port
+		if token == "" {
+			data, err := os.ReadFile(inClusterTokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read service account token: %w", err)
+			}
+			token = strings.TrimSpace(string(data))
+		}
+		if caFile == "" {
+			caFile = inClusterCAFile
+		}
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kubernetes ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid kubernetes ca in %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &KubernetesRESTClient{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		client: &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		now:    time.Now,
+	}, nil
+}
+
+// RestartDeployment 更新 Pod 模板注解触发滚动重启，与 kubectl rollout restart 一致
+func (c *KubernetesRESTClient) RestartDeployment(ctx context.Context, namespace, name string) error {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						"kubectl.kubernetes.io/restartedAt": c.now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	}
+	return c.do(ctx, http.MethodPatch, deploymentPath(namespace, name), patch, nil)
+}
+
+// ScaleDeployment 通过 scale 子资源调整副本数，返回调整前的副本数
+func (c *KubernetesRESTClient) ScaleDeployment(ctx context.Context, namespace, name string, replicas int) (int, error) {
+	var scale struct {
+		Spec struct {
+			Replicas int `json:"replicas"`
+		} `json:"spec"`
+	}
+	path := deploymentPath(namespace, name) + "/scale"
+	if err := c.do(ctx, http.MethodGet, path, nil, &scale); err != nil {
+		return 0, err
+	}
+	patch := map[string]interface{}{"spec": map[string]int{"replicas": replicas}}
+	if err := c.do(ctx, http.MethodPatch, path, patch, nil); err != nil {
+		return 0, err
+	}
+	return scale.Spec.Replicas, nil
+}
+
+// DeletePod 删除 Pod，由控制器重新创建
+func (c *KubernetesRESTClient) DeletePod(ctx context.Context, namespace, name string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", url.PathEscape(namespace), url.PathEscape(name)), nil, nil)
+}
+
+// do 发送请求，PATCH 使用 merge patch
+func (c *KubernetesRESTClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if method == http.MethodPatch {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kubernetes request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("kubernetes %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode kubernetes response: %w", err)
+		}
+	}
+	return nil
+}
+
+func deploymentPath(namespace, name string) string {
+	return fmt.Sprintf("/apis/apps/v1/namespaces/%s/deployments/%s", url.PathEscape(namespace), url.PathEscape(name))
+}
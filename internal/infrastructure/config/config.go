@@ -15,6 +15,17 @@ type Config struct {
 	Redis    RedisConfig    `json:"redis"`
 	Logging  LoggingConfig  `json:"logging"`
 	Security SecurityConfig `json:"security"`
+	Gateway  GatewayConfig  `json:"gateway"`
+	RuleEngine     RuleEngineConfig     `json:"rule_engine"`
+	Postmortem     PostmortemConfig     `json:"postmortem"`
+	Analytics      AnalyticsConfig      `json:"analytics"`
+	AlertBulk      AlertBulkConfig      `json:"alert_bulk"`
+	Retention      RetentionConfig      `json:"retention"`
+	AI             AIConfig             `json:"ai"`
+	AnalysisQueue  AnalysisQueueConfig  `json:"analysis_queue"`
+	AnalysisWorker AnalysisWorkerConfig `json:"analysis_worker"`
+	RAG            RAGConfig            `json:"rag"`
+	Automation     AutomationConfig     `json:"automation"`
 }
 
 // AppConfig 应用配置
@@ -58,6 +69,207 @@ type RedisConfig struct {
 	DialTimeout  int    `json:"dial_timeout"`
 }
 
+// GatewayConfig 告警网关配置
+type GatewayConfig struct {
+	FlapWindow            int  `json:"flap_window"`             // 抖动检测窗口（秒）
+	FlapHistorySize       int  `json:"flap_history_size"`       // 参与计算的最大状态数
+	FlapMinSamples        int  `json:"flap_min_samples"`        // 判定抖动所需的最少状态数
+	FlapHighThreshold     int  `json:"flap_high_threshold"`     // 开始抖动的状态变化百分比
+	FlapLowThreshold      int  `json:"flap_low_threshold"`      // 结束抖动的状态变化百分比
+	FlapHoldNotifications bool `json:"flap_hold_notifications"` // 抖动期间暂停通知
+	FlapReleaseInterval   int  `json:"flap_release_interval"`   // 重新评估抖动状态、补发暂停通知的间隔（秒）
+
+	PipelineConsumer      string `json:"pipeline_consumer"`       // 消费者名称，默认为主机名与进程号
+	PipelineConcurrency   int    `json:"pipeline_concurrency"`    // 并发处理数
+	PipelineMaxDeliveries int    `json:"pipeline_max_deliveries"` // 超过投递次数转入死信
+	PipelineClaimMinIdle  int    `json:"pipeline_claim_min_idle"` // 待确认消息被接管的空闲时长（秒）
+	MetricsPort           int    `json:"metrics_port"`            // worker 指标端口
+
+	SimilarityLabelWeight     int    `json:"similarity_label_weight"`     // 标签相似度权重（百分比）
+	SimilarityTextWeight      int    `json:"similarity_text_weight"`      // 文本相似度权重（百分比）
+	SimilarityTimeWeight      int    `json:"similarity_time_weight"`      // 时间接近度权重（百分比）
+	SimilarityEmbeddingWeight int    `json:"similarity_embedding_weight"` // 向量相似度权重（百分比），为 0 时不调用 Ollama
+	SimilarityTimeHalfLife    int    `json:"similarity_time_half_life"`   // 时间接近度半衰期（秒）
+	OllamaEndpoint            string `json:"ollama_endpoint"`             // Ollama 服务地址
+	OllamaEmbeddingModel      string `json:"ollama_embedding_model"`      // 向量模型
+	OllamaTimeout             int    `json:"ollama_timeout"`              // 请求超时（秒）
+
+	TopologyFile          string `json:"topology_file"`           // 服务依赖拓扑 YAML 文件，为空时仅从告警标签学习
+	TopologyWindow        int    `json:"topology_window"`         // 跨服务关联时间窗口（秒）
+	TopologyServiceLabel  string `json:"topology_service_label"`  // 标识服务的标签
+	TopologyUpstreamLabel string `json:"topology_upstream_label"` // 标识上游依赖的标签
+	TopologyMaxAlerts     int    `json:"topology_max_alerts"`     // 每个关联事件保留的告警数上限
+
+	// 指标快照配置
+	SnapshotEnabled        bool   `json:"snapshot_enabled"`         // 丰富阶段是否采集指标快照
+	SnapshotLookback       int    `json:"snapshot_lookback"`        // 触发时间之前的查询范围（秒）
+	SnapshotLookahead      int    `json:"snapshot_lookahead"`       // 触发时间之后的查询范围（秒）
+	SnapshotMaxPoints      int    `json:"snapshot_max_points"`      // 每条序列保留的数据点数
+	SnapshotMaxRelated     int    `json:"snapshot_max_related"`     // 关联序列最大数量
+	SnapshotRelatedQueries string `json:"snapshot_related_queries"` // 关联查询模板，多个以分号分隔
+	SnapshotTimeout        int    `json:"snapshot_timeout"`         // 采集超时（秒）
+
+	// 告警分类配置
+	ClassifierEnabled             bool    `json:"classifier_enabled"`              // 智能路由是否使用训练的分类模型
+	ClassifierConfidenceThreshold float64 `json:"classifier_confidence_threshold"` // 低于该置信度时调用 LLM 兜底
+	ClassifierLLMFallback         bool    `json:"classifier_llm_fallback"`         // 是否启用 LLM 兜底，经过分析引擎的缓存、预算与并发限制
+	ClassifierLLMTimeout          int     `json:"classifier_llm_timeout"`          // LLM 兜底超时（秒）
+	ClassifierLLMConcurrency      int     `json:"classifier_llm_concurrency"`      // 同时进行的 LLM 兜底上限，已满时使用本地分类
+	ClassifierReloadInterval      int     `json:"classifier_reload_interval"`      // 检查启用模型版本的间隔（秒）
+}
+
+// RuleEngineConfig 规则评估引擎配置
+type RuleEngineConfig struct {
+	Enabled  bool   `json:"enabled"`   // worker 是否评估规则
+	Owner    string `json:"owner"`     // 评估者标识，默认为主机名与进程号
+	Interval int    `json:"interval"`  // 评估间隔（秒）
+	LeaseTTL int    `json:"lease_ttl"` // 规则评估租约时长（秒），应大于评估间隔
+	Timeout  int    `json:"timeout"`   // 单条规则评估超时（秒）
+}
+
+// PostmortemConfig 复盘报告配置
+type PostmortemConfig struct {
+	TemplateFile  string `json:"template_file"`  // 自定义 Markdown 模板文件，为空使用内置模板
+	ServiceLabels string `json:"service_labels"` // 标识受影响服务的标签，逗号分隔，按顺序取第一个存在的
+}
+
+// AnalyticsConfig 告警分析配置
+type AnalyticsConfig struct {
+	Enabled        bool   `json:"enabled"`           // worker 是否刷新小时聚合
+	Owner          string `json:"owner"`             // 刷新者标识，默认为主机名与进程号
+	Interval       int    `json:"interval"`          // 刷新间隔（秒）
+	Lookback       int    `json:"lookback"`          // 每次重新聚合的最近时间窗口（秒）
+	Backfill       int    `json:"backfill"`          // 首次运行时回填的历史时长（秒）
+	MaxHoursPerRun int    `json:"max_hours_per_run"` // 单次刷新最多聚合的小时数
+	LeaseTTL       int    `json:"lease_ttl"`         // 刷新租约时长（秒）
+	ServiceLabels  string `json:"service_labels"`    // 标识服务的标签，逗号分隔
+	TeamLabels     string `json:"team_labels"`       // 标识团队的标签，逗号分隔
+	InstanceLabels string `json:"instance_labels"`   // 标识实例的标签，逗号分隔
+	Timezone       string `json:"timezone"`          // 热力图与班次使用的时区，为空使用本地时区
+	ShiftHours     int    `json:"shift_hours"`       // 值班班次时长（小时）
+	ShiftStartHour int    `json:"shift_start_hour"`  // 每天第一个班次的开始时间（小时）
+}
+
+// AlertBulkConfig 告警批量操作配置
+type AlertBulkConfig struct {
+	MaxItems int `json:"max_items"` // 单次批量操作最多处理的告警数
+}
+
+// RetentionConfig 数据保留与归档配置
+type RetentionConfig struct {
+	Enabled              bool          `json:"enabled"`                // worker 是否归档并删除过期数据
+	Owner                string        `json:"owner"`                  // 归档者标识，默认为主机名与进程号
+	Interval             int           `json:"interval"`               // 归档间隔（秒）
+	BatchSize            int           `json:"batch_size"`             // 每批扫描的记录数
+	MaxBatchesPerRun     int           `json:"max_batches_per_run"`    // 每张表单次最多处理的批次数
+	LeaseTTL             int           `json:"lease_ttl"`              // 归档租约时长（秒）
+	Policies             string        `json:"policies"`               // 保留策略 table:status:severity:days，逗号分隔
+	PartitionMonthsAhead int           `json:"partition_months_ahead"` // 告警表提前创建的月分区数
+	Storage              ArchiveConfig `json:"storage"`
+}
+
+// ArchiveConfig 归档文件存储配置
+type ArchiveConfig struct {
+	Backend     string `json:"backend"`     // local 或 s3
+	Dir         string `json:"dir"`         // 本地归档目录
+	S3Endpoint  string `json:"s3_endpoint"` // S3 兼容存储地址，如 MinIO http://localhost:9000
+	S3Bucket    string `json:"s3_bucket"`
+	S3Region    string `json:"s3_region"`
+	S3AccessKey string `json:"-"`
+	S3SecretKey string `json:"-"`
+	S3Prefix    string `json:"s3_prefix"` // 对象前缀
+}
+
+// AIConfig 告警分析模型配置
+type AIConfig struct {
+	DefaultBackend  string  `json:"default_backend"`   // 默认后端 ollama、openai 或 dify
+	TypeBackends    string  `json:"type_backends"`     // 按分析类型指定后端 type:backend，逗号分隔
+	Timeout         int     `json:"timeout"`           // 单次分析超时（秒）
+	MaxRetries      int     `json:"max_retries"`       // 失败重试次数
+	RepairAttempts  int     `json:"repair_attempts"`   // 输出不符合 schema 时要求模型修正的次数
+	MaxPromptLength int     `json:"max_prompt_length"` // 提示最大长度，超出截断
+	Temperature     float64 `json:"temperature"`       // 采样温度
+
+	CacheEnabled       bool    `json:"cache_enabled"`        // 是否缓存通过校验的模型响应
+	CacheTTL           int     `json:"cache_ttl"`            // 响应缓存时长（秒）
+	DailyTokenBudget   int64   `json:"daily_token_budget"`   // 每个租户每日令牌预算，0 表示不限制
+	TenantTokenBudgets string  `json:"tenant_token_budgets"` // 按租户指定每日预算 tenant:tokens，逗号分隔
+	DowngradeRatio     float64 `json:"downgrade_ratio"`      // 用量达到预算的该比例后切换到低成本模型
+	DowngradeBackend   string  `json:"downgrade_backend"`    // 低成本模型后端，为空时不降级
+	TenantLabel        string  `json:"tenant_label"`         // 告警标签中标识租户的标签名
+	FeedbackWindow     int     `json:"feedback_window"`      // 按反馈计算准确率的时间窗口（秒）
+
+	OllamaEndpoint string `json:"ollama_endpoint"` // Ollama 服务地址
+	OllamaModel    string `json:"ollama_model"`    // Ollama 对话模型
+
+	OpenAIBaseURL string `json:"openai_base_url"` // OpenAI 兼容服务地址，包含 /v1，如 vLLM、DeepSeek、Qwen
+	OpenAIAPIKey  string `json:"-"`
+	OpenAIModel   string `json:"openai_model"`
+
+	DifyBaseURL string `json:"dify_base_url"` // Dify 服务地址
+	DifyAPIKey  string `json:"-"`
+}
+
+// AnalysisQueueConfig 分析任务队列配置
+type AnalysisQueueConfig struct {
+	VisibilityTimeout int  `json:"visibility_timeout"` // 出队任务租约时长（秒），worker 每三分之一时长续约
+	MaxAttempts       int  `json:"max_attempts"`       // 最大投递次数，超过后转入死信
+	ReaperEnabled     bool `json:"reaper_enabled"`     // worker 是否回收过期租约
+	ReapInterval      int  `json:"reap_interval"`      // 回收过期租约的间隔（秒）
+	IdempotencyTTL    int  `json:"idempotency_ttl"`    // 同一告警同一分析类型的去重时长（秒）
+}
+
+// AnalysisWorkerConfig 分析工作器自动扩缩容配置
+type AnalysisWorkerConfig struct {
+	Enabled           bool   `json:"enabled"`             // worker 是否运行分析工作器
+	MinWorkers        int    `json:"min_workers"`         // 最少工作器数
+	MaxWorkers        int    `json:"max_workers"`         // 最多工作器数
+	TasksPerWorker    int    `json:"tasks_per_worker"`    // 每个工作器承担的积压任务数
+	MaxTaskAge        int    `json:"max_task_age"`        // 最老任务等待超过该时长（秒）时增加工作器
+	ScaleInterval     int    `json:"scale_interval"`      // 检查队列的间隔（秒）
+	ScaleUpCooldown   int    `json:"scale_up_cooldown"`   // 两次扩容的最短间隔（秒）
+	ScaleDownCooldown int    `json:"scale_down_cooldown"` // 调整后到缩容的最短间隔（秒）
+	ModelConcurrency  string `json:"model_concurrency"`   // 每个模型的最大并发 model:limit，逗号分隔
+}
+
+// RAGConfig 检索增强分析配置
+type RAGConfig struct {
+	Enabled        bool    `json:"enabled"`         // 是否在分析提示中注入知识库与历史告警
+	TopK           int     `json:"top_k"`           // 注入的参考资料数量
+	MinScore       float64 `json:"min_score"`       // 最低相似度
+	SnippetLength  int     `json:"snippet_length"`  // 每条参考资料的最大字符数
+	SyncInterval   int     `json:"sync_interval"`   // 索引同步间隔（秒）
+	SnapshotPath   string  `json:"snapshot_path"`   // 索引快照文件
+	EmbeddingModel string  `json:"embedding_model"` // Ollama 向量模型
+}
+
+// AutomationConfig 运行手册自动化配置
+type AutomationConfig struct {
+	Enabled         bool   `json:"enabled"`           // 是否按告警触发运行手册，worker 是否执行
+	DryRun          bool   `json:"dry_run"`           // 全局试运行，只记录将执行的操作
+	RiskyActions    string `json:"risky_actions"`     // 需要审批的操作类型，逗号分隔
+	ActionTimeout   int    `json:"action_timeout"`    // 单个操作的默认超时（秒）
+	ApprovalTimeout int    `json:"approval_timeout"`  // 超时未审批的执行过期（秒）
+	RunningGrace    int    `json:"running_grace"`     // 执行超过所有操作超时之和再加该时长仍未结束时标记为失败（秒）
+	PollInterval    int    `json:"poll_interval"`     // worker 领取执行的间隔（秒）
+	Cooldown        int    `json:"cooldown"`          // 同一告警再次触发运行手册的默认间隔（秒）
+	CallbackBaseURL string `json:"callback_base_url"` // 聊天审批链接使用的服务地址
+	ApprovalSecret  string `json:"-"`                 // 聊天审批链接签名密钥，为空时只能通过 API 审批
+
+	SandboxRuntime string `json:"sandbox_runtime"` // 执行脚本的容器运行时命令
+	SandboxImage   string `json:"sandbox_image"`   // 脚本默认镜像
+	SandboxMemory  string `json:"sandbox_memory"`  // 脚本容器内存上限
+	SandboxCPUs    string `json:"sandbox_cpus"`    // 脚本容器 CPU 上限
+	SandboxPids    int    `json:"sandbox_pids"`    // 脚本容器进程数上限
+
+	KubernetesAPIServer string `json:"kubernetes_api_server"` // 为空时使用集群内服务账号
+	KubernetesToken     string `json:"-"`
+	KubernetesCAFile    string `json:"kubernetes_ca_file"`
+
+	N8NBaseURL string `json:"n8n_base_url"` // n8n 服务地址，为空时不支持 n8n 操作
+	N8NAPIKey  string `json:"-"`
+}
+
 // LoggingConfig 日志配置
 type LoggingConfig struct {
 	Level      string `json:"level"`
@@ -164,6 +376,168 @@ func Load() (*Config, error) {
 			MaxRetries:   getEnvInt("REDIS_MAX_RETRIES", 3),
 			DialTimeout:  getEnvInt("REDIS_DIAL_TIMEOUT", 5),
 		},
+		Gateway: GatewayConfig{
+			FlapWindow:            getEnvInt("GATEWAY_FLAP_WINDOW", 3600),
+			FlapHistorySize:       getEnvInt("GATEWAY_FLAP_HISTORY_SIZE", 21),
+			FlapMinSamples:        getEnvInt("GATEWAY_FLAP_MIN_SAMPLES", 5),
+			FlapHighThreshold:     getEnvInt("GATEWAY_FLAP_HIGH_THRESHOLD", 50),
+			FlapLowThreshold:      getEnvInt("GATEWAY_FLAP_LOW_THRESHOLD", 25),
+			FlapHoldNotifications: getEnvBool("GATEWAY_FLAP_HOLD_NOTIFICATIONS", true),
+			FlapReleaseInterval:   getEnvInt("GATEWAY_FLAP_RELEASE_INTERVAL", 60),
+			PipelineConsumer:      getEnv("GATEWAY_PIPELINE_CONSUMER", ""),
+			PipelineConcurrency:   getEnvInt("GATEWAY_PIPELINE_CONCURRENCY", 4),
+			PipelineMaxDeliveries: getEnvInt("GATEWAY_PIPELINE_MAX_DELIVERIES", 5),
+			PipelineClaimMinIdle:  getEnvInt("GATEWAY_PIPELINE_CLAIM_MIN_IDLE", 60),
+			MetricsPort:           getEnvInt("GATEWAY_METRICS_PORT", 9091),
+			SimilarityLabelWeight:     getEnvInt("GATEWAY_SIMILARITY_LABEL_WEIGHT", 45),
+			SimilarityTextWeight:      getEnvInt("GATEWAY_SIMILARITY_TEXT_WEIGHT", 35),
+			SimilarityTimeWeight:      getEnvInt("GATEWAY_SIMILARITY_TIME_WEIGHT", 20),
+			SimilarityEmbeddingWeight: getEnvInt("GATEWAY_SIMILARITY_EMBEDDING_WEIGHT", 0),
+			SimilarityTimeHalfLife:    getEnvInt("GATEWAY_SIMILARITY_TIME_HALF_LIFE", 1800),
+			OllamaEndpoint:            getEnv("OLLAMA_ENDPOINT", "http://localhost:11434"),
+			OllamaEmbeddingModel:      getEnv("OLLAMA_EMBEDDING_MODEL", "nomic-embed-text"),
+			OllamaTimeout:             getEnvInt("OLLAMA_TIMEOUT", 10),
+			TopologyFile:              getEnv("GATEWAY_TOPOLOGY_FILE", ""),
+			TopologyWindow:            getEnvInt("GATEWAY_TOPOLOGY_WINDOW", 600),
+			TopologyServiceLabel:      getEnv("GATEWAY_TOPOLOGY_SERVICE_LABEL", "service"),
+			TopologyUpstreamLabel:     getEnv("GATEWAY_TOPOLOGY_UPSTREAM_LABEL", "upstream"),
+			TopologyMaxAlerts:         getEnvInt("GATEWAY_TOPOLOGY_MAX_ALERTS", 100),
+			SnapshotEnabled:           getEnvBool("GATEWAY_SNAPSHOT_ENABLED", true),
+			SnapshotLookback:          getEnvInt("GATEWAY_SNAPSHOT_LOOKBACK", 1800),
+			SnapshotLookahead:         getEnvInt("GATEWAY_SNAPSHOT_LOOKAHEAD", 300),
+			SnapshotMaxPoints:         getEnvInt("GATEWAY_SNAPSHOT_MAX_POINTS", 60),
+			SnapshotMaxRelated:        getEnvInt("GATEWAY_SNAPSHOT_MAX_RELATED", 3),
+			SnapshotRelatedQueries:    getEnv("GATEWAY_SNAPSHOT_RELATED_QUERIES", ""),
+			SnapshotTimeout:           getEnvInt("GATEWAY_SNAPSHOT_TIMEOUT", 10),
+			ClassifierEnabled:             getEnvBool("GATEWAY_CLASSIFIER_ENABLED", true),
+			ClassifierConfidenceThreshold: getEnvFloat("GATEWAY_CLASSIFIER_CONFIDENCE_THRESHOLD", 0.6),
+			ClassifierLLMFallback:         getEnvBool("GATEWAY_CLASSIFIER_LLM_FALLBACK", false),
+			ClassifierLLMTimeout:          getEnvInt("GATEWAY_CLASSIFIER_LLM_TIMEOUT", 5),
+			ClassifierLLMConcurrency:      getEnvInt("GATEWAY_CLASSIFIER_LLM_CONCURRENCY", 2),
+			ClassifierReloadInterval:      getEnvInt("GATEWAY_CLASSIFIER_RELOAD_INTERVAL", 300),
+		},
+		RuleEngine: RuleEngineConfig{
+			Enabled:  getEnvBool("RULE_ENGINE_ENABLED", true),
+			Owner:    getEnv("RULE_ENGINE_OWNER", ""),
+			Interval: getEnvInt("RULE_ENGINE_INTERVAL", 30),
+			LeaseTTL: getEnvInt("RULE_ENGINE_LEASE_TTL", 90),
+			Timeout:  getEnvInt("RULE_ENGINE_TIMEOUT", 20),
+		},
+		Postmortem: PostmortemConfig{
+			TemplateFile:  getEnv("POSTMORTEM_TEMPLATE_FILE", ""),
+			ServiceLabels: getEnv("POSTMORTEM_SERVICE_LABELS", "service,app,application"),
+		},
+		Analytics: AnalyticsConfig{
+			Enabled:        getEnvBool("ANALYTICS_ENABLED", true),
+			Owner:          getEnv("ANALYTICS_OWNER", ""),
+			Interval:       getEnvInt("ANALYTICS_INTERVAL", 300),
+			Lookback:       getEnvInt("ANALYTICS_LOOKBACK", 172800),
+			Backfill:       getEnvInt("ANALYTICS_BACKFILL", 7776000),
+			MaxHoursPerRun: getEnvInt("ANALYTICS_MAX_HOURS_PER_RUN", 168),
+			LeaseTTL:       getEnvInt("ANALYTICS_LEASE_TTL", 600),
+			ServiceLabels:  getEnv("ANALYTICS_SERVICE_LABELS", "service,app,application"),
+			TeamLabels:     getEnv("ANALYTICS_TEAM_LABELS", "team,owner"),
+			InstanceLabels: getEnv("ANALYTICS_INSTANCE_LABELS", "instance,host,pod"),
+			Timezone:       getEnv("ANALYTICS_TIMEZONE", ""),
+			ShiftHours:     getEnvInt("ANALYTICS_SHIFT_HOURS", 12),
+			ShiftStartHour: getEnvInt("ANALYTICS_SHIFT_START", 9),
+		},
+		AlertBulk: AlertBulkConfig{
+			MaxItems: getEnvInt("ALERT_BULK_MAX_ITEMS", 1000),
+		},
+		Retention: RetentionConfig{
+			Enabled:              getEnvBool("RETENTION_ENABLED", false),
+			Owner:                getEnv("RETENTION_OWNER", ""),
+			Interval:             getEnvInt("RETENTION_INTERVAL", 3600),
+			BatchSize:            getEnvInt("RETENTION_BATCH_SIZE", 1000),
+			MaxBatchesPerRun:     getEnvInt("RETENTION_MAX_BATCHES_PER_RUN", 20),
+			LeaseTTL:             getEnvInt("RETENTION_LEASE_TTL", 1800),
+			Policies:             getEnv("RETENTION_POLICIES", "alerts:resolved:*:90,alerts:*:*:180,alert_processing_records:*:*:30"),
+			PartitionMonthsAhead: getEnvInt("RETENTION_PARTITION_MONTHS_AHEAD", 3),
+			Storage: ArchiveConfig{
+				Backend:     getEnv("ARCHIVE_BACKEND", "local"),
+				Dir:         getEnv("ARCHIVE_DIR", "./data/archive"),
+				S3Endpoint:  getEnv("ARCHIVE_S3_ENDPOINT", "http://localhost:9000"),
+				S3Bucket:    getEnv("ARCHIVE_S3_BUCKET", "alert-archive"),
+				S3Region:    getEnv("ARCHIVE_S3_REGION", "us-east-1"),
+				S3AccessKey: getEnv("ARCHIVE_S3_ACCESS_KEY", ""),
+				S3SecretKey: getEnv("ARCHIVE_S3_SECRET_KEY", ""),
+				S3Prefix:    getEnv("ARCHIVE_S3_PREFIX", ""),
+			},
+		},
+		AI: AIConfig{
+			DefaultBackend:     getEnv("AI_DEFAULT_BACKEND", "ollama"),
+			TypeBackends:       getEnv("AI_TYPE_BACKENDS", ""),
+			Timeout:            getEnvInt("AI_TIMEOUT", 60),
+			MaxRetries:         getEnvInt("AI_MAX_RETRIES", 2),
+			RepairAttempts:     getEnvInt("AI_REPAIR_ATTEMPTS", 1),
+			MaxPromptLength:    getEnvInt("AI_MAX_PROMPT_LENGTH", 8000),
+			Temperature:        getEnvFloat("AI_TEMPERATURE", 0.2),
+			CacheEnabled:       getEnvBool("AI_CACHE_ENABLED", true),
+			CacheTTL:           getEnvInt("AI_CACHE_TTL", 600),
+			DailyTokenBudget:   int64(getEnvInt("AI_DAILY_TOKEN_BUDGET", 0)),
+			TenantTokenBudgets: getEnv("AI_TENANT_TOKEN_BUDGETS", ""),
+			DowngradeRatio:     getEnvFloat("AI_DOWNGRADE_RATIO", 0.8),
+			DowngradeBackend:   getEnv("AI_DOWNGRADE_BACKEND", ""),
+			TenantLabel:        getEnv("AI_TENANT_LABEL", "tenant"),
+			FeedbackWindow:     getEnvInt("AI_FEEDBACK_WINDOW", 86400),
+			OllamaEndpoint:     getEnv("OLLAMA_ENDPOINT", "http://localhost:11434"),
+			OllamaModel:        getEnv("OLLAMA_CHAT_MODEL", "qwen2.5:7b"),
+			OpenAIBaseURL:      getEnv("OPENAI_BASE_URL", "https://api.deepseek.com/v1"),
+			OpenAIAPIKey:       getEnv("OPENAI_API_KEY", ""),
+			OpenAIModel:        getEnv("OPENAI_MODEL", "deepseek-chat"),
+			DifyBaseURL:        getEnv("DIFY_BASE_URL", "http://dify:5001"),
+			DifyAPIKey:         getEnv("DIFY_API_KEY", ""),
+		},
+		AnalysisQueue: AnalysisQueueConfig{
+			VisibilityTimeout: getEnvInt("ANALYSIS_QUEUE_VISIBILITY_TIMEOUT", 120),
+			MaxAttempts:       getEnvInt("ANALYSIS_QUEUE_MAX_ATTEMPTS", 3),
+			ReaperEnabled:     getEnvBool("ANALYSIS_QUEUE_REAPER_ENABLED", true),
+			ReapInterval:      getEnvInt("ANALYSIS_QUEUE_REAP_INTERVAL", 15),
+			IdempotencyTTL:    getEnvInt("ANALYSIS_QUEUE_IDEMPOTENCY_TTL", 86400),
+		},
+		AnalysisWorker: AnalysisWorkerConfig{
+			Enabled:           getEnvBool("ANALYSIS_WORKER_ENABLED", true),
+			MinWorkers:        getEnvInt("ANALYSIS_WORKER_MIN", 1),
+			MaxWorkers:        getEnvInt("ANALYSIS_WORKER_MAX", 8),
+			TasksPerWorker:    getEnvInt("ANALYSIS_WORKER_TASKS_PER_WORKER", 5),
+			MaxTaskAge:        getEnvInt("ANALYSIS_WORKER_MAX_TASK_AGE", 60),
+			ScaleInterval:     getEnvInt("ANALYSIS_WORKER_SCALE_INTERVAL", 10),
+			ScaleUpCooldown:   getEnvInt("ANALYSIS_WORKER_SCALE_UP_COOLDOWN", 30),
+			ScaleDownCooldown: getEnvInt("ANALYSIS_WORKER_SCALE_DOWN_COOLDOWN", 300),
+			ModelConcurrency:  getEnv("ANALYSIS_WORKER_MODEL_CONCURRENCY", ""),
+		},
+		RAG: RAGConfig{
+			Enabled:        getEnvBool("RAG_ENABLED", false),
+			TopK:           getEnvInt("RAG_TOP_K", 3),
+			MinScore:       getEnvFloat("RAG_MIN_SCORE", 0.6),
+			SnippetLength:  getEnvInt("RAG_SNIPPET_LENGTH", 500),
+			SyncInterval:   getEnvInt("RAG_SYNC_INTERVAL", 300),
+			SnapshotPath:   getEnv("RAG_SNAPSHOT_PATH", "./data/knowledge_index.gob"),
+			EmbeddingModel: getEnv("RAG_EMBEDDING_MODEL", "nomic-embed-text"),
+		},
+		Automation: AutomationConfig{
+			Enabled:             getEnvBool("AUTOMATION_ENABLED", true),
+			DryRun:              getEnvBool("AUTOMATION_DRY_RUN", true),
+			RiskyActions:        getEnv("AUTOMATION_RISKY_ACTIONS", "script,kubernetes"),
+			ActionTimeout:       getEnvInt("AUTOMATION_ACTION_TIMEOUT", 60),
+			ApprovalTimeout:     getEnvInt("AUTOMATION_APPROVAL_TIMEOUT", 3600),
+			RunningGrace:        getEnvInt("AUTOMATION_RUNNING_GRACE", 300),
+			PollInterval:        getEnvInt("AUTOMATION_POLL_INTERVAL", 5),
+			Cooldown:            getEnvInt("AUTOMATION_COOLDOWN", 600),
+			CallbackBaseURL:     getEnv("AUTOMATION_CALLBACK_BASE_URL", ""),
+			ApprovalSecret:      getEnv("AUTOMATION_APPROVAL_SECRET", ""),
+			SandboxRuntime:      getEnv("AUTOMATION_SANDBOX_RUNTIME", "docker"),
+			SandboxImage:        getEnv("AUTOMATION_SANDBOX_IMAGE", "alpine:3.19"),
+			SandboxMemory:       getEnv("AUTOMATION_SANDBOX_MEMORY", "128m"),
+			SandboxCPUs:         getEnv("AUTOMATION_SANDBOX_CPUS", "0.5"),
+			SandboxPids:         getEnvInt("AUTOMATION_SANDBOX_PIDS", 64),
+			KubernetesAPIServer: getEnv("AUTOMATION_KUBERNETES_API_SERVER", ""),
+			KubernetesToken:     getEnv("AUTOMATION_KUBERNETES_TOKEN", ""),
+			KubernetesCAFile:    getEnv("AUTOMATION_KUBERNETES_CA_FILE", ""),
+			N8NBaseURL:          getEnv("N8N_BASE_URL", ""),
+			N8NAPIKey:           getEnv("N8N_API_KEY", ""),
+		},
 		Logging: LoggingConfig{
 			Level:      getEnv("LOG_LEVEL", "info"),
 			Format:     getEnv("LOG_FORMAT", "json"),
@@ -241,6 +615,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvFloat 获取环境变量浮点值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvBool 获取环境变量布尔值
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -29,9 +29,13 @@ type AnalysisContainer struct {
 	taskRepo        analysisDomain.AnalysisTaskRepository
 	resultRepo      analysisDomain.AnalysisResultRepository
 	progressTracker analysisDomain.AnalysisProgressTracker
+	eventBus        analysisDomain.AnalysisEventBus
+	alerts          analysisDomain.AlertLoader
 
 	// 队列
-	taskQueue analysisDomain.AnalysisTaskQueue
+	queueConfig analysisDomain.QueueConfig
+	taskQueue   analysisDomain.ReliableTaskQueue
+	queueReaper *analysisApp.QueueReaper
 
 	// 分析引擎
 	analysisEngine analysisDomain.AnalysisEngine
@@ -46,19 +50,32 @@ type AnalysisContainer struct {
 	retryPolicy analysisDomain.AnalysisRetryPolicy
 
 	// 工作器相关
-	workerFactory  worker.WorkerFactory
-	workerManager  analysisDomain.AnalysisWorkerManager
+	workerFactory    worker.WorkerFactory
+	workerManager    analysisDomain.AnalysisWorkerManager
+	autoscalerConfig analysisDomain.AutoscalerConfig
+	autoscalerStatus analysisDomain.AutoscalerStatusStore
 
 	// 应用服务
 	analysisService analysisDomain.AnalysisService
 }
 
-// NewAnalysisContainer 创建分析容器
-func NewAnalysisContainer(db *gorm.DB, redisClient *redis.Client) *AnalysisContainer {
+// NewAnalysisContainer 创建分析容器，engine 为 nil 时使用模拟引擎，alerts 为 nil 时工作器按任务元数据构造告警
+func NewAnalysisContainer(
+	db *gorm.DB,
+	redisClient *redis.Client,
+	engine analysisDomain.AnalysisEngine,
+	alerts analysisDomain.AlertLoader,
+	queueConfig analysisDomain.QueueConfig,
+	autoscalerConfig analysisDomain.AutoscalerConfig,
+) *AnalysisContainer {
 	container := &AnalysisContainer{
-		db:          db,
-		redisClient: redisClient,
-		logger:      logger.L.Named("analysis-container"),
+		db:               db,
+		redisClient:      redisClient,
+		logger:           logger.L.Named("analysis-container"),
+		analysisEngine:   engine,
+		alerts:           alerts,
+		queueConfig:      queueConfig,
+		autoscalerConfig: autoscalerConfig,
 	}
 
 	// 初始化所有依赖
@@ -78,19 +95,21 @@ func NewAnalysisContainer(db *gorm.DB, redisClient *redis.Client) *AnalysisConta
 func (c *AnalysisContainer) initRepositories() {
 	c.taskRepo = repository.NewAnalysisTaskRepository(c.db)
 	c.resultRepo = repository.NewAnalysisResultRepository(c.db)
-	c.progressTracker = repository.NewAnalysisProgressTracker(c.redisClient)
+	c.eventBus = repository.NewAnalysisEventBus(c.redisClient)
+	c.progressTracker = repository.NewAnalysisProgressTracker(c.redisClient, c.eventBus)
 }
 
 // initQueue 初始化队列
 func (c *AnalysisContainer) initQueue() {
-	c.taskQueue = queue.NewAnalysisTaskQueue(c.redisClient)
+	c.taskQueue = queue.NewAnalysisTaskQueue(c.redisClient, c.queueConfig)
+	c.queueReaper = analysisApp.NewQueueReaper(c.taskQueue, c.queueConfig.ReapInterval, c.logger)
 }
 
 // initEngine 初始化分析引擎
 func (c *AnalysisContainer) initEngine() {
-	// TODO: 实现分析引擎
-	// c.analysisEngine = engine.NewAnalysisEngine(...)
-	c.analysisEngine = &MockAnalysisEngine{}
+	if c.analysisEngine == nil {
+		c.analysisEngine = &MockAnalysisEngine{}
+	}
 }
 
 // initMetrics 初始化指标收集器
@@ -123,8 +142,17 @@ func (c *AnalysisContainer) initWorkers() {
 		c.progressTracker,
 		c.analysisEngine,
 		c.metricsCollector,
+		c.eventBus,
+		c.alerts,
+		c.queueConfig.VisibilityTimeout/3,
 	)
 	c.workerManager = worker.NewAnalysisWorkerManager(c.workerFactory)
+	// 超过三个检查间隔未上报的 worker 进程视为已退出
+	interval := c.autoscalerConfig.Interval
+	if interval <= 0 {
+		interval = analysisDomain.DefaultAutoscalerConfig().Interval
+	}
+	c.autoscalerStatus = repository.NewAutoscalerStatusStore(c.redisClient, 3*interval)
 }
 
 // initServices 初始化应用服务
@@ -138,6 +166,8 @@ func (c *AnalysisContainer) initServices() {
 		c.notifier,
 		c.metricsCollector,
 		c.retryPolicy,
+		c.eventBus,
+		c.autoscalerStatus,
 	)
 }
 
@@ -151,11 +181,21 @@ func (c *AnalysisContainer) GetWorkerManager() analysisDomain.AnalysisWorkerMana
 	return c.workerManager
 }
 
+// NewWorkerAutoscaler 创建本进程的工作器自动扩缩容，limiter 与 metrics 可为 nil
+func (c *AnalysisContainer) NewWorkerAutoscaler(limiter analysisDomain.ModelLimiter, metrics analysisDomain.AutoscalerMetrics) *analysisApp.WorkerAutoscaler {
+	return analysisApp.NewWorkerAutoscaler(c.taskQueue, c.workerManager, limiter, c.autoscalerStatus, metrics, c.autoscalerConfig, c.logger)
+}
+
 // GetTaskQueue 获取任务队列
-func (c *AnalysisContainer) GetTaskQueue() analysisDomain.AnalysisTaskQueue {
+func (c *AnalysisContainer) GetTaskQueue() analysisDomain.ReliableTaskQueue {
 	return c.taskQueue
 }
 
+// GetQueueReaper 获取过期租约回收器
+func (c *AnalysisContainer) GetQueueReaper() *analysisApp.QueueReaper {
+	return c.queueReaper
+}
+
 // GetTaskRepository 获取任务仓库
 func (c *AnalysisContainer) GetTaskRepository() analysisDomain.AnalysisTaskRepository {
 	return c.taskRepo
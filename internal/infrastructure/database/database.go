@@ -3,8 +3,16 @@ package database
 import (
 	"time"
 
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/domain/analytics"
+	"alert_agent/internal/domain/automation"
 	"alert_agent/internal/domain/channel"
 	"alert_agent/internal/domain/cluster"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/domain/retention"
+	aiAnalysis "alert_agent/internal/infrastructure/analysis"
 	"alert_agent/internal/infrastructure/config"
 	"alert_agent/internal/security/domain"
 
@@ -46,6 +54,22 @@ func Migrate(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&cluster.Cluster{},
 		&channel.Channel{},
+		&gateway.RoutingConfig{},
+		&gateway.AlertProcessingRecord{},
+		&gateway.ClassifierModel{},
+		&incident.Incident{},
+		&incident.IncidentAlert{},
+		&incident.TimelineEntry{},
+		&alert.SavedSearch{},
+		&alert.Activity{},
+		&analytics.HourlyRollup{},
+		&analytics.RollupState{},
+		&retention.Archive{},
+		&aiAnalysis.AnalysisTemplate{},
+		&analysis.AnalysisFeedback{},
+		&automation.Runbook{},
+		&automation.Execution{},
+		&automation.AutomationAction{},
 		&domain.User{},
 		&domain.Role{},
 		&domain.Permission{},
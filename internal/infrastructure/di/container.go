@@ -1,24 +1,51 @@
 package di
 
 import (
+	"os"
+	"strings"
 	"time"
 	
+	alertApp "alert_agent/internal/application/alert"
+	analyticsApp "alert_agent/internal/application/analytics"
+	automationApp "alert_agent/internal/application/automation"
 	"alert_agent/internal/application/analysis"
 	"alert_agent/internal/application/channel"
 	"alert_agent/internal/application/cluster"
+	"alert_agent/internal/application/gateway"
+	incidentApp "alert_agent/internal/application/incident"
+	retentionApp "alert_agent/internal/application/retention"
+	ruleApp "alert_agent/internal/application/rule"
 	"alert_agent/internal/infrastructure/alert"
+	aiAnalysis "alert_agent/internal/infrastructure/analysis"
+	"alert_agent/internal/infrastructure/archive"
+	automationInfra "alert_agent/internal/infrastructure/automation"
 	"alert_agent/internal/infrastructure/config"
 	"alert_agent/internal/infrastructure/container"
 	"alert_agent/internal/infrastructure/dify"
+	"alert_agent/internal/infrastructure/n8n"
+	"alert_agent/internal/infrastructure/ollama"
+	"alert_agent/internal/infrastructure/queue"
 	"alert_agent/internal/infrastructure/repository"
+	"alert_agent/internal/infrastructure/vectorindex"
 	"alert_agent/internal/interfaces/http"
+	"alert_agent/internal/observability/metrics"
+	"alert_agent/internal/pkg/feature"
+	pkglogger "alert_agent/internal/pkg/logger"
 	"alert_agent/internal/security/di"
+	"alert_agent/internal/service"
 
 	analysisDomain "alert_agent/internal/domain/analysis"
+	analyticsDomain "alert_agent/internal/domain/analytics"
+	automationDomain "alert_agent/internal/domain/automation"
 	alertDomain "alert_agent/internal/domain/alert"
 	channelDomain "alert_agent/internal/domain/channel"
 	clusterDomain "alert_agent/internal/domain/cluster"
+	gatewayDomain "alert_agent/internal/domain/gateway"
+	incidentDomain "alert_agent/internal/domain/incident"
+	retentionDomain "alert_agent/internal/domain/retention"
+	ruleDomain "alert_agent/internal/domain/rule"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -36,20 +63,59 @@ type Container struct {
 	channelRepo         channelDomain.Repository
 	alertRepo           alertDomain.AlertRepository
 	difyAnalysisRepo    analysisDomain.DifyAnalysisRepository
+	routingConfigRepo   gatewayDomain.RoutingConfigRepository
+	flapStateStore      gatewayDomain.FlapStateStore
+	alertProcessingRepo gatewayDomain.AlertProcessingRepository
+	incidentRepo        incidentDomain.Repository
+	alertSearchRepo     alertDomain.SearchRepository
+	savedSearchRepo     alertDomain.SavedSearchRepository
+	analyticsRepo       analyticsDomain.Repository
+	alertBulkRepo       alertDomain.BulkRepository
+	alertActivityRepo   alertDomain.ActivityRepository
+	retentionRepo       retentionDomain.Repository
 
 	// Services
-	clusterService      clusterDomain.Service
-	channelService      channelDomain.Service
-	channelManager      channelDomain.ChannelManager
-	analysisService     analysisDomain.AnalysisService
-	difyAnalysisService analysisDomain.DifyAnalysisService
+	clusterService       clusterDomain.Service
+	channelService       channelDomain.Service
+	channelManager       channelDomain.ChannelManager
+	analysisService      analysisDomain.AnalysisService
+	difyAnalysisService  analysisDomain.DifyAnalysisService
+	routingService       gatewayDomain.RoutingConfigService
+	flapDetector         gatewayDomain.FlapDetector
+	pipelineService      gatewayDomain.PipelineService
+	incidentService      *incidentApp.Service
+	postmortemService    incidentDomain.PostmortemService
+	alertSearchService   *alertApp.SearchService
+	analyticsService     *analyticsApp.Service
+	alertBulkService     *alertApp.BulkService
+	alertActivityService *alertApp.ActivityService
+	retentionService     *retentionApp.Service
+	usageService         *analysis.UsageService
+	feedbackService      *analysis.FeedbackService
+	automationService    *automationApp.Service
+	automationRunner     *automationApp.Runner
+
+	// Gateway Components
+	featureToggles *feature.ToggleManager
+	alertStream    gatewayDomain.AlertStream
+	gatewayMetrics *metrics.GatewayMetrics
+	smartGateway   *gateway.SmartGatewayService
+	classifier     *gateway.AlertClassifierService
+	flapReleaser   *gateway.FlapReleaser
+
+	// Rule Engine
+	ruleScheduler *ruleApp.Scheduler
 
 	// Dify Components
 	difyClient analysisDomain.DifyClient
 	difyConfig *analysis.DifyAnalysisConfig
 
 	// Analysis Container
-	analysisContainer *container.AnalysisContainer
+	analysisContainer     *container.AnalysisContainer
+	modelLimiter          *aiAnalysis.ModelLimiter
+	engine                analysisDomain.AnalysisEngine
+	workerAutoscaler      *analysis.WorkerAutoscaler
+	knowledgeIndexService *analysis.KnowledgeIndexService
 
 	// Security Container
 	securityContainer *di.Container
@@ -69,6 +135,8 @@ func NewContainer(db *gorm.DB, redisClient *redis.Client, logger *zap.Logger, cf
 
 	c.initRepositories()
 	c.initServices()
+	c.initGateway()
+	c.initRuleEngine()
 	c.initDifyComponents()
 	c.initAnalysisContainer()
 	c.initSecurityContainer()
@@ -83,6 +151,16 @@ func (c *Container) initRepositories() {
 	c.channelRepo = repository.NewChannelRepository(c.db)
 	c.alertRepo = alert.NewGORMAlertRepository(c.db)
 	c.difyAnalysisRepo = repository.NewDifyAnalysisRepository(c.db, c.logger)
+	c.routingConfigRepo = repository.NewRoutingConfigRepository(c.db)
+	c.flapStateStore = repository.NewFlapStateStore(c.redisClient, c.flappingConfig().Window)
+	c.alertProcessingRepo = repository.NewAlertProcessingRepository(c.db)
+	c.incidentRepo = repository.NewIncidentRepository(c.db)
+	c.alertSearchRepo = alert.NewGORMSearchRepository(c.db)
+	c.savedSearchRepo = repository.NewSavedSearchRepository(c.db)
+	c.analyticsRepo = repository.NewAnalyticsRepository(c.db)
+	c.alertBulkRepo = alert.NewGORMBulkRepository(c.db)
+	c.alertActivityRepo = repository.NewAlertActivityRepository(c.db)
+	c.retentionRepo = repository.NewRetentionRepository(c.db)
 }
 
 // initServices 初始化服务层
@@ -90,6 +168,15 @@ func (c *Container) initServices() {
 	c.clusterService = cluster.NewClusterService(c.clusterRepo)
 	c.channelService = channel.NewChannelService(c.channelRepo)
 	c.channelManager = channel.NewDefaultChannelManager(c.channelRepo, c.channelService, c.logger)
+	c.routingService = gateway.NewRoutingConfigService(c.routingConfigRepo, c.logger)
+	c.flapDetector = gateway.NewFlapDetectorService(c.flapStateStore, c.flappingConfig(), c.logger)
+	c.incidentService = incidentApp.NewService(c.incidentRepo, service.NewAlertService(c.db, c.redisClient), c.logger)
+	c.postmortemService = c.postmortemGenerator()
+	c.alertSearchService = alertApp.NewSearchService(c.alertSearchRepo, c.savedSearchRepo, c.logger)
+	c.analyticsService = c.analytics()
+	c.alertActivityService = alertApp.NewActivityService(c.alertActivityRepo, c.alertRepo, c.logger)
+	c.retentionService = c.retention()
+	c.automationService, c.automationRunner = c.automation()
 	
 	// 初始化 Dify 配置和客户端
 	c.initDifyComponents()
@@ -102,6 +189,418 @@ func (c *Container) initServices() {
 		c.logger,
 		c.difyConfig,
 	)
+
+	bulkConfig := alertDomain.DefaultBulkConfig()
+	if c.config.AlertBulk.MaxItems > 0 {
+		bulkConfig.MaxItems = c.config.AlertBulk.MaxItems
+	}
+	c.alertBulkService = alertApp.NewBulkService(c.alertRepo, c.alertBulkRepo, c.difyAnalysisService, c.alertActivityService, bulkConfig, c.logger)
+}
+
+// flappingConfig 根据配置生成抖动检测参数
+func (c *Container) flappingConfig() gatewayDomain.FlappingConfig {
+	cfg := c.config.Gateway
+	return gatewayDomain.FlappingConfig{
+		Window:            time.Duration(cfg.FlapWindow) * time.Second,
+		HistorySize:       cfg.FlapHistorySize,
+		MinSamples:        cfg.FlapMinSamples,
+		HighThreshold:     float64(cfg.FlapHighThreshold),
+		LowThreshold:      float64(cfg.FlapLowThreshold),
+		HoldNotifications: cfg.FlapHoldNotifications,
+		ReleaseInterval:   time.Duration(cfg.FlapReleaseInterval) * time.Second,
+	}
+}
+
+// similarityEngine 根据配置创建告警相似度引擎，向量权重为 0 时不连接 Ollama
+func (c *Container) similarityEngine() *gateway.SimilarityEngine {
+	cfg := c.config.Gateway
+	similarityConfig := gatewayDomain.DefaultSimilarityConfig()
+	similarityConfig.Weights = gatewayDomain.SimilarityWeights{
+		Labels:    float64(cfg.SimilarityLabelWeight) / 100,
+		Text:      float64(cfg.SimilarityTextWeight) / 100,
+		Time:      float64(cfg.SimilarityTimeWeight) / 100,
+		Embedding: float64(cfg.SimilarityEmbeddingWeight) / 100,
+	}
+	if cfg.SimilarityTimeHalfLife > 0 {
+		similarityConfig.TimeDecayHalfLife = time.Duration(cfg.SimilarityTimeHalfLife) * time.Second
+	}
+
+	var embedder gatewayDomain.EmbeddingProvider
+	if cfg.SimilarityEmbeddingWeight > 0 {
+		embedder = ollama.NewEmbeddingClient(cfg.OllamaEndpoint, cfg.OllamaEmbeddingModel, time.Duration(cfg.OllamaTimeout)*time.Second)
+	}
+	return gateway.NewSimilarityEngine(similarityConfig, embedder, c.logger)
+}
+
+// topologyCorrelator 根据配置创建拓扑关联服务，拓扑文件加载失败时仅从告警标签学习依赖
+func (c *Container) topologyCorrelator() *gateway.TopologyCorrelatorService {
+	cfg := c.config.Gateway
+	topologyConfig := gatewayDomain.DefaultTopologyConfig()
+	if cfg.TopologyWindow > 0 {
+		topologyConfig.Window = time.Duration(cfg.TopologyWindow) * time.Second
+	}
+	if cfg.TopologyServiceLabel != "" {
+		topologyConfig.ServiceLabel = cfg.TopologyServiceLabel
+	}
+	if cfg.TopologyUpstreamLabel != "" {
+		topologyConfig.UpstreamLabel = cfg.TopologyUpstreamLabel
+	}
+	if cfg.TopologyMaxAlerts > 0 {
+		topologyConfig.MaxAlerts = cfg.TopologyMaxAlerts
+	}
+
+	var definition *gatewayDomain.TopologyDefinition
+	if cfg.TopologyFile != "" {
+		var err error
+		definition, err = gateway.LoadTopologyDefinition(cfg.TopologyFile)
+		if err != nil {
+			c.logger.Warn("failed to load service topology", zap.String("file", cfg.TopologyFile), zap.Error(err))
+		}
+	}
+	return gateway.NewTopologyCorrelatorService(definition, topologyConfig, c.logger)
+}
+
+// alertClassifier 根据配置创建告警分类服务，未启用时返回 nil
+func (c *Container) alertClassifier() *gateway.AlertClassifierService {
+	cfg := c.config.Gateway
+	if !cfg.ClassifierEnabled {
+		return nil
+	}
+	classifierConfig := gatewayDomain.DefaultClassifierConfig()
+	classifierConfig.ConfidenceThreshold = cfg.ClassifierConfidenceThreshold
+	if cfg.ClassifierReloadInterval > 0 {
+		classifierConfig.ReloadInterval = time.Duration(cfg.ClassifierReloadInterval) * time.Second
+	}
+
+	// LLM 兜底与分析任务共用分析引擎，调用计入租户预算并受模型并发限制
+	var fallback gatewayDomain.AlertClassifier
+	if cfg.ClassifierLLMFallback {
+		fallback = aiAnalysis.NewLLMAlertClassifier(c.sharedAnalysisEngine(),
+			time.Duration(cfg.ClassifierLLMTimeout)*time.Second, cfg.ClassifierLLMConcurrency)
+	}
+	return gateway.NewAlertClassifierService(repository.NewAlertClassifierRepository(c.db), fallback, classifierConfig, c.logger)
+}
+
+// metricSnapshotter 根据配置创建指标快照采集服务，未启用时返回 nil
+func (c *Container) metricSnapshotter() gatewayDomain.MetricSnapshotter {
+	cfg := c.config.Gateway
+	if !cfg.SnapshotEnabled {
+		return nil
+	}
+	snapshotConfig := gatewayDomain.DefaultMetricSnapshotConfig()
+	if cfg.SnapshotLookback > 0 {
+		snapshotConfig.Lookback = time.Duration(cfg.SnapshotLookback) * time.Second
+	}
+	if cfg.SnapshotLookahead >= 0 {
+		snapshotConfig.Lookahead = time.Duration(cfg.SnapshotLookahead) * time.Second
+	}
+	if cfg.SnapshotMaxPoints > 0 {
+		snapshotConfig.MaxPoints = cfg.SnapshotMaxPoints
+	}
+	if cfg.SnapshotMaxRelated >= 0 {
+		snapshotConfig.MaxRelatedSeries = cfg.SnapshotMaxRelated
+	}
+	if cfg.SnapshotTimeout > 0 {
+		snapshotConfig.Timeout = time.Duration(cfg.SnapshotTimeout) * time.Second
+	}
+	for _, query := range strings.Split(cfg.SnapshotRelatedQueries, ";") {
+		if query = strings.TrimSpace(query); query != "" {
+			snapshotConfig.RelatedQueries = append(snapshotConfig.RelatedQueries, query)
+		}
+	}
+
+	return gateway.NewMetricSnapshotService(
+		repository.NewMetricSnapshotRepository(c.db),
+		service.NewProviderService(c.db, c.redisClient),
+		snapshotConfig,
+		c.logger,
+	)
+}
+
+// postmortemGenerator 根据配置创建复盘报告生成器，自定义模板不可用时回退到内置模板
+func (c *Container) postmortemGenerator() *incidentApp.PostmortemGenerator {
+	cfg := c.config.Postmortem
+	postmortemConfig := incidentApp.DefaultPostmortemConfig()
+	if labels := splitList(cfg.ServiceLabels); len(labels) > 0 {
+		postmortemConfig.ServiceLabels = labels
+	}
+	if cfg.TemplateFile != "" {
+		content, err := os.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			c.logger.Warn("failed to read postmortem template, using built-in template",
+				zap.String("file", cfg.TemplateFile), zap.Error(err))
+		} else {
+			postmortemConfig.Template = string(content)
+		}
+	}
+
+	repo := repository.NewPostmortemRepository(c.db)
+	generator, err := incidentApp.NewPostmortemGenerator(c.incidentRepo, repo, c.difyAnalysisRepo, postmortemConfig, c.logger)
+	if err != nil {
+		c.logger.Warn("invalid postmortem template, using built-in template",
+			zap.String("file", cfg.TemplateFile), zap.Error(err))
+		postmortemConfig.Template = ""
+		generator, _ = incidentApp.NewPostmortemGenerator(c.incidentRepo, repo, c.difyAnalysisRepo, postmortemConfig, c.logger)
+	}
+	return generator
+}
+
+// analytics 根据配置创建告警分析服务
+func (c *Container) analytics() *analyticsApp.Service {
+	cfg := c.config.Analytics
+	analyticsConfig := analyticsDomain.DefaultConfig()
+	analyticsConfig.Owner = cfg.Owner
+	if cfg.Interval > 0 {
+		analyticsConfig.Interval = time.Duration(cfg.Interval) * time.Second
+	}
+	if cfg.Lookback > 0 {
+		analyticsConfig.Lookback = time.Duration(cfg.Lookback) * time.Second
+	}
+	if cfg.Backfill > 0 {
+		analyticsConfig.Backfill = time.Duration(cfg.Backfill) * time.Second
+	}
+	if cfg.MaxHoursPerRun > 0 {
+		analyticsConfig.MaxHoursPerRun = cfg.MaxHoursPerRun
+	}
+	if cfg.LeaseTTL > 0 {
+		analyticsConfig.LeaseTTL = time.Duration(cfg.LeaseTTL) * time.Second
+	}
+	if labels := splitList(cfg.ServiceLabels); len(labels) > 0 {
+		analyticsConfig.ServiceLabels = labels
+	}
+	if labels := splitList(cfg.TeamLabels); len(labels) > 0 {
+		analyticsConfig.TeamLabels = labels
+	}
+	if labels := splitList(cfg.InstanceLabels); len(labels) > 0 {
+		analyticsConfig.InstanceLabels = labels
+	}
+	if cfg.Timezone != "" {
+		location, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			c.logger.Warn("invalid analytics timezone, using local timezone",
+				zap.String("timezone", cfg.Timezone), zap.Error(err))
+		} else {
+			analyticsConfig.Location = location
+		}
+	}
+	if cfg.ShiftHours > 0 {
+		analyticsConfig.ShiftHours = cfg.ShiftHours
+	}
+	if cfg.ShiftStartHour >= 0 && cfg.ShiftStartHour < 24 {
+		analyticsConfig.ShiftStartHour = cfg.ShiftStartHour
+	}
+
+	return analyticsApp.NewService(c.analyticsRepo, repository.NewLeaseManager(c.redisClient), analyticsConfig, c.logger)
+}
+
+// retention 根据配置创建数据保留服务，S3 配置无效时归档到本地目录
+func (c *Container) retention() *retentionApp.Service {
+	cfg := c.config.Retention
+	retentionConfig := retentionDomain.DefaultConfig()
+	retentionConfig.Owner = cfg.Owner
+	if cfg.Interval > 0 {
+		retentionConfig.Interval = time.Duration(cfg.Interval) * time.Second
+	}
+	if cfg.BatchSize > 0 {
+		retentionConfig.BatchSize = cfg.BatchSize
+	}
+	if cfg.MaxBatchesPerRun > 0 {
+		retentionConfig.MaxBatchesPerRun = cfg.MaxBatchesPerRun
+	}
+	if cfg.LeaseTTL > 0 {
+		retentionConfig.LeaseTTL = time.Duration(cfg.LeaseTTL) * time.Second
+	}
+	if cfg.PartitionMonthsAhead >= 0 {
+		retentionConfig.PartitionMonthsAhead = cfg.PartitionMonthsAhead
+	}
+	if cfg.Policies != "" {
+		policies, err := retentionDomain.ParsePolicies(cfg.Policies)
+		if err != nil {
+			c.logger.Warn("invalid retention policies, using default policies", zap.Error(err))
+		} else {
+			retentionConfig.Policies = policies
+		}
+	}
+
+	storage := archive.NewLocalStorage(cfg.Storage.Dir)
+	if cfg.Storage.Backend == "s3" {
+		s3Storage, err := archive.NewS3Storage(archive.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Bucket:    cfg.Storage.S3Bucket,
+			Region:    cfg.Storage.S3Region,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			Prefix:    cfg.Storage.S3Prefix,
+		})
+		if err != nil {
+			c.logger.Warn("invalid s3 archive storage, archiving to local directory",
+				zap.String("dir", cfg.Storage.Dir), zap.Error(err))
+		} else {
+			storage = s3Storage
+		}
+	}
+
+	return retentionApp.NewService(c.retentionRepo, storage, repository.NewLeaseManager(c.redisClient), retentionConfig, c.logger)
+}
+
+// splitList 拆分逗号分隔的配置项，忽略空值
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// automation 根据配置创建运行手册服务与执行器
+// 未配置 Kubernetes 或 n8n 时对应操作执行失败，脚本在容器运行时中沙箱执行
+func (c *Container) automation() (*automationApp.Service, *automationApp.Runner) {
+	cfg := c.config.Automation
+	automationConfig := automationDomain.DefaultConfig()
+	automationConfig.DryRun = cfg.DryRun
+	if risky := splitList(cfg.RiskyActions); len(risky) > 0 {
+		automationConfig.RiskyActions = nil
+		for _, actionType := range risky {
+			automationConfig.RiskyActions = append(automationConfig.RiskyActions, automationDomain.ActionType(actionType))
+		}
+	}
+	if cfg.ActionTimeout > 0 {
+		automationConfig.DefaultTimeout = time.Duration(cfg.ActionTimeout) * time.Second
+	}
+	if cfg.ApprovalTimeout > 0 {
+		automationConfig.ApprovalTimeout = time.Duration(cfg.ApprovalTimeout) * time.Second
+	}
+	if cfg.RunningGrace > 0 {
+		automationConfig.RunningGrace = time.Duration(cfg.RunningGrace) * time.Second
+	}
+	if cfg.PollInterval > 0 {
+		automationConfig.PollInterval = time.Duration(cfg.PollInterval) * time.Second
+	}
+	if cfg.Cooldown > 0 {
+		automationConfig.Cooldown = time.Duration(cfg.Cooldown) * time.Second
+	}
+	automationConfig.CallbackBaseURL = cfg.CallbackBaseURL
+	automationConfig.ApprovalSecret = cfg.ApprovalSecret
+
+	executors := map[automationDomain.ActionType]automationDomain.ActionExecutor{
+		automationDomain.ActionHTTP: automationInfra.NewHTTPExecutor(),
+		automationDomain.ActionScript: automationInfra.NewScriptExecutor(automationInfra.SandboxConfig{
+			Runtime: cfg.SandboxRuntime,
+			Image:   cfg.SandboxImage,
+			Memory:  cfg.SandboxMemory,
+			CPUs:    cfg.SandboxCPUs,
+			Pids:    cfg.SandboxPids,
+		}),
+	}
+	k8sClient, err := automationInfra.NewKubernetesRESTClient(automationInfra.KubernetesConfig{
+		APIServer: cfg.KubernetesAPIServer,
+		Token:     cfg.KubernetesToken,
+		CAFile:    cfg.KubernetesCAFile,
+	})
+	if err != nil {
+		c.logger.Info("kubernetes runbook actions disabled", zap.Error(err))
+	} else {
+		executors[automationDomain.ActionKubernetes] = automationInfra.NewKubernetesExecutor(k8sClient)
+	}
+	if cfg.N8NBaseURL != "" {
+		executors[automationDomain.ActionN8N] = automationInfra.NewN8NExecutor(n8n.NewHTTPClient(&n8n.HTTPClientConfig{
+			BaseURL: cfg.N8NBaseURL,
+			APIKey:  cfg.N8NAPIKey,
+			Timeout: automationConfig.DefaultTimeout,
+		}, c.logger))
+	}
+
+	executionRepo := repository.NewRunbookExecutionRepository(c.db)
+	service := automationApp.NewService(repository.NewRunbookRepository(c.db), executionRepo, c.alertRepo, c.channelManager, automationConfig, c.logger)
+	return service, automationApp.NewRunner(executionRepo, executors, automationConfig, c.logger)
+}
+
+// initGateway 初始化告警网关，告警写入处理流后由 worker 消费组处理
+func (c *Container) initGateway() {
+	c.featureToggles = feature.NewToggleManager(c.logger)
+	toggles := c.featureToggles
+	featureToggle := gateway.NewFeatureToggleAdapter(toggles)
+	c.gatewayMetrics = metrics.NewGatewayMetrics(prometheus.DefaultRegisterer)
+	c.alertStream = queue.NewRedisAlertStream(c.redisClient)
+
+	// 未启用分类模型时智能路由使用关键词规则
+	var classifier gatewayDomain.AlertClassifier
+	if c.classifier = c.alertClassifier(); c.classifier != nil {
+		classifier = c.classifier
+	}
+
+	// 未启用自动化时路由后不触发运行手册
+	var runbooks gatewayDomain.RunbookTrigger
+	if c.config.Automation.Enabled {
+		runbooks = c.automationService
+	}
+
+	c.smartGateway = gateway.NewSmartGatewayService(
+		gateway.NewAlertReceiverService(c.alertProcessingRepo, c.gatewayMetrics, c.metricSnapshotter(), c.logger),
+		gateway.NewAlertProcessorService(c.alertProcessingRepo, featureToggle, c.gatewayMetrics, classifier, c.logger),
+		gateway.NewAlertRouterService(toggles, c.gatewayMetrics, c.routingService),
+		gateway.NewAlertSuppressorService(toggles, c.gatewayMetrics),
+		gateway.NewAlertConvergerService(toggles, c.gatewayMetrics, c.alertProcessingRepo, c.similarityEngine(), c.topologyCorrelator()),
+		c.alertProcessingRepo,
+		featureToggle,
+		c.gatewayMetrics,
+		c.flapDetector,
+		c.incidentService,
+		runbooks,
+		c.alertStream,
+		c.logger,
+	)
+	c.pipelineService = gateway.NewPipelineManagementService(c.alertStream, c.logger)
+	c.flapReleaser = gateway.NewFlapReleaser(c.flapDetector, c.alertRepo, c.alertStream, c.flappingConfig().ReleaseInterval, c.logger)
+}
+
+// initRuleEngine 初始化规则评估引擎，告警通过 AlertService 创建和恢复
+func (c *Container) initRuleEngine() {
+	cfg := c.config.RuleEngine
+	evaluationConfig := ruleDomain.DefaultEvaluationConfig()
+	evaluationConfig.Owner = cfg.Owner
+	if cfg.Interval > 0 {
+		evaluationConfig.Interval = time.Duration(cfg.Interval) * time.Second
+	}
+	if cfg.LeaseTTL > 0 {
+		evaluationConfig.LeaseTTL = time.Duration(cfg.LeaseTTL) * time.Second
+	}
+	if cfg.Timeout > 0 {
+		evaluationConfig.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	evaluator := ruleApp.NewEvaluator(
+		service.NewProviderService(c.db, c.redisClient),
+		repository.NewRuleStateStore(c.redisClient),
+		service.NewAlertService(c.db, c.redisClient),
+		c.logger,
+	)
+	c.ruleScheduler = ruleApp.NewScheduler(
+		repository.NewRuleSource(c.db),
+		repository.NewLeaseManager(c.redisClient),
+		evaluator,
+		evaluationConfig,
+		c.logger,
+	)
+}
+
+// PipelineConfig 根据配置生成处理管道参数
+func (c *Container) PipelineConfig() gatewayDomain.PipelineConfig {
+	cfg := c.config.Gateway
+	pipelineConfig := gatewayDomain.DefaultPipelineConfig()
+	pipelineConfig.Consumer = cfg.PipelineConsumer
+	if cfg.PipelineConcurrency > 0 {
+		pipelineConfig.Concurrency = cfg.PipelineConcurrency
+	}
+	if cfg.PipelineMaxDeliveries > 0 {
+		pipelineConfig.MaxDeliveries = int64(cfg.PipelineMaxDeliveries)
+	}
+	if cfg.PipelineClaimMinIdle > 0 {
+		pipelineConfig.ClaimMinIdle = time.Duration(cfg.PipelineClaimMinIdle) * time.Second
+	}
+	return pipelineConfig
 }
 
 // initDifyComponents 初始化 Dify 组件
@@ -120,16 +619,224 @@ func (c *Container) initDifyComponents() {
 	}
 	
 	// 创建 Dify 客户端
-	c.difyClient = dify.NewDifyClient(
-		"http://dify:5001", // baseURL
-		"your-api-key",     // apiKey
-		c.logger,
-	)
+	c.difyClient = dify.NewDifyClient(c.config.AI.DifyBaseURL, c.config.AI.DifyAPIKey, c.logger)
 }
 
 // initAnalysisContainer 初始化分析容器
 func (c *Container) initAnalysisContainer() {
-	c.analysisContainer = container.NewAnalysisContainer(c.db, c.redisClient)
+	c.usageService = analysis.NewUsageService(c.usageStore(), c.budgetConfig())
+	c.analysisContainer = container.NewAnalysisContainer(c.db, c.redisClient, c.sharedAnalysisEngine(), c.alertRepo, c.analysisQueueConfig(), c.autoscalerConfig())
+	c.analysisService = c.analysisContainer.GetAnalysisService()
+	c.workerAutoscaler = c.analysisContainer.NewWorkerAutoscaler(c.modelLimiter, metrics.NewAnalysisWorkerMetrics(prometheus.DefaultRegisterer))
+	// 反馈准确率写入功能开关的成熟度评估器
+	c.feedbackService = analysis.NewFeedbackService(
+		repository.NewAnalysisFeedbackRepository(c.db),
+		c.analysisContainer.GetResultRepository(),
+		c.featureToggles.Evaluator(),
+		time.Duration(c.config.AI.FeedbackWindow)*time.Second,
+	)
+}
+
+// analysisQueueConfig 按配置创建分析任务队列配置，未配置的项使用默认值
+func (c *Container) analysisQueueConfig() analysisDomain.QueueConfig {
+	cfg := c.config.AnalysisQueue
+	queueConfig := analysisDomain.DefaultQueueConfig()
+	if cfg.VisibilityTimeout > 0 {
+		queueConfig.VisibilityTimeout = time.Duration(cfg.VisibilityTimeout) * time.Second
+	}
+	if cfg.MaxAttempts > 0 {
+		queueConfig.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.ReapInterval > 0 {
+		queueConfig.ReapInterval = time.Duration(cfg.ReapInterval) * time.Second
+	}
+	if cfg.IdempotencyTTL > 0 {
+		queueConfig.IdempotencyTTL = time.Duration(cfg.IdempotencyTTL) * time.Second
+	}
+	return queueConfig
+}
+
+// autoscalerConfig 按配置创建分析工作器扩缩容配置，未配置的项使用默认值
+func (c *Container) autoscalerConfig() analysisDomain.AutoscalerConfig {
+	cfg := c.config.AnalysisWorker
+	autoscalerConfig := analysisDomain.DefaultAutoscalerConfig()
+	if cfg.MinWorkers >= 0 {
+		autoscalerConfig.MinWorkers = cfg.MinWorkers
+	}
+	if cfg.MaxWorkers > 0 {
+		autoscalerConfig.MaxWorkers = cfg.MaxWorkers
+	}
+	if cfg.TasksPerWorker > 0 {
+		autoscalerConfig.TasksPerWorker = cfg.TasksPerWorker
+	}
+	if cfg.MaxTaskAge >= 0 {
+		autoscalerConfig.MaxTaskAge = time.Duration(cfg.MaxTaskAge) * time.Second
+	}
+	if cfg.ScaleInterval > 0 {
+		autoscalerConfig.Interval = time.Duration(cfg.ScaleInterval) * time.Second
+	}
+	if cfg.ScaleUpCooldown >= 0 {
+		autoscalerConfig.ScaleUpCooldown = time.Duration(cfg.ScaleUpCooldown) * time.Second
+	}
+	if cfg.ScaleDownCooldown >= 0 {
+		autoscalerConfig.ScaleDownCooldown = time.Duration(cfg.ScaleDownCooldown) * time.Second
+	}
+	return autoscalerConfig
+}
+
+// knowledgeIndex 按配置创建知识索引服务，未启用检索增强时返回 nil
+// 快照加载失败时从空索引开始，由同步任务重新生成向量
+func (c *Container) knowledgeIndex() *analysis.KnowledgeIndexService {
+	cfg := c.config.RAG
+	if !cfg.Enabled {
+		return nil
+	}
+	indexConfig := vectorindex.DefaultHNSWConfig()
+	indexConfig.SnapshotPath = cfg.SnapshotPath
+	index, err := vectorindex.OpenHNSW(indexConfig)
+	if err != nil {
+		c.logger.Warn("failed to load knowledge index snapshot, rebuilding", zap.String("file", cfg.SnapshotPath), zap.Error(err))
+		index = vectorindex.NewHNSW(indexConfig)
+	}
+
+	retrievalConfig := analysisDomain.DefaultRetrievalConfig()
+	if cfg.TopK > 0 {
+		retrievalConfig.TopK = cfg.TopK
+	}
+	if cfg.MinScore >= 0 {
+		retrievalConfig.MinScore = cfg.MinScore
+	}
+	if cfg.SnippetLength > 0 {
+		retrievalConfig.SnippetLength = cfg.SnippetLength
+	}
+	if cfg.SyncInterval > 0 {
+		retrievalConfig.SyncInterval = time.Duration(cfg.SyncInterval) * time.Second
+	}
+	embedder := ollama.NewEmbeddingClient(c.config.AI.OllamaEndpoint, cfg.EmbeddingModel, time.Duration(c.config.AI.Timeout)*time.Second)
+	return analysis.NewKnowledgeIndexService(
+		repository.NewKnowledgeSourceRepository(c.db),
+		index,
+		embedder,
+		retrievalConfig,
+		c.logger,
+	)
+}
+
+// budgetConfig 按配置创建令牌预算配置，租户预算格式错误时只使用统一预算
+func (c *Container) budgetConfig() analysisDomain.BudgetConfig {
+	cfg := c.config.AI
+	budget := analysisDomain.DefaultBudgetConfig()
+	budget.DailyTokens = cfg.DailyTokenBudget
+	if cfg.DowngradeRatio > 0 {
+		budget.DowngradeRatio = cfg.DowngradeRatio
+	}
+	if cfg.TenantLabel != "" {
+		budget.TenantLabel = cfg.TenantLabel
+	}
+	tenantBudgets, err := analysisDomain.ParseTenantBudgets(cfg.TenantTokenBudgets)
+	if err != nil {
+		c.logger.Warn("invalid tenant token budgets, using daily budget for all tenants", zap.Error(err))
+	}
+	budget.TenantDailyTokens = tenantBudgets
+	return budget
+}
+
+// usageStore 创建模型用量统计
+func (c *Container) usageStore() analysisDomain.UsageStore {
+	return aiAnalysis.NewRedisUsageStore(c.redisClient, 0)
+}
+
+// sharedAnalysisEngine 获取分析任务与告警分类共用的分析引擎，首次调用时创建
+func (c *Container) sharedAnalysisEngine() analysisDomain.AnalysisEngine {
+	if c.engine == nil {
+		// 分析模块使用全局日志器，api 与 worker 未初始化时沿用容器日志器
+		if pkglogger.L == nil {
+			pkglogger.L = c.logger
+		}
+		c.knowledgeIndexService = c.knowledgeIndex()
+		c.engine = c.analysisEngine()
+	}
+	return c.engine
+}
+
+// analysisEngine 按配置创建分析引擎，分析类型可指定不同的模型后端
+func (c *Container) analysisEngine() analysisDomain.AnalysisEngine {
+	cfg := c.config.AI
+	chatConfig := func(baseURL, apiKey, model string) aiAnalysis.ChatConfig {
+		return aiAnalysis.ChatConfig{
+			BaseURL:     baseURL,
+			APIKey:      apiKey,
+			Model:       model,
+			Temperature: cfg.Temperature,
+			Timeout:     time.Duration(cfg.Timeout) * time.Second,
+		}
+	}
+	services := map[string]aiAnalysis.AIService{
+		aiAnalysis.BackendOllama: aiAnalysis.NewOllamaAIService(chatConfig(cfg.OllamaEndpoint, "", cfg.OllamaModel)),
+		aiAnalysis.BackendOpenAI: aiAnalysis.NewOpenAIAIService(chatConfig(cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.OpenAIModel)),
+		aiAnalysis.BackendDify:   aiAnalysis.NewDifyAIService(c.difyClient, "alert-agent"),
+	}
+
+	// 只有实际使用的模型接入并发限制，扩缩容按这些模型的上限计算容量
+	limits, err := aiAnalysis.ParseModelConcurrency(c.config.AnalysisWorker.ModelConcurrency)
+	if err != nil {
+		c.logger.Warn("invalid model concurrency, model calls are not limited", zap.Error(err))
+	}
+	c.modelLimiter = aiAnalysis.NewModelLimiter(limits)
+
+	defaultService, ok := services[cfg.DefaultBackend]
+	if !ok {
+		c.logger.Warn("unsupported AI backend, using ollama", zap.String("backend", cfg.DefaultBackend))
+		defaultService = services[aiAnalysis.BackendOllama]
+	}
+	defaultService = c.modelLimiter.Wrap(defaultService)
+	typeServices := make(map[analysisDomain.AnalysisType]aiAnalysis.AIService)
+	backends, err := aiAnalysis.ParseTypeBackends(cfg.TypeBackends)
+	if err != nil {
+		c.logger.Warn("invalid AI type backends, using default backend for all types", zap.Error(err))
+	}
+	for analysisType, backend := range backends {
+		typeServices[analysisType] = c.modelLimiter.Wrap(services[backend])
+	}
+	var downgradeService aiAnalysis.AIService
+	if cfg.DowngradeBackend != "" {
+		if downgradeService, ok = services[cfg.DowngradeBackend]; !ok {
+			c.logger.Warn("unsupported AI downgrade backend, downgrade disabled", zap.String("backend", cfg.DowngradeBackend))
+		}
+		downgradeService = c.modelLimiter.Wrap(downgradeService)
+	}
+
+	engineConfig := aiAnalysis.DefaultEngineConfig()
+	if cfg.Timeout > 0 {
+		engineConfig.Timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	if cfg.MaxRetries >= 0 {
+		engineConfig.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.RepairAttempts >= 0 {
+		engineConfig.RepairAttempts = cfg.RepairAttempts
+	}
+	if cfg.MaxPromptLength > 0 {
+		engineConfig.MaxPromptLength = cfg.MaxPromptLength
+	}
+	engineConfig.EnableCache = cfg.CacheEnabled
+	if cfg.CacheTTL > 0 {
+		engineConfig.CacheTTL = time.Duration(cfg.CacheTTL) * time.Second
+	}
+	engineConfig.Budget = c.budgetConfig()
+	// 未启用检索增强时传入 nil 接口，避免引擎持有类型化的空指针
+	var retriever analysisDomain.KnowledgeRetriever
+	if c.knowledgeIndexService != nil {
+		retriever = c.knowledgeIndexService
+	}
+	return aiAnalysis.NewAnalysisEngine(
+		aiAnalysis.NewAIServiceRouter(defaultService, typeServices, downgradeService),
+		aiAnalysis.NewGORMTemplateRepository(c.db),
+		retriever,
+		aiAnalysis.NewRedisResponseCache(c.redisClient),
+		c.usageStore(),
+		engineConfig,
+	)
 }
 
 // initSecurityContainer 初始化安全容器
@@ -152,6 +859,19 @@ func (c *Container) initHTTPRouter() {
 		c.analysisService,
 		nil, // n8nService - 需要实际实现
 		nil, // workflowManager - 需要实际实现
+		c.routingService,
+		c.flapDetector,
+		c.pipelineService,
+		c.incidentService,
+		c.postmortemService,
+		c.alertSearchService,
+		c.analyticsService,
+		c.alertBulkService,
+		c.alertActivityService,
+		c.retentionService,
+		c.usageService,
+		c.feedbackService,
+		c.automationService,
 		c.securityContainer,
 		c.logger,
 	)
@@ -187,6 +907,101 @@ func (c *Container) GetChannelService() channelDomain.Service {
 	return c.channelService
 }
 
+// GetRoutingService 获取路由配置服务
+func (c *Container) GetRoutingService() gatewayDomain.RoutingConfigService {
+	return c.routingService
+}
+
+// GetFlapDetector 获取告警抖动检测器
+func (c *Container) GetFlapDetector() gatewayDomain.FlapDetector {
+	return c.flapDetector
+}
+
+// GetFlapReleaser 获取抖动结束后的通知补发器
+func (c *Container) GetFlapReleaser() *gateway.FlapReleaser {
+	return c.flapReleaser
+}
+
+// GetSmartGateway 获取智能告警网关
+func (c *Container) GetSmartGateway() *gateway.SmartGatewayService {
+	return c.smartGateway
+}
+
+// GetAlertClassifier 获取告警分类服务，未启用时返回 nil
+func (c *Container) GetAlertClassifier() *gateway.AlertClassifierService {
+	return c.classifier
+}
+
+// GetAlertStream 获取告警处理流
+func (c *Container) GetAlertStream() gatewayDomain.AlertStream {
+	return c.alertStream
+}
+
+// GetGatewayMetrics 获取告警网关指标
+func (c *Container) GetGatewayMetrics() *metrics.GatewayMetrics {
+	return c.gatewayMetrics
+}
+
+// GetIncidentService 获取事件服务
+func (c *Container) GetIncidentService() incidentDomain.Service {
+	return c.incidentService
+}
+
+// GetPostmortemService 获取复盘报告服务
+func (c *Container) GetPostmortemService() incidentDomain.PostmortemService {
+	return c.postmortemService
+}
+
+// GetAlertSearchService 获取告警搜索服务
+func (c *Container) GetAlertSearchService() alertDomain.SearchService {
+	return c.alertSearchService
+}
+
+// GetAlertBulkService 获取告警批量操作服务
+func (c *Container) GetAlertBulkService() alertDomain.BulkService {
+	return c.alertBulkService
+}
+
+// GetAlertActivityService 获取告警动态服务
+func (c *Container) GetAlertActivityService() alertDomain.ActivityService {
+	return c.alertActivityService
+}
+
+// GetAnalyticsService 获取告警分析服务
+func (c *Container) GetAnalyticsService() *analyticsApp.Service {
+	return c.analyticsService
+}
+
+// GetRetentionService 获取数据保留服务
+func (c *Container) GetRetentionService() *retentionApp.Service {
+	return c.retentionService
+}
+
+// GetAnalysisQueueReaper 获取分析队列过期租约回收器
+func (c *Container) GetAnalysisQueueReaper() *analysis.QueueReaper {
+	return c.analysisContainer.GetQueueReaper()
+}
+
+// GetWorkerAutoscaler 获取分析工作器自动扩缩容
+func (c *Container) GetWorkerAutoscaler() *analysis.WorkerAutoscaler {
+	return c.workerAutoscaler
+}
+
+// GetKnowledgeIndexService 获取知识索引服务，未启用检索增强时为 nil
+func (c *Container) GetKnowledgeIndexService() *analysis.KnowledgeIndexService {
+	return c.knowledgeIndexService
+}
+
+// GetRuleScheduler 获取规则评估调度器
+func (c *Container) GetRuleScheduler() *ruleApp.Scheduler {
+	return c.ruleScheduler
+}
+
+// GetAutomationRunner 获取运行手册执行器
+func (c *Container) GetAutomationRunner() *automationApp.Runner {
+	return c.automationRunner
+}
+
 // GetHTTPRouter 获取HTTP路由器
 func (c *Container) GetHTTPRouter() *http.Router {
 	return c.router
@@ -0,0 +1,105 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxValuePartition 告警表兜底分区，新月份分区从中拆分
+const MaxValuePartition = "pmax"
+
+// MonthStart 返回所在月份第一天（UTC）
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// PartitionName 月分区名称，如 p202405
+func PartitionName(month time.Time) string {
+	return "p" + MonthStart(month).Format("200601")
+}
+
+// MonthlyPartitionDefs 生成 [from, through] 各月的 RANGE 分区定义，最后附加兜底分区
+func MonthlyPartitionDefs(from, through time.Time) string {
+	var defs []string
+	for month := MonthStart(from); !month.After(MonthStart(through)); month = month.AddDate(0, 1, 0) {
+		defs = append(defs, fmt.Sprintf("PARTITION %s VALUES LESS THAN (TO_DAYS('%s'))",
+			PartitionName(month), month.AddDate(0, 1, 0).Format("2006-01-02")))
+	}
+	defs = append(defs, fmt.Sprintf("PARTITION %s VALUES LESS THAN MAXVALUE", MaxValuePartition))
+	return strings.Join(defs, ",\n\t\t\t")
+}
+
+// AlertPartitions 获取告警表的分区名称，未分区或非 MySQL 时返回空
+func AlertPartitions(db *gorm.DB) ([]string, error) {
+	if db.Dialector.Name() != "mysql" {
+		return nil, nil
+	}
+	var names []string
+	if err := db.Raw(`
+		SELECT PARTITION_NAME FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'alerts' AND PARTITION_NAME IS NOT NULL
+		ORDER BY PARTITION_ORDINAL_POSITION
+	`).Scan(&names).Error; err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// partitionAlertsByMonth 将告警表按创建时间月分区，分区键须包含在主键中
+func partitionAlertsByMonth(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" {
+		return nil
+	}
+	partitions, err := AlertPartitions(db)
+	if err != nil {
+		return err
+	}
+	if len(partitions) > 0 {
+		return nil
+	}
+
+	now := time.Now()
+	from := now
+	var oldest *time.Time
+	if err := db.Raw("SELECT MIN(created_at) FROM alerts").Scan(&oldest).Error; err != nil {
+		return err
+	}
+	if oldest != nil && oldest.Before(from) {
+		from = *oldest
+	}
+
+	if err := db.Exec(`
+		ALTER TABLE alerts
+			MODIFY created_at DATETIME(3) NOT NULL,
+			DROP PRIMARY KEY,
+			ADD PRIMARY KEY (id, created_at)
+	`).Error; err != nil {
+		return err
+	}
+	return db.Exec(fmt.Sprintf(`
+		ALTER TABLE alerts PARTITION BY RANGE (TO_DAYS(created_at)) (
+			%s
+		)
+	`, MonthlyPartitionDefs(from, now.AddDate(0, 3, 0)))).Error
+}
+
+func removeAlertsPartitioning(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" {
+		return nil
+	}
+	partitions, err := AlertPartitions(db)
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		return nil
+	}
+	if err := db.Exec("ALTER TABLE alerts REMOVE PARTITIONING").Error; err != nil {
+		return err
+	}
+	return db.Exec("ALTER TABLE alerts DROP PRIMARY KEY, ADD PRIMARY KEY (id)").Error
+}
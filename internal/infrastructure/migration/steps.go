@@ -131,6 +131,14 @@ func GetAllMigrationSteps() []MigrationStep {
 			Down:        dropIndexesAndConstraints,
 			Checksum:    calculateChecksum("create_indexes_and_constraints"),
 		},
+		{
+			Version:     "v2.0.0-016",
+			Name:        "Partition Alerts Table By Month",
+			Description: "按创建时间对告警表做月分区（仅 MySQL），新月份分区由数据保留任务补齐",
+			Up:          partitionAlertsByMonth,
+			Down:        removeAlertsPartitioning,
+			Checksum:    calculateChecksum("partition_alerts_table_by_month"),
+		},
 	}
 }
 
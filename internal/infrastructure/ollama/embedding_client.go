@@ -0,0 +1,71 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+)
+
+// EmbeddingClient Ollama 向量客户端，与知识库使用相同的 /api/embeddings 接口
+type EmbeddingClient struct {
+	baseURL    string
+	model      string
+	httpClient *http.Client
+}
+
+// NewEmbeddingClient 创建 Ollama 向量客户端
+func NewEmbeddingClient(baseURL, model string, timeout time.Duration) gateway.EmbeddingProvider {
+	return &EmbeddingClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Embed 生成文本向量
+func (c *EmbeddingClient) Embed(ctx context.Context, text string) ([]float32, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"model":  c.model,
+		"prompt": text,
+		"stream": false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/embeddings", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embeddings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Embedding []float32 `json:"embedding"`
+		Error     string    `json:"error,omitempty"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response failed: %w", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("ollama API error: %s", result.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama API returned status %d", resp.StatusCode)
+	}
+
+	return result.Embedding, nil
+}
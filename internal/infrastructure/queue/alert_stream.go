@@ -0,0 +1,406 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// AlertStreamKey 告警处理流
+	AlertStreamKey = "gateway:stream:alerts"
+	// AlertDeadLetterStreamKey 告警死信流
+	AlertDeadLetterStreamKey = "gateway:stream:alerts:dead"
+	// AlertStreamGroup 告警处理消费组
+	AlertStreamGroup = "gateway-pipeline"
+
+	// payloadField 消息体字段
+	payloadField = "payload"
+	// lagScanLimit 计算积压时最多扫描的消息数
+	lagScanLimit = 10000
+)
+
+// RedisAlertStream 基于 Redis Streams 的告警处理流
+type RedisAlertStream struct {
+	client     *redis.Client
+	stream     string
+	deadLetter string
+	group      string
+}
+
+// NewRedisAlertStream 创建告警处理流
+func NewRedisAlertStream(client *redis.Client) *RedisAlertStream {
+	return &RedisAlertStream{
+		client:     client,
+		stream:     AlertStreamKey,
+		deadLetter: AlertDeadLetterStreamKey,
+		group:      AlertStreamGroup,
+	}
+}
+
+// EnsureGroup 确保消费组存在，流不存在时一并创建
+func (s *RedisAlertStream) EnsureGroup(ctx context.Context) error {
+	err := s.client.XGroupCreateMkStream(ctx, s.stream, s.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return nil
+}
+
+// Publish 发布消息
+func (s *RedisAlertStream) Publish(ctx context.Context, msg *gateway.PipelineMessage) (string, error) {
+	if msg.EnqueuedAt.IsZero() {
+		msg.EnqueuedAt = time.Now()
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal pipeline message: %w", err)
+	}
+
+	// 不按长度裁剪，积压期间未投递或待确认的消息不能丢失，已确认的消息由 TrimAcked 清理
+	id, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]interface{}{payloadField: payload},
+	}).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to publish pipeline message: %w", err)
+	}
+
+	msg.ID = id
+	return id, nil
+}
+
+// Read 以消费者身份读取新消息
+func (s *RedisAlertStream) Read(ctx context.Context, consumer string, count int64, block time.Duration) ([]*gateway.PipelineMessage, error) {
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: consumer,
+		Streams:  []string{s.stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read pipeline messages: %w", err)
+	}
+
+	var messages []*gateway.PipelineMessage
+	for _, stream := range streams {
+		for _, entry := range stream.Messages {
+			msg, err := s.decode(ctx, entry)
+			if err != nil {
+				return nil, err
+			}
+			if msg == nil {
+				continue
+			}
+			msg.Deliveries = 1
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// Claim 接管空闲超过 minIdle 的待确认消息，通常来自已崩溃的消费者
+func (s *RedisAlertStream) Claim(ctx context.Context, consumer string, minIdle time.Duration, count int64) ([]*gateway.PipelineMessage, error) {
+	pending, err := s.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: s.stream,
+		Group:  s.group,
+		Idle:   minIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  count,
+	}).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list pending messages: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(pending))
+	deliveries := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		ids = append(ids, p.ID)
+		deliveries[p.ID] = p.RetryCount + 1
+	}
+
+	entries, err := s.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   s.stream,
+		Group:    s.group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending messages: %w", err)
+	}
+
+	messages := make([]*gateway.PipelineMessage, 0, len(entries))
+	for _, entry := range entries {
+		msg, err := s.decode(ctx, entry)
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			continue
+		}
+		msg.Deliveries = deliveries[entry.ID]
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// Ack 确认消息
+func (s *RedisAlertStream) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.client.XAck(ctx, s.stream, s.group, ids...).Err(); err != nil {
+		return fmt.Errorf("failed to ack pipeline messages: %w", err)
+	}
+	return nil
+}
+
+// TrimAcked 删除消费组已确认的消息，保留待确认与未投递的消息，返回删除的消息数
+func (s *RedisAlertStream) TrimAcked(ctx context.Context) (int64, error) {
+	groups, err := s.client.XInfoGroups(ctx, s.stream).Result()
+	if err != nil {
+		if isNoSuchKey(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get consumer group info: %w", err)
+	}
+	lastDelivered := ""
+	for _, group := range groups {
+		if group.Name == s.group {
+			lastDelivered = group.LastDeliveredID
+		}
+	}
+	if lastDelivered == "" || lastDelivered == "0-0" {
+		return 0, nil
+	}
+
+	// 最早的待确认消息之前的消息均已确认；没有待确认消息时最后投递的消息及之前的消息均已确认
+	pending, err := s.client.XPending(ctx, s.stream, s.group).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending summary: %w", err)
+	}
+	minID := nextStreamID(lastDelivered)
+	if pending.Count > 0 {
+		minID = pending.Lower
+	}
+
+	trimmed, err := s.client.XTrimMinID(ctx, s.stream, minID).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to trim acknowledged messages: %w", err)
+	}
+	return trimmed, nil
+}
+
+// DeadLetter 将消息转入死信流并确认原消息
+func (s *RedisAlertStream) DeadLetter(ctx context.Context, msg *gateway.PipelineMessage, stage gateway.PipelineStage, consumer string, cause error) error {
+	deadLetter := &gateway.DeadLetter{
+		Message:  msg,
+		Stage:    stage,
+		FailedAt: time.Now(),
+		Consumer: consumer,
+	}
+	if cause != nil {
+		deadLetter.Error = cause.Error()
+	}
+
+	payload, err := json.Marshal(deadLetter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.deadLetter,
+		Values: map[string]interface{}{payloadField: payload},
+	})
+	pipe.XAck(ctx, s.stream, s.group, msg.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to move message to dead letter stream: %w", err)
+	}
+	return nil
+}
+
+// ListDeadLetters 获取最新的死信消息
+func (s *RedisAlertStream) ListDeadLetters(ctx context.Context, limit int64) ([]*gateway.DeadLetter, error) {
+	entries, err := s.client.XRevRangeN(ctx, s.deadLetter, "+", "-", limit).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	deadLetters := make([]*gateway.DeadLetter, 0, len(entries))
+	for _, entry := range entries {
+		deadLetter, err := decodeDeadLetter(entry)
+		if err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, nil
+}
+
+// GetDeadLetter 获取死信消息
+func (s *RedisAlertStream) GetDeadLetter(ctx context.Context, id string) (*gateway.DeadLetter, error) {
+	entries, err := s.client.XRangeN(ctx, s.deadLetter, id, id, 1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return decodeDeadLetter(entries[0])
+}
+
+// Replay 重新发布死信消息并从死信流删除
+func (s *RedisAlertStream) Replay(ctx context.Context, deadLetter *gateway.DeadLetter) (string, error) {
+	msg := *deadLetter.Message
+	msg.ID = ""
+	msg.Deliveries = 0
+	msg.Replayed++
+
+	id, err := s.Publish(ctx, &msg)
+	if err != nil {
+		return "", err
+	}
+	if err := s.client.XDel(ctx, s.deadLetter, deadLetter.ID).Err(); err != nil {
+		return id, fmt.Errorf("failed to delete replayed dead letter: %w", err)
+	}
+	return id, nil
+}
+
+// Stats 获取处理流统计
+func (s *RedisAlertStream) Stats(ctx context.Context) (*gateway.PipelineStats, error) {
+	stats := &gateway.PipelineStats{
+		Stream: s.stream,
+		Group:  s.group,
+	}
+
+	length, err := s.client.XLen(ctx, s.stream).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream length: %w", err)
+	}
+	stats.Length = length
+
+	deadLetters, err := s.client.XLen(ctx, s.deadLetter).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter stream length: %w", err)
+	}
+	stats.DeadLetters = deadLetters
+
+	groups, err := s.client.XInfoGroups(ctx, s.stream).Result()
+	if err != nil {
+		if isNoSuchKey(err) {
+			return stats, nil
+		}
+		return nil, fmt.Errorf("failed to get consumer group info: %w", err)
+	}
+	for _, group := range groups {
+		if group.Name != s.group {
+			continue
+		}
+		stats.Pending = group.Pending
+		// Redis 7 以下不返回 lag，且删除消息后 lag 可能无法确定，统一按最后投递ID计算
+		lag, err := s.countAfter(ctx, group.LastDeliveredID)
+		if err != nil {
+			return nil, err
+		}
+		stats.Lag = lag
+	}
+
+	consumers, err := s.client.XInfoConsumers(ctx, s.stream, s.group).Result()
+	if err != nil {
+		if isNoSuchKey(err) {
+			return stats, nil
+		}
+		return nil, fmt.Errorf("failed to get consumer info: %w", err)
+	}
+	for _, consumer := range consumers {
+		stats.Consumers = append(stats.Consumers, gateway.ConsumerStats{
+			Name:    consumer.Name,
+			Pending: consumer.Pending,
+			Idle:    consumer.Idle,
+		})
+	}
+
+	return stats, nil
+}
+
+// countAfter 统计指定ID之后的消息数，超过扫描上限时返回上限
+func (s *RedisAlertStream) countAfter(ctx context.Context, id string) (int64, error) {
+	entries, err := s.client.XRangeN(ctx, s.stream, "("+id, "+", lagScanLimit).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count undelivered messages: %w", err)
+	}
+	return int64(len(entries)), nil
+}
+
+// decode 解析流消息，无法解析的消息直接确认丢弃，避免阻塞消费组
+func (s *RedisAlertStream) decode(ctx context.Context, entry redis.XMessage) (*gateway.PipelineMessage, error) {
+	raw, ok := entry.Values[payloadField].(string)
+	var msg gateway.PipelineMessage
+	if !ok || json.Unmarshal([]byte(raw), &msg) != nil {
+		if err := s.Ack(ctx, entry.ID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	msg.ID = entry.ID
+	return &msg, nil
+}
+
+// nextStreamID 获取紧随指定ID之后的消息ID
+func nextStreamID(id string) string {
+	ms, seq, found := strings.Cut(id, "-")
+	if !found {
+		return id
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return id
+	}
+	return fmt.Sprintf("%s-%d", ms, n+1)
+}
+
+// decodeDeadLetter 解析死信消息
+func decodeDeadLetter(entry redis.XMessage) (*gateway.DeadLetter, error) {
+	raw, ok := entry.Values[payloadField].(string)
+	if !ok {
+		return nil, fmt.Errorf("dead letter %s has no payload", entry.ID)
+	}
+	var deadLetter gateway.DeadLetter
+	if err := json.Unmarshal([]byte(raw), &deadLetter); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal dead letter %s: %w", entry.ID, err)
+	}
+	deadLetter.ID = entry.ID
+	return &deadLetter, nil
+}
+
+// isNoSuchKey 判断是否为流或消费组不存在的错误
+func isNoSuchKey(err error) bool {
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		msg := redisErr.Error()
+		return strings.Contains(msg, "no such key") || strings.Contains(msg, "NOGROUP")
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlertStream(t *testing.T) (*RedisAlertStream, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	stream := NewRedisAlertStream(client)
+	require.NoError(t, stream.EnsureGroup(context.Background()))
+	return stream, mr
+}
+
+func publishTestAlert(t *testing.T, stream *RedisAlertStream, recordID string) string {
+	id, err := stream.Publish(context.Background(), &gateway.PipelineMessage{
+		RecordID: recordID,
+		Alert:    &model.Alert{Name: "HighCPU", Level: model.AlertLevelHigh},
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func TestRedisAlertStream_ReadAndAck(t *testing.T) {
+	stream, _ := newTestAlertStream(t)
+	ctx := context.Background()
+
+	// 重复创建消费组不报错
+	require.NoError(t, stream.EnsureGroup(ctx))
+
+	id := publishTestAlert(t, stream, "record-1")
+
+	messages, err := stream.Read(ctx, "worker-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, id, messages[0].ID)
+	assert.Equal(t, "record-1", messages[0].RecordID)
+	assert.Equal(t, "HighCPU", messages[0].Alert.Name)
+	assert.Equal(t, int64(1), messages[0].Deliveries)
+
+	stats, err := stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Pending)
+	assert.Equal(t, int64(0), stats.Lag)
+
+	require.NoError(t, stream.Ack(ctx, id))
+	stats, err = stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Pending)
+}
+
+func TestRedisAlertStream_ClaimFromCrashedConsumer(t *testing.T) {
+	stream, mr := newTestAlertStream(t)
+	ctx := context.Background()
+
+	id := publishTestAlert(t, stream, "record-1")
+
+	// worker-1 读取后崩溃，未确认
+	_, err := stream.Read(ctx, "worker-1", 10, 0)
+	require.NoError(t, err)
+
+	// 未达到空闲时长时不能接管
+	claimed, err := stream.Claim(ctx, "worker-2", time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	mr.SetTime(time.Now().Add(2 * time.Minute))
+	claimed, err = stream.Claim(ctx, "worker-2", time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, id, claimed[0].ID)
+	assert.Equal(t, int64(2), claimed[0].Deliveries)
+
+	stats, err := stream.Stats(ctx)
+	require.NoError(t, err)
+	consumers := make(map[string]int64)
+	for _, consumer := range stats.Consumers {
+		consumers[consumer.Name] = consumer.Pending
+	}
+	assert.Equal(t, int64(0), consumers["worker-1"])
+	assert.Equal(t, int64(1), consumers["worker-2"])
+}
+
+func TestRedisAlertStream_DeadLetterAndReplay(t *testing.T) {
+	stream, _ := newTestAlertStream(t)
+	ctx := context.Background()
+
+	publishTestAlert(t, stream, "record-1")
+	messages, err := stream.Read(ctx, "worker-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	require.NoError(t, stream.DeadLetter(ctx, messages[0], gateway.StageRouting, "worker-1", errors.New("channel unavailable")))
+
+	stats, err := stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Pending)
+	assert.Equal(t, int64(1), stats.DeadLetters)
+
+	deadLetters, err := stream.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, gateway.StageRouting, deadLetters[0].Stage)
+	assert.Equal(t, "channel unavailable", deadLetters[0].Error)
+	assert.Equal(t, "record-1", deadLetters[0].Message.RecordID)
+
+	deadLetter, err := stream.GetDeadLetter(ctx, deadLetters[0].ID)
+	require.NoError(t, err)
+	require.NotNil(t, deadLetter)
+
+	missing, err := stream.GetDeadLetter(ctx, "1-1")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	newID, err := stream.Replay(ctx, deadLetter)
+	require.NoError(t, err)
+	assert.NotEqual(t, messages[0].ID, newID)
+
+	replayed, err := stream.Read(ctx, "worker-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, newID, replayed[0].ID)
+	assert.Equal(t, 1, replayed[0].Replayed)
+
+	stats, err = stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.DeadLetters)
+}
+
+func TestRedisAlertStream_Lag(t *testing.T) {
+	stream, _ := newTestAlertStream(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		publishTestAlert(t, stream, "")
+	}
+	_, err := stream.Read(ctx, "worker-1", 1, 0)
+	require.NoError(t, err)
+
+	stats, err := stream.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Length)
+	assert.Equal(t, int64(1), stats.Pending)
+	assert.Equal(t, int64(2), stats.Lag)
+}
+
+func TestRedisAlertStream_TrimAckedKeepsPendingAndUndelivered(t *testing.T) {
+	stream, mr := newTestAlertStream(t)
+	ctx := context.Background()
+
+	first := publishTestAlert(t, stream, "record-1")
+	second := publishTestAlert(t, stream, "record-2")
+	third := publishTestAlert(t, stream, "record-3")
+
+	// 未投递的消息不会被裁剪
+	trimmed, err := stream.TrimAcked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), trimmed)
+
+	messages, err := stream.Read(ctx, "worker-1", 2, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	// 只确认第二条，第一条仍待确认，第三条未投递
+	require.NoError(t, stream.Ack(ctx, second))
+	trimmed, err = stream.TrimAcked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), trimmed)
+
+	require.NoError(t, stream.Ack(ctx, first))
+	trimmed, err = stream.TrimAcked(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), trimmed)
+
+	remaining, err := mr.Stream(AlertStreamKey)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, third, remaining[0].ID)
+
+	messages, err = stream.Read(ctx, "worker-1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "record-3", messages[0].RecordID)
+}
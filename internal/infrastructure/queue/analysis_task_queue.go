@@ -3,12 +3,14 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"alert_agent/internal/domain/analysis"
 	"alert_agent/internal/pkg/logger"
+	sharedErrors "alert_agent/internal/shared/errors"
 
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -16,27 +18,237 @@ import (
 
 // Redis键名常量
 const (
-	AnalysisTaskQueueKey     = "analysis:queue:tasks"          // 普通任务队列
-	AnalysisPriorityQueueKey = "analysis:queue:priority"       // 优先级任务队列
-	AnalysisProcessingSetKey = "analysis:processing"            // 处理中任务集合
-	AnalysisTaskDataKey      = "analysis:task:%s"              // 任务数据键模板
-	AnalysisQueueStatsKey    = "analysis:queue:stats"          // 队列统计信息
+	AnalysisTaskQueueKey     = "analysis:queue:tasks"        // 普通任务队列
+	AnalysisPriorityQueueKey = "analysis:queue:priority"     // 优先级任务队列
+	AnalysisLeaseSetKey      = "analysis:queue:leases"       // 处理中任务租约，分数为租约到期时间（毫秒）
+	AnalysisAttemptsKey      = "analysis:queue:attempts"     // 任务投递次数
+	AnalysisPrioritiesKey    = "analysis:queue:priorities"   // 任务优先级，重新入队时使用
+	AnalysisDeadLetterKey    = "analysis:queue:dead"         // 死信任务，分数为进入死信的时间（毫秒）
+	AnalysisDeadErrorsKey    = "analysis:queue:dead:errors"  // 死信任务失败原因
+	AnalysisIdempotencyKey   = "analysis:idempotency:%s"     // 幂等键模板
+	AnalysisIdemOwnersKey    = "analysis:idempotency:owners" // 任务占用的幂等键
+	AnalysisTaskDataKey      = "analysis:task:%s"            // 任务数据键模板
+	AnalysisQueueStatsKey    = "analysis:queue:stats"        // 队列统计信息
+
+	// taskDataTTL 任务数据有效期，死信任务的数据不过期
+	taskDataTTL = 24 * time.Hour
+	// popPollInterval 阻塞出队时的轮询间隔
+	popPollInterval = 200 * time.Millisecond
 )
 
-// AnalysisTaskQueueImpl Redis分析任务队列实现
+// queueScriptPrelude 队列脚本公共部分
+// KEYS: 1 优先级队列 2 普通队列 3 租约 4 投递次数 5 优先级 6 死信 7 死信原因 8 幂等键归属 9 统计
+// ARGV: 1 当前时间（毫秒） 2 任务数据键前缀
+const queueScriptPrelude = `
+local function requeue(id)
+	local priority = tonumber(redis.call('HGET', KEYS[5], id) or '0')
+	if priority > 0 then
+		redis.call('ZADD', KEYS[1], priority, id)
+	else
+		redis.call('LPUSH', KEYS[2], id)
+	end
+end
+
+local function release(id)
+	local key = redis.call('HGET', KEYS[8], id)
+	if key then
+		if redis.call('GET', key) == id then
+			redis.call('DEL', key)
+		end
+		redis.call('HDEL', KEYS[8], id)
+	end
+end
+
+local function bury(id, reason)
+	redis.call('ZADD', KEYS[6], ARGV[1], id)
+	redis.call('HSET', KEYS[7], id, reason)
+	redis.call('PERSIST', ARGV[2] .. id)
+	release(id)
+	redis.call('HINCRBY', KEYS[9], 'total_failed', 1)
+end
+`
+
+var (
+	// popScript 从优先级队列或普通队列取出任务并写入租约
+	// ARGV: 3 租约到期时间
+	popScript = redis.NewScript(queueScriptPrelude + `
+local id
+local top = redis.call('ZPOPMAX', KEYS[1])
+if top[1] then
+	id = top[1]
+else
+	id = redis.call('RPOP', KEYS[2])
+end
+if not id then
+	return false
+end
+redis.call('ZADD', KEYS[3], ARGV[3], id)
+local attempts = redis.call('HINCRBY', KEYS[4], id, 1)
+redis.call('HINCRBY', KEYS[9], 'total_popped', 1)
+redis.call('HSET', KEYS[9], 'last_pop_time', math.floor(tonumber(ARGV[1]) / 1000))
+return {id, attempts}
+`)
+
+	// finishScript 从队列、租约与死信中移除任务并释放幂等键
+	// ARGV: 3 任务ID 4 统计字段
+	finishScript = redis.NewScript(queueScriptPrelude + `
+local id = ARGV[3]
+redis.call('ZREM', KEYS[1], id)
+redis.call('LREM', KEYS[2], 0, id)
+redis.call('ZREM', KEYS[3], id)
+redis.call('HDEL', KEYS[4], id)
+redis.call('HDEL', KEYS[5], id)
+redis.call('ZREM', KEYS[6], id)
+redis.call('HDEL', KEYS[7], id)
+release(id)
+redis.call('DEL', ARGV[2] .. id)
+redis.call('HINCRBY', KEYS[9], ARGV[4], 1)
+return 1
+`)
+
+	// nackScript 释放租约，未超过最大投递次数时重新入队，否则转入死信；租约已失效返回 -1
+	// ARGV: 3 任务ID 4 最大投递次数 5 失败原因
+	nackScript = redis.NewScript(queueScriptPrelude + `
+local id = ARGV[3]
+if redis.call('ZREM', KEYS[3], id) == 0 then
+	return -1
+end
+local attempts = tonumber(redis.call('HGET', KEYS[4], id) or '0')
+if attempts >= tonumber(ARGV[4]) then
+	bury(id, ARGV[5])
+	return 1
+end
+requeue(id)
+return 0
+`)
+
+	// extendScript 续约仍持有租约的任务
+	// KEYS: 1 租约 ARGV: 1 任务ID 2 新的租约到期时间
+	extendScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+	// reapScript 重新投递租约过期的任务，超过最大投递次数的转入死信
+	// ARGV: 3 最大投递次数 4 单次处理数量
+	reapScript = redis.NewScript(queueScriptPrelude + `
+local ids = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[4]))
+local requeued, buried = 0, 0
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[3], id)
+	local attempts = tonumber(redis.call('HGET', KEYS[4], id) or '0')
+	if attempts >= tonumber(ARGV[3]) then
+		bury(id, 'lease expired after ' .. attempts .. ' attempts')
+		buried = buried + 1
+	else
+		requeue(id)
+		requeued = requeued + 1
+	end
+end
+return {requeued, buried}
+`)
+
+	// requeueDeadScript 死信任务重新入队并重置投递次数
+	// ARGV: 3 任务ID 4 任务数据有效期（秒）
+	requeueDeadScript = redis.NewScript(queueScriptPrelude + `
+local id = ARGV[3]
+if redis.call('ZREM', KEYS[6], id) == 0 then
+	return 0
+end
+redis.call('HDEL', KEYS[7], id)
+redis.call('HDEL', KEYS[4], id)
+redis.call('EXPIRE', ARGV[2] .. id, ARGV[4])
+requeue(id)
+return 1
+`)
+
+	// priorityScript 更新任务数据与优先级，只有就绪队列中的任务按新优先级调整位置；
+	// 处理中与死信任务不重新入队，之后重新入队时使用新优先级，死信任务的数据保持不过期。返回任务是否在就绪队列中
+	// ARGV: 3 任务ID 4 新优先级 5 任务数据 6 任务数据有效期（秒）
+	priorityScript = redis.NewScript(queueScriptPrelude + `
+local id = ARGV[3]
+if redis.call('ZSCORE', KEYS[6], id) then
+	redis.call('SET', ARGV[2] .. id, ARGV[5])
+else
+	redis.call('SET', ARGV[2] .. id, ARGV[5], 'EX', ARGV[6])
+end
+redis.call('HSET', KEYS[5], id, ARGV[4])
+local ready = redis.call('ZREM', KEYS[1], id) + redis.call('LREM', KEYS[2], 0, id)
+if ready > 0 then
+	requeue(id)
+	return 1
+end
+return 0
+`)
+
+	// claimScript 占用幂等键，已被占用时返回归属的任务ID
+	// KEYS: 1 幂等键归属 ARGV: 1 幂等键 2 任务ID 3 有效期（毫秒）
+	claimScript = redis.NewScript(`
+if redis.call('SET', ARGV[1], ARGV[2], 'NX', 'PX', ARGV[3]) then
+	redis.call('HSET', KEYS[1], ARGV[2], ARGV[1])
+	return ARGV[2]
+end
+return redis.call('GET', ARGV[1])
+`)
+)
+
+// AnalysisTaskQueueImpl Redis分析任务队列实现，出队任务持有租约直到确认
 type AnalysisTaskQueueImpl struct {
 	client *redis.Client
+	config analysis.QueueConfig
 	logger *zap.Logger
+	now    func() time.Time
 }
 
-// NewAnalysisTaskQueue 创建分析任务队列实例
-func NewAnalysisTaskQueue(client *redis.Client) analysis.AnalysisTaskQueue {
+// NewAnalysisTaskQueue 创建分析任务队列实例，配置为零值的项使用默认值
+func NewAnalysisTaskQueue(client *redis.Client, config analysis.QueueConfig) analysis.ReliableTaskQueue {
+	defaults := analysis.DefaultQueueConfig()
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = defaults.VisibilityTimeout
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.ReapInterval <= 0 {
+		config.ReapInterval = defaults.ReapInterval
+	}
+	if config.ReapBatchSize <= 0 {
+		config.ReapBatchSize = defaults.ReapBatchSize
+	}
+	if config.IdempotencyTTL <= 0 {
+		config.IdempotencyTTL = defaults.IdempotencyTTL
+	}
 	return &AnalysisTaskQueueImpl{
 		client: client,
+		config: config,
 		logger: logger.L.Named("analysis-task-queue"),
+		now:    time.Now,
+	}
+}
+
+// scriptKeys 队列脚本使用的键
+func (q *AnalysisTaskQueueImpl) scriptKeys() []string {
+	return []string{
+		AnalysisPriorityQueueKey,
+		AnalysisTaskQueueKey,
+		AnalysisLeaseSetKey,
+		AnalysisAttemptsKey,
+		AnalysisPrioritiesKey,
+		AnalysisDeadLetterKey,
+		AnalysisDeadErrorsKey,
+		AnalysisIdemOwnersKey,
+		AnalysisQueueStatsKey,
 	}
 }
 
+// scriptArgs 队列脚本参数，前两项为当前时间与任务数据键前缀
+func (q *AnalysisTaskQueueImpl) scriptArgs(args ...interface{}) []interface{} {
+	return append([]interface{}{q.now().UnixMilli(), fmt.Sprintf(AnalysisTaskDataKey, "")}, args...)
+}
+
 // Push 推送任务到队列
 func (q *AnalysisTaskQueueImpl) Push(ctx context.Context, task *analysis.AnalysisTask) error {
 	if task == nil {
@@ -46,7 +258,7 @@ func (q *AnalysisTaskQueueImpl) Push(ctx context.Context, task *analysis.Analysi
 	// 序列化任务数据
 	taskData, err := json.Marshal(task)
 	if err != nil {
-		q.logger.Error("Failed to marshal task", 
+		q.logger.Error("Failed to marshal task",
 			zap.String("task_id", task.ID),
 			zap.Error(err))
 		return fmt.Errorf("failed to marshal task: %w", err)
@@ -55,9 +267,15 @@ func (q *AnalysisTaskQueueImpl) Push(ctx context.Context, task *analysis.Analysi
 	// 使用事务确保原子性
 	pipe := q.client.TxPipeline()
 
-	// 保存任务数据
+	// 保存任务数据与优先级
 	taskKey := fmt.Sprintf(AnalysisTaskDataKey, task.ID)
-	pipe.Set(ctx, taskKey, taskData, 24*time.Hour) // 24小时过期
+	pipe.Set(ctx, taskKey, taskData, taskDataTTL)
+	pipe.HSet(ctx, AnalysisPrioritiesKey, task.ID, task.Priority)
+
+	// 重试的任务重新计算投递次数并移出死信
+	pipe.HDel(ctx, AnalysisAttemptsKey, task.ID)
+	pipe.ZRem(ctx, AnalysisDeadLetterKey, task.ID)
+	pipe.HDel(ctx, AnalysisDeadErrorsKey, task.ID)
 
 	// 根据优先级决定队列
 	if task.Priority > 0 {
@@ -73,16 +291,16 @@ func (q *AnalysisTaskQueueImpl) Push(ctx context.Context, task *analysis.Analysi
 
 	// 更新统计信息
 	pipe.HIncrBy(ctx, AnalysisQueueStatsKey, "total_pushed", 1)
-	pipe.HSet(ctx, AnalysisQueueStatsKey, "last_push_time", time.Now().Unix())
+	pipe.HSet(ctx, AnalysisQueueStatsKey, "last_push_time", q.now().Unix())
 
 	if _, err := pipe.Exec(ctx); err != nil {
-		q.logger.Error("Failed to push task", 
+		q.logger.Error("Failed to push task",
 			zap.String("task_id", task.ID),
 			zap.Error(err))
 		return fmt.Errorf("failed to push task: %w", err)
 	}
 
-	q.logger.Debug("Task pushed to queue", 
+	q.logger.Debug("Task pushed to queue",
 		zap.String("task_id", task.ID),
 		zap.Int("priority", task.Priority))
 	return nil
@@ -93,65 +311,206 @@ func (q *AnalysisTaskQueueImpl) Pop(ctx context.Context) (*analysis.AnalysisTask
 	return q.PopWithTimeout(ctx, 0)
 }
 
-// PopWithTimeout 带超时的获取任务
+// PopWithTimeout 带超时的获取任务，取出的任务持有租约直到 Ack 或 Nack
+// 优先级队列与普通队列需在同一脚本中原子地移入租约集合，因此阻塞等待通过轮询实现
 func (q *AnalysisTaskQueueImpl) PopWithTimeout(ctx context.Context, timeout time.Duration) (*analysis.AnalysisTask, error) {
-	var taskID string
-	var err error
+	deadline := time.Now().Add(timeout)
+	for {
+		task, found, err := q.popOnce(ctx)
+		if err != nil || found {
+			return task, err
+		}
+		if timeout <= 0 || !time.Now().Before(deadline) {
+			return nil, nil // 无任务
+		}
 
-	// 首先尝试从优先级队列获取
-	result := q.client.ZPopMax(ctx, AnalysisPriorityQueueKey, 1)
-	if result.Err() == nil && len(result.Val()) > 0 {
-		taskID = result.Val()[0].Member.(string)
-	} else {
-		// 从普通队列获取
-		if timeout > 0 {
-			// 阻塞式获取
-			result := q.client.BRPop(ctx, timeout, AnalysisTaskQueueKey)
-			if result.Err() != nil {
-				if result.Err() == redis.Nil {
-					return nil, nil // 超时，无任务
-				}
-				return nil, fmt.Errorf("failed to pop task: %w", result.Err())
-			}
-			taskID = result.Val()[1] // BRPop返回[key, value]
-		} else {
-			// 非阻塞式获取
-			result := q.client.RPop(ctx, AnalysisTaskQueueKey)
-			if result.Err() != nil {
-				if result.Err() == redis.Nil {
-					return nil, nil // 队列为空
-				}
-				return nil, fmt.Errorf("failed to pop task: %w", result.Err())
-			}
-			taskID = result.Val()
+		wait := popPollInterval
+		if remaining := time.Until(deadline); remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(wait):
 		}
 	}
+}
 
-	if taskID == "" {
-		return nil, nil // 无任务
+// popOnce 尝试取出一个任务，found 表示队列中有任务
+func (q *AnalysisTaskQueueImpl) popOnce(ctx context.Context) (*analysis.AnalysisTask, bool, error) {
+	leaseUntil := q.now().Add(q.config.VisibilityTimeout).UnixMilli()
+	result, err := popScript.Run(ctx, q.client, q.scriptKeys(), q.scriptArgs(leaseUntil)...).Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		if ctx.Err() != nil {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to pop task: %w", err)
 	}
+	taskID, _ := result[0].(string)
+	attempts, _ := result[1].(int64)
 
-	// 获取任务数据
+	// 获取任务数据，数据已过期的任务直接移除
 	task, err := q.getTaskData(ctx, taskID)
 	if err != nil {
-		return nil, err
+		q.logger.Warn("Dropping task without data", zap.String("task_id", taskID), zap.Error(err))
+		if removeErr := q.Remove(ctx, taskID); removeErr != nil {
+			return nil, true, removeErr
+		}
+		return nil, false, nil
 	}
+	if task.Metadata == nil {
+		task.Metadata = make(map[string]interface{})
+	}
+	task.Metadata["queue_attempts"] = attempts
 
-	// 将任务添加到处理集合
-	pipe := q.client.TxPipeline()
-	pipe.SAdd(ctx, AnalysisProcessingSetKey, taskID)
-	pipe.HIncrBy(ctx, AnalysisQueueStatsKey, "total_popped", 1)
-	pipe.HSet(ctx, AnalysisQueueStatsKey, "last_pop_time", time.Now().Unix())
+	q.logger.Debug("Task popped from queue",
+		zap.String("task_id", taskID),
+		zap.Int64("attempts", attempts))
+	return task, true, nil
+}
 
-	if _, err := pipe.Exec(ctx); err != nil {
-		q.logger.Error("Failed to update processing state", 
+// Ack 确认任务处理完成
+func (q *AnalysisTaskQueueImpl) Ack(ctx context.Context, taskID string) error {
+	if err := finishScript.Run(ctx, q.client, q.scriptKeys(), q.scriptArgs(taskID, "total_completed")...).Err(); err != nil {
+		q.logger.Error("Failed to ack task",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+		return fmt.Errorf("failed to ack task: %w", err)
+	}
+
+	q.logger.Debug("Task acked", zap.String("task_id", taskID))
+	return nil
+}
+
+// Nack 任务处理失败，未超过最大投递次数时重新入队，否则转入死信
+func (q *AnalysisTaskQueueImpl) Nack(ctx context.Context, taskID string, cause error) (bool, error) {
+	reason := "unknown error"
+	if cause != nil {
+		reason = cause.Error()
+	}
+	result, err := nackScript.Run(ctx, q.client, q.scriptKeys(), q.scriptArgs(taskID, q.config.MaxAttempts, reason)...).Int64()
+	if err != nil {
+		q.logger.Error("Failed to nack task",
 			zap.String("task_id", taskID),
 			zap.Error(err))
-		// 不返回错误，因为任务已经获取
+		return false, fmt.Errorf("failed to nack task: %w", err)
+	}
+	if result < 0 {
+		return false, analysis.ErrLeaseLost
 	}
 
-	q.logger.Debug("Task popped from queue", zap.String("task_id", taskID))
-	return task, nil
+	deadLettered := result == 1
+	q.logger.Debug("Task nacked",
+		zap.String("task_id", taskID),
+		zap.Bool("dead_lettered", deadLettered))
+	return deadLettered, nil
+}
+
+// ExtendLease 续约处理中的任务
+func (q *AnalysisTaskQueueImpl) ExtendLease(ctx context.Context, taskID string) error {
+	leaseUntil := q.now().Add(q.config.VisibilityTimeout).UnixMilli()
+	extended, err := extendScript.Run(ctx, q.client, []string{AnalysisLeaseSetKey}, taskID, leaseUntil).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to extend task lease: %w", err)
+	}
+	if extended == 0 {
+		return analysis.ErrLeaseLost
+	}
+	return nil
+}
+
+// RequeueExpired 重新投递租约过期的任务
+func (q *AnalysisTaskQueueImpl) RequeueExpired(ctx context.Context) (int, int, error) {
+	result, err := reapScript.Run(ctx, q.client, q.scriptKeys(), q.scriptArgs(q.config.MaxAttempts, q.config.ReapBatchSize)...).Int64Slice()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to requeue expired tasks: %w", err)
+	}
+
+	return int(result[0]), int(result[1]), nil
+}
+
+// ClaimIdempotencyKey 为任务占用幂等键，返回键当前归属的任务ID
+func (q *AnalysisTaskQueueImpl) ClaimIdempotencyKey(ctx context.Context, key, taskID string) (string, error) {
+	redisKey := fmt.Sprintf(AnalysisIdempotencyKey, key)
+	// 键可能恰好在 SET 与 GET 之间过期，此时重试一次
+	for attempt := 0; attempt < 2; attempt++ {
+		owner, err := claimScript.Run(ctx, q.client, []string{AnalysisIdemOwnersKey},
+			redisKey, taskID, q.config.IdempotencyTTL.Milliseconds()).Text()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		return owner, nil
+	}
+	return "", fmt.Errorf("failed to claim idempotency key: %s", key)
+}
+
+// ListDeadLetters 获取死信任务，按进入死信的时间倒序
+func (q *AnalysisTaskQueueImpl) ListDeadLetters(ctx context.Context, limit int64) ([]*analysis.DeadLetterTask, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	entries, err := q.client.ZRevRangeWithScores(ctx, AnalysisDeadLetterKey, 0, limit-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	if len(entries) == 0 {
+		return []*analysis.DeadLetterTask{}, nil
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.Member.(string))
+	}
+	reasons, err := q.client.HMGet(ctx, AnalysisDeadErrorsKey, ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter errors: %w", err)
+	}
+	attempts, err := q.client.HMGet(ctx, AnalysisAttemptsKey, ids...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter attempts: %w", err)
+	}
+
+	deadLetters := make([]*analysis.DeadLetterTask, 0, len(entries))
+	for i, entry := range entries {
+		deadLetter := &analysis.DeadLetterTask{
+			FailedAt: time.UnixMilli(int64(entry.Score)),
+		}
+		if reason, ok := reasons[i].(string); ok {
+			deadLetter.Error = reason
+		}
+		if count, ok := attempts[i].(string); ok {
+			deadLetter.Attempts, _ = strconv.ParseInt(count, 10, 64)
+		}
+		// 数据缺失时仍返回任务ID，便于排查
+		task, err := q.getTaskData(ctx, ids[i])
+		if err != nil {
+			task = &analysis.AnalysisTask{ID: ids[i]}
+		}
+		deadLetter.Task = task
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	return deadLetters, nil
+}
+
+// RequeueDeadLetter 将死信任务重新入队并重置投递次数
+func (q *AnalysisTaskQueueImpl) RequeueDeadLetter(ctx context.Context, taskID string) error {
+	requeued, err := requeueDeadScript.Run(ctx, q.client, q.scriptKeys(),
+		q.scriptArgs(taskID, int64(taskDataTTL.Seconds()))...).Int64()
+	if err != nil {
+		return fmt.Errorf("failed to requeue dead letter: %w", err)
+	}
+	if requeued == 0 {
+		return sharedErrors.NewNotFoundError("dead letter " + taskID)
+	}
+
+	q.logger.Info("Dead letter requeued", zap.String("task_id", taskID))
+	return nil
 }
 
 // Peek 查看队列头部任务但不移除
@@ -207,15 +566,14 @@ func (q *AnalysisTaskQueueImpl) Clear(ctx context.Context) error {
 	pipe := q.client.TxPipeline()
 
 	// 清空所有相关键
-	pipe.Del(ctx, AnalysisTaskQueueKey)
-	pipe.Del(ctx, AnalysisPriorityQueueKey)
-	pipe.Del(ctx, AnalysisProcessingSetKey)
-	pipe.Del(ctx, AnalysisQueueStatsKey)
+	pipe.Del(ctx, q.scriptKeys()...)
 
-	// 清空所有任务数据（通过模式匹配）
-	keys := q.client.Keys(ctx, fmt.Sprintf(AnalysisTaskDataKey, "*"))
-	if keys.Err() == nil && len(keys.Val()) > 0 {
-		pipe.Del(ctx, keys.Val()...)
+	// 清空所有任务数据与幂等键（通过模式匹配）
+	for _, pattern := range []string{AnalysisTaskDataKey, AnalysisIdempotencyKey} {
+		keys := q.client.Keys(ctx, fmt.Sprintf(pattern, "*"))
+		if keys.Err() == nil && len(keys.Val()) > 0 {
+			pipe.Del(ctx, keys.Val()...)
+		}
 	}
 
 	if _, err := pipe.Exec(ctx); err != nil {
@@ -235,12 +593,16 @@ func (q *AnalysisTaskQueueImpl) GetStatus(ctx context.Context) (*analysis.QueueS
 		return nil, err
 	}
 
-	// 获取处理中的任务数量
-	processingSize := q.client.SCard(ctx, AnalysisProcessingSetKey)
+	// 获取处理中与死信任务数量
+	processingSize := q.client.ZCard(ctx, AnalysisLeaseSetKey)
 	if processingSize.Err() != nil {
 		q.logger.Error("Failed to get processing set size", zap.Error(processingSize.Err()))
 		return nil, fmt.Errorf("failed to get processing set size: %w", processingSize.Err())
 	}
+	deadLetterSize := q.client.ZCard(ctx, AnalysisDeadLetterKey)
+	if deadLetterSize.Err() != nil {
+		return nil, fmt.Errorf("failed to get dead letter size: %w", deadLetterSize.Err())
+	}
 
 	// 获取统计信息
 	stats := q.client.HGetAll(ctx, AnalysisQueueStatsKey)
@@ -272,31 +634,18 @@ func (q *AnalysisTaskQueueImpl) GetStatus(ctx context.Context) (*analysis.QueueS
 		CompletedCount:  totalCompleted,
 		FailedCount:     totalFailed,
 		TotalCount:      totalPushed,
+		DeadLetterCount: deadLetterSize.Val(),
 		OldestTask:      oldestTask,
-		LastUpdated:     time.Now(),
+		LastUpdated:     q.now(),
 	}
 
 	return status, nil
 }
 
-// Remove 移除指定任务
+// Remove 从队列、租约与死信中移除指定任务
 func (q *AnalysisTaskQueueImpl) Remove(ctx context.Context, taskID string) error {
-	pipe := q.client.TxPipeline()
-
-	// 从所有可能的位置移除任务
-	pipe.LRem(ctx, AnalysisTaskQueueKey, 0, taskID)           // 从普通队列移除
-	pipe.ZRem(ctx, AnalysisPriorityQueueKey, taskID)         // 从优先级队列移除
-	pipe.SRem(ctx, AnalysisProcessingSetKey, taskID)         // 从处理集合移除
-
-	// 删除任务数据
-	taskKey := fmt.Sprintf(AnalysisTaskDataKey, taskID)
-	pipe.Del(ctx, taskKey)
-
-	// 更新统计信息
-	pipe.HIncrBy(ctx, AnalysisQueueStatsKey, "total_removed", 1)
-
-	if _, err := pipe.Exec(ctx); err != nil {
-		q.logger.Error("Failed to remove task", 
+	if err := finishScript.Run(ctx, q.client, q.scriptKeys(), q.scriptArgs(taskID, "total_removed")...).Err(); err != nil {
+		q.logger.Error("Failed to remove task",
 			zap.String("task_id", taskID),
 			zap.Error(err))
 		return fmt.Errorf("failed to remove task: %w", err)
@@ -333,32 +682,10 @@ func (q *AnalysisTaskQueueImpl) UpdatePriority(ctx context.Context, taskID strin
 		return fmt.Errorf("failed to marshal updated task: %w", err)
 	}
 
-	pipe := q.client.TxPipeline()
-
-	// 更新任务数据
-	pipe.Set(ctx, taskKey, taskData, 24*time.Hour)
-
-	// 如果任务在队列中，需要重新调整位置
-	if oldPriority > 0 {
-		// 从优先级队列移除
-		pipe.ZRem(ctx, AnalysisPriorityQueueKey, taskID)
-	} else {
-		// 从普通队列移除
-		pipe.LRem(ctx, AnalysisTaskQueueKey, 0, taskID)
-	}
-
-	// 根据新优先级重新添加
-	if priority > 0 {
-		pipe.ZAdd(ctx, AnalysisPriorityQueueKey, redis.Z{
-			Score:  float64(priority),
-			Member: taskID,
-		})
-	} else {
-		pipe.LPush(ctx, AnalysisTaskQueueKey, taskID)
-	}
-
-	if _, err := pipe.Exec(ctx); err != nil {
-		q.logger.Error("Failed to update task priority", 
+	ready, err := priorityScript.Run(ctx, q.client, q.scriptKeys(),
+		q.scriptArgs(taskID, priority, taskData, int64(taskDataTTL.Seconds()))...).Int64()
+	if err != nil {
+		q.logger.Error("Failed to update task priority",
 			zap.String("task_id", taskID),
 			zap.Int("old_priority", oldPriority),
 			zap.Int("new_priority", priority),
@@ -366,81 +693,17 @@ func (q *AnalysisTaskQueueImpl) UpdatePriority(ctx context.Context, taskID strin
 		return fmt.Errorf("failed to update task priority: %w", err)
 	}
 
-	q.logger.Debug("Task priority updated", 
+	q.logger.Debug("Task priority updated",
 		zap.String("task_id", taskID),
 		zap.Int("old_priority", oldPriority),
-		zap.Int("new_priority", priority))
+		zap.Int("new_priority", priority),
+		zap.Bool("ready", ready == 1))
 	return nil
 }
 
-// CompleteTask 标记任务完成（从处理集合中移除）
-func (q *AnalysisTaskQueueImpl) CompleteTask(ctx context.Context, taskID string) error {
-	pipe := q.client.TxPipeline()
-
-	// 从处理集合中移除
-	pipe.SRem(ctx, AnalysisProcessingSetKey, taskID)
-
-	// 删除任务数据
-	taskKey := fmt.Sprintf(AnalysisTaskDataKey, taskID)
-	pipe.Del(ctx, taskKey)
-
-	// 更新统计信息
-	pipe.HIncrBy(ctx, AnalysisQueueStatsKey, "total_completed", 1)
-
-	if _, err := pipe.Exec(ctx); err != nil {
-		q.logger.Error("Failed to complete task", 
-			zap.String("task_id", taskID),
-			zap.Error(err))
-		return fmt.Errorf("failed to complete task: %w", err)
-	}
-
-	q.logger.Debug("Task completed", zap.String("task_id", taskID))
-	return nil
-}
-
-// FailTask 标记任务失败
-func (q *AnalysisTaskQueueImpl) FailTask(ctx context.Context, taskID string) error {
-	pipe := q.client.TxPipeline()
-
-	// 从处理集合中移除
-	pipe.SRem(ctx, AnalysisProcessingSetKey, taskID)
-
-	// 删除任务数据
-	taskKey := fmt.Sprintf(AnalysisTaskDataKey, taskID)
-	pipe.Del(ctx, taskKey)
-
-	// 更新统计信息
-	pipe.HIncrBy(ctx, AnalysisQueueStatsKey, "total_failed", 1)
-
-	if _, err := pipe.Exec(ctx); err != nil {
-		q.logger.Error("Failed to mark task as failed", 
-			zap.String("task_id", taskID),
-			zap.Error(err))
-		return fmt.Errorf("failed to mark task as failed: %w", err)
-	}
-
-	q.logger.Debug("Task marked as failed", zap.String("task_id", taskID))
-	return nil
-}
-
-// RequeueTask 重新入队任务
-func (q *AnalysisTaskQueueImpl) RequeueTask(ctx context.Context, taskID string) error {
-	// 获取任务数据
-	task, err := q.getTaskData(ctx, taskID)
-	if err != nil {
-		return err
-	}
-
-	// 从处理集合中移除
-	q.client.SRem(ctx, AnalysisProcessingSetKey, taskID)
-
-	// 重新推送到队列
-	return q.Push(ctx, task)
-}
-
 // GetProcessingTasks 获取正在处理的任务列表
 func (q *AnalysisTaskQueueImpl) GetProcessingTasks(ctx context.Context) ([]string, error) {
-	result := q.client.SMembers(ctx, AnalysisProcessingSetKey)
+	result := q.client.ZRange(ctx, AnalysisLeaseSetKey, 0, -1)
 	if result.Err() != nil {
 		return nil, fmt.Errorf("failed to get processing tasks: %w", result.Err())
 	}
@@ -456,7 +719,7 @@ func (q *AnalysisTaskQueueImpl) getTaskData(ctx context.Context, taskID string)
 			q.logger.Warn("Task data not found", zap.String("task_id", taskID))
 			return nil, fmt.Errorf("task data not found: %s", taskID)
 		}
-		q.logger.Error("Failed to get task data", 
+		q.logger.Error("Failed to get task data",
 			zap.String("task_id", taskID),
 			zap.Error(result.Err()))
 		return nil, fmt.Errorf("failed to get task data: %w", result.Err())
@@ -464,11 +727,11 @@ func (q *AnalysisTaskQueueImpl) getTaskData(ctx context.Context, taskID string)
 
 	var task analysis.AnalysisTask
 	if err := json.Unmarshal([]byte(result.Val()), &task); err != nil {
-		q.logger.Error("Failed to unmarshal task data", 
+		q.logger.Error("Failed to unmarshal task data",
 			zap.String("task_id", taskID),
 			zap.Error(err))
 		return nil, fmt.Errorf("failed to unmarshal task data: %w", err)
 	}
 
 	return &task, nil
-}
\ No newline at end of file
+}
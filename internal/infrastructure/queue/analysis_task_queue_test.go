@@ -0,0 +1,257 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/logger"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestAnalysisQueue(t *testing.T) (*AnalysisTaskQueueImpl, *time.Time) {
+	logger.L = zap.NewNop()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Now()
+	q := NewAnalysisTaskQueue(client, analysis.QueueConfig{
+		VisibilityTimeout: time.Minute,
+		MaxAttempts:       2,
+	}).(*AnalysisTaskQueueImpl)
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+func pushTestTask(t *testing.T, q *AnalysisTaskQueueImpl, id string, priority int) {
+	require.NoError(t, q.Push(context.Background(), &analysis.AnalysisTask{
+		ID:       id,
+		AlertID:  "alert-" + id,
+		Type:     analysis.AnalysisTypeRootCause,
+		Priority: priority,
+	}))
+}
+
+func TestAnalysisTaskQueue_PopAckNack(t *testing.T) {
+	q, _ := newTestAnalysisQueue(t)
+	ctx := context.Background()
+
+	pushTestTask(t, q, "normal", 0)
+	pushTestTask(t, q, "urgent", 8)
+
+	// 优先级队列先出队，出队任务持有租约
+	task, err := q.Pop(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, "urgent", task.ID)
+	assert.Equal(t, int64(1), task.Metadata["queue_attempts"])
+
+	processing, err := q.GetProcessingTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent"}, processing)
+
+	// 失败未超过最大投递次数时重新入队
+	deadLettered, err := q.Nack(ctx, "urgent", errors.New("model timeout"))
+	require.NoError(t, err)
+	assert.False(t, deadLettered)
+
+	task, err = q.Pop(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "urgent", task.ID)
+	assert.Equal(t, int64(2), task.Metadata["queue_attempts"])
+	require.NoError(t, q.Ack(ctx, "urgent"))
+
+	// 已确认的任务不能再 Nack
+	_, err = q.Nack(ctx, "urgent", errors.New("late"))
+	assert.ErrorIs(t, err, analysis.ErrLeaseLost)
+
+	task, err = q.Pop(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "normal", task.ID)
+	require.NoError(t, q.Ack(ctx, "normal"))
+
+	task, err = q.Pop(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, task)
+
+	status, err := q.GetStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.PendingCount)
+	assert.Equal(t, int64(0), status.ProcessingCount)
+}
+
+func TestAnalysisTaskQueue_ExpiredLeasesAreRequeuedThenDeadLettered(t *testing.T) {
+	q, now := newTestAnalysisQueue(t)
+	ctx := context.Background()
+
+	pushTestTask(t, q, "task-1", 0)
+
+	_, err := q.Pop(ctx)
+	require.NoError(t, err)
+
+	// 续约成功后租约未过期，不会被回收
+	*now = now.Add(50 * time.Second)
+	require.NoError(t, q.ExtendLease(ctx, "task-1"))
+	*now = now.Add(50 * time.Second)
+	requeued, deadLettered, err := q.RequeueExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, requeued)
+	assert.Equal(t, 0, deadLettered)
+
+	// worker 崩溃，租约过期后重新投递，续约失败
+	*now = now.Add(2 * time.Minute)
+	requeued, deadLettered, err = q.RequeueExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+	assert.Equal(t, 0, deadLettered)
+	assert.ErrorIs(t, q.ExtendLease(ctx, "task-1"), analysis.ErrLeaseLost)
+
+	// 第二次投递再次过期，超过最大投递次数转入死信
+	task, err := q.Pop(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", task.ID)
+	*now = now.Add(2 * time.Minute)
+	requeued, deadLettered, err = q.RequeueExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, requeued)
+	assert.Equal(t, 1, deadLettered)
+
+	deadLetters, err := q.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "task-1", deadLetters[0].Task.ID)
+	assert.Equal(t, "alert-task-1", deadLetters[0].Task.AlertID)
+	assert.Equal(t, int64(2), deadLetters[0].Attempts)
+	assert.NotEmpty(t, deadLetters[0].Error)
+
+	status, err := q.GetStatus(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), status.DeadLetterCount)
+
+	// 死信重新入队后投递次数重置
+	require.NoError(t, q.RequeueDeadLetter(ctx, "task-1"))
+	task, err = q.Pop(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", task.ID)
+	assert.Equal(t, int64(1), task.Metadata["queue_attempts"])
+
+	deadLetters, err = q.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+
+	err = q.RequeueDeadLetter(ctx, "task-1")
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeNotFound))
+}
+
+func TestAnalysisTaskQueue_NackDeadLettersAfterMaxAttempts(t *testing.T) {
+	q, _ := newTestAnalysisQueue(t)
+	ctx := context.Background()
+
+	pushTestTask(t, q, "task-1", 0)
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := q.Pop(ctx)
+		require.NoError(t, err)
+		deadLettered, err := q.Nack(ctx, "task-1", errors.New("invalid response"))
+		require.NoError(t, err)
+		assert.Equal(t, attempt == 2, deadLettered)
+	}
+
+	deadLetters, err := q.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, "invalid response", deadLetters[0].Error)
+
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	// 重新推送的任务移出死信，投递次数从头计算
+	pushTestTask(t, q, "task-1", 0)
+	deadLetters, err = q.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+
+	task, err := q.Pop(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, int64(1), task.Metadata["queue_attempts"])
+	deadLettered, err := q.Nack(ctx, "task-1", errors.New("invalid response"))
+	require.NoError(t, err)
+	assert.False(t, deadLettered)
+}
+
+func TestAnalysisTaskQueue_UpdatePriorityKeepsDeadLetters(t *testing.T) {
+	q, _ := newTestAnalysisQueue(t)
+	ctx := context.Background()
+
+	pushTestTask(t, q, "task-1", 0)
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := q.Pop(ctx)
+		require.NoError(t, err)
+		_, err = q.Nack(ctx, "task-1", errors.New("invalid response"))
+		require.NoError(t, err)
+	}
+
+	// 死信任务调整优先级后仍在死信中，数据不过期
+	require.NoError(t, q.UpdatePriority(ctx, "task-1", 5))
+	size, err := q.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+	deadLetters, err := q.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, deadLetters, 1)
+	ttl, err := q.client.TTL(ctx, "analysis:task:task-1").Result()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), ttl)
+
+	// 就绪任务按新优先级调整位置
+	pushTestTask(t, q, "task-2", 0)
+	pushTestTask(t, q, "task-3", 0)
+	require.NoError(t, q.UpdatePriority(ctx, "task-3", 3))
+	task, err := q.Pop(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, "task-3", task.ID)
+
+	// 死信重新入队时使用新优先级
+	require.NoError(t, q.RequeueDeadLetter(ctx, "task-1"))
+	task, err = q.Pop(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, "task-1", task.ID)
+	assert.Equal(t, 5, task.Priority)
+}
+
+func TestAnalysisTaskQueue_IdempotencyKey(t *testing.T) {
+	q, _ := newTestAnalysisQueue(t)
+	ctx := context.Background()
+	key := analysis.IdempotencyKey("alert-1", analysis.AnalysisTypeRootCause)
+
+	owner, err := q.ClaimIdempotencyKey(ctx, key, "task-1")
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", owner)
+
+	// 重复提交合并到已有任务
+	owner, err = q.ClaimIdempotencyKey(ctx, key, "task-2")
+	require.NoError(t, err)
+	assert.Equal(t, "task-1", owner)
+
+	// 任务确认后释放幂等键
+	pushTestTask(t, q, "task-1", 0)
+	_, err = q.Pop(ctx)
+	require.NoError(t, err)
+	require.NoError(t, q.Ack(ctx, "task-1"))
+
+	owner, err = q.ClaimIdempotencyKey(ctx, key, "task-2")
+	require.NoError(t, err)
+	assert.Equal(t, "task-2", owner)
+}
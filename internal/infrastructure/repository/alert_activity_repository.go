@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AlertActivityRepository 告警动态数据仓储实现
+type AlertActivityRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertActivityRepository 创建告警动态数据仓储
+func NewAlertActivityRepository(db *gorm.DB) alert.ActivityRepository {
+	return &AlertActivityRepository{db: db}
+}
+
+// GetAlert 获取告警，不存在时返回 nil
+func (r *AlertActivityRepository) GetAlert(ctx context.Context, id uint) (*model.Alert, error) {
+	var a model.Alert
+	if err := r.db.WithContext(ctx).First(&a, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+	return &a, nil
+}
+
+// Create 保存动态
+func (r *AlertActivityRepository) Create(ctx context.Context, activities ...*alert.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).CreateInBatches(activities, 200).Error; err != nil {
+		return fmt.Errorf("failed to create alert activities: %w", err)
+	}
+	return nil
+}
+
+// GetByID 获取动态，不存在时返回 nil
+func (r *AlertActivityRepository) GetByID(ctx context.Context, id uint) (*alert.Activity, error) {
+	var activity alert.Activity
+	if err := r.db.WithContext(ctx).First(&activity, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get alert activity: %w", err)
+	}
+	return &activity, nil
+}
+
+// ListByAlert 获取告警的动态，按时间正序
+func (r *AlertActivityRepository) ListByAlert(ctx context.Context, alertID uint) ([]*alert.Activity, error) {
+	var activities []*alert.Activity
+	if err := r.db.WithContext(ctx).Where("alert_id = ?", alertID).
+		Order("created_at ASC, id ASC").Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alert activities: %w", err)
+	}
+	return activities, nil
+}
+
+// ListNotifyRecords 获取告警的通知记录，按时间正序
+func (r *AlertActivityRepository) ListNotifyRecords(ctx context.Context, alertID uint) ([]*model.NotifyRecord, error) {
+	var records []*model.NotifyRecord
+	if err := r.db.WithContext(ctx).Where("alert_id = ?", alertID).
+		Order("created_at ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notify records: %w", err)
+	}
+	return records, nil
+}
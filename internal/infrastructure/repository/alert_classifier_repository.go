@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"gorm.io/gorm"
+)
+
+// AlertClassifierRepository 告警分类模型版本与训练样本仓储实现
+type AlertClassifierRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertClassifierRepository 创建告警分类模型仓储
+func NewAlertClassifierRepository(db *gorm.DB) gateway.ClassifierModelRepository {
+	return &AlertClassifierRepository{db: db}
+}
+
+// Create 保存新版本
+func (r *AlertClassifierRepository) Create(ctx context.Context, classifier *gateway.ClassifierModel) error {
+	if err := r.db.WithContext(ctx).Create(classifier).Error; err != nil {
+		return fmt.Errorf("failed to create classifier model: %w", err)
+	}
+	return nil
+}
+
+// GetActive 获取启用的版本，没有时返回 nil
+func (r *AlertClassifierRepository) GetActive(ctx context.Context) (*gateway.ClassifierModel, error) {
+	var classifier gateway.ClassifierModel
+	if err := r.db.WithContext(ctx).Where("active = ?", true).
+		Order("created_at DESC").First(&classifier).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active classifier model: %w", err)
+	}
+	return &classifier, nil
+}
+
+// List 获取最近的版本，不加载模型参数
+func (r *AlertClassifierRepository) List(ctx context.Context, limit int) ([]*gateway.ClassifierModel, error) {
+	var classifiers []*gateway.ClassifierModel
+	if err := r.db.WithContext(ctx).Omit("params").
+		Order("created_at DESC").Limit(limit).Find(&classifiers).Error; err != nil {
+		return nil, fmt.Errorf("failed to list classifier models: %w", err)
+	}
+	return classifiers, nil
+}
+
+// Activate 启用指定版本并停用其他版本
+func (r *AlertClassifierRepository) Activate(ctx context.Context, version string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&gateway.ClassifierModel{}).Where("version = ?", version).Update("active", true)
+		if result.Error != nil {
+			return fmt.Errorf("failed to activate classifier model: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return sharedErrors.NewNotFoundError("classifier model " + version)
+		}
+		if err := tx.Model(&gateway.ClassifierModel{}).Where("version <> ? AND active = ?", version, true).
+			Update("active", false).Error; err != nil {
+			return fmt.Errorf("failed to deactivate classifier models: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListTrainingSamples 获取已处理告警及其类别
+// 类别优先取反馈中更正的类别，其次取最近一次完成分析的类别；分析被评为错误且未更正的告警不参与训练
+func (r *AlertClassifierRepository) ListTrainingSamples(ctx context.Context, since time.Time, limit int) ([]*gateway.TrainingSample, error) {
+	var alerts []*model.Alert
+	if err := r.db.WithContext(ctx).
+		Where("updated_at >= ? AND (handle_time IS NOT NULL OR status = ?)", since, model.AlertStatusResolved).
+		Order("id DESC").Limit(limit).Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list handled alerts: %w", err)
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+
+	alertIDs := make([]string, len(alerts))
+	for i, a := range alerts {
+		alertIDs[i] = strconv.FormatUint(uint64(a.ID), 10)
+	}
+
+	var results []*analysisResultModel
+	if err := r.db.WithContext(ctx).Select("alert_id, category, severity").
+		Where("alert_id IN ? AND status = ? AND category <> ''", alertIDs, analysis.AnalysisStatusCompleted).
+		Order("created_at DESC").Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to list analysis categories: %w", err)
+	}
+	latest := make(map[string]*analysisResultModel)
+	for _, result := range results {
+		if _, ok := latest[result.AlertID]; !ok {
+			latest[result.AlertID] = result
+		}
+	}
+
+	var feedback []*analysis.AnalysisFeedback
+	if err := r.db.WithContext(ctx).Select("alert_id, rating, corrected_category").
+		Where("alert_id IN ?", alertIDs).
+		Order("created_at DESC, id DESC").Find(&feedback).Error; err != nil {
+		return nil, fmt.Errorf("failed to list analysis feedback: %w", err)
+	}
+	reviewed := make(map[string]*analysis.AnalysisFeedback)
+	for _, f := range feedback {
+		if _, ok := reviewed[f.AlertID]; !ok {
+			reviewed[f.AlertID] = f
+		}
+	}
+
+	samples := make([]*gateway.TrainingSample, 0, len(alerts))
+	for i, a := range alerts {
+		id := alertIDs[i]
+		sample := &gateway.TrainingSample{Alert: a}
+		if result, ok := latest[id]; ok {
+			sample.Category = result.Category
+			sample.Severity = result.Severity
+		}
+		if f, ok := reviewed[id]; ok {
+			switch {
+			case f.CorrectedCategory != "":
+				sample.Category = f.CorrectedCategory
+			case f.Rating == analysis.FeedbackDown:
+				sample.Category = ""
+			}
+		}
+		if sample.Category != "" {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
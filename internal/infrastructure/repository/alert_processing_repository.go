@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/shared/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AlertProcessingRepository 告警处理记录仓储实现
+type AlertProcessingRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewAlertProcessingRepository 创建告警处理记录仓储
+func NewAlertProcessingRepository(db *gorm.DB) gateway.AlertProcessingRepository {
+	return &AlertProcessingRepository{
+		db:     db,
+		logger: logger.WithComponent("alert-processing-repository"),
+	}
+}
+
+// Create 创建处理记录
+func (r *AlertProcessingRepository) Create(ctx context.Context, record *gateway.AlertProcessingRecord) error {
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		r.logger.Error("failed to create processing record", zap.String("id", record.ID), zap.Error(err))
+		return fmt.Errorf("failed to create processing record: %w", err)
+	}
+	return nil
+}
+
+// Update 更新处理记录
+func (r *AlertProcessingRepository) Update(ctx context.Context, record *gateway.AlertProcessingRecord) error {
+	if err := r.db.WithContext(ctx).Save(record).Error; err != nil {
+		r.logger.Error("failed to update processing record", zap.String("id", record.ID), zap.Error(err))
+		return fmt.Errorf("failed to update processing record: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取处理记录，不存在时返回 nil
+func (r *AlertProcessingRepository) GetByID(ctx context.Context, id string) (*gateway.AlertProcessingRecord, error) {
+	var record gateway.AlertProcessingRecord
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get processing record: %w", err)
+	}
+	return &record, nil
+}
+
+// GetByAlertID 获取告警最新的处理记录，不存在时返回 nil
+func (r *AlertProcessingRepository) GetByAlertID(ctx context.Context, alertID uint) (*gateway.AlertProcessingRecord, error) {
+	var record gateway.AlertProcessingRecord
+	if err := r.db.WithContext(ctx).Where("alert_id = ?", alertID).Order("created_at DESC").First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get processing record by alert: %w", err)
+	}
+	return &record, nil
+}
+
+// List 获取处理记录列表
+func (r *AlertProcessingRepository) List(ctx context.Context, filter gateway.AlertProcessingFilter) ([]*gateway.AlertProcessingRecord, error) {
+	db := r.db.WithContext(ctx).Model(&gateway.AlertProcessingRecord{})
+	if len(filter.Status) > 0 {
+		db = db.Where("status IN ?", filter.Status)
+	}
+	if len(filter.ProcessingMode) > 0 {
+		db = db.Where("processing_mode IN ?", filter.ProcessingMode)
+	}
+	if filter.TimeRange != nil {
+		db = db.Where("received_at BETWEEN ? AND ?", filter.TimeRange.Start, filter.TimeRange.End)
+	}
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+
+	var records []*gateway.AlertProcessingRecord
+	if err := db.Order("received_at DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list processing records: %w", err)
+	}
+	return records, nil
+}
+
+// GetStatistics 获取处理统计
+func (r *AlertProcessingRepository) GetStatistics(ctx context.Context, timeRange gateway.TimeRange) (*gateway.GatewayStatistics, error) {
+	stats := &gateway.GatewayStatistics{
+		ProcessingModes:    make(map[gateway.ProcessingMode]int64),
+		StatusDistribution: make(map[gateway.AlertStatus]int64),
+		LastUpdated:        time.Now(),
+	}
+
+	db := r.db.WithContext(ctx).Model(&gateway.AlertProcessingRecord{}).
+		Where("received_at BETWEEN ? AND ?", timeRange.Start, timeRange.End)
+
+	var statusRows []struct {
+		Status gateway.AlertStatus
+		Count  int64
+	}
+	if err := db.Session(&gorm.Session{}).Select("status, COUNT(*) AS count").Group("status").Scan(&statusRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count processing records by status: %w", err)
+	}
+	for _, row := range statusRows {
+		stats.StatusDistribution[row.Status] = row.Count
+		stats.TotalReceived += row.Count
+		switch row.Status {
+		case gateway.AlertStatusRouted:
+			stats.TotalRouted = row.Count
+		case gateway.AlertStatusSuppressed:
+			stats.TotalSuppressed = row.Count
+		case gateway.AlertStatusConverged:
+			stats.TotalConverged = row.Count
+		case gateway.AlertStatusFailed:
+			stats.TotalFailed = row.Count
+		}
+	}
+	stats.TotalProcessed = stats.TotalRouted + stats.TotalSuppressed + stats.TotalConverged
+
+	var modeRows []struct {
+		ProcessingMode gateway.ProcessingMode
+		Count          int64
+	}
+	if err := db.Session(&gorm.Session{}).Select("processing_mode, COUNT(*) AS count").Group("processing_mode").Scan(&modeRows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count processing records by mode: %w", err)
+	}
+	for _, row := range modeRows {
+		stats.ProcessingModes[row.ProcessingMode] = row.Count
+	}
+
+	return stats, nil
+}
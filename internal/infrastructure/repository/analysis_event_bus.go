@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// analysisEventChannel 任务事件频道模板
+const analysisEventChannel = "analysis:events:%s"
+
+// subscriberBuffer 订阅者通道缓冲，消费过慢时丢弃模型输出增量
+const subscriberBuffer = 256
+
+// RedisAnalysisEventBus 基于 Redis pub/sub 的分析事件总线
+type RedisAnalysisEventBus struct {
+	client *redis.Client
+	logger *zap.Logger
+}
+
+// NewAnalysisEventBus 创建分析事件总线
+func NewAnalysisEventBus(client *redis.Client) analysis.AnalysisEventBus {
+	return &RedisAnalysisEventBus{
+		client: client,
+		logger: logger.L.Named("analysis-event-bus"),
+	}
+}
+
+// Publish 发布事件
+func (b *RedisAnalysisEventBus) Publish(ctx context.Context, event *analysis.StreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stream event: %w", err)
+	}
+	if err := b.client.Publish(ctx, fmt.Sprintf(analysisEventChannel, event.TaskID), data).Err(); err != nil {
+		return fmt.Errorf("failed to publish stream event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe 订阅任务事件
+func (b *RedisAnalysisEventBus) Subscribe(ctx context.Context, taskID string) (<-chan *analysis.StreamEvent, error) {
+	pubsub := b.client.Subscribe(ctx, fmt.Sprintf(analysisEventChannel, taskID))
+	// 等待订阅确认，调用方随后读取的进度快照与之后的事件之间不会遗漏
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe stream events: %w", err)
+	}
+
+	events := make(chan *analysis.StreamEvent, subscriberBuffer)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event analysis.StreamEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					b.logger.Warn("Dropping malformed stream event", zap.String("task_id", taskID), zap.Error(err))
+					continue
+				}
+				if !b.deliver(ctx, events, &event) {
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// deliver 投递事件，进度事件阻塞等待消费，模型输出增量在缓冲已满时丢弃
+func (b *RedisAnalysisEventBus) deliver(ctx context.Context, events chan<- *analysis.StreamEvent, event *analysis.StreamEvent) bool {
+	if event.Type == analysis.StreamEventToken {
+		select {
+		case events <- event:
+		default:
+		}
+		return true
+	}
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
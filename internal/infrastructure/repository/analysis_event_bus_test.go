@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	analysisApp "alert_agent/internal/application/analysis"
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/logger"
+	sharedErrors "alert_agent/internal/shared/errors"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTaskRepository 只实现按 ID 查询的任务仓库
+type memoryTaskRepository struct {
+	analysis.AnalysisTaskRepository
+	tasks map[string]*analysis.AnalysisTask
+}
+
+func (r *memoryTaskRepository) GetByID(ctx context.Context, taskID string) (*analysis.AnalysisTask, error) {
+	if task, ok := r.tasks[taskID]; ok {
+		return task, nil
+	}
+	return nil, assert.AnError
+}
+
+func receiveEvent(t *testing.T, events <-chan *analysis.StreamEvent) *analysis.StreamEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "stream closed unexpectedly")
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for stream event")
+		return nil
+	}
+}
+
+func TestAnalysisService_StreamAnalysis(t *testing.T) {
+	logger.L = zap.NewNop()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	bus := NewAnalysisEventBus(client)
+	tracker := NewAnalysisProgressTracker(client, bus)
+	tasks := &memoryTaskRepository{tasks: map[string]*analysis.AnalysisTask{
+		"task-1": {ID: "task-1", Status: analysis.AnalysisStatusPending},
+		"task-2": {ID: "task-2", Status: analysis.AnalysisStatusCompleted},
+	}}
+	service := analysisApp.NewAnalysisService(nil, tasks, nil, tracker, nil, nil, nil, nil, bus, nil)
+	ctx := context.Background()
+
+	_, err := service.StreamAnalysis(ctx, "missing")
+	assert.True(t, sharedErrors.IsErrorType(err, sharedErrors.ErrorTypeNotFound))
+
+	events, err := service.StreamAnalysis(ctx, "task-1")
+	require.NoError(t, err)
+
+	// 尚无进度时按任务状态生成快照
+	event := receiveEvent(t, events)
+	assert.Equal(t, analysis.ProgressStagePending, event.Progress.Stage)
+
+	// worker 写入的进度与模型输出经 Redis 转发给订阅方
+	require.NoError(t, tracker.UpdateProgress(ctx, "task-1", &analysis.AnalysisProgress{Stage: analysis.ProgressStageAnalyzing, Progress: 50}))
+	require.NoError(t, bus.Publish(ctx, &analysis.StreamEvent{TaskID: "task-1", Type: analysis.StreamEventToken, Delta: `{"summary":`}))
+	require.NoError(t, tracker.UpdateProgress(ctx, "task-1", &analysis.AnalysisProgress{Stage: analysis.ProgressStageCompleted, Progress: 100}))
+
+	event = receiveEvent(t, events)
+	assert.Equal(t, analysis.StreamEventProgress, event.Type)
+	assert.Equal(t, "task-1", event.Progress.TaskID)
+	assert.Equal(t, float64(50), event.Progress.Progress)
+	event = receiveEvent(t, events)
+	assert.Equal(t, analysis.StreamEventToken, event.Type)
+	assert.Equal(t, `{"summary":`, event.Delta)
+	event = receiveEvent(t, events)
+	assert.True(t, event.IsTerminal())
+	_, ok := <-events
+	assert.False(t, ok, "stream should close after terminal stage")
+
+	// 已结束的任务只推送最终状态
+	events, err = service.StreamAnalysis(ctx, "task-2")
+	require.NoError(t, err)
+	event = receiveEvent(t, events)
+	assert.Equal(t, analysis.ProgressStageCompleted, event.Progress.Stage)
+	_, ok = <-events
+	assert.False(t, ok)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"alert_agent/internal/domain/analysis"
+
+	"gorm.io/gorm"
+)
+
+// AnalysisFeedbackRepositoryImpl 分析反馈存储实现
+type AnalysisFeedbackRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAnalysisFeedbackRepository 创建分析反馈存储实例
+func NewAnalysisFeedbackRepository(db *gorm.DB) analysis.FeedbackRepository {
+	return &AnalysisFeedbackRepositoryImpl{db: db}
+}
+
+// Create 保存反馈
+func (r *AnalysisFeedbackRepositoryImpl) Create(ctx context.Context, feedback *analysis.AnalysisFeedback) error {
+	if err := r.db.WithContext(ctx).Create(feedback).Error; err != nil {
+		return fmt.Errorf("failed to create analysis feedback: %w", err)
+	}
+	return nil
+}
+
+// ListByResult 获取分析结果的反馈
+func (r *AnalysisFeedbackRepositoryImpl) ListByResult(ctx context.Context, resultID string) ([]*analysis.AnalysisFeedback, error) {
+	var feedback []*analysis.AnalysisFeedback
+	if err := r.db.WithContext(ctx).Where("result_id = ?", resultID).
+		Order("created_at DESC, id DESC").Find(&feedback).Error; err != nil {
+		return nil, fmt.Errorf("failed to list analysis feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+// StatsByModel 按模型统计反馈
+func (r *AnalysisFeedbackRepositoryImpl) StatsByModel(ctx context.Context, filter *analysis.FeedbackFilter) ([]*analysis.FeedbackStats, error) {
+	query := r.db.WithContext(ctx).Model(&analysis.AnalysisFeedback{})
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.ModelUsed != "" {
+		query = query.Where("model_used = ?", filter.ModelUsed)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	var stats []*analysis.FeedbackStats
+	err := query.Select(`model_used,
+		COUNT(*) AS total,
+		SUM(CASE WHEN rating = ? THEN 1 ELSE 0 END) AS positive,
+		SUM(CASE WHEN rating = ? THEN 1 ELSE 0 END) AS negative,
+		SUM(CASE WHEN rating = ? AND corrected_root_cause <> '' THEN 1 ELSE 0 END) AS corrected,
+		AVG(confidence) AS avg_confidence,
+		AVG(processing_time_ms) AS avg_latency_ms`,
+		analysis.FeedbackUp, analysis.FeedbackDown, analysis.FeedbackDown).
+		Group("model_used").Order("total DESC").Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate analysis feedback: %w", err)
+	}
+	for _, s := range stats {
+		if s.Total > 0 {
+			s.Accuracy = float64(s.Positive) / float64(s.Total)
+		}
+	}
+	return stats, nil
+}
@@ -7,14 +7,17 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 
 	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/logger"
 )
 
-// NewAnalysisProgressTracker 创建分析进度跟踪器实例
-func NewAnalysisProgressTracker(redisClient *redis.Client) analysis.AnalysisProgressTracker {
+// NewAnalysisProgressTracker 创建分析进度跟踪器实例，eventBus 不为 nil 时同时发布进度事件
+func NewAnalysisProgressTracker(redisClient *redis.Client, eventBus analysis.AnalysisEventBus) analysis.AnalysisProgressTracker {
 	return &AnalysisProgressTrackerImpl{
 		redisClient: redisClient,
+		eventBus:    eventBus,
 		keyPrefix:   "analysis:progress:",
 		ttl:         24 * time.Hour, // 进度信息保留24小时
 	}
@@ -23,6 +26,7 @@ func NewAnalysisProgressTracker(redisClient *redis.Client) analysis.AnalysisProg
 // AnalysisProgressTrackerImpl 分析进度跟踪器实现
 type AnalysisProgressTrackerImpl struct {
 	redisClient *redis.Client
+	eventBus    analysis.AnalysisEventBus
 	keyPrefix   string
 	ttl         time.Duration
 }
@@ -54,6 +58,14 @@ func (t *AnalysisProgressTrackerImpl) UpdateProgress(ctx context.Context, taskID
 		return fmt.Errorf("failed to store progress in Redis: %w", err)
 	}
 
+	// 发布失败不影响进度存储，订阅方仍可轮询获取
+	if t.eventBus != nil {
+		event := &analysis.StreamEvent{TaskID: taskID, Type: analysis.StreamEventProgress, Progress: progress}
+		if err := t.eventBus.Publish(ctx, event); err != nil {
+			logger.L.Warn("Failed to publish progress event", zap.String("task_id", taskID), zap.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -35,6 +35,10 @@ type analysisResultModel struct {
 	ResultJSON      string    `gorm:"type:text" json:"result_json"`
 	Summary         string    `gorm:"type:text" json:"summary"`
 	Recommendations string    `gorm:"type:text" json:"recommendations_json"`
+	StructuredJSON  string    `gorm:"type:text" json:"structured_json"`
+	Category        string    `gorm:"type:varchar(32);index" json:"category"`
+	Severity        string    `gorm:"type:varchar(16)" json:"severity"`
+	CitationsJSON   string    `gorm:"type:text" json:"citations_json"`
 	ErrorMessage    string    `gorm:"type:text" json:"error_message"`
 	CreatedAt       time.Time `gorm:"not null" json:"created_at"`
 	UpdatedAt       time.Time `gorm:"not null" json:"updated_at"`
@@ -80,6 +84,20 @@ func (m *analysisResultModel) toEntity() (*analysis.AnalysisResult, error) {
 		result.Recommendations = []string{}
 	}
 
+	// 解析结构化输出JSON
+	if m.StructuredJSON != "" {
+		if err := json.Unmarshal([]byte(m.StructuredJSON), &result.Structured); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal structured JSON: %w", err)
+		}
+	}
+
+	// 解析参考资料JSON
+	if m.CitationsJSON != "" {
+		if err := json.Unmarshal([]byte(m.CitationsJSON), &result.Citations); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal citations JSON: %w", err)
+		}
+	}
+
 	// 解析元数据JSON
 	if m.MetadataJSON != "" {
 		if err := json.Unmarshal([]byte(m.MetadataJSON), &result.Metadata); err != nil {
@@ -124,6 +142,26 @@ func (m *analysisResultModel) fromEntity(result *analysis.AnalysisResult) error
 		m.Recommendations = string(recommendationsJSON)
 	}
 
+	// 序列化结构化输出JSON，类别与严重程度单独存储便于查询
+	if result.Structured != nil {
+		structuredJSON, err := json.Marshal(result.Structured)
+		if err != nil {
+			return fmt.Errorf("failed to marshal structured JSON: %w", err)
+		}
+		m.StructuredJSON = string(structuredJSON)
+		m.Category = result.Structured.Category
+		m.Severity = result.Structured.Severity
+	}
+
+	// 序列化参考资料JSON
+	if len(result.Citations) > 0 {
+		citationsJSON, err := json.Marshal(result.Citations)
+		if err != nil {
+			return fmt.Errorf("failed to marshal citations JSON: %w", err)
+		}
+		m.CitationsJSON = string(citationsJSON)
+	}
+
 	// 序列化元数据JSON
 	if result.Metadata != nil {
 		metadataJSON, err := json.Marshal(result.Metadata)
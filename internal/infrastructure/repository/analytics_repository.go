@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/analytics"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dimensionColumns 统计维度到聚合表列的白名单
+var dimensionColumns = map[analytics.Dimension]string{
+	analytics.DimensionRule:     "rule_id",
+	analytics.DimensionService:  "service",
+	analytics.DimensionTeam:     "team",
+	analytics.DimensionInstance: "instance",
+	analytics.DimensionSeverity: "severity",
+}
+
+// totalsColumns 聚合计数的汇总列
+const totalsColumns = "SUM(alert_count) AS alerts, SUM(acked_count) AS acked, SUM(ack_seconds) AS ack_seconds, " +
+	"SUM(resolved_count) AS resolved, SUM(resolve_seconds) AS resolve_seconds"
+
+// AnalyticsRepositoryImpl 告警分析数据仓储实现
+type AnalyticsRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAnalyticsRepository 创建告警分析数据仓储
+func NewAnalyticsRepository(db *gorm.DB) analytics.Repository {
+	return &AnalyticsRepositoryImpl{db: db}
+}
+
+// ListAlertsCreatedBetween 获取创建时间在 [from, to) 内的告警，仅包含聚合所需字段
+func (r *AnalyticsRepositoryImpl) ListAlertsCreatedBetween(ctx context.Context, from, to time.Time) ([]*model.Alert, error) {
+	var alerts []*model.Alert
+	if err := r.db.WithContext(ctx).Model(&model.Alert{}).
+		Select("id, created_at, rule_id, labels, level, severity, status, handle_time").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// ReplaceRollups 在一个事务内替换 [from, to) 内的小时聚合
+func (r *AnalyticsRepositoryImpl) ReplaceRollups(ctx context.Context, from, to time.Time, rollups []*analytics.HourlyRollup) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bucket >= ? AND bucket < ?", from, to).
+			Delete(&analytics.HourlyRollup{}).Error; err != nil {
+			return fmt.Errorf("failed to delete rollups: %w", err)
+		}
+		if len(rollups) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(rollups, 500).Error; err != nil {
+			return fmt.Errorf("failed to create rollups: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetWatermark 获取聚合进度，从未聚合时返回 nil
+func (r *AnalyticsRepositoryImpl) GetWatermark(ctx context.Context, name string) (*time.Time, error) {
+	var state analytics.RollupState
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&state).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+	return &state.Watermark, nil
+}
+
+// SetWatermark 保存聚合进度
+func (r *AnalyticsRepositoryImpl) SetWatermark(ctx context.Context, name string, watermark time.Time) error {
+	state := &analytics.RollupState{Name: name, Watermark: watermark, UpdatedAt: time.Now()}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"watermark", "updated_at"}),
+	}).Create(state).Error; err != nil {
+		return fmt.Errorf("failed to set rollup watermark: %w", err)
+	}
+	return nil
+}
+
+// AggregateBy 按维度汇总，按告警数倒序，limit 为 0 时不限制
+func (r *AnalyticsRepositoryImpl) AggregateBy(ctx context.Context, dimension analytics.Dimension, filter *analytics.Filter, limit int) ([]*analytics.DimensionStats, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("unsupported dimension: %s", dimension)
+	}
+
+	var rows []struct {
+		DimKey string
+		analytics.Totals
+	}
+	query := applyAnalyticsFilter(r.db.WithContext(ctx).Model(&analytics.HourlyRollup{}), filter).
+		Select(column + " AS dim_key, " + totalsColumns).
+		Group(column).
+		Order("alerts DESC").Order(column + " ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate rollups: %w", err)
+	}
+
+	stats := make([]*analytics.DimensionStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, &analytics.DimensionStats{Key: row.DimKey, Totals: row.Totals})
+	}
+	return stats, nil
+}
+
+// BucketTotals 按小时桶汇总，按时间正序
+func (r *AnalyticsRepositoryImpl) BucketTotals(ctx context.Context, filter *analytics.Filter) ([]*analytics.BucketTotals, error) {
+	var rows []struct {
+		Bucket time.Time
+		analytics.Totals
+	}
+	if err := applyAnalyticsFilter(r.db.WithContext(ctx).Model(&analytics.HourlyRollup{}), filter).
+		Select("bucket, " + totalsColumns).
+		Group("bucket").
+		Order("bucket ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate rollup buckets: %w", err)
+	}
+
+	buckets := make([]*analytics.BucketTotals, 0, len(rows))
+	for _, row := range rows {
+		buckets = append(buckets, &analytics.BucketTotals{Bucket: row.Bucket, Totals: row.Totals})
+	}
+	return buckets, nil
+}
+
+// RuleNames 获取规则名称
+func (r *AnalyticsRepositoryImpl) RuleNames(ctx context.Context, ruleIDs []uint) (map[uint]string, error) {
+	names := make(map[uint]string, len(ruleIDs))
+	if len(ruleIDs) == 0 {
+		return names, nil
+	}
+	var rules []model.Rule
+	if err := r.db.WithContext(ctx).Unscoped().Select("id, name").
+		Where("id IN ?", ruleIDs).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list rule names: %w", err)
+	}
+	for _, rule := range rules {
+		names[rule.ID] = rule.Name
+	}
+	return names, nil
+}
+
+// applyAnalyticsFilter 应用统计过滤条件
+func applyAnalyticsFilter(query *gorm.DB, filter *analytics.Filter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("bucket >= ?", filter.From.UTC().Truncate(time.Hour))
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("bucket < ?", filter.To.UTC())
+	}
+	if filter.RuleID > 0 {
+		query = query.Where("rule_id = ?", filter.RuleID)
+	}
+	if filter.Service != "" {
+		query = query.Where("service = ?", filter.Service)
+	}
+	if filter.Team != "" {
+		query = query.Where("team = ?", filter.Team)
+	}
+	if filter.Instance != "" {
+		query = query.Where("instance = ?", filter.Instance)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	return query
+}
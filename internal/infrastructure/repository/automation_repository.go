@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/automation"
+
+	"gorm.io/gorm"
+)
+
+// RunbookRepository 运行手册仓储实现
+type RunbookRepository struct {
+	db *gorm.DB
+}
+
+// NewRunbookRepository 创建运行手册仓储
+func NewRunbookRepository(db *gorm.DB) automation.RunbookRepository {
+	return &RunbookRepository{db: db}
+}
+
+// Create 创建运行手册
+func (r *RunbookRepository) Create(ctx context.Context, runbook *automation.Runbook) error {
+	if err := r.db.WithContext(ctx).Create(runbook).Error; err != nil {
+		return fmt.Errorf("failed to create runbook: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取运行手册
+func (r *RunbookRepository) GetByID(ctx context.Context, id string) (*automation.Runbook, error) {
+	var runbook automation.Runbook
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&runbook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get runbook: %w", err)
+	}
+	return &runbook, nil
+}
+
+// List 获取运行手册列表
+func (r *RunbookRepository) List(ctx context.Context, enabledOnly bool) ([]*automation.Runbook, error) {
+	var runbooks []*automation.Runbook
+	db := r.db.WithContext(ctx)
+	if enabledOnly {
+		db = db.Where("enabled = ?", true)
+	}
+	if err := db.Order("name ASC").Find(&runbooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list runbooks: %w", err)
+	}
+	return runbooks, nil
+}
+
+// Update 更新运行手册
+func (r *RunbookRepository) Update(ctx context.Context, runbook *automation.Runbook) error {
+	if err := r.db.WithContext(ctx).Save(runbook).Error; err != nil {
+		return fmt.Errorf("failed to update runbook: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除运行手册，保留历史执行记录
+func (r *RunbookRepository) Delete(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&automation.Runbook{}).Error; err != nil {
+		return fmt.Errorf("failed to delete runbook: %w", err)
+	}
+	return nil
+}
+
+// RunbookExecutionRepository 运行手册执行与操作日志仓储实现
+type RunbookExecutionRepository struct {
+	db *gorm.DB
+}
+
+// NewRunbookExecutionRepository 创建执行仓储
+func NewRunbookExecutionRepository(db *gorm.DB) automation.ExecutionRepository {
+	return &RunbookExecutionRepository{db: db}
+}
+
+// Create 创建执行记录
+func (r *RunbookExecutionRepository) Create(ctx context.Context, execution *automation.Execution) error {
+	if err := r.db.WithContext(ctx).Create(execution).Error; err != nil {
+		return fmt.Errorf("failed to create runbook execution: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取执行记录
+func (r *RunbookExecutionRepository) GetByID(ctx context.Context, id string) (*automation.Execution, error) {
+	var execution automation.Execution
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&execution).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get runbook execution: %w", err)
+	}
+	return &execution, nil
+}
+
+// GetLatest 获取运行手册针对告警的最近一次执行
+func (r *RunbookExecutionRepository) GetLatest(ctx context.Context, runbookID string, alertID uint) (*automation.Execution, error) {
+	var execution automation.Execution
+	if err := r.db.WithContext(ctx).Where("runbook_id = ? AND alert_id = ?", runbookID, alertID).
+		Order("created_at DESC").First(&execution).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest runbook execution: %w", err)
+	}
+	return &execution, nil
+}
+
+// List 获取执行记录
+func (r *RunbookExecutionRepository) List(ctx context.Context, filter *automation.ExecutionFilter) ([]*automation.Execution, int64, error) {
+	var executions []*automation.Execution
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&automation.Execution{})
+	if filter.RunbookID != "" {
+		db = db.Where("runbook_id = ?", filter.RunbookID)
+	}
+	if filter.AlertID != 0 {
+		db = db.Where("alert_id = ?", filter.AlertID)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count runbook executions: %w", err)
+	}
+
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+	if err := db.Order("created_at DESC").Find(&executions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list runbook executions: %w", err)
+	}
+	return executions, total, nil
+}
+
+// Update 更新执行记录
+func (r *RunbookExecutionRepository) Update(ctx context.Context, execution *automation.Execution) error {
+	if err := r.db.WithContext(ctx).Save(execution).Error; err != nil {
+		return fmt.Errorf("failed to update runbook execution: %w", err)
+	}
+	return nil
+}
+
+// Transition 仅当执行处于 from 状态时保存
+func (r *RunbookExecutionRepository) Transition(ctx context.Context, execution *automation.Execution, from automation.ExecutionStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&automation.Execution{}).
+		Where("id = ? AND status = ?", execution.ID, from).
+		Select("*").Omit("id", "created_at").Updates(execution)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to transition runbook execution: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListByStatus 获取指定状态的执行
+func (r *RunbookExecutionRepository) ListByStatus(ctx context.Context, status automation.ExecutionStatus, limit int) ([]*automation.Execution, error) {
+	var executions []*automation.Execution
+	db := r.db.WithContext(ctx).Where("status = ?", status).Order("created_at ASC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if err := db.Find(&executions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list runbook executions: %w", err)
+	}
+	return executions, nil
+}
+
+// ListPendingBefore 获取创建时间早于 before 的待审批执行
+func (r *RunbookExecutionRepository) ListPendingBefore(ctx context.Context, before time.Time) ([]*automation.Execution, error) {
+	var executions []*automation.Execution
+	if err := r.db.WithContext(ctx).Where("status = ? AND created_at < ?", automation.ExecutionPendingApproval, before).
+		Find(&executions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pending runbook executions: %w", err)
+	}
+	return executions, nil
+}
+
+// AddAction 保存操作日志
+func (r *RunbookExecutionRepository) AddAction(ctx context.Context, action *automation.AutomationAction) error {
+	if err := r.db.WithContext(ctx).Create(action).Error; err != nil {
+		return fmt.Errorf("failed to create automation action: %w", err)
+	}
+	return nil
+}
+
+// ListActions 获取执行的操作日志
+func (r *RunbookExecutionRepository) ListActions(ctx context.Context, executionID string) ([]*automation.AutomationAction, error) {
+	var actions []*automation.AutomationAction
+	if err := r.db.WithContext(ctx).Where("execution_id = ?", executionID).
+		Order("created_at ASC").Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list automation actions: %w", err)
+	}
+	return actions, nil
+}
+
+// ListActionsByAlert 获取告警的操作日志
+func (r *RunbookExecutionRepository) ListActionsByAlert(ctx context.Context, alertID uint) ([]*automation.AutomationAction, error) {
+	var actions []*automation.AutomationAction
+	if err := r.db.WithContext(ctx).Where("alert_id = ?", strconv.FormatUint(uint64(alertID), 10)).
+		Order("created_at ASC").Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alert automation actions: %w", err)
+	}
+	return actions, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// autoscalerStatusKey 各 worker 进程扩缩容状态的哈希，字段为进程标识
+const autoscalerStatusKey = "analysis:autoscalers"
+
+// RedisAutoscalerStatusStore 基于 Redis 哈希的扩缩容状态存储
+type RedisAutoscalerStatusStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewAutoscalerStatusStore 创建扩缩容状态存储，超过 ttl 未更新的进程视为已退出并在读取时清除
+func NewAutoscalerStatusStore(client *redis.Client, ttl time.Duration) analysis.AutoscalerStatusStore {
+	return &RedisAutoscalerStatusStore{client: client, ttl: ttl}
+}
+
+// Save 保存进程的状态
+func (s *RedisAutoscalerStatusStore) Save(ctx context.Context, status *analysis.AutoscalerStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal autoscaler status: %w", err)
+	}
+	if err := s.client.HSet(ctx, autoscalerStatusKey, status.Instance, data).Err(); err != nil {
+		return fmt.Errorf("failed to save autoscaler status: %w", err)
+	}
+	return nil
+}
+
+// List 获取仍在上报的进程状态，按进程标识排序
+func (s *RedisAutoscalerStatusStore) List(ctx context.Context) ([]*analysis.AutoscalerStatus, error) {
+	values, err := s.client.HGetAll(ctx, autoscalerStatusKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list autoscaler statuses: %w", err)
+	}
+
+	statuses := make([]*analysis.AutoscalerStatus, 0, len(values))
+	var stale []string
+	for instance, value := range values {
+		var status analysis.AutoscalerStatus
+		if err := json.Unmarshal([]byte(value), &status); err != nil || time.Since(status.UpdatedAt) > s.ttl {
+			stale = append(stale, instance)
+			continue
+		}
+		statuses = append(statuses, &status)
+	}
+	if len(stale) > 0 {
+		s.client.HDel(ctx, autoscalerStatusKey, stale...)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Instance < statuses[j].Instance })
+	return statuses, nil
+}
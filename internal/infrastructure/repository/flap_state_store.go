@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"alert_agent/internal/domain/gateway"
+)
+
+// maxFlapUpdateRetries 乐观锁冲突时的最大重试次数
+const maxFlapUpdateRetries = 5
+
+// NewFlapStateStore 创建基于 Redis 的抖动状态存储
+func NewFlapStateStore(redisClient *redis.Client, window time.Duration) gateway.FlapStateStore {
+	if window <= 0 {
+		window = gateway.DefaultFlappingConfig().Window
+	}
+	return &FlapStateStoreImpl{
+		redisClient: redisClient,
+		keyPrefix:   "gateway:flap:",
+		flappingKey: "gateway:flapping",
+		ttl:         2 * window, // 窗口内无新状态时状态自然过期
+	}
+}
+
+// FlapStateStoreImpl 抖动状态存储实现
+type FlapStateStoreImpl struct {
+	redisClient *redis.Client
+	keyPrefix   string
+	flappingKey string
+	ttl         time.Duration
+}
+
+// getStateKey 获取状态存储键
+func (s *FlapStateStoreImpl) getStateKey(fingerprint string) string {
+	return s.keyPrefix + fingerprint
+}
+
+// Get 获取指纹的抖动状态
+func (s *FlapStateStoreImpl) Get(ctx context.Context, fingerprint string) (*gateway.FlapState, error) {
+	data, err := s.redisClient.Get(ctx, s.getStateKey(fingerprint)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get flap state from Redis: %w", err)
+	}
+
+	var state gateway.FlapState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal flap state: %w", err)
+	}
+	return &state, nil
+}
+
+// Update 使用 WATCH 乐观锁读取并更新抖动状态，保证多实例并发时不丢失状态
+func (s *FlapStateStoreImpl) Update(ctx context.Context, fingerprint string, fn func(state *gateway.FlapState) (*gateway.FlapState, error)) (*gateway.FlapState, error) {
+	key := s.getStateKey(fingerprint)
+	var result *gateway.FlapState
+
+	txf := func(tx *redis.Tx) error {
+		var current *gateway.FlapState
+		data, err := tx.Get(ctx, key).Bytes()
+		switch {
+		case err == redis.Nil:
+		case err != nil:
+			return err
+		default:
+			current = &gateway.FlapState{}
+			if err := json.Unmarshal(data, current); err != nil {
+				return fmt.Errorf("failed to unmarshal flap state: %w", err)
+			}
+		}
+
+		updated, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(updated)
+		if err != nil {
+			return fmt.Errorf("failed to marshal flap state: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, payload, s.ttl)
+			if updated.Flapping {
+				pipe.SAdd(ctx, s.flappingKey, fingerprint)
+			} else {
+				pipe.SRem(ctx, s.flappingKey, fingerprint)
+			}
+			return nil
+		})
+		if err == nil {
+			result = updated
+		}
+		return err
+	}
+
+	for i := 0; i < maxFlapUpdateRetries; i++ {
+		err := s.redisClient.Watch(ctx, txf, key)
+		if err == nil {
+			return result, nil
+		}
+		if !errors.Is(err, redis.TxFailedErr) {
+			return nil, fmt.Errorf("failed to update flap state in Redis: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("failed to update flap state %s: too many concurrent updates", fingerprint)
+}
+
+// ListFlapping 获取所有正在抖动的状态，并清理已过期的成员
+func (s *FlapStateStoreImpl) ListFlapping(ctx context.Context) ([]*gateway.FlapState, error) {
+	fingerprints, err := s.redisClient.SMembers(ctx, s.flappingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flapping alerts from Redis: %w", err)
+	}
+
+	states := make([]*gateway.FlapState, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		state, err := s.Get(ctx, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		if state == nil || !state.Flapping {
+			s.redisClient.SRem(ctx, s.flappingKey, fingerprint)
+			continue
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/incident"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IncidentRepository 事件仓储实现
+type IncidentRepository struct {
+	db *gorm.DB
+}
+
+// NewIncidentRepository 创建事件仓储
+func NewIncidentRepository(db *gorm.DB) incident.Repository {
+	return &IncidentRepository{db: db}
+}
+
+// Create 创建事件
+func (r *IncidentRepository) Create(ctx context.Context, inc *incident.Incident) error {
+	if err := r.db.WithContext(ctx).Create(inc).Error; err != nil {
+		return fmt.Errorf("failed to create incident: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取事件，不存在时返回 nil
+func (r *IncidentRepository) GetByID(ctx context.Context, id string) (*incident.Incident, error) {
+	var inc incident.Incident
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&inc).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get incident: %w", err)
+	}
+	return &inc, nil
+}
+
+// GetOpenByGroupID 获取收敛分组对应的未解决事件，不存在时返回 nil
+func (r *IncidentRepository) GetOpenByGroupID(ctx context.Context, groupID string) (*incident.Incident, error) {
+	var inc incident.Incident
+	if err := r.db.WithContext(ctx).
+		Where("group_id = ? AND status <> ?", groupID, incident.StatusResolved).
+		Order("created_at DESC").First(&inc).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get incident by group: %w", err)
+	}
+	return &inc, nil
+}
+
+// GetByAlertID 获取告警所属的事件，不存在时返回 nil
+func (r *IncidentRepository) GetByAlertID(ctx context.Context, alertID uint) (*incident.Incident, error) {
+	var link incident.IncidentAlert
+	if err := r.db.WithContext(ctx).Where("alert_id = ?", alertID).First(&link).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get incident by alert: %w", err)
+	}
+	return r.GetByID(ctx, link.IncidentID)
+}
+
+// List 获取事件列表，按创建时间倒序
+func (r *IncidentRepository) List(ctx context.Context, filter *incident.Filter) ([]*incident.Incident, int64, error) {
+	var incidents []*incident.Incident
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&incident.Incident{})
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.Severity != "" {
+		db = db.Where("severity = ?", filter.Severity)
+	}
+	if filter.Assignee != "" {
+		db = db.Where("assignee = ?", filter.Assignee)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count incidents: %w", err)
+	}
+
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		db = db.Offset(filter.Offset)
+	}
+	if err := db.Order("created_at DESC").Find(&incidents).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list incidents: %w", err)
+	}
+	return incidents, total, nil
+}
+
+// Update 更新事件
+func (r *IncidentRepository) Update(ctx context.Context, inc *incident.Incident) error {
+	if err := r.db.WithContext(ctx).Save(inc).Error; err != nil {
+		return fmt.Errorf("failed to update incident: %w", err)
+	}
+	return nil
+}
+
+// AttachAlerts 关联告警，已属于其他事件的告警被跳过，返回实际关联的告警
+func (r *IncidentRepository) AttachAlerts(ctx context.Context, incidentID string, alertIDs []uint, actor string) ([]uint, error) {
+	var attached []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, alertID := range alertIDs {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&incident.IncidentAlert{
+				IncidentID: incidentID,
+				AlertID:    alertID,
+				AttachedBy: actor,
+				AttachedAt: now,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				attached = append(attached, alertID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach alerts: %w", err)
+	}
+	return attached, nil
+}
+
+// DetachAlerts 解除关联告警，返回实际解除的告警
+func (r *IncidentRepository) DetachAlerts(ctx context.Context, incidentID string, alertIDs []uint) ([]uint, error) {
+	var detached []uint
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&incident.IncidentAlert{}).
+			Where("incident_id = ? AND alert_id IN ?", incidentID, alertIDs).
+			Pluck("alert_id", &detached).Error; err != nil {
+			return err
+		}
+		if len(detached) == 0 {
+			return nil
+		}
+		return tx.Where("incident_id = ? AND alert_id IN ?", incidentID, detached).
+			Delete(&incident.IncidentAlert{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detach alerts: %w", err)
+	}
+	return detached, nil
+}
+
+// ListAlertIDs 获取事件关联的告警
+func (r *IncidentRepository) ListAlertIDs(ctx context.Context, incidentID string) ([]uint, error) {
+	var alertIDs []uint
+	if err := r.db.WithContext(ctx).Model(&incident.IncidentAlert{}).
+		Where("incident_id = ?", incidentID).Order("attached_at ASC").
+		Pluck("alert_id", &alertIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list incident alerts: %w", err)
+	}
+	return alertIDs, nil
+}
+
+// AddTimelineEntry 添加时间线条目
+func (r *IncidentRepository) AddTimelineEntry(ctx context.Context, entry *incident.TimelineEntry) error {
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to add incident timeline entry: %w", err)
+	}
+	return nil
+}
+
+// ListTimeline 获取事件时间线，按时间正序
+func (r *IncidentRepository) ListTimeline(ctx context.Context, incidentID string) ([]*incident.TimelineEntry, error) {
+	var entries []*incident.TimelineEntry
+	if err := r.db.WithContext(ctx).Where("incident_id = ?", incidentID).
+		Order("created_at ASC, id ASC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list incident timeline: %w", err)
+	}
+	return entries, nil
+}
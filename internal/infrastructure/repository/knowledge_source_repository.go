@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// KnowledgeSourceRepositoryImpl 从知识库与已解决告警读取可检索文档
+type KnowledgeSourceRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewKnowledgeSourceRepository 创建知识文档来源
+func NewKnowledgeSourceRepository(db *gorm.DB) analysis.KnowledgeSource {
+	return &KnowledgeSourceRepositoryImpl{db: db}
+}
+
+// resolvedAlertsWithNotes 已解决且填写了处理说明的告警
+func (r *KnowledgeSourceRepositoryImpl) resolvedAlertsWithNotes(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&model.Alert{}).
+		Where("status = ? AND handle_note <> ''", model.AlertStatusResolved)
+}
+
+// afterCursor 筛选排在游标之后的记录，sourceType 为该表文档的来源类型
+func afterCursor(db *gorm.DB, after analysis.KnowledgeCursor, sourceType string) *gorm.DB {
+	switch {
+	case sourceType > after.SourceType:
+		// 更新时间相同时该来源的文档都排在游标之后
+		return db.Where("updated_at >= ?", after.UpdatedAt)
+	case sourceType == after.SourceType:
+		return db.Where("(updated_at > ? OR (updated_at = ? AND id > ?))", after.UpdatedAt, after.UpdatedAt, after.SourceID)
+	default:
+		return db.Where("updated_at > ?", after.UpdatedAt)
+	}
+}
+
+// ListDocuments 获取排在 after 之后的文档，按 (更新时间, 来源类型, 来源ID) 正序
+func (r *KnowledgeSourceRepositoryImpl) ListDocuments(ctx context.Context, after analysis.KnowledgeCursor, limit int) ([]*analysis.KnowledgeDocument, error) {
+	var knowledge []*model.Knowledge
+	if err := afterCursor(r.db.WithContext(ctx), after, analysis.KnowledgeSourceKnowledge).
+		Order("updated_at ASC, id ASC").Limit(limit).Find(&knowledge).Error; err != nil {
+		return nil, fmt.Errorf("failed to list knowledge: %w", err)
+	}
+	var alerts []*model.Alert
+	if err := afterCursor(r.resolvedAlertsWithNotes(ctx), after, analysis.KnowledgeSourceAlert).
+		Order("updated_at ASC, id ASC").Limit(limit).Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list resolved alerts: %w", err)
+	}
+
+	documents := make([]*analysis.KnowledgeDocument, 0, len(knowledge)+len(alerts))
+	for _, k := range knowledge {
+		content := k.Content
+		if k.Summary != "" {
+			content = k.Summary + "\n" + content
+		}
+		documents = append(documents, &analysis.KnowledgeDocument{
+			ID:         analysis.KnowledgeDocumentID(analysis.KnowledgeSourceKnowledge, k.ID),
+			SourceType: analysis.KnowledgeSourceKnowledge,
+			SourceID:   k.ID,
+			Title:      k.Title,
+			Content:    content,
+			UpdatedAt:  k.UpdatedAt,
+		})
+	}
+	for _, alert := range alerts {
+		documents = append(documents, &analysis.KnowledgeDocument{
+			ID:         analysis.KnowledgeDocumentID(analysis.KnowledgeSourceAlert, alert.ID),
+			SourceType: analysis.KnowledgeSourceAlert,
+			SourceID:   alert.ID,
+			Title:      alert.Title,
+			Content:    alertDocumentContent(alert),
+			UpdatedAt:  alert.UpdatedAt,
+		})
+	}
+
+	// 两类文档合并后按同一顺序截取
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].Cursor().Before(documents[j].Cursor())
+	})
+	if len(documents) > limit {
+		documents = documents[:limit]
+	}
+	return documents, nil
+}
+
+// ListDocumentIDs 获取当前所有文档ID
+func (r *KnowledgeSourceRepositoryImpl) ListDocumentIDs(ctx context.Context) ([]string, error) {
+	var knowledgeIDs []uint
+	if err := r.db.WithContext(ctx).Model(&model.Knowledge{}).Pluck("id", &knowledgeIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list knowledge ids: %w", err)
+	}
+	var alertIDs []uint
+	if err := r.resolvedAlertsWithNotes(ctx).Pluck("id", &alertIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list resolved alert ids: %w", err)
+	}
+
+	ids := make([]string, 0, len(knowledgeIDs)+len(alertIDs))
+	for _, id := range knowledgeIDs {
+		ids = append(ids, analysis.KnowledgeDocumentID(analysis.KnowledgeSourceKnowledge, id))
+	}
+	for _, id := range alertIDs {
+		ids = append(ids, analysis.KnowledgeDocumentID(analysis.KnowledgeSourceAlert, id))
+	}
+	return ids, nil
+}
+
+// alertDocumentContent 已解决告警的检索正文，处理说明放在最前
+func alertDocumentContent(alert *model.Alert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "处理说明：%s\n", alert.HandleNote)
+	fmt.Fprintf(&b, "告警：%s（%s，来源 %s）\n", alert.Name, alert.Level, alert.Source)
+	fmt.Fprintf(&b, "内容：%s\n", alert.Content)
+	if alert.Analysis != "" {
+		fmt.Fprintf(&b, "分析：%s\n", alert.Analysis)
+	}
+	return b.String()
+}
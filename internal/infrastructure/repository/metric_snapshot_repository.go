@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// MetricSnapshotRepositoryImpl 指标快照仓储实现
+type MetricSnapshotRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewMetricSnapshotRepository 创建指标快照仓储
+func NewMetricSnapshotRepository(db *gorm.DB) gateway.MetricSnapshotRepository {
+	return &MetricSnapshotRepositoryImpl{db: db}
+}
+
+// GetRule 获取告警规则
+func (r *MetricSnapshotRepositoryImpl) GetRule(ctx context.Context, ruleID uint) (*model.Rule, error) {
+	var rule model.Rule
+	if err := r.db.WithContext(ctx).First(&rule, ruleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// SaveMetricSnapshot 保存告警指标快照，未发送的通知追加文本图表
+func (r *MetricSnapshotRepositoryImpl) SaveMetricSnapshot(ctx context.Context, alertID uint, snapshot *model.MetricSnapshot) error {
+	alert := &model.Alert{}
+	if err := alert.SetMetricSnapshot(snapshot); err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Alert{}).Where("id = ?", alertID).
+			Update("metric_snapshot", alert.MetricSnapshot).Error; err != nil {
+			return fmt.Errorf("failed to save metric snapshot: %w", err)
+		}
+
+		var records []*model.NotifyRecord
+		if err := tx.Where("alert_id = ? AND status = ?", alertID, model.NotifyStatusPending).
+			Find(&records).Error; err != nil {
+			return fmt.Errorf("failed to list pending notify records: %w", err)
+		}
+		chart := snapshot.TextChart()
+		for _, record := range records {
+			if err := tx.Model(record).Update("content", record.Content+"\n\n"+chart).Error; err != nil {
+				return fmt.Errorf("failed to update notify record: %w", err)
+			}
+		}
+		return nil
+	})
+}
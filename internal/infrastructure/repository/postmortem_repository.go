@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PostmortemRepositoryImpl 复盘报告数据仓储实现
+type PostmortemRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPostmortemRepository 创建复盘报告数据仓储
+func NewPostmortemRepository(db *gorm.DB) incident.PostmortemRepository {
+	return &PostmortemRepositoryImpl{db: db}
+}
+
+// ListAlerts 批量获取告警，按创建时间正序
+func (r *PostmortemRepositoryImpl) ListAlerts(ctx context.Context, alertIDs []uint) ([]*model.Alert, error) {
+	var alerts []*model.Alert
+	if len(alertIDs) == 0 {
+		return alerts, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", alertIDs).
+		Order("created_at ASC").Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// ListNotifyRecords 获取告警的通知记录，按时间正序
+func (r *PostmortemRepositoryImpl) ListNotifyRecords(ctx context.Context, alertIDs []uint) ([]*model.NotifyRecord, error) {
+	var records []*model.NotifyRecord
+	if len(alertIDs) == 0 {
+		return records, nil
+	}
+	if err := r.db.WithContext(ctx).Where("alert_id IN ?", alertIDs).
+		Order("created_at ASC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list notify records: %w", err)
+	}
+	return records, nil
+}
+
+// SaveKnowledge 保存知识库条目，相同 source 与 source_id 的条目被覆盖
+func (r *PostmortemRepositoryImpl) SaveKnowledge(ctx context.Context, knowledge *model.Knowledge) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.Knowledge
+		err := tx.Where("source = ? AND source_id = ?", knowledge.Source, knowledge.SourceID).First(&existing).Error
+		switch {
+		case err == nil:
+			knowledge.ID = existing.ID
+			knowledge.CreatedAt = existing.CreatedAt
+			// 保留已生成的向量
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"title":    knowledge.Title,
+				"content":  knowledge.Content,
+				"category": knowledge.Category,
+				"tags":     knowledge.Tags,
+				"summary":  knowledge.Summary,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update knowledge: %w", err)
+			}
+			return nil
+		case err == gorm.ErrRecordNotFound:
+			if err := tx.Create(knowledge).Error; err != nil {
+				return fmt.Errorf("failed to create knowledge: %w", err)
+			}
+			return nil
+		default:
+			return fmt.Errorf("failed to get knowledge: %w", err)
+		}
+	})
+}
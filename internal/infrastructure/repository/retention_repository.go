@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/retention"
+	"alert_agent/internal/infrastructure/migration"
+	"alert_agent/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// alertArchive 告警归档格式，补充 model.Alert 中不输出 JSON 的时间字段
+type alertArchive struct {
+	*model.Alert
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	HandleTime *time.Time `json:"handle_time,omitempty"`
+	NotifyTime *time.Time `json:"notify_time,omitempty"`
+}
+
+func newAlertArchive(a *model.Alert) *alertArchive {
+	archived := &alertArchive{
+		Alert:      a,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
+		HandleTime: a.HandleTime,
+		NotifyTime: a.NotifyTime,
+	}
+	if a.DeletedAt.Valid {
+		archived.DeletedAt = &a.DeletedAt.Time
+	}
+	return archived
+}
+
+func (a *alertArchive) toAlert() *model.Alert {
+	alert := a.Alert
+	alert.CreatedAt = a.CreatedAt
+	alert.UpdatedAt = a.UpdatedAt
+	alert.HandleTime = a.HandleTime
+	alert.NotifyTime = a.NotifyTime
+	if a.DeletedAt != nil {
+		alert.DeletedAt = gorm.DeletedAt{Time: *a.DeletedAt, Valid: true}
+	}
+	return alert
+}
+
+// RetentionRepositoryImpl 数据保留仓储实现
+type RetentionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository 创建数据保留仓储
+func NewRetentionRepository(db *gorm.DB) retention.Repository {
+	return &RetentionRepositoryImpl{db: db}
+}
+
+// ScanCandidates 按创建时间与主键顺序获取 after 之后、创建时间早于 before 的记录，包含已软删除的告警
+func (r *RetentionRepositoryImpl) ScanCandidates(ctx context.Context, table retention.Table, before time.Time, after *retention.Cursor, limit int) ([]*retention.Row, error) {
+	order := clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: "created_at"}},
+		{Column: clause.Column{Name: "id"}},
+	}}
+
+	switch table {
+	case retention.TableAlerts:
+		query := r.db.WithContext(ctx).Unscoped().Where("created_at < ?", before)
+		if after != nil {
+			id, err := strconv.ParseUint(after.ID, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alert cursor: %s", after.ID)
+			}
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", after.CreatedAt, after.CreatedAt, id)
+		}
+		var alerts []*model.Alert
+		if err := query.Clauses(order).Limit(limit).Find(&alerts).Error; err != nil {
+			return nil, fmt.Errorf("failed to scan alerts: %w", err)
+		}
+		rows := make([]*retention.Row, 0, len(alerts))
+		for _, a := range alerts {
+			data, err := json.Marshal(newAlertArchive(a))
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode alert %d: %w", a.ID, err)
+			}
+			severity := a.Severity
+			if severity == "" {
+				severity = a.Level
+			}
+			rows = append(rows, &retention.Row{
+				ID:        strconv.FormatUint(uint64(a.ID), 10),
+				Status:    a.Status,
+				Severity:  severity,
+				CreatedAt: a.CreatedAt,
+				Data:      data,
+			})
+		}
+		return rows, nil
+
+	case retention.TableProcessingRecords:
+		query := r.db.WithContext(ctx).Where("created_at < ?", before)
+		if after != nil {
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", after.CreatedAt, after.CreatedAt, after.ID)
+		}
+		var records []*gateway.AlertProcessingRecord
+		if err := query.Clauses(order).Limit(limit).Find(&records).Error; err != nil {
+			return nil, fmt.Errorf("failed to scan processing records: %w", err)
+		}
+		rows := make([]*retention.Row, 0, len(records))
+		for _, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode processing record %s: %w", record.ID, err)
+			}
+			rows = append(rows, &retention.Row{
+				ID:        record.ID,
+				Status:    string(record.Status),
+				CreatedAt: record.CreatedAt,
+				Data:      data,
+			})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("unsupported table: %s", table)
+}
+
+// Delete 物理删除记录，返回删除数量
+func (r *RetentionRepositoryImpl) Delete(ctx context.Context, table retention.Table, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var result *gorm.DB
+	switch table {
+	case retention.TableAlerts:
+		alertIDs := make([]uint64, 0, len(ids))
+		for _, id := range ids {
+			alertID, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid alert id: %s", id)
+			}
+			alertIDs = append(alertIDs, alertID)
+		}
+		result = r.db.WithContext(ctx).Unscoped().Where("id IN ?", alertIDs).Delete(&model.Alert{})
+	case retention.TableProcessingRecords:
+		result = r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&gateway.AlertProcessingRecord{})
+	default:
+		return 0, fmt.Errorf("unsupported table: %s", table)
+	}
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete %s: %w", table, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Restore 写回归档记录，主键已存在的跳过，返回写入数量
+func (r *RetentionRepositoryImpl) Restore(ctx context.Context, table retention.Table, rows []json.RawMessage) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	var value interface{}
+	switch table {
+	case retention.TableAlerts:
+		alerts := make([]*model.Alert, 0, len(rows))
+		for _, row := range rows {
+			archived := &alertArchive{Alert: &model.Alert{}}
+			if err := json.Unmarshal(row, archived); err != nil {
+				return 0, fmt.Errorf("failed to decode archived alert: %w", err)
+			}
+			alerts = append(alerts, archived.toAlert())
+		}
+		value = &alerts
+	case retention.TableProcessingRecords:
+		records := make([]*gateway.AlertProcessingRecord, 0, len(rows))
+		for _, row := range rows {
+			var record gateway.AlertProcessingRecord
+			if err := json.Unmarshal(row, &record); err != nil {
+				return 0, fmt.Errorf("failed to decode archived processing record: %w", err)
+			}
+			records = append(records, &record)
+		}
+		value = &records
+	default:
+		return 0, fmt.Errorf("unsupported table: %s", table)
+	}
+
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(value)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to restore %s: %w", table, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// SaveArchive 保存归档文件记录
+func (r *RetentionRepositoryImpl) SaveArchive(ctx context.Context, archive *retention.Archive) error {
+	if err := r.db.WithContext(ctx).Create(archive).Error; err != nil {
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+	return nil
+}
+
+// ListArchives 获取时间范围与 [from, to) 重叠的归档文件，按时间正序
+func (r *RetentionRepositoryImpl) ListArchives(ctx context.Context, filter *retention.ArchiveFilter) ([]*retention.Archive, error) {
+	query := r.db.WithContext(ctx).Model(&retention.Archive{})
+	if filter.Table != "" {
+		query = query.Where("source_table = ?", filter.Table)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("range_to >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("range_from < ?", filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var archives []*retention.Archive
+	if err := query.Order("range_from ASC, id ASC").Find(&archives).Error; err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	return archives, nil
+}
+
+// MarkRestored 记录归档文件的恢复时间
+func (r *RetentionRepositoryImpl) MarkRestored(ctx context.Context, id uint, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&retention.Archive{}).
+		Where("id = ?", id).Update("restored_at", at).Error; err != nil {
+		return fmt.Errorf("failed to mark archive restored: %w", err)
+	}
+	return nil
+}
+
+// EnsurePartitions 从兜底分区拆分出截至 through 所在月份的月分区，告警表未分区时不处理
+func (r *RetentionRepositoryImpl) EnsurePartitions(ctx context.Context, through time.Time) (int, error) {
+	db := r.db.WithContext(ctx)
+	partitions, err := migration.AlertPartitions(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list alert partitions: %w", err)
+	}
+	if len(partitions) == 0 {
+		return 0, nil
+	}
+
+	// 最新的月分区，分区名按 pYYYYMM 排列在兜底分区之前
+	var latest time.Time
+	for _, name := range partitions {
+		if month, err := time.Parse("200601", strings.TrimPrefix(name, "p")); err == nil && month.After(latest) {
+			latest = month
+		}
+	}
+	if latest.IsZero() || !migration.MonthStart(through).After(latest) {
+		return 0, nil
+	}
+
+	from := latest.AddDate(0, 1, 0)
+	added := 0
+	for month := from; !month.After(migration.MonthStart(through)); month = month.AddDate(0, 1, 0) {
+		added++
+	}
+	if err := db.Exec(fmt.Sprintf("ALTER TABLE alerts REORGANIZE PARTITION %s INTO (%s)",
+		migration.MaxValuePartition, migration.MonthlyPartitionDefs(from, through))).Error; err != nil {
+		return 0, fmt.Errorf("failed to add alert partitions: %w", err)
+	}
+	return added, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/shared/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RoutingConfigRepository 路由配置仓储实现
+type RoutingConfigRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewRoutingConfigRepository 创建路由配置仓储
+func NewRoutingConfigRepository(db *gorm.DB) gateway.RoutingConfigRepository {
+	return &RoutingConfigRepository{
+		db:     db,
+		logger: logger.WithComponent("routing-config-repository"),
+	}
+}
+
+// Create 创建路由配置
+func (r *RoutingConfigRepository) Create(ctx context.Context, config *gateway.RoutingConfig) error {
+	if err := r.db.WithContext(ctx).Create(config).Error; err != nil {
+		r.logger.Error("failed to create routing config", zap.Int("version", config.Version), zap.Error(err))
+		return fmt.Errorf("failed to create routing config: %w", err)
+	}
+	return nil
+}
+
+// GetByVersion 根据版本获取路由配置，不存在时返回 nil
+func (r *RoutingConfigRepository) GetByVersion(ctx context.Context, version int) (*gateway.RoutingConfig, error) {
+	var config gateway.RoutingConfig
+	if err := r.db.WithContext(ctx).Where("version = ?", version).First(&config).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get routing config: %w", err)
+	}
+	return &config, nil
+}
+
+// GetActive 获取当前生效的路由配置，不存在时返回 nil
+func (r *RoutingConfigRepository) GetActive(ctx context.Context) (*gateway.RoutingConfig, error) {
+	var config gateway.RoutingConfig
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Order("version DESC").First(&config).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active routing config: %w", err)
+	}
+	return &config, nil
+}
+
+// List 获取路由配置列表，按版本倒序
+func (r *RoutingConfigRepository) List(ctx context.Context, limit, offset int) ([]*gateway.RoutingConfig, int64, error) {
+	var configs []*gateway.RoutingConfig
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&gateway.RoutingConfig{})
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count routing configs: %w", err)
+	}
+
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	if offset > 0 {
+		db = db.Offset(offset)
+	}
+	if err := db.Order("version DESC").Find(&configs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list routing configs: %w", err)
+	}
+
+	return configs, total, nil
+}
+
+// LatestVersion 获取最新版本号，没有配置时返回 0
+func (r *RoutingConfigRepository) LatestVersion(ctx context.Context) (int, error) {
+	var version *int
+	if err := r.db.WithContext(ctx).Model(&gateway.RoutingConfig{}).Select("MAX(version)").Scan(&version).Error; err != nil {
+		return 0, fmt.Errorf("failed to get latest routing config version: %w", err)
+	}
+	if version == nil {
+		return 0, nil
+	}
+	return *version, nil
+}
+
+// Activate 激活指定版本并停用其他版本
+func (r *RoutingConfigRepository) Activate(ctx context.Context, version int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&gateway.RoutingConfig{}).Where("active = ?", true).
+			Updates(map[string]interface{}{"active": false, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to deactivate routing configs: %w", err)
+		}
+
+		result := tx.Model(&gateway.RoutingConfig{}).Where("version = ?", version).
+			Updates(map[string]interface{}{"active": true, "updated_at": now})
+		if result.Error != nil {
+			return fmt.Errorf("failed to activate routing config: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("routing config version %d not found", version)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"alert_agent/internal/domain/rule"
+	"alert_agent/internal/model"
+)
+
+// NewRuleStateStore 创建基于 Redis 的规则评估状态存储
+func NewRuleStateStore(redisClient *redis.Client) rule.StateStore {
+	return &RuleStateStoreImpl{
+		redisClient: redisClient,
+		keyPrefix:   "rule:eval:state:",
+	}
+}
+
+// RuleStateStoreImpl 规则评估状态存储实现，每条规则一个哈希，字段为序列指纹
+type RuleStateStoreImpl struct {
+	redisClient *redis.Client
+	keyPrefix   string
+}
+
+func (s *RuleStateStoreImpl) key(ruleID uint) string {
+	return fmt.Sprintf("%s%d", s.keyPrefix, ruleID)
+}
+
+// List 获取规则下所有时间序列状态
+func (s *RuleStateStoreImpl) List(ctx context.Context, ruleID uint) (map[string]*rule.SeriesState, error) {
+	values, err := s.redisClient.HGetAll(ctx, s.key(ruleID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rule state from Redis: %w", err)
+	}
+
+	states := make(map[string]*rule.SeriesState, len(values))
+	for fingerprint, data := range values {
+		var state rule.SeriesState
+		if err := json.Unmarshal([]byte(data), &state); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rule state: %w", err)
+		}
+		states[fingerprint] = &state
+	}
+	return states, nil
+}
+
+// Save 保存时间序列状态
+func (s *RuleStateStoreImpl) Save(ctx context.Context, ruleID uint, state *rule.SeriesState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rule state: %w", err)
+	}
+	if err := s.redisClient.HSet(ctx, s.key(ruleID), state.Fingerprint, data).Err(); err != nil {
+		return fmt.Errorf("failed to save rule state to Redis: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除时间序列状态
+func (s *RuleStateStoreImpl) Delete(ctx context.Context, ruleID uint, fingerprint string) error {
+	if err := s.redisClient.HDel(ctx, s.key(ruleID), fingerprint).Err(); err != nil {
+		return fmt.Errorf("failed to delete rule state from Redis: %w", err)
+	}
+	return nil
+}
+
+// renewLeaseScript 持有者续约，未持有时尝试获取
+var renewLeaseScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if current == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaseScript 仅持有者可以释放租约
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// NewLeaseManager 创建基于 Redis 的租约管理器
+func NewLeaseManager(redisClient *redis.Client) rule.LeaseManager {
+	return &LeaseManagerImpl{redisClient: redisClient}
+}
+
+// LeaseManagerImpl 租约管理器实现
+type LeaseManagerImpl struct {
+	redisClient *redis.Client
+}
+
+// Acquire 获取或续约租约
+func (m *LeaseManagerImpl) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	acquired, err := renewLeaseScript.Run(ctx, m.redisClient, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	return acquired == 1, nil
+}
+
+// Release 释放自己持有的租约
+func (m *LeaseManagerImpl) Release(ctx context.Context, key, owner string) error {
+	if err := releaseLeaseScript.Run(ctx, m.redisClient, []string{key}, owner).Err(); err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// NewRuleSource 创建基于 GORM 的规则来源
+func NewRuleSource(db *gorm.DB) rule.RuleSource {
+	return &RuleSourceImpl{db: db}
+}
+
+// RuleSourceImpl 规则来源实现
+type RuleSourceImpl struct {
+	db *gorm.DB
+}
+
+// ListEnabledRules 获取启用的规则
+func (s *RuleSourceImpl) ListEnabledRules(ctx context.Context) ([]*model.Rule, error) {
+	var rules []*model.Rule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list enabled rules: %w", err)
+	}
+	return rules, nil
+}
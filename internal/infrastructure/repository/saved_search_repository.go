@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"alert_agent/internal/domain/alert"
+
+	"gorm.io/gorm"
+)
+
+// SavedSearchRepository 保存的告警搜索仓储实现
+type SavedSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewSavedSearchRepository 创建保存的告警搜索仓储
+func NewSavedSearchRepository(db *gorm.DB) alert.SavedSearchRepository {
+	return &SavedSearchRepository{db: db}
+}
+
+// Create 创建保存的搜索
+func (r *SavedSearchRepository) Create(ctx context.Context, search *alert.SavedSearch) error {
+	if err := r.db.WithContext(ctx).Create(search).Error; err != nil {
+		return fmt.Errorf("failed to create saved search: %w", err)
+	}
+	return nil
+}
+
+// GetByID 获取保存的搜索，不存在时返回 nil
+func (r *SavedSearchRepository) GetByID(ctx context.Context, id uint) (*alert.SavedSearch, error) {
+	var search alert.SavedSearch
+	if err := r.db.WithContext(ctx).First(&search, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return &search, nil
+}
+
+// GetByName 按用户与名称获取保存的搜索，不存在时返回 nil
+func (r *SavedSearchRepository) GetByName(ctx context.Context, owner, name string) (*alert.SavedSearch, error) {
+	var search alert.SavedSearch
+	if err := r.db.WithContext(ctx).Where("owner = ? AND name = ?", owner, name).First(&search).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get saved search: %w", err)
+	}
+	return &search, nil
+}
+
+// ListByOwner 获取用户保存的搜索，按名称排序
+func (r *SavedSearchRepository) ListByOwner(ctx context.Context, owner string) ([]*alert.SavedSearch, error) {
+	var searches []*alert.SavedSearch
+	if err := r.db.WithContext(ctx).Where("owner = ?", owner).Order("name ASC").Find(&searches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// Update 更新保存的搜索
+func (r *SavedSearchRepository) Update(ctx context.Context, search *alert.SavedSearch) error {
+	if err := r.db.WithContext(ctx).Save(search).Error; err != nil {
+		return fmt.Errorf("failed to update saved search: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除保存的搜索
+func (r *SavedSearchRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Delete(&alert.SavedSearch{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,513 @@
+package vectorindex
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+)
+
+// snapshotVersion 快照格式版本，格式不兼容时递增
+const snapshotVersion = 1
+
+// HNSWConfig HNSW 索引配置
+type HNSWConfig struct {
+	M              int    // 每层的最大邻居数，第 0 层为 2M
+	EfConstruction int    // 构建时的候选集大小
+	EfSearch       int    // 检索时的候选集大小
+	SnapshotPath   string // 快照文件路径，为空时不持久化
+}
+
+// DefaultHNSWConfig 默认 HNSW 索引配置
+func DefaultHNSWConfig() HNSWConfig {
+	return HNSWConfig{
+		M:              16,
+		EfConstruction: 200,
+		EfSearch:       64,
+	}
+}
+
+// hnswNode 索引节点，字段导出用于快照编码
+type hnswNode struct {
+	ID        string
+	Vector    []float32 // 已归一化，内积即余弦相似度
+	Level     int
+	Neighbors [][]int32
+	Deleted   bool
+	Meta      analysis.VectorMeta
+}
+
+// hnswSnapshot 索引快照
+type hnswSnapshot struct {
+	Version  int
+	Dim      int
+	Entry    int32
+	MaxLevel int
+	Nodes    []*hnswNode
+}
+
+// HNSW 进程内的分层可导航小世界图索引，删除与替换采用墓碑标记，墓碑过多时重建
+type HNSW struct {
+	mu        sync.RWMutex
+	config    HNSWConfig
+	nodes     []*hnswNode
+	ids       map[string]int32
+	entry     int32
+	maxLevel  int
+	dim       int
+	deleted   int
+	dirty     bool
+	levelMult float64
+	rng       *rand.Rand
+}
+
+// NewHNSW 创建空索引
+func NewHNSW(config HNSWConfig) *HNSW {
+	defaults := DefaultHNSWConfig()
+	if config.M <= 1 {
+		config.M = defaults.M
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = defaults.EfConstruction
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = defaults.EfSearch
+	}
+	return &HNSW{
+		config:    config,
+		ids:       make(map[string]int32),
+		entry:     -1,
+		levelMult: 1 / math.Log(float64(config.M)),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// OpenHNSW 创建索引并加载已有快照，快照不存在时返回空索引
+func OpenHNSW(config HNSWConfig) (*HNSW, error) {
+	index := NewHNSW(config)
+	if config.SnapshotPath == "" {
+		return index, nil
+	}
+
+	file, err := os.Open(config.SnapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index snapshot: %w", err)
+	}
+	defer file.Close()
+
+	var snapshot hnswSnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode index snapshot: %w", err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported index snapshot version: %d", snapshot.Version)
+	}
+
+	index.nodes = snapshot.Nodes
+	index.entry = snapshot.Entry
+	index.maxLevel = snapshot.MaxLevel
+	index.dim = snapshot.Dim
+	for i, node := range index.nodes {
+		if node.Deleted {
+			index.deleted++
+			continue
+		}
+		index.ids[node.ID] = int32(i)
+	}
+	return index, nil
+}
+
+// Persist 将索引快照原子地写入文件，索引未变化时跳过
+func (h *HNSW) Persist() error {
+	if h.config.SnapshotPath == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.dirty {
+		return nil
+	}
+
+	dir := filepath.Dir(h.config.SnapshotPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(h.config.SnapshotPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	snapshot := hnswSnapshot{
+		Version:  snapshotVersion,
+		Dim:      h.dim,
+		Entry:    h.entry,
+		MaxLevel: h.maxLevel,
+		Nodes:    h.nodes,
+	}
+	if err := gob.NewEncoder(tmp).Encode(&snapshot); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to encode index snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write index snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.config.SnapshotPath); err != nil {
+		return fmt.Errorf("failed to replace index snapshot: %w", err)
+	}
+
+	h.dirty = false
+	return nil
+}
+
+// Upsert 写入或替换文档向量
+func (h *HNSW) Upsert(id string, vector []float32, meta analysis.VectorMeta) error {
+	normalized, ok := normalize(vector)
+	if !ok {
+		return fmt.Errorf("vector for %s is empty or zero", id)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.dim != 0 && len(normalized) != h.dim {
+		return fmt.Errorf("vector dimension %d does not match index dimension %d", len(normalized), h.dim)
+	}
+	h.dim = len(normalized)
+
+	if existing, ok := h.ids[id]; ok {
+		h.nodes[existing].Deleted = true
+		h.deleted++
+	}
+	h.insert(&hnswNode{ID: id, Vector: normalized, Meta: meta})
+	h.dirty = true
+
+	if h.deleted > 64 && h.deleted > len(h.nodes)/2 {
+		h.rebuild()
+	}
+	return nil
+}
+
+// Delete 删除文档
+func (h *HNSW) Delete(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	index, ok := h.ids[id]
+	if !ok {
+		return false
+	}
+	h.nodes[index].Deleted = true
+	delete(h.ids, id)
+	h.deleted++
+	h.dirty = true
+	return true
+}
+
+// Search 检索最相似的 k 个文档
+func (h *HNSW) Search(vector []float32, k int) []analysis.VectorHit {
+	query, ok := normalize(vector)
+	if !ok || k <= 0 {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.entry < 0 || len(query) != h.dim {
+		return nil
+	}
+
+	ep := h.entry
+	for level := h.maxLevel; level > 0; level-- {
+		ep = h.greedy(query, ep, level)
+	}
+	// 墓碑节点参与导航但不返回，需扩大候选集
+	ef := h.config.EfSearch
+	if want := k + h.deleted; want > ef {
+		ef = want
+	}
+	candidates := h.searchLayer(query, []int32{ep}, ef, 0)
+
+	hits := make([]analysis.VectorHit, 0, k)
+	for _, c := range candidates {
+		node := h.nodes[c.index]
+		if node.Deleted {
+			continue
+		}
+		hits = append(hits, analysis.VectorHit{ID: node.ID, Score: 1 - c.distance, Meta: node.Meta})
+		if len(hits) == k {
+			break
+		}
+	}
+	return hits
+}
+
+// Get 获取文档元数据
+func (h *HNSW) Get(id string) (analysis.VectorMeta, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	index, ok := h.ids[id]
+	if !ok {
+		return analysis.VectorMeta{}, false
+	}
+	return h.nodes[index].Meta, true
+}
+
+// IDs 获取所有文档ID
+func (h *HNSW) IDs() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ids := make([]string, 0, len(h.ids))
+	for id := range h.ids {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Len 文档数量
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.ids)
+}
+
+// insert 插入节点，调用方需持有写锁
+func (h *HNSW) insert(node *hnswNode) {
+	node.Level = int(-math.Log(1-h.rng.Float64()) * h.levelMult)
+	node.Neighbors = make([][]int32, node.Level+1)
+	index := int32(len(h.nodes))
+	h.nodes = append(h.nodes, node)
+	h.ids[node.ID] = index
+
+	if h.entry < 0 {
+		h.entry = index
+		h.maxLevel = node.Level
+		return
+	}
+
+	ep := h.entry
+	for level := h.maxLevel; level > node.Level; level-- {
+		ep = h.greedy(node.Vector, ep, level)
+	}
+
+	entryPoints := []int32{ep}
+	for level := minInt(node.Level, h.maxLevel); level >= 0; level-- {
+		candidates := h.searchLayer(node.Vector, entryPoints, h.config.EfConstruction, level)
+		maxNeighbors := h.maxNeighbors(level)
+
+		neighbors := make([]int32, 0, maxNeighbors)
+		for _, c := range candidates {
+			if len(neighbors) == maxNeighbors {
+				break
+			}
+			neighbors = append(neighbors, c.index)
+		}
+		node.Neighbors[level] = neighbors
+
+		for _, neighbor := range neighbors {
+			h.connect(neighbor, index, level)
+		}
+
+		entryPoints = entryPoints[:0]
+		for _, c := range candidates {
+			entryPoints = append(entryPoints, c.index)
+		}
+	}
+
+	if node.Level > h.maxLevel {
+		h.entry = index
+		h.maxLevel = node.Level
+	}
+}
+
+// connect 为邻居添加反向连接，超过上限时保留最近的邻居
+func (h *HNSW) connect(from, to int32, level int) {
+	node := h.nodes[from]
+	node.Neighbors[level] = append(node.Neighbors[level], to)
+
+	maxNeighbors := h.maxNeighbors(level)
+	if len(node.Neighbors[level]) <= maxNeighbors {
+		return
+	}
+	neighbors := node.Neighbors[level]
+	sort.Slice(neighbors, func(i, j int) bool {
+		return h.distance(node.Vector, neighbors[i]) < h.distance(node.Vector, neighbors[j])
+	})
+	node.Neighbors[level] = neighbors[:maxNeighbors]
+}
+
+// rebuild 丢弃墓碑节点并重建索引，调用方需持有写锁
+func (h *HNSW) rebuild() {
+	live := make([]*hnswNode, 0, len(h.ids))
+	for _, node := range h.nodes {
+		if !node.Deleted {
+			live = append(live, node)
+		}
+	}
+
+	h.nodes = make([]*hnswNode, 0, len(live))
+	h.ids = make(map[string]int32, len(live))
+	h.entry = -1
+	h.maxLevel = 0
+	h.deleted = 0
+	for _, node := range live {
+		h.insert(&hnswNode{ID: node.ID, Vector: node.Vector, Meta: node.Meta})
+	}
+}
+
+func (h *HNSW) maxNeighbors(level int) int {
+	if level == 0 {
+		return 2 * h.config.M
+	}
+	return h.config.M
+}
+
+func (h *HNSW) distance(query []float32, index int32) float64 {
+	return 1 - dot(query, h.nodes[index].Vector)
+}
+
+// greedy 在单层中贪心移动到离查询最近的节点
+func (h *HNSW) greedy(query []float32, ep int32, level int) int32 {
+	best := h.distance(query, ep)
+	for changed := true; changed; {
+		changed = false
+		for _, neighbor := range h.nodes[ep].Neighbors[level] {
+			if d := h.distance(query, neighbor); d < best {
+				best, ep, changed = d, neighbor, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer 在单层中检索 ef 个最近的节点，按距离升序返回
+func (h *HNSW) searchLayer(query []float32, entryPoints []int32, ef, level int) []candidate {
+	visited := make(map[int32]struct{}, ef*4)
+	candidates := &minHeap{}
+	results := &maxHeap{}
+	for _, ep := range entryPoints {
+		if _, ok := visited[ep]; ok {
+			continue
+		}
+		visited[ep] = struct{}{}
+		c := candidate{index: ep, distance: h.distance(query, ep)}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		current := heap.Pop(candidates).(candidate)
+		if results.Len() >= ef && current.distance > (*results)[0].distance {
+			break
+		}
+		node := h.nodes[current.index]
+		if level >= len(node.Neighbors) {
+			continue
+		}
+		for _, neighbor := range node.Neighbors[level] {
+			if _, ok := visited[neighbor]; ok {
+				continue
+			}
+			visited[neighbor] = struct{}{}
+			d := h.distance(query, neighbor)
+			if results.Len() < ef || d < (*results)[0].distance {
+				heap.Push(candidates, candidate{index: neighbor, distance: d})
+				heap.Push(results, candidate{index: neighbor, distance: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	sorted := make([]candidate, results.Len())
+	for i := len(sorted) - 1; i >= 0; i-- {
+		sorted[i] = heap.Pop(results).(candidate)
+	}
+	return sorted
+}
+
+// normalize 归一化向量，零向量返回 false
+func normalize(vector []float32) ([]float32, bool) {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return nil, false
+	}
+	norm = math.Sqrt(norm)
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = float32(float64(v) / norm)
+	}
+	return normalized, true
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type candidate struct {
+	index    int32
+	distance float64
+}
+
+// minHeap 按距离升序的候选堆
+type minHeap []candidate
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].distance < h[j].distance }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// maxHeap 按距离降序的结果堆，堆顶为当前最远的结果
+type maxHeap []candidate
+
+func (h maxHeap) Len() int            { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].distance > h[j].distance }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,143 @@
+package vectorindex
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"alert_agent/internal/domain/analysis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomVector(rng *rand.Rand, dim int) []float32 {
+	vector := make([]float32, dim)
+	for i := range vector {
+		vector[i] = float32(rng.NormFloat64())
+	}
+	return vector
+}
+
+// bruteForce 精确检索，用于计算召回率
+func bruteForce(vectors map[string][]float32, query []float32, k int) []string {
+	type scored struct {
+		id    string
+		score float64
+	}
+	q, _ := normalize(query)
+	all := make([]scored, 0, len(vectors))
+	for id, vector := range vectors {
+		v, _ := normalize(vector)
+		all = append(all, scored{id: id, score: dot(q, v)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score > all[j].score })
+	ids := make([]string, 0, k)
+	for _, s := range all[:k] {
+		ids = append(ids, s.id)
+	}
+	return ids
+}
+
+func TestHNSW_Recall(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	index := NewHNSW(DefaultHNSWConfig())
+	vectors := make(map[string][]float32)
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("doc:%d", i)
+		vectors[id] = randomVector(rng, 32)
+		require.NoError(t, index.Upsert(id, vectors[id], analysis.VectorMeta{Title: id}))
+	}
+	require.Equal(t, 1000, index.Len())
+
+	const k = 5
+	found, total := 0, 0
+	for i := 0; i < 50; i++ {
+		query := randomVector(rng, 32)
+		expected := make(map[string]struct{}, k)
+		for _, id := range bruteForce(vectors, query, k) {
+			expected[id] = struct{}{}
+		}
+		hits := index.Search(query, k)
+		require.Len(t, hits, k)
+		for j, hit := range hits {
+			if _, ok := expected[hit.ID]; ok {
+				found++
+			}
+			assert.Equal(t, hit.ID, hit.Meta.Title)
+			if j > 0 {
+				assert.GreaterOrEqual(t, hits[j-1].Score, hit.Score)
+			}
+		}
+		total += k
+	}
+	assert.GreaterOrEqual(t, float64(found)/float64(total), 0.9)
+}
+
+func TestHNSW_UpsertAndDelete(t *testing.T) {
+	index := NewHNSW(DefaultHNSWConfig())
+	require.NoError(t, index.Upsert("a", []float32{1, 0, 0}, analysis.VectorMeta{Title: "a"}))
+	require.NoError(t, index.Upsert("b", []float32{0, 1, 0}, analysis.VectorMeta{Title: "b"}))
+	assert.Error(t, index.Upsert("c", []float32{1, 0}, analysis.VectorMeta{}), "dimension mismatch")
+	assert.Error(t, index.Upsert("c", []float32{0, 0, 0}, analysis.VectorMeta{}), "zero vector")
+
+	hits := index.Search([]float32{1, 0.1, 0}, 1)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "a", hits[0].ID)
+
+	// 替换后旧向量不再命中
+	require.NoError(t, index.Upsert("a", []float32{0, 0, 1}, analysis.VectorMeta{Title: "a2"}))
+	assert.Equal(t, 2, index.Len())
+	hits = index.Search([]float32{1, 0.1, 0}, 1)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "b", hits[0].ID)
+	meta, ok := index.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, "a2", meta.Title)
+
+	assert.True(t, index.Delete("b"))
+	assert.False(t, index.Delete("b"))
+	assert.ElementsMatch(t, []string{"a"}, index.IDs())
+	hits = index.Search([]float32{0, 1, 0}, 5)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "a", hits[0].ID)
+
+	// 大量替换触发重建后仍可检索
+	for i := 0; i < 200; i++ {
+		require.NoError(t, index.Upsert("a", []float32{0, float32(i % 3), 1}, analysis.VectorMeta{}))
+	}
+	assert.Equal(t, 1, index.Len())
+	assert.Len(t, index.Search([]float32{0, 0, 1}, 3), 1)
+}
+
+func TestHNSW_SnapshotRoundTrip(t *testing.T) {
+	config := DefaultHNSWConfig()
+	config.SnapshotPath = filepath.Join(t.TempDir(), "index", "knowledge.gob")
+
+	empty, err := OpenHNSW(config)
+	require.NoError(t, err, "missing snapshot opens an empty index")
+	assert.Equal(t, 0, empty.Len())
+
+	rng := rand.New(rand.NewSource(2))
+	index := NewHNSW(config)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("doc:%d", i)
+		require.NoError(t, index.Upsert(id, randomVector(rng, 16), analysis.VectorMeta{Title: id, ContentHash: "h"}))
+	}
+	index.Delete("doc:7")
+	require.NoError(t, index.Persist())
+
+	restored, err := OpenHNSW(config)
+	require.NoError(t, err)
+	assert.Equal(t, 99, restored.Len())
+	_, ok := restored.Get("doc:7")
+	assert.False(t, ok)
+	meta, ok := restored.Get("doc:8")
+	require.True(t, ok)
+	assert.Equal(t, "h", meta.ContentHash)
+
+	query := randomVector(rng, 16)
+	assert.Equal(t, index.Search(query, 5), restored.Search(query, 5))
+}
@@ -2,12 +2,15 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"alert_agent/internal/domain/analysis"
+	"alert_agent/internal/model"
 	"alert_agent/internal/pkg/logger"
 
 	"github.com/google/uuid"
@@ -25,12 +28,15 @@ type AnalysisWorkerImpl struct {
 	lastActiveTime  atomic.Value // time.Time
 	metadata        map[string]interface{}
 
-	taskQueue       analysis.AnalysisTaskQueue
+	taskQueue       analysis.ReliableTaskQueue
 	taskRepo        analysis.AnalysisTaskRepository
 	resultRepo      analysis.AnalysisResultRepository
 	progressTracker analysis.AnalysisProgressTracker
 	analysisEngine  analysis.AnalysisEngine
 	metricsCollector analysis.AnalysisMetricsCollector
+	eventBus        analysis.AnalysisEventBus // 为 nil 时不发布模型流式输出
+	alerts          analysis.AlertLoader // 为 nil 时按任务元数据构造告警
+	renewInterval   time.Duration // 处理中任务的租约续约间隔
 
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -40,13 +46,19 @@ type AnalysisWorkerImpl struct {
 
 // NewAnalysisWorker 创建分析工作器
 func NewAnalysisWorker(
-	taskQueue analysis.AnalysisTaskQueue,
+	taskQueue analysis.ReliableTaskQueue,
 	taskRepo analysis.AnalysisTaskRepository,
 	resultRepo analysis.AnalysisResultRepository,
 	progressTracker analysis.AnalysisProgressTracker,
 	analysisEngine analysis.AnalysisEngine,
 	metricsCollector analysis.AnalysisMetricsCollector,
+	eventBus analysis.AnalysisEventBus,
+	alerts analysis.AlertLoader,
+	renewInterval time.Duration,
 ) analysis.AnalysisWorker {
+	if renewInterval <= 0 {
+		renewInterval = analysis.DefaultQueueConfig().VisibilityTimeout / 3
+	}
 	workerID := uuid.New().String()
 	worker := &AnalysisWorkerImpl{
 		id:              workerID,
@@ -58,6 +70,9 @@ func NewAnalysisWorker(
 		progressTracker: progressTracker,
 		analysisEngine:  analysisEngine,
 		metricsCollector: metricsCollector,
+		eventBus:        eventBus,
+		alerts:          alerts,
+		renewInterval:   renewInterval,
 		logger:          logger.L.Named(fmt.Sprintf("analysis-worker-%s", workerID[:8])),
 	}
 
@@ -165,15 +180,24 @@ func (w *AnalysisWorkerImpl) ProcessTask(ctx context.Context, task *analysis.Ana
 	// 更新进度
 	progress := &analysis.AnalysisProgress{
 		TaskID:    task.ID,
-		Stage:     "initializing",
+		Stage:     analysis.ProgressStageInitializing,
 		Progress:  0,
 		Message:   "开始分析任务",
 		UpdatedAt: time.Now(),
 	}
 	w.progressTracker.UpdateProgress(ctx, task.ID, progress)
 
+	// 读取告警，分析引擎需要告警内容生成提示
+	alert, err := w.loadAlert(ctx, task)
+	if err != nil {
+		w.handleTaskError(ctx, task, err)
+		atomic.AddInt64(&w.errorCount, 1)
+		return nil, err
+	}
+
 	// 创建分析请求
 	request := &analysis.AnalysisRequest{
+		Alert:    alert,
 		Type:     task.Type,
 		Priority: task.Priority,
 		Timeout:  task.Timeout,
@@ -225,7 +249,7 @@ func (w *AnalysisWorkerImpl) ProcessTask(ctx context.Context, task *analysis.Ana
 	// 更新最终进度
 	finalProgress := &analysis.AnalysisProgress{
 		TaskID:    task.ID,
-		Stage:     "completed",
+		Stage:     analysis.ProgressStageCompleted,
 		Progress:  100,
 		Message:   "分析完成",
 		UpdatedAt: time.Now(),
@@ -290,23 +314,112 @@ func (w *AnalysisWorkerImpl) workerLoop() {
 				continue
 			}
 
-			// 处理任务
+			// 处理任务，处理期间定期续约
+			stopRenew := w.renewLease(task.ID)
 			_, err = w.ProcessTask(w.ctx, task)
+			stopRenew()
 			if err != nil {
 				w.logger.Error("Failed to process task", 
 					zap.String("task_id", task.ID),
 					zap.Error(err))
 			}
+			w.settle(task, err)
+		}
+	}
+}
+
+// renewLease 定期续约任务租约，返回停止续约的函数
+func (w *AnalysisWorkerImpl) renewLease(taskID string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := w.taskQueue.ExtendLease(w.ctx, taskID); err != nil {
+					w.logger.Warn("Failed to extend task lease",
+						zap.String("task_id", taskID),
+						zap.Error(err))
+					if errors.Is(err, analysis.ErrLeaseLost) {
+						return
+					}
+				}
+			}
 		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
 	}
 }
 
+// settle 确认任务或交还队列，失败的任务未超过最大投递次数时重新投递
+func (w *AnalysisWorkerImpl) settle(task *analysis.AnalysisTask, processErr error) {
+	// 停止时仍需确认已处理的任务
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if processErr == nil {
+		if err := w.taskQueue.Ack(ctx, task.ID); err != nil {
+			w.logger.Error("Failed to ack task", zap.String("task_id", task.ID), zap.Error(err))
+		}
+		return
+	}
+
+	deadLettered, err := w.taskQueue.Nack(ctx, task.ID, processErr)
+	if err != nil {
+		if !errors.Is(err, analysis.ErrLeaseLost) {
+			w.logger.Error("Failed to nack task", zap.String("task_id", task.ID), zap.Error(err))
+		}
+		return
+	}
+	if deadLettered {
+		w.logger.Warn("Task moved to dead letter queue", zap.String("task_id", task.ID))
+		return
+	}
+
+	// 重新投递的任务恢复为待处理
+	task.Status = analysis.AnalysisStatusPending
+	task.RetryCount++
+	task.UpdatedAt = time.Now()
+	if err := w.taskRepo.Update(ctx, task); err != nil {
+		w.logger.Error("Failed to reset requeued task status",
+			zap.String("task_id", task.ID),
+			zap.Error(err))
+	}
+}
+
+// loadAlert 读取任务对应的告警，未配置告警来源时按任务元数据构造
+func (w *AnalysisWorkerImpl) loadAlert(ctx context.Context, task *analysis.AnalysisTask) (*model.Alert, error) {
+	alertID, err := strconv.ParseUint(task.AlertID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert id %q: %w", task.AlertID, err)
+	}
+	if w.alerts == nil {
+		alert := &model.Alert{ID: uint(alertID)}
+		alert.Name, _ = task.Metadata["alert_name"].(string)
+		alert.Level, _ = task.Metadata["alert_level"].(string)
+		alert.Source, _ = task.Metadata["alert_source"].(string)
+		return alert, nil
+	}
+	alert, err := w.alerts.GetByID(ctx, uint(alertID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert %s: %w", task.AlertID, err)
+	}
+	return alert, nil
+}
+
 // executeAnalysis 执行分析
 func (w *AnalysisWorkerImpl) executeAnalysis(ctx context.Context, task *analysis.AnalysisTask, request *analysis.AnalysisRequest) (*analysis.AnalysisResult, error) {
 	// 更新进度
 	progress := &analysis.AnalysisProgress{
 		TaskID:    task.ID,
-		Stage:     "analyzing",
+		Stage:     analysis.ProgressStageAnalyzing,
 		Progress:  25,
 		Message:   "正在执行分析",
 		UpdatedAt: time.Now(),
@@ -324,9 +437,17 @@ func (w *AnalysisWorkerImpl) executeAnalysis(ctx context.Context, task *analysis
 	progress.UpdatedAt = time.Now()
 	w.progressTracker.UpdateProgress(ctx, task.ID, progress)
 
-	// 执行分析
+	// 执行分析，模型的流式输出实时发布给订阅方
 	startTime := time.Now()
+	var tokens *tokenPublisher
+	if w.eventBus != nil {
+		tokens = newTokenPublisher(ctx, w.eventBus, task.ID, w.logger)
+		ctx = analysis.WithTokenSink(ctx, tokens.Write)
+	}
 	result, err := w.analysisEngine.Analyze(ctx, request)
+	if tokens != nil {
+		tokens.Flush()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("analysis engine failed: %w", err)
 	}
@@ -410,7 +531,7 @@ func (w *AnalysisWorkerImpl) handleTaskError(ctx context.Context, task *analysis
 	// 更新错误进度
 	errorProgress := &analysis.AnalysisProgress{
 		TaskID:    task.ID,
-		Stage:     "failed",
+		Stage:     analysis.ProgressStageFailed,
 		Progress:  0,
 		Message:   fmt.Sprintf("分析失败: %s", err.Error()),
 		UpdatedAt: time.Now(),
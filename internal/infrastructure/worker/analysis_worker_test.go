@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	analysisInfra "alert_agent/internal/infrastructure/analysis"
+	"alert_agent/internal/infrastructure/queue"
+	"alert_agent/internal/model"
+	"alert_agent/internal/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryTaskRepo 记录任务状态的内存任务仓储
+type memoryTaskRepo struct {
+	analysis.AnalysisTaskRepository
+	mu    sync.Mutex
+	tasks map[string]analysis.AnalysisTask
+}
+
+func (r *memoryTaskRepo) Update(ctx context.Context, task *analysis.AnalysisTask) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[task.ID] = *task
+	return nil
+}
+
+func (r *memoryTaskRepo) status(taskID string) analysis.AnalysisStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.tasks[taskID].Status
+}
+
+// memoryResultRepo 保存结果的内存结果仓储
+type memoryResultRepo struct {
+	analysis.AnalysisResultRepository
+	mu      sync.Mutex
+	results []*analysis.AnalysisResult
+}
+
+func (r *memoryResultRepo) Create(ctx context.Context, result *analysis.AnalysisResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = append(r.results, result)
+	return nil
+}
+
+func (r *memoryResultRepo) all() []*analysis.AnalysisResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*analysis.AnalysisResult(nil), r.results...)
+}
+
+type nopProgressTracker struct {
+	analysis.AnalysisProgressTracker
+}
+
+func (nopProgressTracker) UpdateProgress(ctx context.Context, taskID string, progress *analysis.AnalysisProgress) error {
+	return nil
+}
+
+type nopMetricsCollector struct {
+	analysis.AnalysisMetricsCollector
+}
+
+func (nopMetricsCollector) RecordTaskCompleted(ctx context.Context, taskID string, analysisType analysis.AnalysisType, duration time.Duration) {
+}
+
+func (nopMetricsCollector) RecordTaskFailed(ctx context.Context, taskID string, analysisType analysis.AnalysisType, err error) {
+}
+
+type alertLoaderFunc func(ctx context.Context, id uint) (*model.Alert, error)
+
+func (f alertLoaderFunc) GetByID(ctx context.Context, id uint) (*model.Alert, error) {
+	return f(ctx, id)
+}
+
+func TestAnalysisWorker_ProcessesQueuedTaskThroughEngine(t *testing.T) {
+	logger.L = zap.NewNop()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	taskQueue := queue.NewAnalysisTaskQueue(client, analysis.QueueConfig{
+		VisibilityTimeout: time.Minute,
+		MaxAttempts:       1,
+	})
+	engine := analysisInfra.NewAnalysisEngine(
+		analysisInfra.NewRuleBasedAIService(),
+		analysisInfra.NewStaticTemplateRepository(nil),
+		nil, nil, nil, nil,
+	)
+	alerts := alertLoaderFunc(func(ctx context.Context, id uint) (*model.Alert, error) {
+		if id != 42 {
+			return nil, fmt.Errorf("alert %d not found", id)
+		}
+		return &model.Alert{
+			ID:      42,
+			Name:    "DiskFull",
+			Title:   "磁盘使用率超过95%",
+			Level:   "critical",
+			Content: "/data 分区剩余空间不足",
+			Labels:  `{"instance":"db-1"}`,
+		}, nil
+	})
+	tasks := &memoryTaskRepo{tasks: make(map[string]analysis.AnalysisTask)}
+	results := &memoryResultRepo{}
+
+	worker := NewAnalysisWorker(taskQueue, tasks, results, nopProgressTracker{}, engine,
+		nopMetricsCollector{}, nil, alerts, time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, taskQueue.Push(ctx, &analysis.AnalysisTask{
+		ID:       "task-1",
+		AlertID:  "42",
+		Type:     analysis.AnalysisTypeRootCause,
+		Status:   analysis.AnalysisStatusPending,
+		Timeout:  10 * time.Second,
+		Metadata: map[string]interface{}{},
+	}))
+
+	// 提交的任务经队列由工作器交给分析引擎处理并确认
+	require.NoError(t, worker.Start(ctx))
+	defer func() { _ = worker.Stop(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		status, err := taskQueue.GetStatus(ctx)
+		return err == nil && status.ProcessingCount == 0 && len(results.all()) > 0
+	}, 5*time.Second, 20*time.Millisecond)
+
+	stored := results.all()
+	require.Len(t, stored, 1)
+	assert.Equal(t, analysis.AnalysisStatusCompleted, stored[0].Status)
+	assert.Equal(t, "task-1", stored[0].TaskID)
+	assert.Equal(t, "42", stored[0].AlertID)
+	assert.NotEmpty(t, stored[0].Summary)
+	assert.Equal(t, analysis.AnalysisStatusCompleted, tasks.status("task-1"))
+
+	deadLetters, err := taskQueue.ListDeadLetters(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+
+	"go.uber.org/zap"
+)
+
+// 模型流式输出按批发布，避免每个 token 一次 Redis 往返
+const (
+	tokenFlushSize     = 64                     // 累积字节数达到后发布
+	tokenFlushInterval = 100 * time.Millisecond // 距上次发布超过该间隔后发布
+)
+
+// tokenPublisher 把模型流式输出合并后发布为 token 事件
+type tokenPublisher struct {
+	ctx       context.Context
+	eventBus  analysis.AnalysisEventBus
+	taskID    string
+	logger    *zap.Logger
+	buffer    strings.Builder
+	lastFlush time.Time
+	failed    bool // 发布失败后不再发布，分析照常进行
+}
+
+func newTokenPublisher(ctx context.Context, eventBus analysis.AnalysisEventBus, taskID string, logger *zap.Logger) *tokenPublisher {
+	return &tokenPublisher{
+		ctx:       ctx,
+		eventBus:  eventBus,
+		taskID:    taskID,
+		logger:    logger,
+		lastFlush: time.Now(),
+	}
+}
+
+// Write 接收模型输出增量
+func (p *tokenPublisher) Write(delta string) {
+	if p.failed {
+		return
+	}
+	p.buffer.WriteString(delta)
+	if p.buffer.Len() >= tokenFlushSize || time.Since(p.lastFlush) >= tokenFlushInterval {
+		p.Flush()
+	}
+}
+
+// Flush 发布已累积的输出
+func (p *tokenPublisher) Flush() {
+	if p.failed || p.buffer.Len() == 0 {
+		return
+	}
+	event := &analysis.StreamEvent{TaskID: p.taskID, Type: analysis.StreamEventToken, Delta: p.buffer.String()}
+	p.buffer.Reset()
+	p.lastFlush = time.Now()
+	if err := p.eventBus.Publish(p.ctx, event); err != nil {
+		p.failed = true
+		p.logger.Warn("Failed to publish token event, streaming disabled for task",
+			zap.String("task_id", p.taskID),
+			zap.Error(err))
+	}
+}
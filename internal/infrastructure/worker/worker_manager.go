@@ -32,12 +32,15 @@ type WorkerFactory interface {
 
 // DefaultWorkerFactory 默认工作器工厂
 type DefaultWorkerFactory struct {
-	taskQueue       analysis.AnalysisTaskQueue
+	taskQueue       analysis.ReliableTaskQueue
 	taskRepo        analysis.AnalysisTaskRepository
 	resultRepo      analysis.AnalysisResultRepository
 	progressTracker analysis.AnalysisProgressTracker
 	analysisEngine  analysis.AnalysisEngine
 	metricsCollector analysis.AnalysisMetricsCollector
+	eventBus        analysis.AnalysisEventBus
+	alerts          analysis.AlertLoader
+	renewInterval   time.Duration
 }
 
 // CreateWorker 创建工作器
@@ -49,17 +52,23 @@ func (f *DefaultWorkerFactory) CreateWorker() analysis.AnalysisWorker {
 		f.progressTracker,
 		f.analysisEngine,
 		f.metricsCollector,
+		f.eventBus,
+		f.alerts,
+		f.renewInterval,
 	)
 }
 
-// NewDefaultWorkerFactory 创建默认工作器工厂
+// NewDefaultWorkerFactory 创建默认工作器工厂，eventBus 用于发布模型流式输出，alerts 用于读取待分析的告警，renewInterval 为处理中任务的续约间隔
 func NewDefaultWorkerFactory(
-	taskQueue analysis.AnalysisTaskQueue,
+	taskQueue analysis.ReliableTaskQueue,
 	taskRepo analysis.AnalysisTaskRepository,
 	resultRepo analysis.AnalysisResultRepository,
 	progressTracker analysis.AnalysisProgressTracker,
 	analysisEngine analysis.AnalysisEngine,
 	metricsCollector analysis.AnalysisMetricsCollector,
+	eventBus analysis.AnalysisEventBus,
+	alerts analysis.AlertLoader,
+	renewInterval time.Duration,
 ) WorkerFactory {
 	return &DefaultWorkerFactory{
 		taskQueue:       taskQueue,
@@ -68,6 +77,9 @@ func NewDefaultWorkerFactory(
 		progressTracker: progressTracker,
 		analysisEngine:  analysisEngine,
 		metricsCollector: metricsCollector,
+		eventBus:        eventBus,
+		alerts:          alerts,
+		renewInterval:   renewInterval,
 	}
 }
 
@@ -243,20 +255,25 @@ func (m *AnalysisWorkerManagerImpl) ScaleWorkers(ctx context.Context, targetCoun
 			}
 		}
 
-		// 如果不健康的工作器不够，随机选择健康的
-		for workerID := range m.workers {
-			if len(workersToRemove) >= removeCount {
-				break
-			}
-			found := false
-			for _, id := range workersToRemove {
-				if id == workerID {
-					found = true
+		// 如果不健康的工作器不够，优先选择空闲的，避免中断正在分析的任务
+		for _, idle := range []bool{true, false} {
+			for workerID, worker := range m.workers {
+				if len(workersToRemove) >= removeCount {
 					break
 				}
-			}
-			if !found {
-				workersToRemove = append(workersToRemove, workerID)
+				if idle && worker.GetStatus().CurrentTask != "" {
+					continue
+				}
+				found := false
+				for _, id := range workersToRemove {
+					if id == workerID {
+						found = true
+						break
+					}
+				}
+				if !found {
+					workersToRemove = append(workersToRemove, workerID)
+				}
 			}
 		}
 
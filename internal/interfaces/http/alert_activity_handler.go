@@ -0,0 +1,181 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AlertActivityHandler 告警详情与动态HTTP处理器
+type AlertActivityHandler struct {
+	service alert.ActivityService
+	logger  *zap.Logger
+}
+
+// NewAlertActivityHandler 创建告警动态处理器
+func NewAlertActivityHandler(service alert.ActivityService, logger *zap.Logger) *AlertActivityHandler {
+	return &AlertActivityHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetAlert 获取告警详情
+// @Summary 获取告警详情
+// @Description 返回告警与时间线，时间线包含触发、评论、指派、状态变更与通知事件
+// @Tags alerts
+// @Produce json
+// @Param id path int true "告警ID"
+// @Success 200 {object} types.APIResponse{data=alert.AlertDetail}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/alerts/{id} [get]
+func (h *AlertActivityHandler) GetAlert(c *gin.Context) {
+	id, ok := h.alertID(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetDetail(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alert retrieved successfully", detail))
+}
+
+// GetTimeline 获取告警时间线
+// @Summary 获取告警时间线
+// @Description 按时间正序返回告警动态，评论的回复嵌套在 replies 中
+// @Tags alerts
+// @Produce json
+// @Param id path int true "告警ID"
+// @Success 200 {object} types.APIResponse{data=[]alert.Activity}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/alerts/{id}/timeline [get]
+func (h *AlertActivityHandler) GetTimeline(c *gin.Context) {
+	id, ok := h.alertID(c)
+	if !ok {
+		return
+	}
+
+	timeline, err := h.service.Timeline(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Timeline retrieved successfully", timeline))
+}
+
+// AddComment 添加评论
+// @Summary 添加评论
+// @Description 评论中的 @用户名 会记录为提及；指定 parent_id 回复已有评论
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Param comment body alert.CommentRequest true "评论"
+// @Success 201 {object} types.APIResponse{data=alert.Activity}
+// @Failure 400 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/alerts/{id}/comments [post]
+func (h *AlertActivityHandler) AddComment(c *gin.Context) {
+	id, ok := h.alertID(c)
+	if !ok {
+		return
+	}
+	var req alert.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	activity, err := h.service.AddComment(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Comment added successfully", activity))
+}
+
+// Assign 指派告警
+// @Summary 指派告警
+// @Description assignee 为空时取消指派，历史处理人保留在时间线中
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Param assignment body alert.AssignRequest true "指派信息"
+// @Success 200 {object} types.APIResponse{data=alert.Activity}
+// @Failure 404 {object} types.APIResponse
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/alerts/{id}/assign [post]
+func (h *AlertActivityHandler) Assign(c *gin.Context) {
+	id, ok := h.alertID(c)
+	if !ok {
+		return
+	}
+	var req alert.AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	activity, err := h.service.Assign(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alert assigned successfully", activity))
+}
+
+// ChangeStatus 变更告警状态
+// @Summary 变更告警状态
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "告警ID"
+// @Param status body alert.StatusRequest true "状态 new/acknowledged/resolved"
+// @Success 200 {object} types.APIResponse{data=alert.Activity}
+// @Failure 400 {object} types.APIResponse
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/alerts/{id}/status [post]
+func (h *AlertActivityHandler) ChangeStatus(c *gin.Context) {
+	id, ok := h.alertID(c)
+	if !ok {
+		return
+	}
+	var req alert.StatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	activity, err := h.service.ChangeStatus(c.Request.Context(), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alert status changed successfully", activity))
+}
+
+func (h *AlertActivityHandler) alertID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondBadRequest(c, "INVALID_ID", "Invalid alert ID")
+		return 0, false
+	}
+	return uint(id), true
+}
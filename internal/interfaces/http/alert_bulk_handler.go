@@ -0,0 +1,68 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AlertBulkHandler 告警批量操作HTTP处理器
+type AlertBulkHandler struct {
+	service alert.BulkService
+	logger  *zap.Logger
+}
+
+// NewAlertBulkHandler 创建告警批量操作处理器
+func NewAlertBulkHandler(service alert.BulkService, logger *zap.Logger) *AlertBulkHandler {
+	return &AlertBulkHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Execute 批量操作告警
+// @Summary 批量操作告警
+// @Description 按ID列表或查询语句选择告警，批量确认、恢复、指派、添加备注或重新分析；dry_run 只返回匹配数量与预期结果；匹配数量超过上限时拒绝执行
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param request body alert.BulkRequest true "批量操作"
+// @Success 200 {object} types.APIResponse{data=alert.BulkResult}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/alerts/bulk [post]
+func (h *AlertBulkHandler) Execute(c *gin.Context) {
+	var req alert.BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	actor := alert.BulkActor{
+		Username:  c.GetString("username"),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		actor.UserID = fmt.Sprint(userID)
+	}
+	if actor.Username == "" {
+		actor.Username = anonymousOwner
+	}
+
+	result, err := h.service.Execute(c.Request.Context(), &req, actor)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	message := "Bulk operation completed"
+	if req.DryRun {
+		message = "Bulk operation previewed"
+	}
+	c.JSON(http.StatusOK, types.NewSuccessResponse(message, result))
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"alert_agent/internal/domain/alert"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// anonymousOwner 未登录时保存搜索使用的用户
+const anonymousOwner = "anonymous"
+
+// AlertSearchHandler 告警搜索HTTP处理器
+type AlertSearchHandler struct {
+	service alert.SearchService
+	logger  *zap.Logger
+}
+
+// NewAlertSearchHandler 创建告警搜索处理器
+func NewAlertSearchHandler(service alert.SearchService, logger *zap.Logger) *AlertSearchHandler {
+	return &AlertSearchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Search 搜索告警
+// @Summary 搜索告警
+// @Description 使用查询语言搜索告警，如 severity=critical AND labels.namespace=~"prod-.*" AND NOT title:"disk"；sort 为字段名，降序加 - 前缀；翻页时传入上一页返回的 next_cursor
+// @Tags alerts
+// @Produce json
+// @Param q query string false "查询语句"
+// @Param sort query string false "排序字段" default(-created_at)
+// @Param cursor query string false "分页游标"
+// @Param limit query int false "每页数量" default(50)
+// @Success 200 {object} types.APIResponse{data=alert.SearchResult}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/alerts/search [get]
+func (h *AlertSearchHandler) Search(c *gin.Context) {
+	var req alert.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, err := h.service.Search(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alerts retrieved successfully", result))
+}
+
+// ListSavedSearches 获取保存的搜索
+// @Summary 获取保存的搜索
+// @Tags alerts
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=[]alert.SavedSearch}
+// @Router /api/v1/alerts/searches [get]
+func (h *AlertSearchHandler) ListSavedSearches(c *gin.Context) {
+	searches, err := h.service.ListSavedSearches(c.Request.Context(), h.owner(c))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Saved searches retrieved successfully", searches))
+}
+
+// CreateSavedSearch 保存搜索
+// @Summary 保存搜索
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param search body alert.SavedSearchRequest true "搜索信息"
+// @Success 201 {object} types.APIResponse{data=alert.SavedSearch}
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/alerts/searches [post]
+func (h *AlertSearchHandler) CreateSavedSearch(c *gin.Context) {
+	var req alert.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	search, err := h.service.CreateSavedSearch(c.Request.Context(), h.owner(c), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Saved search created successfully", search))
+}
+
+// UpdateSavedSearch 更新保存的搜索
+// @Summary 更新保存的搜索
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "搜索ID"
+// @Param search body alert.SavedSearchRequest true "搜索信息"
+// @Success 200 {object} types.APIResponse{data=alert.SavedSearch}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/alerts/searches/{id} [put]
+func (h *AlertSearchHandler) UpdateSavedSearch(c *gin.Context) {
+	id, ok := h.searchID(c)
+	if !ok {
+		return
+	}
+	var req alert.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	search, err := h.service.UpdateSavedSearch(c.Request.Context(), h.owner(c), id, &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Saved search updated successfully", search))
+}
+
+// DeleteSavedSearch 删除保存的搜索
+// @Summary 删除保存的搜索
+// @Tags alerts
+// @Produce json
+// @Param id path int true "搜索ID"
+// @Success 200 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/alerts/searches/{id} [delete]
+func (h *AlertSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	id, ok := h.searchID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSavedSearch(c.Request.Context(), h.owner(c), id); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Saved search deleted successfully", nil))
+}
+
+// RunSavedSearch 执行保存的搜索
+// @Summary 执行保存的搜索
+// @Tags alerts
+// @Produce json
+// @Param id path int true "搜索ID"
+// @Param cursor query string false "分页游标"
+// @Param limit query int false "每页数量" default(50)
+// @Success 200 {object} types.APIResponse{data=alert.SearchResult}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/alerts/searches/{id}/run [get]
+func (h *AlertSearchHandler) RunSavedSearch(c *gin.Context) {
+	id, ok := h.searchID(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	result, err := h.service.RunSavedSearch(c.Request.Context(), h.owner(c), id, c.Query("cursor"), limit)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alerts retrieved successfully", result))
+}
+
+// owner 当前用户，未登录时使用匿名用户
+func (h *AlertSearchHandler) owner(c *gin.Context) string {
+	if username := c.GetString("username"); username != "" {
+		return username
+	}
+	return anonymousOwner
+}
+
+func (h *AlertSearchHandler) searchID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondBadRequest(c, "INVALID_ID", "Invalid saved search ID")
+		return 0, false
+	}
+	return uint(id), true
+}
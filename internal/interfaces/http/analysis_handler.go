@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -61,6 +62,8 @@ type AnalysisResultResponse struct {
 	Result          map[string]interface{} `json:"result"`
 	Summary         string                 `json:"summary"`
 	Recommendations []string               `json:"recommendations"`
+	Structured      *analysisDomain.StructuredOutput `json:"structured,omitempty"`
+	Citations       []*analysisDomain.Citation       `json:"citations,omitempty"`
 	ErrorMessage    string                 `json:"error_message"`
 	CreatedAt       string                 `json:"created_at"`
 	UpdatedAt       string                 `json:"updated_at"`
@@ -216,6 +219,60 @@ func (h *AnalysisHandler) GetAnalysisProgress(c *gin.Context) {
 	c.JSON(http.StatusOK, types.NewSuccessResponse("Analysis progress retrieved", response))
 }
 
+// streamHeartbeatInterval 流式订阅的心跳间隔，避免代理因空闲断开连接
+const streamHeartbeatInterval = 15 * time.Second
+
+// StreamAnalysis 流式订阅分析进度
+// @Summary 流式订阅分析进度
+// @Description 通过 Server-Sent Events 推送任务进度（progress 事件）与模型输出增量（token 事件），任务结束后连接关闭
+// @Tags analysis
+// @Produce text/event-stream
+// @Param task_id path string true "任务ID"
+// @Success 200 {object} analysisDomain.StreamEvent
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/analysis/stream/{task_id} [get]
+func (h *AnalysisHandler) StreamAnalysis(c *gin.Context) {
+	taskID := c.Param("task_id")
+	events, err := h.analysisService.StreamAnalysis(c.Request.Context(), taskID)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	// 流式响应的时长不受服务器写超时限制，由任务结束或客户端断开决定
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to clear write deadline for analysis stream",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
 // CancelAnalysis 取消分析任务
 // @Summary 取消分析任务
 // @Description 根据任务ID取消分析任务
@@ -405,24 +462,73 @@ func (h *AnalysisHandler) GetQueueStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, types.NewSuccessResponse("Queue status retrieved", status))
 }
 
+// ListDeadLetters 列出死信任务
+// @Summary 列出死信任务
+// @Description 列出超过最大投递次数的分析任务，按进入死信的时间倒序
+// @Tags analysis
+// @Accept json
+// @Produce json
+// @Param limit query int false "限制数量" default(50)
+// @Success 200 {object} APIResponse{data=[]analysisDomain.DeadLetterTask}
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/analysis/queue/dead-letters [get]
+func (h *AnalysisHandler) ListDeadLetters(c *gin.Context) {
+	limit := int64(50)
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.ParseInt(limitStr, 10, 64); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	deadLetters, err := h.analysisService.ListDeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list dead letters", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, types.NewErrorResponse("Failed to list dead letters: "+err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Dead letters retrieved", deadLetters))
+}
+
+// RequeueDeadLetter 重新投递死信任务
+// @Summary 重新投递死信任务
+// @Description 将死信任务重新入队并重置投递次数
+// @Tags analysis
+// @Accept json
+// @Produce json
+// @Param task_id path string true "任务ID"
+// @Success 200 {object} APIResponse
+// @Failure 404 {object} APIResponse
+// @Failure 500 {object} APIResponse
+// @Router /api/v1/analysis/queue/dead-letters/{task_id}/requeue [post]
+func (h *AnalysisHandler) RequeueDeadLetter(c *gin.Context) {
+	taskID := c.Param("task_id")
+	if err := h.analysisService.RequeueDeadLetter(c.Request.Context(), taskID); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Dead letter requeued", nil))
+}
+
 // GetWorkerStatuses 获取工作器状态
 // @Summary 获取工作器状态
-// @Description 获取所有分析工作器的状态信息
+// @Description 获取本进程的分析工作器，以及各 worker 进程上报的扩缩容状态与最近的扩缩容决策
 // @Tags analysis
 // @Accept json
 // @Produce json
-// @Success 200 {object} APIResponse{data=[]WorkerStatus}
+// @Success 200 {object} APIResponse{data=analysis.WorkerPoolStatus}
 // @Failure 500 {object} APIResponse
 // @Router /api/v1/analysis/workers/status [get]
 func (h *AnalysisHandler) GetWorkerStatuses(c *gin.Context) {
-	statuses, err := h.analysisService.GetWorkerStatuses(c.Request.Context())
+	status, err := h.analysisService.GetWorkerPoolStatus(c.Request.Context())
 	if err != nil {
 		h.logger.Error("Failed to get worker statuses", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, types.NewErrorResponse("Failed to get worker statuses: "+err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, types.NewSuccessResponse("Worker statuses retrieved", statuses))
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Worker statuses retrieved", status))
 }
 
 // HealthCheck 健康检查
@@ -458,6 +564,8 @@ func (h *AnalysisHandler) convertAnalysisResult(result *analysisDomain.AnalysisR
 		Result:          result.Result,
 		Summary:         result.Summary,
 		Recommendations: result.Recommendations,
+		Structured:      result.Structured,
+		Citations:       result.Citations,
 		ErrorMessage:    result.ErrorMessage,
 		CreatedAt:       result.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:       result.UpdatedAt.Format(time.RFC3339),
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	analysisDomain "alert_agent/internal/domain/analysis"
+	"alert_agent/internal/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// slowStreamService 按固定间隔推送模型输出，最后推送完成进度
+type slowStreamService struct {
+	analysisDomain.AnalysisService
+	tokens   int
+	interval time.Duration
+}
+
+func (s *slowStreamService) StreamAnalysis(ctx context.Context, taskID string) (<-chan *analysisDomain.StreamEvent, error) {
+	events := make(chan *analysisDomain.StreamEvent)
+	go func() {
+		defer close(events)
+		for i := 0; i < s.tokens; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(s.interval):
+			}
+			events <- &analysisDomain.StreamEvent{TaskID: taskID, Type: analysisDomain.StreamEventToken, Delta: "x"}
+		}
+		events <- &analysisDomain.StreamEvent{
+			TaskID: taskID,
+			Type:   analysisDomain.StreamEventProgress,
+			Progress: &analysisDomain.AnalysisProgress{
+				TaskID:   taskID,
+				Stage:    analysisDomain.ProgressStageCompleted,
+				Progress: 100,
+			},
+		}
+	}()
+	return events, nil
+}
+
+func TestAnalysisHandler_StreamOutlivesWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger.L = zap.NewNop()
+
+	handler := NewAnalysisHandler(&slowStreamService{tokens: 6, interval: 50 * time.Millisecond})
+	router := gin.New()
+	router.GET("/api/v1/analysis/stream/:task_id", handler.StreamAnalysis)
+
+	// 流式响应总时长超过服务器写超时
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/analysis/stream/task-1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 6, strings.Count(string(body), "event:token"))
+	assert.Contains(t, string(body), `"stage":"completed"`)
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/analytics"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AnalyticsHandler 告警分析HTTP处理器
+type AnalyticsHandler struct {
+	service analytics.Service
+	logger  *zap.Logger
+}
+
+// NewAnalyticsHandler 创建告警分析处理器
+func NewAnalyticsHandler(service analytics.Service, logger *zap.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetResponseMetrics 获取 MTTA/MTTR
+// @Summary 获取 MTTA/MTTR
+// @Description 按规则、服务或团队统计平均确认时长与平均恢复时长，时间范围默认为最近 7 天
+// @Tags analytics
+// @Produce json
+// @Param dimension query string false "统计维度 rule/service/team" default(rule)
+// @Param from query string false "开始时间 RFC3339"
+// @Param to query string false "结束时间 RFC3339"
+// @Param rule_id query int false "规则ID"
+// @Param service query string false "服务"
+// @Param team query string false "团队"
+// @Param instance query string false "实例"
+// @Param severity query string false "级别"
+// @Param limit query int false "返回数量，0 为不限制"
+// @Success 200 {object} types.APIResponse{data=[]analytics.DimensionStats}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analytics/response [get]
+func (h *AnalyticsHandler) GetResponseMetrics(c *gin.Context) {
+	filter, ok := h.filter(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	dimension := analytics.Dimension(c.DefaultQuery("dimension", string(analytics.DimensionRule)))
+
+	stats, err := h.service.ResponseMetrics(c.Request.Context(), dimension, filter, limit)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Response metrics retrieved successfully", stats))
+}
+
+// GetTopNoisy 获取告警最多的规则或实例
+// @Summary 获取告警最多的规则或实例
+// @Tags analytics
+// @Produce json
+// @Param dimension query string false "统计维度 rule/instance" default(rule)
+// @Param from query string false "开始时间 RFC3339"
+// @Param to query string false "结束时间 RFC3339"
+// @Param limit query int false "返回数量" default(10)
+// @Success 200 {object} types.APIResponse{data=[]analytics.DimensionStats}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analytics/top [get]
+func (h *AnalyticsHandler) GetTopNoisy(c *gin.Context) {
+	filter, ok := h.filter(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	dimension := analytics.Dimension(c.DefaultQuery("dimension", string(analytics.DimensionRule)))
+
+	stats, err := h.service.TopNoisy(c.Request.Context(), dimension, filter, limit)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Top noisy sources retrieved successfully", stats))
+}
+
+// GetShifts 获取每个值班班次的告警数
+// @Summary 获取每个值班班次的告警数
+// @Tags analytics
+// @Produce json
+// @Param from query string false "开始时间 RFC3339"
+// @Param to query string false "结束时间 RFC3339"
+// @Success 200 {object} types.APIResponse{data=[]analytics.ShiftStats}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analytics/shifts [get]
+func (h *AnalyticsHandler) GetShifts(c *gin.Context) {
+	filter, ok := h.filter(c)
+	if !ok {
+		return
+	}
+
+	shifts, err := h.service.AlertsPerShift(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Shift statistics retrieved successfully", shifts))
+}
+
+// GetHeatmap 获取告警热力图
+// @Summary 获取告警热力图
+// @Description 星期 × 小时的告警数，cells[weekday][hour]，weekday 0 为周日
+// @Tags analytics
+// @Produce json
+// @Param from query string false "开始时间 RFC3339"
+// @Param to query string false "结束时间 RFC3339"
+// @Success 200 {object} types.APIResponse{data=analytics.Heatmap}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analytics/heatmap [get]
+func (h *AnalyticsHandler) GetHeatmap(c *gin.Context) {
+	filter, ok := h.filter(c)
+	if !ok {
+		return
+	}
+
+	heatmap, err := h.service.Heatmap(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Heatmap retrieved successfully", heatmap))
+}
+
+// GetWeekOverWeek 获取周环比
+// @Summary 获取周环比
+// @Description 截至 to（默认当前时间）的最近一周与上一周对比
+// @Tags analytics
+// @Produce json
+// @Param to query string false "截止时间 RFC3339"
+// @Success 200 {object} types.APIResponse{data=analytics.PeriodComparison}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analytics/week-over-week [get]
+func (h *AnalyticsHandler) GetWeekOverWeek(c *gin.Context) {
+	filter, ok := h.filter(c)
+	if !ok {
+		return
+	}
+	end := filter.To
+	filter.From, filter.To = time.Time{}, time.Time{}
+
+	comparison, err := h.service.WeekOverWeek(c.Request.Context(), end, filter)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Week over week comparison retrieved successfully", comparison))
+}
+
+// RefreshRollups 立即刷新小时聚合
+// @Summary 立即刷新小时聚合
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} types.APIResponse
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/analytics/rollups/refresh [post]
+func (h *AnalyticsHandler) RefreshRollups(c *gin.Context) {
+	if err := h.service.RefreshRollups(c.Request.Context()); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Rollups refreshed successfully", nil))
+}
+
+// filter 解析统计过滤条件
+func (h *AnalyticsHandler) filter(c *gin.Context) (*analytics.Filter, bool) {
+	filter := &analytics.Filter{
+		Service:  c.Query("service"),
+		Team:     c.Query("team"),
+		Instance: c.Query("instance"),
+		Severity: c.Query("severity"),
+	}
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondBadRequest(c, "INVALID_TIME", "Invalid "+param+", expected RFC3339")
+				return nil, false
+			}
+			*target = parsed
+		}
+	}
+	if value := c.Query("rule_id"); value != "" {
+		ruleID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			respondBadRequest(c, "INVALID_RULE_ID", "Invalid rule_id")
+			return nil, false
+		}
+		filter.RuleID = uint(ruleID)
+	}
+	return filter, true
+}
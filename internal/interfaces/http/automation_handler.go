@@ -0,0 +1,442 @@
+package http
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"alert_agent/internal/domain/automation"
+	"alert_agent/internal/security/user"
+	"alert_agent/internal/shared/errors"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CredentialVerifier 校验聊天审批确认页提交的账号密码，由用户服务实现
+type CredentialVerifier interface {
+	Login(ctx context.Context, req *user.LoginRequest, clientIP, userAgent string) (*user.LoginResponse, error)
+}
+
+// AutomationHandler 运行手册自动化HTTP处理器
+type AutomationHandler struct {
+	service     automation.Service
+	credentials CredentialVerifier
+	logger      *zap.Logger
+}
+
+// NewAutomationHandler 创建运行手册处理器，credentials 为 nil 时聊天审批只接受已认证的请求
+func NewAutomationHandler(service automation.Service, credentials CredentialVerifier, logger *zap.Logger) *AutomationHandler {
+	return &AutomationHandler{
+		service:     service,
+		credentials: credentials,
+		logger:      logger,
+	}
+}
+
+// executionDetail 执行记录及操作日志
+type executionDetail struct {
+	*automation.Execution
+	Actions []*automation.AutomationAction `json:"actions"`
+}
+
+// CreateRunbook 创建运行手册
+// @Summary 创建运行手册
+// @Description 创建绑定告警匹配器的运行手册，步骤支持 HTTP 调用、沙箱脚本、Kubernetes 操作和 n8n 工作流
+// @Tags automation
+// @Accept json
+// @Produce json
+// @Param runbook body automation.RunbookRequest true "运行手册"
+// @Success 201 {object} types.APIResponse{data=automation.Runbook}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/automation/runbooks [post]
+func (h *AutomationHandler) CreateRunbook(c *gin.Context) {
+	var req automation.RunbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = c.GetString("username")
+	}
+
+	runbook, err := h.service.CreateRunbook(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Runbook created successfully", runbook))
+}
+
+// ListRunbooks 获取运行手册列表
+// @Summary 获取运行手册列表
+// @Tags automation
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=[]automation.Runbook}
+// @Router /api/v1/automation/runbooks [get]
+func (h *AutomationHandler) ListRunbooks(c *gin.Context) {
+	runbooks, err := h.service.ListRunbooks(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Runbooks retrieved successfully", runbooks))
+}
+
+// GetRunbook 获取运行手册
+// @Summary 获取运行手册
+// @Tags automation
+// @Produce json
+// @Param id path string true "运行手册ID"
+// @Success 200 {object} types.APIResponse{data=automation.Runbook}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/automation/runbooks/{id} [get]
+func (h *AutomationHandler) GetRunbook(c *gin.Context) {
+	runbook, err := h.service.GetRunbook(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Runbook retrieved successfully", runbook))
+}
+
+// UpdateRunbook 更新运行手册
+// @Summary 更新运行手册
+// @Tags automation
+// @Accept json
+// @Produce json
+// @Param id path string true "运行手册ID"
+// @Param runbook body automation.RunbookRequest true "运行手册"
+// @Success 200 {object} types.APIResponse{data=automation.Runbook}
+// @Failure 400 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/automation/runbooks/{id} [put]
+func (h *AutomationHandler) UpdateRunbook(c *gin.Context) {
+	var req automation.RunbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	runbook, err := h.service.UpdateRunbook(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Runbook updated successfully", runbook))
+}
+
+// DeleteRunbook 删除运行手册
+// @Summary 删除运行手册
+// @Tags automation
+// @Produce json
+// @Param id path string true "运行手册ID"
+// @Success 200 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/automation/runbooks/{id} [delete]
+func (h *AutomationHandler) DeleteRunbook(c *gin.Context) {
+	if err := h.service.DeleteRunbook(c.Request.Context(), c.Param("id")); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Runbook deleted successfully", nil))
+}
+
+// TriggerRunbook 手工触发运行手册
+// @Summary 手工触发运行手册
+// @Description 针对告警触发运行手册，dry_run 为 true 时只记录将执行的操作
+// @Tags automation
+// @Accept json
+// @Produce json
+// @Param id path string true "运行手册ID"
+// @Param request body automation.TriggerRequest true "触发参数"
+// @Success 201 {object} types.APIResponse{data=automation.Execution}
+// @Failure 400 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/automation/runbooks/{id}/trigger [post]
+func (h *AutomationHandler) TriggerRunbook(c *gin.Context) {
+	var req automation.TriggerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.TriggeredBy == "" {
+		req.TriggeredBy = c.GetString("username")
+	}
+
+	execution, err := h.service.TriggerRunbook(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Runbook triggered successfully", execution))
+}
+
+// ListExecutions 获取执行记录
+// @Summary 获取运行手册执行记录
+// @Tags automation
+// @Produce json
+// @Param runbook_id query string false "运行手册ID"
+// @Param alert_id query int false "告警ID"
+// @Param status query string false "状态"
+// @Param limit query int false "每页数量" default(20)
+// @Param offset query int false "偏移量" default(0)
+// @Success 200 {object} types.APIResponse{data=types.PageResult}
+// @Router /api/v1/automation/executions [get]
+func (h *AutomationHandler) ListExecutions(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	alertID, _ := strconv.ParseUint(c.Query("alert_id"), 10, 32)
+	filter := &automation.ExecutionFilter{
+		RunbookID: c.Query("runbook_id"),
+		AlertID:   uint(alertID),
+		Status:    automation.ExecutionStatus(c.Query("status")),
+		Limit:     limit,
+		Offset:    offset,
+	}
+
+	executions, total, err := h.service.ListExecutions(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Executions retrieved successfully", types.PageResult{
+		Data:  executions,
+		Total: total,
+		Size:  limit,
+	}))
+}
+
+// GetExecution 获取执行记录及操作日志
+// @Summary 获取运行手册执行记录
+// @Tags automation
+// @Produce json
+// @Param id path string true "执行ID"
+// @Success 200 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/automation/executions/{id} [get]
+func (h *AutomationHandler) GetExecution(c *gin.Context) {
+	execution, actions, err := h.service.GetExecution(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Execution retrieved successfully", executionDetail{Execution: execution, Actions: actions}))
+}
+
+// ApproveExecution 批准执行
+// @Summary 批准运行手册执行
+// @Tags automation
+// @Accept json
+// @Produce json
+// @Param id path string true "执行ID"
+// @Param request body automation.ApprovalRequest false "审批说明"
+// @Success 200 {object} types.APIResponse{data=automation.Execution}
+// @Failure 401 {object} types.APIResponse
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/automation/executions/{id}/approve [post]
+func (h *AutomationHandler) ApproveExecution(c *gin.Context) {
+	h.decide(c, h.service.Approve, "Execution approved successfully")
+}
+
+// RejectExecution 拒绝执行
+// @Summary 拒绝运行手册执行
+// @Tags automation
+// @Accept json
+// @Produce json
+// @Param id path string true "执行ID"
+// @Param request body automation.ApprovalRequest false "拒绝说明"
+// @Success 200 {object} types.APIResponse{data=automation.Execution}
+// @Failure 401 {object} types.APIResponse
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/automation/executions/{id}/reject [post]
+func (h *AutomationHandler) RejectExecution(c *gin.Context) {
+	h.decide(c, h.service.Reject, "Execution rejected successfully")
+}
+
+// callbackPage 聊天审批确认页，提交后才记录审批结果
+var callbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head><meta charset="utf-8"><title>运行手册审批</title></head>
+<body>
+<h1>{{ .Title }}</h1>
+{{ with .Execution }}<p>运行手册：{{ .RunbookName }}，告警 #{{ .AlertID }}</p>
+<ol>{{ range .Steps }}<li>{{ .Name }}：{{ .Action.Describe }}</li>{{ end }}</ol>{{ end }}
+{{ if .Message }}<p>{{ .Message }}</p>{{ end }}
+{{ if .Action }}<form method="post" action="{{ .Action }}">
+<p><label>用户名 <input name="username" autocomplete="username" required></label></p>
+<p><label>密码 <input name="password" type="password" autocomplete="current-password" required></label></p>
+<p><button type="submit">{{ .Submit }}</button></p>
+</form>{{ end }}
+</body>
+</html>`))
+
+// callbackView 确认页内容
+type callbackView struct {
+	Title     string
+	Execution *automation.Execution
+	Message   string
+	Action    string
+	Submit    string
+}
+
+// ConfirmCallback 展示聊天消息中审批链接的确认页
+// @Summary 聊天审批确认页
+// @Description 聊天消息中的批准或拒绝链接打开确认页，只校验签名不改变执行状态，避免链接预取与消息预览触发审批
+// @Tags automation
+// @Produce html
+// @Param id path string true "执行ID"
+// @Param decision query string true "approve 或 reject"
+// @Param expires query int true "过期时间戳"
+// @Param signature query string true "签名"
+// @Success 200 {string} string "确认页"
+// @Failure 403 {string} string "链接无效或已过期"
+// @Router /api/v1/automation/executions/{id}/callback [get]
+func (h *AutomationHandler) ConfirmCallback(c *gin.Context) {
+	decision := c.Query("decision")
+	execution, err := h.service.VerifyCallback(c.Request.Context(), c.Param("id"),
+		decision, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		h.renderCallbackError(c, err)
+		return
+	}
+
+	view := callbackView{Title: "拒绝运行手册执行", Execution: execution, Action: c.Request.URL.RequestURI(), Submit: "确认拒绝"}
+	if decision == automation.DecisionApprove {
+		view.Title, view.Submit = "批准运行手册执行", "确认批准"
+	}
+	if execution.Status != automation.ExecutionPendingApproval {
+		view.Message = "执行当前状态为 " + string(execution.Status) + "，无需审批"
+		view.Action = ""
+	}
+	h.renderCallback(c, http.StatusOK, view)
+}
+
+// Callback 确认聊天消息中的审批链接，审批人为认证的用户
+// @Summary 聊天审批回调
+// @Description 在确认页提交审批，请求未携带令牌时使用表单中的账号密码认证，审批人记录为认证的用户
+// @Tags automation
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param id path string true "执行ID"
+// @Param decision query string true "approve 或 reject"
+// @Param expires query int true "过期时间戳"
+// @Param signature query string true "签名"
+// @Success 200 {string} string "审批结果"
+// @Failure 401 {string} string "认证失败"
+// @Failure 403 {string} string "链接无效或已过期"
+// @Router /api/v1/automation/executions/{id}/callback [post]
+func (h *AutomationHandler) Callback(c *gin.Context) {
+	approver := c.GetString("username")
+	if approver == "" {
+		approver = h.authenticate(c)
+	}
+	if approver == "" {
+		h.renderCallbackError(c, errors.NewUnauthorizedError("Invalid username or password"))
+		return
+	}
+
+	execution, err := h.service.HandleCallback(c.Request.Context(), c.Param("id"),
+		c.Query("decision"), c.Query("expires"), c.Query("signature"), approver)
+	if err != nil {
+		h.renderCallbackError(c, err)
+		return
+	}
+
+	h.renderCallback(c, http.StatusOK, callbackView{
+		Title:     "审批已记录",
+		Execution: execution,
+		Message:   "执行状态：" + string(execution.Status) + "，审批人：" + execution.ApprovedBy,
+	})
+}
+
+// authenticate 使用确认页提交的账号密码认证，失败时返回空字符串
+func (h *AutomationHandler) authenticate(c *gin.Context) string {
+	username, password := c.PostForm("username"), c.PostForm("password")
+	if h.credentials == nil || username == "" || password == "" {
+		return ""
+	}
+	resp, err := h.credentials.Login(c.Request.Context(), &user.LoginRequest{Username: username, Password: password},
+		c.ClientIP(), c.Request.UserAgent())
+	if err != nil || resp.User == nil {
+		h.logger.Warn("Chat approval authentication failed", zap.String("username", username), zap.Error(err))
+		return ""
+	}
+	return resp.User.Username
+}
+
+func (h *AutomationHandler) renderCallbackError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	if appErr, ok := err.(*errors.AppError); ok {
+		status = errors.GetHTTPStatusCode(appErr)
+		message = appErr.Message
+	} else {
+		h.logger.Error("request failed", zap.Error(err))
+	}
+	h.renderCallback(c, status, callbackView{Title: "无法完成审批", Message: message})
+}
+
+func (h *AutomationHandler) renderCallback(c *gin.Context, status int, view callbackView) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := callbackPage.Execute(c.Writer, view); err != nil {
+		h.logger.Error("Failed to render approval page", zap.Error(err))
+	}
+}
+
+// ListAlertActions 获取告警的自动化操作日志
+// @Summary 获取告警的自动化操作日志
+// @Tags automation
+// @Produce json
+// @Param id path int true "告警ID"
+// @Success 200 {object} types.APIResponse{data=[]automation.AutomationAction}
+// @Router /api/v1/alerts/{id}/automation [get]
+func (h *AutomationHandler) ListAlertActions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondBadRequest(c, "INVALID_ID", "Invalid alert ID")
+		return
+	}
+
+	actions, err := h.service.ListAlertActions(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Automation actions retrieved successfully", actions))
+}
+
+func (h *AutomationHandler) decide(c *gin.Context, decide func(ctx context.Context, id string, req *automation.ApprovalRequest) (*automation.Execution, error), message string) {
+	var req automation.ApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	req.Approver = c.GetString("username")
+	if req.Approver == "" {
+		respondError(c, h.logger, errors.NewUnauthorizedError("Authentication is required to decide executions"))
+		return
+	}
+
+	execution, err := decide(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse(message, execution))
+}
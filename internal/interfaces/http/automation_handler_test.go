@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"alert_agent/internal/domain/automation"
+	"alert_agent/internal/security/user"
+	"alert_agent/internal/shared/errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// callbackService 记录聊天审批回调的自动化服务
+type callbackService struct {
+	automation.Service
+	execution *automation.Execution
+	approvers []string
+}
+
+func (s *callbackService) VerifyCallback(ctx context.Context, id, decision, expires, signature string) (*automation.Execution, error) {
+	if signature != "valid" {
+		return nil, errors.NewForbiddenError("Invalid approval signature")
+	}
+	return s.execution, nil
+}
+
+func (s *callbackService) HandleCallback(ctx context.Context, id, decision, expires, signature, approver string) (*automation.Execution, error) {
+	if _, err := s.VerifyCallback(ctx, id, decision, expires, signature); err != nil {
+		return nil, err
+	}
+	s.approvers = append(s.approvers, approver)
+	s.execution.Status = automation.ExecutionQueued
+	s.execution.ApprovedBy = approver
+	return s.execution, nil
+}
+
+func (s *callbackService) Approve(ctx context.Context, id string, req *automation.ApprovalRequest) (*automation.Execution, error) {
+	s.approvers = append(s.approvers, req.Approver)
+	return s.execution, nil
+}
+
+type credentialsFunc func(username, password string) (*user.LoginResponse, error)
+
+func (f credentialsFunc) Login(ctx context.Context, req *user.LoginRequest, clientIP, userAgent string) (*user.LoginResponse, error) {
+	return f(req.Username, req.Password)
+}
+
+func newCallbackRouter(service *callbackService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	credentials := credentialsFunc(func(username, password string) (*user.LoginResponse, error) {
+		if username != "alice" || password != "s3cret" {
+			return nil, errors.NewUnauthorizedError("Invalid username or password")
+		}
+		return &user.LoginResponse{User: &user.User{Username: "alice"}}, nil
+	})
+	handler := NewAutomationHandler(service, credentials, zap.NewNop())
+
+	router := gin.New()
+	router.GET("/executions/:id/callback", handler.ConfirmCallback)
+	router.POST("/executions/:id/callback", handler.Callback)
+	router.POST("/executions/:id/approve", handler.ApproveExecution)
+	return router
+}
+
+func pendingExecution() *automation.Execution {
+	return &automation.Execution{
+		ID:          "exec-1",
+		RunbookName: "restart-api",
+		AlertID:     7,
+		Status:      automation.ExecutionPendingApproval,
+		Steps: []automation.Step{{
+			Name: "restart",
+			Action: automation.Action{
+				Type: automation.ActionKubernetes,
+				Kubernetes: &automation.KubernetesAction{
+					Operation: automation.KubernetesRestartDeployment,
+					Namespace: "prod",
+					Name:      "api",
+				},
+			},
+		}},
+	}
+}
+
+func TestAutomationHandler_CallbackLinkOnlyShowsConfirmation(t *testing.T) {
+	service := &callbackService{execution: pendingExecution()}
+	router := newCallbackRouter(service)
+
+	const link = "/executions/exec-1/callback?decision=approve&expires=1&signature=valid"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+
+	// 打开链接只展示确认页，不记录审批
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `method="post"`)
+	assert.Contains(t, w.Body.String(), "restart-api")
+	assert.Empty(t, service.approvers)
+	assert.Equal(t, automation.ExecutionPendingApproval, service.execution.Status)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+		"/executions/exec-1/callback?decision=approve&expires=1&signature=forged", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAutomationHandler_CallbackRecordsAuthenticatedApprover(t *testing.T) {
+	service := &callbackService{execution: pendingExecution()}
+	router := newCallbackRouter(service)
+
+	const link = "/executions/exec-1/callback?decision=approve&expires=1&signature=valid"
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, link, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(url.Values{"username": {"alice"}, "password": {"wrong"}})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, service.approvers)
+
+	w = post(url.Values{"username": {"alice"}, "password": {"s3cret"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"alice"}, service.approvers)
+	assert.Contains(t, w.Body.String(), "alice")
+}
+
+func TestAutomationHandler_ApproveIgnoresApproverInBody(t *testing.T) {
+	service := &callbackService{execution: pendingExecution()}
+	router := newCallbackRouter(service)
+
+	// 未认证的请求不能以请求体中的审批人审批
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/executions/exec-1/approve",
+		strings.NewReader(`{"approver":"admin"}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, service.approvers)
+
+	authenticated := gin.New()
+	handler := NewAutomationHandler(service, nil, zap.NewNop())
+	authenticated.POST("/executions/:id/approve", func(c *gin.Context) {
+		c.Set("username", "bob")
+	}, handler.ApproveExecution)
+
+	w = httptest.NewRecorder()
+	authenticated.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/executions/exec-1/approve",
+		strings.NewReader(`{"approver":"admin"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"bob"}, service.approvers)
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"alert_agent/internal/shared/errors"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// respondError 将应用错误转换为统一的API响应
+func respondError(c *gin.Context, logger *zap.Logger, err error) {
+	logger.Error("request failed", zap.Error(err))
+
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(errors.GetHTTPStatusCode(appErr), types.APIResponse{
+			Status:  "error",
+			Message: appErr.Message,
+			Error: &types.ErrorInfo{
+				Type:    string(appErr.Type),
+				Code:    appErr.Code,
+				Message: appErr.Message,
+				Details: appErr.Details,
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, types.APIResponse{
+		Status:  "error",
+		Message: "Internal server error",
+		Error: &types.ErrorInfo{
+			Type:    "internal",
+			Code:    "INTERNAL_ERROR",
+			Message: "An unexpected error occurred",
+		},
+	})
+}
+
+// respondBadRequest 返回请求参数错误
+func respondBadRequest(c *gin.Context, code, message string) {
+	c.JSON(http.StatusBadRequest, types.APIResponse{
+		Status:  "error",
+		Message: message,
+		Error: &types.ErrorInfo{
+			Type:    "validation",
+			Code:    code,
+			Message: message,
+		},
+	})
+}
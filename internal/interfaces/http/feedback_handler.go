@@ -0,0 +1,124 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FeedbackHandler 分析反馈HTTP处理器
+type FeedbackHandler struct {
+	service analysis.FeedbackService
+	logger  *zap.Logger
+}
+
+// NewFeedbackHandler 创建分析反馈处理器
+func NewFeedbackHandler(service analysis.FeedbackService, logger *zap.Logger) *FeedbackHandler {
+	return &FeedbackHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// SubmitFeedback 对分析结果提交评价
+// @Summary 提交分析反馈
+// @Description 响应人对已完成的分析结果点赞或点踩，可附带真实根因与分类
+// @Tags analysis
+// @Accept json
+// @Produce json
+// @Param id path string true "分析结果ID"
+// @Param request body analysis.FeedbackRequest true "反馈"
+// @Success 201 {object} types.APIResponse{data=analysis.AnalysisFeedback}
+// @Failure 400 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/analysis/results/{id}/feedback [post]
+func (h *FeedbackHandler) SubmitFeedback(c *gin.Context) {
+	var req analysis.FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	feedback, err := h.service.SubmitFeedback(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Feedback submitted successfully", feedback))
+}
+
+// ListFeedback 获取分析结果的反馈
+// @Summary 获取分析反馈
+// @Tags analysis
+// @Produce json
+// @Param id path string true "分析结果ID"
+// @Success 200 {object} types.APIResponse{data=[]analysis.AnalysisFeedback}
+// @Router /api/v1/analysis/results/{id}/feedback [get]
+func (h *FeedbackHandler) ListFeedback(c *gin.Context) {
+	feedback, err := h.service.ListFeedback(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Feedback retrieved successfully", feedback))
+}
+
+// GetStats 获取反馈统计
+// @Summary 获取分析反馈统计
+// @Description 按模型统计好评率、平均置信度与耗时
+// @Tags analysis
+// @Produce json
+// @Param from query string false "开始日期 2006-01-02 或 RFC3339"
+// @Param to query string false "结束日期 2006-01-02 或 RFC3339"
+// @Param model query string false "模型"
+// @Param type query string false "分析类型"
+// @Success 200 {object} types.APIResponse{data=analysis.FeedbackSummary}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analysis/feedback/stats [get]
+func (h *FeedbackHandler) GetStats(c *gin.Context) {
+	from, ok := parseFeedbackTime(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseFeedbackTime(c, "to")
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.GetStats(c.Request.Context(), &analysis.FeedbackFilter{
+		From:      from,
+		To:        to,
+		ModelUsed: c.Query("model"),
+		Type:      analysis.AnalysisType(c.Query("type")),
+	})
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Feedback stats retrieved successfully", summary))
+}
+
+// parseFeedbackTime 解析日期或时间参数，为空时返回零值
+func parseFeedbackTime(c *gin.Context, param string) (time.Time, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	t, err := time.ParseInLocation(analysis.UsageDateLayout, value, time.Local)
+	if err != nil {
+		respondBadRequest(c, "INVALID_TIME", "Invalid "+param+", expected 2006-01-02 or RFC3339")
+		return time.Time{}, false
+	}
+	return t, true
+}
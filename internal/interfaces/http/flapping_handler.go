@@ -0,0 +1,67 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/shared/errors"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FlappingHandler 告警抖动HTTP处理器
+type FlappingHandler struct {
+	detector gateway.FlapDetector
+	logger   *zap.Logger
+}
+
+// NewFlappingHandler 创建告警抖动处理器
+func NewFlappingHandler(detector gateway.FlapDetector, logger *zap.Logger) *FlappingHandler {
+	return &FlappingHandler{
+		detector: detector,
+		logger:   logger,
+	}
+}
+
+// ListFlapping 获取正在抖动的告警
+// @Summary 获取正在抖动的告警
+// @Tags gateway
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=[]gateway.FlapState}
+// @Router /api/v1/gateway/flapping [get]
+func (h *FlappingHandler) ListFlapping(c *gin.Context) {
+	states, err := h.detector.ListFlapping(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Flapping alerts retrieved successfully", states))
+}
+
+// GetState 获取告警指纹的抖动状态
+// @Summary 获取告警指纹的抖动状态
+// @Tags gateway
+// @Produce json
+// @Param fingerprint path string true "告警指纹"
+// @Success 200 {object} types.APIResponse{data=gateway.FlapState}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/gateway/flapping/{fingerprint} [get]
+func (h *FlappingHandler) GetState(c *gin.Context) {
+	fingerprint := c.Param("fingerprint")
+
+	state, err := h.detector.GetState(c.Request.Context(), fingerprint)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+	if state == nil {
+		respondError(c, h.logger, errors.NewNotFoundError(fmt.Sprintf("flap state %s", fingerprint)))
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Flap state retrieved successfully", state))
+}
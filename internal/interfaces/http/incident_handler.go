@@ -0,0 +1,314 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"alert_agent/internal/domain/incident"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IncidentHandler 事件HTTP处理器
+type IncidentHandler struct {
+	service     incident.Service
+	postmortems incident.PostmortemService
+	logger      *zap.Logger
+}
+
+// NewIncidentHandler 创建事件处理器
+func NewIncidentHandler(service incident.Service, postmortems incident.PostmortemService, logger *zap.Logger) *IncidentHandler {
+	return &IncidentHandler{
+		service:     service,
+		postmortems: postmortems,
+		logger:      logger,
+	}
+}
+
+// CreateIncident 创建事件
+// @Summary 创建事件
+// @Description 手工创建事件，可同时关联告警
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param incident body incident.CreateIncidentRequest true "事件信息"
+// @Success 201 {object} types.APIResponse{data=incident.Incident}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/incidents [post]
+func (h *IncidentHandler) CreateIncident(c *gin.Context) {
+	var req incident.CreateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.CreatedBy = c.GetString("username")
+
+	inc, err := h.service.CreateIncident(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Incident created successfully", inc))
+}
+
+// ListIncidents 获取事件列表
+// @Summary 获取事件列表
+// @Tags incidents
+// @Produce json
+// @Param status query string false "状态"
+// @Param severity query string false "严重程度"
+// @Param assignee query string false "处理人"
+// @Param limit query int false "每页数量" default(20)
+// @Param offset query int false "偏移量" default(0)
+// @Success 200 {object} types.APIResponse{data=types.PageResult}
+// @Router /api/v1/incidents [get]
+func (h *IncidentHandler) ListIncidents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	filter := &incident.Filter{
+		Status:   incident.Status(c.Query("status")),
+		Severity: incident.Severity(c.Query("severity")),
+		Assignee: c.Query("assignee"),
+		Limit:    limit,
+		Offset:   offset,
+	}
+
+	incidents, total, err := h.service.ListIncidents(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Incidents retrieved successfully", types.PageResult{
+		Data:  incidents,
+		Total: total,
+		Size:  limit,
+	}))
+}
+
+// GetIncident 获取事件
+// @Summary 获取事件
+// @Tags incidents
+// @Produce json
+// @Param id path string true "事件ID"
+// @Success 200 {object} types.APIResponse{data=incident.Incident}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/incidents/{id} [get]
+func (h *IncidentHandler) GetIncident(c *gin.Context) {
+	inc, err := h.service.GetIncident(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Incident retrieved successfully", inc))
+}
+
+// UpdateIncident 更新事件
+// @Summary 更新事件
+// @Description 更新标题、描述、严重程度或处理人
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path string true "事件ID"
+// @Param incident body incident.UpdateIncidentRequest true "更新内容"
+// @Success 200 {object} types.APIResponse{data=incident.Incident}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/incidents/{id} [put]
+func (h *IncidentHandler) UpdateIncident(c *gin.Context) {
+	var req incident.UpdateIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	inc, err := h.service.UpdateIncident(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Incident updated successfully", inc))
+}
+
+// UpdateStatus 变更事件状态
+// @Summary 变更事件状态
+// @Description 状态只能按 triggered → acknowledged → mitigated → resolved 推进，已解决的事件可重新打开；resolve_alerts 为 true 时同时恢复关联告警
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path string true "事件ID"
+// @Param status body incident.UpdateStatusRequest true "目标状态"
+// @Success 200 {object} types.APIResponse{data=incident.Incident}
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/incidents/{id}/status [post]
+func (h *IncidentHandler) UpdateStatus(c *gin.Context) {
+	var req incident.UpdateStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	inc, err := h.service.UpdateStatus(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Incident status updated successfully", inc))
+}
+
+// AttachAlerts 关联告警
+// @Summary 关联告警
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path string true "事件ID"
+// @Param alerts body incident.AttachAlertsRequest true "告警ID列表"
+// @Success 200 {object} types.APIResponse{data=incident.Incident}
+// @Router /api/v1/incidents/{id}/alerts [post]
+func (h *IncidentHandler) AttachAlerts(c *gin.Context) {
+	var req incident.AttachAlertsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	inc, err := h.service.AttachAlerts(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alerts attached successfully", inc))
+}
+
+// DetachAlerts 解除关联告警
+// @Summary 解除关联告警
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path string true "事件ID"
+// @Param alerts body incident.AttachAlertsRequest true "告警ID列表"
+// @Success 200 {object} types.APIResponse{data=incident.Incident}
+// @Router /api/v1/incidents/{id}/alerts/detach [post]
+func (h *IncidentHandler) DetachAlerts(c *gin.Context) {
+	var req incident.AttachAlertsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	inc, err := h.service.DetachAlerts(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Alerts detached successfully", inc))
+}
+
+// GetTimeline 获取事件时间线
+// @Summary 获取事件时间线
+// @Tags incidents
+// @Produce json
+// @Param id path string true "事件ID"
+// @Success 200 {object} types.APIResponse{data=[]incident.TimelineEntry}
+// @Router /api/v1/incidents/{id}/timeline [get]
+func (h *IncidentHandler) GetTimeline(c *gin.Context) {
+	entries, err := h.service.GetTimeline(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Incident timeline retrieved successfully", entries))
+}
+
+// AddTimelineEntry 添加时间线条目
+// @Summary 添加时间线条目
+// @Description 添加评论、通知或分析结果，type 默认为 comment
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path string true "事件ID"
+// @Param entry body incident.AddTimelineEntryRequest true "时间线条目"
+// @Success 201 {object} types.APIResponse{data=incident.TimelineEntry}
+// @Router /api/v1/incidents/{id}/timeline [post]
+func (h *IncidentHandler) AddTimelineEntry(c *gin.Context) {
+	var req incident.AddTimelineEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	req.Actor = c.GetString("username")
+
+	entry, err := h.service.AddTimelineEntry(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Timeline entry added successfully", entry))
+}
+
+// GenerateIncidentPostmortem 生成事件复盘报告
+// @Summary 生成事件复盘报告
+// @Description 根据事件时间线、关联告警、通知记录与 AI 分析结果生成 Markdown 复盘草稿，可通过 template 自定义模板，save_to_knowledge 为 true 时保存到知识库
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path string true "事件ID"
+// @Param request body incident.PostmortemRequest false "生成选项"
+// @Success 200 {object} types.APIResponse{data=incident.Postmortem}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/incidents/{id}/postmortem [post]
+func (h *IncidentHandler) GenerateIncidentPostmortem(c *gin.Context) {
+	var req incident.PostmortemRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "INVALID_REQUEST", err.Error())
+			return
+		}
+	}
+	req.IncidentID = c.Param("id")
+	h.generatePostmortem(c, &req)
+}
+
+// GeneratePostmortem 根据告警集合生成复盘报告
+// @Summary 生成复盘报告
+// @Description 根据 incident_id 或 alert_ids 生成 Markdown 复盘草稿
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param request body incident.PostmortemRequest true "生成选项"
+// @Success 200 {object} types.APIResponse{data=incident.Postmortem}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/postmortems [post]
+func (h *IncidentHandler) GeneratePostmortem(c *gin.Context) {
+	var req incident.PostmortemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	h.generatePostmortem(c, &req)
+}
+
+func (h *IncidentHandler) generatePostmortem(c *gin.Context, req *incident.PostmortemRequest) {
+	req.Actor = c.GetString("username")
+
+	pm, err := h.postmortems.GeneratePostmortem(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Postmortem generated successfully", pm))
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// streamIDPattern Redis Stream 消息ID格式
+var streamIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// PipelineHandler 网关处理管道HTTP处理器
+type PipelineHandler struct {
+	service gateway.PipelineService
+	logger  *zap.Logger
+}
+
+// NewPipelineHandler 创建处理管道处理器
+func NewPipelineHandler(service gateway.PipelineService, logger *zap.Logger) *PipelineHandler {
+	return &PipelineHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetStats 获取处理管道统计
+// @Summary 获取处理管道统计
+// @Description 返回处理流长度、待确认数、消费组积压和死信数量
+// @Tags gateway
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=gateway.PipelineStats}
+// @Router /api/v1/gateway/pipeline/stats [get]
+func (h *PipelineHandler) GetStats(c *gin.Context) {
+	stats, err := h.service.GetStats(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Pipeline stats retrieved successfully", stats))
+}
+
+// ListDeadLetters 获取死信消息
+// @Summary 获取死信消息
+// @Tags gateway
+// @Produce json
+// @Param limit query int false "返回数量" default(50)
+// @Success 200 {object} types.APIResponse{data=[]gateway.DeadLetter}
+// @Router /api/v1/gateway/pipeline/dead-letters [get]
+func (h *PipelineHandler) ListDeadLetters(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 64)
+
+	deadLetters, err := h.service.ListDeadLetters(c.Request.Context(), limit)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Dead letters retrieved successfully", deadLetters))
+}
+
+// ReplayDeadLetter 重放死信消息
+// @Summary 重放死信消息
+// @Description 将死信消息重新发布到处理流并从死信流删除
+// @Tags gateway
+// @Produce json
+// @Param id path string true "死信消息ID"
+// @Success 200 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/gateway/pipeline/dead-letters/{id}/replay [post]
+func (h *PipelineHandler) ReplayDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+	if !streamIDPattern.MatchString(id) {
+		respondBadRequest(c, "INVALID_ID", "Dead letter id must be a stream entry id")
+		return
+	}
+
+	messageID, err := h.service.ReplayDeadLetter(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Dead letter replayed successfully", gin.H{
+		"dead_letter_id": id,
+		"message_id":     messageID,
+	}))
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/retention"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RetentionHandler 数据保留与归档HTTP处理器
+type RetentionHandler struct {
+	service retention.Service
+	logger  *zap.Logger
+}
+
+// NewRetentionHandler 创建数据保留处理器
+func NewRetentionHandler(service retention.Service, logger *zap.Logger) *RetentionHandler {
+	return &RetentionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetPolicies 获取保留策略
+// @Summary 获取保留策略
+// @Description 同一条数据匹配多条策略时以最具体的为准，没有适用策略的数据永久保留
+// @Tags retention
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=[]retention.Policy}
+// @Router /api/v1/retention/policies [get]
+func (h *RetentionHandler) GetPolicies(c *gin.Context) {
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Retention policies retrieved successfully", h.service.Policies()))
+}
+
+// ListArchives 查询归档文件
+// @Summary 查询归档文件
+// @Tags retention
+// @Produce json
+// @Param table query string false "数据表 alerts/alert_processing_records"
+// @Param from query string false "开始时间 RFC3339"
+// @Param to query string false "结束时间 RFC3339"
+// @Param limit query int false "返回数量，0 为不限制"
+// @Success 200 {object} types.APIResponse{data=[]retention.Archive}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/retention/archives [get]
+func (h *RetentionHandler) ListArchives(c *gin.Context) {
+	filter := &retention.ArchiveFilter{Table: retention.Table(c.Query("table"))}
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondBadRequest(c, "INVALID_TIME", "Invalid "+param+", expected RFC3339")
+				return
+			}
+			*target = parsed
+		}
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "0"))
+
+	archives, err := h.service.ListArchives(c.Request.Context(), filter)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Archives retrieved successfully", archives))
+}
+
+// Run 立即归档过期数据
+// @Summary 立即归档过期数据
+// @Description 过期数据写入压缩的 JSONL 归档文件后从数据库删除，并补齐告警表月分区
+// @Tags retention
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=retention.RunResult}
+// @Failure 409 {object} types.APIResponse
+// @Router /api/v1/retention/run [post]
+func (h *RetentionHandler) Run(c *gin.Context) {
+	result, err := h.service.Archive(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Expired data archived successfully", result))
+}
+
+// Restore 恢复归档数据
+// @Summary 恢复归档数据
+// @Description 将归档中创建时间在 [from, to) 内的记录写回数据库用于排查，已存在的记录跳过
+// @Tags retention
+// @Accept json
+// @Produce json
+// @Param request body retention.RestoreRequest true "恢复范围"
+// @Success 200 {object} types.APIResponse{data=retention.RestoreResult}
+// @Failure 400 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/retention/restore [post]
+func (h *RetentionHandler) Restore(c *gin.Context) {
+	var req retention.RestoreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	result, err := h.service.Restore(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Archived data restored successfully", result))
+}
@@ -2,9 +2,16 @@ package http
 
 import (
 	"alert_agent/internal/application/analysis"
+	"alert_agent/internal/domain/alert"
+	"alert_agent/internal/domain/analytics"
+	"alert_agent/internal/domain/automation"
 	"alert_agent/internal/domain/channel"
 	"alert_agent/internal/domain/cluster"
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/incident"
+	"alert_agent/internal/domain/retention"
 	domainAnalysis "alert_agent/internal/domain/analysis"
+	"alert_agent/internal/middleware"
 	"alert_agent/internal/security/di"
 	"alert_agent/internal/security/routes"
 
@@ -14,14 +21,26 @@ import (
 
 // Router HTTP路由器
 type Router struct {
-	clusterHandler    *ClusterHandler
-	channelHandler    *ChannelHandler
-	pluginHandler     *PluginHandler
-	analysisHandler   *AnalysisHandler
-	n8nService        *analysis.N8NAnalysisService
-	workflowManager   domainAnalysis.N8NWorkflowManager
-	securityContainer *di.Container
-	logger            *zap.Logger
+	clusterHandler     *ClusterHandler
+	channelHandler     *ChannelHandler
+	pluginHandler      *PluginHandler
+	analysisHandler    *AnalysisHandler
+	routingHandler     *RoutingHandler
+	flappingHandler    *FlappingHandler
+	pipelineHandler    *PipelineHandler
+	incidentHandler    *IncidentHandler
+	alertSearchHandler *AlertSearchHandler
+	analyticsHandler   *AnalyticsHandler
+	alertBulkHandler   *AlertBulkHandler
+	activityHandler    *AlertActivityHandler
+	retentionHandler   *RetentionHandler
+	usageHandler       *UsageHandler
+	feedbackHandler    *FeedbackHandler
+	automationHandler  *AutomationHandler
+	n8nService         *analysis.N8NAnalysisService
+	workflowManager    domainAnalysis.N8NWorkflowManager
+	securityContainer  *di.Container
+	logger             *zap.Logger
 }
 
 // NewRouter 创建路由器
@@ -32,18 +51,48 @@ func NewRouter(
 	analysisService domainAnalysis.AnalysisService,
 	n8nService *analysis.N8NAnalysisService,
 	workflowManager domainAnalysis.N8NWorkflowManager,
+	routingService gateway.RoutingConfigService,
+	flapDetector gateway.FlapDetector,
+	pipelineService gateway.PipelineService,
+	incidentService incident.Service,
+	postmortemService incident.PostmortemService,
+	alertSearchService alert.SearchService,
+	analyticsService analytics.Service,
+	alertBulkService alert.BulkService,
+	activityService alert.ActivityService,
+	retentionService retention.Service,
+	usageService domainAnalysis.UsageService,
+	feedbackService domainAnalysis.FeedbackService,
+	automationService automation.Service,
 	securityContainer *di.Container,
 	logger *zap.Logger,
 ) *Router {
+	var credentials CredentialVerifier
+	if securityContainer != nil {
+		credentials = securityContainer.GetUserService()
+	}
+
 	return &Router{
-		clusterHandler:    NewClusterHandler(clusterService, logger),
-		channelHandler:    NewChannelHandler(channelService, logger),
-		pluginHandler:     NewPluginHandler(channelManager, logger),
-		analysisHandler:   NewAnalysisHandler(analysisService),
-		n8nService:        n8nService,
-		workflowManager:   workflowManager,
-		securityContainer: securityContainer,
-		logger:            logger,
+		clusterHandler:     NewClusterHandler(clusterService, logger),
+		channelHandler:     NewChannelHandler(channelService, logger),
+		pluginHandler:      NewPluginHandler(channelManager, logger),
+		analysisHandler:    NewAnalysisHandler(analysisService),
+		routingHandler:     NewRoutingHandler(routingService, logger),
+		flappingHandler:    NewFlappingHandler(flapDetector, logger),
+		pipelineHandler:    NewPipelineHandler(pipelineService, logger),
+		incidentHandler:    NewIncidentHandler(incidentService, postmortemService, logger),
+		alertSearchHandler: NewAlertSearchHandler(alertSearchService, logger),
+		analyticsHandler:   NewAnalyticsHandler(analyticsService, logger),
+		alertBulkHandler:   NewAlertBulkHandler(alertBulkService, logger),
+		activityHandler:    NewAlertActivityHandler(activityService, logger),
+		retentionHandler:   NewRetentionHandler(retentionService, logger),
+		usageHandler:       NewUsageHandler(usageService, logger),
+		feedbackHandler:    NewFeedbackHandler(feedbackService, logger),
+		automationHandler:  NewAutomationHandler(automationService, credentials, logger),
+		n8nService:         n8nService,
+		workflowManager:    workflowManager,
+		securityContainer:  securityContainer,
+		logger:             logger,
 	}
 }
 
@@ -113,6 +162,7 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 			analysis.POST("/submit", r.analysisHandler.SubmitAnalysis)
 			analysis.GET("/result/:id", r.analysisHandler.GetAnalysisResult)
 			analysis.GET("/progress/:id", r.analysisHandler.GetAnalysisProgress)
+			analysis.GET("/stream/:task_id", r.analysisHandler.StreamAnalysis)
 			analysis.DELETE("/cancel/:id", r.analysisHandler.CancelAnalysis)
 			analysis.POST("/retry/:id", r.analysisHandler.RetryAnalysis)
 			
@@ -122,12 +172,122 @@ func (r *Router) SetupRoutes(engine *gin.Engine) {
 			
 			// 队列和工作器状态
 			analysis.GET("/queue/status", r.analysisHandler.GetQueueStatus)
+			analysis.GET("/queue/dead-letters", r.analysisHandler.ListDeadLetters)
+			analysis.POST("/queue/dead-letters/:task_id/requeue", r.analysisHandler.RequeueDeadLetter)
 			analysis.GET("/workers/status", r.analysisHandler.GetWorkerStatuses)
+
+			// 模型用量与预算
+			analysis.GET("/usage", r.usageHandler.ListUsage)
+			analysis.GET("/usage/:tenant", r.usageHandler.GetTenantUsage)
+
+			// 分析反馈
+			analysis.POST("/results/:id/feedback", r.feedbackHandler.SubmitFeedback)
+			analysis.GET("/results/:id/feedback", r.feedbackHandler.ListFeedback)
+			analysis.GET("/feedback/stats", r.feedbackHandler.GetStats)
 			
 			// 健康检查
 			analysis.GET("/health", r.analysisHandler.HealthCheck)
 		}
 
+		// 告警路由树配置
+		routing := v1.Group("/routing")
+		{
+			routing.GET("/configs", r.routingHandler.ListConfigs)
+			routing.POST("/configs", r.routingHandler.CreateConfig)
+			routing.GET("/configs/:version", r.routingHandler.GetConfig)
+			routing.POST("/configs/:version/activate", r.routingHandler.ActivateConfig)
+			routing.GET("/active", r.routingHandler.GetActiveConfig)
+			routing.POST("/test", r.routingHandler.TestRoute)
+		}
+
+		// 告警网关
+		gw := v1.Group("/gateway")
+		{
+			gw.GET("/flapping", r.flappingHandler.ListFlapping)
+			gw.GET("/flapping/:fingerprint", r.flappingHandler.GetState)
+			gw.GET("/pipeline/stats", r.pipelineHandler.GetStats)
+			gw.GET("/pipeline/dead-letters", r.pipelineHandler.ListDeadLetters)
+			gw.POST("/pipeline/dead-letters/:id/replay", r.pipelineHandler.ReplayDeadLetter)
+		}
+
+		// 记录操作人的接口需要认证身份
+		auth := middleware.AuthMiddleware(r.securityContainer.GetMiddlewareConfig())
+
+		// 事件管理
+		incidents := v1.Group("/incidents", auth)
+		{
+			incidents.POST("", r.incidentHandler.CreateIncident)
+			incidents.GET("", r.incidentHandler.ListIncidents)
+			incidents.GET("/:id", r.incidentHandler.GetIncident)
+			incidents.PUT("/:id", r.incidentHandler.UpdateIncident)
+			incidents.POST("/:id/status", r.incidentHandler.UpdateStatus)
+			incidents.POST("/:id/alerts", r.incidentHandler.AttachAlerts)
+			incidents.POST("/:id/alerts/detach", r.incidentHandler.DetachAlerts)
+			incidents.GET("/:id/timeline", r.incidentHandler.GetTimeline)
+			incidents.POST("/:id/timeline", r.incidentHandler.AddTimelineEntry)
+			incidents.POST("/:id/postmortem", r.incidentHandler.GenerateIncidentPostmortem)
+		}
+
+		// 复盘报告
+		v1.POST("/postmortems", auth, r.incidentHandler.GeneratePostmortem)
+
+		// 告警搜索、批量操作与动态
+		alerts := v1.Group("/alerts")
+		{
+			alerts.GET("/search", r.alertSearchHandler.Search)
+			alerts.GET("/searches", r.alertSearchHandler.ListSavedSearches)
+			alerts.POST("/searches", r.alertSearchHandler.CreateSavedSearch)
+			alerts.PUT("/searches/:id", r.alertSearchHandler.UpdateSavedSearch)
+			alerts.DELETE("/searches/:id", r.alertSearchHandler.DeleteSavedSearch)
+			alerts.GET("/searches/:id/run", r.alertSearchHandler.RunSavedSearch)
+			alerts.POST("/bulk", r.alertBulkHandler.Execute)
+			alerts.GET("/:id", r.activityHandler.GetAlert)
+			alerts.GET("/:id/timeline", r.activityHandler.GetTimeline)
+			alerts.POST("/:id/comments", auth, r.activityHandler.AddComment)
+			alerts.POST("/:id/assign", auth, r.activityHandler.Assign)
+			alerts.POST("/:id/status", auth, r.activityHandler.ChangeStatus)
+			alerts.GET("/:id/automation", r.automationHandler.ListAlertActions)
+		}
+
+		// 运行手册自动化
+		automationGroup := v1.Group("/automation")
+		{
+			automationGroup.POST("/runbooks", r.automationHandler.CreateRunbook)
+			automationGroup.GET("/runbooks", r.automationHandler.ListRunbooks)
+			automationGroup.GET("/runbooks/:id", r.automationHandler.GetRunbook)
+			automationGroup.PUT("/runbooks/:id", r.automationHandler.UpdateRunbook)
+			automationGroup.DELETE("/runbooks/:id", r.automationHandler.DeleteRunbook)
+			automationGroup.POST("/runbooks/:id/trigger", r.automationHandler.TriggerRunbook)
+			automationGroup.GET("/executions", r.automationHandler.ListExecutions)
+			automationGroup.GET("/executions/:id", r.automationHandler.GetExecution)
+			// 审批人取自认证身份
+			approval := middleware.AuthMiddleware(r.securityContainer.GetMiddlewareConfig())
+			automationGroup.POST("/executions/:id/approve", approval, r.automationHandler.ApproveExecution)
+			automationGroup.POST("/executions/:id/reject", approval, r.automationHandler.RejectExecution)
+			automationGroup.GET("/executions/:id/callback", r.automationHandler.ConfirmCallback)
+			automationGroup.POST("/executions/:id/callback", r.automationHandler.Callback)
+		}
+
+		// 告警分析
+		stats := v1.Group("/analytics")
+		{
+			stats.GET("/response", r.analyticsHandler.GetResponseMetrics)
+			stats.GET("/top", r.analyticsHandler.GetTopNoisy)
+			stats.GET("/shifts", r.analyticsHandler.GetShifts)
+			stats.GET("/heatmap", r.analyticsHandler.GetHeatmap)
+			stats.GET("/week-over-week", r.analyticsHandler.GetWeekOverWeek)
+			stats.POST("/rollups/refresh", r.analyticsHandler.RefreshRollups)
+		}
+
+		// 数据保留与归档
+		retentionGroup := v1.Group("/retention")
+		{
+			retentionGroup.GET("/policies", r.retentionHandler.GetPolicies)
+			retentionGroup.GET("/archives", r.retentionHandler.ListArchives)
+			retentionGroup.POST("/run", r.retentionHandler.Run)
+			retentionGroup.POST("/restore", r.retentionHandler.Restore)
+		}
+
 		// n8n 分析路由
 		n8n := v1.Group("/n8n")
 		{
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RoutingHandler 路由配置HTTP处理器
+type RoutingHandler struct {
+	service gateway.RoutingConfigService
+	logger  *zap.Logger
+}
+
+// NewRoutingHandler 创建路由配置处理器
+func NewRoutingHandler(service gateway.RoutingConfigService, logger *zap.Logger) *RoutingHandler {
+	return &RoutingHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateConfig 创建路由配置
+// @Summary 创建路由配置
+// @Description 创建新版本的声明式路由树配置
+// @Tags routing
+// @Accept json
+// @Produce json
+// @Param config body gateway.CreateRoutingConfigRequest true "路由配置"
+// @Success 201 {object} types.APIResponse{data=gateway.RoutingConfig}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/routing/configs [post]
+func (h *RoutingHandler) CreateConfig(c *gin.Context) {
+	var req gateway.CreateRoutingConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = c.GetString("username")
+	}
+
+	config, err := h.service.CreateConfig(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, types.NewSuccessResponse("Routing config created successfully", config))
+}
+
+// ListConfigs 获取路由配置版本列表
+// @Summary 获取路由配置版本列表
+// @Tags routing
+// @Produce json
+// @Param limit query int false "每页数量" default(20)
+// @Param offset query int false "偏移量" default(0)
+// @Success 200 {object} types.APIResponse{data=types.PageResult}
+// @Router /api/v1/routing/configs [get]
+func (h *RoutingHandler) ListConfigs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	configs, total, err := h.service.ListConfigs(c.Request.Context(), limit, offset)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Routing configs retrieved successfully", types.PageResult{
+		Data:  configs,
+		Total: total,
+		Size:  limit,
+	}))
+}
+
+// GetConfig 获取指定版本的路由配置
+// @Summary 获取指定版本的路由配置
+// @Tags routing
+// @Produce json
+// @Param version path int true "配置版本"
+// @Success 200 {object} types.APIResponse{data=gateway.RoutingConfig}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/routing/configs/{version} [get]
+func (h *RoutingHandler) GetConfig(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		respondBadRequest(c, "INVALID_VERSION", "Config version must be a positive integer")
+		return
+	}
+
+	config, err := h.service.GetConfig(c.Request.Context(), version)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Routing config retrieved successfully", config))
+}
+
+// GetActiveConfig 获取当前生效的路由配置
+// @Summary 获取当前生效的路由配置
+// @Tags routing
+// @Produce json
+// @Success 200 {object} types.APIResponse{data=gateway.RoutingConfig}
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/routing/active [get]
+func (h *RoutingHandler) GetActiveConfig(c *gin.Context) {
+	config, err := h.service.GetActiveConfig(c.Request.Context())
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Active routing config retrieved successfully", config))
+}
+
+// ActivateConfig 激活指定版本的路由配置
+// @Summary 激活路由配置
+// @Tags routing
+// @Produce json
+// @Param version path int true "配置版本"
+// @Success 200 {object} types.APIResponse
+// @Failure 404 {object} types.APIResponse
+// @Router /api/v1/routing/configs/{version}/activate [post]
+func (h *RoutingHandler) ActivateConfig(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		respondBadRequest(c, "INVALID_VERSION", "Config version must be a positive integer")
+		return
+	}
+
+	if err := h.service.ActivateConfig(c.Request.Context(), version); err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Routing config activated successfully", gin.H{"version": version}))
+}
+
+// TestRoute 测试路由
+// @Summary 测试路由
+// @Description 返回示例告警会被路由到的接收者
+// @Tags routing
+// @Accept json
+// @Produce json
+// @Param request body gateway.RouteTestRequest true "示例告警"
+// @Success 200 {object} types.APIResponse{data=gateway.RouteTestResult}
+// @Router /api/v1/routing/test [post]
+func (h *RoutingHandler) TestRoute(c *gin.Context) {
+	var req gateway.RouteTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+	if req.Alert == nil && len(req.Labels) == 0 {
+		respondBadRequest(c, "INVALID_REQUEST", "Either alert or labels is required")
+		return
+	}
+
+	result, err := h.service.TestRoute(c.Request.Context(), &req)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Route test completed", result))
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"alert_agent/internal/domain/analysis"
+	"alert_agent/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UsageHandler 模型用量与预算HTTP处理器
+type UsageHandler struct {
+	service analysis.UsageService
+	logger  *zap.Logger
+}
+
+// NewUsageHandler 创建模型用量处理器
+func NewUsageHandler(service analysis.UsageService, logger *zap.Logger) *UsageHandler {
+	return &UsageHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListUsage 获取单日各租户的模型用量
+// @Summary 获取各租户的模型用量
+// @Description 用量按令牌数倒序，包含每日预算、剩余额度与当前预算状态
+// @Tags analysis
+// @Produce json
+// @Param date query string false "日期 2006-01-02，默认今天"
+// @Success 200 {object} types.APIResponse{data=[]analysis.TenantUsage}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analysis/usage [get]
+func (h *UsageHandler) ListUsage(c *gin.Context) {
+	day, ok := parseUsageDate(c, "date", time.Now())
+	if !ok {
+		return
+	}
+
+	usages, err := h.service.ListUsage(c.Request.Context(), day)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Token usage retrieved successfully", usages))
+}
+
+// GetTenantUsage 获取租户每天的模型用量
+// @Summary 获取租户的模型用量
+// @Tags analysis
+// @Produce json
+// @Param tenant path string true "租户"
+// @Param to query string false "结束日期 2006-01-02，默认今天"
+// @Param days query int false "天数，默认 7"
+// @Success 200 {object} types.APIResponse{data=[]analysis.TenantUsage}
+// @Failure 400 {object} types.APIResponse
+// @Router /api/v1/analysis/usage/{tenant} [get]
+func (h *UsageHandler) GetTenantUsage(c *gin.Context) {
+	to, ok := parseUsageDate(c, "to", time.Now())
+	if !ok {
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 {
+		respondBadRequest(c, "INVALID_DAYS", "days must be a positive integer")
+		return
+	}
+
+	usages, err := h.service.TenantUsage(c.Request.Context(), c.Param("tenant"), to.AddDate(0, 0, 1-days), to)
+	if err != nil {
+		respondError(c, h.logger, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, types.NewSuccessResponse("Tenant token usage retrieved successfully", usages))
+}
+
+// parseUsageDate 解析日期参数，格式错误时返回 400
+func parseUsageDate(c *gin.Context, param string, defaultValue time.Time) (time.Time, bool) {
+	value := c.Query(param)
+	if value == "" {
+		return defaultValue, true
+	}
+	day, err := time.ParseInLocation(analysis.UsageDateLayout, value, time.Local)
+	if err != nil {
+		respondBadRequest(c, "INVALID_DATE", "Invalid "+param+", expected 2006-01-02")
+		return time.Time{}, false
+	}
+	return day, true
+}
@@ -46,6 +46,8 @@ type Alert struct {
 	NotifyTime  *time.Time     `json:"-"`
 	NotifyCount int            `json:"notify_count,omitempty" gorm:"default:0"`
 	Severity    string         `json:"severity" gorm:"type:varchar(20);not null;default:'medium'"`
+	// MetricSnapshot 告警触发前后的指标快照（JSON），由告警丰富阶段写入
+	MetricSnapshot string `json:"metric_snapshot,omitempty" gorm:"type:text"`
 }
 
 // Validate 验证告警数据
@@ -111,6 +113,8 @@ type AlertResponse struct {
 	NotifyTime  string `json:"notify_time,omitempty"`
 	NotifyCount int    `json:"notify_count,omitempty"`
 	Severity    string `json:"severity"`
+
+	MetricSnapshot *MetricSnapshot `json:"metric_snapshot,omitempty"`
 }
 
 // ToResponse 转换为响应格式
@@ -135,6 +139,9 @@ func (a *Alert) ToResponse() *AlertResponse {
 		NotifyCount: a.NotifyCount,
 		Severity:    a.Severity,
 	}
+	if snapshot, err := a.GetMetricSnapshot(); err == nil {
+		resp.MetricSnapshot = snapshot
+	}
 	if a.HandleTime != nil {
 		resp.HandleTime = a.HandleTime.Format(time.RFC3339)
 	}
@@ -0,0 +1,202 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sparkBlocks 文本迷你图字符，由低到高
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// MetricSnapshot 告警触发时间附近的指标快照，保存降采样后的数据点和统计值
+type MetricSnapshot struct {
+	ProviderID uint           `json:"provider_id"`
+	Provider   string         `json:"provider"`
+	Query      string         `json:"query"`
+	Start      time.Time      `json:"start"`
+	End        time.Time      `json:"end"`
+	Step       int64          `json:"step"` // 数据点间隔（秒）
+	FiredAt    time.Time      `json:"fired_at"`
+	Series     []MetricSeries `json:"series"`
+	CapturedAt time.Time      `json:"captured_at"`
+}
+
+// MetricSeries 快照中的一条时间序列，Related 为非告警序列的关联序列
+type MetricSeries struct {
+	Query   string            `json:"query,omitempty"`
+	Labels  map[string]string `json:"labels"`
+	Related bool              `json:"related,omitempty"`
+	Points  []MetricPoint     `json:"points"`
+	Stats   MetricStats       `json:"stats"`
+}
+
+// MetricPoint 数据点，T 为 Unix 秒
+type MetricPoint struct {
+	T int64   `json:"t"`
+	V float64 `json:"v"`
+}
+
+// MetricStats 序列统计值
+type MetricStats struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Avg   float64 `json:"avg"`
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+	Count int     `json:"count"`
+}
+
+// GetMetricSnapshot 解析告警的指标快照，未采集时返回 nil
+func (a *Alert) GetMetricSnapshot() (*MetricSnapshot, error) {
+	if a.MetricSnapshot == "" {
+		return nil, nil
+	}
+	var snapshot MetricSnapshot
+	if err := json.Unmarshal([]byte(a.MetricSnapshot), &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid metric snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// SetMetricSnapshot 设置告警的指标快照
+func (a *Alert) SetMetricSnapshot(snapshot *MetricSnapshot) error {
+	if snapshot == nil {
+		a.MetricSnapshot = ""
+		return nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metric snapshot: %w", err)
+	}
+	a.MetricSnapshot = string(data)
+	return nil
+}
+
+// NewMetricSeries 创建序列，数据点超过 maxPoints 时按时间分桶取均值降采样
+func NewMetricSeries(labels map[string]string, points []MetricPoint, maxPoints int) MetricSeries {
+	sort.Slice(points, func(i, j int) bool { return points[i].T < points[j].T })
+	series := MetricSeries{
+		Labels: labels,
+		Points: downsamplePoints(points, maxPoints),
+		Stats:  computeMetricStats(points),
+	}
+	return series
+}
+
+// downsamplePoints 将数据点均匀分为 maxPoints 个桶，每个桶取时间和值的均值
+func downsamplePoints(points []MetricPoint, maxPoints int) []MetricPoint {
+	if maxPoints <= 0 || len(points) <= maxPoints {
+		return points
+	}
+	result := make([]MetricPoint, 0, maxPoints)
+	for i := 0; i < maxPoints; i++ {
+		from := i * len(points) / maxPoints
+		to := (i + 1) * len(points) / maxPoints
+		var sumT, sumV float64
+		for _, p := range points[from:to] {
+			sumT += float64(p.T)
+			sumV += p.V
+		}
+		n := float64(to - from)
+		result = append(result, MetricPoint{T: int64(sumT / n), V: sumV / n})
+	}
+	return result
+}
+
+// computeMetricStats 基于原始数据点计算统计值
+func computeMetricStats(points []MetricPoint) MetricStats {
+	if len(points) == 0 {
+		return MetricStats{}
+	}
+	stats := MetricStats{
+		Min:   math.Inf(1),
+		Max:   math.Inf(-1),
+		First: points[0].V,
+		Last:  points[len(points)-1].V,
+		Count: len(points),
+	}
+	var sum float64
+	for _, p := range points {
+		stats.Min = math.Min(stats.Min, p.V)
+		stats.Max = math.Max(stats.Max, p.V)
+		sum += p.V
+	}
+	stats.Avg = sum / float64(len(points))
+	return stats
+}
+
+// Sparkline 渲染序列的文本迷你图
+func (s *MetricSeries) Sparkline() string {
+	if len(s.Points) == 0 {
+		return ""
+	}
+	spread := s.Stats.Max - s.Stats.Min
+	var b strings.Builder
+	for _, p := range s.Points {
+		idx := 0
+		if spread > 0 {
+			idx = int((p.V - s.Stats.Min) / spread * float64(len(sparkBlocks)-1))
+		}
+		if idx < 0 {
+			idx = 0
+		} else if idx >= len(sparkBlocks) {
+			idx = len(sparkBlocks) - 1
+		}
+		b.WriteRune(sparkBlocks[idx])
+	}
+	return b.String()
+}
+
+// Name 序列名称，格式同 PromQL 序列选择器
+func (s *MetricSeries) Name() string {
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		if name != "__name__" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, s.Labels[name]))
+	}
+	return s.Labels["__name__"] + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// TextChart 渲染快照的文本图表，用于 LLM 提示词和通知内容
+func (s *MetricSnapshot) TextChart() string {
+	if s == nil || len(s.Series) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "指标快照 %s ~ %s（告警触发于 %s，步长 %ds）\n",
+		s.Start.Format("01-02 15:04"), s.End.Format("01-02 15:04"), s.FiredAt.Format("15:04:05"), s.Step)
+	fmt.Fprintf(&b, "查询: %s\n", s.Query)
+	for i := range s.Series {
+		series := &s.Series[i]
+		kind := "告警序列"
+		if series.Related {
+			kind = "关联序列"
+		}
+		fmt.Fprintf(&b, "[%s] %s\n", kind, series.Name())
+		if series.Query != "" && series.Query != s.Query {
+			fmt.Fprintf(&b, "  查询: %s\n", series.Query)
+		}
+		fmt.Fprintf(&b, "  %s\n", series.Sparkline())
+		fmt.Fprintf(&b, "  min=%s max=%s avg=%s first=%s last=%s\n",
+			formatMetricValue(series.Stats.Min), formatMetricValue(series.Stats.Max),
+			formatMetricValue(series.Stats.Avg), formatMetricValue(series.Stats.First),
+			formatMetricValue(series.Stats.Last))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func formatMetricValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -68,3 +68,52 @@ func (p *Provider) MarshalBinary() ([]byte, error) {
 func (p *Provider) UnmarshalBinary(data []byte) error {
 	return json.Unmarshal(data, p)
 }
+
+// Provider 认证类型常量
+const (
+	ProviderAuthNone   = "none"
+	ProviderAuthBasic  = "basic"
+	ProviderAuthBearer = "bearer"
+)
+
+// ProviderConnection 数据源连接参数，由 AuthType 与 AuthConfig 解析得到
+type ProviderConnection struct {
+	BasicAuth   *BasicAuth `json:"basic_auth,omitempty"`
+	BearerToken string     `json:"bearer_token,omitempty"`
+	TLSConfig   *TLSConfig `json:"tls_config,omitempty"`
+	TenantID    string     `json:"tenant_id,omitempty"` // VictoriaMetrics 集群版租户
+}
+
+// Connection 解析连接参数，AuthConfig 兼容 {"username","password"} 形式的基础认证配置
+func (p *Provider) Connection() (*ProviderConnection, error) {
+	conn := &ProviderConnection{}
+	if p.AuthConfig == "" {
+		return conn, nil
+	}
+
+	var raw struct {
+		ProviderConnection
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Token    string `json:"token"`
+	}
+	if err := json.Unmarshal([]byte(p.AuthConfig), &raw); err != nil {
+		return nil, errors.New("invalid auth config")
+	}
+	*conn = raw.ProviderConnection
+
+	switch p.AuthType {
+	case ProviderAuthBasic:
+		if conn.BasicAuth == nil {
+			conn.BasicAuth = &BasicAuth{Username: raw.Username, Password: raw.Password}
+		}
+	case ProviderAuthBearer:
+		if conn.BearerToken == "" {
+			conn.BearerToken = raw.Token
+		}
+	default:
+		conn.BasicAuth = nil
+		conn.BearerToken = ""
+	}
+	return conn, nil
+}
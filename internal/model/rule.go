@@ -14,6 +14,7 @@ type Rule struct {
 	ProviderID    uint   `json:"provider_id" gorm:"not null"`
 	QueryExpr     string `json:"query_expr" gorm:"type:text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;not null"`
 	ConditionExpr string `json:"condition_expr" gorm:"type:text CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;not null"`
+	For           int    `json:"for" gorm:"column:for_duration;not null;default:0"` // 条件持续满足多少秒后触发
 	NotifyType    string `json:"notify_type" gorm:"type:varchar(50) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;not null"`
 	NotifyGroup   string `json:"notify_group" gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;not null"`
 	Template      string `json:"template" gorm:"type:varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;not null"`
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"alert_agent/internal/domain/analysis"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// AnalysisWorkerMetrics 分析工作器扩缩容指标
+type AnalysisWorkerMetrics struct {
+	workers        prometheus.Gauge
+	desiredWorkers prometheus.Gauge
+	capacity       prometheus.Gauge
+	queuePending   prometheus.Gauge
+	queueOldestAge prometheus.Gauge
+	modelInFlight  *prometheus.GaugeVec
+	modelLimit     *prometheus.GaugeVec
+	decisionsTotal *prometheus.CounterVec
+}
+
+// NewAnalysisWorkerMetrics 创建分析工作器指标
+func NewAnalysisWorkerMetrics(registerer prometheus.Registerer) *AnalysisWorkerMetrics {
+	factory := promauto.With(registerer)
+
+	return &AnalysisWorkerMetrics{
+		workers: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_analysis_workers",
+			Help: "Number of analysis workers running in this process",
+		}),
+		desiredWorkers: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_analysis_workers_desired",
+			Help: "Number of analysis workers the autoscaler wants before cooldowns",
+		}),
+		capacity: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_analysis_workers_capacity",
+			Help: "Maximum number of analysis workers after model concurrency limits",
+		}),
+		queuePending: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_analysis_queue_pending",
+			Help: "Number of analysis tasks waiting in the queue",
+		}),
+		queueOldestAge: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_analysis_queue_oldest_task_age_seconds",
+			Help: "Age of the oldest pending analysis task in seconds",
+		}),
+		modelInFlight: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "alertagent_analysis_model_in_flight",
+				Help: "Number of in-flight model calls per model",
+			},
+			[]string{"model"},
+		),
+		modelLimit: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "alertagent_analysis_model_concurrency_limit",
+				Help: "Concurrency limit per model, 0 means unlimited",
+			},
+			[]string{"model"},
+		),
+		decisionsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_analysis_scaling_decisions_total",
+				Help: "Total number of autoscaling decisions by direction",
+			},
+			[]string{"direction"},
+		),
+	}
+}
+
+// ObserveStatus 记录队列积压与工作器数
+func (m *AnalysisWorkerMetrics) ObserveStatus(status *analysis.AutoscalerStatus, queue *analysis.QueueStatus) {
+	m.workers.Set(float64(status.Current))
+	m.desiredWorkers.Set(float64(status.Desired))
+	m.capacity.Set(float64(status.Capacity))
+	m.queuePending.Set(float64(queue.PendingCount))
+	if queue.OldestTask != nil && queue.PendingCount > 0 {
+		m.queueOldestAge.Set(status.UpdatedAt.Sub(*queue.OldestTask).Seconds())
+	} else {
+		m.queueOldestAge.Set(0)
+	}
+	for model, slots := range status.Models {
+		m.modelInFlight.WithLabelValues(model).Set(float64(slots.InFlight))
+		m.modelLimit.WithLabelValues(model).Set(float64(slots.Limit))
+	}
+}
+
+// RecordDecision 记录扩缩容决策
+func (m *AnalysisWorkerMetrics) RecordDecision(decision *analysis.ScalingDecision) {
+	m.decisionsTotal.WithLabelValues(decision.Direction).Inc()
+}
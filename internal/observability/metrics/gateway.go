@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// GatewayMetrics 告警网关与处理管道指标
+type GatewayMetrics struct {
+	alertsReceived    *prometheus.CounterVec
+	alertsProcessed   *prometheus.CounterVec
+	alertsRouted      *prometheus.CounterVec
+	processingLatency *prometheus.HistogramVec
+	errors            *prometheus.CounterVec
+
+	pipelineLength      prometheus.Gauge
+	pipelinePending     prometheus.Gauge
+	pipelineLag         prometheus.Gauge
+	pipelineDeadLetters prometheus.Gauge
+	consumerPending     *prometheus.GaugeVec
+	messagesTotal       *prometheus.CounterVec
+	messageDuration     prometheus.Histogram
+	deadLettersTotal    *prometheus.CounterVec
+}
+
+// NewGatewayMetrics 创建告警网关指标
+func NewGatewayMetrics(registerer prometheus.Registerer) *GatewayMetrics {
+	factory := promauto.With(registerer)
+
+	return &GatewayMetrics{
+		alertsReceived: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_gateway_alerts_received_total",
+				Help: "Total number of alerts received by the gateway",
+			},
+			[]string{"level"},
+		),
+		alertsProcessed: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_gateway_alerts_processed_total",
+				Help: "Total number of alerts processed by the gateway",
+			},
+			[]string{"status"},
+		),
+		alertsRouted: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_gateway_alerts_routed_total",
+				Help: "Total number of routing decisions",
+			},
+			[]string{"suppressed"},
+		),
+		processingLatency: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "alertagent_gateway_processing_latency_seconds",
+				Help:    "Gateway processing latency in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"mode"},
+		),
+		errors: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_gateway_errors_total",
+				Help: "Total number of gateway errors",
+			},
+			[]string{"operation"},
+		),
+
+		pipelineLength: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_gateway_pipeline_stream_length",
+			Help: "Number of entries in the gateway pipeline stream",
+		}),
+		pipelinePending: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_gateway_pipeline_pending",
+			Help: "Number of delivered but unacknowledged pipeline messages",
+		}),
+		pipelineLag: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_gateway_pipeline_lag",
+			Help: "Number of pipeline messages not yet delivered to the consumer group",
+		}),
+		pipelineDeadLetters: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "alertagent_gateway_pipeline_dead_letters",
+			Help: "Number of messages in the dead letter stream",
+		}),
+		consumerPending: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "alertagent_gateway_pipeline_consumer_pending",
+				Help: "Number of pending pipeline messages per consumer",
+			},
+			[]string{"consumer"},
+		),
+		messagesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_gateway_pipeline_messages_total",
+				Help: "Total number of pipeline messages handled by result",
+			},
+			[]string{"status"},
+		),
+		messageDuration: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:    "alertagent_gateway_pipeline_message_duration_seconds",
+			Help:    "Pipeline message handling duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		}),
+		deadLettersTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "alertagent_gateway_pipeline_dead_letters_total",
+				Help: "Total number of messages moved to the dead letter stream",
+			},
+			[]string{"stage"},
+		),
+	}
+}
+
+// RecordAlertReceived 记录告警接收
+func (gm *GatewayMetrics) RecordAlertReceived(ctx context.Context, alert *model.Alert) {
+	gm.alertsReceived.WithLabelValues(alert.Level).Inc()
+}
+
+// RecordAlertProcessed 记录告警处理
+func (gm *GatewayMetrics) RecordAlertProcessed(ctx context.Context, record *gateway.AlertProcessingRecord) {
+	gm.alertsProcessed.WithLabelValues(string(record.Status)).Inc()
+}
+
+// RecordAlertRouted 记录告警路由
+func (gm *GatewayMetrics) RecordAlertRouted(ctx context.Context, decision *gateway.RoutingDecision) {
+	suppressed := "false"
+	if decision.Suppressed {
+		suppressed = "true"
+	}
+	gm.alertsRouted.WithLabelValues(suppressed).Inc()
+}
+
+// RecordProcessingLatency 记录处理延迟（毫秒）
+func (gm *GatewayMetrics) RecordProcessingLatency(ctx context.Context, mode gateway.ProcessingMode, latency int64) {
+	gm.processingLatency.WithLabelValues(string(mode)).Observe(float64(latency) / 1000)
+}
+
+// RecordError 记录错误
+func (gm *GatewayMetrics) RecordError(ctx context.Context, operation string, err error) {
+	gm.errors.WithLabelValues(operation).Inc()
+}
+
+// ObserveStats 记录处理管道积压统计
+func (gm *GatewayMetrics) ObserveStats(stats *gateway.PipelineStats) {
+	gm.pipelineLength.Set(float64(stats.Length))
+	gm.pipelinePending.Set(float64(stats.Pending))
+	gm.pipelineLag.Set(float64(stats.Lag))
+	gm.pipelineDeadLetters.Set(float64(stats.DeadLetters))
+
+	gm.consumerPending.Reset()
+	for _, consumer := range stats.Consumers {
+		gm.consumerPending.WithLabelValues(consumer.Name).Set(float64(consumer.Pending))
+	}
+}
+
+// RecordMessage 记录消息处理结果
+func (gm *GatewayMetrics) RecordMessage(status string, duration time.Duration) {
+	gm.messagesTotal.WithLabelValues(status).Inc()
+	gm.messageDuration.Observe(duration.Seconds())
+}
+
+// RecordDeadLetter 记录死信
+func (gm *GatewayMetrics) RecordDeadLetter(stage gateway.PipelineStage) {
+	gm.deadLettersTotal.WithLabelValues(string(stage)).Inc()
+}
@@ -411,6 +411,11 @@ func (tm *ToggleManager) ListFeatures() map[FeatureName]*FeatureConfig {
 	return result
 }
 
+// Evaluator 获取判断功能 AI 成熟度的评估器，外部记录的指标参与开关判断
+func (tm *ToggleManager) Evaluator() *AIMaturityEvaluator {
+	return tm.evaluator
+}
+
 // RegisterCallback 注册功能状态变更回调
 func (tm *ToggleManager) RegisterCallback(featureName FeatureName, callback func(FeatureConfig)) {
 	tm.mutex.Lock()
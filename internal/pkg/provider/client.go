@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"alert_agent/internal/model"
+)
+
+// DefaultTimeout 默认请求超时
+const DefaultTimeout = 30 * time.Second
+
+// maxResponseSize 单次查询响应的最大字节数
+const maxResponseSize = 32 << 20
+
+// APIResponse Prometheus HTTP API 响应
+type APIResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ErrorType string          `json:"errorType,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Warnings  []string        `json:"warnings,omitempty"`
+}
+
+// BuildInfo 数据源版本信息
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Branch    string `json:"branch,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+// APIError 数据源返回的错误
+type APIError struct {
+	StatusCode int
+	ErrorType  string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.ErrorType != "" {
+		return fmt.Sprintf("%s: %s", e.ErrorType, e.Message)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Message)
+}
+
+// Client Prometheus/VictoriaMetrics 查询客户端
+type Client struct {
+	baseURL     string
+	basicAuth   *model.BasicAuth
+	bearerToken string
+	httpClient  *http.Client
+}
+
+// NewClient 根据数据源配置创建带认证和 TLS 的客户端
+func NewClient(p *model.Provider, timeout time.Duration) (*Client, error) {
+	if p.Type != model.ProviderTypePrometheus && p.Type != model.ProviderTypeVictoriaMetrics {
+		return nil, fmt.Errorf("unsupported provider type: %s", p.Type)
+	}
+	endpoint, err := url.Parse(strings.TrimRight(p.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %s", p.Endpoint)
+	}
+
+	conn, err := p.Connection()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if conn.TLSConfig != nil {
+		tlsConfig, err := buildTLSConfig(conn.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	baseURL := endpoint.String()
+	// VictoriaMetrics 集群版通过 vmselect 的租户路径查询
+	if p.Type == model.ProviderTypeVictoriaMetrics && conn.TenantID != "" {
+		baseURL += "/select/" + url.PathEscape(conn.TenantID) + "/prometheus"
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Client{
+		baseURL:     baseURL,
+		basicAuth:   conn.BasicAuth,
+		bearerToken: conn.BearerToken,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}, nil
+}
+
+// buildTLSConfig 构建 TLS 配置，CACert 为 PEM 格式证书内容
+func buildTLSConfig(cfg *model.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, errors.New("invalid CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// BuildInfo 探测 /api/v1/status/buildinfo
+func (c *Client) BuildInfo(ctx context.Context) (*BuildInfo, error) {
+	resp, err := c.Get(ctx, "/api/v1/status/buildinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	var info BuildInfo
+	if err := json.Unmarshal(resp.Data, &info); err != nil {
+		return nil, fmt.Errorf("decode buildinfo failed: %w", err)
+	}
+	return &info, nil
+}
+
+// Query 即时查询
+func (c *Client) Query(ctx context.Context, params url.Values) (*APIResponse, error) {
+	return c.Get(ctx, "/api/v1/query", params)
+}
+
+// QueryRange 范围查询
+func (c *Client) QueryRange(ctx context.Context, params url.Values) (*APIResponse, error) {
+	return c.Get(ctx, "/api/v1/query_range", params)
+}
+
+// Labels 查询标签名
+func (c *Client) Labels(ctx context.Context, params url.Values) (*APIResponse, error) {
+	return c.Get(ctx, "/api/v1/labels", params)
+}
+
+// LabelValues 查询标签值
+func (c *Client) LabelValues(ctx context.Context, name string, params url.Values) (*APIResponse, error) {
+	return c.Get(ctx, "/api/v1/label/"+url.PathEscape(name)+"/values", params)
+}
+
+// Series 查询时间序列
+func (c *Client) Series(ctx context.Context, params url.Values) (*APIResponse, error) {
+	return c.Get(ctx, "/api/v1/series", params)
+}
+
+// Get 调用 Prometheus HTTP API，status 不为 success 时返回 APIError
+func (c *Client) Get(ctx context.Context, path string, params url.Values) (*APIResponse, error) {
+	target := c.baseURL + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.basicAuth != nil {
+		req.SetBasicAuth(c.basicAuth.Username, c.basicAuth.Password)
+	} else if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("read response failed: %w", err)
+	}
+
+	var result APIResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(truncate(string(body), 256))}
+	}
+	if result.Status != "success" {
+		return nil, &APIError{StatusCode: resp.StatusCode, ErrorType: result.ErrorType, Message: result.Error}
+	}
+	return &result, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"alert_agent/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_BasicAuthBuildInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+		assert.Equal(t, "/api/v1/status/buildinfo", r.URL.Path)
+		w.Write([]byte(`{"status":"success","data":{"version":"2.45.0","revision":"abc"}}`))
+	}))
+	defer server.Close()
+
+	provider := &model.Provider{
+		Type:       model.ProviderTypePrometheus,
+		Endpoint:   server.URL + "/",
+		AuthType:   model.ProviderAuthBasic,
+		AuthConfig: `{"username":"admin","password":"secret"}`,
+	}
+	client, err := NewClient(provider, 0)
+	require.NoError(t, err)
+
+	info, err := client.BuildInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "2.45.0", info.Version)
+
+	// 错误的凭证返回非 JSON 响应
+	provider.AuthConfig = `{"username":"admin","password":"wrong"}`
+	client, err = NewClient(provider, 0)
+	require.NoError(t, err)
+	_, err = client.BuildInfo(context.Background())
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestClient_VictoriaMetricsTenantQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		assert.Equal(t, "/select/42/prometheus/api/v1/query", r.URL.Path)
+		if r.URL.Query().Get("query") == "up{" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&model.Provider{
+		Type:       model.ProviderTypeVictoriaMetrics,
+		Endpoint:   server.URL,
+		AuthType:   model.ProviderAuthBearer,
+		AuthConfig: `{"token":"token-1","tenant_id":"42"}`,
+	}, 0)
+	require.NoError(t, err)
+
+	resp, err := client.Query(context.Background(), url.Values{"query": {"up"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resultType":"vector","result":[]}`, string(resp.Data))
+
+	_, err = client.Query(context.Background(), url.Values{"query": {"up{"}})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "bad_data", apiErr.ErrorType)
+}
+
+func TestNewClient_InvalidConfig(t *testing.T) {
+	_, err := NewClient(&model.Provider{Type: model.ProviderTypePrometheus, Endpoint: "localhost:9090"}, 0)
+	assert.Error(t, err)
+
+	_, err = NewClient(&model.Provider{
+		Type:       model.ProviderTypePrometheus,
+		Endpoint:   "https://prometheus:9090",
+		AuthConfig: `{"tls_config":{"ca_cert":"not a certificate"}}`,
+	}, 0)
+	assert.EqualError(t, err, "invalid CA certificate")
+}
@@ -106,6 +106,12 @@ func RegisterRoutes(r *gin.Engine, featureService *service.FeatureService) {
 			providers.PUT("/:id", v1.UpdateProvider)
 			providers.DELETE("/:id", v1.DeleteProvider)
 			providers.POST("/test", v1.TestProvider)
+			providers.POST("/:id/test", v1.CheckProvider)
+			providers.GET("/:id/query", v1.QueryProvider)
+			providers.GET("/:id/query_range", v1.QueryRangeProvider)
+			providers.GET("/:id/labels", v1.ListProviderLabels)
+			providers.GET("/:id/label/:name/values", v1.ListProviderLabelValues)
+			providers.GET("/:id/series", v1.ListProviderSeries)
 		}
 
 		// 功能开关管理
@@ -150,6 +150,18 @@ func (s *AlertService) UpdateAlertStatus(ctx context.Context, id uint, status st
 	return nil
 }
 
+// ResolveAlert 恢复告警，已恢复的告警不重复更新
+func (s *AlertService) ResolveAlert(ctx context.Context, id uint, note string) error {
+	alert, err := s.GetAlert(ctx, id)
+	if err != nil {
+		return err
+	}
+	if alert.Status == model.AlertStatusResolved {
+		return nil
+	}
+	return s.UpdateAlertStatus(ctx, id, model.AlertStatusResolved, "rule-engine", note)
+}
+
 // ListAlerts 获取告警列表
 func (s *AlertService) ListAlerts(ctx context.Context, query *AlertQuery) ([]*model.Alert, int64, error) {
 	db := s.db.WithContext(ctx).Model(&model.Alert{})
@@ -271,7 +283,11 @@ func (s *AlertService) triggerNotification(ctx context.Context, tx *gorm.DB, ale
 // renderTemplate 渲染通知模板
 func (s *AlertService) renderTemplate(template *model.NotifyTemplate, alert *model.Alert) string {
 	// TODO: 实现模板渲染逻辑
-	return template.Content
+	content := template.Content
+	if snapshot, err := alert.GetMetricSnapshot(); err == nil && snapshot != nil {
+		content += "\n\n" + snapshot.TextChart()
+	}
+	return content
 }
 
 // AlertQuery 告警查询参数
@@ -96,7 +96,7 @@ func CreateKnowledge(alert *model.Alert) (*model.Knowledge, error) {
 // generateSummary 生成内容摘要
 func (s *KnowledgeService) generateSummary(ctx context.Context, content string) (string, error) {
 	prompt := fmt.Sprintf("请为以下内容生成一个简短的摘要（不超过100字）：\n\n%s", content)
-	return s.ollamaService.callOllamaAPI(ctx, prompt)
+	return s.ollamaService.callOllamaAPI(ctx, prompt, "")
 }
 
 // generateVector 生成内容的向量表示
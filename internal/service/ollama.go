@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"alert_agent/internal/config"
+	"alert_agent/internal/domain/analysis"
+	aiAnalysis "alert_agent/internal/infrastructure/analysis"
 	"alert_agent/internal/model"
 	"alert_agent/internal/pkg/database"
 	"alert_agent/internal/pkg/logger"
@@ -84,39 +86,68 @@ func (s *OllamaService) getConfig() *config.OllamaConfig {
 	return s.config
 }
 
-// AnalyzeAlert 分析告警
+// AnalyzeAlert 分析告警，返回渲染为 Markdown 的结构化根因分析
 func (s *OllamaService) AnalyzeAlert(ctx context.Context, alert *model.Alert) (string, error) {
+	output, err := s.AnalyzeAlertStructured(ctx, alert)
+	if err != nil {
+		return "", err
+	}
+	return output.Markdown(), nil
+}
+
+// AnalyzeAlertStructured 按根因分析 schema 分析告警，输出不合格时要求模型修正一次
+func (s *OllamaService) AnalyzeAlertStructured(ctx context.Context, alert *model.Alert) (*analysis.StructuredOutput, error) {
 	// 获取当前配置
 	currentConfig := s.getConfig()
 	
 	// 检查是否启用Ollama功能
 	if !currentConfig.Enabled {
-		return "", fmt.Errorf("ollama analysis is disabled")
+		return nil, fmt.Errorf("ollama analysis is disabled")
 	}
 
 	// 构建提示词
-	prompt := fmt.Sprintf(`请分析以下告警信息，并提供详细的分析和建议：
+	prompt := fmt.Sprintf(`请分析以下告警信息，给出严重程度、可能的根因、处理方案与预防措施：
 
 告警标题：%s
 告警级别：%s
 告警来源：%s
 告警内容：%s
+%s
+请用中文回答，并保持专业和客观。`, alert.Title, alert.Level, alert.Source, alert.Content, metricSnapshotSection(alert)) +
+		aiAnalysis.OutputInstruction(analysis.AnalysisTypeRootCause)
 
-请从以下几个方面进行分析：
-1. 告警的严重程度和影响范围
-2. 可能的原因分析
-3. 建议的处理方案
-4. 预防措施建议
+	// 调用Ollama API
+	content, err := s.callOllamaAPI(ctx, prompt, "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze alert: %w", err)
+	}
 
-请用中文回答，并保持专业和客观。`, alert.Title, alert.Level, alert.Source, alert.Content)
+	output, _, err := aiAnalysis.ParseStructuredOutput(analysis.AnalysisTypeRootCause, content)
+	if err == nil {
+		return output, nil
+	}
+	s.logger.Warn("Ollama analysis output failed schema validation, requesting repair",
+		zap.Uint("alert_id", alert.ID),
+		zap.Error(err))
 
-	// 调用Ollama API
-	analysis, err := s.callOllamaAPI(ctx, prompt)
+	content, repairErr := s.callOllamaAPI(ctx, aiAnalysis.RepairPrompt(analysis.AnalysisTypeRootCause, content, err), "json")
+	if repairErr != nil {
+		return nil, fmt.Errorf("failed to repair analysis output: %w", repairErr)
+	}
+	output, _, err = aiAnalysis.ParseStructuredOutput(analysis.AnalysisTypeRootCause, content)
 	if err != nil {
-		return "", fmt.Errorf("failed to analyze alert: %w", err)
+		return nil, fmt.Errorf("failed to analyze alert: %w", err)
 	}
+	return output, nil
+}
 
-	return analysis, nil
+// metricSnapshotSection 告警指标快照的提示词片段，无快照时为空
+func metricSnapshotSection(alert *model.Alert) string {
+	snapshot, err := alert.GetMetricSnapshot()
+	if err != nil || snapshot == nil {
+		return ""
+	}
+	return "\n" + snapshot.TextChart() + "\n"
 }
 
 // FindSimilarAlerts 查找相似告警
@@ -140,7 +171,7 @@ func (s *OllamaService) FindSimilarAlerts(ctx context.Context, alert *model.Aler
 请从数据库中查找相似的告警，并返回告警ID列表。`, alert.Title, alert.Level, alert.Source, alert.Content)
 
 	// 调用Ollama API
-	similarIDs, err := s.callOllamaAPI(ctx, prompt)
+	similarIDs, err := s.callOllamaAPI(ctx, prompt, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to find similar alerts: %w", err)
 	}
@@ -154,8 +185,8 @@ func (s *OllamaService) FindSimilarAlerts(ctx context.Context, alert *model.Aler
 	return similarAlerts, nil
 }
 
-// callOllamaAPI 调用Ollama API
-func (s *OllamaService) callOllamaAPI(ctx context.Context, prompt string) (string, error) {
+// callOllamaAPI 调用Ollama API，format 为 json 时要求模型输出 JSON
+func (s *OllamaService) callOllamaAPI(ctx context.Context, prompt, format string) (string, error) {
 	// 获取当前配置
 	currentConfig := s.getConfig()
 	
@@ -165,6 +196,9 @@ func (s *OllamaService) callOllamaAPI(ctx context.Context, prompt string) (strin
 		"prompt": prompt,
 		"stream": false,
 	}
+	if format != "" {
+		reqBody["format"] = format
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"time"
 
+	"alert_agent/internal/domain/gateway"
+	"alert_agent/internal/domain/rule"
 	"alert_agent/internal/model"
+	providerclient "alert_agent/internal/pkg/provider"
 
 	goredis "github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -15,6 +18,7 @@ import (
 var (
 	ErrProviderNotFound = errors.New("provider not found")
 	ErrInvalidProvider  = errors.New("invalid provider data")
+	ErrProviderInactive = errors.New("provider is inactive")
 )
 
 const (
@@ -151,3 +155,93 @@ func (s *ProviderService) DeleteProvider(ctx context.Context, id uint) error {
 
 	return nil
 }
+
+// TestConnection 探测数据源 /api/v1/status/buildinfo，不记录检查结果
+func (s *ProviderService) TestConnection(ctx context.Context, provider *model.Provider) (*providerclient.BuildInfo, error) {
+	if err := provider.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+	}
+
+	client, err := providerclient.NewClient(provider, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+	}
+	return client.BuildInfo(ctx)
+}
+
+// CheckProvider 按已保存的配置检查数据源连通性并记录检查结果
+func (s *ProviderService) CheckProvider(ctx context.Context, id uint) (*providerclient.BuildInfo, error) {
+	provider, err := s.GetProvider(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	info, probeErr := s.TestConnection(ctx, provider)
+	if errors.Is(probeErr, ErrInvalidProvider) {
+		return nil, probeErr
+	}
+	if err := s.recordCheck(ctx, provider.ID, probeErr); err != nil {
+		return nil, err
+	}
+	return info, probeErr
+}
+
+// Client 获取已保存数据源的查询客户端
+func (s *ProviderService) Client(ctx context.Context, id uint) (*providerclient.Client, error) {
+	provider, err := s.GetProvider(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if provider.Status == model.ProviderStatusInactive {
+		return nil, ErrProviderInactive
+	}
+	client, err := providerclient.NewClient(provider, providerclient.DefaultTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProvider, err)
+	}
+	return client, nil
+}
+
+// QueryClient 获取规则评估使用的查询客户端
+func (s *ProviderService) QueryClient(ctx context.Context, id uint) (rule.QueryClient, *model.Provider, error) {
+	provider, err := s.GetProvider(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := s.Client(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, provider, nil
+}
+
+// RangeQueryClient 获取指标快照使用的范围查询客户端
+func (s *ProviderService) RangeQueryClient(ctx context.Context, id uint) (gateway.RangeQueryClient, *model.Provider, error) {
+	provider, err := s.GetProvider(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := s.Client(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, provider, nil
+}
+
+// recordCheck 记录连通性检查时间和错误
+func (s *ProviderService) recordCheck(ctx context.Context, id uint, checkErr error) error {
+	lastError := ""
+	if checkErr != nil {
+		lastError = checkErr.Error()
+	}
+
+	if err := s.db.WithContext(ctx).Model(&model.Provider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_check": time.Now(),
+		"last_error": lastError,
+	}).Error; err != nil {
+		return err
+	}
+
+	// 删除缓存，下次读取时加载最新检查结果
+	cacheKey := fmt.Sprintf("%s%d", ProviderCacheKeyPrefix, id)
+	return s.cache.Del(ctx, cacheKey).Err()
+}